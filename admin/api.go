@@ -19,11 +19,14 @@ package admin
 
 import (
 	"context"
+	"io"
 
 	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
 
+	"github.com/polarismesh/polaris/admin/gitops"
 	connlimit "github.com/polarismesh/polaris/common/conn/limit"
 	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store/migrate"
 )
 
 type ConnReq struct {
@@ -47,6 +50,25 @@ type ConnStatsResp struct {
 	Stats           []*connlimit.HostConnStat
 }
 
+// OperationRecordsResp 操作记录查询结果
+type OperationRecordsResp struct {
+	Total   uint32               `json:"total"`
+	Records []*model.RecordEntry `json:"records"`
+}
+
+// CryptoKeyVersion 使用同一个主密钥版本包装数据密钥的加密配置
+type CryptoKeyVersion struct {
+	Algorithm string `json:"algorithm"`
+	// Version 主密钥版本, 为空表示数据密钥还未被主密钥包装
+	Version string `json:"version"`
+	// Primary 是否为当前用于包装新数据密钥的版本
+	Primary bool `json:"primary"`
+	// Files 配置文件, 格式为 namespace@group@file_name
+	Files []string `json:"files"`
+	// Releases 生效中的配置发布, 格式为 namespace@group@file_name@release_name
+	Releases []string `json:"releases"`
+}
+
 type ScopeLevel struct {
 	Name  string
 	Level string
@@ -80,4 +102,15 @@ type AdminOperateServer interface {
 	ReleaseLeaderElection(ctx context.Context, electKey string) error
 	// GetCMDBInfo get cmdb info
 	GetCMDBInfo(ctx context.Context) ([]model.LocationView, error)
+	// GetOperationRecords search operation history
+	GetOperationRecords(ctx context.Context, query map[string]string) (*OperationRecordsResp, error)
+	// GetCryptoKeyVersions report the master key versions used by the encrypted config files
+	GetCryptoKeyVersions(ctx context.Context) ([]*CryptoKeyVersion, error)
+	// Backup write a consistent snapshot of all governance data to w as a versioned archive
+	Backup(ctx context.Context, withInstances bool, w io.Writer) (*migrate.Manifest, error)
+	// Restore restore the governance data from the archive, policy decides how to handle the existing data
+	Restore(ctx context.Context, policy string, r io.Reader) ([]*migrate.Result, error)
+	// ReconcileManifests reconcile the governance resources with the manifests archive (zip or tar.gz),
+	// existing resources not managed by gitops are only taken over when adopt is true
+	ReconcileManifests(ctx context.Context, archive []byte, dryRun, adopt bool) (*gitops.Plan, error)
 }
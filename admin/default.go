@@ -21,9 +21,11 @@ import (
 	"context"
 	"errors"
 
+	"github.com/polarismesh/polaris/admin/gitops"
 	"github.com/polarismesh/polaris/admin/job"
 	"github.com/polarismesh/polaris/auth"
 	"github.com/polarismesh/polaris/cache"
+	"github.com/polarismesh/polaris/config"
 	"github.com/polarismesh/polaris/service"
 	"github.com/polarismesh/polaris/service/healthcheck"
 	"github.com/polarismesh/polaris/store"
@@ -69,6 +71,12 @@ func initialize(_ context.Context, cfg *Config, namingService service.DiscoverSe
 	maintainServer.healthCheckServer = healthCheckServer
 	maintainServer.cacheMgn = cacheMgn
 	maintainServer.storage = storage
+	// 配置中心在运维模块之前初始化，用于同步配置文件以及保存 gitops 的受管理资源清单
+	configServer, err := config.GetServer()
+	if err != nil {
+		return err
+	}
+	maintainServer.reconciler = gitops.NewReconciler(namingService, configServer, cacheMgn)
 
 	maintainJobs := job.NewMaintainJobs(namingService, cacheMgn, storage)
 	if err := maintainJobs.StartMaintianJobs(cfg.Jobs); err != nil {
@@ -0,0 +1,118 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	apiconfig "github.com/polarismesh/specification/source/go/api/v1/config_manage"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/config"
+	"github.com/polarismesh/polaris/service"
+)
+
+const (
+	// InventoryGroup 保存受管理资源清单的配置分组，位于系统命名空间下
+	InventoryGroup = "polaris-gitops"
+	// InventoryFile 保存受管理资源清单的配置文件，不会被发布
+	InventoryFile = "inventory.json"
+)
+
+// inventory 受管理的资源以及上次应用时的摘要，kind -> key -> hash
+type inventory struct {
+	Resources map[string]map[string]string `json:"resources"`
+}
+
+func (i *inventory) get(kind, key string) string {
+	return i.Resources[kind][key]
+}
+
+func (i *inventory) set(kind, key, hash string) {
+	if _, ok := i.Resources[kind]; !ok {
+		i.Resources[kind] = map[string]string{}
+	}
+	i.Resources[kind][key] = hash
+}
+
+func (i *inventory) remove(kind, key string) {
+	delete(i.Resources[kind], key)
+}
+
+// inventoryStore 将受管理资源清单保存在配置中心，集群中的所有节点共享
+type inventoryStore struct {
+	config config.ConfigCenterServer
+	exists bool
+}
+
+func (s *inventoryStore) file() *apiconfig.ConfigFile {
+	return &apiconfig.ConfigFile{
+		Namespace: utils.NewStringValue(service.SystemNamespace),
+		Group:     utils.NewStringValue(InventoryGroup),
+		Name:      utils.NewStringValue(InventoryFile),
+	}
+}
+
+func (s *inventoryStore) load(ctx context.Context) (*inventory, error) {
+	if s.config == nil {
+		return nil, errors.New("config center is required to store the gitops inventory")
+	}
+	ret := &inventory{Resources: map[string]map[string]string{}}
+	resp := s.config.GetConfigFileRichInfo(ctx, s.file())
+	switch resp.GetCode().GetValue() {
+	case uint32(apimodel.Code_ExecuteSuccess):
+	case uint32(apimodel.Code_NotFoundResource):
+		s.exists = false
+		return ret, nil
+	default:
+		return nil, fmt.Errorf("load inventory: %w", configError(resp))
+	}
+	s.exists = true
+	if content := resp.GetConfigFile().GetContent().GetValue(); content != "" {
+		if err := json.Unmarshal([]byte(content), ret); err != nil {
+			return nil, fmt.Errorf("load inventory: %w", err)
+		}
+	}
+	if ret.Resources == nil {
+		ret.Resources = map[string]map[string]string{}
+	}
+	return ret, nil
+}
+
+func (s *inventoryStore) save(ctx context.Context, inv *inventory) error {
+	content, err := json.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return err
+	}
+	file := s.file()
+	file.Content = utils.NewStringValue(string(content))
+	file.Format = utils.NewStringValue("json")
+	file.Comment = utils.NewStringValue("resources managed by gitops, do not edit")
+	if s.exists {
+		return configError(s.config.UpdateConfigFile(ctx, file))
+	}
+	if err := configError(s.config.CreateConfigFile(ctx, file)); err != nil {
+		return err
+	}
+	s.exists = true
+	return nil
+}
@@ -0,0 +1,525 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	apiconfig "github.com/polarismesh/specification/source/go/api/v1/config_manage"
+	apifault "github.com/polarismesh/specification/source/go/api/v1/fault_tolerance"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	apitraffic "github.com/polarismesh/specification/source/go/api/v1/traffic_manage"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/config"
+)
+
+const (
+	// queryPageSize 分页查询现有资源时每页的数量
+	queryPageSize = 100
+	// internalMetaPrefix 服务端写入配置文件标签中的内部字段
+	internalMetaPrefix = "internal-"
+	// defaultRateLimitAction 未设置限流效果时服务端返回的默认值
+	defaultRateLimitAction = "REJECT"
+)
+
+// resource 一个声明式资源或者一个已经存在的资源
+type resource struct {
+	key  string
+	msg  proto.Message
+	hash string
+}
+
+// kindHandler 一类资源的解析、查询以及变更
+type kindHandler interface {
+	kind() string
+	// decode 解析并校验资源清单中的 spec
+	decode(spec []byte) (*resource, error)
+	// list 查询当前已经存在的全部资源
+	list(ctx context.Context, r *Reconciler) (map[string]*resource, error)
+	create(ctx context.Context, r *Reconciler, desired *resource) error
+	update(ctx context.Context, r *Reconciler, current, desired *resource) error
+	delete(ctx context.Context, r *Reconciler, current *resource) error
+}
+
+// ruleKind 使用 proto 描述的治理规则
+type ruleKind[T proto.Message] struct {
+	name   string
+	newMsg func() T
+	key    func(T) (string, error)
+	// ignored 服务端维护的字段，不参与比较
+	ignored []string
+	// defaults 补全服务端在查询时填充的默认值，可以为空
+	defaults func(T)
+	// listPage 分页查询现有规则，返回规则以及总数
+	listPage func(ctx context.Context, r *Reconciler, offset int) ([]T, uint32, error)
+	// prepare 写入前使用已经存在的规则补全 ID 等字段
+	prepare  func(current, desired T)
+	createFn func(ctx context.Context, r *Reconciler, desired T) error
+	updateFn func(ctx context.Context, r *Reconciler, current, desired T) error
+	deleteFn func(ctx context.Context, r *Reconciler, current T) error
+}
+
+func (k *ruleKind[T]) kind() string {
+	return k.name
+}
+
+func (k *ruleKind[T]) decode(spec []byte) (*resource, error) {
+	msg := k.newMsg()
+	if err := jsonpb.Unmarshal(bytes.NewReader(spec), msg); err != nil {
+		return nil, err
+	}
+	if k.defaults != nil {
+		k.defaults(msg)
+	}
+	return k.toResource(msg)
+}
+
+func (k *ruleKind[T]) toResource(msg T) (*resource, error) {
+	key, err := k.key(msg)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := hashMessage(msg, k.ignored)
+	if err != nil {
+		return nil, err
+	}
+	return &resource{key: key, msg: msg, hash: hash}, nil
+}
+
+func (k *ruleKind[T]) list(ctx context.Context, r *Reconciler) (map[string]*resource, error) {
+	ret := map[string]*resource{}
+	for offset := 0; ; offset += queryPageSize {
+		items, total, err := k.listPage(ctx, r, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			res, err := k.toResource(item)
+			if err != nil {
+				// 不满足声明式约束的存量规则不会被管理
+				continue
+			}
+			ret[res.key] = res
+		}
+		if len(items) == 0 || offset+queryPageSize >= int(total) {
+			return ret, nil
+		}
+	}
+}
+
+func (k *ruleKind[T]) create(ctx context.Context, r *Reconciler, desired *resource) error {
+	return k.createFn(ctx, r, desired.msg.(T))
+}
+
+func (k *ruleKind[T]) update(ctx context.Context, r *Reconciler, current, desired *resource) error {
+	// 不修改解析出来的期望规则，避免影响后续的比较
+	msg := proto.Clone(desired.msg).(T)
+	k.prepare(current.msg.(T), msg)
+	return k.updateFn(ctx, r, current.msg.(T), msg)
+}
+
+func (k *ruleKind[T]) delete(ctx context.Context, r *Reconciler, current *resource) error {
+	return k.deleteFn(ctx, r, current.msg.(T))
+}
+
+// hashMessage 计算规则的摘要，忽略服务端维护的字段以及默认值
+func hashMessage(msg proto.Message, ignored []string) (string, error) {
+	m := jsonpb.Marshaler{OrigName: true}
+	content, err := m.MarshalToString(msg)
+	if err != nil {
+		return "", err
+	}
+	values := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(content), &values); err != nil {
+		return "", err
+	}
+	for _, field := range ignored {
+		delete(values, field)
+	}
+	return hashValue(pruneEmpty(values))
+}
+
+// pruneEmpty 递归去掉空的对象以及数组，服务端返回的规则中未设置的字段可能是空对象
+func pruneEmpty(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			item = pruneEmpty(item)
+			if isEmpty(item) {
+				delete(val, k)
+				continue
+			}
+			val[k] = item
+		}
+		return val
+	case []interface{}:
+		for i := range val {
+			val[i] = pruneEmpty(val[i])
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+func isEmpty(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(val) == 0
+	case []interface{}:
+		return len(val) == 0
+	case string:
+		return val == ""
+	case bool:
+		return !val
+	case float64:
+		return val == 0
+	default:
+		return false
+	}
+}
+
+func hashValue(v interface{}) (string, error) {
+	// encoding/json 按照 key 排序输出 map，结果稳定
+	content, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// batchWriteError 将批量写操作的失败信息转换为错误
+func batchWriteError(resp *apiservice.BatchWriteResponse) error {
+	if resp.GetCode().GetValue() == uint32(apimodel.Code_ExecuteSuccess) {
+		return nil
+	}
+	for _, item := range resp.GetResponses() {
+		if item.GetCode().GetValue() != uint32(apimodel.Code_ExecuteSuccess) {
+			return fmt.Errorf("code %d: %s", item.GetCode().GetValue(), item.GetInfo().GetValue())
+		}
+	}
+	return fmt.Errorf("code %d: %s", resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+}
+
+func queryError(resp *apiservice.BatchQueryResponse) error {
+	if resp.GetCode().GetValue() == uint32(apimodel.Code_ExecuteSuccess) {
+		return nil
+	}
+	return fmt.Errorf("code %d: %s", resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+}
+
+func pageQuery(offset int) map[string]string {
+	return map[string]string{
+		"offset": strconv.Itoa(offset),
+		"limit":  strconv.Itoa(queryPageSize),
+	}
+}
+
+func unmarshalAnys[T proto.Message](data []*any.Any, newMsg func() T) ([]T, error) {
+	ret := make([]T, 0, len(data))
+	for _, item := range data {
+		msg := newMsg()
+		if err := ptypes.UnmarshalAny(item, msg); err != nil {
+			return nil, err
+		}
+		ret = append(ret, msg)
+	}
+	return ret, nil
+}
+
+var serverFields = []string{"id", "revision", "ctime", "mtime", "etime"}
+
+var routeRuleKind = &ruleKind[*apitraffic.RouteRule]{
+	name:    KindRouteRule,
+	newMsg:  func() *apitraffic.RouteRule { return &apitraffic.RouteRule{} },
+	ignored: append([]string{"extendInfo", "extend_info"}, serverFields...),
+	key: func(rule *apitraffic.RouteRule) (string, error) {
+		if rule.GetName() == "" || rule.GetNamespace() == "" {
+			return "", errors.New("name and namespace are required")
+		}
+		return rule.GetNamespace() + "/" + rule.GetName(), nil
+	},
+	listPage: func(ctx context.Context, r *Reconciler, offset int) ([]*apitraffic.RouteRule, uint32, error) {
+		resp := r.naming.QueryRoutingConfigsV2(ctx, pageQuery(offset))
+		if err := queryError(resp); err != nil {
+			return nil, 0, err
+		}
+		items, err := unmarshalAnys(resp.GetData(), func() *apitraffic.RouteRule { return &apitraffic.RouteRule{} })
+		return items, resp.GetAmount().GetValue(), err
+	},
+	prepare: func(current, desired *apitraffic.RouteRule) {
+		desired.Id = current.GetId()
+	},
+	createFn: func(ctx context.Context, r *Reconciler, desired *apitraffic.RouteRule) error {
+		return batchWriteError(r.naming.CreateRoutingConfigsV2(ctx, []*apitraffic.RouteRule{desired}))
+	},
+	updateFn: func(ctx context.Context, r *Reconciler, current, desired *apitraffic.RouteRule) error {
+		if err := batchWriteError(r.naming.UpdateRoutingConfigsV2(ctx,
+			[]*apitraffic.RouteRule{desired})); err != nil {
+			return err
+		}
+		// 更新路由规则时不会修改启用状态
+		if current.GetEnable() == desired.GetEnable() {
+			return nil
+		}
+		return batchWriteError(r.naming.EnableRoutings(ctx, []*apitraffic.RouteRule{{
+			Id:     desired.GetId(),
+			Enable: desired.GetEnable(),
+		}}))
+	},
+	deleteFn: func(ctx context.Context, r *Reconciler, current *apitraffic.RouteRule) error {
+		return batchWriteError(r.naming.DeleteRoutingConfigsV2(ctx, []*apitraffic.RouteRule{{Id: current.GetId()}}))
+	},
+}
+
+var rateLimitKind = &ruleKind[*apitraffic.Rule]{
+	name:    KindRateLimit,
+	newMsg:  func() *apitraffic.Rule { return &apitraffic.Rule{} },
+	ignored: append([]string{"service_token", "labels"}, serverFields...),
+	defaults: func(rule *apitraffic.Rule) {
+		if rule.GetAction().GetValue() == "" {
+			rule.Action = utils.NewStringValue(defaultRateLimitAction)
+		}
+	},
+	key: func(rule *apitraffic.Rule) (string, error) {
+		if rule.GetName().GetValue() == "" || rule.GetNamespace().GetValue() == "" ||
+			rule.GetService().GetValue() == "" {
+			return "", errors.New("name, namespace and service are required")
+		}
+		return rule.GetNamespace().GetValue() + "/" + rule.GetService().GetValue() + "/" +
+			rule.GetName().GetValue(), nil
+	},
+	listPage: func(ctx context.Context, r *Reconciler, offset int) ([]*apitraffic.Rule, uint32, error) {
+		resp := r.naming.GetRateLimits(ctx, pageQuery(offset))
+		if err := queryError(resp); err != nil {
+			return nil, 0, err
+		}
+		return resp.GetRateLimits(), resp.GetAmount().GetValue(), nil
+	},
+	prepare: func(current, desired *apitraffic.Rule) {
+		desired.Id = current.GetId()
+		desired.ServiceToken = current.GetServiceToken()
+	},
+	createFn: func(ctx context.Context, r *Reconciler, desired *apitraffic.Rule) error {
+		return batchWriteError(r.naming.CreateRateLimits(ctx, []*apitraffic.Rule{desired}))
+	},
+	updateFn: func(ctx context.Context, r *Reconciler, _, desired *apitraffic.Rule) error {
+		return batchWriteError(r.naming.UpdateRateLimits(ctx, []*apitraffic.Rule{desired}))
+	},
+	deleteFn: func(ctx context.Context, r *Reconciler, current *apitraffic.Rule) error {
+		return batchWriteError(r.naming.DeleteRateLimits(ctx, []*apitraffic.Rule{{
+			Id:           current.GetId(),
+			ServiceToken: current.GetServiceToken(),
+		}}))
+	},
+}
+
+var circuitBreakerRuleKind = &ruleKind[*apifault.CircuitBreakerRule]{
+	name:    KindCircuitBreakerRule,
+	newMsg:  func() *apifault.CircuitBreakerRule { return &apifault.CircuitBreakerRule{} },
+	ignored: serverFields,
+	key: func(rule *apifault.CircuitBreakerRule) (string, error) {
+		if rule.GetName() == "" || rule.GetNamespace() == "" {
+			return "", errors.New("name and namespace are required")
+		}
+		return rule.GetNamespace() + "/" + rule.GetName(), nil
+	},
+	listPage: func(ctx context.Context, r *Reconciler, offset int) ([]*apifault.CircuitBreakerRule, uint32, error) {
+		// 熔断规则的缓存按照服务组织，直接分页查询全部规则
+		resp := r.naming.GetCircuitBreakerRules(ctx, pageQuery(offset))
+		if err := queryError(resp); err != nil {
+			return nil, 0, err
+		}
+		items, err := unmarshalAnys(resp.GetData(),
+			func() *apifault.CircuitBreakerRule { return &apifault.CircuitBreakerRule{} })
+		return items, resp.GetAmount().GetValue(), err
+	},
+	prepare: func(current, desired *apifault.CircuitBreakerRule) {
+		desired.Id = current.GetId()
+	},
+	createFn: func(ctx context.Context, r *Reconciler, desired *apifault.CircuitBreakerRule) error {
+		return batchWriteError(r.naming.CreateCircuitBreakerRules(ctx, []*apifault.CircuitBreakerRule{desired}))
+	},
+	updateFn: func(ctx context.Context, r *Reconciler, _, desired *apifault.CircuitBreakerRule) error {
+		return batchWriteError(r.naming.UpdateCircuitBreakerRules(ctx, []*apifault.CircuitBreakerRule{desired}))
+	},
+	deleteFn: func(ctx context.Context, r *Reconciler, current *apifault.CircuitBreakerRule) error {
+		return batchWriteError(r.naming.DeleteCircuitBreakerRules(ctx,
+			[]*apifault.CircuitBreakerRule{{Id: current.GetId()}}))
+	},
+}
+
+// configFileKind 配置文件以当前生效的发布内容为准，应用时创建或者更新配置文件并发布
+type configFileKind struct{}
+
+func (k *configFileKind) kind() string {
+	return KindConfigFile
+}
+
+// configFileState 参与比较的配置文件内容
+type configFileState struct {
+	Namespace string            `json:"namespace"`
+	Group     string            `json:"group"`
+	Name      string            `json:"name"`
+	Format    string            `json:"format"`
+	Md5       string            `json:"md5"`
+	Tags      map[string]string `json:"tags"`
+}
+
+func (k *configFileKind) decode(spec []byte) (*resource, error) {
+	file := &apiconfig.ConfigFile{}
+	if err := jsonpb.Unmarshal(bytes.NewReader(spec), file); err != nil {
+		return nil, err
+	}
+	if file.GetNamespace().GetValue() == "" || file.GetGroup().GetValue() == "" ||
+		file.GetName().GetValue() == "" {
+		return nil, errors.New("namespace, group and name are required")
+	}
+	if file.GetEncrypted().GetValue() {
+		return nil, errors.New("encrypted config file is not supported")
+	}
+	tags := model.ToTagMap(file.GetTags())
+	hash, err := hashValue(&configFileState{
+		Namespace: file.GetNamespace().GetValue(),
+		Group:     file.GetGroup().GetValue(),
+		Name:      file.GetName().GetValue(),
+		Format:    file.GetFormat().GetValue(),
+		Md5:       config.CalMd5(file.GetContent().GetValue()),
+		Tags:      tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resource{key: configFileKey(file.GetNamespace().GetValue(), file.GetGroup().GetValue(),
+		file.GetName().GetValue()), msg: file, hash: hash}, nil
+}
+
+func (k *configFileKind) list(ctx context.Context, r *Reconciler) (map[string]*resource, error) {
+	ret := map[string]*resource{}
+	if r.config == nil {
+		return ret, nil
+	}
+	// 只关心声明以及受管理的配置文件，从缓存中读取生效的发布
+	keys := r.keysOf(KindConfigFile)
+	sort.Strings(keys)
+	for _, key := range keys {
+		namespace, group, name, ok := splitConfigFileKey(key)
+		if !ok {
+			continue
+		}
+		release := r.cacheMgn.ConfigFile().GetActiveRelease(namespace, group, name)
+		if release == nil {
+			continue
+		}
+		tags := map[string]string{}
+		for k, v := range release.Metadata {
+			if !strings.HasPrefix(k, internalMetaPrefix) {
+				tags[k] = v
+			}
+		}
+		hash, err := hashValue(&configFileState{
+			Namespace: namespace,
+			Group:     group,
+			Name:      name,
+			Format:    release.Format,
+			Md5:       release.Md5,
+			Tags:      tags,
+		})
+		if err != nil {
+			return nil, err
+		}
+		ret[key] = &resource{key: key, hash: hash, msg: &apiconfig.ConfigFile{
+			Namespace: utils.NewStringValue(namespace),
+			Group:     utils.NewStringValue(group),
+			Name:      utils.NewStringValue(name),
+		}}
+	}
+	return ret, nil
+}
+
+func (k *configFileKind) create(ctx context.Context, r *Reconciler, desired *resource) error {
+	return k.publish(ctx, r, desired)
+}
+
+func (k *configFileKind) update(ctx context.Context, r *Reconciler, _, desired *resource) error {
+	return k.publish(ctx, r, desired)
+}
+
+func (k *configFileKind) publish(ctx context.Context, r *Reconciler, desired *resource) error {
+	if r.config == nil {
+		return errors.New("config center is not available")
+	}
+	file := desired.msg.(*apiconfig.ConfigFile)
+	resp := r.config.UpsertAndReleaseConfigFile(ctx, &apiconfig.ConfigFilePublishInfo{
+		Namespace:          file.GetNamespace(),
+		Group:              file.GetGroup(),
+		FileName:           file.GetName(),
+		Content:            file.GetContent(),
+		Comment:            file.GetComment(),
+		Format:             file.GetFormat(),
+		Tags:               file.GetTags(),
+		ReleaseDescription: utils.NewStringValue("gitops"),
+	})
+	return configError(resp)
+}
+
+func (k *configFileKind) delete(ctx context.Context, r *Reconciler, current *resource) error {
+	if r.config == nil {
+		return errors.New("config center is not available")
+	}
+	return configError(r.config.DeleteConfigFile(ctx, current.msg.(*apiconfig.ConfigFile)))
+}
+
+func configError(resp *apiconfig.ConfigResponse) error {
+	if resp.GetCode().GetValue() == uint32(apimodel.Code_ExecuteSuccess) {
+		return nil
+	}
+	return fmt.Errorf("code %d: %s", resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+}
+
+func configFileKey(namespace, group, name string) string {
+	return namespace + "/" + group + "/" + name
+}
+
+func splitConfigFileKey(key string) (string, string, string, bool) {
+	// 配置文件名称中可以包含 /，命名空间以及分组中不可以
+	parts := strings.SplitN(key, "/", 3)
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
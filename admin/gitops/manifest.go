@@ -0,0 +1,219 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package gitops
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+const (
+	// APIVersion 声明式资源清单的版本
+	APIVersion = "polaris.io/v1"
+
+	// KindRouteRule 路由规则
+	KindRouteRule = "RouteRule"
+	// KindRateLimit 限流规则
+	KindRateLimit = "RateLimit"
+	// KindCircuitBreakerRule 熔断规则
+	KindCircuitBreakerRule = "CircuitBreakerRule"
+	// KindConfigFile 配置文件，应用时会同时发布
+	KindConfigFile = "ConfigFile"
+
+	// maxManifestSize 归档中单个清单文件的最大长度
+	maxManifestSize = 16 * 1024 * 1024
+	// maxArchiveSize 归档中全部清单文件解压后的最大总长度，避免压缩炸弹
+	maxArchiveSize = 64 * 1024 * 1024
+)
+
+// Manifest 一个声明式资源，格式如下
+//
+//	apiVersion: polaris.io/v1
+//	kind: RateLimit
+//	spec:
+//	  name: ...
+type Manifest struct {
+	APIVersion string          `json:"apiVersion"`
+	Kind       string          `json:"kind"`
+	Spec       json.RawMessage `json:"spec"`
+	// Source 资源所在的文件以及文档序号，用于错误提示
+	Source string `json:"-"`
+}
+
+// ParseArchive 解析 zip 或者 tar.gz 归档中全部 .yaml/.yml 文件的资源清单
+func ParseArchive(data []byte) ([]*Manifest, error) {
+	var (
+		files = map[string][]byte{}
+		total int
+	)
+	addFile := func(name string, content []byte) error {
+		total += len(content)
+		if total > maxArchiveSize {
+			return fmt.Errorf("manifest files exceed %d bytes in total", maxArchiveSize)
+		}
+		files[name] = content
+		return nil
+	}
+	switch {
+	case bytes.HasPrefix(data, []byte("PK")):
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid zip archive: %w", err)
+		}
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() || !isManifestFile(f.Name) {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			content, err := readLimited(rc)
+			_ = rc.Close()
+			if err != nil {
+				return nil, fmt.Errorf("read %s: %w", f.Name, err)
+			}
+			if err := addFile(f.Name, content); err != nil {
+				return nil, err
+			}
+		}
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		gr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tar.gz archive: %w", err)
+		}
+		tr := tar.NewReader(gr)
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid tar.gz archive: %w", err)
+			}
+			if hdr.Typeflag != tar.TypeReg || !isManifestFile(hdr.Name) {
+				continue
+			}
+			content, err := readLimited(tr)
+			if err != nil {
+				return nil, fmt.Errorf("read %s: %w", hdr.Name, err)
+			}
+			if err := addFile(hdr.Name, content); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, errors.New("unsupported archive, expect zip or tar.gz")
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	manifests := make([]*Manifest, 0, len(names))
+	for _, name := range names {
+		items, err := ParseManifests(name, files[name])
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, items...)
+	}
+	return manifests, nil
+}
+
+// ParseManifests 解析一个可能包含多个文档（以 --- 分隔）的 YAML 文件
+func ParseManifests(source string, data []byte) ([]*Manifest, error) {
+	manifests := make([]*Manifest, 0, 1)
+	for i, doc := range splitDocuments(data) {
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		ref := fmt.Sprintf("%s#%d", source, i)
+		content, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ref, err)
+		}
+		if string(content) == "null" {
+			// 只有注释的文档
+			continue
+		}
+		item := &Manifest{}
+		if err := json.Unmarshal(content, item); err != nil {
+			return nil, fmt.Errorf("%s: %w", ref, err)
+		}
+		if item.APIVersion != APIVersion {
+			return nil, fmt.Errorf("%s: unsupported apiVersion `%s`, expect %s", ref, item.APIVersion, APIVersion)
+		}
+		if len(item.Spec) == 0 || string(item.Spec) == "null" {
+			return nil, fmt.Errorf("%s: spec is required", ref)
+		}
+		item.Source = ref
+		manifests = append(manifests, item)
+	}
+	return manifests, nil
+}
+
+// splitDocuments 按照单独一行的 --- 拆分 YAML 文档
+func splitDocuments(data []byte) [][]byte {
+	var (
+		docs    [][]byte
+		current bytes.Buffer
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxManifestSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimRight(line, " \t\r") == "---" {
+			docs = append(docs, append([]byte(nil), current.Bytes()...))
+			current.Reset()
+			continue
+		}
+		current.WriteString(line)
+		current.WriteByte('\n')
+	}
+	return append(docs, current.Bytes())
+}
+
+func isManifestFile(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return (ext == ".yaml" || ext == ".yml") && !strings.HasPrefix(path.Base(name), ".")
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxManifestSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxManifestSize {
+		return nil, fmt.Errorf("file exceeds %d bytes", maxManifestSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,282 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+// Package gitops 将 git 中声明的治理规则以及配置文件同步到北极星，并检测手工修改造成的漂移
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/cache"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/config"
+	"github.com/polarismesh/polaris/service"
+)
+
+// Action 对资源执行的操作
+type Action string
+
+const (
+	// ActionCreate 创建资源
+	ActionCreate Action = "create"
+	// ActionUpdate 更新资源
+	ActionUpdate Action = "update"
+	// ActionDelete 删除资源
+	ActionDelete Action = "delete"
+	// ActionNone 资源与声明一致
+	ActionNone Action = "none"
+	// ActionConflict 已经存在不受管理的同名资源，并且没有要求接管
+	ActionConflict Action = "conflict"
+)
+
+// Change 一个资源的变更
+type Change struct {
+	Kind   string `json:"kind"`
+	Key    string `json:"key"`
+	Action Action `json:"action"`
+	// Drift 受管理的资源在 gitops 之外被修改或者删除
+	Drift  bool   `json:"drift"`
+	Reason string `json:"reason,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Plan 一次同步的执行计划，非 dry-run 时包含每个变更的执行结果
+type Plan struct {
+	DryRun  bool      `json:"dryRun"`
+	Changes []*Change `json:"changes"`
+}
+
+// Failed 执行失败的变更数量
+func (p *Plan) Failed() int {
+	cnt := 0
+	for _, change := range p.Changes {
+		if change.Error != "" {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// kinds 支持的资源类型，按照执行顺序排列
+var kinds = []kindHandler{
+	&configFileKind{},
+	routeRuleKind,
+	rateLimitKind,
+	circuitBreakerRuleKind,
+}
+
+// Reconciler 计算声明的资源与当前资源的差异并执行变更
+type Reconciler struct {
+	// lock 同一时间只允许执行一次同步
+	lock     sync.Mutex
+	naming   service.DiscoverServer
+	config   config.ConfigCenterServer
+	cacheMgn *cache.CacheManager
+	inv      *inventoryStore
+
+	// 以下字段只在一次同步过程中有效
+	desired map[string]map[string]*resource
+	managed *inventory
+}
+
+// NewReconciler 创建同步器，configServer 同时用于保存受管理资源的清单
+func NewReconciler(namingServer service.DiscoverServer, configServer config.ConfigCenterServer,
+	cacheMgn *cache.CacheManager) *Reconciler {
+	return &Reconciler{
+		naming:   namingServer,
+		config:   configServer,
+		cacheMgn: cacheMgn,
+		inv:      &inventoryStore{config: configServer},
+	}
+}
+
+// Reconcile 比较资源清单与当前资源，dryRun 为 true 时只返回执行计划
+// 只会删除之前由 gitops 创建或者接管的资源，手工创建的同名资源只有在 adopt 为 true 时才会被接管，
+// 否则作为冲突返回并且不做任何修改
+func (r *Reconciler) Reconcile(ctx context.Context, manifests []*Manifest, dryRun, adopt bool) (*Plan, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	desired, err := decodeManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
+	managed, err := r.inv.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.desired, r.managed = desired, managed
+	defer func() {
+		r.desired, r.managed = nil, nil
+	}()
+
+	plan := &Plan{DryRun: dryRun, Changes: []*Change{}}
+	type step struct {
+		handler kindHandler
+		change  *Change
+		current *resource
+		desired *resource
+	}
+	var steps, deletes []*step
+	for _, handler := range kinds {
+		current, err := handler.list(ctx, r)
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", handler.kind(), err)
+		}
+		for _, key := range r.keysOf(handler.kind()) {
+			d, c := desired[handler.kind()][key], current[key]
+			change := diff(handler.kind(), key, d, c, managed.get(handler.kind(), key), adopt)
+			plan.Changes = append(plan.Changes, change)
+			item := &step{handler: handler, change: change, current: c, desired: d}
+			if change.Action == ActionDelete {
+				deletes = append(deletes, item)
+			} else {
+				steps = append(steps, item)
+			}
+		}
+	}
+	if dryRun {
+		return plan, nil
+	}
+
+	// 先创建以及更新，最后删除不再声明的资源
+	for _, item := range append(steps, deletes...) {
+		kind, key := item.handler.kind(), item.change.Key
+		var err error
+		switch item.change.Action {
+		case ActionCreate:
+			err = item.handler.create(ctx, r, item.desired)
+		case ActionUpdate:
+			err = item.handler.update(ctx, r, item.current, item.desired)
+		case ActionDelete:
+			err = item.handler.delete(ctx, r, item.current)
+		case ActionConflict:
+			continue
+		}
+		if err != nil {
+			item.change.Error = err.Error()
+			log.Error("[GitOps] apply change fail", utils.RequestID(ctx), zap.String("kind", kind),
+				zap.String("key", key), zap.String("action", string(item.change.Action)), zap.Error(err))
+			continue
+		}
+		if item.desired != nil {
+			managed.set(kind, key, item.desired.hash)
+		} else {
+			managed.remove(kind, key)
+		}
+	}
+	if err := r.inv.save(ctx, managed); err != nil {
+		return plan, fmt.Errorf("save inventory: %w", err)
+	}
+	return plan, nil
+}
+
+// diff 计算单个资源的变更，recorded 为上次应用时记录的摘要，为空表示资源不受管理
+func diff(kind, key string, desired, current *resource, recorded string, adopt bool) *Change {
+	change := &Change{Kind: kind, Key: key, Action: ActionNone}
+	managed := recorded != ""
+	switch {
+	case desired != nil && current == nil:
+		change.Action = ActionCreate
+		if managed {
+			change.Drift = true
+			change.Reason = "deleted outside gitops"
+		}
+	case desired != nil:
+		switch {
+		case !managed && !adopt:
+			change.Action = ActionConflict
+			change.Error = "resource already exists and is not managed by gitops"
+		case !managed && current.hash == desired.hash:
+			change.Reason = "adopt existing resource"
+		case !managed:
+			change.Action = ActionUpdate
+			change.Reason = "adopt existing resource"
+		case current.hash == desired.hash:
+		default:
+			change.Action = ActionUpdate
+			if current.hash != recorded {
+				change.Drift = true
+				change.Reason = "modified outside gitops"
+			} else {
+				change.Reason = "manifest changed"
+			}
+		}
+	case current != nil:
+		change.Action = ActionDelete
+		change.Reason = "removed from manifests"
+		if current.hash != recorded {
+			change.Drift = true
+			change.Reason = "removed from manifests, modified outside gitops"
+		}
+	default:
+		// 受管理的资源已经在 gitops 之外被删除
+		change.Drift = true
+		change.Reason = "deleted outside gitops"
+	}
+	return change
+}
+
+// keysOf 声明的以及受管理的资源，已排序
+func (r *Reconciler) keysOf(kind string) []string {
+	keys := map[string]struct{}{}
+	for key := range r.desired[kind] {
+		keys[key] = struct{}{}
+	}
+	for key := range r.managed.Resources[kind] {
+		keys[key] = struct{}{}
+	}
+	ret := make([]string, 0, len(keys))
+	for key := range keys {
+		ret = append(ret, key)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func decodeManifests(manifests []*Manifest) (map[string]map[string]*resource, error) {
+	handlers := make(map[string]kindHandler, len(kinds))
+	ret := make(map[string]map[string]*resource, len(kinds))
+	for _, handler := range kinds {
+		handlers[handler.kind()] = handler
+		ret[handler.kind()] = map[string]*resource{}
+	}
+	sources := map[string]string{}
+	for _, item := range manifests {
+		handler, ok := handlers[item.Kind]
+		if !ok {
+			return nil, fmt.Errorf("%s: unsupported kind `%s`", item.Source, item.Kind)
+		}
+		res, err := handler.decode(item.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", item.Source, err)
+		}
+		id := item.Kind + "/" + res.key
+		if source, ok := sources[id]; ok {
+			return nil, fmt.Errorf("%s: duplicate %s `%s`, already declared in %s", item.Source, item.Kind,
+				res.key, source)
+		}
+		sources[id] = item.Source
+		ret[item.Kind][res.key] = res
+	}
+	return ret, nil
+}
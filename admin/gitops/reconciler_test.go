@@ -0,0 +1,228 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package gitops
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	apitraffic "github.com/polarismesh/specification/source/go/api/v1/traffic_manage"
+	"github.com/stretchr/testify/assert"
+
+	_ "github.com/polarismesh/polaris/cache"
+	"github.com/polarismesh/polaris/common/utils"
+	_ "github.com/polarismesh/polaris/plugin/healthchecker/memory"
+	_ "github.com/polarismesh/polaris/plugin/history/logger"
+	_ "github.com/polarismesh/polaris/plugin/password"
+	_ "github.com/polarismesh/polaris/store/boltdb"
+	testsuit "github.com/polarismesh/polaris/test/suit"
+)
+
+const (
+	testRouteRule = `
+apiVersion: polaris.io/v1
+kind: RouteRule
+spec:
+  name: gitops-route
+  namespace: default
+  enable: true
+  routing_policy: RulePolicy
+  routing_config:
+    "@type": type.googleapis.com/v1.RuleRoutingConfig
+    rules:
+    - name: rule
+      sources:
+      - service: "*"
+        namespace: "*"
+      destinations:
+      - service: gitops-svc
+        namespace: default
+        weight: 100
+`
+	testRateLimit = `
+apiVersion: polaris.io/v1
+kind: RateLimit
+spec:
+  name: gitops-limit
+  namespace: default
+  service: gitops-svc
+  type: GLOBAL
+  amounts:
+  - maxAmount: 100
+    validDuration: 1s
+`
+	testConfigFile = `
+apiVersion: polaris.io/v1
+kind: ConfigFile
+spec:
+  namespace: default
+  group: gitops
+  name: app.yaml
+  format: yaml
+  content: "a: 1"
+  tags:
+  - key: team
+    value: infra
+`
+)
+
+func zipManifests(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		assert.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func changesOf(plan *Plan) map[string]*Change {
+	ret := map[string]*Change{}
+	for _, change := range plan.Changes {
+		ret[change.Kind+"/"+change.Key] = change
+	}
+	return ret
+}
+
+func TestParseManifests(t *testing.T) {
+	manifests, err := ParseManifests("rules.yaml", []byte(testRouteRule+"---\n# comment only\n---"+testRateLimit))
+	assert.NoError(t, err)
+	assert.Len(t, manifests, 2)
+	assert.Equal(t, KindRouteRule, manifests[0].Kind)
+	assert.Equal(t, KindRateLimit, manifests[1].Kind)
+
+	_, err = ParseManifests("bad.yaml", []byte("apiVersion: v2\nkind: RouteRule\nspec: {}\n"))
+	assert.Error(t, err)
+
+	manifests, err = ParseArchive(zipManifests(t, map[string]string{
+		"rules/route.yaml": testRouteRule,
+		"config/app.yml":   testConfigFile,
+		"README.md":        "ignored",
+	}))
+	assert.NoError(t, err)
+	assert.Len(t, manifests, 2)
+
+	// 解压后的总长度超过限制
+	files := map[string]string{}
+	for i := 0; i <= maxArchiveSize/maxManifestSize; i++ {
+		files[fmt.Sprintf("big-%d.yaml", i)] = strings.Repeat("#", maxManifestSize)
+	}
+	_, err = ParseArchive(zipManifests(t, files))
+	assert.ErrorContains(t, err, "in total")
+
+	// 同一个资源不能重复声明
+	manifests, err = ParseManifests("dup.yaml", []byte(testRateLimit+"---"+testRateLimit))
+	assert.NoError(t, err)
+	_, err = decodeManifests(manifests)
+	assert.Error(t, err)
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	discoverSuit := &testsuit.DiscoverTestSuit{}
+	if err := discoverSuit.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(discoverSuit.Destroy)
+
+	ctx := discoverSuit.DefaultCtx
+	r := NewReconciler(discoverSuit.DiscoverServer(), discoverSuit.ConfigServer(), discoverSuit.CacheMgr())
+	manifests, err := ParseManifests("all.yaml", []byte(testRouteRule+"---"+testRateLimit+"---"+testConfigFile))
+	assert.NoError(t, err)
+
+	plan, err := r.Reconcile(ctx, manifests, true, true)
+	assert.NoError(t, err)
+	assert.Len(t, plan.Changes, 3)
+	for _, change := range plan.Changes {
+		assert.Equal(t, ActionCreate, change.Action, change.Key)
+	}
+
+	plan, err = r.Reconcile(ctx, manifests, false, true)
+	assert.NoError(t, err)
+	assert.Zero(t, plan.Failed(), plan.Changes)
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+
+	// 再次同步时没有任何变更
+	plan, err = r.Reconcile(ctx, manifests, true, true)
+	assert.NoError(t, err)
+	for _, change := range plan.Changes {
+		assert.Equal(t, ActionNone, change.Action, change.Key)
+		assert.False(t, change.Drift, change.Key)
+	}
+
+	// 手工修改受管理的规则
+	resp := discoverSuit.DiscoverServer().GetRateLimits(ctx, map[string]string{"name": "gitops-limit"})
+	assert.Len(t, resp.GetRateLimits(), 1)
+	rule := resp.GetRateLimits()[0]
+	rule.Amounts[0].MaxAmount = utils.NewUInt32Value(1)
+	assert.Zero(t, batchWriteError(discoverSuit.DiscoverServer().UpdateRateLimits(ctx, []*apitraffic.Rule{rule})))
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+
+	plan, err = r.Reconcile(ctx, manifests, false, true)
+	assert.NoError(t, err)
+	change := changesOf(plan)[KindRateLimit+"/default/gitops-svc/gitops-limit"]
+	assert.Equal(t, ActionUpdate, change.Action)
+	assert.True(t, change.Drift)
+	assert.Empty(t, change.Error)
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+
+	resp = discoverSuit.DiscoverServer().GetRateLimits(ctx, map[string]string{"name": "gitops-limit"})
+	assert.Equal(t, uint32(100), resp.GetRateLimits()[0].GetAmounts()[0].GetMaxAmount().GetValue())
+
+	// 从清单中移除的资源会被删除
+	manifests, err = ParseManifests("all.yaml", []byte(testRouteRule))
+	assert.NoError(t, err)
+	plan, err = r.Reconcile(ctx, manifests, false, true)
+	assert.NoError(t, err)
+	changes := changesOf(plan)
+	assert.Equal(t, ActionNone, changes[KindRouteRule+"/default/gitops-route"].Action)
+	assert.Equal(t, ActionDelete, changes[KindRateLimit+"/default/gitops-svc/gitops-limit"].Action)
+	assert.Equal(t, ActionDelete, changes[KindConfigFile+"/default/gitops/app.yaml"].Action)
+	assert.Zero(t, plan.Failed(), plan.Changes)
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+
+	resp = discoverSuit.DiscoverServer().GetRateLimits(ctx, map[string]string{"name": "gitops-limit"})
+	assert.Empty(t, resp.GetRateLimits())
+	// 手工创建的同名资源不会被自动接管
+	rule.Id = nil
+	rule.Revision = nil
+	assert.Zero(t, batchWriteError(discoverSuit.DiscoverServer().CreateRateLimits(ctx, []*apitraffic.Rule{rule})))
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+
+	manifests, err = ParseManifests("all.yaml", []byte(testRouteRule+"---"+testRateLimit))
+	assert.NoError(t, err)
+	plan, err = r.Reconcile(ctx, manifests, false, false)
+	assert.NoError(t, err)
+	change = changesOf(plan)[KindRateLimit+"/default/gitops-svc/gitops-limit"]
+	assert.Equal(t, ActionConflict, change.Action)
+	assert.NotEmpty(t, change.Error)
+	assert.Equal(t, 1, plan.Failed(), plan.Changes)
+
+	plan, err = r.Reconcile(ctx, manifests, false, true)
+	assert.NoError(t, err)
+	change = changesOf(plan)[KindRateLimit+"/default/gitops-svc/gitops-limit"]
+	assert.Equal(t, ActionUpdate, change.Action)
+	assert.Zero(t, plan.Failed(), plan.Changes)
+}
+
@@ -76,3 +76,77 @@ func (job *cleanConfigFileHistoryJob) interval() time.Duration {
 
 func (job *cleanConfigFileHistoryJob) clear() {
 }
+
+const (
+	// 默认保存操作记录天数
+	defaultOperationRecordRetentionDays = 30
+	// 最少保存的天数
+	minRetentionDays = 1
+)
+
+// retentionDuration 保存天数对应的时长
+func retentionDuration(days int) time.Duration {
+	return time.Duration(days) * 24 * time.Hour
+}
+
+type CleanOperationRecordJobConfig struct {
+	// RetentionDays 保存的天数
+	RetentionDays int    `mapstructure:"retentionDays"`
+	BatchSize     uint64 `mapstructure:"batchSize"`
+}
+
+type cleanOperationRecordJob struct {
+	cfg     *CleanOperationRecordJobConfig
+	storage store.Store
+}
+
+func (job *cleanOperationRecordJob) init(raw map[string]interface{}) error {
+	cfg := &CleanOperationRecordJobConfig{
+		RetentionDays: defaultOperationRecordRetentionDays,
+		BatchSize:     1000,
+	}
+	decodeConfig := &mapstructure.DecoderConfig{
+		Result: cfg,
+	}
+	decoder, err := mapstructure.NewDecoder(decodeConfig)
+	if err != nil {
+		log.Errorf("[Maintain][Job][cleanOperationRecordJob] new config decoder err: %v", err)
+		return err
+	}
+	if err = decoder.Decode(raw); err != nil {
+		log.Errorf("[Maintain][Job][cleanOperationRecordJob] parse config err: %v", err)
+		return err
+	}
+	if cfg.RetentionDays < minRetentionDays {
+		cfg.RetentionDays = minRetentionDays
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = 1000
+	}
+	job.cfg = cfg
+	return nil
+}
+
+func (job *cleanOperationRecordJob) execute() {
+	endTime := time.Now().Add(-1 * retentionDuration(job.cfg.RetentionDays))
+	var total uint64
+	for {
+		cleaned, err := job.storage.CleanOperationRecords(endTime, job.cfg.BatchSize)
+		if err != nil {
+			log.Errorf("[Maintain][Job][cleanOperationRecordJob] execute err: %v", err)
+			return
+		}
+		total += cleaned
+		if cleaned < job.cfg.BatchSize {
+			break
+		}
+	}
+	log.Infof("[Maintain][Job][cleanOperationRecordJob] clean %d operation records before %v", total, endTime)
+}
+
+func (job *cleanOperationRecordJob) interval() time.Duration {
+	return time.Minute
+}
+
+func (job *cleanOperationRecordJob) clear() {
+}
@@ -0,0 +1,71 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package job
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_CleanOperationRecordJobConfigInit(t *testing.T) {
+	job := cleanOperationRecordJob{}
+	if err := job.init(map[string]interface{}{"retentionDays": 30}); err != nil {
+		t.Errorf("init cleanOperationRecordJob config, err: %v", err)
+	}
+	if job.cfg.RetentionDays != 30 {
+		t.Errorf("init cleanOperationRecordJob config. expect: 30, actual: %d", job.cfg.RetentionDays)
+	}
+	if retentionDuration(job.cfg.RetentionDays) != 30*24*time.Hour {
+		t.Errorf("retention duration expect: %s, actual: %s", 30*24*time.Hour,
+			retentionDuration(job.cfg.RetentionDays))
+	}
+
+	if err := job.init(map[string]interface{}{"retentionDays": 0}); err != nil {
+		t.Errorf("init cleanOperationRecordJob config, err: %v", err)
+	}
+	if job.cfg.RetentionDays != minRetentionDays {
+		t.Errorf("init cleanOperationRecordJob config. expect: %d, actual: %d",
+			minRetentionDays, job.cfg.RetentionDays)
+	}
+}
+
+func Test_CleanOperationRecordJobConfigInitErr(t *testing.T) {
+	job := cleanOperationRecordJob{}
+	if err := job.init(map[string]interface{}{"retentionDays": "720h"}); err == nil {
+		t.Errorf("init cleanOperationRecordJob config should err")
+	}
+}
+
+func Test_CleanConfigFileHistoryJobConfigInit(t *testing.T) {
+	job := cleanConfigFileHistoryJob{}
+	if err := job.init(map[string]interface{}{"retentionDays": "168h"}); err != nil {
+		t.Errorf("init cleanConfigFileHistoryJob config, err: %v", err)
+	}
+	if job.cfg.RetentionDays != 168*time.Hour {
+		t.Errorf("init cleanConfigFileHistoryJob config. expect: %s, actual: %s", 168*time.Hour,
+			job.cfg.RetentionDays)
+	}
+
+	if err := job.init(map[string]interface{}{"retentionDays": "10s"}); err != nil {
+		t.Errorf("init cleanConfigFileHistoryJob config, err: %v", err)
+	}
+	if job.cfg.RetentionDays != time.Minute {
+		t.Errorf("init cleanConfigFileHistoryJob config. expect: %s, actual: %s", time.Minute,
+			job.cfg.RetentionDays)
+	}
+}
@@ -55,6 +55,10 @@ func NewMaintainJobs(namingServer service.DiscoverServer, cacheMgn *cache.CacheM
 				storage: storage},
 			"CleanConfigReleaseHistory": &cleanConfigFileHistoryJob{
 				storage: storage},
+			"CleanOperationHistory": &cleanOperationRecordJob{
+				storage: storage},
+			"RewrapConfigFileDataKey": &rewrapConfigFileDataKeyJob{
+				storage: storage},
 		},
 		startedJobs: map[string]maintainJob{},
 		storage:     storage,
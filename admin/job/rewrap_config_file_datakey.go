@@ -0,0 +1,264 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package job
+
+import (
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/store"
+)
+
+type RewrapConfigFileDataKeyJobConfig struct {
+	Interval  time.Duration `mapstructure:"interval"`
+	BatchSize uint32        `mapstructure:"batchSize"`
+}
+
+// rewrapConfigFileDataKeyJob 主密钥轮转后, 使用新的主密钥重新包装配置文件的数据密钥,
+// 配置内容以及数据密钥本身保持不变, 因此客户端无感知
+type rewrapConfigFileDataKeyJob struct {
+	cfg           *RewrapConfigFileDataKeyJobConfig
+	storage       store.Store
+	cryptoManager plugin.CryptoManager
+}
+
+func (job *rewrapConfigFileDataKeyJob) init(raw map[string]interface{}) error {
+	cfg := &RewrapConfigFileDataKeyJobConfig{
+		Interval:  10 * time.Minute,
+		BatchSize: 100,
+	}
+	decodeConfig := &mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     cfg,
+	}
+	decoder, err := mapstructure.NewDecoder(decodeConfig)
+	if err != nil {
+		log.Errorf("[Maintain][Job][RewrapConfigFileDataKey] new config decoder err: %v", err)
+		return err
+	}
+	if err = decoder.Decode(raw); err != nil {
+		log.Errorf("[Maintain][Job][RewrapConfigFileDataKey] parse config err: %v", err)
+		return err
+	}
+	if cfg.Interval < time.Minute {
+		cfg.Interval = time.Minute
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = 100
+	}
+	job.cfg = cfg
+	if job.cryptoManager == nil {
+		job.cryptoManager = plugin.GetCryptoManager()
+	}
+	return nil
+}
+
+func (job *rewrapConfigFileDataKeyJob) execute() {
+	wrappers := map[string]plugin.KeyWrapper{}
+	for _, name := range job.cryptoManager.GetCryptoAlgoNames() {
+		crypto, err := job.cryptoManager.GetCrypto(name)
+		if err != nil {
+			continue
+		}
+		if wrapper, ok := crypto.(plugin.KeyWrapper); ok {
+			wrappers[name] = wrapper
+		}
+	}
+	if len(wrappers) == 0 {
+		return
+	}
+
+	// 配置文件、配置发布(含灰度发布)以及发布历史都保存了数据密钥, 需要全部重新包装后才能下线旧的主密钥
+	if rewrapped := job.rewrapConfigFiles(wrappers); rewrapped > 0 {
+		log.Infof("[Maintain][Job][RewrapConfigFileDataKey] rewrap %d config files data key", rewrapped)
+	}
+	if rewrapped := job.rewrapReleases(wrappers); rewrapped > 0 {
+		log.Infof("[Maintain][Job][RewrapConfigFileDataKey] rewrap %d config releases data key", rewrapped)
+	}
+	if rewrapped := job.rewrapHistories(wrappers); rewrapped > 0 {
+		log.Infof("[Maintain][Job][RewrapConfigFileDataKey] rewrap %d config release histories data key", rewrapped)
+	}
+}
+
+func (job *rewrapConfigFileDataKeyJob) rewrapConfigFiles(wrappers map[string]plugin.KeyWrapper) int {
+	var rewrapped int
+	for offset := uint32(0); ; offset += job.cfg.BatchSize {
+		total, files, err := job.storage.QueryConfigFiles(map[string]string{}, offset, job.cfg.BatchSize)
+		if err != nil {
+			log.Errorf("[Maintain][Job][RewrapConfigFileDataKey] query config files err: %v", err)
+			return rewrapped
+		}
+		for _, file := range files {
+			wrapper, ok := wrappers[file.GetEncryptAlgo()]
+			if !ok || !needRewrap(wrapper, file.GetEncryptDataKey()) {
+				continue
+			}
+			if err := job.rewrap(wrapper, file.Key()); err != nil {
+				log.Errorf("[Maintain][Job][RewrapConfigFileDataKey] rewrap %s err: %v", file.KeyString(), err)
+				continue
+			}
+			rewrapped++
+		}
+		if len(files) == 0 || offset+job.cfg.BatchSize >= total {
+			break
+		}
+	}
+	return rewrapped
+}
+
+func (job *rewrapConfigFileDataKeyJob) rewrapReleases(wrappers map[string]plugin.KeyWrapper) int {
+	releases, err := job.storage.GetMoreReleaseFile(true, time.Time{})
+	if err != nil {
+		log.Errorf("[Maintain][Job][RewrapConfigFileDataKey] query config releases err: %v", err)
+		return 0
+	}
+	var rewrapped int
+	for _, release := range releases {
+		if !release.Valid {
+			continue
+		}
+		wrapper, ok := wrappers[release.GetEncryptAlgo()]
+		if !ok || !needRewrap(wrapper, release.GetEncryptDataKey()) {
+			continue
+		}
+		if err := job.rewrapRelease(wrapper, release.ConfigFileReleaseKey); err != nil {
+			log.Errorf("[Maintain][Job][RewrapConfigFileDataKey] rewrap release %s err: %v",
+				release.ReleaseKey(), err)
+			continue
+		}
+		rewrapped++
+	}
+	return rewrapped
+}
+
+func (job *rewrapConfigFileDataKeyJob) rewrapHistories(wrappers map[string]plugin.KeyWrapper) int {
+	var rewrapped int
+	for offset := uint32(0); ; offset += job.cfg.BatchSize {
+		total, histories, err := job.storage.QueryConfigFileReleaseHistories(map[string]string{},
+			offset, job.cfg.BatchSize)
+		if err != nil {
+			log.Errorf("[Maintain][Job][RewrapConfigFileDataKey] query config release histories err: %v", err)
+			return rewrapped
+		}
+		for _, history := range histories {
+			wrapper, ok := wrappers[history.GetEncryptAlgo()]
+			if !ok || !needRewrap(wrapper, history.GetEncryptDataKey()) {
+				continue
+			}
+			// 发布历史只会新增, 不存在并发修改, 无需加锁
+			wrapped, err := rewrapDataKey(wrapper, history.GetEncryptDataKey())
+			if err == nil {
+				history.Metadata[model.MetaKeyConfigFileDataKey] = wrapped
+				err = job.storage.UpdateConfigFileReleaseHistoryMetadata(history)
+			}
+			if err != nil {
+				log.Errorf("[Maintain][Job][RewrapConfigFileDataKey] rewrap release history %d err: %v",
+					history.Id, err)
+				continue
+			}
+			rewrapped++
+		}
+		if len(histories) == 0 || offset+job.cfg.BatchSize >= total {
+			break
+		}
+	}
+	return rewrapped
+}
+
+func needRewrap(wrapper plugin.KeyWrapper, dataKey string) bool {
+	return dataKey != "" && wrapper.KeyVersion(dataKey) != wrapper.PrimaryKeyVersion()
+}
+
+// rewrapDataKey 使用主密钥的当前版本重新包装数据密钥, 数据密钥本身不变
+func rewrapDataKey(wrapper plugin.KeyWrapper, wrapped string) (string, error) {
+	dataKey, err := wrapper.UnwrapKey(wrapped)
+	if err != nil {
+		return "", err
+	}
+	return wrapper.WrapKey(dataKey)
+}
+
+// rewrap 锁住配置文件后重新包装, 避免覆盖并发的配置修改
+func (job *rewrapConfigFileDataKeyJob) rewrap(wrapper plugin.KeyWrapper, key *model.ConfigFileKey) error {
+	tx, err := job.storage.StartTx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if _, err := job.storage.LockConfigFile(tx, key); err != nil {
+		return err
+	}
+	file, err := job.storage.GetConfigFileTx(tx, key.Namespace, key.Group, key.Name)
+	if err != nil {
+		return err
+	}
+	if file == nil || !needRewrap(wrapper, file.GetEncryptDataKey()) {
+		return nil
+	}
+	wrapped, err := rewrapDataKey(wrapper, file.GetEncryptDataKey())
+	if err != nil {
+		return err
+	}
+	file.Metadata[model.MetaKeyConfigFileDataKey] = wrapped
+	if err := job.storage.UpdateConfigFileTx(tx, file); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// rewrapRelease 发布与配置文件的修改使用同一把锁, 锁住配置文件后重新包装发布的数据密钥
+func (job *rewrapConfigFileDataKeyJob) rewrapRelease(wrapper plugin.KeyWrapper,
+	key *model.ConfigFileReleaseKey) error {
+	tx, err := job.storage.StartTx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if _, err := job.storage.LockConfigFile(tx, key.ToFileKey()); err != nil {
+		return err
+	}
+	release, err := job.storage.GetConfigFileReleaseTx(tx, key)
+	if err != nil {
+		return err
+	}
+	if release == nil || !needRewrap(wrapper, release.GetEncryptDataKey()) {
+		return nil
+	}
+	wrapped, err := rewrapDataKey(wrapper, release.GetEncryptDataKey())
+	if err != nil {
+		return err
+	}
+	release.Metadata[model.MetaKeyConfigFileDataKey] = wrapped
+	if err := job.storage.UpdateConfigFileReleaseMetadataTx(tx, release); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (job *rewrapConfigFileDataKeyJob) interval() time.Duration {
+	return job.cfg.Interval
+}
+
+func (job *rewrapConfigFileDataKeyJob) clear() {
+}
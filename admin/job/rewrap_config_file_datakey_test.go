@@ -0,0 +1,169 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package job
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/plugin/crypto/aes"
+	"github.com/polarismesh/polaris/store/mock"
+)
+
+// fakeKeyWrapper wrap the data key as <version>:<base64>
+type fakeKeyWrapper struct {
+	aes.AESCrypto
+	primary string
+}
+
+func (f *fakeKeyWrapper) Name() string {
+	return "FAKE"
+}
+
+func (f *fakeKeyWrapper) WrapKey(dataKey []byte) (string, error) {
+	return f.primary + ":" + base64.StdEncoding.EncodeToString(dataKey), nil
+}
+
+func (f *fakeKeyWrapper) UnwrapKey(wrapped string) ([]byte, error) {
+	_, data, _ := strings.Cut(wrapped, ":")
+	return base64.StdEncoding.DecodeString(data)
+}
+
+func (f *fakeKeyWrapper) KeyVersion(wrapped string) string {
+	version, _, _ := strings.Cut(wrapped, ":")
+	return version
+}
+
+func (f *fakeKeyWrapper) PrimaryKeyVersion() string {
+	return f.primary
+}
+
+func (f *fakeKeyWrapper) ContentAlgorithm() string {
+	return aes.PluginName
+}
+
+type fakeCryptoManager struct {
+	plugin.CryptoManager
+	crypto plugin.Crypto
+}
+
+func (f *fakeCryptoManager) GetCryptoAlgoNames() []string {
+	return []string{f.crypto.Name()}
+}
+
+func (f *fakeCryptoManager) GetCrypto(algo string) (plugin.Crypto, error) {
+	return f.crypto, nil
+}
+
+func Test_RewrapConfigFileDataKeyJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	storage := mock.NewMockStore(ctrl)
+	tx := mock.NewMockTx(ctrl)
+
+	newFile := func(name, dataKey string) *model.ConfigFile {
+		return &model.ConfigFile{
+			Namespace: "default",
+			Group:     "group",
+			Name:      name,
+			Metadata: map[string]string{
+				model.MetaKeyConfigFileEncryptAlgo: "FAKE",
+				model.MetaKeyConfigFileDataKey:     dataKey,
+			},
+		}
+	}
+	stale := newFile("stale", "v1:"+base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")))
+	latest := newFile("latest", "v2:"+base64.StdEncoding.EncodeToString([]byte("fedcba9876543210")))
+	plain := &model.ConfigFile{Namespace: "default", Group: "group", Name: "plain"}
+
+	storage.EXPECT().QueryConfigFiles(gomock.Any(), uint32(0), uint32(100)).
+		Return(uint32(3), []*model.ConfigFile{stale, latest, plain}, nil)
+	storage.EXPECT().StartTx().Return(tx, nil)
+	storage.EXPECT().LockConfigFile(tx, stale.Key()).Return(stale, nil)
+	storage.EXPECT().GetConfigFileTx(tx, "default", "group", "stale").Return(stale, nil)
+	storage.EXPECT().UpdateConfigFileTx(tx, gomock.Any()).DoAndReturn(func(_ interface{}, file *model.ConfigFile) error {
+		assert.Equal(t, "v2:"+base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")),
+			file.GetEncryptDataKey())
+		return nil
+	})
+	tx.EXPECT().Commit().Return(nil)
+	tx.EXPECT().Rollback().Return(nil)
+
+	// 发布(含灰度发布)以及发布历史中的数据密钥同样需要重新包装
+	staleMeta := func() map[string]string {
+		return map[string]string{
+			model.MetaKeyConfigFileEncryptAlgo: "FAKE",
+			model.MetaKeyConfigFileDataKey:     stale.GetEncryptDataKey(),
+		}
+	}
+	newRelease := func(name string, valid bool) *model.ConfigFileRelease {
+		return &model.ConfigFileRelease{
+			SimpleConfigFileRelease: &model.SimpleConfigFileRelease{
+				ConfigFileReleaseKey: &model.ConfigFileReleaseKey{
+					Namespace: "default",
+					Group:     "group",
+					FileName:  "stale",
+					Name:      name,
+				},
+				Valid:    valid,
+				Metadata: staleMeta(),
+			},
+		}
+	}
+	grayRelease := newRelease("gray", true)
+	deletedRelease := newRelease("deleted", false)
+	releaseTx := mock.NewMockTx(ctrl)
+	storage.EXPECT().GetMoreReleaseFile(true, time.Time{}).
+		Return([]*model.ConfigFileRelease{grayRelease, deletedRelease}, nil)
+	storage.EXPECT().StartTx().Return(releaseTx, nil)
+	storage.EXPECT().LockConfigFile(releaseTx, stale.Key()).Return(stale, nil)
+	storage.EXPECT().GetConfigFileReleaseTx(releaseTx, grayRelease.ConfigFileReleaseKey).Return(grayRelease, nil)
+	storage.EXPECT().UpdateConfigFileReleaseMetadataTx(releaseTx, gomock.Any()).DoAndReturn(
+		func(_ interface{}, release *model.ConfigFileRelease) error {
+			assert.Equal(t, "gray", release.Name)
+			assert.Equal(t, "v2:"+base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")),
+				release.GetEncryptDataKey())
+			return nil
+		})
+	releaseTx.EXPECT().Commit().Return(nil)
+	releaseTx.EXPECT().Rollback().Return(nil)
+
+	history := &model.ConfigFileReleaseHistory{Id: 1, Metadata: staleMeta()}
+	storage.EXPECT().QueryConfigFileReleaseHistories(gomock.Any(), uint32(0), uint32(100)).
+		Return(uint32(1), []*model.ConfigFileReleaseHistory{history}, nil)
+	storage.EXPECT().UpdateConfigFileReleaseHistoryMetadata(gomock.Any()).DoAndReturn(
+		func(history *model.ConfigFileReleaseHistory) error {
+			assert.Equal(t, "v2:"+base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")),
+				history.GetEncryptDataKey())
+			return nil
+		})
+
+	job := &rewrapConfigFileDataKeyJob{
+		storage:       storage,
+		cryptoManager: &fakeCryptoManager{crypto: &fakeKeyWrapper{primary: "v2"}},
+	}
+	assert.NoError(t, job.init(map[string]interface{}{"interval": "30s"}))
+	assert.Equal(t, time.Minute, job.interval())
+	job.execute()
+}
@@ -20,13 +20,17 @@ package admin
 import (
 	"context"
 	"errors"
+	"io"
 	"runtime/debug"
+	"sort"
+	"strconv"
 	"time"
 
 	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
 	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
 	"go.uber.org/zap"
 
+	"github.com/polarismesh/polaris/admin/gitops"
 	api "github.com/polarismesh/polaris/common/api/v1"
 	connlimit "github.com/polarismesh/polaris/common/conn/limit"
 	commonlog "github.com/polarismesh/polaris/common/log"
@@ -34,6 +38,7 @@ import (
 	commonstore "github.com/polarismesh/polaris/common/store"
 	"github.com/polarismesh/polaris/common/utils"
 	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/store/migrate"
 )
 
 func (s *Server) GetServerConnections(_ context.Context, req *ConnReq) (*ConnCountResp, error) {
@@ -220,3 +225,176 @@ func (svr *Server) GetCMDBInfo(ctx context.Context) ([]model.LocationView, error
 
 	return ret, nil
 }
+
+// operationRecordFilters 操作记录支持的查询条件
+var operationRecordFilters = map[string]struct{}{
+	"resource_type":  {},
+	"resource_name":  {},
+	"namespace":      {},
+	"operator":       {},
+	"operation_type": {},
+	"start_time":     {},
+	"end_time":       {},
+}
+
+func (svr *Server) GetOperationRecords(ctx context.Context, query map[string]string) (*OperationRecordsResp, error) {
+	filter := make(map[string]string, len(query))
+	for k, v := range query {
+		filter[k] = v
+	}
+	offset, limit, err := utils.ParseOffsetAndLimit(filter)
+	if err != nil {
+		return nil, err
+	}
+	for k := range filter {
+		if _, ok := operationRecordFilters[k]; !ok {
+			return nil, errors.New("invalid query param " + k)
+		}
+	}
+	for _, key := range []string{"start_time", "end_time"} {
+		if val, ok := filter[key]; ok {
+			if _, err := strconv.ParseInt(val, 10, 64); err != nil {
+				return nil, errors.New("invalid " + key + ", must be unix timestamp in seconds")
+			}
+		}
+	}
+
+	total, records, err := svr.storage.GetOperationRecords(filter, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = []*model.RecordEntry{}
+	}
+	return &OperationRecordsResp{
+		Total:   total,
+		Records: records,
+	}, nil
+}
+
+// keyWrappers 开启了信封加密的加密插件
+func keyWrappers(cryptoMgr plugin.CryptoManager) map[string]plugin.KeyWrapper {
+	ret := map[string]plugin.KeyWrapper{}
+	if cryptoMgr == nil {
+		return ret
+	}
+	for _, name := range cryptoMgr.GetCryptoAlgoNames() {
+		crypto, err := cryptoMgr.GetCrypto(name)
+		if err != nil {
+			continue
+		}
+		if wrapper, ok := crypto.(plugin.KeyWrapper); ok {
+			ret[name] = wrapper
+		}
+	}
+	return ret
+}
+
+func (svr *Server) GetCryptoKeyVersions(ctx context.Context) ([]*CryptoKeyVersion, error) {
+	wrappers := keyWrappers(plugin.GetCryptoManager())
+	if len(wrappers) == 0 {
+		return []*CryptoKeyVersion{}, nil
+	}
+
+	stats := map[string]*CryptoKeyVersion{}
+	versionOf := func(algo, dataKey string) *CryptoKeyVersion {
+		wrapper, ok := wrappers[algo]
+		if !ok || dataKey == "" {
+			return nil
+		}
+		version := wrapper.KeyVersion(dataKey)
+		key := algo + "@" + version
+		if _, ok := stats[key]; !ok {
+			stats[key] = &CryptoKeyVersion{
+				Algorithm: algo,
+				Version:   version,
+				Primary:   version == wrapper.PrimaryKeyVersion(),
+				Files:     []string{},
+				Releases:  []string{},
+			}
+		}
+		return stats[key]
+	}
+
+	const pageSize = 100
+	for offset := uint32(0); ; offset += pageSize {
+		total, files, err := svr.storage.QueryConfigFiles(map[string]string{}, offset, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if stat := versionOf(file.GetEncryptAlgo(), file.GetEncryptDataKey()); stat != nil {
+				stat.Files = append(stat.Files, file.KeyString())
+			}
+		}
+		if len(files) == 0 || offset+pageSize >= total {
+			break
+		}
+	}
+
+	releases, err := svr.storage.GetMoreReleaseFile(true, time.Unix(0, 0))
+	if err != nil {
+		return nil, err
+	}
+	for _, release := range releases {
+		if !release.Active || !release.Valid {
+			continue
+		}
+		if stat := versionOf(release.GetEncryptAlgo(), release.GetEncryptDataKey()); stat != nil {
+			stat.Releases = append(stat.Releases, release.ReleaseKey())
+		}
+	}
+
+	ret := make([]*CryptoKeyVersion, 0, len(stats))
+	for _, stat := range stats {
+		ret = append(ret, stat)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Algorithm != ret[j].Algorithm {
+			return ret[i].Algorithm < ret[j].Algorithm
+		}
+		return ret[i].Version < ret[j].Version
+	})
+	return ret, nil
+}
+
+func (svr *Server) Backup(ctx context.Context, withInstances bool, w io.Writer) (*migrate.Manifest, error) {
+	manifest, err := migrate.Backup(svr.storage, w, migrate.BackupOptions{WithInstances: withInstances})
+	if err != nil {
+		log.Error("[MAINTAIN] backup governance data fail", utils.RequestID(ctx), zap.Error(err))
+		return nil, err
+	}
+	log.Info("[MAINTAIN] backup governance data", utils.RequestID(ctx),
+		zap.Bool("instances", withInstances), zap.Time("snapshot", manifest.CreateTime))
+	return manifest, nil
+}
+
+func (svr *Server) Restore(ctx context.Context, policy string, r io.Reader) ([]*migrate.Result, error) {
+	results, err := migrate.Restore(svr.storage, r, migrate.RestoreOptions{Policy: policy})
+	if err != nil {
+		log.Error("[MAINTAIN] restore governance data fail", utils.RequestID(ctx), zap.Error(err))
+		return results, err
+	}
+	for _, ret := range results {
+		log.Info("[MAINTAIN] restore governance data", utils.RequestID(ctx),
+			zap.String("resource", ret.Resource), zap.Int("total", ret.Source),
+			zap.Int("written", ret.Created), zap.Int("skipped", ret.Existed))
+	}
+	return results, nil
+}
+
+func (svr *Server) ReconcileManifests(ctx context.Context, archive []byte,
+	dryRun, adopt bool) (*gitops.Plan, error) {
+	manifests, err := gitops.ParseArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := svr.reconciler.Reconcile(ctx, manifests, dryRun, adopt)
+	if err != nil {
+		log.Error("[MAINTAIN] reconcile manifests fail", utils.RequestID(ctx), zap.Error(err))
+		return plan, err
+	}
+	log.Info("[MAINTAIN] reconcile manifests", utils.RequestID(ctx), zap.Bool("dry-run", dryRun),
+		zap.Bool("adopt", adopt), zap.Int("manifests", len(manifests)), zap.Int("failed", plan.Failed()))
+	return plan, nil
+}
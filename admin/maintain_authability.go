@@ -19,12 +19,15 @@ package admin
 
 import (
 	"context"
+	"io"
 
 	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
 
+	"github.com/polarismesh/polaris/admin/gitops"
 	api "github.com/polarismesh/polaris/common/api/v1"
 	"github.com/polarismesh/polaris/common/model"
 	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store/migrate"
 )
 
 var _ AdminOperateServer = (*serverAuthAbility)(nil)
@@ -178,3 +181,76 @@ func (svr *serverAuthAbility) GetCMDBInfo(ctx context.Context) ([]model.Location
 
 	return svr.targetServer.GetCMDBInfo(ctx)
 }
+
+func (svr *serverAuthAbility) GetOperationRecords(ctx context.Context,
+	query map[string]string) (*OperationRecordsResp, error) {
+	authCtx := svr.collectMaintainAuthContext(ctx, model.Read, "GetOperationRecords")
+	_, err := svr.strategyMgn.GetAuthChecker().CheckConsolePermission(authCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = authCtx.GetRequestContext()
+	ctx = context.WithValue(ctx, utils.ContextAuthContextKey, authCtx)
+
+	return svr.targetServer.GetOperationRecords(ctx, query)
+}
+
+func (svr *serverAuthAbility) GetCryptoKeyVersions(ctx context.Context) ([]*CryptoKeyVersion, error) {
+	authCtx := svr.collectMaintainAuthContext(ctx, model.Read, "GetCryptoKeyVersions")
+	_, err := svr.strategyMgn.GetAuthChecker().CheckConsolePermission(authCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = authCtx.GetRequestContext()
+	ctx = context.WithValue(ctx, utils.ContextAuthContextKey, authCtx)
+
+	return svr.targetServer.GetCryptoKeyVersions(ctx)
+}
+
+func (svr *serverAuthAbility) Backup(ctx context.Context, withInstances bool,
+	w io.Writer) (*migrate.Manifest, error) {
+	authCtx := svr.collectMaintainAuthContext(ctx, model.Read, "Backup")
+	_, err := svr.strategyMgn.GetAuthChecker().CheckConsolePermission(authCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = authCtx.GetRequestContext()
+	ctx = context.WithValue(ctx, utils.ContextAuthContextKey, authCtx)
+
+	return svr.targetServer.Backup(ctx, withInstances, w)
+}
+
+func (svr *serverAuthAbility) Restore(ctx context.Context, policy string,
+	r io.Reader) ([]*migrate.Result, error) {
+	authCtx := svr.collectMaintainAuthContext(ctx, model.Modify, "Restore")
+	_, err := svr.strategyMgn.GetAuthChecker().CheckConsolePermission(authCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = authCtx.GetRequestContext()
+	ctx = context.WithValue(ctx, utils.ContextAuthContextKey, authCtx)
+
+	return svr.targetServer.Restore(ctx, policy, r)
+}
+
+func (svr *serverAuthAbility) ReconcileManifests(ctx context.Context, archive []byte,
+	dryRun, adopt bool) (*gitops.Plan, error) {
+	op := model.Modify
+	if dryRun {
+		op = model.Read
+	}
+	authCtx := svr.collectMaintainAuthContext(ctx, op, "ReconcileManifests")
+	_, err := svr.strategyMgn.GetAuthChecker().CheckConsolePermission(authCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = authCtx.GetRequestContext()
+	ctx = context.WithValue(ctx, utils.ContextAuthContextKey, authCtx)
+
+	return svr.targetServer.ReconcileManifests(ctx, archive, dryRun, adopt)
+}
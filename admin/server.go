@@ -20,6 +20,7 @@ package admin
 import (
 	"sync"
 
+	"github.com/polarismesh/polaris/admin/gitops"
 	"github.com/polarismesh/polaris/cache"
 	"github.com/polarismesh/polaris/service"
 	"github.com/polarismesh/polaris/service/healthcheck"
@@ -34,4 +35,5 @@ type Server struct {
 	healthCheckServer *healthcheck.Server
 	cacheMgn          *cache.CacheManager
 	storage           store.Store
+	reconciler        *gitops.Reconciler
 }
@@ -0,0 +1,274 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+const (
+	paramServiceID = "service_id"
+	paramCheckID   = "check_id"
+	paramService   = "service"
+	paramKey       = "key"
+
+	paramDatacenter = "dc"
+	paramNamespace  = "ns"
+	paramIndex      = "index"
+	paramWait       = "wait"
+	paramToken      = "token"
+	paramTag        = "tag"
+	paramPassing    = "passing"
+	paramRecurse    = "recurse"
+	paramKeys       = "keys"
+	paramSeparator  = "separator"
+	paramRaw        = "raw"
+	paramCas        = "cas"
+	paramAcquire    = "acquire"
+	paramRelease    = "release"
+
+	headerNamespace = "X-Consul-Namespace"
+)
+
+// GetConsulServer consul v1 web server
+func (h *ConsulServer) GetConsulServer() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path("/v1").Consumes(restful.MIME_JSON, restful.MIME_OCTET, "text/plain").Produces(restful.MIME_JSON)
+	h.addAgentAccess(ws)
+	h.addCatalogAccess(ws)
+	h.addKVAccess(ws)
+	return ws
+}
+
+// addAgentAccess agent 以及 status 接口
+func (h *ConsulServer) addAgentAccess(ws *restful.WebService) {
+	ws.Route(ws.PUT("/agent/service/register").To(h.RegisterService))
+	ws.Route(ws.PUT(fmt.Sprintf("/agent/service/deregister/{%s}", paramServiceID)).To(h.DeregisterService).
+		Param(ws.PathParameter(paramServiceID, "consul service id").DataType("string")))
+	ws.Route(ws.GET("/agent/services").To(h.GetAgentServices))
+	ws.Route(ws.GET(fmt.Sprintf("/agent/service/{%s}", paramServiceID)).To(h.GetAgentService).
+		Param(ws.PathParameter(paramServiceID, "consul service id").DataType("string")))
+	for _, status := range []string{"pass", "warn", "fail", "update"} {
+		ws.Route(ws.PUT(fmt.Sprintf("/agent/check/%s/{%s}", status, paramCheckID)).To(h.UpdateCheck).
+			Param(ws.PathParameter(paramCheckID, "consul check id").DataType("string")))
+	}
+	ws.Route(ws.GET("/agent/self").To(h.GetAgentSelf))
+	ws.Route(ws.GET("/status/leader").To(h.GetStatusLeader))
+	ws.Route(ws.GET("/status/peers").To(h.GetStatusPeers))
+}
+
+// addCatalogAccess catalog 以及 health 接口
+func (h *ConsulServer) addCatalogAccess(ws *restful.WebService) {
+	ws.Route(ws.GET("/catalog/datacenters").To(h.GetCatalogDatacenters))
+	ws.Route(ws.GET("/catalog/services").To(h.GetCatalogServices))
+	ws.Route(ws.GET(fmt.Sprintf("/catalog/service/{%s}", paramService)).To(h.GetCatalogService).
+		Param(ws.PathParameter(paramService, "service name").DataType("string")))
+	ws.Route(ws.GET(fmt.Sprintf("/health/service/{%s}", paramService)).To(h.GetHealthService).
+		Param(ws.PathParameter(paramService, "service name").DataType("string")))
+}
+
+// addKVAccess KV 接口，映射到配置中心的配置文件
+func (h *ConsulServer) addKVAccess(ws *restful.WebService) {
+	path := fmt.Sprintf("/kv/{%s:*}", paramKey)
+	ws.Route(ws.GET(path).To(h.GetKV).Param(ws.PathParameter(paramKey, "kv key").DataType("string")))
+	ws.Route(ws.PUT(path).To(h.PutKV).Param(ws.PathParameter(paramKey, "kv key").DataType("string")))
+	ws.Route(ws.DELETE(path).To(h.DeleteKV).Param(ws.PathParameter(paramKey, "kv key").DataType("string")))
+}
+
+// GetAgentSelf 返回 agent 的基础信息，部分客户端启动时依赖该接口探测 agent
+func (h *ConsulServer) GetAgentSelf(req *restful.Request, rsp *restful.Response) {
+	writeJSON(req, rsp, map[string]interface{}{
+		"Config": map[string]interface{}{
+			"Datacenter": h.datacenter,
+			"NodeName":   utils.LocalHost,
+			"Server":     true,
+		},
+		"Member": map[string]interface{}{
+			"Name": utils.LocalHost,
+			"Addr": utils.LocalHost,
+			"Port": h.listenPort,
+		},
+	})
+}
+
+// GetStatusLeader polaris 没有 consul 的 raft 角色，返回当前节点地址
+func (h *ConsulServer) GetStatusLeader(req *restful.Request, rsp *restful.Response) {
+	writeJSON(req, rsp, h.selfAddress())
+}
+
+// GetStatusPeers 返回当前节点地址
+func (h *ConsulServer) GetStatusPeers(req *restful.Request, rsp *restful.Response) {
+	writeJSON(req, rsp, []string{h.selfAddress()})
+}
+
+// GetCatalogDatacenters 返回配置的数据中心
+func (h *ConsulServer) GetCatalogDatacenters(req *restful.Request, rsp *restful.Response) {
+	writeJSON(req, rsp, []string{h.datacenter})
+}
+
+func (h *ConsulServer) selfAddress() string {
+	return net.JoinHostPort(utils.LocalHost, strconv.Itoa(int(h.listenPort)))
+}
+
+// checkDatacenter 只支持配置的数据中心
+func (h *ConsulServer) checkDatacenter(req *restful.Request, rsp *restful.Response) bool {
+	if dc := req.QueryParameter(paramDatacenter); dc != "" && dc != h.datacenter {
+		writeError(req, rsp, http.StatusInternalServerError, "No path to datacenter")
+		return false
+	}
+	return true
+}
+
+// readNamespace consul 企业版通过 ns 参数或者 X-Consul-Namespace 指定命名空间
+func (h *ConsulServer) readNamespace(req *restful.Request) string {
+	if ns := req.QueryParameter(paramNamespace); ns != "" {
+		return ns
+	}
+	if ns := req.HeaderParameter(headerNamespace); ns != "" {
+		return ns
+	}
+	return h.namespace
+}
+
+// readToken 读取 consul ACL token，作为 polaris 的鉴权 token
+func readToken(req *restful.Request) string {
+	if token := req.HeaderParameter(headerToken); token != "" {
+		return token
+	}
+	if auth := req.HeaderParameter("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return req.QueryParameter(paramToken)
+}
+
+// buildContext 构造写请求的上下文
+func buildContext(req *restful.Request) context.Context {
+	ctx := context.WithValue(context.Background(), utils.ContextAuthTokenKey, readToken(req))
+	ctx = context.WithValue(ctx, utils.ContextClientAddress, req.Request.RemoteAddr)
+	ctx = context.WithValue(ctx, utils.StringContext("request-id"), req.HeaderParameter("Request-Id"))
+	return ctx
+}
+
+// clientIP 注册请求未携带 Address 时使用请求方的 IP
+func clientIP(req *restful.Request) string {
+	host, _, err := net.SplitHostPort(req.Request.RemoteAddr)
+	if err != nil {
+		return req.Request.RemoteAddr
+	}
+	return host
+}
+
+// hasQueryParameter consul 的布尔参数只需要出现即可，例如 ?passing
+func hasQueryParameter(req *restful.Request, name string) bool {
+	values, ok := req.Request.URL.Query()[name]
+	if !ok {
+		return false
+	}
+	for _, value := range values {
+		if value == "false" || value == "0" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseBlockingParams 解析阻塞查询的 index 以及 wait 参数
+func parseBlockingParams(req *restful.Request) (uint64, time.Duration, error) {
+	var (
+		index uint64
+		wait  = DefaultBlockingWait
+		err   error
+	)
+	if value := req.QueryParameter(paramIndex); value != "" {
+		if index, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("Invalid index: %s", value)
+		}
+	}
+	if value := req.QueryParameter(paramWait); value != "" {
+		if wait, err = time.ParseDuration(value); err != nil {
+			// 未携带单位时按照秒处理
+			secs, convErr := strconv.Atoi(value)
+			if convErr != nil {
+				return 0, 0, fmt.Errorf("Invalid wait time: %s", value)
+			}
+			wait = time.Duration(secs) * time.Second
+		}
+	}
+	if wait > MaxBlockingWait {
+		wait = MaxBlockingWait
+	}
+	// consul 在 wait 的基础上增加最多 1/16 的随机抖动，这里不做抖动
+	return index, wait, nil
+}
+
+// setMeta 设置 consul 查询的元信息头
+func setMeta(rsp *restful.Response, index uint64) {
+	rsp.AddHeader(headerIndex, strconv.FormatUint(index, 10))
+	rsp.AddHeader(headerKnownLeader, "true")
+	rsp.AddHeader(headerLastContact, "0")
+}
+
+func writeJSON(req *restful.Request, rsp *restful.Response, data interface{}) {
+	writeJSONWithCode(req, rsp, http.StatusOK, data)
+}
+
+func writeJSONWithCode(req *restful.Request, rsp *restful.Response, code int, data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		writeError(req, rsp, http.StatusInternalServerError, err.Error())
+		return
+	}
+	req.SetAttribute(attrStatusCode, uint32(code))
+	rsp.AddHeader(restful.HEADER_ContentType, restful.MIME_JSON)
+	rsp.WriteHeader(code)
+	_, _ = rsp.Write(body)
+}
+
+// writeError consul 的错误信息为纯文本
+func writeError(req *restful.Request, rsp *restful.Response, code int, msg string) {
+	req.SetAttribute(attrStatusCode, uint32(code))
+	rsp.AddHeader(restful.HEADER_ContentType, "text/plain; charset=utf-8")
+	rsp.WriteHeader(code)
+	_, _ = rsp.Write([]byte(msg))
+}
+
+// writePolarisError 根据 polaris 返回码计算 HTTP 状态码
+func writePolarisError(req *restful.Request, rsp *restful.Response, code uint32, info string) {
+	httpCode := int(code / 1000)
+	if httpCode < http.StatusBadRequest {
+		httpCode = http.StatusInternalServerError
+	}
+	writeError(req, rsp, httpCode, info)
+}
+
+// writeOK agent 写接口成功时返回空的 body
+func writeOK(req *restful.Request, rsp *restful.Response) {
+	req.SetAttribute(attrStatusCode, uint32(http.StatusOK))
+	rsp.WriteHeader(http.StatusOK)
+}
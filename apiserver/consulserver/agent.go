@@ -0,0 +1,212 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/emicklei/go-restful/v3"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+// RegisterService 注册服务实例，TTL check 转换为 polaris 心跳健康检查
+func (h *ConsulServer) RegisterService(req *restful.Request, rsp *restful.Response) {
+	reg := &AgentServiceRegistration{}
+	if err := req.ReadEntity(reg); err != nil {
+		writeError(req, rsp, http.StatusBadRequest, "Request decode failed: "+err.Error())
+		return
+	}
+	if reg.Name == "" {
+		writeError(req, rsp, http.StatusBadRequest, "Missing service name")
+		return
+	}
+	namespace := h.readNamespace(req)
+	if reg.Namespace != "" {
+		namespace = reg.Namespace
+	}
+	ins, err := convertRegistration(reg, namespace, h.namespace, clientIP(req))
+	if err != nil {
+		writeError(req, rsp, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctx := buildContext(req)
+	code, info := h.registerInstance(ctx, ins)
+	if code != api.ExecuteSuccess {
+		consullog.Errorf("[CONSUL-SERVER] register service fail, namespace: %s, service: %s, id: %s, code: %d, info: %s",
+			namespace, reg.Name, reg.serviceID(), code, info)
+		writePolarisError(req, rsp, code, info)
+		return
+	}
+	consullog.Infof("[CONSUL-SERVER] register service, client: %s, namespace: %s, service: %s, id: %s, address: %s:%d",
+		req.Request.RemoteAddr, namespace, reg.Name, reg.serviceID(), ins.GetHost().GetValue(), reg.Port)
+	writeOK(req, rsp)
+}
+
+// registerInstance 注册实例，服务不存在时先创建服务
+func (h *ConsulServer) registerInstance(ctx context.Context, ins *apiservice.Instance) (uint32, string) {
+	resp := h.namingServer.RegisterInstance(ctx, ins)
+	code := resp.GetCode().GetValue()
+	if code == api.NotFoundResource || code == api.NotFoundService {
+		svcResp := h.namingServer.CreateServices(ctx, []*apiservice.Service{{
+			Namespace: ins.GetNamespace(),
+			Name:      ins.GetService(),
+		}})
+		svcCode := svcResp.GetCode().GetValue()
+		if svcCode != api.ExecuteSuccess && svcCode != api.ExistedResource {
+			return svcCode, svcResp.GetInfo().GetValue()
+		}
+		resp = h.namingServer.RegisterInstance(ctx, ins)
+		code = resp.GetCode().GetValue()
+	}
+	if code == api.ExistedResource || code == api.SameInstanceRequest {
+		code = api.ExecuteSuccess
+	}
+	return code, resp.GetInfo().GetValue()
+}
+
+// DeregisterService 反注册服务实例
+func (h *ConsulServer) DeregisterService(req *restful.Request, rsp *restful.Response) {
+	namespace := h.readNamespace(req)
+	serviceID := req.PathParameter(paramServiceID)
+	instanceID := buildInstanceID(namespace, h.namespace, serviceID)
+	resp := h.namingServer.DeregisterInstance(buildContext(req),
+		&apiservice.Instance{Id: utils.NewStringValue(instanceID)})
+	code := resp.GetCode().GetValue()
+	if code == api.NotFoundInstance || code == api.NotFoundResource {
+		writeError(req, rsp, http.StatusNotFound, "Unknown service ID \""+serviceID+"\"")
+		return
+	}
+	if code != api.ExecuteSuccess {
+		writePolarisError(req, rsp, code, resp.GetInfo().GetValue())
+		return
+	}
+	consullog.Infof("[CONSUL-SERVER] deregister service, client: %s, namespace: %s, id: %s",
+		req.Request.RemoteAddr, namespace, serviceID)
+	writeOK(req, rsp)
+}
+
+// UpdateCheck 更新 TTL check 的状态
+// pass、warn 作为一次心跳上报，fail 将实例置为不健康，直到下一次心跳
+func (h *ConsulServer) UpdateCheck(req *restful.Request, rsp *restful.Response) {
+	status := HealthPassing
+	output := req.QueryParameter("note")
+	path := req.Request.URL.Path
+	switch {
+	case strings.Contains(path, "/agent/check/warn/"):
+		status = HealthWarning
+	case strings.Contains(path, "/agent/check/fail/"):
+		status = HealthCritical
+	case strings.Contains(path, "/agent/check/update/"):
+		update := &CheckUpdate{}
+		if err := req.ReadEntity(update); err != nil {
+			writeError(req, rsp, http.StatusBadRequest, "Request decode failed: "+err.Error())
+			return
+		}
+		if update.Status != HealthPassing && update.Status != HealthWarning && update.Status != HealthCritical {
+			writeError(req, rsp, http.StatusBadRequest, "Invalid check status: \""+update.Status+"\"")
+			return
+		}
+		status, output = update.Status, update.Output
+	}
+
+	namespace := h.readNamespace(req)
+	// 仅支持 consul 默认生成的 check id，即 service:<service id>，其余的 check id 视为 service id
+	serviceID := strings.TrimPrefix(req.PathParameter(paramCheckID), checkIDPrefix)
+	instanceID := buildInstanceID(namespace, h.namespace, serviceID)
+	// 刚注册的实例可能还没有同步到缓存中，实例是否存在由心跳上报判断
+	ctx := buildContext(req)
+
+	var (
+		code uint32
+		info string
+	)
+	if status == HealthCritical {
+		code, info = h.markUnhealthy(ctx, instanceID, output)
+	} else {
+		resp := h.healthCheckServer.Report(ctx, &apiservice.Instance{Id: utils.NewStringValue(instanceID)})
+		code, info = resp.GetCode().GetValue(), resp.GetInfo().GetValue()
+		// 未开启健康检查的实例上报心跳，对于 consul 来说仍然成功
+		if code == api.HeartbeatOnDisabledIns {
+			code = api.ExecuteSuccess
+		}
+	}
+	if code == api.NotFoundInstance || code == api.NotFoundResource {
+		writeError(req, rsp, http.StatusNotFound, "Unknown check ID \""+req.PathParameter(paramCheckID)+"\"")
+		return
+	}
+	if code != api.ExecuteSuccess {
+		writePolarisError(req, rsp, code, info)
+		return
+	}
+	writeOK(req, rsp)
+}
+
+// markUnhealthy 将实例置为不健康，并记录 check 的 output
+func (h *ConsulServer) markUnhealthy(ctx context.Context, instanceID, output string) (uint32, string) {
+	ins := h.cacheMgr.Instance().GetInstance(instanceID)
+	if ins == nil {
+		return api.NotFoundInstance, "instance not found"
+	}
+	metadata := make(map[string]string, len(ins.Metadata())+1)
+	for k, v := range ins.Metadata() {
+		metadata[k] = v
+	}
+	metadata[MetadataCheckNotes] = output
+	resp := h.namingServer.UpdateInstance(ctx, &apiservice.Instance{
+		Id:       utils.NewStringValue(instanceID),
+		Healthy:  utils.NewBoolValue(false),
+		Metadata: metadata,
+	})
+	code := resp.GetCode().GetValue()
+	if code == api.NoNeedUpdate {
+		code = api.ExecuteSuccess
+	}
+	return code, resp.GetInfo().GetValue()
+}
+
+// GetAgentServices 返回命名空间下通过 consul 协议注册的服务实例
+func (h *ConsulServer) GetAgentServices(req *restful.Request, rsp *restful.Response) {
+	namespace := h.readNamespace(req)
+	ret := map[string]*AgentService{}
+	_ = h.cacheMgr.Instance().IteratorInstances(func(_ string, ins *model.Instance) (bool, error) {
+		if ins.Namespace() == namespace && ins.Metadata()[MetadataRegisterFrom] == registerFromConsul {
+			svc := toAgentService(ins, h.datacenter, 0)
+			ret[svc.ID] = svc
+		}
+		return true, nil
+	})
+	writeJSON(req, rsp, ret)
+}
+
+// GetAgentService 返回单个服务实例
+func (h *ConsulServer) GetAgentService(req *restful.Request, rsp *restful.Response) {
+	namespace := h.readNamespace(req)
+	serviceID := req.PathParameter(paramServiceID)
+	ins := h.cacheMgr.Instance().GetInstance(buildInstanceID(namespace, h.namespace, serviceID))
+	if ins == nil {
+		writeError(req, rsp, http.StatusNotFound, "unknown service ID: "+serviceID)
+		return
+	}
+	writeJSON(req, rsp, toAgentService(ins, h.datacenter, 0))
+}
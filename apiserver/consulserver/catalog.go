@@ -0,0 +1,186 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+// GetCatalogServices 返回命名空间下的服务以及服务实例的 tags
+func (h *ConsulServer) GetCatalogServices(req *restful.Request, rsp *restful.Response) {
+	if !h.checkDatacenter(req, rsp) {
+		return
+	}
+	minIndex, wait, err := parseBlockingParams(req)
+	if err != nil {
+		writeError(req, rsp, http.StatusBadRequest, err.Error())
+		return
+	}
+	namespace := h.readNamespace(req)
+	data, index := blockingQuery(req.Request.Context(), minIndex, wait,
+		func() (interface{}, uint64) {
+			return h.listCatalogServices(namespace)
+		})
+	setMeta(rsp, index)
+	writeJSON(req, rsp, data)
+}
+
+func (h *ConsulServer) listCatalogServices(namespace string) (map[string][]string, uint64) {
+	_, services := h.cacheMgr.Service().ListServices(namespace)
+	ret := make(map[string][]string, len(services))
+	instances := make([]*model.Instance, 0, len(services))
+	var mtime time.Time
+	for _, svc := range services {
+		if svc.IsAlias() {
+			continue
+		}
+		if svc.ModifyTime.After(mtime) {
+			mtime = svc.ModifyTime
+		}
+		tagSet := map[string]struct{}{}
+		for _, ins := range h.cacheMgr.Instance().GetInstancesByServiceID(svc.ID) {
+			instances = append(instances, ins)
+			for _, tag := range instanceTags(ins) {
+				tagSet[tag] = struct{}{}
+			}
+		}
+		tags := make([]string, 0, len(tagSet))
+		for tag := range tagSet {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		ret[svc.Name] = tags
+	}
+	// 服务列表以及实例的变化都会导致 index 变化，与 consul 的行为一致
+	names := make([]string, 0, len(ret))
+	for name := range ret {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	revision := instancesRevision(instances)
+	for _, name := range names {
+		revision += "|" + name
+	}
+	if insMtime := instancesMtime(instances); insMtime.After(mtime) {
+		mtime = insMtime
+	}
+	return ret, dataIndex(mtime, revision)
+}
+
+// serviceQuery 服务实例查询条件
+type serviceQuery struct {
+	namespace   string
+	service     string
+	tags        []string
+	passingOnly bool
+}
+
+// queryInstances 查询服务下满足条件的实例，返回实例以及实例列表的 index
+func (h *ConsulServer) queryInstances(query *serviceQuery) ([]*model.Instance, uint64) {
+	svc := h.cacheMgr.Service().GetServiceByName(query.service, query.namespace)
+	if svc == nil {
+		return nil, dataIndex(time.Time{}, "")
+	}
+	all := h.cacheMgr.Instance().GetInstancesByServiceID(svc.ID)
+	ret := make([]*model.Instance, 0, len(all))
+	for _, ins := range all {
+		if !hasTags(ins, query.tags) {
+			continue
+		}
+		if query.passingOnly && instanceStatus(ins) != HealthPassing {
+			continue
+		}
+		ret = append(ret, ins)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ID() < ret[j].ID()
+	})
+	mtime := instancesMtime(all)
+	if svc.ModifyTime.After(mtime) {
+		mtime = svc.ModifyTime
+	}
+	return ret, dataIndex(mtime, svc.ID+"|"+instancesRevision(all))
+}
+
+// blockingInstances 按照阻塞查询的语义查询服务实例
+func (h *ConsulServer) blockingInstances(req *restful.Request, rsp *restful.Response,
+	query *serviceQuery) ([]*model.Instance, uint64, bool) {
+	if !h.checkDatacenter(req, rsp) {
+		return nil, 0, false
+	}
+	minIndex, wait, err := parseBlockingParams(req)
+	if err != nil {
+		writeError(req, rsp, http.StatusBadRequest, err.Error())
+		return nil, 0, false
+	}
+	data, index := blockingQuery(req.Request.Context(), minIndex, wait, func() (interface{}, uint64) {
+		return h.queryInstances(query)
+	})
+	instances, _ := data.([]*model.Instance)
+	return instances, index, true
+}
+
+func (h *ConsulServer) readServiceQuery(req *restful.Request) *serviceQuery {
+	return &serviceQuery{
+		namespace:   h.readNamespace(req),
+		service:     req.PathParameter(paramService),
+		tags:        req.QueryParameters(paramTag),
+		passingOnly: hasQueryParameter(req, paramPassing),
+	}
+}
+
+// GetCatalogService 返回服务下的全部实例
+func (h *ConsulServer) GetCatalogService(req *restful.Request, rsp *restful.Response) {
+	query := h.readServiceQuery(req)
+	query.passingOnly = false
+	instances, index, ok := h.blockingInstances(req, rsp, query)
+	if !ok {
+		return
+	}
+	ret := make([]*CatalogService, 0, len(instances))
+	for _, ins := range instances {
+		ret = append(ret, toCatalogService(ins, h.datacenter, index))
+	}
+	setMeta(rsp, index)
+	writeJSON(req, rsp, ret)
+}
+
+// GetHealthService 返回服务实例以及健康检查状态，passing 参数只返回健康的实例
+func (h *ConsulServer) GetHealthService(req *restful.Request, rsp *restful.Response) {
+	query := h.readServiceQuery(req)
+	instances, index, ok := h.blockingInstances(req, rsp, query)
+	if !ok {
+		return
+	}
+	ret := make([]*ServiceEntry, 0, len(instances))
+	for _, ins := range instances {
+		ret = append(ret, &ServiceEntry{
+			Node:    toNode(ins, h.datacenter, index),
+			Service: toAgentService(ins, h.datacenter, index),
+			Checks:  toHealthChecks(ins, index),
+		})
+	}
+	setMeta(rsp, index)
+	writeJSON(req, rsp, ret)
+}
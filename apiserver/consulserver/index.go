@@ -0,0 +1,76 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+)
+
+// revisionBits index 低位用于存放 revision 摘要的位数
+const revisionBits = 20
+
+// dataIndex 根据数据的最后修改时间以及 revision 计算 X-Consul-Index
+// 两者都来自各节点共享的存储数据，因此负载均衡后不同节点对相同数据返回相同的 index
+// 高位为修改时间（秒），使 index 随数据更新递增；低位为 revision 的摘要，保证同一秒内的变化也会改变 index
+func dataIndex(mtime time.Time, revision string) uint64 {
+	if revision == "" {
+		// consul 约定 index 不能为 0，数据不存在时返回 1，客户端可以基于 1 继续阻塞等待数据创建
+		return 1
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(revision))
+	var index uint64
+	if !mtime.IsZero() && mtime.Unix() > 0 {
+		index = uint64(mtime.Unix()) << revisionBits
+	}
+	index |= uint64(h.Sum32() & (1<<revisionBits - 1))
+	// 避免与数据不存在时的 index 冲突
+	if index <= 1 {
+		index = 2
+	}
+	return index
+}
+
+// blockingQuery 执行 consul 阻塞查询，query 返回数据以及数据对应的 index
+// 当 minIndex 为 0 或者数据的 index 与 minIndex 不同时立即返回，否则等待数据变化或者超时
+// index 可能因为数据删除而变小，consul 客户端在 index 回退时会重置后重新查询
+func blockingQuery(ctx context.Context, minIndex uint64, wait time.Duration,
+	query func() (interface{}, uint64)) (interface{}, uint64) {
+	data, index := query()
+	if minIndex == 0 || index != minIndex {
+		return data, index
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	ticker := time.NewTicker(blockingCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return data, index
+		case <-timer.C:
+			return data, index
+		case <-ticker.C:
+			if data, index = query(); index != minIndex {
+				return data, index
+			}
+		}
+	}
+}
@@ -0,0 +1,351 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	apiconfig "github.com/polarismesh/specification/source/go/api/v1/config_manage"
+
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/plugin"
+)
+
+const (
+	// kvFormat KV 写入的配置文件格式
+	kvFormat = "text"
+	// maxKVValueSize consul 默认限制单个 value 为 512KB
+	maxKVValueSize = 512 * 1024
+
+	kvPathPrefix = "/v1/kv/"
+)
+
+// GetKV 查询 KV，支持 recurse、keys、raw 以及阻塞查询
+func (h *ConsulServer) GetKV(req *restful.Request, rsp *restful.Response) {
+	if !h.checkDatacenter(req, rsp) {
+		return
+	}
+	minIndex, wait, err := parseBlockingParams(req)
+	if err != nil {
+		writeError(req, rsp, http.StatusBadRequest, err.Error())
+		return
+	}
+	namespace := h.readNamespace(req)
+	key := readKey(req)
+	recurse := hasQueryParameter(req, paramRecurse)
+	keysOnly := hasQueryParameter(req, paramKeys)
+
+	data, index := blockingQuery(req.Request.Context(), minIndex, wait,
+		func() (interface{}, uint64) {
+			if recurse || keysOnly {
+				return h.listKV(namespace, key)
+			}
+			release := h.cacheMgr.ConfigFile().GetActiveRelease(namespace, h.kvGroup, key)
+			if release == nil {
+				return nil, dataIndex(time.Time{}, "")
+			}
+			return []*model.ConfigFileRelease{release}, releasesIndex([]*model.ConfigFileRelease{release})
+		})
+	setMeta(rsp, index)
+	releases, _ := data.([]*model.ConfigFileRelease)
+	if len(releases) == 0 {
+		req.SetAttribute(attrStatusCode, uint32(http.StatusNotFound))
+		rsp.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if keysOnly {
+		writeJSON(req, rsp, filterKeys(releases, key, req.QueryParameter(paramSeparator)))
+		return
+	}
+	ctx := buildContext(req)
+	ret := make([]*KVPair, 0, len(releases))
+	for _, release := range releases {
+		pair, code, info := h.readKVPair(ctx, release)
+		if code == api.NotFoundResource {
+			// 阻塞查询返回后配置已经被删除
+			continue
+		}
+		if code != api.ExecuteSuccess {
+			consullog.Errorf("[CONSUL-SERVER] get kv fail, namespace: %s, key: %s, code: %d, info: %s",
+				namespace, release.FileName, code, info)
+			writePolarisError(req, rsp, code, info)
+			return
+		}
+		ret = append(ret, pair)
+	}
+	if len(ret) == 0 {
+		req.SetAttribute(attrStatusCode, uint32(http.StatusNotFound))
+		rsp.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if hasQueryParameter(req, paramRaw) && !recurse {
+		req.SetAttribute(attrStatusCode, uint32(http.StatusOK))
+		rsp.AddHeader(restful.HEADER_ContentType, restful.MIME_OCTET)
+		rsp.WriteHeader(http.StatusOK)
+		_, _ = rsp.Write(ret[0].Value)
+		return
+	}
+	writeJSON(req, rsp, ret)
+}
+
+// readKVPair 通过配置中心的客户端查询接口读取 KV 的内容，与其他客户端一样经过鉴权，
+// 加密的配置在这里解密后再返回，consul 客户端无法自行解密
+func (h *ConsulServer) readKVPair(ctx context.Context, release *model.ConfigFileRelease) (*KVPair, uint32, string) {
+	resp := h.configServer.GetConfigFileWithCache(ctx, &apiconfig.ClientConfigFileInfo{
+		Namespace: utils.NewStringValue(release.Namespace),
+		Group:     utils.NewStringValue(release.Group),
+		FileName:  utils.NewStringValue(release.FileName),
+	})
+	if code := resp.GetCode().GetValue(); code != api.ExecuteSuccess {
+		return nil, code, resp.GetInfo().GetValue()
+	}
+	file := resp.GetConfigFile()
+	content, err := decryptContent(file)
+	if err != nil {
+		return nil, api.ExecuteException, err.Error()
+	}
+	return &KVPair{
+		Key:         file.GetFileName().GetValue(),
+		CreateIndex: release.Id,
+		ModifyIndex: file.GetVersion().GetValue(),
+		Value:       []byte(content),
+	}, api.ExecuteSuccess, ""
+}
+
+// decryptContent 使用返回给客户端的数据密钥解密配置内容
+func decryptContent(file *apiconfig.ClientConfigFileInfo) (string, error) {
+	content := file.GetContent().GetValue()
+	if !file.GetEncrypted().GetValue() {
+		return content, nil
+	}
+	var dataKey, algorithm string
+	for _, tag := range file.GetTags() {
+		switch tag.GetKey().GetValue() {
+		case model.MetaKeyConfigFileDataKey:
+			dataKey = tag.GetValue().GetValue()
+		case model.MetaKeyConfigFileEncryptAlgo:
+			algorithm = tag.GetValue().GetValue()
+		}
+	}
+	if dataKey == "" || algorithm == "" {
+		return content, nil
+	}
+	cryptoMgr := plugin.GetCryptoManager()
+	if cryptoMgr == nil {
+		return "", errors.New("crypto manager is not available")
+	}
+	crypto, err := cryptoMgr.GetCrypto(algorithm)
+	if err != nil {
+		return "", err
+	}
+	key, err := base64.StdEncoding.DecodeString(dataKey)
+	if err != nil {
+		return "", err
+	}
+	return crypto.Decrypt(content, key)
+}
+
+// listKV 查询 key 前缀匹配的配置，分组的 active release 不包含内容，需要逐个获取
+func (h *ConsulServer) listKV(namespace, prefix string) ([]*model.ConfigFileRelease, uint64) {
+	simples, _ := h.cacheMgr.ConfigFile().GetGroupActiveReleases(namespace, h.kvGroup)
+	names := make([]string, 0, len(simples))
+	for _, item := range simples {
+		if strings.HasPrefix(item.FileName, prefix) {
+			names = append(names, item.FileName)
+		}
+	}
+	sort.Strings(names)
+	ret := make([]*model.ConfigFileRelease, 0, len(names))
+	for _, name := range names {
+		release := h.cacheMgr.ConfigFile().GetActiveRelease(namespace, h.kvGroup, name)
+		if release == nil {
+			continue
+		}
+		ret = append(ret, release)
+	}
+	return ret, releasesIndex(ret)
+}
+
+// releasesIndex 根据配置发布的版本以及修改时间计算 index
+func releasesIndex(releases []*model.ConfigFileRelease) uint64 {
+	var mtime time.Time
+	revision := &strings.Builder{}
+	for _, release := range releases {
+		if release.ModifyTime.After(mtime) {
+			mtime = release.ModifyTime
+		}
+		_, _ = fmt.Fprintf(revision, "%s:%d|", release.FileName, release.Version)
+	}
+	return dataIndex(mtime, revision.String())
+}
+
+// filterKeys 返回 key 列表，指定 separator 时只返回到前缀之后第一个 separator 为止的部分
+func filterKeys(releases []*model.ConfigFileRelease, prefix, separator string) []string {
+	keys := make([]string, 0, len(releases))
+	seen := map[string]struct{}{}
+	for _, release := range releases {
+		key := release.FileName
+		if separator != "" {
+			if idx := strings.Index(key[len(prefix):], separator); idx >= 0 {
+				key = key[:len(prefix)+idx+len(separator)]
+			}
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// readKey go-restful 的路径参数会丢掉结尾的 /，而 consul 的 key 前缀查询依赖结尾的 /
+func readKey(req *restful.Request) string {
+	idx := strings.Index(req.Request.URL.Path, kvPathPrefix)
+	if idx < 0 {
+		return req.PathParameter(paramKey)
+	}
+	return req.Request.URL.Path[idx+len(kvPathPrefix):]
+}
+
+// PutKV 写入 KV，即创建或更新配置文件并发布，支持 cas
+func (h *ConsulServer) PutKV(req *restful.Request, rsp *restful.Response) {
+	if !h.checkDatacenter(req, rsp) {
+		return
+	}
+	if req.QueryParameter(paramAcquire) != "" || req.QueryParameter(paramRelease) != "" {
+		writeError(req, rsp, http.StatusBadRequest, "Sessions are not supported")
+		return
+	}
+	namespace := h.readNamespace(req)
+	key := readKey(req)
+	if key == "" {
+		writeError(req, rsp, http.StatusBadRequest, "Missing key name")
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Request.Body, maxKVValueSize+1))
+	if err != nil {
+		writeError(req, rsp, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(body) > maxKVValueSize {
+		writeError(req, rsp, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("Value exceeds %d byte limit", maxKVValueSize))
+		return
+	}
+	if !h.checkCas(req, rsp, namespace, key) {
+		return
+	}
+
+	resp := h.configServer.UpsertAndReleaseConfigFile(buildContext(req), &apiconfig.ConfigFilePublishInfo{
+		Namespace: utils.NewStringValue(namespace),
+		Group:     utils.NewStringValue(h.kvGroup),
+		FileName:  utils.NewStringValue(key),
+		Content:   utils.NewStringValue(string(body)),
+		Format:    utils.NewStringValue(kvFormat),
+	})
+	if code := resp.GetCode().GetValue(); code != api.ExecuteSuccess {
+		consullog.Errorf("[CONSUL-SERVER] put kv fail, namespace: %s, key: %s, code: %d, info: %s",
+			namespace, key, code, resp.GetInfo().GetValue())
+		writePolarisError(req, rsp, code, resp.GetInfo().GetValue())
+		return
+	}
+	writeJSON(req, rsp, true)
+}
+
+// DeleteKV 删除 KV，recurse 时删除前缀匹配的全部 key
+func (h *ConsulServer) DeleteKV(req *restful.Request, rsp *restful.Response) {
+	if !h.checkDatacenter(req, rsp) {
+		return
+	}
+	namespace := h.readNamespace(req)
+	key := readKey(req)
+	keys := []string{key}
+	if hasQueryParameter(req, paramRecurse) {
+		releases, _ := h.listKV(namespace, key)
+		keys = keys[:0]
+		for _, release := range releases {
+			keys = append(keys, release.FileName)
+		}
+	} else if !h.checkCas(req, rsp, namespace, key) {
+		return
+	}
+
+	ctx := buildContext(req)
+	for _, item := range keys {
+		resp := h.configServer.DeleteConfigFile(ctx, &apiconfig.ConfigFile{
+			Namespace: utils.NewStringValue(namespace),
+			Group:     utils.NewStringValue(h.kvGroup),
+			Name:      utils.NewStringValue(item),
+		})
+		code := resp.GetCode().GetValue()
+		if code != api.ExecuteSuccess && code != api.NotFoundResource {
+			consullog.Errorf("[CONSUL-SERVER] delete kv fail, namespace: %s, key: %s, code: %d, info: %s",
+				namespace, item, code, resp.GetInfo().GetValue())
+			writePolarisError(req, rsp, code, resp.GetInfo().GetValue())
+			return
+		}
+	}
+	writeJSON(req, rsp, true)
+}
+
+// checkCas 处理 cas 参数，0 表示 key 不存在时才写入，否则要求当前的 ModifyIndex 与之相等
+// 返回 false 时已经写入了响应，不再继续处理
+func (h *ConsulServer) checkCas(req *restful.Request, rsp *restful.Response, namespace, key string) bool {
+	value := req.QueryParameter(paramCas)
+	if value == "" {
+		return true
+	}
+	cas, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		writeError(req, rsp, http.StatusBadRequest, "Invalid cas index: "+value)
+		return false
+	}
+	// cas 需要读取最新的数据，不能使用缓存
+	release, err := h.cacheMgr.GetStore().GetConfigFileActiveRelease(&model.ConfigFileKey{
+		Namespace: namespace,
+		Group:     h.kvGroup,
+		Name:      key,
+	})
+	if err != nil {
+		writeError(req, rsp, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	var current uint64
+	if release != nil {
+		current = release.Version
+	}
+	if current != cas {
+		writeJSON(req, rsp, false)
+		return false
+	}
+	return true
+}
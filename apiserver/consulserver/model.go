@@ -0,0 +1,438 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+const (
+	HealthPassing  = "passing"
+	HealthWarning  = "warning"
+	HealthCritical = "critical"
+
+	checkTypeTTL = "ttl"
+	// checkIDPrefix consul 为注册时内嵌的 check 生成的默认 check id 前缀
+	checkIDPrefix = "service:"
+	// defaultWeight 未指定 Weights 时的实例权重
+	defaultWeight = 100
+)
+
+// AgentWeights consul 服务权重
+type AgentWeights struct {
+	Passing int `json:"Passing"`
+	Warning int `json:"Warning"`
+}
+
+// AgentServiceCheck consul 注册服务时携带的 check 定义
+type AgentServiceCheck struct {
+	CheckID                        string `json:"CheckID,omitempty"`
+	Name                           string `json:"Name,omitempty"`
+	TTL                            string `json:"TTL,omitempty"`
+	HTTP                           string `json:"HTTP,omitempty"`
+	TCP                            string `json:"TCP,omitempty"`
+	GRPC                           string `json:"GRPC,omitempty"`
+	Interval                       string `json:"Interval,omitempty"`
+	Timeout                        string `json:"Timeout,omitempty"`
+	DeregisterCriticalServiceAfter string `json:"DeregisterCriticalServiceAfter,omitempty"`
+	Status                         string `json:"Status,omitempty"`
+	Notes                          string `json:"Notes,omitempty"`
+}
+
+// AgentServiceRegistration /v1/agent/service/register 请求
+type AgentServiceRegistration struct {
+	ID                string               `json:"ID,omitempty"`
+	Name              string               `json:"Name,omitempty"`
+	Tags              []string             `json:"Tags,omitempty"`
+	Port              int                  `json:"Port,omitempty"`
+	Address           string               `json:"Address,omitempty"`
+	EnableTagOverride bool                 `json:"EnableTagOverride,omitempty"`
+	Meta              map[string]string    `json:"Meta,omitempty"`
+	Weights           *AgentWeights        `json:"Weights,omitempty"`
+	Check             *AgentServiceCheck   `json:"Check,omitempty"`
+	Checks            []*AgentServiceCheck `json:"Checks,omitempty"`
+	Namespace         string               `json:"Namespace,omitempty"`
+}
+
+// AgentService consul agent 上的服务
+type AgentService struct {
+	ID                string            `json:"ID"`
+	Service           string            `json:"Service"`
+	Tags              []string          `json:"Tags"`
+	Meta              map[string]string `json:"Meta"`
+	Port              int               `json:"Port"`
+	Address           string            `json:"Address"`
+	Weights           AgentWeights      `json:"Weights"`
+	EnableTagOverride bool              `json:"EnableTagOverride"`
+	Datacenter        string            `json:"Datacenter"`
+	CreateIndex       uint64            `json:"CreateIndex"`
+	ModifyIndex       uint64            `json:"ModifyIndex"`
+}
+
+// Node consul 节点，polaris 中使用实例的 host 作为节点
+type Node struct {
+	ID              string            `json:"ID"`
+	Node            string            `json:"Node"`
+	Address         string            `json:"Address"`
+	Datacenter      string            `json:"Datacenter"`
+	TaggedAddresses map[string]string `json:"TaggedAddresses"`
+	Meta            map[string]string `json:"Meta"`
+	CreateIndex     uint64            `json:"CreateIndex"`
+	ModifyIndex     uint64            `json:"ModifyIndex"`
+}
+
+// HealthCheck consul 健康检查状态
+type HealthCheck struct {
+	Node        string   `json:"Node"`
+	CheckID     string   `json:"CheckID"`
+	Name        string   `json:"Name"`
+	Status      string   `json:"Status"`
+	Notes       string   `json:"Notes"`
+	Output      string   `json:"Output"`
+	ServiceID   string   `json:"ServiceID"`
+	ServiceName string   `json:"ServiceName"`
+	ServiceTags []string `json:"ServiceTags"`
+	Type        string   `json:"Type"`
+	CreateIndex uint64   `json:"CreateIndex"`
+	ModifyIndex uint64   `json:"ModifyIndex"`
+}
+
+// ServiceEntry /v1/health/service/:service 返回的条目
+type ServiceEntry struct {
+	Node    *Node          `json:"Node"`
+	Service *AgentService  `json:"Service"`
+	Checks  []*HealthCheck `json:"Checks"`
+}
+
+// CatalogService /v1/catalog/service/:service 返回的条目
+type CatalogService struct {
+	ID                       string            `json:"ID"`
+	Node                     string            `json:"Node"`
+	Address                  string            `json:"Address"`
+	Datacenter               string            `json:"Datacenter"`
+	TaggedAddresses          map[string]string `json:"TaggedAddresses"`
+	NodeMeta                 map[string]string `json:"NodeMeta"`
+	ServiceID                string            `json:"ServiceID"`
+	ServiceName              string            `json:"ServiceName"`
+	ServiceAddress           string            `json:"ServiceAddress"`
+	ServiceTags              []string          `json:"ServiceTags"`
+	ServiceMeta              map[string]string `json:"ServiceMeta"`
+	ServicePort              int               `json:"ServicePort"`
+	ServiceWeights           AgentWeights      `json:"ServiceWeights"`
+	ServiceEnableTagOverride bool              `json:"ServiceEnableTagOverride"`
+	CreateIndex              uint64            `json:"CreateIndex"`
+	ModifyIndex              uint64            `json:"ModifyIndex"`
+}
+
+// CheckUpdate /v1/agent/check/update/:check_id 请求
+type CheckUpdate struct {
+	Status string `json:"Status"`
+	Output string `json:"Output"`
+}
+
+// KVPair consul KV 数据，Value 序列化为 base64
+type KVPair struct {
+	Key         string `json:"Key"`
+	CreateIndex uint64 `json:"CreateIndex"`
+	ModifyIndex uint64 `json:"ModifyIndex"`
+	LockIndex   uint64 `json:"LockIndex"`
+	Flags       uint64 `json:"Flags"`
+	Value       []byte `json:"Value"`
+	Session     string `json:"Session,omitempty"`
+}
+
+// ttlCheck 找到注册请求中的 TTL check，polaris 仅支持客户端心跳方式的健康检查
+func (r *AgentServiceRegistration) ttlCheck() *AgentServiceCheck {
+	checks := make([]*AgentServiceCheck, 0, len(r.Checks)+1)
+	if r.Check != nil {
+		checks = append(checks, r.Check)
+	}
+	checks = append(checks, r.Checks...)
+	for _, check := range checks {
+		if check != nil && check.TTL != "" {
+			return check
+		}
+	}
+	return nil
+}
+
+// serviceID 未指定 ID 时 consul 使用服务名作为 service id
+func (r *AgentServiceRegistration) serviceID() string {
+	if r.ID != "" {
+		return r.ID
+	}
+	return r.Name
+}
+
+// checkID 未指定 CheckID 时 consul 使用 service:<service id>
+func checkID(serviceID string, check *AgentServiceCheck) string {
+	if check != nil && check.CheckID != "" {
+		return check.CheckID
+	}
+	return checkIDPrefix + serviceID
+}
+
+// buildInstanceID 非默认命名空间的实例 id 增加命名空间前缀，避免不同命名空间的 service id 冲突
+func buildInstanceID(namespace, defaultNamespace, serviceID string) string {
+	if namespace != defaultNamespace {
+		return namespace + ":" + serviceID
+	}
+	return serviceID
+}
+
+// parseTTL 解析 consul 的 duration，最小为 1s
+func parseTTL(ttl string) (uint32, error) {
+	d, err := time.ParseDuration(ttl)
+	if err != nil {
+		return 0, fmt.Errorf("invalid check ttl %q: %w", ttl, err)
+	}
+	secs := math.Ceil(d.Seconds())
+	if secs < 1 {
+		secs = 1
+	}
+	return uint32(secs), nil
+}
+
+// convertRegistration 将 consul 服务注册请求转换为 polaris 实例
+func convertRegistration(reg *AgentServiceRegistration, namespace, defaultNamespace,
+	clientIP string) (*apiservice.Instance, error) {
+	serviceID := reg.serviceID()
+	host := reg.Address
+	if host == "" {
+		host = clientIP
+	}
+	metadata := make(map[string]string, len(reg.Meta)+4)
+	for k, v := range reg.Meta {
+		metadata[k] = v
+	}
+	metadata[MetadataRegisterFrom] = registerFromConsul
+	metadata[MetadataServiceID] = serviceID
+	if len(reg.Tags) > 0 {
+		tags, _ := json.Marshal(reg.Tags)
+		metadata[MetadataTags] = string(tags)
+	}
+	weight := uint32(defaultWeight)
+	if reg.Weights != nil && reg.Weights.Passing > 0 {
+		weight = uint32(reg.Weights.Passing)
+	}
+
+	ins := &apiservice.Instance{
+		Id:        utils.NewStringValue(buildInstanceID(namespace, defaultNamespace, serviceID)),
+		Service:   utils.NewStringValue(reg.Name),
+		Namespace: utils.NewStringValue(namespace),
+		Host:      utils.NewStringValue(host),
+		Port:      utils.NewUInt32Value(uint32(reg.Port)),
+		Protocol:  utils.NewStringValue("http"),
+		Weight:    utils.NewUInt32Value(weight),
+		Healthy:   utils.NewBoolValue(true),
+		Isolate:   utils.NewBoolValue(false),
+		Metadata:  metadata,
+	}
+	// TTL check 映射为 polaris 心跳健康检查，初始状态与 consul 一致，未指定时为 critical
+	if check := reg.ttlCheck(); check != nil {
+		ttl, err := parseTTL(check.TTL)
+		if err != nil {
+			return nil, err
+		}
+		metadata[MetadataCheckTTL] = check.TTL
+		ins.EnableHealthCheck = utils.NewBoolValue(true)
+		ins.HealthCheck = &apiservice.HealthCheck{
+			Type:      apiservice.HealthCheck_HEARTBEAT,
+			Heartbeat: &apiservice.HeartbeatHealthCheck{Ttl: utils.NewUInt32Value(ttl)},
+		}
+		ins.Healthy = utils.NewBoolValue(check.Status == HealthPassing)
+	}
+	return ins, nil
+}
+
+// instanceServiceID 获取实例对应的 consul service id，非 consul 注册的实例使用 polaris 实例 id
+func instanceServiceID(ins *model.Instance) string {
+	if id := ins.Metadata()[MetadataServiceID]; id != "" {
+		return id
+	}
+	return ins.ID()
+}
+
+// instanceTags 获取实例的 consul tags
+func instanceTags(ins *model.Instance) []string {
+	tags := []string{}
+	if raw := ins.Metadata()[MetadataTags]; raw != "" {
+		_ = json.Unmarshal([]byte(raw), &tags)
+	}
+	return tags
+}
+
+// instanceMeta 去掉 polaris 内部使用的元数据
+func instanceMeta(ins *model.Instance) map[string]string {
+	meta := map[string]string{}
+	for k, v := range ins.Metadata() {
+		if strings.HasPrefix(k, "internal-") {
+			continue
+		}
+		meta[k] = v
+	}
+	return meta
+}
+
+// instanceStatus 健康且未隔离的实例为 passing，其余为 critical
+func instanceStatus(ins *model.Instance) string {
+	if ins.Healthy() && !ins.Isolate() {
+		return HealthPassing
+	}
+	return HealthCritical
+}
+
+func toAgentService(ins *model.Instance, datacenter string, index uint64) *AgentService {
+	return &AgentService{
+		ID:          instanceServiceID(ins),
+		Service:     ins.Proto.GetService().GetValue(),
+		Tags:        instanceTags(ins),
+		Meta:        instanceMeta(ins),
+		Port:        int(ins.Port()),
+		Address:     ins.Host(),
+		Weights:     AgentWeights{Passing: int(ins.Weight()), Warning: 1},
+		Datacenter:  datacenter,
+		CreateIndex: index,
+		ModifyIndex: index,
+	}
+}
+
+func toNode(ins *model.Instance, datacenter string, index uint64) *Node {
+	return &Node{
+		Node:            ins.Host(),
+		Address:         ins.Host(),
+		Datacenter:      datacenter,
+		TaggedAddresses: map[string]string{"lan": ins.Host(), "wan": ins.Host()},
+		Meta:            map[string]string{},
+		CreateIndex:     index,
+		ModifyIndex:     index,
+	}
+}
+
+func toHealthChecks(ins *model.Instance, index uint64) []*HealthCheck {
+	serviceID := instanceServiceID(ins)
+	checkType := ""
+	if ins.EnableHealthCheck() {
+		checkType = checkTypeTTL
+	}
+	output := ins.Metadata()[MetadataCheckNotes]
+	if ins.Isolate() {
+		output = "instance is isolated"
+	}
+	return []*HealthCheck{
+		{
+			Node:        ins.Host(),
+			CheckID:     "serfHealth",
+			Name:        "Serf Health Status",
+			Status:      HealthPassing,
+			ServiceTags: []string{},
+			CreateIndex: index,
+			ModifyIndex: index,
+		},
+		{
+			Node:        ins.Host(),
+			CheckID:     checkIDPrefix + serviceID,
+			Name:        "Service '" + ins.Proto.GetService().GetValue() + "' check",
+			Status:      instanceStatus(ins),
+			Output:      output,
+			ServiceID:   serviceID,
+			ServiceName: ins.Proto.GetService().GetValue(),
+			ServiceTags: instanceTags(ins),
+			Type:        checkType,
+			CreateIndex: index,
+			ModifyIndex: index,
+		},
+	}
+}
+
+func toCatalogService(ins *model.Instance, datacenter string, index uint64) *CatalogService {
+	svc := toAgentService(ins, datacenter, index)
+	return &CatalogService{
+		Node:            ins.Host(),
+		Address:         ins.Host(),
+		Datacenter:      datacenter,
+		TaggedAddresses: map[string]string{"lan": ins.Host(), "wan": ins.Host()},
+		NodeMeta:        map[string]string{},
+		ServiceID:       svc.ID,
+		ServiceName:     svc.Service,
+		ServiceAddress:  svc.Address,
+		ServiceTags:     svc.Tags,
+		ServiceMeta:     svc.Meta,
+		ServicePort:     svc.Port,
+		ServiceWeights:  svc.Weights,
+		CreateIndex:     index,
+		ModifyIndex:     index,
+	}
+}
+
+// hasTags 实例是否包含全部的 tag
+func hasTags(ins *model.Instance, tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	own := instanceTags(ins)
+	for _, tag := range tags {
+		found := false
+		for _, item := range own {
+			if item == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// instancesRevision 根据实例的 revision 以及健康状态计算服务实例列表的版本
+func instancesRevision(instances []*model.Instance) string {
+	items := make([]string, 0, len(instances))
+	for _, ins := range instances {
+		items = append(items, ins.ID()+"|"+ins.Revision()+"|"+strconv.FormatBool(ins.Healthy())+"|"+
+			strconv.FormatBool(ins.Isolate()))
+	}
+	sort.Strings(items)
+	sum := sha1.Sum([]byte(strings.Join(items, ",")))
+	return hex.EncodeToString(sum[:])
+}
+
+// instancesMtime 返回实例列表中最后的修改时间
+func instancesMtime(instances []*model.Instance) time.Time {
+	var mtime time.Time
+	for _, ins := range instances {
+		if ins.ModifyTime.After(mtime) {
+			mtime = ins.ModifyTime
+		}
+	}
+	return mtime
+}
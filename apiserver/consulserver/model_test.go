@@ -0,0 +1,133 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+func TestConvertRegistration(t *testing.T) {
+	reg := &AgentServiceRegistration{
+		ID:      "web-1",
+		Name:    "web",
+		Tags:    []string{"v1", "primary"},
+		Port:    8080,
+		Meta:    map[string]string{"env": "prod"},
+		Weights: &AgentWeights{Passing: 10, Warning: 1},
+		Checks: []*AgentServiceCheck{
+			{HTTP: "http://127.0.0.1:8080/health", Interval: "10s"},
+			{TTL: "1500ms", Status: HealthPassing},
+		},
+	}
+	ins, err := convertRegistration(reg, "default", "default", "10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "web-1", ins.GetId().GetValue())
+	assert.Equal(t, "10.0.0.1", ins.GetHost().GetValue())
+	assert.Equal(t, uint32(10), ins.GetWeight().GetValue())
+	assert.Equal(t, uint32(2), ins.GetHealthCheck().GetHeartbeat().GetTtl().GetValue())
+	assert.True(t, ins.GetHealthy().GetValue())
+	assert.Equal(t, "prod", ins.GetMetadata()["env"])
+
+	// 非默认命名空间的实例 id 增加命名空间前缀
+	ins, err = convertRegistration(&AgentServiceRegistration{Name: "web", Address: "10.0.0.2",
+		Check: &AgentServiceCheck{TTL: "10s"}}, "test", "default", "10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "test:web", ins.GetId().GetValue())
+	assert.Equal(t, "10.0.0.2", ins.GetHost().GetValue())
+	assert.False(t, ins.GetHealthy().GetValue())
+	assert.Equal(t, uint32(defaultWeight), ins.GetWeight().GetValue())
+
+	_, err = convertRegistration(&AgentServiceRegistration{Name: "web", Check: &AgentServiceCheck{TTL: "abc"}},
+		"default", "default", "10.0.0.1")
+	assert.Error(t, err)
+
+	saved := &model.Instance{Proto: ins, Valid: true}
+	svc := toAgentService(saved, "dc1", 1)
+	assert.Equal(t, "web", svc.ID)
+	assert.Empty(t, svc.Tags)
+	assert.NotContains(t, svc.Meta, MetadataServiceID)
+	assert.Equal(t, HealthCritical, toHealthChecks(saved, 1)[1].Status)
+}
+
+func TestFilterKeys(t *testing.T) {
+	releases := []*model.ConfigFileRelease{}
+	for _, key := range []string{"app/a", "app/b/c", "app/b/d", "other"} {
+		releases = append(releases, &model.ConfigFileRelease{SimpleConfigFileRelease: &model.SimpleConfigFileRelease{
+			ConfigFileReleaseKey: &model.ConfigFileReleaseKey{FileName: key},
+		}})
+	}
+	assert.Equal(t, []string{"app/a", "app/b/c", "app/b/d", "other"}, filterKeys(releases, "", ""))
+	assert.Equal(t, []string{"app/", "other"}, filterKeys(releases, "", "/"))
+	assert.Equal(t, []string{"app/a", "app/b/"}, filterKeys(releases[:3], "app/", "/"))
+}
+
+func TestDataIndex(t *testing.T) {
+	mtime := time.Unix(1700000000, 0)
+	// 相同的数据在不同节点上计算出相同的 index
+	first := dataIndex(mtime, "r1")
+	assert.Equal(t, first, dataIndex(mtime, "r1"))
+	// 同一秒内数据变化同样会改变 index
+	assert.NotEqual(t, first, dataIndex(mtime, "r2"))
+	// 数据更新后 index 递增
+	assert.Greater(t, dataIndex(mtime.Add(time.Second), "r2"), first)
+	// 数据不存在时 index 为 1
+	assert.Equal(t, uint64(1), dataIndex(time.Time{}, ""))
+}
+
+func TestBlockingQuery(t *testing.T) {
+	mtime := time.Unix(1700000000, 0)
+	second := dataIndex(mtime, "r2")
+
+	// 数据没有变化时等待到超时
+	start := time.Now()
+	_, index := blockingQuery(context.Background(), second, time.Second,
+		func() (interface{}, uint64) {
+			return nil, dataIndex(mtime, "r2")
+		})
+	assert.Equal(t, second, index)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+
+	// 客户端持有其他节点返回的 index 时立即返回
+	_, index = blockingQuery(context.Background(), second+1, 5*time.Second,
+		func() (interface{}, uint64) {
+			return nil, second
+		})
+	assert.Equal(t, second, index)
+
+	// 数据变化后立即返回新的 index
+	revision := atomic.Value{}
+	revision.Store("r2")
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		revision.Store("r3")
+	}()
+	start = time.Now()
+	_, index = blockingQuery(context.Background(), second, 5*time.Second,
+		func() (interface{}, uint64) {
+			return nil, dataIndex(mtime, revision.Load().(string))
+		})
+	assert.NotEqual(t, second, index)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
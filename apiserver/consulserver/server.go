@@ -0,0 +1,364 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/apiserver"
+	"github.com/polarismesh/polaris/cache"
+	"github.com/polarismesh/polaris/common/conn/keepalive"
+	connlimit "github.com/polarismesh/polaris/common/conn/limit"
+	"github.com/polarismesh/polaris/common/metrics"
+	"github.com/polarismesh/polaris/common/secure"
+	"github.com/polarismesh/polaris/config"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/service"
+	"github.com/polarismesh/polaris/service/healthcheck"
+)
+
+const (
+	ServerConsul = "service-consul"
+
+	// MetadataRegisterFrom 标记实例由 consul 协议注册
+	MetadataRegisterFrom = "internal-register-from"
+	// MetadataServiceID consul 注册时的 service id
+	MetadataServiceID = "internal-consul-service-id"
+	// MetadataTags consul 注册时的 tags，JSON 数组
+	MetadataTags = "internal-consul-tags"
+	// MetadataCheckTTL consul TTL check 的时长
+	MetadataCheckTTL = "internal-consul-check-ttl"
+	// MetadataCheckNotes 最近一次 check 上报的 output
+	MetadataCheckNotes = "internal-consul-check-output"
+
+	registerFromConsul = "consul"
+
+	headerIndex       = "X-Consul-Index"
+	headerKnownLeader = "X-Consul-Knownleader"
+	headerLastContact = "X-Consul-Lastcontact"
+	headerToken       = "X-Consul-Token"
+
+	attrStartTime  = "start-time"
+	attrStatusCode = "polaris-code"
+)
+
+// ConsulServer 兼容 consul agent、catalog、health 以及 KV 接口的 apiserver
+type ConsulServer struct {
+	server            *http.Server
+	namingServer      service.DiscoverServer
+	originDiscoverSvr service.DiscoverServer
+	configServer      config.ConfigCenterServer
+	healthCheckServer *healthcheck.Server
+	cacheMgr          *cache.CacheManager
+	connLimitConfig   *connlimit.Config
+	tlsInfo           *secure.TLSInfo
+	option            map[string]interface{}
+	openAPI           map[string]apiserver.APIConfig
+	listenIP          string
+	listenPort        uint32
+	namespace         string
+	datacenter        string
+	kvGroup           string
+	exitCh            chan struct{}
+	start             bool
+	restart           bool
+	rateLimit         plugin.Ratelimit
+	statis            plugin.Statis
+}
+
+// GetPort 获取端口
+func (h *ConsulServer) GetPort() uint32 {
+	return h.listenPort
+}
+
+// GetProtocol 获取协议
+func (h *ConsulServer) GetProtocol() string {
+	return ServerConsul
+}
+
+// Initialize 初始化 consul API 服务器
+func (h *ConsulServer) Initialize(_ context.Context, option map[string]interface{},
+	api map[string]apiserver.APIConfig) error {
+	h.option = option
+	h.openAPI = api
+	h.listenIP = DefaultListenIP
+	if value, ok := option[optionListenIP].(string); ok && value != "" {
+		h.listenIP = value
+	}
+	h.listenPort = DefaultListenPort
+	if value, ok := option[optionListenPort].(int); ok && value > 0 {
+		h.listenPort = uint32(value)
+	}
+	h.namespace = DefaultNamespace
+	if value, ok := option[optionNamespace].(string); ok && value != "" {
+		h.namespace = value
+	}
+	h.datacenter = DefaultDatacenter
+	if value, ok := option[optionDatacenter].(string); ok && value != "" {
+		h.datacenter = value
+	}
+	h.kvGroup = DefaultKVGroup
+	if value, ok := option[optionKVGroup].(string); ok && value != "" {
+		h.kvGroup = value
+	}
+
+	// 连接数限制的配置
+	if raw, _ := option[optionConnLimit].(map[interface{}]interface{}); raw != nil {
+		connLimitConfig, err := connlimit.ParseConnLimitConfig(raw)
+		if err != nil {
+			return err
+		}
+		h.connLimitConfig = connLimitConfig
+	}
+	if raw, _ := option[optionTLS].(map[interface{}]interface{}); raw != nil {
+		tlsConfig, err := secure.ParseTLSConfig(raw)
+		if err != nil {
+			return err
+		}
+		h.tlsInfo = &secure.TLSInfo{
+			CertFile:      tlsConfig.CertFile,
+			KeyFile:       tlsConfig.KeyFile,
+			TrustedCAFile: tlsConfig.TrustedCAFile,
+		}
+	}
+	return nil
+}
+
+// Run 启动 consul API 服务器
+func (h *ConsulServer) Run(errCh chan error) {
+	consullog.Infof("start ConsulServer")
+	h.exitCh = make(chan struct{})
+	h.start = true
+	defer func() {
+		close(h.exitCh)
+		h.start = false
+	}()
+	if err := h.prepare(); err != nil {
+		consullog.Errorf("%v", err)
+		errCh <- err
+		return
+	}
+	address := fmt.Sprintf("%v:%v", h.listenIP, h.listenPort)
+	// 阻塞查询最长等待 MaxBlockingWait，写超时需要大于该值
+	server := http.Server{Addr: address, Handler: h.createRestfulContainer(),
+		WriteTimeout: MaxBlockingWait + time.Minute}
+
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		consullog.Errorf("net listen(%s) err: %s", address, err.Error())
+		errCh <- err
+		return
+	}
+	ln = keepalive.NewTcpKeepAliveListener(3*time.Minute, ln.(*net.TCPListener))
+	// 开启最大连接数限制
+	if h.connLimitConfig != nil && h.connLimitConfig.OpenConnLimit {
+		consullog.Infof("consul server use max connection limit per ip: %d, max limit: %d",
+			h.connLimitConfig.MaxConnPerHost, h.connLimitConfig.MaxConnLimit)
+		ln, err = connlimit.NewListener(ln, h.GetProtocol(), h.connLimitConfig)
+		if err != nil {
+			consullog.Errorf("conn limit init err: %s", err.Error())
+			errCh <- err
+			return
+		}
+	}
+	h.server = &server
+
+	if h.tlsInfo.IsEmpty() {
+		err = server.Serve(ln)
+	} else {
+		err = server.ServeTLS(ln, h.tlsInfo.CertFile, h.tlsInfo.KeyFile)
+	}
+	if err != nil && err != http.ErrServerClosed {
+		consullog.Errorf("%+v", err)
+		if !h.restart {
+			consullog.Infof("not in restart progress, broadcast error")
+			errCh <- err
+		}
+		return
+	}
+	consullog.Infof("ConsulServer stop")
+}
+
+// prepare 引入功能模块和插件
+func (h *ConsulServer) prepare() error {
+	var err error
+	if h.namingServer, err = service.GetServer(); err != nil {
+		return err
+	}
+	if h.originDiscoverSvr, err = service.GetOriginServer(); err != nil {
+		return err
+	}
+	if h.configServer, err = config.GetServer(); err != nil {
+		return err
+	}
+	if h.healthCheckServer, err = healthcheck.GetServer(); err != nil {
+		return err
+	}
+	h.cacheMgr = h.originDiscoverSvr.Cache()
+	h.rateLimit = plugin.GetRatelimit()
+	h.statis = plugin.GetStatis()
+	return nil
+}
+
+// createRestfulContainer 创建handler
+func (h *ConsulServer) createRestfulContainer() *restful.Container {
+	wsContainer := restful.NewContainer()
+	wsContainer.Filter(h.process)
+	wsContainer.Add(h.GetConsulServer())
+	wsContainer.RecoverHandler(h.recoverFunc)
+	return wsContainer
+}
+
+func (h *ConsulServer) recoverFunc(i interface{}, w http.ResponseWriter) {
+	consullog.Errorf("panic %+v", i)
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+// process 在接收和回复时统一处理请求
+func (h *ConsulServer) process(req *restful.Request, rsp *restful.Response, chain *restful.FilterChain) {
+	func() {
+		if err := h.preprocess(req, rsp); err != nil {
+			return
+		}
+		chain.ProcessFilter(req, rsp)
+	}()
+	h.postprocess(req, rsp)
+}
+
+// preprocess 请求预处理：记录写请求、限流
+func (h *ConsulServer) preprocess(req *restful.Request, rsp *restful.Response) error {
+	req.SetAttribute(attrStartTime, time.Now())
+	if req.Request.Method != http.MethodGet {
+		accesslog.Info("receive request",
+			zap.String("client-address", req.Request.RemoteAddr),
+			zap.String("user-agent", req.HeaderParameter("User-Agent")),
+			zap.String("method", req.Request.Method),
+			zap.String("url", req.Request.URL.String()),
+		)
+	}
+	return h.enterRateLimit(req, rsp)
+}
+
+// enterRateLimit 访问限制
+func (h *ConsulServer) enterRateLimit(req *restful.Request, rsp *restful.Response) error {
+	if h.rateLimit == nil {
+		return nil
+	}
+	address := req.Request.RemoteAddr
+	segments := strings.Split(address, ":")
+	if len(segments) == 2 && !h.rateLimit.Allow(plugin.IPRatelimit, segments[0]) {
+		accesslog.Error("ip ratelimit is not allow", zap.String("client", address))
+		rsp.WriteHeader(http.StatusTooManyRequests)
+		return errors.New("ip ratelimit is not allow")
+	}
+	apiName := getConsulApi(req)
+	if !h.rateLimit.Allow(plugin.APIRatelimit, apiName) {
+		accesslog.Error("api ratelimit is not allow", zap.String("client", address), zap.String("api", apiName))
+		rsp.WriteHeader(http.StatusTooManyRequests)
+		return errors.New("api ratelimit is not allow")
+	}
+	return nil
+}
+
+// postprocess 请求后处理：统计
+func (h *ConsulServer) postprocess(req *restful.Request, rsp *restful.Response) {
+	startTime, _ := req.Attribute(attrStartTime).(time.Time)
+	diff := time.Since(startTime)
+	code, ok := req.Attribute(attrStatusCode).(uint32)
+	if !ok {
+		code = uint32(rsp.StatusCode())
+		if code == http.StatusNotFound {
+			return
+		}
+	}
+	// 阻塞查询本身耗时较长，不打印
+	if diff > time.Second && req.QueryParameter(paramIndex) == "" {
+		accesslog.Info("handling time > 1s",
+			zap.String("client-address", req.Request.RemoteAddr),
+			zap.String("method", req.Request.Method),
+			zap.String("url", req.Request.URL.String()),
+			zap.Duration("handling-time", diff),
+		)
+	}
+	if h.statis != nil {
+		h.statis.ReportCallMetrics(metrics.CallMetric{
+			API:      getConsulApi(req),
+			Protocol: "HTTP",
+			Code:     int(code),
+			Duration: diff,
+		})
+	}
+}
+
+// getConsulApi 聚合 consul 接口，不暴露服务名、实例 id 以及 KV 的 key
+func getConsulApi(req *restful.Request) string {
+	if route := req.SelectedRoutePath(); route != "" {
+		return req.Request.Method + ":" + route
+	}
+	return req.Request.Method + ":" + req.Request.URL.Path
+}
+
+// Stop 结束 consul API 服务器的运行
+func (h *ConsulServer) Stop() {
+	connlimit.RemoveLimitListener(h.GetProtocol())
+	if h.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := h.server.Shutdown(ctx); nil != err {
+			consullog.Errorf("ConsulServer shutdown failed, err: %v", err)
+		}
+	}
+}
+
+// Restart 重启 consul API 服务器
+func (h *ConsulServer) Restart(
+	option map[string]interface{}, api map[string]apiserver.APIConfig, errCh chan error) error {
+	consullog.Infof("restart consul server new config: %+v", option)
+	backupOption := h.option
+	backupAPI := h.openAPI
+
+	// 设置restart标记，防止stop的时候把错误抛出
+	h.restart = true
+	h.Stop()
+	if h.start {
+		<-h.exitCh
+	}
+
+	if err := h.Initialize(context.Background(), option, api); err != nil {
+		h.restart = false
+		if initErr := h.Initialize(context.Background(), backupOption, backupAPI); initErr != nil {
+			consullog.Errorf("start consul server with backup cfg err: %s", initErr.Error())
+			return initErr
+		}
+		go h.Run(errCh)
+		consullog.Errorf("restart consul server initialize err: %s", err.Error())
+		return err
+	}
+	h.restart = false
+	go h.Run(errCh)
+	return nil
+}
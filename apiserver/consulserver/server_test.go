@@ -0,0 +1,235 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	apiconfig "github.com/polarismesh/specification/source/go/api/v1/config_manage"
+	"github.com/stretchr/testify/assert"
+
+	_ "github.com/polarismesh/polaris/cache"
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/utils"
+	_ "github.com/polarismesh/polaris/plugin/healthchecker/memory"
+	_ "github.com/polarismesh/polaris/plugin/history/logger"
+	_ "github.com/polarismesh/polaris/plugin/password"
+	_ "github.com/polarismesh/polaris/store/boltdb"
+	testsuit "github.com/polarismesh/polaris/test/suit"
+)
+
+type consulClient struct {
+	t     *testing.T
+	url   string
+	token string
+}
+
+func (c *consulClient) do(method, path string, body []byte) (*http.Response, []byte) {
+	req, err := http.NewRequest(method, c.url+path, bytes.NewReader(body))
+	assert.NoError(c.t, err)
+	req.Header.Set(headerToken, c.token)
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := http.DefaultClient.Do(req)
+	assert.NoError(c.t, err)
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+	data, err := io.ReadAll(rsp.Body)
+	assert.NoError(c.t, err)
+	return rsp, data
+}
+
+func (c *consulClient) healthService(query string) ([]*ServiceEntry, uint64) {
+	rsp, data := c.do(http.MethodGet, "/v1/health/service/consul-web"+query, nil)
+	assert.Equal(c.t, http.StatusOK, rsp.StatusCode, string(data))
+	entries := []*ServiceEntry{}
+	assert.NoError(c.t, json.Unmarshal(data, &entries))
+	index, err := strconv.ParseUint(rsp.Header.Get(headerIndex), 10, 64)
+	assert.NoError(c.t, err)
+	return entries, index
+}
+
+func newConsulServerForTest(t *testing.T) (*testsuit.DiscoverTestSuit, *consulClient) {
+	discoverSuit := &testsuit.DiscoverTestSuit{}
+	if err := discoverSuit.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(discoverSuit.Destroy)
+
+	svr := &ConsulServer{
+		namingServer:      discoverSuit.DiscoverServer(),
+		originDiscoverSvr: discoverSuit.OriginDiscoverServer(),
+		configServer:      discoverSuit.ConfigServer(),
+		healthCheckServer: discoverSuit.HealthCheckServer(),
+		cacheMgr:          discoverSuit.CacheMgr(),
+	}
+	assert.NoError(t, svr.Initialize(context.Background(), map[string]interface{}{}, nil))
+	httpSvr := httptest.NewServer(svr.createRestfulContainer())
+	t.Cleanup(httpSvr.Close)
+	return discoverSuit, &consulClient{t: t, url: httpSvr.URL, token: utils.ParseAuthToken(discoverSuit.DefaultCtx)}
+}
+
+func TestConsulServer_Service(t *testing.T) {
+	discoverSuit, client := newConsulServerForTest(t)
+	t.Cleanup(func() {
+		discoverSuit.CleanService("consul-web", DefaultNamespace)
+	})
+
+	reg := &AgentServiceRegistration{
+		ID:      "consul-web-1",
+		Name:    "consul-web",
+		Tags:    []string{"v1"},
+		Address: "127.0.0.10",
+		Port:    8080,
+		Meta:    map[string]string{"env": "test"},
+		Check:   &AgentServiceCheck{TTL: "10s", Status: HealthPassing},
+	}
+	body, _ := json.Marshal(reg)
+	rsp, data := client.do(http.MethodPut, "/v1/agent/service/register", body)
+	assert.Equal(t, http.StatusOK, rsp.StatusCode, string(data))
+	// 注册后立即上报 TTL 心跳，此时实例还没有同步到缓存中
+	rsp, data = client.do(http.MethodPut, "/v1/agent/check/pass/service:consul-web-1", nil)
+	assert.Equal(t, http.StatusOK, rsp.StatusCode, string(data))
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+
+	entries, index := client.healthService("?passing&tag=v1")
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "consul-web-1", entries[0].Service.ID)
+	assert.Equal(t, []string{"v1"}, entries[0].Service.Tags)
+	assert.Equal(t, "test", entries[0].Service.Meta["env"])
+	assert.Equal(t, 8080, entries[0].Service.Port)
+	entries, _ = client.healthService("?tag=v2")
+	assert.Empty(t, entries)
+
+	rsp, data = client.do(http.MethodGet, "/v1/catalog/services", nil)
+	assert.Equal(t, http.StatusOK, rsp.StatusCode, string(data))
+	services := map[string][]string{}
+	assert.NoError(t, json.Unmarshal(data, &services))
+	assert.Equal(t, []string{"v1"}, services["consul-web"])
+
+	rsp, _ = client.do(http.MethodPut, "/v1/agent/check/pass/service:consul-web-1", nil)
+	assert.Equal(t, http.StatusOK, rsp.StatusCode)
+	// 心跳上报允许实例短暂不存在（刚注册时缓存还未同步），连续上报后才返回 404
+	for i := 0; i < 5; i++ {
+		rsp, _ = client.do(http.MethodPut, "/v1/agent/check/pass/service:unknown", nil)
+	}
+	assert.Equal(t, http.StatusNotFound, rsp.StatusCode)
+
+	// 阻塞查询在 check 失败后返回新的 index
+	done := make(chan struct{})
+	var (
+		blocked      []*ServiceEntry
+		blockedIndex uint64
+	)
+	go func() {
+		defer close(done)
+		blocked, blockedIndex = client.healthService("?index=" + strconv.FormatUint(index, 10) + "&wait=30s")
+	}()
+	update, _ := json.Marshal(&CheckUpdate{Status: HealthCritical, Output: "db down"})
+	rsp, data = client.do(http.MethodPut, "/v1/agent/check/update/service:consul-web-1", update)
+	assert.Equal(t, http.StatusOK, rsp.StatusCode, string(data))
+	select {
+	case <-done:
+	case <-time.After(20 * time.Second):
+		t.Fatal("blocking query not return after check fail")
+	}
+	assert.NotEqual(t, index, blockedIndex)
+	assert.Len(t, blocked, 1)
+	assert.Equal(t, HealthCritical, blocked[0].Checks[1].Status)
+	assert.Equal(t, "db down", blocked[0].Checks[1].Output)
+	entries, _ = client.healthService("?passing")
+	assert.Empty(t, entries)
+
+	rsp, _ = client.do(http.MethodPut, "/v1/agent/service/deregister/consul-web-1", nil)
+	assert.Equal(t, http.StatusOK, rsp.StatusCode)
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+	entries, _ = client.healthService("")
+	assert.Empty(t, entries)
+}
+
+func TestConsulServer_KV(t *testing.T) {
+	discoverSuit, client := newConsulServerForTest(t)
+
+	rsp, data := client.do(http.MethodPut, "/v1/kv/app/db/url", []byte("mysql://db"))
+	assert.Equal(t, http.StatusOK, rsp.StatusCode, string(data))
+	assert.Equal(t, "true", string(data))
+	rsp, data = client.do(http.MethodPut, "/v1/kv/app/name?cas=0", []byte("web"))
+	assert.Equal(t, http.StatusOK, rsp.StatusCode, string(data))
+	assert.Equal(t, "true", string(data))
+	// key 已经存在时 cas=0 失败
+	_, data = client.do(http.MethodPut, "/v1/kv/app/name?cas=0", []byte("web2"))
+	assert.Equal(t, "false", string(data))
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+
+	rsp, data = client.do(http.MethodGet, "/v1/kv/app/db/url", nil)
+	assert.Equal(t, http.StatusOK, rsp.StatusCode, string(data))
+	pairs := []*KVPair{}
+	assert.NoError(t, json.Unmarshal(data, &pairs))
+	assert.Len(t, pairs, 1)
+	assert.Equal(t, "mysql://db", string(pairs[0].Value))
+
+	_, data = client.do(http.MethodGet, "/v1/kv/app/name?raw", nil)
+	assert.Equal(t, "web", string(data))
+
+	_, data = client.do(http.MethodGet, "/v1/kv/app/?keys&separator=/", nil)
+	keys := []string{}
+	assert.NoError(t, json.Unmarshal(data, &keys))
+	assert.Equal(t, []string{"app/db/", "app/name"}, keys)
+
+	_, data = client.do(http.MethodGet, "/v1/kv/app?recurse", nil)
+	pairs = []*KVPair{}
+	assert.NoError(t, json.Unmarshal(data, &pairs))
+	assert.Len(t, pairs, 2)
+
+	// 加密的配置返回解密后的内容
+	secret := &apiconfig.ConfigFile{
+		Namespace:   utils.NewStringValue(DefaultNamespace),
+		Group:       utils.NewStringValue(DefaultKVGroup),
+		Name:        utils.NewStringValue("app/secret"),
+		Content:     utils.NewStringValue("password"),
+		Format:      utils.NewStringValue(kvFormat),
+		Encrypted:   utils.NewBoolValue(true),
+		EncryptAlgo: utils.NewStringValue("AES"),
+	}
+	createRsp := discoverSuit.ConfigServer().CreateConfigFile(discoverSuit.DefaultCtx, secret)
+	assert.Equal(t, api.ExecuteSuccess, createRsp.GetCode().GetValue(), createRsp.GetInfo().GetValue())
+	publishRsp := discoverSuit.ConfigServer().PublishConfigFile(discoverSuit.DefaultCtx, &apiconfig.ConfigFileRelease{
+		Namespace: secret.Namespace,
+		Group:     secret.Group,
+		FileName:  secret.Name,
+	})
+	assert.Equal(t, api.ExecuteSuccess, publishRsp.GetCode().GetValue(), publishRsp.GetInfo().GetValue())
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+	_, data = client.do(http.MethodGet, "/v1/kv/app/secret?raw", nil)
+	assert.Equal(t, "password", string(data))
+
+	rsp, _ = client.do(http.MethodDelete, "/v1/kv/app?recurse", nil)
+	assert.Equal(t, http.StatusOK, rsp.StatusCode)
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+	rsp, _ = client.do(http.MethodGet, "/v1/kv/app/name", nil)
+	assert.Equal(t, http.StatusNotFound, rsp.StatusCode)
+	assert.Equal(t, "1", rsp.Header.Get(headerIndex))
+}
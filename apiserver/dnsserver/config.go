@@ -0,0 +1,118 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dnsserver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	optionListenIP          = "listenIP"
+	optionListenPort        = "listenPort"
+	optionDomain            = "domain"
+	optionTTL               = "ttl"
+	optionNegativeTTL       = "negativeTtl"
+	optionNegativeCacheSize = "negativeCacheSize"
+	optionMaxAnswers        = "maxAnswers"
+	optionEnableRouting     = "enableRouting"
+	optionEnableNearby      = "enableNearby"
+)
+
+const (
+	DefaultListenIP   = "0.0.0.0"
+	DefaultListenPort = 53
+	// DefaultDomain 权威解析的域，查询格式为 <service>.<namespace>.<domain>.
+	DefaultDomain = "polaris."
+	// DefaultTTL 应答记录的 TTL，单位秒
+	DefaultTTL = 5
+	// DefaultNegativeTTL NXDOMAIN 以及空应答的缓存时间，即 SOA 的 minimum，单位秒
+	DefaultNegativeTTL = 30
+	// DefaultNegativeCacheSize 服务端缓存的 NXDOMAIN 域名个数
+	DefaultNegativeCacheSize = 10000
+	// DefaultMaxAnswers 单个应答中最多返回的实例个数
+	DefaultMaxAnswers = 16
+
+	// tcpIdleTimeout TCP 连接的空闲超时时间
+	tcpIdleTimeout = 10 * time.Second
+	// udpMinSize 没有 EDNS0 时 UDP 应答的最大长度
+	udpMinSize = 512
+	// udpMaxSize EDNS0 声明的 UDP 应答长度上限
+	udpMaxSize = 4096
+)
+
+// Config DNS apiserver 配置
+type Config struct {
+	ListenIP          string
+	ListenPort        uint32
+	Domain            string
+	TTL               uint32
+	NegativeTTL       uint32
+	NegativeCacheSize int
+	MaxAnswers        int
+	EnableRouting     bool
+	EnableNearby      bool
+}
+
+// parseConfig 解析 apiserver 的 option
+func parseConfig(option map[string]interface{}) (*Config, error) {
+	conf := &Config{
+		ListenIP:          DefaultListenIP,
+		ListenPort:        DefaultListenPort,
+		Domain:            DefaultDomain,
+		TTL:               DefaultTTL,
+		NegativeTTL:       DefaultNegativeTTL,
+		NegativeCacheSize: DefaultNegativeCacheSize,
+		MaxAnswers:        DefaultMaxAnswers,
+		EnableRouting:     true,
+		EnableNearby:      true,
+	}
+	if value, ok := option[optionListenIP].(string); ok && value != "" {
+		conf.ListenIP = value
+	}
+	if value, ok := option[optionListenPort].(int); ok && value > 0 {
+		conf.ListenPort = uint32(value)
+	}
+	if value, ok := option[optionDomain].(string); ok && value != "" {
+		conf.Domain = value
+	}
+	conf.Domain = strings.ToLower(strings.Trim(conf.Domain, ".")) + "."
+	if conf.Domain == "." {
+		return nil, fmt.Errorf("dns domain can not be root")
+	}
+	if value, ok := option[optionTTL].(int); ok && value >= 0 {
+		conf.TTL = uint32(value)
+	}
+	if value, ok := option[optionNegativeTTL].(int); ok && value >= 0 {
+		conf.NegativeTTL = uint32(value)
+	}
+	if value, ok := option[optionNegativeCacheSize].(int); ok && value >= 0 {
+		conf.NegativeCacheSize = value
+	}
+	if value, ok := option[optionMaxAnswers].(int); ok && value > 0 {
+		conf.MaxAnswers = value
+	}
+	if value, ok := option[optionEnableRouting].(bool); ok {
+		conf.EnableRouting = value
+	}
+	if value, ok := option[optionEnableNearby].(bool); ok {
+		conf.EnableNearby = value
+	}
+	return conf, nil
+}
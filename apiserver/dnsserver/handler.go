@@ -0,0 +1,370 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dnsserver
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/polarismesh/polaris/common/metrics"
+	"github.com/polarismesh/polaris/common/model"
+)
+
+// answer 单个问题的应答
+type answer struct {
+	rcode       dnsmessage.RCode
+	answers     []dnsmessage.Resource
+	authorities []dnsmessage.Resource
+	additionals []dnsmessage.Resource
+}
+
+// handle 处理一个 DNS 请求报文，返回 nil 表示丢弃该请求
+func (h *DNSServer) handle(msg []byte, clientIP string, udp bool) []byte {
+	start := time.Now()
+	var parser dnsmessage.Parser
+	reqHeader, err := parser.Start(msg)
+	if err != nil || reqHeader.Response {
+		return nil
+	}
+	rspHeader := dnsmessage.Header{
+		ID:               reqHeader.ID,
+		Response:         true,
+		OpCode:           reqHeader.OpCode,
+		Authoritative:    true,
+		RecursionDesired: reqHeader.RecursionDesired,
+	}
+	rspMsg := &dnsmessage.Message{Header: rspHeader}
+
+	questions, err := parser.AllQuestions()
+	if err != nil || len(questions) != 1 {
+		rspMsg.RCode = dnsmessage.RCodeFormatError
+		return h.pack(rspMsg, udpMinSize, udp)
+	}
+	question := questions[0]
+	rspMsg.Questions = questions
+
+	// EDNS0 中声明的 UDP 应答长度
+	maxSize, opt := readEDNS(&parser)
+	if opt != nil {
+		rspMsg.Additionals = append(rspMsg.Additionals, *opt)
+	}
+
+	if reqHeader.OpCode != 0 {
+		rspMsg.RCode = dnsmessage.RCodeNotImplemented
+	} else {
+		ret := h.answer(question, clientIP)
+		rspMsg.RCode = ret.rcode
+		rspMsg.Answers = ret.answers
+		rspMsg.Authorities = ret.authorities
+		rspMsg.Additionals = append(ret.additionals, rspMsg.Additionals...)
+	}
+	out := h.pack(rspMsg, maxSize, udp)
+	if h.statis != nil {
+		h.statis.ReportCallMetrics(metrics.CallMetric{
+			API:      "DNS:" + strings.TrimPrefix(question.Type.String(), "Type"),
+			Protocol: "DNS",
+			Code:     int(rspMsg.RCode),
+			Duration: time.Since(start),
+		})
+	}
+	return out
+}
+
+// readEDNS 读取请求中的 OPT 记录，返回 UDP 应答长度上限以及应答中需要携带的 OPT 记录
+func readEDNS(parser *dnsmessage.Parser) (int, *dnsmessage.Resource) {
+	if err := parser.SkipAllAnswers(); err != nil {
+		return udpMinSize, nil
+	}
+	if err := parser.SkipAllAuthorities(); err != nil {
+		return udpMinSize, nil
+	}
+	for {
+		header, err := parser.AdditionalHeader()
+		if err != nil {
+			return udpMinSize, nil
+		}
+		if header.Type != dnsmessage.TypeOPT {
+			if err := parser.SkipAdditional(); err != nil {
+				return udpMinSize, nil
+			}
+			continue
+		}
+		size := int(header.Class)
+		if size < udpMinSize {
+			size = udpMinSize
+		}
+		if size > udpMaxSize {
+			size = udpMaxSize
+		}
+		opt := dnsmessage.Resource{Body: &dnsmessage.OPTResource{}}
+		_ = opt.Header.SetEDNS0(udpMaxSize, dnsmessage.RCodeSuccess, false)
+		return size, &opt
+	}
+}
+
+// pack 序列化应答，UDP 应答超过长度限制时只返回问题并设置 TC 标记，客户端会改用 TCP 重试
+func (h *DNSServer) pack(msg *dnsmessage.Message, maxSize int, udp bool) []byte {
+	out, err := msg.Pack()
+	if err != nil {
+		dnslog.Error("[DNS] pack response", zap.Error(err))
+		msg.RCode = dnsmessage.RCodeServerFailure
+		msg.Answers, msg.Authorities, msg.Additionals = nil, nil, nil
+		out, _ = msg.Pack()
+		return out
+	}
+	if !udp || len(out) <= maxSize {
+		return out
+	}
+	msg.Truncated = true
+	msg.Answers, msg.Authorities = nil, nil
+	additionals := msg.Additionals[:0]
+	for _, item := range msg.Additionals {
+		if item.Header.Type == dnsmessage.TypeOPT {
+			additionals = append(additionals, item)
+		}
+	}
+	msg.Additionals = additionals
+	out, _ = msg.Pack()
+	return out
+}
+
+// answer 解析 <service>.<namespace>.<domain>. 形式的域名
+// SRV 查询兼容 _<name>._<proto>.<service>.<namespace>.<domain>.，SRV 记录的 target 为
+// <dashed ip>.<service>.<namespace>.<domain>.，同样可以被解析
+// 域名不区分大小写，客户端可能随机化大小写（DNS 0x20），因此统一转换为小写后查询
+func (h *DNSServer) answer(question dnsmessage.Question, clientIP string) *answer {
+	name := strings.ToLower(question.Name.String())
+	domain := h.conf.Domain
+	if name == domain {
+		return h.answerApex(question)
+	}
+	if !strings.HasSuffix(name, "."+domain) {
+		return &answer{rcode: dnsmessage.RCodeRefused}
+	}
+	if h.negatives.hit(name) {
+		return h.nxdomain()
+	}
+	labels := strings.Split(name[:len(name)-len(domain)-1], ".")
+
+	// 实例地址形式的域名
+	if len(labels) >= 3 {
+		if ip := parseDashedIP(labels[0]); ip != nil {
+			if ret := h.answerAddress(question, labels[1:], ip); ret != nil {
+				return ret
+			}
+		}
+	}
+	for len(labels) > 0 && strings.HasPrefix(labels[0], "_") {
+		labels = labels[1:]
+	}
+	if len(labels) < 2 {
+		h.negatives.add(name)
+		return h.nxdomain()
+	}
+	svc := h.resolver.lookupService(strings.Join(labels[:len(labels)-1], "."), labels[len(labels)-1])
+	if svc == nil {
+		h.negatives.add(name)
+		return h.nxdomain()
+	}
+
+	instances := h.resolver.resolve(svc, clientIP)
+	ret := &answer{rcode: dnsmessage.RCodeSuccess}
+	switch question.Type {
+	case dnsmessage.TypeA, dnsmessage.TypeAAAA, dnsmessage.TypeALL:
+		for _, ins := range instances {
+			if rr := h.addressRecord(question.Name, question.Type, ins.Host()); rr != nil {
+				ret.answers = append(ret.answers, *rr)
+			}
+		}
+	case dnsmessage.TypeSRV:
+		h.appendSRV(ret, question.Name, svc, instances)
+	}
+	if len(ret.answers) == 0 {
+		ret.authorities = []dnsmessage.Resource{h.soa()}
+	}
+	return ret
+}
+
+// answerAddress 解析 SRV target 形式的域名，实例不存在、不健康或者被隔离时返回 nil 按照普通服务域名处理
+func (h *DNSServer) answerAddress(question dnsmessage.Question, labels []string, ip net.IP) *answer {
+	svc := h.resolver.lookupService(strings.Join(labels[:len(labels)-1], "."), labels[len(labels)-1])
+	if svc == nil {
+		return nil
+	}
+	var target *model.Instance
+	for _, ins := range h.resolver.instances.GetInstancesByServiceID(svc.ID) {
+		if !ins.Healthy() || ins.Isolate() {
+			continue
+		}
+		if hostIP := net.ParseIP(ins.Host()); hostIP != nil && hostIP.Equal(ip) {
+			target = ins
+			break
+		}
+	}
+	if target == nil {
+		return nil
+	}
+	ret := &answer{rcode: dnsmessage.RCodeSuccess}
+	if rr := h.addressRecord(question.Name, question.Type, target.Host()); rr != nil {
+		ret.answers = append(ret.answers, *rr)
+	} else {
+		ret.authorities = []dnsmessage.Resource{h.soa()}
+	}
+	return ret
+}
+
+// appendSRV SRV 记录的权重为实例权重，IP 类型的实例在 additional 中携带地址记录
+func (h *DNSServer) appendSRV(ret *answer, name dnsmessage.Name, svc *model.Service, instances []*model.Instance) {
+	for _, ins := range instances {
+		var target dnsmessage.Name
+		var err error
+		if ip := net.ParseIP(ins.Host()); ip != nil {
+			target, err = dnsmessage.NewName(dashedIP(ip) + "." + svc.Name + "." + svc.Namespace + "." + h.conf.Domain)
+			if err == nil {
+				qtype := dnsmessage.TypeA
+				if ip.To4() == nil {
+					qtype = dnsmessage.TypeAAAA
+				}
+				if rr := h.addressRecord(target, qtype, ins.Host()); rr != nil {
+					ret.additionals = append(ret.additionals, *rr)
+				}
+			}
+		} else {
+			target, err = dnsmessage.NewName(strings.TrimSuffix(ins.Host(), ".") + ".")
+		}
+		if err != nil {
+			continue
+		}
+		weight := ins.Weight()
+		if weight > 0xffff {
+			weight = 0xffff
+		}
+		ret.answers = append(ret.answers, dnsmessage.Resource{
+			Header: h.header(name, dnsmessage.TypeSRV, h.conf.TTL),
+			Body: &dnsmessage.SRVResource{
+				Priority: 0,
+				Weight:   uint16(weight),
+				Port:     uint16(ins.Port()),
+				Target:   target,
+			},
+		})
+	}
+}
+
+// addressRecord 根据查询类型返回 A 或者 AAAA 记录，类型不匹配时返回 nil
+func (h *DNSServer) addressRecord(name dnsmessage.Name, qtype dnsmessage.Type, host string) *dnsmessage.Resource {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		if qtype != dnsmessage.TypeA && qtype != dnsmessage.TypeALL {
+			return nil
+		}
+		rr := &dnsmessage.AResource{}
+		copy(rr.A[:], ip4)
+		return &dnsmessage.Resource{Header: h.header(name, dnsmessage.TypeA, h.conf.TTL), Body: rr}
+	}
+	if qtype != dnsmessage.TypeAAAA && qtype != dnsmessage.TypeALL {
+		return nil
+	}
+	rr := &dnsmessage.AAAAResource{}
+	copy(rr.AAAA[:], ip.To16())
+	return &dnsmessage.Resource{Header: h.header(name, dnsmessage.TypeAAAA, h.conf.TTL), Body: rr}
+}
+
+// answerApex 域本身只应答 SOA 以及 NS
+func (h *DNSServer) answerApex(question dnsmessage.Question) *answer {
+	ret := &answer{rcode: dnsmessage.RCodeSuccess}
+	switch question.Type {
+	case dnsmessage.TypeSOA:
+		ret.answers = []dnsmessage.Resource{h.soa()}
+	case dnsmessage.TypeNS:
+		ret.answers = []dnsmessage.Resource{{
+			Header: h.header(h.domainName(), dnsmessage.TypeNS, h.conf.TTL),
+			Body:   &dnsmessage.NSResource{NS: h.nsName()},
+		}}
+	default:
+		ret.authorities = []dnsmessage.Resource{h.soa()}
+	}
+	return ret
+}
+
+// nxdomain 域名不存在，authority 中携带 SOA 用于客户端的否定缓存
+func (h *DNSServer) nxdomain() *answer {
+	return &answer{
+		rcode:       dnsmessage.RCodeNameError,
+		authorities: []dnsmessage.Resource{h.soa()},
+	}
+}
+
+// soa SOA 的 minimum 以及 TTL 为否定缓存时间，参考 RFC 2308
+func (h *DNSServer) soa() dnsmessage.Resource {
+	mbox, _ := dnsmessage.NewName("hostmaster." + h.conf.Domain)
+	return dnsmessage.Resource{
+		Header: h.header(h.domainName(), dnsmessage.TypeSOA, h.conf.NegativeTTL),
+		Body: &dnsmessage.SOAResource{
+			NS:      h.nsName(),
+			MBox:    mbox,
+			Serial:  h.serial,
+			Refresh: 3600,
+			Retry:   600,
+			Expire:  86400,
+			MinTTL:  h.conf.NegativeTTL,
+		},
+	}
+}
+
+func (h *DNSServer) domainName() dnsmessage.Name {
+	name, _ := dnsmessage.NewName(h.conf.Domain)
+	return name
+}
+
+func (h *DNSServer) nsName() dnsmessage.Name {
+	name, _ := dnsmessage.NewName("ns." + h.conf.Domain)
+	return name
+}
+
+func (h *DNSServer) header(name dnsmessage.Name, rtype dnsmessage.Type, ttl uint32) dnsmessage.ResourceHeader {
+	return dnsmessage.ResourceHeader{Name: name, Type: rtype, Class: dnsmessage.ClassINET, TTL: ttl}
+}
+
+// dashedIP 将 IP 中的 . 以及 : 替换为 -，作为域名中的一个 label
+func dashedIP(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return strings.ReplaceAll(ip4.String(), ".", "-")
+	}
+	return strings.ReplaceAll(ip.String(), ":", "-")
+}
+
+// parseDashedIP dashedIP 的逆过程，不是 IP 时返回 nil
+func parseDashedIP(label string) net.IP {
+	if strings.Count(label, "-") == 3 {
+		if ip := net.ParseIP(strings.ReplaceAll(label, "-", ".")); ip != nil && ip.To4() != nil {
+			return ip
+		}
+	}
+	if ip := net.ParseIP(strings.ReplaceAll(label, "-", ":")); ip != nil && ip.To4() == nil {
+		return ip
+	}
+	return nil
+}
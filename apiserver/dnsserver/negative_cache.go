@@ -0,0 +1,83 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dnsserver
+
+import (
+	"sync"
+	"time"
+)
+
+// negativeCache 缓存不存在的域名，服务名区分大小写，因此域名按照原样缓存，
+// 在 negativeTtl 内直接返回 NXDOMAIN，避免不存在的服务反复查询缓存
+type negativeCache struct {
+	lock    sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[string]time.Time
+}
+
+func newNegativeCache(size int, ttl time.Duration) *negativeCache {
+	return &negativeCache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]time.Time),
+	}
+}
+
+func (c *negativeCache) enabled() bool {
+	return c.size > 0 && c.ttl > 0
+}
+
+// hit 域名是否在缓存中并且没有过期
+func (c *negativeCache) hit(name string) bool {
+	if !c.enabled() {
+		return false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	expire, ok := c.entries[name]
+	if !ok {
+		return false
+	}
+	if time.Now().After(expire) {
+		delete(c.entries, name)
+		return false
+	}
+	return true
+}
+
+// add 记录不存在的域名，缓存满时先清理过期数据，仍然满时清空
+func (c *negativeCache) add(name string) {
+	if !c.enabled() {
+		return
+	}
+	now := time.Now()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.entries) >= c.size {
+		for key, expire := range c.entries {
+			if now.After(expire) {
+				delete(c.entries, key)
+			}
+		}
+		if len(c.entries) >= c.size {
+			c.entries = make(map[string]time.Time, c.size)
+		}
+	}
+	c.entries[name] = now.Add(c.ttl)
+}
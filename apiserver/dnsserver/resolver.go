@@ -0,0 +1,269 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dnsserver
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+
+	regexp "github.com/dlclark/regexp2"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apitraffic "github.com/polarismesh/specification/source/go/api/v1/traffic_manage"
+
+	types "github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/plugin"
+)
+
+// resolver 根据缓存中的实例、路由规则以及客户端的地域信息选择应答的实例
+type resolver struct {
+	services  types.ServiceCache
+	instances types.InstanceCache
+	routings  types.RoutingConfigCache
+	cmdb      plugin.CMDB
+	conf      *Config
+	patterns  sync.Map
+}
+
+// lookupService 查询服务，别名服务解析为指向的服务
+func (r *resolver) lookupService(service, namespace string) *model.Service {
+	svc := r.services.GetServiceByName(service, namespace)
+	if svc != nil && svc.IsAlias() {
+		svc = r.services.GetServiceByID(svc.Reference)
+	}
+	return svc
+}
+
+// resolve 返回服务下可以接收流量的实例，依次经过路由规则、就近以及权重排序
+func (r *resolver) resolve(svc *model.Service, clientIP string) []*model.Instance {
+	candidates := make([]*model.Instance, 0, 8)
+	for _, ins := range r.instances.GetInstancesByServiceID(svc.ID) {
+		if ins.Healthy() && !ins.Isolate() && ins.Weight() > 0 {
+			candidates = append(candidates, ins)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	if r.conf.EnableRouting && r.routings != nil {
+		candidates = r.route(svc, clientIP, candidates)
+	}
+	if r.conf.EnableNearby {
+		candidates = r.nearby(clientIP, candidates)
+	}
+	candidates = weightedShuffle(candidates)
+	if len(candidates) > r.conf.MaxAnswers {
+		candidates = candidates[:r.conf.MaxAnswers]
+	}
+	return candidates
+}
+
+// route 使用规则路由选择实例，DNS 请求只能提供客户端 IP，
+// 因此只匹配不带条件或者仅包含 CALLER_IP 条件的规则，没有实例满足规则时返回全部实例
+func (r *resolver) route(svc *model.Service, clientIP string, candidates []*model.Instance) []*model.Instance {
+	rules := r.routings.ListRouterRule(svc.Name, svc.Namespace)
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Priority < rules[j].Priority
+	})
+	for _, rule := range rules {
+		if rule.GetRoutingPolicy() != apitraffic.RoutingPolicy_RulePolicy || rule.RuleRouting == nil {
+			continue
+		}
+		subRules := rule.RuleRouting.GetRules()
+		if len(subRules) == 0 {
+			subRules = []*apitraffic.SubRuleRouting{{
+				Sources:      rule.RuleRouting.GetSources(),
+				Destinations: rule.RuleRouting.GetDestinations(),
+			}}
+		}
+		for _, subRule := range subRules {
+			if !r.matchSources(subRule.GetSources(), clientIP) {
+				continue
+			}
+			if selected := r.selectDestination(svc, subRule.GetDestinations(), candidates); len(selected) > 0 {
+				return selected
+			}
+			return candidates
+		}
+	}
+	return candidates
+}
+
+// matchSources 主调服务必须为全匹配，参数只支持 CALLER_IP
+func (r *resolver) matchSources(sources []*apitraffic.SourceService, clientIP string) bool {
+	if len(sources) == 0 {
+		return true
+	}
+	for _, source := range sources {
+		if !utils.IsMatchAll(source.GetService()) || !utils.IsMatchAll(source.GetNamespace()) {
+			continue
+		}
+		matched := true
+		for _, argument := range source.GetArguments() {
+			if argument.GetType() != apitraffic.SourceMatch_CALLER_IP ||
+				!utils.MatchString(clientIP, argument.GetValue(), r.compile) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// selectDestination 按照优先级选择第一组有实例的目标，同优先级的目标按照权重随机选择
+func (r *resolver) selectDestination(svc *model.Service, destinations []*apitraffic.DestinationGroup,
+	candidates []*model.Instance) []*model.Instance {
+	groups := map[uint32][]*apitraffic.DestinationGroup{}
+	priorities := make([]uint32, 0, len(destinations))
+	for _, dest := range destinations {
+		if dest.GetIsolate() || dest.GetWeight() == 0 {
+			continue
+		}
+		if !matchName(dest.GetService(), svc.Name) || !matchName(dest.GetNamespace(), svc.Namespace) {
+			continue
+		}
+		if _, ok := groups[dest.GetPriority()]; !ok {
+			priorities = append(priorities, dest.GetPriority())
+		}
+		groups[dest.GetPriority()] = append(groups[dest.GetPriority()], dest)
+	}
+	sort.Slice(priorities, func(i, j int) bool {
+		return priorities[i] < priorities[j]
+	})
+	for _, priority := range priorities {
+		type matchedGroup struct {
+			weight    uint32
+			instances []*model.Instance
+		}
+		matched := make([]matchedGroup, 0, len(groups[priority]))
+		var total uint32
+		for _, dest := range groups[priority] {
+			instances := r.filterByLabels(candidates, dest.GetLabels())
+			if len(instances) == 0 {
+				continue
+			}
+			matched = append(matched, matchedGroup{weight: dest.GetWeight(), instances: instances})
+			total += dest.GetWeight()
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		hit := uint32(rand.Int63n(int64(total)))
+		for _, group := range matched {
+			if hit < group.weight {
+				return group.instances
+			}
+			hit -= group.weight
+		}
+	}
+	return nil
+}
+
+func (r *resolver) filterByLabels(candidates []*model.Instance,
+	labels map[string]*apimodel.MatchString) []*model.Instance {
+	ret := make([]*model.Instance, 0, len(candidates))
+	for _, ins := range candidates {
+		matched := true
+		for key, value := range labels {
+			metaValue, ok := ins.Metadata()[key]
+			if !ok || !utils.MatchString(metaValue, value, r.compile) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			ret = append(ret, ins)
+		}
+	}
+	return ret
+}
+
+// compile 缓存规则中的正则表达式
+func (r *resolver) compile(pattern string) *regexp.Regexp {
+	if val, ok := r.patterns.Load(pattern); ok {
+		return val.(*regexp.Regexp)
+	}
+	exp, err := regexp.Compile(pattern, regexp.RE2)
+	if err != nil {
+		return nil
+	}
+	r.patterns.Store(pattern, exp)
+	return exp
+}
+
+// nearby 根据 CMDB 中客户端的地域信息，优先返回同 zone，其次同 region 的实例
+func (r *resolver) nearby(clientIP string, candidates []*model.Instance) []*model.Instance {
+	if r.cmdb == nil || clientIP == "" {
+		return candidates
+	}
+	location, err := r.cmdb.GetLocation(clientIP)
+	if err != nil || location == nil || location.Proto == nil {
+		return candidates
+	}
+	region := location.Proto.GetRegion().GetValue()
+	zone := location.Proto.GetZone().GetValue()
+	sameZone := make([]*model.Instance, 0, len(candidates))
+	sameRegion := make([]*model.Instance, 0, len(candidates))
+	for _, ins := range candidates {
+		if region == "" || ins.Location().GetRegion().GetValue() != region {
+			continue
+		}
+		sameRegion = append(sameRegion, ins)
+		if zone != "" && ins.Location().GetZone().GetValue() == zone {
+			sameZone = append(sameZone, ins)
+		}
+	}
+	if len(sameZone) > 0 {
+		return sameZone
+	}
+	if len(sameRegion) > 0 {
+		return sameRegion
+	}
+	return candidates
+}
+
+func matchName(expect, actual string) bool {
+	return utils.IsMatchAll(expect) || expect == actual
+}
+
+// weightedShuffle 按照实例权重进行随机排序，权重越大越靠前，客户端通常使用第一个地址
+func weightedShuffle(instances []*model.Instance) []*model.Instance {
+	type keyed struct {
+		key float64
+		ins *model.Instance
+	}
+	items := make([]keyed, 0, len(instances))
+	for _, ins := range instances {
+		// Efraimidis-Spirakis 加权随机采样，key = u^(1/w)
+		items = append(items, keyed{key: math.Pow(rand.Float64(), 1/float64(ins.Weight())), ins: ins})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].key > items[j].key
+	})
+	ret := make([]*model.Instance, 0, len(items))
+	for _, item := range items {
+		ret = append(ret, item.ins)
+	}
+	return ret
+}
@@ -0,0 +1,261 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dnsserver
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/apiserver"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/service"
+)
+
+const (
+	ServerDNS = "service-dns"
+)
+
+// DNSServer 基于服务发现数据的权威 DNS 服务器，支持 UDP 以及 TCP
+type DNSServer struct {
+	conf       *Config
+	option     map[string]interface{}
+	openAPI    map[string]apiserver.APIConfig
+	resolver   *resolver
+	negatives  *negativeCache
+	serial     uint32
+	statis     plugin.Statis
+	udpConn    net.PacketConn
+	tcpLn      net.Listener
+	exitCh     chan struct{}
+	start      bool
+	restart    bool
+	listenPort uint32
+}
+
+// GetPort 获取端口
+func (h *DNSServer) GetPort() uint32 {
+	return h.listenPort
+}
+
+// GetProtocol 获取协议
+func (h *DNSServer) GetProtocol() string {
+	return ServerDNS
+}
+
+// Initialize 初始化 DNS 服务器
+func (h *DNSServer) Initialize(_ context.Context, option map[string]interface{},
+	api map[string]apiserver.APIConfig) error {
+	conf, err := parseConfig(option)
+	if err != nil {
+		return err
+	}
+	h.conf = conf
+	h.option = option
+	h.openAPI = api
+	h.listenPort = conf.ListenPort
+	h.negatives = newNegativeCache(conf.NegativeCacheSize, time.Duration(conf.NegativeTTL)*time.Second)
+	h.serial = uint32(time.Now().Unix())
+	return nil
+}
+
+// Run 启动 DNS 服务器
+func (h *DNSServer) Run(errCh chan error) {
+	dnslog.Infof("start DNSServer")
+	h.exitCh = make(chan struct{})
+	h.start = true
+	defer func() {
+		close(h.exitCh)
+		h.start = false
+	}()
+
+	namingServer, err := service.GetOriginServer()
+	if err != nil {
+		dnslog.Errorf("%v", err)
+		errCh <- err
+		return
+	}
+	cacheMgr := namingServer.Cache()
+	h.resolver = &resolver{
+		services:  cacheMgr.Service(),
+		instances: cacheMgr.Instance(),
+		routings:  cacheMgr.RoutingConfig(),
+		cmdb:      plugin.GetCMDB(),
+		conf:      h.conf,
+	}
+	h.statis = plugin.GetStatis()
+
+	address := fmt.Sprintf("%v:%v", h.conf.ListenIP, h.conf.ListenPort)
+	if h.udpConn, err = net.ListenPacket("udp", address); err != nil {
+		dnslog.Errorf("dns udp listen(%s) err: %s", address, err.Error())
+		errCh <- err
+		return
+	}
+	if h.tcpLn, err = net.Listen("tcp", address); err != nil {
+		_ = h.udpConn.Close()
+		dnslog.Errorf("dns tcp listen(%s) err: %s", address, err.Error())
+		errCh <- err
+		return
+	}
+
+	// 任意一个监听退出时关闭另一个，两者都退出后 Run 才返回
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	errs := make(chan error, 2)
+	go func() {
+		defer wg.Done()
+		errs <- h.serveUDP(h.udpConn)
+		_ = h.tcpLn.Close()
+	}()
+	go func() {
+		defer wg.Done()
+		errs <- h.serveTCP(h.tcpLn)
+		_ = h.udpConn.Close()
+	}()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil && !errors.Is(err, net.ErrClosed) {
+			dnslog.Errorf("%+v", err)
+			if !h.restart {
+				errCh <- err
+			}
+			return
+		}
+	}
+	dnslog.Infof("DNSServer stop")
+}
+
+// serveUDP 每个 UDP 请求使用独立的协程处理
+func (h *DNSServer) serveUDP(conn net.PacketConn) error {
+	for {
+		buf := make([]byte, udpMaxSize)
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		go func() {
+			rsp := h.handle(buf[:n], hostOf(addr), true)
+			if rsp == nil {
+				return
+			}
+			if _, err := conn.WriteTo(rsp, addr); err != nil {
+				dnslog.Error("[DNS] write udp response", zap.String("client", addr.String()), zap.Error(err))
+			}
+		}()
+	}
+}
+
+// serveTCP TCP 请求使用 2 字节长度前缀，一个连接上可以发送多个请求
+func (h *DNSServer) serveTCP(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go h.serveTCPConn(conn)
+	}
+}
+
+func (h *DNSServer) serveTCPConn(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+	client := hostOf(conn.RemoteAddr())
+	lenBuf := make([]byte, 2)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(tcpIdleTimeout))
+		if _, err := io.ReadFull(conn, lenBuf); err != nil {
+			return
+		}
+		msg := make([]byte, binary.BigEndian.Uint16(lenBuf))
+		if _, err := io.ReadFull(conn, msg); err != nil {
+			return
+		}
+		rsp := h.handle(msg, client, false)
+		if rsp == nil {
+			return
+		}
+		out := make([]byte, 2, len(rsp)+2)
+		binary.BigEndian.PutUint16(out, uint16(len(rsp)))
+		_ = conn.SetWriteDeadline(time.Now().Add(tcpIdleTimeout))
+		if _, err := conn.Write(append(out, rsp...)); err != nil {
+			return
+		}
+	}
+}
+
+func hostOf(addr net.Addr) string {
+	switch v := addr.(type) {
+	case *net.UDPAddr:
+		return v.IP.String()
+	case *net.TCPAddr:
+		return v.IP.String()
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// Stop 结束 DNS 服务器的运行
+func (h *DNSServer) Stop() {
+	if h.udpConn != nil {
+		_ = h.udpConn.Close()
+	}
+	if h.tcpLn != nil {
+		_ = h.tcpLn.Close()
+	}
+}
+
+// Restart 重启 DNS 服务器
+func (h *DNSServer) Restart(
+	option map[string]interface{}, api map[string]apiserver.APIConfig, errCh chan error) error {
+	dnslog.Infof("restart dns server new config: %+v", option)
+	backupOption := h.option
+	backupAPI := h.openAPI
+
+	// 设置restart标记，防止stop的时候把错误抛出
+	h.restart = true
+	h.Stop()
+	if h.start {
+		<-h.exitCh
+	}
+
+	if err := h.Initialize(context.Background(), option, api); err != nil {
+		h.restart = false
+		if initErr := h.Initialize(context.Background(), backupOption, backupAPI); initErr != nil {
+			dnslog.Errorf("start dns server with backup cfg err: %s", initErr.Error())
+			return initErr
+		}
+		go h.Run(errCh)
+		dnslog.Errorf("restart dns server initialize err: %s", err.Error())
+		return err
+	}
+	h.restart = false
+	go h.Run(errCh)
+	return nil
+}
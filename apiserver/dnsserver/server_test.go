@@ -0,0 +1,262 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dnsserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	apitraffic "github.com/polarismesh/specification/source/go/api/v1/traffic_manage"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/dns/dnsmessage"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/polarismesh/polaris/cache/mock"
+	"github.com/polarismesh/polaris/common/model"
+)
+
+func newTestInstance(id, host string, port uint32, healthy bool, metadata map[string]string) *model.Instance {
+	return &model.Instance{
+		Proto: &apiservice.Instance{
+			Id:       wrapperspb.String(id),
+			Host:     wrapperspb.String(host),
+			Port:     wrapperspb.UInt32(port),
+			Weight:   wrapperspb.UInt32(100),
+			Healthy:  wrapperspb.Bool(healthy),
+			Isolate:  wrapperspb.Bool(false),
+			Metadata: metadata,
+		},
+		ServiceID: "svc-id",
+		Valid:     true,
+	}
+}
+
+func newTestServer(t *testing.T, instances []*model.Instance,
+	rules []*model.ExtendRouterConfig) *DNSServer {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	svc := &model.Service{ID: "svc-id", Name: "echo", Namespace: "default"}
+	services := mock.NewMockServiceCache(ctrl)
+	services.EXPECT().GetServiceByName(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(name, namespace string) *model.Service {
+			if name == svc.Name && namespace == svc.Namespace {
+				return svc
+			}
+			return nil
+		}).AnyTimes()
+	instanceCache := mock.NewMockInstanceCache(ctrl)
+	instanceCache.EXPECT().GetInstancesByServiceID(svc.ID).Return(instances).AnyTimes()
+	routings := mock.NewMockRoutingConfigCache(ctrl)
+	routings.EXPECT().ListRouterRule(svc.Name, svc.Namespace).Return(rules).AnyTimes()
+
+	s := &DNSServer{}
+	assert.NoError(t, s.Initialize(context.Background(), map[string]interface{}{
+		"domain":        "Polaris.Local",
+		"enableRouting": true,
+	}, nil))
+	s.resolver = &resolver{
+		services:  services,
+		instances: instanceCache,
+		routings:  routings,
+		conf:      s.conf,
+	}
+	return s
+}
+
+func query(t *testing.T, s *DNSServer, name string, qtype dnsmessage.Type, clientIP string) *dnsmessage.Message {
+	req := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 1234, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName(name),
+			Type:  qtype,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	msg, err := req.Pack()
+	assert.NoError(t, err)
+	out := s.handle(msg, clientIP, true)
+	assert.NotNil(t, out)
+	rsp := &dnsmessage.Message{}
+	assert.NoError(t, rsp.Unpack(out))
+	assert.Equal(t, uint16(1234), rsp.ID)
+	assert.True(t, rsp.Response)
+	return rsp
+}
+
+func TestHandleAddress(t *testing.T) {
+	s := newTestServer(t, []*model.Instance{
+		newTestInstance("1", "10.0.0.1", 8080, true, nil),
+		newTestInstance("2", "10.0.0.2", 8080, false, nil),
+		newTestInstance("3", "fd00::1", 8080, true, nil),
+	}, nil)
+
+	rsp := query(t, s, "echo.default.polaris.local.", dnsmessage.TypeA, "127.0.0.1")
+	assert.Equal(t, dnsmessage.RCodeSuccess, rsp.RCode)
+	assert.True(t, rsp.Authoritative)
+	assert.Len(t, rsp.Answers, 1)
+	assert.Equal(t, [4]byte{10, 0, 0, 1}, rsp.Answers[0].Body.(*dnsmessage.AResource).A)
+	assert.Equal(t, uint32(DefaultTTL), rsp.Answers[0].Header.TTL)
+
+	// 域名不区分大小写，应答中保留请求的大小写
+	rsp = query(t, s, "EcHo.DeFaUlT.PoLaRiS.LoCaL.", dnsmessage.TypeA, "127.0.0.1")
+	assert.Equal(t, dnsmessage.RCodeSuccess, rsp.RCode)
+	assert.Len(t, rsp.Answers, 1)
+	assert.Equal(t, "EcHo.DeFaUlT.PoLaRiS.LoCaL.", rsp.Answers[0].Header.Name.String())
+
+	rsp = query(t, s, "echo.default.polaris.local.", dnsmessage.TypeAAAA, "127.0.0.1")
+	assert.Len(t, rsp.Answers, 1)
+	assert.Equal(t, net.ParseIP("fd00::1").To16(), net.IP(rsp.Answers[0].Body.(*dnsmessage.AAAAResource).AAAA[:]))
+
+	// 没有对应类型的记录返回 NODATA
+	rsp = query(t, s, "echo.default.polaris.local.", dnsmessage.TypeTXT, "127.0.0.1")
+	assert.Equal(t, dnsmessage.RCodeSuccess, rsp.RCode)
+	assert.Len(t, rsp.Answers, 0)
+	assert.Len(t, rsp.Authorities, 1)
+}
+
+func TestHandleSRV(t *testing.T) {
+	s := newTestServer(t, []*model.Instance{
+		newTestInstance("1", "10.0.0.1", 8080, true, nil),
+		newTestInstance("2", "10.0.0.2", 8080, false, nil),
+	}, nil)
+
+	rsp := query(t, s, "_http._tcp.echo.default.polaris.local.", dnsmessage.TypeSRV, "127.0.0.1")
+	assert.Equal(t, dnsmessage.RCodeSuccess, rsp.RCode)
+	assert.Len(t, rsp.Answers, 1)
+	srv := rsp.Answers[0].Body.(*dnsmessage.SRVResource)
+	assert.Equal(t, uint16(8080), srv.Port)
+	assert.Equal(t, uint16(100), srv.Weight)
+	assert.Equal(t, "10-0-0-1.echo.default.polaris.local.", srv.Target.String())
+	assert.Len(t, rsp.Additionals, 1)
+
+	// SRV 的 target 可以再次解析
+	rsp = query(t, s, srv.Target.String(), dnsmessage.TypeA, "127.0.0.1")
+	assert.Len(t, rsp.Answers, 1)
+	assert.Equal(t, [4]byte{10, 0, 0, 1}, rsp.Answers[0].Body.(*dnsmessage.AResource).A)
+
+	// 不健康的实例不会被解析
+	rsp = query(t, s, "10-0-0-2.echo.default.polaris.local.", dnsmessage.TypeA, "127.0.0.1")
+	assert.Equal(t, dnsmessage.RCodeNameError, rsp.RCode)
+	assert.Empty(t, rsp.Answers)
+}
+
+func TestHandleNegative(t *testing.T) {
+	s := newTestServer(t, nil, nil)
+
+	rsp := query(t, s, "missing.default.polaris.local.", dnsmessage.TypeA, "127.0.0.1")
+	assert.Equal(t, dnsmessage.RCodeNameError, rsp.RCode)
+	assert.Len(t, rsp.Authorities, 1)
+	soa := rsp.Authorities[0].Body.(*dnsmessage.SOAResource)
+	assert.Equal(t, uint32(DefaultNegativeTTL), soa.MinTTL)
+	assert.True(t, s.negatives.hit("missing.default.polaris.local."))
+
+	rsp = query(t, s, "echo.default.example.com.", dnsmessage.TypeA, "127.0.0.1")
+	assert.Equal(t, dnsmessage.RCodeRefused, rsp.RCode)
+
+	rsp = query(t, s, "polaris.local.", dnsmessage.TypeSOA, "127.0.0.1")
+	assert.Equal(t, dnsmessage.RCodeSuccess, rsp.RCode)
+	assert.Len(t, rsp.Answers, 1)
+}
+
+func TestHandleTruncate(t *testing.T) {
+	instances := make([]*model.Instance, 0, 64)
+	for i := 0; i < 64; i++ {
+		instances = append(instances, newTestInstance(
+			"id", net.IPv4(10, 0, 1, byte(i)).String(), 8080, true, nil))
+	}
+	s := newTestServer(t, instances, nil)
+	s.conf.MaxAnswers = 64
+
+	rsp := query(t, s, "_http._tcp.echo.default.polaris.local.", dnsmessage.TypeSRV, "127.0.0.1")
+	assert.True(t, rsp.Truncated)
+	assert.Len(t, rsp.Answers, 0)
+}
+
+func TestRoute(t *testing.T) {
+	rules := []*model.ExtendRouterConfig{{
+		RouterConfig: &model.RouterConfig{
+			Policy:   apitraffic.RoutingPolicy_RulePolicy.String(),
+			Priority: 0,
+		},
+		RuleRouting: &apitraffic.RuleRoutingConfig{
+			Rules: []*apitraffic.SubRuleRouting{{
+				Sources: []*apitraffic.SourceService{{
+					Service:   "*",
+					Namespace: "*",
+					Arguments: []*apitraffic.SourceMatch{{
+						Type: apitraffic.SourceMatch_CALLER_IP,
+						Value: &apimodel.MatchString{
+							Type:  apimodel.MatchString_REGEX,
+							Value: wrapperspb.String("^192\\.168\\."),
+						},
+					}},
+				}},
+				Destinations: []*apitraffic.DestinationGroup{{
+					Service:   "*",
+					Namespace: "*",
+					Weight:    100,
+					Labels: map[string]*apimodel.MatchString{
+						"env": {Type: apimodel.MatchString_EXACT, Value: wrapperspb.String("gray")},
+					},
+				}},
+			}},
+		},
+	}}
+	s := newTestServer(t, []*model.Instance{
+		newTestInstance("1", "10.0.0.1", 8080, true, map[string]string{"env": "prod"}),
+		newTestInstance("2", "10.0.0.2", 8080, true, map[string]string{"env": "gray"}),
+	}, rules)
+
+	rsp := query(t, s, "echo.default.polaris.local.", dnsmessage.TypeA, "192.168.1.1")
+	assert.Len(t, rsp.Answers, 1)
+	assert.Equal(t, [4]byte{10, 0, 0, 2}, rsp.Answers[0].Body.(*dnsmessage.AResource).A)
+
+	rsp = query(t, s, "echo.default.polaris.local.", dnsmessage.TypeA, "172.16.0.1")
+	assert.Len(t, rsp.Answers, 2)
+}
+
+func TestDashedIP(t *testing.T) {
+	for _, host := range []string{"10.0.0.1", "fd00::1", "2001:db8::8a2e:370:7334"} {
+		ip := net.ParseIP(host)
+		assert.True(t, ip.Equal(parseDashedIP(dashedIP(ip))), host)
+	}
+	assert.Nil(t, parseDashedIP("echo"))
+	assert.Nil(t, parseDashedIP("a-b-c-d"))
+}
+
+func TestNegativeCache(t *testing.T) {
+	c := newNegativeCache(2, 50*time.Millisecond)
+	c.add("a.")
+	c.add("b.")
+	assert.True(t, c.hit("a."))
+	assert.False(t, c.hit("A."))
+	c.add("c.")
+	assert.True(t, c.hit("c."))
+	time.Sleep(60 * time.Millisecond)
+	assert.False(t, c.hit("c."))
+
+	disabled := newNegativeCache(0, time.Second)
+	disabled.add("a.")
+	assert.False(t, disabled.hit("a."))
+}
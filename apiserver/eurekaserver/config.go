@@ -31,6 +31,7 @@ const (
 	optionPeerNodesToReplicate   = "peersToReplicate"
 	optionCustomValues           = "customValues"
 	optionGenerateUniqueInstId   = "generateUniqueInstId"
+	optionSyncFrom               = "syncFrom"
 )
 
 const (
@@ -47,4 +48,8 @@ const (
 	DefaultSelfPreservationDuration = 15 * time.Minute
 	DefaultListenIP                 = "0.0.0.0"
 	DefaultListenPort               = 8761
+	// DefaultSyncInterval interval in seconds to pull delta from the source eureka cluster
+	DefaultSyncInterval = 30
+	// DefaultSyncSourceName name of the source eureka cluster, saved into synced instance metadata
+	DefaultSyncSourceName = "eureka"
 )
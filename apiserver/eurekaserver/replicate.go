@@ -50,6 +50,9 @@ const (
 	valueIdentityName     = "PolarisServer"
 )
 
+// eurekaReplicateSubscriber durable subscriber name of the instance event replication
+const eurekaReplicateSubscriber = "eureka_replicate"
+
 // BatchReplication do the server request replication
 func (h *EurekaServer) BatchReplication(req *restful.Request, rsp *restful.Response) {
 	eurekalog.Infof("[EUREKA-SERVER] received replicate request %+v", req)
@@ -206,6 +209,10 @@ func (h *EurekaServer) shouldReplicate(e model.InstanceEvent) bool {
 		eurekalog.Warnf("[EUREKA]fail to replicate, service name is empty for event %s", e)
 		return false
 	}
+	// instances pulled from the source eureka cluster should not be replicated back
+	if _, ok := e.Instance.GetMetadata()[MetadataSyncSource]; ok {
+		return false
+	}
 	metadata := e.MetaData
 	if len(metadata) > 0 {
 		if value, ok := metadata[MetadataReplicate]; ok {
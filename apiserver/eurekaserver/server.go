@@ -65,6 +65,7 @@ const (
 	MetadataSecurePortEnabled   = "internal-eureka-secure-port-enabled"
 	MetadataReplicate           = "internal-eureka-replicate"
 	MetadataInstanceId          = "internal-eureka-instance-id"
+	MetadataSyncSource          = "internal-eureka-sync-source"
 
 	InternalMetadataStatus           = "internal-eureka-status"
 	InternalMetadataOverriddenStatus = "internal-eureka-overriddenStatus"
@@ -154,6 +155,9 @@ type EurekaServer struct {
 	subCtxs              []*eventhub.SubscribtionContext
 
 	allowAsyncRegis bool
+
+	syncConfig *SyncConfig
+	syncCancel context.CancelFunc
 }
 
 // GetPort 获取端口
@@ -253,6 +257,14 @@ func (h *EurekaServer) Initialize(ctx context.Context, option map[string]interfa
 		h.generateUniqueInstId = false
 	}
 
+	if raw, _ := option[optionSyncFrom].(map[interface{}]interface{}); raw != nil {
+		syncConfig, err := parseSyncConfig(h.namespace, raw)
+		if err != nil {
+			return err
+		}
+		h.syncConfig = syncConfig
+	}
+
 	if raw, _ := option[optionCustomValues].(map[interface{}]interface{}); raw != nil {
 		for k, v := range raw {
 			CustomEurekaParameters[k.(string)] = fmt.Sprintf("%v", v)
@@ -345,7 +357,9 @@ func (h *EurekaServer) Run(errCh chan error) {
 	if len(h.replicatePeers) > 0 {
 		h.eventHandlerHandler = &EurekaInstanceEventHandler{
 			BaseInstanceEventHandler: service.NewBaseInstanceEventHandler(h.namingServer), svr: h}
-		subCtx, err := eventhub.Subscribe(eventhub.InstanceEventTopic, h.eventHandlerHandler)
+		// 实例事件 topic 开启持久化时，重启后从上次复制的位置继续同步
+		subCtx, err := eventhub.Subscribe(eventhub.InstanceEventTopic, h.eventHandlerHandler,
+			eventhub.WithDurable(eurekaReplicateSubscriber))
 		if err != nil {
 			errCh <- err
 			return
@@ -355,6 +369,15 @@ func (h *EurekaServer) Run(errCh chan error) {
 	h.registerInstanceChain()
 	h.workers = NewApplicationsWorkers(h.refreshInterval, h.deltaExpireInterval, h.enableSelfPreservation,
 		h.namingServer, h.healthCheckServer, h.namespace)
+	if h.syncConfig != nil {
+		var syncCtx context.Context
+		syncCtx, h.syncCancel = context.WithCancel(context.Background())
+		if err := newSyncWorker(h, h.syncConfig).start(syncCtx); err != nil {
+			eurekalog.Errorf("[EUREKA-SYNC] fail to start leader election of syncing: %v", err)
+			errCh <- err
+			return
+		}
+	}
 	h.statis = plugin.GetStatis()
 	// 初始化http server
 	address := fmt.Sprintf("%v:%v", h.listenIP, h.listenPort)
@@ -603,6 +626,9 @@ func (h *EurekaServer) Stop() {
 	// 释放connLimit的数据，如果没有开启，也需要执行一下
 	// 目的：防止restart的时候，connLimit冲突
 	connlimit.RemoveLimitListener(h.GetProtocol())
+	if h.syncCancel != nil {
+		h.syncCancel()
+	}
 	if h.server != nil {
 		// 延迟三秒，等待http server关闭，做到流量无损。
 		// 在此之前已经建立的链接，会正常执行业务，若执行时长超过3秒，则会抛出异常。
@@ -0,0 +1,439 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package eurekaserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+const (
+	syncOptionName      = "name"
+	syncOptionAddresses = "addresses"
+	syncOptionNamespace = "namespace"
+	syncOptionInterval  = "interval"
+	syncOptionToken     = "token"
+
+	syncRequestTimeout = 30 * time.Second
+)
+
+// SyncConfig config of pulling applications from an existing eureka cluster, used to migrate
+// the eureka clients to polaris without downtime
+type SyncConfig struct {
+	// Name name of the source cluster, saved into the metadata of the synced instances
+	Name string
+	// Addresses addresses of the source cluster, e.g. http://127.0.0.1:8761/eureka
+	Addresses []string
+	// Namespace polaris namespace to store the synced instances
+	Namespace string
+	// Interval interval to pull the delta of the source cluster
+	Interval time.Duration
+	// Token polaris token used to write the synced instances when auth is enabled
+	Token string
+}
+
+func parseSyncConfig(defaultNamespace string, raw map[interface{}]interface{}) (*SyncConfig, error) {
+	cfg := &SyncConfig{
+		Name:      DefaultSyncSourceName,
+		Namespace: defaultNamespace,
+		Interval:  DefaultSyncInterval * time.Second,
+	}
+	if value, ok := raw[syncOptionName].(string); ok && len(value) > 0 {
+		cfg.Name = value
+	}
+	if value, ok := raw[syncOptionNamespace].(string); ok && len(value) > 0 {
+		cfg.Namespace = value
+	}
+	if value, ok := raw[syncOptionToken].(string); ok {
+		cfg.Token = value
+	}
+	if value, ok := raw[syncOptionInterval].(int); ok && value > 0 {
+		cfg.Interval = time.Duration(value) * time.Second
+	}
+	addresses, _ := raw[syncOptionAddresses].([]interface{})
+	for _, address := range addresses {
+		addressStr, ok := address.(string)
+		if !ok || len(addressStr) == 0 {
+			continue
+		}
+		cfg.Addresses = append(cfg.Addresses, strings.TrimSuffix(addressStr, "/"))
+	}
+	if len(cfg.Addresses) == 0 {
+		return nil, errors.New("eureka syncFrom.addresses can not be empty")
+	}
+	return cfg, nil
+}
+
+// SyncWorker pulls /apps and /apps/delta from the source eureka cluster, and mirrors
+// the applications and instances into polaris
+type SyncWorker struct {
+	svr     *EurekaServer
+	cfg     *SyncConfig
+	client  *http.Client
+	storage store.Store
+	// apps synced instances, appName -> eureka instanceId -> instance
+	apps map[string]map[string]*InstanceInfo
+	// invalid instances which can not be synced, appName -> eureka instanceId -> instance,
+	// they are still counted in the hashcode to keep consistent with the source
+	invalid map[string]map[string]*InstanceInfo
+	// unnamed status counts of the invalid instances without instanceId and hostName
+	unnamed    map[string]int
+	fullSynced bool
+}
+
+func newSyncWorker(svr *EurekaServer, cfg *SyncConfig) *SyncWorker {
+	w := &SyncWorker{
+		svr:     svr,
+		cfg:     cfg,
+		client:  &http.Client{Timeout: syncRequestTimeout},
+		storage: svr.namingServer.Cache().GetStore(),
+	}
+	w.reset()
+	return w
+}
+
+// electionKey only the leader pulls from the source cluster, so that the nodes behind the same
+// load balancer do not write the same instances concurrently
+func (w *SyncWorker) electionKey() string {
+	return store.ElectionKeyEurekaSyncPrefix + w.cfg.Name
+}
+
+// reset clears the local mirror, the next sync will be a full sync
+func (w *SyncWorker) reset() {
+	w.apps = map[string]map[string]*InstanceInfo{}
+	w.invalid = map[string]map[string]*InstanceInfo{}
+	w.unnamed = map[string]int{}
+	w.fullSynced = false
+}
+
+func (w *SyncWorker) start(ctx context.Context) error {
+	if err := w.storage.StartLeaderElection(w.electionKey()); err != nil {
+		return err
+	}
+	go w.run(ctx)
+	return nil
+}
+
+func (w *SyncWorker) run(ctx context.Context) {
+	eurekalog.Infof("[EUREKA-SYNC] start to sync from %s %v into namespace %s",
+		w.cfg.Name, w.cfg.Addresses, w.cfg.Namespace)
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+	w.syncIfLeader()
+	for {
+		select {
+		case <-ctx.Done():
+			eurekalog.Infof("[EUREKA-SYNC] sync worker of %s done", w.cfg.Name)
+			return
+		case <-ticker.C:
+			w.syncIfLeader()
+		}
+	}
+}
+
+func (w *SyncWorker) syncIfLeader() {
+	if !w.storage.IsLeader(w.electionKey()) {
+		// the mirror may be out of date when becoming the leader again
+		if w.fullSynced {
+			eurekalog.Infof("[EUREKA-SYNC] not the leader of syncing from %s any more", w.cfg.Name)
+			w.reset()
+		}
+		return
+	}
+	w.syncOnce()
+}
+
+func (w *SyncWorker) syncOnce() {
+	if w.fullSynced {
+		err := w.deltaSync()
+		if err == nil {
+			return
+		}
+		eurekalog.Warnf("[EUREKA-SYNC] fail to sync delta from %s, fallback to full sync: %v", w.cfg.Name, err)
+	}
+	if err := w.fullSync(); err != nil {
+		eurekalog.Errorf("[EUREKA-SYNC] fail to sync applications from %s: %v", w.cfg.Name, err)
+	}
+}
+
+// fullSync pulls all the applications, and removes the synced instances which not exist in the source
+func (w *SyncWorker) fullSync() error {
+	apps, err := w.fetch("/apps")
+	if err != nil {
+		return err
+	}
+	synced := map[string]map[string]*InstanceInfo{}
+	invalid := map[string]map[string]*InstanceInfo{}
+	unnamed := map[string]int{}
+	expectIds := map[string]struct{}{}
+	for _, app := range apps.Application {
+		for _, instance := range app.Instance {
+			instId := syncInstanceId(instance)
+			if err := convertInstancePorts(instance); err != nil || len(instId) == 0 {
+				eurekalog.Warnf("[EUREKA-SYNC] ignore invalid instance %s of app %s, err: %v", instId, app.Name, err)
+				if len(instId) == 0 {
+					unnamed[instance.Status]++
+					continue
+				}
+				if _, ok := invalid[app.Name]; !ok {
+					invalid[app.Name] = map[string]*InstanceInfo{}
+				}
+				invalid[app.Name][instId] = instance
+				continue
+			}
+			if _, ok := synced[app.Name]; !ok {
+				synced[app.Name] = map[string]*InstanceInfo{}
+			}
+			synced[app.Name][instId] = instance
+			expectIds[w.polarisInstanceId(app.Name, instId)] = struct{}{}
+			if old, ok := w.apps[app.Name][instId]; ok && sameSyncInstance(old, instance) {
+				continue
+			}
+			w.upsertInstance(app.Name, instance)
+		}
+	}
+
+	staleIds := make([]string, 0, 4)
+	_ = w.svr.originDiscoverSvr.Cache().Instance().IteratorInstances(
+		func(key string, value *model.Instance) (bool, error) {
+			if value.Namespace() != w.cfg.Namespace || value.Metadata()[MetadataSyncSource] != w.cfg.Name {
+				return true, nil
+			}
+			if _, ok := expectIds[key]; !ok {
+				staleIds = append(staleIds, key)
+			}
+			return true, nil
+		})
+	for _, instId := range staleIds {
+		w.deregisterInstance(instId)
+	}
+	w.apps = synced
+	w.invalid = invalid
+	w.unnamed = unnamed
+	w.fullSynced = true
+	eurekalog.Debugf("[EUREKA-SYNC] full sync from %s success, hashcode %s", w.cfg.Name, apps.AppsHashCode)
+	return nil
+}
+
+// deltaSync pulls the recently changed instances, and checks the hashcode to make sure the local
+// mirror is consistent with the source
+func (w *SyncWorker) deltaSync() error {
+	delta, err := w.fetch("/apps/delta")
+	if err != nil {
+		return err
+	}
+	for _, app := range delta.Application {
+		for _, instance := range app.Instance {
+			instId := syncInstanceId(instance)
+			if len(instId) == 0 {
+				// can not be tracked by delta, the hashcode mismatch triggers a full sync
+				continue
+			}
+			valid := convertInstancePorts(instance) == nil
+			switch instance.ActionType {
+			case ActionAdded, ActionModified:
+				if !valid {
+					putSyncInstance(w.invalid, app.Name, instId, instance)
+					if removeSyncInstance(w.apps, app.Name, instId) {
+						w.deregisterInstance(w.polarisInstanceId(app.Name, instId))
+					}
+					continue
+				}
+				removeSyncInstance(w.invalid, app.Name, instId)
+				putSyncInstance(w.apps, app.Name, instId, instance)
+				w.upsertInstance(app.Name, instance)
+			case ActionDeleted:
+				removeSyncInstance(w.invalid, app.Name, instId)
+				if removeSyncInstance(w.apps, app.Name, instId) {
+					w.deregisterInstance(w.polarisInstanceId(app.Name, instId))
+				}
+			}
+		}
+	}
+	if hashCode := w.hashCode(); hashCode != delta.AppsHashCode {
+		return fmt.Errorf("apps hashcode mismatch, local %s, remote %s", hashCode, delta.AppsHashCode)
+	}
+	eurekalog.Debugf("[EUREKA-SYNC] delta sync from %s success, hashcode %s", w.cfg.Name, delta.AppsHashCode)
+	return nil
+}
+
+// hashCode calculates the hashcode of the local mirror in the same way as eureka,
+// the invalid instances are counted as well since they are counted by the source
+func (w *SyncWorker) hashCode() string {
+	counts := map[string]int{}
+	for status, count := range w.unnamed {
+		counts[status] += count
+	}
+	for _, apps := range []map[string]map[string]*InstanceInfo{w.apps, w.invalid} {
+		for _, instances := range apps {
+			for _, instance := range instances {
+				counts[instance.Status]++
+			}
+		}
+	}
+	return buildHashStr(counts)
+}
+
+func putSyncInstance(apps map[string]map[string]*InstanceInfo, appName, instId string, instance *InstanceInfo) {
+	if _, ok := apps[appName]; !ok {
+		apps[appName] = map[string]*InstanceInfo{}
+	}
+	apps[appName][instId] = instance
+}
+
+// removeSyncInstance removes the instance, returns whether the instance exists
+func removeSyncInstance(apps map[string]map[string]*InstanceInfo, appName, instId string) bool {
+	if _, ok := apps[appName][instId]; !ok {
+		return false
+	}
+	delete(apps[appName], instId)
+	if len(apps[appName]) == 0 {
+		delete(apps, appName)
+	}
+	return true
+}
+
+func (w *SyncWorker) fetch(path string) (*Applications, error) {
+	var lastErr error
+	for _, address := range w.cfg.Addresses {
+		apps, err := w.fetchFrom(address + path)
+		if err == nil {
+			return apps, nil
+		}
+		eurekalog.Warnf("[EUREKA-SYNC] fail to fetch %s from %s: %v", path, address, err)
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func (w *SyncWorker) fetchFrom(url string) (*Applications, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(restful.HEADER_Accept, restful.MIME_JSON)
+	rsp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+	data, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d, body %s", rsp.StatusCode, string(data))
+	}
+	appsRsp := &ApplicationsResponse{}
+	if err := json.Unmarshal(data, appsRsp); err != nil {
+		return nil, err
+	}
+	if appsRsp.Applications == nil {
+		return nil, errors.New("applications is empty")
+	}
+	return appsRsp.Applications, nil
+}
+
+// syncContext the instance events are marked as replicated to prevent replicating back to eureka peers
+func (w *SyncWorker) syncContext() context.Context {
+	ctx := context.WithValue(context.Background(), utils.ContextAuthTokenKey, w.cfg.Token)
+	ctx = context.WithValue(ctx, model.CtxEventKeyMetadata,
+		map[string]string{MetadataReplicate: strconv.FormatBool(true)})
+	return context.WithValue(ctx, utils.ContextOpenAsyncRegis, w.svr.allowAsyncRegis)
+}
+
+func (w *SyncWorker) polarisInstanceId(appName string, instId string) string {
+	return checkOrBuildNewInstanceIdByNamespace(w.cfg.Namespace, w.svr.namespace,
+		formatWriteName(appName), instId, w.svr.generateUniqueInstId)
+}
+
+// upsertInstance registers or updates the instance, the health status of the synced instance
+// is maintained by the source cluster, so the heartbeat check is disabled
+func (w *SyncWorker) upsertInstance(appName string, instance *InstanceInfo) {
+	appId := formatWriteName(appName)
+	targetInstance := convertEurekaInstance(instance, w.cfg.Namespace, w.svr.namespace, appId,
+		w.svr.generateUniqueInstId)
+	targetInstance.EnableHealthCheck = &wrappers.BoolValue{Value: false}
+	targetInstance.HealthCheck = nil
+	targetInstance.Metadata[MetadataSyncSource] = w.cfg.Name
+
+	instId := targetInstance.GetId().GetValue()
+	saveIns := w.svr.originDiscoverSvr.Cache().Instance().GetInstance(instId)
+	if saveIns != nil && saveIns.Metadata()[MetadataSyncSource] != w.cfg.Name {
+		// the instance is registered to polaris directly, or replicated from polaris to the source
+		eurekalog.Infof("[EUREKA-SYNC] instance %s of app %s is not synced from %s, skip it",
+			instId, appId, w.cfg.Name)
+		return
+	}
+	resp := w.svr.namingServer.RegisterInstance(w.syncContext(), targetInstance)
+	code := resp.GetCode().GetValue()
+	if code == api.NotFoundResource {
+		svcResp := w.svr.namingServer.CreateServices(w.syncContext(), []*apiservice.Service{{
+			Namespace: &wrappers.StringValue{Value: w.cfg.Namespace},
+			Name:      &wrappers.StringValue{Value: appId},
+		}})
+		if svcCode := svcResp.GetCode().GetValue(); svcCode == api.ExecuteSuccess || svcCode == api.ExistedResource {
+			code = w.svr.namingServer.RegisterInstance(w.syncContext(), targetInstance).GetCode().GetValue()
+		}
+	}
+	if code != api.ExecuteSuccess && code != api.ExistedResource && code != api.SameInstanceRequest {
+		eurekalog.Errorf("[EUREKA-SYNC] fail to sync instance %s of app %s, code is %d", instId, appId, code)
+	}
+}
+
+func (w *SyncWorker) deregisterInstance(instId string) {
+	saveIns := w.svr.originDiscoverSvr.Cache().Instance().GetInstance(instId)
+	if saveIns != nil && saveIns.Metadata()[MetadataSyncSource] != w.cfg.Name {
+		return
+	}
+	resp := w.svr.namingServer.DeregisterInstance(w.syncContext(),
+		&apiservice.Instance{Id: &wrappers.StringValue{Value: instId}})
+	if code := resp.GetCode().GetValue(); code != api.ExecuteSuccess && code != api.NotFoundResource {
+		eurekalog.Errorf("[EUREKA-SYNC] fail to remove synced instance %s, code is %d", instId, code)
+	}
+}
+
+func syncInstanceId(instance *InstanceInfo) string {
+	if len(instance.InstanceId) > 0 {
+		return instance.InstanceId
+	}
+	return instance.HostName
+}
+
+// sameSyncInstance whether the instance pulled from the source is not changed
+func sameSyncInstance(old, cur *InstanceInfo) bool {
+	return old.Status == cur.Status && ObjectToString(old.LastDirtyTimestamp) == ObjectToString(cur.LastDirtyTimestamp)
+}
@@ -0,0 +1,221 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package eurekaserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	testsuit "github.com/polarismesh/polaris/test/suit"
+)
+
+type fakeEurekaSource struct {
+	lock  sync.Mutex
+	apps  string
+	delta string
+}
+
+func (f *fakeEurekaSource) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/eureka/apps":
+		_, _ = w.Write([]byte(f.apps))
+	case "/eureka/apps/delta":
+		_, _ = w.Write([]byte(f.delta))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeEurekaSource) set(apps, delta string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.apps = apps
+	f.delta = delta
+}
+
+func fakeEurekaApps(hashCode string, instances ...string) string {
+	instanceList := ""
+	for i, instance := range instances {
+		if i > 0 {
+			instanceList += ","
+		}
+		instanceList += instance
+	}
+	return fmt.Sprintf(`{"applications":{"versions__delta":"1","apps__hashcode":"%s",`+
+		`"application":[{"name":"SYNC-APP","instance":[%s]}]}}`, hashCode, instanceList)
+}
+
+func fakeEurekaInstance(instId string, port int, status string, action string) string {
+	return fmt.Sprintf(`{"instanceId":"%s","app":"SYNC-APP","ipAddr":"127.0.0.1","hostName":"127.0.0.1",`+
+		`"status":"%s","port":{"$":%d,"@enabled":"true"},"securePort":{"$":443,"@enabled":"false"},`+
+		`"countryId":1,"dataCenterInfo":{"@class":"%s","name":"MyOwn"},`+
+		`"metadata":{"version":"v1"},"lastDirtyTimestamp":"%d","actionType":"%s"}`,
+		instId, status, port, DefaultDciClazz, time.Now().UnixNano(), action)
+}
+
+func TestParseSyncConfig(t *testing.T) {
+	_, err := parseSyncConfig(DefaultNamespace, map[interface{}]interface{}{})
+	assert.Error(t, err)
+
+	cfg, err := parseSyncConfig(DefaultNamespace, map[interface{}]interface{}{
+		"addresses": []interface{}{"http://127.0.0.1:8761/eureka/"},
+		"interval":  5,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultSyncSourceName, cfg.Name)
+	assert.Equal(t, DefaultNamespace, cfg.Namespace)
+	assert.Equal(t, 5*time.Second, cfg.Interval)
+	assert.Equal(t, []string{"http://127.0.0.1:8761/eureka"}, cfg.Addresses)
+}
+
+func TestSyncFromEureka(t *testing.T) {
+	discoverSuit := &testsuit.DiscoverTestSuit{}
+	if err := discoverSuit.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	defer discoverSuit.Destroy()
+	defer func() {
+		discoverSuit.CleanService("SYNC-APP", DefaultNamespace)
+	}()
+
+	source := &fakeEurekaSource{}
+	sourceSvr := httptest.NewServer(source)
+	defer sourceSvr.Close()
+
+	eurekaSrv, err := createEurekaServerForTest(discoverSuit, map[string]interface{}{
+		optionSyncFrom: map[interface{}]interface{}{
+			"name":      "legacy",
+			"token":     utils.ParseAuthToken(discoverSuit.DefaultCtx),
+			"addresses": []interface{}{"http://127.0.0.1:1/eureka", sourceSvr.URL + "/eureka/"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, eurekaSrv.syncConfig)
+	worker := newSyncWorker(eurekaSrv, eurekaSrv.syncConfig)
+	instanceCache := discoverSuit.OriginDiscoverServer().Cache().Instance()
+
+	t.Run("full-sync", func(t *testing.T) {
+		source.set(fakeEurekaApps("UP_2_",
+			fakeEurekaInstance("sync-1", 8001, StatusUp, ActionAdded),
+			fakeEurekaInstance("sync-2", 8002, StatusUp, ActionAdded)), "")
+		worker.syncOnce()
+		assert.True(t, worker.fullSynced)
+		time.Sleep(discoverSuit.UpdateCacheInterval())
+
+		for _, instId := range []string{"sync-1", "sync-2"} {
+			ins := instanceCache.GetInstance(instId)
+			if !assert.NotNil(t, ins, instId) {
+				return
+			}
+			assert.Equal(t, "legacy", ins.Metadata()[MetadataSyncSource])
+			assert.Equal(t, "v1", ins.Metadata()["version"])
+			assert.False(t, ins.EnableHealthCheck())
+			assert.False(t, ins.Isolate())
+		}
+	})
+
+	t.Run("delta-sync", func(t *testing.T) {
+		source.set(fakeEurekaApps("DOWN_1_", fakeEurekaInstance("sync-1", 8001, StatusDown, ActionAdded)),
+			fakeEurekaApps("DOWN_1_",
+				fakeEurekaInstance("sync-1", 8001, StatusDown, ActionModified),
+				fakeEurekaInstance("sync-2", 8002, StatusUp, ActionDeleted)))
+		worker.syncOnce()
+		time.Sleep(discoverSuit.UpdateCacheInterval())
+
+		ins := instanceCache.GetInstance("sync-1")
+		if assert.NotNil(t, ins) {
+			assert.True(t, ins.Isolate())
+			assert.Equal(t, StatusDown, ins.Metadata()[InternalMetadataStatus])
+		}
+		assert.Nil(t, instanceCache.GetInstance("sync-2"))
+	})
+
+	t.Run("hashcode-mismatch-fallback-full-sync", func(t *testing.T) {
+		source.set(fakeEurekaApps("UP_1_", fakeEurekaInstance("sync-3", 8003, StatusUp, ActionAdded)),
+			fakeEurekaApps("UP_5_"))
+		worker.syncOnce()
+		time.Sleep(discoverSuit.UpdateCacheInterval())
+
+		assert.Nil(t, instanceCache.GetInstance("sync-1"))
+		assert.NotNil(t, instanceCache.GetInstance("sync-3"))
+		assert.Equal(t, "UP_1_", worker.hashCode())
+	})
+
+	t.Run("invalid-instance-counted-in-hashcode", func(t *testing.T) {
+		invalid := `{"instanceId":"sync-bad","app":"SYNC-APP","ipAddr":"127.0.0.1","status":"UP",` +
+			`"port":{"$":"bad","@enabled":"true"}}`
+		source.set(fakeEurekaApps("UP_2_", fakeEurekaInstance("sync-3", 8003, StatusUp, ActionAdded), invalid),
+			fakeEurekaApps("UP_2_"))
+		worker.syncOnce()
+		assert.Equal(t, "UP_2_", worker.hashCode())
+		// 无效的实例不会导致 hashcode 不一致而每次都全量同步
+		assert.NoError(t, worker.deltaSync())
+		assert.Nil(t, instanceCache.GetInstance("sync-bad"))
+	})
+
+	t.Run("follower-skip-sync", func(t *testing.T) {
+		assert.False(t, worker.storage.IsLeader(worker.electionKey()))
+		worker.syncIfLeader()
+		assert.False(t, worker.fullSynced)
+		assert.Empty(t, worker.apps)
+	})
+
+	t.Run("skip-not-synced-instance", func(t *testing.T) {
+		instances := batchBuildInstances("SYNC-APP", "127.0.0.2", 8004, nil, 1)
+		batchCreateInstance(t, eurekaSrv, DefaultNamespace, instances)
+		time.Sleep(discoverSuit.UpdateCacheInterval())
+
+		instId := instances[0].InstanceId
+		worker.upsertInstance("SYNC-APP", instances[0])
+		worker.deregisterInstance(instId)
+		time.Sleep(discoverSuit.UpdateCacheInterval())
+		ins := instanceCache.GetInstance(instId)
+		if assert.NotNil(t, ins) {
+			assert.Empty(t, ins.Metadata()[MetadataSyncSource])
+			assert.True(t, ins.EnableHealthCheck())
+		}
+		_ = eurekaSrv.deregisterInstance(context.Background(), DefaultNamespace, "SYNC-APP", instId, false)
+	})
+}
+
+func TestShouldReplicateSyncedInstance(t *testing.T) {
+	eurekaSrv := &EurekaServer{
+		replicateWorkers: &ReplicateWorkers{works: map[string]*ReplicateWorker{DefaultNamespace: {}}},
+	}
+	event := model.InstanceEvent{
+		Namespace: DefaultNamespace,
+		Service:   "SYNC-APP",
+		Instance:  &apiservice.Instance{Metadata: map[string]string{}},
+	}
+	assert.True(t, eurekaSrv.shouldReplicate(event))
+	event.Instance.Metadata[MetadataSyncSource] = "legacy"
+	assert.False(t, eurekaSrv.shouldReplicate(event))
+}
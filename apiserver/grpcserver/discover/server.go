@@ -89,6 +89,10 @@ func (g *GRPCServer) Initialize(ctx context.Context, option map[string]interface
 		return err
 	}
 
+	if g.v1server != nil {
+		// restart 时释放旧的 server 持有的资源
+		g.v1server.Destroy()
+	}
 	g.v1server = v1.NewDiscoverServer(
 		v1.WithAllowAccess(g.allowAccess),
 		v1.WithEnterRateLimit(g.enterRateLimit),
@@ -127,6 +131,9 @@ func (g *GRPCServer) Run(errCh chan error) {
 // Stop 关闭GRPC
 func (g *GRPCServer) Stop() {
 	g.BaseGrpcServer.Stop(g.GetProtocol())
+	if g.v1server != nil {
+		g.v1server.Destroy()
+	}
 }
 
 // Restart 重启Server
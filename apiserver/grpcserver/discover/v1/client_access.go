@@ -93,6 +93,18 @@ func (g *DiscoverServer) Discover(server apiservice.PolarisGRPC_DiscoverServer)
 	userAgent, _ := ctx.Value(utils.StringContext("user-agent")).(string)
 	method, _ := grpc.MethodFromServerStream(server)
 
+	send := server.Send
+	// 订阅模式下，客户端只需要发送一次请求，后续数据变化由服务端主动推送
+	var stream *discoverStream
+	if isSubscribeMode(ctx) && g.subscribeHub != nil && g.subscribeHub.enabled() {
+		streamCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stream = g.subscribeHub.newStream(streamCtx, server)
+		defer stream.close()
+		go stream.run(g.handleDiscoverRequest)
+		send = stream.send
+	}
+
 	for {
 		in, err := server.Recv()
 		if err != nil {
@@ -107,13 +119,14 @@ func (g *DiscoverServer) Discover(server apiservice.PolarisGRPC_DiscoverServer)
 			zap.String("type", apiservice.DiscoverRequest_DiscoverRequestType_name[int32(in.Type)]),
 			zap.String("client-address", clientAddress),
 			zap.String("user-agent", userAgent),
+			zap.Bool("subscribe", stream != nil),
 			utils.ZapRequestID(requestID),
 		)
 
 		// 是否允许访问
 		if ok := g.allowAccess(method); !ok {
 			resp := api.NewDiscoverResponse(apimodel.Code_ClientAPINotOpen)
-			if sendErr := server.Send(resp); sendErr != nil {
+			if sendErr := send(resp); sendErr != nil {
 				return sendErr
 			}
 			continue
@@ -122,59 +135,71 @@ func (g *DiscoverServer) Discover(server apiservice.PolarisGRPC_DiscoverServer)
 		// stream模式，需要对每个包进行检测
 		if code := g.enterRateLimit(clientIP, method); code != uint32(apimodel.Code_ExecuteSuccess) {
 			resp := api.NewDiscoverResponse(apimodel.Code(code))
-			if err = server.Send(resp); err != nil {
+			if err = send(resp); err != nil {
 				return err
 			}
 			continue
 		}
 
-		var out *apiservice.DiscoverResponse
-		var action string
-		startTime := commontime.CurrentMillisecond()
-		defer func() {
-			plugin.GetStatis().ReportDiscoverCall(metrics.ClientDiscoverMetric{
-				Action:    action,
-				ClientIP:  utils.ParseClientAddress(ctx),
-				Namespace: in.GetService().GetNamespace().GetValue(),
-				Resource:  in.GetType().String() + ":" + in.GetService().GetName().GetValue(),
-				Timestamp: startTime,
-				CostTime:  commontime.CurrentMillisecond() - startTime,
-				Revision:  out.GetService().GetRevision().GetValue(),
-				Success:   out.GetCode().GetValue() > uint32(apimodel.Code_DataNoChange),
-			})
-		}()
-
-		switch in.Type {
-		case apiservice.DiscoverRequest_INSTANCE:
-			action = metrics.ActionDiscoverInstance
-			out = g.namingServer.ServiceInstancesCache(ctx, &apiservice.DiscoverFilter{}, in.Service)
-		case apiservice.DiscoverRequest_ROUTING:
-			action = metrics.ActionDiscoverRouterRule
-			out = g.namingServer.GetRoutingConfigWithCache(ctx, in.Service)
-		case apiservice.DiscoverRequest_RATE_LIMIT:
-			action = metrics.ActionDiscoverRateLimit
-			out = g.namingServer.GetRateLimitWithCache(ctx, in.Service)
-		case apiservice.DiscoverRequest_CIRCUIT_BREAKER:
-			action = metrics.ActionDiscoverCircuitBreaker
-			out = g.namingServer.GetCircuitBreakerWithCache(ctx, in.Service)
-		case apiservice.DiscoverRequest_SERVICES:
-			action = metrics.ActionDiscoverServices
-			out = g.namingServer.GetServiceWithCache(ctx, in.Service)
-		case apiservice.DiscoverRequest_FAULT_DETECTOR:
-			action = metrics.ActionDiscoverFaultDetect
-			out = g.namingServer.GetFaultDetectWithCache(ctx, in.Service)
-		case apiservice.DiscoverRequest_SERVICE_CONTRACT:
-			action = metrics.ActionDiscoverServiceContract
-			out = g.namingServer.GetServiceContractWithCache(ctx, in.ServiceContract)
-		default:
-			out = api.NewDiscoverRoutingResponse(apimodel.Code_InvalidDiscoverResource, in.Service)
-		}
-
-		err = server.Send(out)
-		if err != nil {
+		out := g.handleDiscoverRequest(ctx, in)
+		if err = send(out); err != nil {
 			return err
 		}
+		if stream != nil && out.GetCode().GetValue() <= uint32(apimodel.Code_DataNoChange) {
+			revision := requestRevision(in)
+			if out.GetCode().GetValue() == uint32(apimodel.Code_ExecuteSuccess) {
+				revision = responseRevision(out)
+			}
+			stream.subscribe(in, revision)
+		}
+	}
+}
+
+// handleDiscoverRequest 根据请求类型从缓存中获取数据，并上报客户端拉取的监控数据
+func (g *DiscoverServer) handleDiscoverRequest(ctx context.Context,
+	in *apiservice.DiscoverRequest) *apiservice.DiscoverResponse {
+	var out *apiservice.DiscoverResponse
+	var action string
+	startTime := commontime.CurrentMillisecond()
+	defer func() {
+		plugin.GetStatis().ReportDiscoverCall(metrics.ClientDiscoverMetric{
+			Action:    action,
+			ClientIP:  utils.ParseClientAddress(ctx),
+			Namespace: in.GetService().GetNamespace().GetValue(),
+			Resource:  in.GetType().String() + ":" + in.GetService().GetName().GetValue(),
+			Timestamp: startTime,
+			CostTime:  commontime.CurrentMillisecond() - startTime,
+			Revision:  out.GetService().GetRevision().GetValue(),
+			Success:   out.GetCode().GetValue() > uint32(apimodel.Code_DataNoChange),
+		})
+	}()
+
+	switch in.Type {
+	case apiservice.DiscoverRequest_INSTANCE:
+		action = metrics.ActionDiscoverInstance
+		out = g.namingServer.ServiceInstancesCache(ctx, &apiservice.DiscoverFilter{}, in.Service)
+	case apiservice.DiscoverRequest_ROUTING:
+		action = metrics.ActionDiscoverRouterRule
+		out = g.namingServer.GetRoutingConfigWithCache(ctx, in.Service)
+	case apiservice.DiscoverRequest_RATE_LIMIT:
+		action = metrics.ActionDiscoverRateLimit
+		out = g.namingServer.GetRateLimitWithCache(ctx, in.Service)
+	case apiservice.DiscoverRequest_CIRCUIT_BREAKER:
+		action = metrics.ActionDiscoverCircuitBreaker
+		out = g.namingServer.GetCircuitBreakerWithCache(ctx, in.Service)
+	case apiservice.DiscoverRequest_SERVICES:
+		action = metrics.ActionDiscoverServices
+		out = g.namingServer.GetServiceWithCache(ctx, in.Service)
+	case apiservice.DiscoverRequest_FAULT_DETECTOR:
+		action = metrics.ActionDiscoverFaultDetect
+		out = g.namingServer.GetFaultDetectWithCache(ctx, in.Service)
+	case apiservice.DiscoverRequest_SERVICE_CONTRACT:
+		action = metrics.ActionDiscoverServiceContract
+		out = g.namingServer.GetServiceContractWithCache(ctx, in.ServiceContract)
+	default:
+		out = api.NewDiscoverRoutingResponse(apimodel.Code_InvalidDiscoverResource, in.Service)
 	}
+	return out
 }
 
 func (g *DiscoverServer) ReportServiceContract(ctx context.Context, in *apiservice.ServiceContract) (*apiservice.Response, error) {
@@ -27,6 +27,7 @@ type DiscoverServer struct {
 	healthCheckServer *healthcheck.Server
 	enterRateLimit    func(ip string, method string) uint32
 	allowAccess       func(method string) bool
+	subscribeHub      *subscribeHub
 }
 
 func NewDiscoverServer(options ...Option) *DiscoverServer {
@@ -36,9 +37,20 @@ func NewDiscoverServer(options ...Option) *DiscoverServer {
 		options[i](s)
 	}
 
+	s.subscribeHub = newSubscribeHub(s)
+	if err := s.subscribeHub.start(); err != nil {
+		accesslog.Warnf("[Grpc][Discover] subscribe mode is unavailable, err: %s", err.Error())
+	}
 	return s
 }
 
+// Destroy release the resources of the discover server
+func (s *DiscoverServer) Destroy() {
+	if s.subscribeHub != nil {
+		s.subscribeHub.stop()
+	}
+}
+
 type Option func(s *DiscoverServer)
 
 func WithNamingServer(svr service.DiscoverServer) Option {
@@ -0,0 +1,445 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package v1
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	types "github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/common/eventhub"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+const (
+	// HeaderDiscoverMode grpc header of the discover stream, set to DiscoverModeSubscribe to enable server push
+	HeaderDiscoverMode = "discover-mode"
+	// DiscoverModeSubscribe client registers interest once, server pushes the changes
+	DiscoverModeSubscribe = "subscribe"
+
+	// pushMergeDelay merge the changes in a short time to one push
+	pushMergeDelay = 50 * time.Millisecond
+)
+
+// cacheDiscoverTypes the discover types may be affected when the cache updated
+var cacheDiscoverTypes = map[string][]apiservice.DiscoverRequest_DiscoverRequestType{
+	types.ServiceName:         {apiservice.DiscoverRequest_SERVICES, apiservice.DiscoverRequest_INSTANCE},
+	types.RoutingConfigName:   {apiservice.DiscoverRequest_ROUTING},
+	types.RateLimitConfigName: {apiservice.DiscoverRequest_RATE_LIMIT},
+	types.CircuitBreakerName:  {apiservice.DiscoverRequest_CIRCUIT_BREAKER},
+	types.FaultDetectRuleName: {apiservice.DiscoverRequest_FAULT_DETECTOR},
+	types.ServiceContractName: {apiservice.DiscoverRequest_SERVICE_CONTRACT},
+}
+
+// isSubscribeMode 判断 discover stream 是否开启了服务端推送模式
+func isSubscribeMode(ctx context.Context) bool {
+	header, ok := ctx.Value(utils.ContextGrpcHeader).(metadata.MD)
+	if !ok {
+		return false
+	}
+	values := header.Get(HeaderDiscoverMode)
+	return len(values) > 0 && values[0] == DiscoverModeSubscribe
+}
+
+// watchKey the resource key which the subscription cares about
+type watchKey struct {
+	Type      apiservice.DiscoverRequest_DiscoverRequestType
+	Namespace string
+	Name      string
+}
+
+// subscribeKey the unique key of one subscription in the stream
+type subscribeKey struct {
+	watchKey
+	// Contract service contract name/protocol/version
+	Contract string
+}
+
+type subscribeItem struct {
+	key      subscribeKey
+	watch    watchKey
+	request  *apiservice.DiscoverRequest
+	revision string
+	dirty    bool
+}
+
+// discoverStream the discover stream in subscribe mode
+type discoverStream struct {
+	id     string
+	ctx    context.Context
+	hub    *subscribeHub
+	server apiservice.PolarisGRPC_DiscoverServer
+
+	sendLock *sync.Mutex
+	lock     *sync.Mutex
+	items    map[subscribeKey]*subscribeItem
+	notifyCh chan struct{}
+}
+
+// send the stream.Send is not concurrency safe, push and reply must be serialized
+func (s *discoverStream) send(resp *apiservice.DiscoverResponse) error {
+	s.sendLock.Lock()
+	defer s.sendLock.Unlock()
+	return s.server.Send(resp)
+}
+
+// subscribe register the interest of the request, revision is the last revision client owned
+func (s *discoverStream) subscribe(req *apiservice.DiscoverRequest, revision string) {
+	key := buildSubscribeKey(req)
+	watch := s.hub.resolveWatchKey(key.watchKey)
+
+	s.lock.Lock()
+	item, ok := s.items[key]
+	if !ok {
+		item = &subscribeItem{key: key}
+		s.items[key] = item
+	}
+	oldWatch := item.watch
+	item.watch = watch
+	item.request = req
+	item.revision = revision
+	item.dirty = false
+	s.lock.Unlock()
+
+	if ok && oldWatch != watch {
+		s.hub.unwatch(oldWatch, s)
+	}
+	s.hub.watch(watch, s)
+}
+
+// onWatchChange mark the subscriptions of the watch key need to recheck
+func (s *discoverStream) onWatchChange(watch watchKey) {
+	s.markDirty(func(item *subscribeItem) bool {
+		return item.watch == watch
+	})
+}
+
+// onTypeChange mark all the subscriptions of the discover type need to recheck
+func (s *discoverStream) onTypeChange(discoverType apiservice.DiscoverRequest_DiscoverRequestType) {
+	s.markDirty(func(item *subscribeItem) bool {
+		return item.key.Type == discoverType
+	})
+}
+
+func (s *discoverStream) markDirty(match func(item *subscribeItem) bool) {
+	s.lock.Lock()
+	var changed bool
+	for _, item := range s.items {
+		if match(item) {
+			item.dirty = true
+			changed = true
+		}
+	}
+	s.lock.Unlock()
+	if !changed {
+		return
+	}
+	select {
+	case s.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *discoverStream) run(handle func(ctx context.Context,
+	req *apiservice.DiscoverRequest) *apiservice.DiscoverResponse) {
+	for {
+		select {
+		case <-s.notifyCh:
+			// wait a moment to merge the continuous changes
+			time.Sleep(pushMergeDelay)
+			if err := s.push(handle); err != nil {
+				accesslog.Error("[Grpc][Discover] push discover response", zap.String("stream", s.id),
+					zap.Error(err))
+				return
+			}
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *discoverStream) push(handle func(ctx context.Context,
+	req *apiservice.DiscoverRequest) *apiservice.DiscoverResponse) error {
+	s.lock.Lock()
+	items := make([]subscribeItem, 0, len(s.items))
+	for _, item := range s.items {
+		if item.dirty {
+			item.dirty = false
+			items = append(items, *item)
+		}
+	}
+	s.lock.Unlock()
+
+	for i := range items {
+		item := items[i]
+		out := handle(s.ctx, withRevision(item.request, item.revision))
+		if out.GetCode().GetValue() == uint32(apimodel.Code_DataNoChange) {
+			continue
+		}
+		if err := s.send(out); err != nil {
+			return err
+		}
+		revision := responseRevision(out)
+		s.lock.Lock()
+		if cur, ok := s.items[item.key]; ok && cur.revision == item.revision {
+			cur.revision = revision
+		}
+		s.lock.Unlock()
+	}
+	return nil
+}
+
+func (s *discoverStream) close() {
+	s.lock.Lock()
+	watches := make([]watchKey, 0, len(s.items))
+	for _, item := range s.items {
+		watches = append(watches, item.watch)
+	}
+	s.items = map[subscribeKey]*subscribeItem{}
+	s.lock.Unlock()
+	for i := range watches {
+		s.hub.unwatch(watches[i], s)
+	}
+	s.hub.removeStream(s)
+}
+
+// subscribeHub dispatch the cache changes to the subscribed discover streams
+type subscribeHub struct {
+	svr     *DiscoverServer
+	lock    *sync.RWMutex
+	streams map[string]*discoverStream
+	watches map[watchKey]map[string]*discoverStream
+	subCtxs []*eventhub.SubscribtionContext
+}
+
+func newSubscribeHub(svr *DiscoverServer) *subscribeHub {
+	return &subscribeHub{
+		svr:     svr,
+		lock:    &sync.RWMutex{},
+		streams: map[string]*discoverStream{},
+		watches: map[watchKey]map[string]*discoverStream{},
+	}
+}
+
+// start subscribe the cache events, subscribe mode is unavailable when eventhub not initialized
+func (h *subscribeHub) start() error {
+	subCtx, err := eventhub.SubscribeWithFunc(eventhub.CacheInstanceEventTopic, h.onInstanceEvent)
+	if err != nil {
+		return err
+	}
+	h.subCtxs = append(h.subCtxs, subCtx)
+	subCtx, err = eventhub.SubscribeWithFunc(eventhub.CacheResourceUpdateTopic, h.onResourceUpdate)
+	if err != nil {
+		h.stop()
+		return err
+	}
+	h.subCtxs = append(h.subCtxs, subCtx)
+	return nil
+}
+
+func (h *subscribeHub) stop() {
+	for i := range h.subCtxs {
+		h.subCtxs[i].Cancel()
+	}
+	h.subCtxs = nil
+}
+
+func (h *subscribeHub) enabled() bool {
+	return len(h.subCtxs) > 0
+}
+
+func (h *subscribeHub) newStream(ctx context.Context, server apiservice.PolarisGRPC_DiscoverServer) *discoverStream {
+	stream := &discoverStream{
+		id:       uuid.NewString(),
+		ctx:      ctx,
+		hub:      h,
+		server:   server,
+		sendLock: &sync.Mutex{},
+		lock:     &sync.Mutex{},
+		items:    map[subscribeKey]*subscribeItem{},
+		notifyCh: make(chan struct{}, 1),
+	}
+	h.lock.Lock()
+	h.streams[stream.id] = stream
+	h.lock.Unlock()
+	return stream
+}
+
+func (h *subscribeHub) removeStream(stream *discoverStream) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	delete(h.streams, stream.id)
+}
+
+func (h *subscribeHub) watch(key watchKey, stream *discoverStream) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	streams, ok := h.watches[key]
+	if !ok {
+		streams = map[string]*discoverStream{}
+		h.watches[key] = streams
+	}
+	streams[stream.id] = stream
+}
+
+func (h *subscribeHub) unwatch(key watchKey, stream *discoverStream) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	streams, ok := h.watches[key]
+	if !ok {
+		return
+	}
+	stream.lock.Lock()
+	for _, item := range stream.items {
+		if item.watch == key {
+			// other subscription of the stream still watch the key
+			stream.lock.Unlock()
+			return
+		}
+	}
+	stream.lock.Unlock()
+	delete(streams, stream.id)
+	if len(streams) == 0 {
+		delete(h.watches, key)
+	}
+}
+
+// resolveWatchKey the instances of the alias service belong to the source service
+func (h *subscribeHub) resolveWatchKey(key watchKey) watchKey {
+	if key.Type != apiservice.DiscoverRequest_INSTANCE || h.svr.namingServer == nil {
+		return key
+	}
+	caches := h.svr.namingServer.Cache()
+	if caches == nil {
+		return key
+	}
+	svc := caches.Service().GetServiceByName(key.Name, key.Namespace)
+	if svc == nil || !svc.IsAlias() {
+		return key
+	}
+	source := caches.Service().GetServiceByID(svc.Reference)
+	if source == nil {
+		return key
+	}
+	return watchKey{Type: key.Type, Namespace: source.Namespace, Name: source.Name}
+}
+
+func (h *subscribeHub) onInstanceEvent(ctx context.Context, args any) error {
+	event, ok := args.(*eventhub.CacheInstanceEvent)
+	if !ok || event.Instance == nil {
+		return nil
+	}
+	key := watchKey{
+		Type:      apiservice.DiscoverRequest_INSTANCE,
+		Namespace: event.Instance.Namespace(),
+		Name:      event.Instance.Service(),
+	}
+	h.lock.RLock()
+	streams := make([]*discoverStream, 0, len(h.watches[key]))
+	for _, stream := range h.watches[key] {
+		streams = append(streams, stream)
+	}
+	h.lock.RUnlock()
+	for i := range streams {
+		streams[i].onWatchChange(key)
+	}
+	return nil
+}
+
+func (h *subscribeHub) onResourceUpdate(ctx context.Context, args any) error {
+	event, ok := args.(*eventhub.CacheResourceUpdateEvent)
+	if !ok {
+		return nil
+	}
+	discoverTypes, ok := cacheDiscoverTypes[event.Name]
+	if !ok {
+		return nil
+	}
+	h.lock.RLock()
+	streams := make([]*discoverStream, 0, len(h.streams))
+	for _, stream := range h.streams {
+		streams = append(streams, stream)
+	}
+	h.lock.RUnlock()
+	for i := range streams {
+		for _, discoverType := range discoverTypes {
+			streams[i].onTypeChange(discoverType)
+		}
+	}
+	return nil
+}
+
+func buildSubscribeKey(req *apiservice.DiscoverRequest) subscribeKey {
+	key := subscribeKey{
+		watchKey: watchKey{
+			Type:      req.GetType(),
+			Namespace: req.GetService().GetNamespace().GetValue(),
+			Name:      req.GetService().GetName().GetValue(),
+		},
+	}
+	if req.GetType() == apiservice.DiscoverRequest_SERVICE_CONTRACT {
+		contract := req.GetServiceContract()
+		key.Namespace = contract.GetNamespace()
+		key.Name = contract.GetService()
+		key.Contract = contract.GetName() + "/" + contract.GetProtocol() + "/" + contract.GetVersion()
+	}
+	return key
+}
+
+// requestRevision the revision client owned in the discover request
+func requestRevision(req *apiservice.DiscoverRequest) string {
+	if req.GetType() == apiservice.DiscoverRequest_SERVICE_CONTRACT {
+		return req.GetServiceContract().GetRevision()
+	}
+	return req.GetService().GetRevision().GetValue()
+}
+
+// responseRevision the revision of the data pushed in the discover response
+func responseRevision(out *apiservice.DiscoverResponse) string {
+	if out.GetType() == apiservice.DiscoverResponse_SERVICE_CONTRACT {
+		return out.GetServiceContract().GetRevision()
+	}
+	return out.GetService().GetRevision().GetValue()
+}
+
+// withRevision copy the discover request with the revision
+func withRevision(req *apiservice.DiscoverRequest, revision string) *apiservice.DiscoverRequest {
+	ret := &apiservice.DiscoverRequest{
+		Type:   req.GetType(),
+		Filter: req.GetFilter(),
+	}
+	if req.GetService() != nil {
+		svc := *req.GetService()
+		svc.Revision = wrapperspb.String(revision)
+		ret.Service = &svc
+	}
+	if req.GetServiceContract() != nil {
+		contract := *req.GetServiceContract()
+		contract.Revision = revision
+		ret.ServiceContract = &contract
+	}
+	return ret
+}
@@ -0,0 +1,273 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package v1
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/polarismesh/polaris/cache"
+	types "github.com/polarismesh/polaris/cache/api"
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/eventhub"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/service"
+)
+
+// fakeNamingServer returns the discover response with the current revision
+type fakeNamingServer struct {
+	service.DiscoverServer
+	lock      sync.Mutex
+	revisions map[apiservice.DiscoverResponse_DiscoverResponseType]string
+}
+
+func (f *fakeNamingServer) setRevision(t apiservice.DiscoverResponse_DiscoverResponseType, revision string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.revisions[t] = revision
+}
+
+func (f *fakeNamingServer) response(t apiservice.DiscoverResponse_DiscoverResponseType,
+	req *apiservice.Service) *apiservice.DiscoverResponse {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	revision := f.revisions[t]
+	if req.GetRevision().GetValue() == revision {
+		resp := api.NewDiscoverResponse(apimodel.Code_DataNoChange)
+		resp.Type = t
+		return resp
+	}
+	resp := api.NewDiscoverResponse(apimodel.Code_ExecuteSuccess)
+	resp.Type = t
+	resp.Service = &apiservice.Service{
+		Namespace: req.GetNamespace(),
+		Name:      req.GetName(),
+		Revision:  wrapperspb.String(revision),
+	}
+	return resp
+}
+
+// Cache the fake naming server has no cache, subscription keys will not be resolved
+func (f *fakeNamingServer) Cache() *cache.CacheManager {
+	return nil
+}
+
+func (f *fakeNamingServer) ServiceInstancesCache(ctx context.Context, filter *apiservice.DiscoverFilter,
+	req *apiservice.Service) *apiservice.DiscoverResponse {
+	return f.response(apiservice.DiscoverResponse_INSTANCE, req)
+}
+
+func (f *fakeNamingServer) GetRateLimitWithCache(ctx context.Context,
+	req *apiservice.Service) *apiservice.DiscoverResponse {
+	return f.response(apiservice.DiscoverResponse_RATE_LIMIT, req)
+}
+
+// fakeDiscoverStream in-memory discover stream
+type fakeDiscoverStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	reqs chan *apiservice.DiscoverRequest
+	resp chan *apiservice.DiscoverResponse
+}
+
+func (f *fakeDiscoverStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeDiscoverStream) Send(resp *apiservice.DiscoverResponse) error {
+	f.resp <- resp
+	return nil
+}
+
+func (f *fakeDiscoverStream) Recv() (*apiservice.DiscoverRequest, error) {
+	select {
+	case req, ok := <-f.reqs:
+		if !ok {
+			return nil, io.EOF
+		}
+		return req, nil
+	case <-f.ctx.Done():
+		return nil, io.EOF
+	}
+}
+
+func (f *fakeDiscoverStream) expect(t *testing.T) *apiservice.DiscoverResponse {
+	select {
+	case resp := <-f.resp:
+		return resp
+	case <-time.After(3 * time.Second):
+		t.Fatal("wait discover response timeout")
+	}
+	return nil
+}
+
+func (f *fakeDiscoverStream) expectNothing(t *testing.T) {
+	select {
+	case resp := <-f.resp:
+		t.Fatalf("unexpected discover response %+v", resp)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func newTestDiscoverServer(t *testing.T, naming *fakeNamingServer) *DiscoverServer {
+	eventhub.InitEventHub()
+	svr := NewDiscoverServer(
+		WithAllowAccess(func(method string) bool { return true }),
+		WithEnterRateLimit(func(ip string, method string) uint32 { return uint32(apimodel.Code_ExecuteSuccess) }),
+		WithNamingServer(naming),
+	)
+	assert.True(t, svr.subscribeHub.enabled())
+	t.Cleanup(svr.Destroy)
+	return svr
+}
+
+func newFakeStream(ctx context.Context, subscribe bool) *fakeDiscoverStream {
+	md := metadata.MD{}
+	if subscribe {
+		md.Set(HeaderDiscoverMode, DiscoverModeSubscribe)
+	}
+	return &fakeDiscoverStream{
+		ctx:  metadata.NewIncomingContext(ctx, md),
+		reqs: make(chan *apiservice.DiscoverRequest, 8),
+		resp: make(chan *apiservice.DiscoverResponse, 8),
+	}
+}
+
+func discoverRequest(t apiservice.DiscoverRequest_DiscoverRequestType, revision string) *apiservice.DiscoverRequest {
+	return &apiservice.DiscoverRequest{
+		Type: t,
+		Service: &apiservice.Service{
+			Namespace: wrapperspb.String("default"),
+			Name:      wrapperspb.String("svc"),
+			Revision:  wrapperspb.String(revision),
+		},
+	}
+}
+
+func TestDiscover_Subscribe(t *testing.T) {
+	naming := &fakeNamingServer{revisions: map[apiservice.DiscoverResponse_DiscoverResponseType]string{
+		apiservice.DiscoverResponse_INSTANCE:   "ins-1",
+		apiservice.DiscoverResponse_RATE_LIMIT: "rl-1",
+	}}
+	svr := newTestDiscoverServer(t, naming)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := newFakeStream(ctx, true)
+	go func() {
+		_ = svr.Discover(stream)
+	}()
+
+	// register interest once, reply the current data immediately
+	stream.reqs <- discoverRequest(apiservice.DiscoverRequest_INSTANCE, "")
+	resp := stream.expect(t)
+	assert.Equal(t, "ins-1", resp.GetService().GetRevision().GetValue())
+	stream.reqs <- discoverRequest(apiservice.DiscoverRequest_RATE_LIMIT, "rl-1")
+	resp = stream.expect(t)
+	assert.Equal(t, uint32(apimodel.Code_DataNoChange), resp.GetCode().GetValue())
+
+	// instance of the subscribed service changed
+	naming.setRevision(apiservice.DiscoverResponse_INSTANCE, "ins-2")
+	_ = eventhub.Publish(eventhub.CacheInstanceEventTopic, &eventhub.CacheInstanceEvent{
+		Instance: &model.Instance{Proto: &apiservice.Instance{
+			Namespace: wrapperspb.String("default"),
+			Service:   wrapperspb.String("svc"),
+		}},
+		EventType: eventhub.EventUpdated,
+	})
+	resp = stream.expect(t)
+	assert.Equal(t, apiservice.DiscoverResponse_INSTANCE, resp.GetType())
+	assert.Equal(t, "ins-2", resp.GetService().GetRevision().GetValue())
+
+	// instance of other service changed, nothing to push
+	_ = eventhub.Publish(eventhub.CacheInstanceEventTopic, &eventhub.CacheInstanceEvent{
+		Instance: &model.Instance{Proto: &apiservice.Instance{
+			Namespace: wrapperspb.String("default"),
+			Service:   wrapperspb.String("other"),
+		}},
+		EventType: eventhub.EventUpdated,
+	})
+	stream.expectNothing(t)
+
+	// rule cache updated, but the revision not changed
+	_ = eventhub.Publish(eventhub.CacheResourceUpdateTopic, &eventhub.CacheResourceUpdateEvent{
+		Name:  types.RateLimitConfigName,
+		Total: 1,
+	})
+	stream.expectNothing(t)
+
+	naming.setRevision(apiservice.DiscoverResponse_RATE_LIMIT, "rl-2")
+	_ = eventhub.Publish(eventhub.CacheResourceUpdateTopic, &eventhub.CacheResourceUpdateEvent{
+		Name:  types.RateLimitConfigName,
+		Total: 1,
+	})
+	resp = stream.expect(t)
+	assert.Equal(t, apiservice.DiscoverResponse_RATE_LIMIT, resp.GetType())
+	assert.Equal(t, "rl-2", resp.GetService().GetRevision().GetValue())
+}
+
+func TestDiscover_Polling(t *testing.T) {
+	naming := &fakeNamingServer{revisions: map[apiservice.DiscoverResponse_DiscoverResponseType]string{
+		apiservice.DiscoverResponse_INSTANCE: "ins-1",
+	}}
+	svr := newTestDiscoverServer(t, naming)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := newFakeStream(ctx, false)
+	go func() {
+		_ = svr.Discover(stream)
+	}()
+
+	stream.reqs <- discoverRequest(apiservice.DiscoverRequest_INSTANCE, "")
+	resp := stream.expect(t)
+	assert.Equal(t, "ins-1", resp.GetService().GetRevision().GetValue())
+
+	// stream without subscribe header will not receive push
+	naming.setRevision(apiservice.DiscoverResponse_INSTANCE, "ins-2")
+	_ = eventhub.Publish(eventhub.CacheInstanceEventTopic, &eventhub.CacheInstanceEvent{
+		Instance: &model.Instance{Proto: &apiservice.Instance{
+			Namespace: wrapperspb.String("default"),
+			Service:   wrapperspb.String("svc"),
+		}},
+		EventType: eventhub.EventUpdated,
+	})
+	stream.expectNothing(t)
+}
+
+func Test_responseRevision(t *testing.T) {
+	assert.Equal(t, "svc-revision", responseRevision(&apiservice.DiscoverResponse{
+		Type:    apiservice.DiscoverResponse_INSTANCE,
+		Service: &apiservice.Service{Revision: wrapperspb.String("svc-revision")},
+	}))
+	// the service contract response does not carry the service
+	assert.Equal(t, "contract-revision", responseRevision(&apiservice.DiscoverResponse{
+		Type:            apiservice.DiscoverResponse_SERVICE_CONTRACT,
+		ServiceContract: &apiservice.ServiceContract{Revision: "contract-revision"},
+	}))
+}
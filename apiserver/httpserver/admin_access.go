@@ -20,12 +20,16 @@ package httpserver
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"strconv"
 
 	"github.com/emicklei/go-restful/v3"
 	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
 	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"go.uber.org/zap"
 
 	"github.com/polarismesh/polaris/admin"
 	"github.com/polarismesh/polaris/apiserver/httpserver/docs"
@@ -66,7 +70,14 @@ func (h *HTTPServer) GetAdminAccessServer() *restful.WebService {
 	ws.Route(docs.EnrichListLeaderElectionsApiDocs(ws.GET("/leaders").To(h.ListLeaderElections)))
 	ws.Route(docs.EnrichReleaseLeaderElectionApiDocs(ws.POST("/leaders/release").To(h.ReleaseLeaderElection)))
 	ws.Route(docs.EnrichGetCMDBInfoApiDocs(ws.GET("/cmdb/info").To(h.GetCMDBInfo)))
+	ws.Route(docs.EnrichGetOperationRecordsApiDocs(ws.GET("/history/records").To(h.GetOperationRecords)))
+	ws.Route(docs.EnrichGetCryptoKeyVersionsApiDocs(ws.GET("/crypto/keyversions").To(h.GetCryptoKeyVersions)))
 	ws.Route(docs.EnrichGetReportClientsApiDocs(ws.GET("/report/clients").To(h.GetReportClients)))
+	ws.Route(docs.EnrichBackupApiDocs(ws.GET("/backup").Produces(mimeGzip).To(h.Backup)))
+	ws.Route(docs.EnrichRestoreApiDocs(ws.POST("/restore").Consumes(mimeGzip, restful.MIME_OCTET).
+		To(h.Restore)))
+	ws.Route(docs.EnrichReconcileManifestsApiDocs(ws.POST("/gitops/reconcile").
+		Consumes(mimeZip, mimeGzip, restful.MIME_OCTET).To(h.ReconcileManifests)))
 	return ws
 }
 
@@ -294,6 +305,115 @@ func (h *HTTPServer) GetCMDBInfo(req *restful.Request, rsp *restful.Response) {
 	_ = rsp.WriteAsJson(ret)
 }
 
+// GetOperationRecords 查询操作记录
+func (h *HTTPServer) GetOperationRecords(req *restful.Request, rsp *restful.Response) {
+	ctx := initContext(req)
+	params := httpcommon.ParseQueryParams(req)
+
+	ret, err := h.maintainServer.GetOperationRecords(ctx, params)
+	if err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	_ = rsp.WriteAsJson(ret)
+}
+
+// GetCryptoKeyVersions 查询加密配置使用的主密钥版本
+func (h *HTTPServer) GetCryptoKeyVersions(req *restful.Request, rsp *restful.Response) {
+	ctx := initContext(req)
+
+	ret, err := h.maintainServer.GetCryptoKeyVersions(ctx)
+	if err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	_ = rsp.WriteAsJson(ret)
+}
+
+const (
+	// mimeGzip 备份归档以及 tar.gz 资源清单归档的数据类型
+	mimeGzip = "application/gzip"
+	// mimeZip zip 资源清单归档的数据类型
+	mimeZip = "application/zip"
+	// maxManifestArchiveSize 资源清单归档的最大长度
+	maxManifestArchiveSize = 64 * 1024 * 1024
+)
+
+// Backup 导出全部治理数据的备份归档
+// query参数：instances，可选，是否包含服务实例，默认不包含
+func (h *HTTPServer) Backup(req *restful.Request, rsp *restful.Response) {
+	ctx := initContext(req)
+	params := httpcommon.ParseQueryParams(req)
+	withInstances, _ := strconv.ParseBool(params["instances"])
+
+	// 先写入临时文件，备份失败时可以正常返回错误信息
+	f, err := os.CreateTemp("", "polaris-backup-*")
+	if err != nil {
+		_ = rsp.WriteErrorString(http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	manifest, err := h.maintainServer.Backup(ctx, withInstances, f)
+	if err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		_ = rsp.WriteErrorString(http.StatusInternalServerError, err.Error())
+		return
+	}
+	rsp.AddHeader("Content-Type", mimeGzip)
+	rsp.AddHeader("Content-Disposition", fmt.Sprintf("attachment; filename=polaris-backup-%s.jsonl.gz",
+		manifest.CreateTime.Format("20060102150405")))
+	rsp.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(rsp, f); err != nil {
+		log.Error("[MAINTAIN] write backup archive", utils.RequestID(ctx), zap.Error(err))
+	}
+}
+
+// Restore 从备份归档中恢复治理数据
+// query参数：policy，可选，数据冲突时的处理策略，skip（默认）、overwrite、fail
+func (h *HTTPServer) Restore(req *restful.Request, rsp *restful.Response) {
+	ctx := initContext(req)
+	params := httpcommon.ParseQueryParams(req)
+
+	ret, err := h.maintainServer.Restore(ctx, params["policy"], req.Request.Body)
+	if err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	_ = rsp.WriteAsJson(ret)
+}
+
+// ReconcileManifests 使用资源清单归档同步治理规则以及配置文件
+// query参数：dry_run，可选，为 true 时只返回执行计划
+// query参数：adopt，可选，为 true 时接管已经存在的同名资源，否则作为冲突返回
+func (h *HTTPServer) ReconcileManifests(req *restful.Request, rsp *restful.Response) {
+	ctx := initContext(req)
+	params := httpcommon.ParseQueryParams(req)
+	dryRun, _ := strconv.ParseBool(params["dry_run"])
+	adopt, _ := strconv.ParseBool(params["adopt"])
+
+	archive, err := io.ReadAll(io.LimitReader(req.Request.Body, maxManifestArchiveSize+1))
+	if err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(archive) > maxManifestArchiveSize {
+		_ = rsp.WriteErrorString(http.StatusRequestEntityTooLarge, "manifests archive is too large")
+		return
+	}
+	plan, err := h.maintainServer.ReconcileManifests(ctx, archive, dryRun, adopt)
+	if err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	_ = rsp.WriteAsJson(plan)
+}
+
 func initContext(req *restful.Request) context.Context {
 	ctx := context.Background()
 
@@ -18,7 +18,11 @@
 package httpserver
 
 import (
+	"encoding/json"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/emicklei/go-restful/v3"
 	"github.com/golang/protobuf/proto"
@@ -28,15 +32,21 @@ import (
 
 	"github.com/polarismesh/polaris/apiserver/httpserver/docs"
 	httpcommon "github.com/polarismesh/polaris/apiserver/httpserver/utils"
+	"github.com/polarismesh/polaris/auth"
 	api "github.com/polarismesh/polaris/common/api/v1"
 	"github.com/polarismesh/polaris/common/utils"
 )
 
+// oidcNonceCookie 绑定发起 OIDC 登录的浏览器的 cookie
+const oidcNonceCookie = "polaris_oidc_nonce"
+
 // GetAuthServer 运维接口
 func (h *HTTPServer) GetAuthServer(ws *restful.WebService) error {
 	ws.Route(docs.EnrichAuthStatusApiDocs(ws.GET("/auth/status").To(h.AuthStatus)))
 	//
 	ws.Route(docs.EnrichLoginApiDocs(ws.POST("/user/login").To(h.Login)))
+	ws.Route(docs.EnrichOIDCAuthorizeApiDocs(ws.GET("/user/login/oidc").To(h.OIDCAuthorize)))
+	ws.Route(docs.EnrichOIDCLoginApiDocs(ws.GET("/user/login/oidc/callback").To(h.OIDCLogin)))
 	ws.Route(docs.EnrichGetUsersApiDocs(ws.GET("/users").To(h.GetUsers)))
 	ws.Route(docs.EnrichCreateUsersApiDocs(ws.POST("/users").To(h.CreateUsers)))
 	ws.Route(docs.EnrichDeleteUsersApiDocs(ws.POST("/users/delete").To(h.DeleteUsers)))
@@ -45,6 +55,9 @@ func (h *HTTPServer) GetAuthServer(ws *restful.WebService) error {
 	ws.Route(docs.EnrichGetUserTokenApiDocs(ws.GET("/user/token").To(h.GetUserToken)))
 	ws.Route(docs.EnrichUpdateUserTokenApiDocs(ws.PUT("/user/token/status").To(h.UpdateUserToken)))
 	ws.Route(docs.EnrichResetUserTokenApiDocs(ws.PUT("/user/token/refresh").To(h.ResetUserToken)))
+	ws.Route(docs.EnrichCreateAccessTokenApiDocs(ws.POST("/user/token/access").To(h.CreateAccessToken)))
+	ws.Route(docs.EnrichGetAccessTokensApiDocs(ws.GET("/user/token/access").To(h.GetAccessTokens)))
+	ws.Route(docs.EnrichRevokeAccessTokenApiDocs(ws.POST("/user/token/access/revoke").To(h.RevokeAccessToken)))
 	//
 	ws.Route(docs.EnrichCreateGroupApiDocs(ws.POST("/usergroup").To(h.CreateGroup)))
 	ws.Route(docs.EnrichUpdateGroupsApiDocs(ws.PUT("/usergroups").To(h.UpdateGroups)))
@@ -58,6 +71,7 @@ func (h *HTTPServer) GetAuthServer(ws *restful.WebService) error {
 	ws.Route(docs.EnrichCreateStrategyApiDocs(ws.POST("/auth/strategy").To(h.CreateStrategy)))
 	ws.Route(docs.EnrichGetStrategyApiDocs(ws.GET("/auth/strategy/detail").To(h.GetStrategy)))
 	ws.Route(docs.EnrichUpdateStrategiesApiDocs(ws.PUT("/auth/strategies").To(h.UpdateStrategies)))
+	ws.Route(docs.EnrichUpdateStrategyActionApiDocs(ws.PUT("/auth/strategy/action").To(h.UpdateStrategyAction)))
 	ws.Route(docs.EnrichDeleteStrategiesApiDocs(ws.POST("/auth/strategies/delete").To(h.DeleteStrategies)))
 	ws.Route(docs.EnrichGetStrategiesApiDocs(ws.GET("/auth/strategies").To(h.GetStrategies)))
 	ws.Route(docs.EnrichGetPrincipalResourcesApiDocs(ws.GET("/auth/principal/resources").To(h.GetPrincipalResources)))
@@ -105,6 +119,60 @@ func (h *HTTPServer) Login(req *restful.Request, rsp *restful.Response) {
 	handler.WriteHeaderAndProto(h.userMgn.Login(loginReq))
 }
 
+// OIDCAuthorize 跳转至 OIDC 身份提供方进行登录
+func (h *HTTPServer) OIDCAuthorize(req *restful.Request, rsp *restful.Response) {
+	handler := &httpcommon.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	authorization, errResp := h.userMgn.OIDCAuthorize(handler.ParseHeaderContext())
+	if errResp != nil {
+		handler.WriteHeaderAndProto(errResp)
+		return
+	}
+	// 回调地址位于授权地址之下，cookie 只在授权回调时携带
+	http.SetCookie(rsp.ResponseWriter, &http.Cookie{
+		Name:     oidcNonceCookie,
+		Value:    authorization.Nonce,
+		Path:     req.Request.URL.Path,
+		MaxAge:   int(time.Until(authorization.ExpireAt).Seconds()),
+		Secure:   req.Request.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(rsp.ResponseWriter, req.Request, authorization.URL, http.StatusFound)
+}
+
+// OIDCLogin OIDC 身份提供方的授权回调，使用授权码完成登录
+func (h *HTTPServer) OIDCLogin(req *restful.Request, rsp *restful.Response) {
+	handler := &httpcommon.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	if oauthErr := req.QueryParameter("error"); oauthErr != "" {
+		handler.WriteHeaderAndProto(api.NewAuthResponseWithMsg(apimodel.Code_NotAllowedAccess,
+			oauthErr+": "+req.QueryParameter("error_description")))
+		return
+	}
+	var binding string
+	if cookie, err := req.Request.Cookie(oidcNonceCookie); err == nil {
+		binding = cookie.Value
+	}
+	// 授权请求只能使用一次，清理浏览器中的随机串
+	http.SetCookie(rsp.ResponseWriter, &http.Cookie{
+		Name:     oidcNonceCookie,
+		Path:     strings.TrimSuffix(req.Request.URL.Path, "/callback"),
+		MaxAge:   -1,
+		Secure:   req.Request.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	handler.WriteHeaderAndProto(h.userMgn.OIDCLogin(handler.ParseHeaderContext(),
+		req.QueryParameter("code"), req.QueryParameter("state"), binding))
+}
+
 // CreateUsers 批量创建用户
 func (h *HTTPServer) CreateUsers(req *restful.Request, rsp *restful.Response) {
 	handler := &httpcommon.Handler{
@@ -249,6 +317,60 @@ func (h *HTTPServer) ResetUserToken(req *restful.Request, rsp *restful.Response)
 	handler.WriteHeaderAndProto(h.userMgn.ResetUserToken(ctx, user))
 }
 
+// CreateAccessToken 创建个人访问令牌
+func (h *HTTPServer) CreateAccessToken(req *restful.Request, rsp *restful.Response) {
+	handler := &httpcommon.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	tokenReq := &auth.AccessTokenRequest{}
+	if err := httpcommon.ParseJsonBody(req, tokenReq); err != nil {
+		handler.WriteHeaderAndProto(api.NewAuthResponseWithMsg(apimodel.Code_ParseException, err.Error()))
+		return
+	}
+
+	view, errResp := h.userMgn.CreateAccessToken(handler.ParseHeaderContext(), tokenReq)
+	if errResp != nil {
+		handler.WriteHeaderAndProto(errResp)
+		return
+	}
+	_ = rsp.WriteAsJson(view)
+}
+
+// GetAccessTokens 查询用户或者用户组的个人访问令牌
+func (h *HTTPServer) GetAccessTokens(req *restful.Request, rsp *restful.Response) {
+	handler := &httpcommon.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	ret, errResp := h.userMgn.GetAccessTokens(handler.ParseHeaderContext(), httpcommon.ParseQueryParams(req))
+	if errResp != nil {
+		handler.WriteHeaderAndProto(errResp)
+		return
+	}
+	_ = rsp.WriteAsJson(ret)
+}
+
+// RevokeAccessToken 吊销个人访问令牌
+func (h *HTTPServer) RevokeAccessToken(req *restful.Request, rsp *restful.Response) {
+	handler := &httpcommon.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	var revokeReq struct {
+		ID string `json:"id"`
+	}
+	if err := httpcommon.ParseJsonBody(req, &revokeReq); err != nil {
+		handler.WriteHeaderAndProto(api.NewAuthResponseWithMsg(apimodel.Code_ParseException, err.Error()))
+		return
+	}
+
+	handler.WriteHeaderAndProto(h.userMgn.RevokeAccessToken(handler.ParseHeaderContext(), revokeReq.ID))
+}
+
 // CreateGroup 创建用户组
 func (h *HTTPServer) CreateGroup(req *restful.Request, rsp *restful.Response) {
 	handler := &httpcommon.Handler{
@@ -434,6 +556,28 @@ func (h *HTTPServer) UpdateStrategies(req *restful.Request, rsp *restful.Respons
 	handler.WriteHeaderAndProto(h.strategyMgn.UpdateStrategies(ctx, strategies))
 }
 
+// StrategyActionRequest 修改鉴权策略动作的请求
+type StrategyActionRequest struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+}
+
+// UpdateStrategyAction 修改鉴权策略的动作
+func (h *HTTPServer) UpdateStrategyAction(req *restful.Request, rsp *restful.Response) {
+	handler := &httpcommon.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	actionReq := &StrategyActionRequest{}
+	if err := json.NewDecoder(req.Request.Body).Decode(actionReq); err != nil {
+		handler.WriteHeaderAndProto(api.NewResponseWithMsg(apimodel.Code_ParseException, err.Error()))
+		return
+	}
+	ctx := handler.ParseHeaderContext()
+	handler.WriteHeaderAndProto(h.strategyMgn.UpdateStrategyAction(ctx, actionReq.ID, actionReq.Action))
+}
+
 // DeleteStrategies 批量删除鉴权策略
 func (h *HTTPServer) DeleteStrategies(req *restful.Request, rsp *restful.Response) {
 	handler := &httpcommon.Handler{
@@ -24,7 +24,9 @@ import (
 	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
 
 	"github.com/polarismesh/polaris/admin"
+	"github.com/polarismesh/polaris/admin/gitops"
 	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store/migrate"
 )
 
 var (
@@ -134,9 +136,81 @@ func EnrichGetCMDBInfoApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
 		Returns(0, "", []model.LocationView{})
 }
 
+func EnrichGetOperationRecordsApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("查询操作记录").
+		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
+		Param(restful.QueryParameter("resource_type", "资源类型，例如 Routing、Service").
+			DataType(typeNameString).Required(false)).
+		Param(restful.QueryParameter("resource_name", "资源名称").DataType(typeNameString).Required(false)).
+		Param(restful.QueryParameter("namespace", "命名空间").DataType(typeNameString).Required(false)).
+		Param(restful.QueryParameter("operator", "操作人").DataType(typeNameString).Required(false)).
+		Param(restful.QueryParameter("operation_type", "操作类型，例如 Create、Update、Delete").
+			DataType(typeNameString).Required(false)).
+		Param(restful.QueryParameter("start_time", "开始时间，unix 时间戳（秒）").
+			DataType(typeNameInteger).Required(false)).
+		Param(restful.QueryParameter("end_time", "结束时间，unix 时间戳（秒）").
+			DataType(typeNameInteger).Required(false)).
+		Param(restful.QueryParameter("offset", "查询偏移量").DataType(typeNameInteger).Required(false)).
+		Param(restful.QueryParameter("limit", "查询条数，最多100").DataType(typeNameInteger).Required(false)).
+		Returns(0, "", admin.OperationRecordsResp{})
+}
+
+func EnrichGetCryptoKeyVersionsApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("查询加密配置使用的主密钥版本").
+		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
+		Returns(0, "", []admin.CryptoKeyVersion{})
+}
+
 func EnrichGetReportClientsApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
 	return r.
 		Doc("查询SDK实例列表").
 		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
 		Returns(0, "", model.PrometheusDiscoveryResponse{})
 }
+
+func EnrichBackupApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("导出全部治理数据的备份归档").
+		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
+		Param(restful.QueryParameter("instances", "是否包含服务实例，默认不包含").
+			DataType(typeNameBool).Required(false)).
+		ReturnsWithHeaders(0, "", nil, map[string]restful.Header{
+			"Content-Type": {
+				Items: &restful.Items{
+					Type:    "string",
+					Default: "application/gzip",
+				},
+			},
+			"Content-Disposition": {
+				Items: &restful.Items{
+					Type:    "string",
+					Default: "attachment; filename=polaris-backup.jsonl.gz",
+				},
+			},
+		})
+}
+
+func EnrichRestoreApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("从备份归档中恢复治理数据").
+		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
+		Param(restful.QueryParameter("policy", "数据冲突时的处理策略，skip（默认）、overwrite、fail").
+			DataType(typeNameString).Required(false)).
+		Param(restful.BodyParameter("archive", "备份归档").DataType("file").Required(true)).
+		Returns(0, "", []migrate.Result{})
+}
+
+func EnrichReconcileManifestsApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("使用资源清单归档同步路由、限流、熔断规则以及配置文件").
+		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
+		Param(restful.QueryParameter("dry_run", "只返回执行计划，不执行变更").
+			DataType(typeNameBool).Required(false)).
+		Param(restful.QueryParameter("adopt", "接管已经存在且不受 gitops 管理的同名资源，否则作为冲突返回").
+			DataType(typeNameBool).Required(false)).
+		Param(restful.BodyParameter("archive", "包含 YAML 资源清单的 zip 或者 tar.gz 归档").
+			DataType("file").Required(true)).
+		Returns(0, "", gitops.Plan{})
+}
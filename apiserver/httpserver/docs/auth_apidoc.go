@@ -21,6 +21,8 @@ import (
 	"github.com/emicklei/go-restful/v3"
 	restfulspec "github.com/polarismesh/go-restful-openapi/v2"
 	apisecurity "github.com/polarismesh/specification/source/go/api/v1/security"
+
+	"github.com/polarismesh/polaris/auth"
 )
 
 var (
@@ -127,6 +129,22 @@ func EnrichGetStrategyApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
 		}{})
 }
 
+func EnrichUpdateStrategyActionApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("修改鉴权策略的动作").
+		Notes("action 支持 READ_WRITE、ONLY_READ、ALLOW:<列表>、DENY:<列表>，列表元素为 READ、CREATE、MODIFY、DELETE、* "+
+			"或者接口方法名，例如 DENY:DELETE,UpdateInstances，拒绝策略优先于允许策略").
+		Metadata(restfulspec.KeyOpenAPITags, authApiTags).
+		Reads(struct {
+			ID     string `json:"id"`
+			Action string `json:"action"`
+		}{}, "update auth strategy action").
+		Returns(0, "", struct {
+			BaseResponse
+			AuthStrategy apisecurity.AuthStrategy `json:"authStrategy"`
+		}{})
+}
+
 func EnrichDeleteStrategiesApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
 	return r.
 		Doc("批量删除鉴权策略").
@@ -152,6 +170,29 @@ func EnrichLoginApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
 		}{})
 }
 
+func EnrichOIDCAuthorizeApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("OIDC单点登录").
+		Metadata(restfulspec.KeyOpenAPITags, usersApiTags).
+		Notes("跳转至 auth.user.option.oidc 配置的身份提供方进行登录，使用授权码 + PKCE 模式，"+
+			"同时下发 HttpOnly 的 polaris_oidc_nonce cookie 用于绑定发起登录的浏览器").
+		Returns(302, "跳转至身份提供方的授权地址", nil)
+}
+
+func EnrichOIDCLoginApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("OIDC单点登录回调").
+		Metadata(restfulspec.KeyOpenAPITags, usersApiTags).
+		Notes("使用身份提供方返回的授权码完成登录，首次登录时自动创建子账户，并按照用户组声明同步用户组关系，"+
+			"请求需要携带发起登录时下发的 polaris_oidc_nonce cookie").
+		Param(restful.QueryParameter("code", "授权码").DataType("string").Required(true)).
+		Param(restful.QueryParameter("state", "授权请求的 state").DataType("string").Required(true)).
+		Returns(0, "", struct {
+			BaseResponse
+			LoginResponse *apisecurity.LoginResponse `json:"loginResponse"`
+		}{})
+}
+
 func EnrichGetUsersApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
 	return r.
 		Doc("根据相关条件对用户列表进行查询").
@@ -253,6 +294,37 @@ func EnrichResetUserTokenApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder
 		}{})
 }
 
+func EnrichCreateAccessTokenApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("创建个人访问令牌").
+		Metadata(restfulspec.KeyOpenAPITags, usersApiTags).
+		Notes("为用户或者用户组创建带有效期的访问令牌，可以限制命名空间/资源范围以及只读，令牌原文只在创建时返回").
+		Reads(auth.AccessTokenRequest{}, "create access token").
+		Returns(0, "", auth.AccessTokenView{})
+}
+
+func EnrichGetAccessTokensApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("查询个人访问令牌").
+		Metadata(restfulspec.KeyOpenAPITags, usersApiTags).
+		Param(restful.QueryParameter("principal_id", "用户或者用户组ID").DataType("string").Required(true)).
+		Param(restful.QueryParameter("principal_type", "user 或者 group，默认为 user").
+			DataType("string").Required(false)).
+		Param(restful.QueryParameter("offset", "查询偏移量").DataType("integer").Required(false)).
+		Param(restful.QueryParameter("limit", "查询条数，最多100").DataType("integer").Required(false)).
+		Returns(0, "", auth.AccessTokenList{})
+}
+
+func EnrichRevokeAccessTokenApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("吊销个人访问令牌").
+		Metadata(restfulspec.KeyOpenAPITags, usersApiTags).
+		Reads(struct {
+			ID string `json:"id"`
+		}{}, "revoke access token").
+		Returns(0, "", BaseResponse{})
+}
+
 func EnrichCreateGroupApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
 	return r.
 		Doc("创建用户组").
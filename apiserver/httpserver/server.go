@@ -628,11 +628,11 @@ func (h *HTTPServer) enterAuth(req *restful.Request, rsp *restful.Response) erro
 	rid := req.HeaderParameter("Request-Id")
 
 	address := req.Request.RemoteAddr
-	segments := strings.Split(address, ":")
-	if len(segments) != 2 {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
 		return nil
 	}
-	if !h.whitelist.Contain(segments[0]) {
+	if !h.whitelist.Contain(plugin.WhitelistEntry{IP: host, API: req.Request.URL.Path}) {
 		log.Error("http access is not allowed",
 			zap.String("client", address),
 			utils.ZapRequestID(rid))
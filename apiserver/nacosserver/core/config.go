@@ -0,0 +1,129 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/polarismesh/specification/source/go/api/v1/config_manage"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+
+	nacosmodel "github.com/polarismesh/polaris/apiserver/nacosserver/model"
+	"github.com/polarismesh/polaris/cache"
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/config"
+)
+
+// PublishGrayConfig 将 nacos 的 beta/tag 发布转为 polaris 的灰度发布
+// 先更新配置文件的编辑态内容，再停止正在进行中的灰度发布，最后按照 betaLabels 重新发起灰度发布
+// polaris 的配置同一时间只能存在一个灰度发布，因此 beta 与 tag、以及不同的 tag 之间不能同时进行，
+// 存在冲突的灰度发布时拒绝本次发布，需要先停止正在进行中的灰度发布
+func PublishGrayConfig(ctx context.Context, cacheSvr *cache.CacheManager, configSvr config.ConfigCenterServer,
+	req *config_manage.ConfigFilePublishInfo, betaLabels []*apimodel.ClientLabel) *config_manage.ConfigResponse {
+	if len(betaLabels) == 0 {
+		return api.NewConfigResponse(apimodel.Code_InvalidMatchRule)
+	}
+	if msg := checkGrayConflict(cacheSvr, req, betaLabels); msg != "" {
+		return api.NewConfigResponseWithInfo(apimodel.Code_DataConflict, msg)
+	}
+	format := req.GetFormat()
+	if format.GetValue() == "" {
+		format = utils.NewStringValue(utils.FileFormatText)
+	}
+	file := &config_manage.ConfigFile{
+		Namespace: req.GetNamespace(),
+		Group:     req.GetGroup(),
+		Name:      req.GetFileName(),
+		Content:   req.GetContent(),
+		Format:    format,
+		Comment:   req.GetComment(),
+		Tags:      req.GetTags(),
+	}
+	resp := configSvr.CreateConfigFile(ctx, file)
+	if resp.GetCode().GetValue() == uint32(apimodel.Code_ExistedResource) {
+		resp = configSvr.UpdateConfigFile(ctx, file)
+	}
+	switch resp.GetCode().GetValue() {
+	case uint32(apimodel.Code_ExecuteSuccess), uint32(apimodel.Code_NoNeedUpdate):
+	default:
+		return resp
+	}
+
+	releaseKey := &config_manage.ConfigFileRelease{
+		Namespace: req.GetNamespace(),
+		Group:     req.GetGroup(),
+		FileName:  req.GetFileName(),
+	}
+	if stopResp := StopGrayConfig(ctx, configSvr, releaseKey); stopResp.GetCode().GetValue() !=
+		uint32(apimodel.Code_ExecuteSuccess) {
+		return stopResp
+	}
+	return configSvr.PublishConfigFile(ctx, &config_manage.ConfigFileRelease{
+		Namespace:          req.GetNamespace(),
+		Group:              req.GetGroup(),
+		FileName:           req.GetFileName(),
+		ReleaseType:        utils.NewStringValue(model.ReleaseTypeGray),
+		BetaLabels:         betaLabels,
+		ReleaseDescription: req.GetReleaseDescription(),
+	})
+}
+
+// checkGrayConflict 检查正在进行中的灰度发布与本次发布是否冲突，同为 beta 或者相同的 tag 时直接覆盖
+func checkGrayConflict(cacheSvr *cache.CacheManager, req *config_manage.ConfigFilePublishInfo,
+	betaLabels []*apimodel.ClientLabel) string {
+	running := cacheSvr.ConfigFile().GetActiveGrayRelease(req.GetNamespace().GetValue(),
+		req.GetGroup().GetValue(), req.GetFileName().GetValue())
+	if running == nil {
+		return ""
+	}
+	runningLabels := cacheSvr.Gray().GetGrayRule(model.GetGrayConfigRealseKey(running.SimpleConfigFileRelease))
+	runningTag := nacosmodel.ParseConfigTag(runningLabels)
+	tag := nacosmodel.ParseConfigTag(betaLabels)
+	if runningTag == tag {
+		return ""
+	}
+	if runningTag == "" {
+		return fmt.Sprintf("config is in beta publishing, stop beta before publishing tag %s", tag)
+	}
+	return fmt.Sprintf("config is in gray publishing of tag %s, stop it before publishing a new beta or tag",
+		runningTag)
+}
+
+// HitTagGrayRelease 判断配置是否存在 tag 对应的灰度发布
+// nacos 按 tag 查询配置时，tag 没有对应的配置返回不存在，而不是回退到正式发布的配置
+func HitTagGrayRelease(cacheSvr *cache.CacheManager, namespace, group, fileName, tag string) bool {
+	running := cacheSvr.ConfigFile().GetActiveGrayRelease(namespace, group, fileName)
+	if running == nil {
+		return false
+	}
+	labels := cacheSvr.Gray().GetGrayRule(model.GetGrayConfigRealseKey(running.SimpleConfigFileRelease))
+	return nacosmodel.ParseConfigTag(labels) == tag
+}
+
+// StopGrayConfig 停止配置文件的灰度发布，对应 nacos 的 stop beta
+func StopGrayConfig(ctx context.Context, configSvr config.ConfigCenterServer,
+	req *config_manage.ConfigFileRelease) *config_manage.ConfigResponse {
+	batchResp := configSvr.StopGrayConfigFileReleases(ctx, []*config_manage.ConfigFileRelease{req})
+	if len(batchResp.GetResponses()) == 1 {
+		return batchResp.GetResponses()[0]
+	}
+	return api.NewConfigResponseWithInfo(apimodel.Code(batchResp.GetCode().GetValue()), batchResp.GetInfo().GetValue())
+}
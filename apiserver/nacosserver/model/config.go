@@ -27,7 +27,10 @@ import (
 
 	"github.com/emicklei/go-restful/v3"
 	"github.com/polarismesh/specification/source/go/api/v1/config_manage"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
 	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	commonmodel "github.com/polarismesh/polaris/common/model"
 )
 
 type ConfigFileBase struct {
@@ -45,8 +48,8 @@ type ConfigFile struct {
 	CasMd5           string `param:"casMd5"`
 	Type             string `param:"type"`
 	SrcUser          string `param:"srcUser"`
-	Labels           string `param:config_tags`
-	Description      string `param:desc`
+	Labels           string `param:"config_tags"`
+	Description      string `param:"desc"`
 	EncryptedDataKey string `param:"encryptedDataKey"`
 }
 
@@ -78,6 +81,13 @@ func (i *ConfigFile) ToSpecConfigFile() *config_manage.ConfigFilePublishInfo {
 		ReleaseDescription: wrapperspb.String(i.Description),
 	}
 
+	if i.Labels != "" {
+		specFile.Tags = append(specFile.Tags, &config_manage.ConfigFileTag{
+			Key:   wrapperspb.String(ConfigTagsLabelKey),
+			Value: wrapperspb.String(i.Labels),
+		})
+	}
+
 	// TODO 暂时不支持 Nacos 的配置加解密
 	// isCipher := strings.HasPrefix(i.DataId, "cipher-") && i.DataId != "cipher-"
 	// if isCipher {
@@ -94,6 +104,97 @@ func (i *ConfigFile) ToSpecConfigFile() *config_manage.ConfigFilePublishInfo {
 	return specFile
 }
 
+// IsGray 是否为 beta/tag 发布，对应 polaris 的灰度发布
+func (i *ConfigFile) IsGray() bool {
+	return i.BetaIps != "" || i.Tag != ""
+}
+
+// ToReleaseSpec 转为 polaris 的配置发布请求
+func (i *ConfigFile) ToReleaseSpec() *config_manage.ConfigFileRelease {
+	return &config_manage.ConfigFileRelease{
+		Namespace: wrapperspb.String(ToPolarisNamespace(i.Namespace)),
+		Group:     wrapperspb.String(i.Group),
+		FileName:  wrapperspb.String(i.DataId),
+	}
+}
+
+const (
+	// ClientLabelNacosConfigTag nacos 配置的 tag 在灰度规则中对应的客户端标签
+	ClientLabelNacosConfigTag = "NACOS_CONFIG_TAG"
+	// ConfigTagsLabelKey nacos 的 config_tags 在 polaris 配置标签中对应的 key
+	ConfigTagsLabelKey = "config_tags"
+)
+
+// BuildBetaLabels 将 nacos 的 betaIps 或者 tag 转为 polaris 灰度发布的客户端匹配规则，betaIps 优先
+func BuildBetaLabels(betaIps, tag string) []*apimodel.ClientLabel {
+	if betaIps != "" {
+		ips := make([]string, 0, 4)
+		for _, ip := range strings.Split(betaIps, ",") {
+			if ip = strings.TrimSpace(ip); ip != "" {
+				ips = append(ips, ip)
+			}
+		}
+		return []*apimodel.ClientLabel{
+			{
+				Key: commonmodel.ClientLabel_IP,
+				Value: &apimodel.MatchString{
+					Type:  apimodel.MatchString_IN,
+					Value: wrapperspb.String(strings.Join(ips, ",")),
+				},
+			},
+		}
+	}
+	if tag != "" {
+		return []*apimodel.ClientLabel{
+			{
+				Key: ClientLabelNacosConfigTag,
+				Value: &apimodel.MatchString{
+					Type:  apimodel.MatchString_EXACT,
+					Value: wrapperspb.String(tag),
+				},
+			},
+		}
+	}
+	return nil
+}
+
+// ParseBetaIps 从灰度规则中解析出 nacos 的 betaIps
+func ParseBetaIps(labels []*apimodel.ClientLabel) string {
+	for i := range labels {
+		if labels[i].GetKey() == commonmodel.ClientLabel_IP {
+			return labels[i].GetValue().GetValue().GetValue()
+		}
+	}
+	return ""
+}
+
+// ParseConfigTag 从灰度规则中解析 nacos 配置的 tag，不是 tag 灰度时返回空
+func ParseConfigTag(labels []*apimodel.ClientLabel) string {
+	for i := range labels {
+		if labels[i].GetKey() == ClientLabelNacosConfigTag {
+			return labels[i].GetValue().GetValue().GetValue()
+		}
+	}
+	return ""
+}
+
+// BuildClientTags 查询配置时携带的客户端标签，用于匹配 tag 灰度规则
+func BuildClientTags(clientIP, tag string) []*config_manage.ConfigFileTag {
+	tags := []*config_manage.ConfigFileTag{
+		{
+			Key:   wrapperspb.String(commonmodel.ClientLabel_IP),
+			Value: wrapperspb.String(clientIP),
+		},
+	}
+	if tag != "" {
+		tags = append(tags, &config_manage.ConfigFileTag{
+			Key:   wrapperspb.String(ClientLabelNacosConfigTag),
+			Value: wrapperspb.String(tag),
+		})
+	}
+	return tags
+}
+
 const (
 	LineSeparatorRune = '\x01'
 	WordSeparatorRune = '\x02'
@@ -223,3 +324,59 @@ func ParseConfigListenContext(req *restful.Request, configKeysString string) (*C
 
 	return watchCtx, nil
 }
+
+const (
+	ConfigOpTypeInsert = "I"
+	ConfigOpTypeUpdate = "U"
+	ConfigOpTypeDelete = "D"
+)
+
+// ConfigHistoryInfo /nacos/v1/cs/history 返回的配置发布历史
+type ConfigHistoryInfo struct {
+	Id               string `json:"id"`
+	LastId           int64  `json:"lastId"`
+	DataId           string `json:"dataId"`
+	Group            string `json:"group"`
+	Tenant           string `json:"tenant"`
+	AppName          string `json:"appName"`
+	Md5              string `json:"md5"`
+	Content          string `json:"content,omitempty"`
+	SrcIp            string `json:"srcIp"`
+	SrcUser          string `json:"srcUser"`
+	OpType           string `json:"opType"`
+	CreatedTime      string `json:"createdTime"`
+	LastModifiedTime string `json:"lastModifiedTime"`
+}
+
+// ConfigHistoryPage 配置发布历史的分页结果
+type ConfigHistoryPage struct {
+	TotalCount     int                  `json:"totalCount"`
+	PageNumber     int                  `json:"pageNumber"`
+	PagesAvailable int                  `json:"pagesAvailable"`
+	PageItems      []*ConfigHistoryInfo `json:"pageItems"`
+}
+
+// ConfigInfoWrapper /nacos/v1/cs/history/configs 返回的命名空间下的配置
+type ConfigInfoWrapper struct {
+	Id      string `json:"id"`
+	DataId  string `json:"dataId"`
+	Group   string `json:"group"`
+	Tenant  string `json:"tenant"`
+	Md5     string `json:"md5"`
+	Type    string `json:"type"`
+	AppName string `json:"appName"`
+}
+
+// ConfigInfo4Beta /nacos/v1/cs/configs?beta=true 返回的 beta 配置
+type ConfigInfo4Beta struct {
+	Id               string `json:"id"`
+	DataId           string `json:"dataId"`
+	Group            string `json:"group"`
+	Tenant           string `json:"tenant"`
+	AppName          string `json:"appName"`
+	Content          string `json:"content"`
+	Md5              string `json:"md5"`
+	Type             string `json:"type"`
+	EncryptedDataKey string `json:"encryptedDataKey"`
+	BetaIps          string `json:"betaIps"`
+}
@@ -0,0 +1,47 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import "testing"
+
+func TestParseConfigTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		betaIps string
+		tag     string
+		want    string
+	}{
+		{
+			name: "tag",
+			tag:  "canary",
+			want: "canary",
+		},
+		{
+			name:    "beta",
+			betaIps: "127.0.0.1,127.0.0.2",
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseConfigTag(BuildBetaLabels(tt.betaIps, tt.tag)); got != tt.want {
+				t.Errorf("ParseConfigTag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
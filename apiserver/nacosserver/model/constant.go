@@ -54,6 +54,14 @@ const (
 	ParamPageSize          = "pageSize"
 	ParamSelector          = "selector"
 	ParamTenant            = "tenant"
+	ParamProtectThreshold  = "protectThreshold"
+	ParamGroupNameParam    = "groupNameParam"
+	ParamServiceNameParam  = "serviceNameParam"
+	ParamHasIpCount        = "hasIpCount"
+	ParamWithInstances     = "withInstances"
+	ParamBeta              = "beta"
+	ParamBetaIps           = "betaIps"
+	ParamTag               = "tag"
 )
 
 const (
@@ -74,6 +82,7 @@ const (
 )
 
 const (
+	InternalNacosPrefix                  = "internal-nacos-"
 	InternalNacosCluster                 = "internal-nacos-cluster"
 	InternalNacosServiceName             = "internal-nacos-service"
 	InternalNacosServiceProtectThreshold = "internal-nacos-protectThreshold"
@@ -18,6 +18,9 @@
 package model
 
 import (
+	"net/http"
+
+	api "github.com/polarismesh/polaris/common/api/v1"
 	"github.com/polarismesh/polaris/common/utils"
 )
 
@@ -40,3 +43,15 @@ type NacosError struct {
 func (e *NacosError) Error() string {
 	return utils.MustJson(e)
 }
+
+// ToNacosError 将 polaris 的失败响应转为 nacos 错误，polaris 返回码的前三位和 HTTP 状态码一致
+func ToNacosError(resp api.ResponseMessage) *NacosError {
+	code := api.CalcCode(resp)
+	if code < http.StatusBadRequest {
+		code = http.StatusInternalServerError
+	}
+	return &NacosError{
+		ErrCode: int32(code),
+		ErrMsg:  resp.GetInfo().GetValue(),
+	}
+}
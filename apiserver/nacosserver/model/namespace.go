@@ -0,0 +1,45 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+const (
+	// NamespaceTypeGlobal public 命名空间
+	NamespaceTypeGlobal = 0
+	// NamespaceTypeCustom 用户创建的命名空间
+	NamespaceTypeCustom = 2
+	// DefaultNamespaceQuota nacos 控制台展示的命名空间配置数配额
+	DefaultNamespaceQuota = 200
+)
+
+// NamespaceView /nacos/v1/console/namespaces 返回的命名空间信息，
+// polaris 的命名空间名称同时作为 nacos 的命名空间 ID 以及展示名称
+type NamespaceView struct {
+	Namespace         string `json:"namespace"`
+	NamespaceShowName string `json:"namespaceShowName"`
+	NamespaceDesc     string `json:"namespaceDesc"`
+	Quota             int    `json:"quota"`
+	ConfigCount       int    `json:"configCount"`
+	Type              int    `json:"type"`
+}
+
+// RestResult nacos 控制台接口的统一返回结构
+type RestResult struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message,omitempty"`
+	Data    interface{} `json:"data"`
+}
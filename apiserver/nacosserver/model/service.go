@@ -76,3 +76,70 @@ func HandleServiceListRequest(discoverSvr service.DiscoverServer, namespace stri
 	}
 	return viewList, len(services)
 }
+
+// Selector nacos 服务的实例选择器，polaris 不支持选择器，固定为 none
+type Selector struct {
+	Type string `json:"type"`
+}
+
+// HealthChecker nacos 集群的健康检查方式
+type HealthChecker struct {
+	Type string `json:"type"`
+}
+
+// ClusterInfo nacos 集群信息，polaris 中集群来自实例的 internal-nacos-cluster 标签
+type ClusterInfo struct {
+	ServiceName      string            `json:"serviceName"`
+	Name             string            `json:"name"`
+	HealthChecker    *HealthChecker    `json:"healthChecker"`
+	DefaultPort      int               `json:"defaultPort"`
+	DefaultCheckPort int               `json:"defaultCheckPort"`
+	UseIPPort4Check  bool              `json:"useIPPort4Check"`
+	Metadata         map[string]string `json:"metadata"`
+}
+
+// ServiceDetail /nacos/v1/ns/service 查询返回的服务详情
+type ServiceDetail struct {
+	Namespace        string            `json:"namespaceId"`
+	Name             string            `json:"name"`
+	GroupName        string            `json:"groupName"`
+	ProtectThreshold float64           `json:"protectThreshold"`
+	Metadata         map[string]string `json:"metadata"`
+	Selector         *Selector         `json:"selector"`
+	Clusters         []*ClusterInfo    `json:"clusters,omitempty"`
+}
+
+// CatalogService /nacos/v1/ns/catalog/services 中的服务概要信息
+type CatalogService struct {
+	Name                 string `json:"name"`
+	GroupName            string `json:"groupName"`
+	ClusterCount         int    `json:"clusterCount"`
+	IpCount              int    `json:"ipCount"`
+	HealthyInstanceCount int    `json:"healthyInstanceCount"`
+	TriggerFlag          string `json:"triggerFlag"`
+}
+
+// CatalogServiceDetail /nacos/v1/ns/catalog/services 携带 withInstances 时返回的服务以及实例信息
+type CatalogServiceDetail struct {
+	ServiceName string                   `json:"serviceName"`
+	GroupName   string                   `json:"groupName"`
+	Metadata    map[string]string        `json:"metadata"`
+	ClusterMap  map[string]*ClusterHosts `json:"clusterMap"`
+}
+
+// ClusterHosts 集群下的实例列表
+type ClusterHosts struct {
+	Hosts []*Instance `json:"hosts"`
+}
+
+// ToNacosServiceMeta 去掉 polaris 服务中 nacos 内部使用的元数据
+func ToNacosServiceMeta(meta map[string]string) map[string]string {
+	ret := make(map[string]string, len(meta))
+	for k, v := range meta {
+		if strings.HasPrefix(k, InternalNacosPrefix) {
+			continue
+		}
+		ret[k] = v
+	}
+	return ret
+}
@@ -18,8 +18,11 @@
 package v1
 
 import (
+	"strconv"
+
 	"github.com/emicklei/go-restful/v3"
 
+	"github.com/polarismesh/polaris/apiserver/nacosserver/model"
 	nacoshttp "github.com/polarismesh/polaris/apiserver/nacosserver/v1/http"
 )
 
@@ -30,6 +33,17 @@ func (n *NacosV1Server) GetAuthServer() (*restful.WebService, error) {
 	return ws, nil
 }
 
+// GetConsoleServer nacos 控制台的命名空间管理接口
+func (n *NacosV1Server) GetConsoleServer() (*restful.WebService, error) {
+	ws := new(restful.WebService)
+	ws.Path("/nacos/v1/console").Consumes(restful.MIME_JSON, model.MIME).Produces(restful.MIME_JSON)
+	ws.Route(ws.GET("/namespaces").To(n.GetNamespaces))
+	ws.Route(ws.POST("/namespaces").To(n.CreateNamespace))
+	ws.Route(ws.PUT("/namespaces").To(n.UpdateNamespace))
+	ws.Route(ws.DELETE("/namespaces").To(n.DeleteNamespace))
+	return ws, nil
+}
+
 func (n *NacosV1Server) addSystemAccess(ws *restful.WebService) {
 	ws.Route(ws.GET("/operator/metrics").To(n.ServerHealthStatus))
 }
@@ -49,6 +63,94 @@ func (n *NacosV1Server) Login(req *restful.Request, rsp *restful.Response) {
 	nacoshttp.WrirteNacosResponse(data, rsp)
 }
 
+// GetNamespaces 命名空间列表，携带 show=all 时查询单个命名空间，携带 checkNamespaceIdExist 时检查命名空间是否存在
+func (n *NacosV1Server) GetNamespaces(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	ctx := handler.ParseHeaderContext()
+	if check, _ := strconv.ParseBool(req.QueryParameter("checkNamespaceIdExist")); check {
+		namespaceID, err := nacoshttp.Required(req, "customNamespaceId")
+		if err != nil {
+			nacoshttp.WrirteNacosErrorResponse(err, rsp)
+			return
+		}
+		_, err = n.handleGetNamespace(ctx, namespaceID)
+		nacoshttp.WrirteNacosResponse(err == nil, rsp)
+		return
+	}
+	if req.QueryParameter("show") == "all" {
+		data, err := n.handleGetNamespace(ctx, req.QueryParameter(model.ParamNamespaceID))
+		if err != nil {
+			nacoshttp.WrirteNacosErrorResponse(err, rsp)
+			return
+		}
+		nacoshttp.WrirteNacosResponse(data, rsp)
+		return
+	}
+	data, err := n.handleListNamespaces(ctx)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(data, rsp)
+}
+
+func (n *NacosV1Server) CreateNamespace(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	err := n.handleCreateNamespace(handler.ParseHeaderContext(), nacoshttp.Optional(req, "customNamespaceId", ""),
+		nacoshttp.Optional(req, "namespaceName", ""), nacoshttp.Optional(req, "namespaceDesc", ""))
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(true, rsp)
+}
+
+func (n *NacosV1Server) UpdateNamespace(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	namespaceID, err := nacoshttp.Required(req, "namespace")
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	err = n.handleUpdateNamespace(handler.ParseHeaderContext(), namespaceID,
+		nacoshttp.Optional(req, "namespaceDesc", ""))
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(true, rsp)
+}
+
+func (n *NacosV1Server) DeleteNamespace(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	namespaceID, err := nacoshttp.Required(req, model.ParamNamespaceID)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	if err := n.handleDeleteNamespace(handler.ParseHeaderContext(), namespaceID); err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(true, rsp)
+}
+
 func (n *NacosV1Server) ServerHealthStatus(req *restful.Request, rsp *restful.Response) {
 	nacoshttp.WrirteNacosResponse(map[string]interface{}{
 		"status": "UP",
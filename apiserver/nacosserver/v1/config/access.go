@@ -19,6 +19,7 @@ package config
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/emicklei/go-restful/v3"
 	"go.uber.org/zap"
@@ -34,6 +35,15 @@ func (n *ConfigServer) GetClientServer() (*restful.WebService, error) {
 	return ws, nil
 }
 
+func (n *ConfigServer) GetHistoryServer() (*restful.WebService, error) {
+	ws := new(restful.WebService)
+	ws.Path("/nacos/v1/cs/history").Consumes(restful.MIME_JSON, model.MIME).Produces(restful.MIME_JSON)
+	ws.Route(ws.GET("/").To(n.GetHistory))
+	ws.Route(ws.GET("/previous").To(n.PreviousHistory))
+	ws.Route(ws.GET("/configs").To(n.ListNamespaceConfigs))
+	return ws, nil
+}
+
 func (n *ConfigServer) addConfigFileAccess(ws *restful.WebService) {
 	ws.Route(ws.POST("/").To(n.PublishConfig))
 	ws.Route(ws.GET("/").To(n.GetConfig))
@@ -72,8 +82,13 @@ func (n *ConfigServer) GetConfig(req *restful.Request, rsp *restful.Response) {
 		nacoshttp.WrirteNacosErrorResponse(err, rsp)
 		return
 	}
+	if isBetaRequest(req) {
+		nacoshttp.WrirteNacosResponse(n.handleGetBetaConfig(handler.ParseHeaderContext(), baseInfo), rsp)
+		return
+	}
 	ret, err := n.handleGetConfig(handler.ParseHeaderContext(), &model.ConfigFile{
 		ConfigFileBase: *baseInfo,
+		Tag:            nacoshttp.Optional(req, model.ParamTag, ""),
 	}, rsp)
 	if err != nil {
 		nacoshttp.WrirteNacosErrorResponse(err, rsp)
@@ -92,6 +107,10 @@ func (n *ConfigServer) DeleteConfig(req *restful.Request, rsp *restful.Response)
 		nacoshttp.WrirteNacosErrorResponse(err, rsp)
 		return
 	}
+	if isBetaRequest(req) {
+		nacoshttp.WrirteNacosResponse(n.handleStopBetaConfig(handler.ParseHeaderContext(), baseInfo), rsp)
+		return
+	}
 	ret, err := n.handleDeleteConfig(handler.ParseHeaderContext(), &model.ConfigFile{
 		ConfigFileBase: *baseInfo,
 	})
@@ -123,6 +142,82 @@ func (n *ConfigServer) WatchConfigs(req *restful.Request, rsp *restful.Response)
 	n.handleWatch(handler.ParseHeaderContext(), listenCtx, rsp)
 }
 
+// GetHistory 携带 nid 时查询单条历史，否则分页查询配置的发布历史
+func (n *ConfigServer) GetHistory(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	baseInfo, err := parseConfigFileBase(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	if nid := nacoshttp.Optional(req, "nid", ""); nid != "" {
+		ret, err := n.handleGetHistory(handler.ParseHeaderContext(), baseInfo, nid)
+		if err != nil {
+			nacoshttp.WrirteNacosErrorResponse(err, rsp)
+			return
+		}
+		nacoshttp.WrirteNacosResponse(ret, rsp)
+		return
+	}
+	pageNo, _ := strconv.Atoi(nacoshttp.Optional(req, model.ParamPageNo, "1"))
+	pageSize, _ := strconv.Atoi(nacoshttp.Optional(req, model.ParamPageSize, "100"))
+	ret, err := n.handleListHistories(handler.ParseHeaderContext(), baseInfo, pageNo, pageSize)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(ret, rsp)
+}
+
+func (n *ConfigServer) PreviousHistory(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	baseInfo, err := parseConfigFileBase(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	id, err := nacoshttp.Required(req, "id")
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	ret, err := n.handlePreviousHistory(handler.ParseHeaderContext(), baseInfo, id)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(ret, rsp)
+}
+
+func (n *ConfigServer) ListNamespaceConfigs(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	namespace := nacoshttp.Optional(req, model.ParamTenant, model.DefaultNacosConfigNamespace)
+	ret, err := n.handleListNamespaceConfigs(handler.ParseHeaderContext(), namespace)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(ret, rsp)
+}
+
+// isBetaRequest 是否为 nacos 控制台针对 beta 配置的操作
+func isBetaRequest(req *restful.Request) bool {
+	beta, _ := strconv.ParseBool(nacoshttp.Optional(req, model.ParamBeta, "false"))
+	return beta
+}
+
 func parseConfigFileBase(req *restful.Request) (*model.ConfigFileBase, error) {
 	namespace := nacoshttp.Optional(req, model.ParamTenant, model.DefaultNacosConfigNamespace)
 	dataId, err := nacoshttp.Required(req, "dataId")
@@ -42,6 +42,11 @@ func BuildConfigFile(req *restful.Request) (*model.ConfigFile, error) {
 		SrcUser:        nacoshttp.Optional(req, "src_user", ""),
 		Labels:         nacoshttp.Optional(req, "config_tags", ""),
 		Description:    nacoshttp.Optional(req, "desc", ""),
+		Tag:            nacoshttp.Optional(req, model.ParamTag, ""),
+		BetaIps:        req.HeaderParameter(model.ParamBetaIps),
+	}
+	if configfile.BetaIps == "" {
+		configfile.BetaIps = nacoshttp.Optional(req, model.ParamBetaIps, "")
 	}
 
 	return configfile, nil
@@ -21,6 +21,7 @@ import (
 	"context"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,6 +30,7 @@ import (
 	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
 	"go.uber.org/zap"
 
+	"github.com/polarismesh/polaris/apiserver/nacosserver/core"
 	"github.com/polarismesh/polaris/apiserver/nacosserver/model"
 	api "github.com/polarismesh/polaris/common/api/v1"
 	"github.com/polarismesh/polaris/common/metrics"
@@ -41,7 +43,10 @@ import (
 
 func (n *ConfigServer) handlePublishConfig(ctx context.Context, req *model.ConfigFile) (bool, error) {
 	var resp *config_manage.ConfigResponse
-	if req.CasMd5 != "" {
+	if req.IsGray() {
+		resp = core.PublishGrayConfig(ctx, n.cacheSvr, n.configSvr, req.ToSpecConfigFile(),
+			model.BuildBetaLabels(req.BetaIps, req.Tag))
+	} else if req.CasMd5 != "" {
 		resp = n.configSvr.CasUpsertAndReleaseConfigFileFromClient(ctx, req.ToSpecConfigFile())
 	} else {
 		resp = n.configSvr.UpsertAndReleaseConfigFileFromClient(ctx, req.ToSpecConfigFile())
@@ -52,8 +57,12 @@ func (n *ConfigServer) handlePublishConfig(ctx context.Context, req *model.Confi
 	}
 	nacoslog.Error("[NACOS-V1][Config] publish config file fail",
 		zap.Uint32("code", resp.GetCode().GetValue()), zap.String("msg", resp.GetInfo().GetValue()))
+	errCode := int32(model.ExceptionCode_ServerError)
+	if resp.GetCode().GetValue() == uint32(apimodel.Code_DataConflict) {
+		errCode = int32(http.StatusConflict)
+	}
 	return false, &model.NacosError{
-		ErrCode: int32(model.ExceptionCode_ServerError),
+		ErrCode: errCode,
 		ErrMsg:  resp.GetInfo().GetValue(),
 	}
 }
@@ -87,7 +96,17 @@ func (n *ConfigServer) handleGetConfig(ctx context.Context, req *model.ConfigFil
 		})
 	}()
 
-	queryResp = n.configSvr.GetConfigFileWithCache(ctx, req.ToQuerySpec())
+	querySpec := req.ToQuerySpec()
+	if req.Tag != "" {
+		if !core.HitTagGrayRelease(n.cacheSvr, querySpec.GetNamespace().GetValue(), req.Group, req.DataId, req.Tag) {
+			return "", &model.NacosError{
+				ErrCode: int32(http.StatusNotFound),
+				ErrMsg:  "config data not exist",
+			}
+		}
+		querySpec.Tags = model.BuildClientTags(utils.ParseClientIP(ctx), req.Tag)
+	}
+	queryResp = n.configSvr.GetConfigFileWithCache(ctx, querySpec)
 	if queryResp.GetCode().GetValue() != uint32(apimodel.Code_ExecuteSuccess) {
 		nacoslog.Error("[NACOS-V1][Config] query config file fail",
 			zap.Uint32("code", queryResp.GetCode().GetValue()), zap.String("msg", queryResp.GetInfo().GetValue()))
@@ -113,6 +132,62 @@ func (n *ConfigServer) handleGetConfig(ctx context.Context, req *model.ConfigFil
 	return viewRelease.GetContent().GetValue(), nil
 }
 
+// handleGetBetaConfig 查询正在灰度发布中的配置，对应 nacos 的 query beta
+// 灰度发布的内容通过配置中心查询，与正式发布一样经过鉴权以及解密
+func (n *ConfigServer) handleGetBetaConfig(ctx context.Context, req *model.ConfigFileBase) *model.RestResult {
+	namespace := model.ToPolarisNamespace(req.Namespace)
+	betaRelease := n.cacheSvr.ConfigFile().GetActiveGrayRelease(namespace, req.Group, req.DataId)
+	if betaRelease == nil {
+		return &model.RestResult{Code: http.StatusOK, Message: "query beta ok"}
+	}
+	resp := n.configSvr.GetConfigFileRelease(ctx, &config_manage.ConfigFileRelease{
+		Namespace: utils.NewStringValue(namespace),
+		Group:     utils.NewStringValue(req.Group),
+		FileName:  utils.NewStringValue(req.DataId),
+		Name:      utils.NewStringValue(betaRelease.Name),
+	})
+	if code := resp.GetCode().GetValue(); code != uint32(apimodel.Code_ExecuteSuccess) {
+		nacoslog.Error("[NACOS-V1][Config] query beta config file fail",
+			zap.Uint32("code", code), zap.String("msg", resp.GetInfo().GetValue()))
+		if code == uint32(apimodel.Code_NotAllowedAccess) {
+			return &model.RestResult{Code: http.StatusForbidden, Message: resp.GetInfo().GetValue()}
+		}
+		return &model.RestResult{Code: int(model.ExceptionCode_ServerError), Message: resp.GetInfo().GetValue()}
+	}
+	release := resp.GetConfigFileRelease()
+	if release == nil {
+		return &model.RestResult{Code: http.StatusOK, Message: "query beta ok"}
+	}
+	betaLabels := n.cacheSvr.Gray().GetGrayRule(commonmodel.GetGrayConfigRealseKey(betaRelease.SimpleConfigFileRelease))
+	return &model.RestResult{
+		Code:    http.StatusOK,
+		Message: "query beta ok",
+		Data: &model.ConfigInfo4Beta{
+			Id:      strconv.FormatUint(release.GetId().GetValue(), 10),
+			DataId:  req.DataId,
+			Group:   req.Group,
+			Tenant:  req.Namespace,
+			Content: release.GetContent().GetValue(),
+			Md5:     release.GetMd5().GetValue(),
+			Type:    release.GetFormat().GetValue(),
+			BetaIps: model.ParseBetaIps(betaLabels),
+		},
+	}
+}
+
+// handleStopBetaConfig 停止配置的灰度发布，对应 nacos 的 stop beta
+func (n *ConfigServer) handleStopBetaConfig(ctx context.Context, req *model.ConfigFileBase) *model.RestResult {
+	file := &model.ConfigFile{ConfigFileBase: *req}
+	resp := core.StopGrayConfig(ctx, n.configSvr, file.ToReleaseSpec())
+	if resp.GetCode().GetValue() != uint32(apimodel.Code_ExecuteSuccess) {
+		nacoslog.Error("[NACOS-V1][Config] stop beta config file fail",
+			zap.Uint32("code", resp.GetCode().GetValue()), zap.String("msg", resp.GetInfo().GetValue()))
+		return &model.RestResult{Code: int(model.ExceptionCode_ServerError), Message: "remove beta data error",
+			Data: false}
+	}
+	return &model.RestResult{Code: http.StatusOK, Message: "stop beta ok", Data: true}
+}
+
 func (n *ConfigServer) handleWatch(ctx context.Context, listenCtx *model.ConfigWatchContext,
 	rsp *restful.Response) {
 
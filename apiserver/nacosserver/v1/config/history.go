@@ -0,0 +1,207 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package config
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/polarismesh/specification/source/go/api/v1/config_manage"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/apiserver/nacosserver/model"
+	"github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+// handleListHistories com.alibaba.nacos.config.server.controller.HistoryController#listConfigHistory
+func (n *ConfigServer) handleListHistories(ctx context.Context, base *model.ConfigFileBase,
+	pageNo, pageSize int) (*model.ConfigHistoryPage, error) {
+	histories, err := n.listFileHistories(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+	if pageNo < 1 {
+		pageNo = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	page := &model.ConfigHistoryPage{
+		TotalCount:     len(histories),
+		PageNumber:     pageNo,
+		PagesAvailable: (len(histories) + pageSize - 1) / pageSize,
+		PageItems:      []*model.ConfigHistoryInfo{},
+	}
+	start := (pageNo - 1) * pageSize
+	for i := start; i < len(histories) && i < start+pageSize; i++ {
+		item := histories[i]
+		item.Content = ""
+		page.PageItems = append(page.PageItems, item)
+	}
+	return page, nil
+}
+
+// handleGetHistory com.alibaba.nacos.config.server.controller.HistoryController#getConfigHistoryInfo
+func (n *ConfigServer) handleGetHistory(ctx context.Context, base *model.ConfigFileBase,
+	nid string) (*model.ConfigHistoryInfo, error) {
+	histories, err := n.listFileHistories(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range histories {
+		if item.Id == nid {
+			return item, nil
+		}
+	}
+	return nil, &model.NacosError{
+		ErrCode: int32(model.ExceptionCode_NotFound),
+		ErrMsg:  "history not found: " + nid,
+	}
+}
+
+// handlePreviousHistory com.alibaba.nacos.config.server.controller.HistoryController#getPreviousConfigHistoryInfo
+func (n *ConfigServer) handlePreviousHistory(ctx context.Context, base *model.ConfigFileBase,
+	id string) (*model.ConfigHistoryInfo, error) {
+	histories, err := n.listFileHistories(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range histories {
+		if item.Id != id {
+			continue
+		}
+		if i+1 < len(histories) {
+			return histories[i+1], nil
+		}
+		break
+	}
+	return nil, &model.NacosError{
+		ErrCode: int32(model.ExceptionCode_NotFound),
+		ErrMsg:  "previous history not found: " + id,
+	}
+}
+
+// handleListNamespaceConfigs com.alibaba.nacos.config.server.controller.HistoryController#getDataIds
+func (n *ConfigServer) handleListNamespaceConfigs(ctx context.Context,
+	namespace string) ([]*model.ConfigInfoWrapper, error) {
+	_, releases, err := n.cacheSvr.ConfigFile().QueryReleases(&api.ConfigReleaseArgs{
+		BaseConfigArgs: api.BaseConfigArgs{
+			Namespace: model.ToPolarisNamespace(namespace),
+		},
+		OnlyActive: true,
+		NoPage:     true,
+	})
+	if err != nil {
+		return nil, &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_ServerError),
+			ErrMsg:  err.Error(),
+		}
+	}
+	ret := make([]*model.ConfigInfoWrapper, 0, len(releases))
+	for _, item := range releases {
+		ret = append(ret, &model.ConfigInfoWrapper{
+			Id:     strconv.FormatUint(item.Id, 10),
+			DataId: item.FileName,
+			Group:  item.Group,
+			Tenant: model.ToNacosConfigNamespace(item.Namespace),
+			Md5:    item.Md5,
+			Type:   item.Format,
+		})
+	}
+	return ret, nil
+}
+
+// listFileHistories 查询单个配置的全部发布历史，按照 ID 倒序排列。polaris 按照分组以及文件名模糊查询，
+// 因此需要再做一次精确过滤，并且使用 endId 翻页，避免翻页过程中新增的发布记录导致数据错位
+func (n *ConfigServer) listFileHistories(ctx context.Context,
+	base *model.ConfigFileBase) ([]*model.ConfigHistoryInfo, error) {
+	namespace := model.ToPolarisNamespace(base.Namespace)
+	matched := make([]*config_manage.ConfigFileReleaseHistory, 0, 8)
+	var endId uint64
+	for {
+		filter := map[string]string{
+			"namespace": namespace,
+			"group":     base.Group,
+			"name":      base.DataId,
+			"limit":     strconv.Itoa(utils.QueryMaxLimit),
+		}
+		if endId > 0 {
+			filter["endId"] = strconv.FormatUint(endId, 10)
+		}
+		resp := n.configSvr.GetConfigFileReleaseHistories(ctx, filter)
+		if resp.GetCode().GetValue() != uint32(apimodel.Code_ExecuteSuccess) {
+			nacoslog.Error("[NACOS-V1][Config] query config file release history fail",
+				zap.Uint32("code", resp.GetCode().GetValue()), zap.String("msg", resp.GetInfo().GetValue()))
+			return nil, model.ToNacosError(resp)
+		}
+		items := resp.GetConfigFileReleaseHistories()
+		for _, item := range items {
+			if item.GetGroup().GetValue() != base.Group || item.GetFileName().GetValue() != base.DataId {
+				continue
+			}
+			if item.GetStatus().GetValue() != utils.ReleaseStatusSuccess {
+				continue
+			}
+			// 灰度发布不会改变正式配置，nacos 中也不记录历史
+			if rType := item.GetType().GetValue(); rType == utils.ReleaseTypeGray ||
+				rType == utils.ReleaseTypeCancelGray {
+				continue
+			}
+			matched = append(matched, item)
+		}
+		if len(items) < utils.QueryMaxLimit {
+			break
+		}
+		endId = items[len(items)-1].GetId().GetValue()
+	}
+
+	ret := make([]*model.ConfigHistoryInfo, 0, len(matched))
+	for i, item := range matched {
+		info := &model.ConfigHistoryInfo{
+			Id:               strconv.FormatUint(item.GetId().GetValue(), 10),
+			LastId:           -1,
+			DataId:           item.GetFileName().GetValue(),
+			Group:            item.GetGroup().GetValue(),
+			Tenant:           model.ToNacosConfigNamespace(item.GetNamespace().GetValue()),
+			Md5:              item.GetMd5().GetValue(),
+			Content:          item.GetContent().GetValue(),
+			SrcUser:          item.GetCreateBy().GetValue(),
+			OpType:           model.ConfigOpTypeUpdate,
+			CreatedTime:      item.GetCreateTime().GetValue(),
+			LastModifiedTime: item.GetModifyTime().GetValue(),
+		}
+		if i+1 < len(matched) {
+			info.LastId = int64(matched[i+1].GetId().GetValue())
+		}
+		switch {
+		case isDeleteHistory(item):
+			info.OpType = model.ConfigOpTypeDelete
+		case i+1 == len(matched) || isDeleteHistory(matched[i+1]):
+			info.OpType = model.ConfigOpTypeInsert
+		}
+		ret = append(ret, info)
+	}
+	return ret, nil
+}
+
+func isDeleteHistory(item *config_manage.ConfigFileReleaseHistory) bool {
+	rType := item.GetType().GetValue()
+	return rType == utils.ReleaseTypeDelete || rType == utils.ReleaseTypeClean
+}
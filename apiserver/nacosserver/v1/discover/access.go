@@ -19,6 +19,7 @@ package discover
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/emicklei/go-restful/v3"
 
@@ -30,12 +31,24 @@ func (n *DiscoverServer) GetClientServer() (*restful.WebService, error) {
 	ws := new(restful.WebService)
 	ws.Path("/nacos/v1/ns").Consumes(restful.MIME_JSON, model.MIME).Produces(restful.MIME_JSON)
 	n.addInstanceAccess(ws)
+	n.AddServiceAccess(ws)
+	n.addCatalogAccess(ws)
 	n.addSystemAccess(ws)
 	return ws, nil
 }
 
 func (n *DiscoverServer) AddServiceAccess(ws *restful.WebService) {
 	ws.Route(ws.GET("/service/list").To(n.ListServices))
+	ws.Route(ws.POST("/service").To(n.CreateService))
+	ws.Route(ws.PUT("/service").To(n.UpdateService))
+	ws.Route(ws.DELETE("/service").To(n.DeleteService))
+	ws.Route(ws.GET("/service").To(n.GetService))
+}
+
+func (n *DiscoverServer) addCatalogAccess(ws *restful.WebService) {
+	ws.Route(ws.GET("/catalog/services").To(n.CatalogServices))
+	ws.Route(ws.GET("/catalog/service").To(n.CatalogService))
+	ws.Route(ws.GET("/catalog/instances").To(n.CatalogInstances))
 }
 
 func (n *DiscoverServer) addInstanceAccess(ws *restful.WebService) {
@@ -43,6 +56,7 @@ func (n *DiscoverServer) addInstanceAccess(ws *restful.WebService) {
 	ws.Route(ws.PUT("/instance").To(n.UpdateInstance))
 	ws.Route(ws.DELETE("/instance").To(n.DeRegisterInstance))
 	ws.Route(ws.PUT("/instance/beat").To(n.Heartbeat))
+	ws.Route(ws.GET("/instance").To(n.GetInstance))
 	ws.Route(ws.GET("/instance/list").To(n.ListInstances))
 }
 
@@ -178,6 +192,201 @@ func (n *DiscoverServer) ListInstances(req *restful.Request, rsp *restful.Respon
 	nacoshttp.WrirteNacosResponse(data, rsp)
 }
 
+func (n *DiscoverServer) CreateService(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	svc, err := BuildService(parseNamespace(req), req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	if err := n.handleCreateService(handler.ParseHeaderContext(), svc); err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteSimpleResponse("ok", http.StatusOK, rsp)
+}
+
+func (n *DiscoverServer) UpdateService(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	svc, err := BuildService(parseNamespace(req), req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	if err := n.handleUpdateService(handler.ParseHeaderContext(), svc); err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteSimpleResponse("ok", http.StatusOK, rsp)
+}
+
+func (n *DiscoverServer) DeleteService(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	key, err := BuildServiceKey(parseNamespace(req), req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	if err := n.handleDeleteService(handler.ParseHeaderContext(), key); err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteSimpleResponse("ok", http.StatusOK, rsp)
+}
+
+func (n *DiscoverServer) GetService(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	key, err := BuildServiceKey(parseNamespace(req), req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	data, err := n.handleGetService(handler.ParseHeaderContext(), key)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(data, rsp)
+}
+
+func (n *DiscoverServer) GetInstance(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	key, err := BuildServiceKey(parseNamespace(req), req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	ip, err := nacoshttp.Required(req, model.ParamInstanceIP)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	port, err := nacoshttp.RequiredInt(req, model.ParamInstancePort)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	cluster := nacoshttp.Optional(req, model.ParamCluster, "")
+	if len(cluster) == 0 {
+		cluster = nacoshttp.Optional(req, model.ParamClusterName, model.DefaultServiceClusterName)
+	}
+	data, err := n.handleGetInstance(handler.ParseHeaderContext(), key, ip, port, cluster)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(data, rsp)
+}
+
+func (n *DiscoverServer) CatalogServices(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	pageNo, err := nacoshttp.RequiredInt(req, model.ParamPageNo)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	pageSize, err := nacoshttp.RequiredInt(req, model.ParamPageSize)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	hasIpCount, _ := strconv.ParseBool(nacoshttp.Optional(req, model.ParamHasIpCount, "false"))
+	// 和 nacos 保持一致，默认返回实例信息
+	withInstances, _ := strconv.ParseBool(nacoshttp.Optional(req, model.ParamWithInstances, "true"))
+	data := n.handleCatalogServices(handler.ParseHeaderContext(), &CatalogQuery{
+		Namespace:     parseNamespace(req),
+		GroupName:     nacoshttp.Optional(req, model.ParamGroupNameParam, ""),
+		ServiceName:   nacoshttp.Optional(req, model.ParamServiceNameParam, ""),
+		PageNo:        pageNo,
+		PageSize:      pageSize,
+		HasIpCount:    hasIpCount,
+		WithInstances: withInstances,
+	})
+	nacoshttp.WrirteNacosResponse(data, rsp)
+}
+
+func (n *DiscoverServer) CatalogService(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	key, err := BuildServiceKey(parseNamespace(req), req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	data, err := n.handleCatalogService(handler.ParseHeaderContext(), key)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(data, rsp)
+}
+
+func (n *DiscoverServer) CatalogInstances(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	key, err := BuildServiceKey(parseNamespace(req), req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	cluster, err := nacoshttp.Required(req, model.ParamClusterName)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	pageNo, err := nacoshttp.RequiredInt(req, model.ParamPageNo)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	pageSize, err := nacoshttp.RequiredInt(req, model.ParamPageSize)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	data, err := n.handleCatalogInstances(handler.ParseHeaderContext(), key, cluster, pageNo, pageSize)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(data, rsp)
+}
+
+func parseNamespace(req *restful.Request) string {
+	namespace := nacoshttp.Optional(req, model.ParamNamespaceID, model.DefaultNacosNamespace)
+	return model.ToPolarisNamespace(namespace)
+}
+
 func (n *DiscoverServer) ServerHealthStatus(req *restful.Request, rsp *restful.Response) {
 	nacoshttp.WrirteNacosResponse(map[string]interface{}{
 		"status": "UP",
@@ -143,3 +143,48 @@ func parseaMetadata(metadataStr string) (map[string]string, error) {
 
 	return metadata, nil
 }
+
+// BuildServiceKey 解析请求中的服务名以及分组名，兼容 group@@service 形式的服务名
+func BuildServiceKey(namespace string, req *restful.Request) (*model.ServiceKey, error) {
+	service, err := nacoshttp.Required(req, model.ParamServiceName)
+	if err != nil {
+		return nil, err
+	}
+	group := nacoshttp.Optional(req, model.ParamGroupName, model.DefaultServiceGroup)
+	if strings.Contains(service, model.DefaultNacosGroupConnectStr) {
+		ss := strings.SplitN(service, model.DefaultNacosGroupConnectStr, 2)
+		group, service = ss[0], ss[1]
+	}
+	return &model.ServiceKey{
+		Namespace: namespace,
+		Group:     group,
+		Name:      service,
+	}, nil
+}
+
+// BuildService 解析创建以及更新服务的请求，保护阈值保存在服务的元数据中
+func BuildService(namespace string, req *restful.Request) (*model.ServiceMetadata, error) {
+	key, err := BuildServiceKey(namespace, req)
+	if err != nil {
+		return nil, err
+	}
+	thresholdStr := nacoshttp.Optional(req, model.ParamProtectThreshold, "0")
+	threshold, err := strconv.ParseFloat(thresholdStr, 64)
+	if err != nil || threshold < 0 || threshold > 1 {
+		return nil, &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_InvalidParam),
+			ErrMsg:  fmt.Sprintf("protectThreshold invalid: %s, must be between 0 and 1", thresholdStr),
+		}
+	}
+	metadata := map[string]string{}
+	if metadataStr := nacoshttp.Optional(req, model.ParamInstanceMetadata, ""); metadataStr != "" {
+		if metadata, err = parseaMetadata(metadataStr); err != nil {
+			return nil, err
+		}
+	}
+	return &model.ServiceMetadata{
+		ServiceKey:          *key,
+		ProtectionThreshold: threshold,
+		ExtendData:          metadata,
+	}, nil
+}
@@ -0,0 +1,151 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package discover
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/polarismesh/polaris/apiserver/nacosserver/model"
+	commonmodel "github.com/polarismesh/polaris/common/model"
+)
+
+// CatalogQuery /nacos/v1/ns/catalog/services 的查询条件
+type CatalogQuery struct {
+	Namespace     string
+	GroupName     string
+	ServiceName   string
+	PageNo        int
+	PageSize      int
+	HasIpCount    bool
+	WithInstances bool
+}
+
+// handleCatalogServices com.alibaba.nacos.naming.controllers.CatalogController#listDetail，
+// 分组名以及服务名为模糊匹配
+func (n *DiscoverServer) handleCatalogServices(ctx context.Context, query *CatalogQuery) interface{} {
+	_, services := n.discoverSvr.Cache().Service().ListServices(query.Namespace)
+	type matchedService struct {
+		key *model.ServiceKey
+		svc *commonmodel.Service
+	}
+	matched := make([]matchedService, 0, len(services))
+	for _, svc := range services {
+		if svc.IsAlias() {
+			continue
+		}
+		key := &model.ServiceKey{
+			Namespace: svc.Namespace,
+			Group:     model.GetGroupName(svc.Name),
+			Name:      model.GetServiceName(svc.Name),
+		}
+		if !strings.Contains(key.Group, query.GroupName) || !strings.Contains(key.Name, query.ServiceName) {
+			continue
+		}
+		if query.HasIpCount &&
+			n.discoverSvr.Cache().Instance().GetInstancesCountByServiceID(svc.ID).TotalInstanceCount == 0 {
+			continue
+		}
+		matched = append(matched, matchedService{key: key, svc: svc})
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].key.Group != matched[j].key.Group {
+			return matched[i].key.Group < matched[j].key.Group
+		}
+		return matched[i].key.Name < matched[j].key.Name
+	})
+	total := len(matched)
+	start, end := pageRange(total, query.PageNo, query.PageSize)
+	matched = matched[start:end]
+
+	if query.WithInstances {
+		details := make([]*model.CatalogServiceDetail, 0, len(matched))
+		for _, item := range matched {
+			detail := &model.CatalogServiceDetail{
+				ServiceName: item.key.Name,
+				GroupName:   item.key.Group,
+				Metadata:    model.ToNacosServiceMeta(item.svc.Meta),
+				ClusterMap:  map[string]*model.ClusterHosts{},
+			}
+			for _, cluster := range n.groupByCluster(item.svc, item.key) {
+				detail.ClusterMap[cluster.info.Name] = &model.ClusterHosts{Hosts: cluster.hosts}
+			}
+			details = append(details, detail)
+		}
+		return details
+	}
+
+	serviceList := make([]*model.CatalogService, 0, len(matched))
+	for _, item := range matched {
+		view := &model.CatalogService{
+			Name:        item.key.Name,
+			GroupName:   item.key.Group,
+			TriggerFlag: "false",
+		}
+		for _, cluster := range n.groupByCluster(item.svc, item.key) {
+			view.ClusterCount++
+			view.IpCount += len(cluster.hosts)
+			for _, ins := range cluster.hosts {
+				if ins.Healthy {
+					view.HealthyInstanceCount++
+				}
+			}
+		}
+		serviceList = append(serviceList, view)
+	}
+	return map[string]interface{}{
+		"count":       total,
+		"serviceList": serviceList,
+	}
+}
+
+// handleCatalogService com.alibaba.nacos.naming.controllers.CatalogController#serviceDetail
+func (n *DiscoverServer) handleCatalogService(ctx context.Context,
+	key *model.ServiceKey) (map[string]interface{}, error) {
+	detail, err := n.handleGetService(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	clusters := detail.Clusters
+	detail.Clusters = nil
+	return map[string]interface{}{
+		"service":  detail,
+		"clusters": clusters,
+	}, nil
+}
+
+// handleCatalogInstances com.alibaba.nacos.naming.controllers.CatalogController#instanceList
+func (n *DiscoverServer) handleCatalogInstances(ctx context.Context, key *model.ServiceKey, cluster string,
+	pageNo, pageSize int) (map[string]interface{}, error) {
+	svc, err := n.getService(key)
+	if err != nil {
+		return nil, err
+	}
+	instances := make([]*model.Instance, 0, 8)
+	for _, ins := range n.listInstances(svc, key) {
+		if ins.ClusterName == cluster {
+			instances = append(instances, ins)
+		}
+	}
+	start, end := pageRange(len(instances), pageNo, pageSize)
+	return map[string]interface{}{
+		"count": len(instances),
+		"list":  instances[start:end],
+	}, nil
+}
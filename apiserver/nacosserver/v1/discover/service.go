@@ -0,0 +1,223 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package discover
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+
+	"github.com/polarismesh/polaris/apiserver/nacosserver/model"
+	commonmodel "github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+// handleCreateService com.alibaba.nacos.naming.controllers.ServiceController#create
+func (n *DiscoverServer) handleCreateService(ctx context.Context, svc *model.ServiceMetadata) error {
+	resp := n.discoverSvr.CreateServices(ctx, []*apiservice.Service{toSpecService(svc)})
+	return checkBatchWriteResponse(resp)
+}
+
+// handleUpdateService com.alibaba.nacos.naming.controllers.ServiceController#update，
+// 和 nacos 一致，保护阈值以及元数据均为整体覆盖
+func (n *DiscoverServer) handleUpdateService(ctx context.Context, svc *model.ServiceMetadata) error {
+	resp := n.discoverSvr.UpdateServices(ctx, []*apiservice.Service{toSpecService(svc)})
+	return checkBatchWriteResponse(resp)
+}
+
+// handleDeleteService com.alibaba.nacos.naming.controllers.ServiceController#remove
+func (n *DiscoverServer) handleDeleteService(ctx context.Context, key *model.ServiceKey) error {
+	resp := n.discoverSvr.DeleteServices(ctx, []*apiservice.Service{{
+		Name:      utils.NewStringValue(model.BuildServiceName(key.Name, key.Group)),
+		Namespace: utils.NewStringValue(key.Namespace),
+	}})
+	return checkBatchWriteResponse(resp)
+}
+
+// handleGetService com.alibaba.nacos.naming.controllers.ServiceController#detail
+func (n *DiscoverServer) handleGetService(ctx context.Context, key *model.ServiceKey) (*model.ServiceDetail, error) {
+	svc, err := n.getService(key)
+	if err != nil {
+		return nil, err
+	}
+	detail := &model.ServiceDetail{
+		Namespace:        model.ToNacosNamespace(svc.Namespace),
+		Name:             key.Name,
+		GroupName:        key.Group,
+		ProtectThreshold: protectThreshold(svc),
+		Metadata:         model.ToNacosServiceMeta(svc.Meta),
+		Selector:         &model.Selector{Type: "none"},
+		Clusters:         []*model.ClusterInfo{},
+	}
+	for _, cluster := range n.groupByCluster(svc, key) {
+		detail.Clusters = append(detail.Clusters, cluster.info)
+	}
+	return detail, nil
+}
+
+// handleGetInstance com.alibaba.nacos.naming.controllers.InstanceController#detail
+func (n *DiscoverServer) handleGetInstance(ctx context.Context, key *model.ServiceKey, ip string, port int,
+	cluster string) (map[string]interface{}, error) {
+	svc, err := n.getService(key)
+	if err != nil {
+		return nil, err
+	}
+	for _, ins := range n.listInstances(svc, key) {
+		if ins.IP != ip || int(ins.Port) != port || ins.ClusterName != cluster {
+			continue
+		}
+		return map[string]interface{}{
+			"service":     ins.ServiceName,
+			"ip":          ins.IP,
+			"port":        ins.Port,
+			"clusterName": ins.ClusterName,
+			"weight":      ins.Weight,
+			"healthy":     ins.Healthy,
+			"enabled":     ins.Enabled,
+			"ephemeral":   ins.Ephemeral,
+			"instanceId":  ins.Id,
+			"metadata":    ins.Metadata,
+		}, nil
+	}
+	return nil, &model.NacosError{
+		ErrCode: int32(model.ExceptionCode_NotFound),
+		ErrMsg:  fmt.Sprintf("no matched ip found: %s:%d in cluster %s", ip, port, cluster),
+	}
+}
+
+func (n *DiscoverServer) getService(key *model.ServiceKey) (*commonmodel.Service, error) {
+	svc := n.discoverSvr.Cache().Service().GetServiceByName(model.BuildServiceName(key.Name, key.Group),
+		key.Namespace)
+	if svc == nil {
+		return nil, &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_NotFound),
+			ErrMsg:  "service not found: " + model.BuildServiceName(key.Name, key.Group) + "@" + key.Namespace,
+		}
+	}
+	return svc, nil
+}
+
+// listInstances 返回服务下的全部实例，包括不健康以及隔离的实例
+func (n *DiscoverServer) listInstances(svc *commonmodel.Service, key *model.ServiceKey) []*model.Instance {
+	instances := n.discoverSvr.Cache().Instance().GetInstancesByServiceID(svc.ID)
+	ret := make([]*model.Instance, 0, len(instances))
+	for i := range instances {
+		ins := &model.Instance{}
+		ins.FromSpecInstance(instances[i])
+		if ins.ClusterName == "" {
+			ins.ClusterName = model.DefaultServiceClusterName
+		}
+		ins.ServiceName = key.Group + model.DefaultNacosGroupConnectStr + key.Name
+		ret = append(ret, ins)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].IP != ret[j].IP {
+			return ret[i].IP < ret[j].IP
+		}
+		return ret[i].Port < ret[j].Port
+	})
+	return ret
+}
+
+type clusterInstances struct {
+	info  *model.ClusterInfo
+	hosts []*model.Instance
+}
+
+// groupByCluster 按照集群名称对实例分组，集群按照名称排序
+func (n *DiscoverServer) groupByCluster(svc *commonmodel.Service, key *model.ServiceKey) []*clusterInstances {
+	clusters := map[string]*clusterInstances{}
+	for _, ins := range n.listInstances(svc, key) {
+		cluster, ok := clusters[ins.ClusterName]
+		if !ok {
+			cluster = &clusterInstances{
+				info: &model.ClusterInfo{
+					ServiceName:      key.Name,
+					Name:             ins.ClusterName,
+					HealthChecker:    &model.HealthChecker{Type: "NONE"},
+					DefaultPort:      80,
+					DefaultCheckPort: 80,
+					UseIPPort4Check:  true,
+					Metadata:         map[string]string{},
+				},
+			}
+			clusters[ins.ClusterName] = cluster
+		}
+		cluster.hosts = append(cluster.hosts, ins)
+	}
+	ret := make([]*clusterInstances, 0, len(clusters))
+	for _, cluster := range clusters {
+		ret = append(ret, cluster)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].info.Name < ret[j].info.Name
+	})
+	return ret
+}
+
+func toSpecService(svc *model.ServiceMetadata) *apiservice.Service {
+	metadata := make(map[string]string, len(svc.ExtendData)+1)
+	for k, v := range svc.ExtendData {
+		metadata[k] = v
+	}
+	metadata[model.InternalNacosServiceProtectThreshold] = strconv.FormatFloat(svc.ProtectionThreshold, 'f', -1, 64)
+	return &apiservice.Service{
+		Name:      utils.NewStringValue(model.BuildServiceName(svc.Name, svc.Group)),
+		Namespace: utils.NewStringValue(svc.Namespace),
+		Metadata:  metadata,
+	}
+}
+
+func protectThreshold(svc *commonmodel.Service) float64 {
+	threshold, _ := strconv.ParseFloat(svc.Meta[model.InternalNacosServiceProtectThreshold], 64)
+	return threshold
+}
+
+// checkBatchWriteResponse 单个资源的批量写请求，优先使用具体资源的错误信息
+func checkBatchWriteResponse(resp *apiservice.BatchWriteResponse) error {
+	if apimodel.Code(resp.GetCode().GetValue()) == apimodel.Code_ExecuteSuccess {
+		return nil
+	}
+	if len(resp.GetResponses()) > 0 {
+		return model.ToNacosError(resp.GetResponses()[0])
+	}
+	return model.ToNacosError(resp)
+}
+
+// pageRange 计算分页的起止下标，pageNo 从 1 开始
+func pageRange(total, pageNo, pageSize int) (int, int) {
+	if pageNo < 1 {
+		pageNo = 1
+	}
+	if pageSize <= 0 {
+		return 0, 0
+	}
+	start := (pageNo - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	return start, end
+}
@@ -95,6 +95,10 @@ func (h *Handler) ParseHeaderContext() context.Context {
 	}
 	ctx = context.WithValue(ctx, utils.StringContext("operator"), operator)
 	ctx = context.WithValue(ctx, utils.ContextClientAddress, h.Request.Request.RemoteAddr)
+	// accessToken 在请求预处理时已经写入 header，服务、命名空间等管理接口需要据此鉴权
+	if authToken := h.Request.HeaderParameter(utils.HeaderAuthTokenKey); authToken != "" {
+		ctx = context.WithValue(ctx, utils.ContextAuthTokenKey, authToken)
+	}
 	return ctx
 }
 
@@ -0,0 +1,159 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package v1
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+
+	"github.com/polarismesh/polaris/apiserver/nacosserver/model"
+	"github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+// handleListNamespaces com.alibaba.nacos.console.controller.NamespaceController#getNamespaces
+func (n *NacosV1Server) handleListNamespaces(ctx context.Context) (*model.RestResult, error) {
+	views := make([]*model.NamespaceView, 0, 8)
+	for offset := 0; ; offset += utils.QueryMaxLimit {
+		resp := n.namespaceSvr.GetNamespaces(ctx, map[string][]string{
+			"offset": {strconv.Itoa(offset)},
+			"limit":  {strconv.Itoa(utils.QueryMaxLimit)},
+		})
+		if apimodel.Code(resp.GetCode().GetValue()) != apimodel.Code_ExecuteSuccess {
+			return nil, model.ToNacosError(resp)
+		}
+		for _, item := range resp.GetNamespaces() {
+			views = append(views, n.toNamespaceView(item))
+		}
+		if len(resp.GetNamespaces()) < utils.QueryMaxLimit {
+			break
+		}
+	}
+	// public 命名空间排在第一位
+	for i := range views {
+		if views[i].Type == model.NamespaceTypeGlobal {
+			views[0], views[i] = views[i], views[0]
+			break
+		}
+	}
+	return &model.RestResult{Code: http.StatusOK, Data: views}, nil
+}
+
+// handleGetNamespace com.alibaba.nacos.console.controller.NamespaceController#getNamespace
+func (n *NacosV1Server) handleGetNamespace(ctx context.Context, namespaceID string) (*model.NamespaceView, error) {
+	name := model.ToPolarisNamespace(namespaceID)
+	resp := n.namespaceSvr.GetNamespaces(ctx, map[string][]string{
+		"name": {name},
+	})
+	if apimodel.Code(resp.GetCode().GetValue()) != apimodel.Code_ExecuteSuccess {
+		return nil, model.ToNacosError(resp)
+	}
+	for _, item := range resp.GetNamespaces() {
+		if item.GetName().GetValue() == name {
+			return n.toNamespaceView(item), nil
+		}
+	}
+	return nil, &model.NacosError{
+		ErrCode: int32(model.ExceptionCode_NotFound),
+		ErrMsg:  "namespace not found: " + namespaceID,
+	}
+}
+
+// handleCreateNamespace com.alibaba.nacos.console.controller.NamespaceController#createNamespace，
+// polaris 的命名空间没有单独的展示名称，未指定 customNamespaceId 时使用 namespaceName 作为命名空间 ID
+func (n *NacosV1Server) handleCreateNamespace(ctx context.Context, namespaceID, name, desc string) error {
+	if namespaceID == "" {
+		namespaceID = name
+	}
+	if namespaceID == "" || model.ToPolarisNamespace(namespaceID) == model.ConvertPolarisNamespaceVal {
+		return &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_InvalidParam),
+			ErrMsg:  "illegal namespace: " + namespaceID,
+		}
+	}
+	resp := n.namespaceSvr.CreateNamespace(ctx, &apimodel.Namespace{
+		Name:    utils.NewStringValue(namespaceID),
+		Comment: utils.NewStringValue(desc),
+	})
+	if apimodel.Code(resp.GetCode().GetValue()) != apimodel.Code_ExecuteSuccess {
+		return model.ToNacosError(resp)
+	}
+	return nil
+}
+
+// handleUpdateNamespace com.alibaba.nacos.console.controller.NamespaceController#editNamespace，只能修改描述
+func (n *NacosV1Server) handleUpdateNamespace(ctx context.Context, namespaceID, desc string) error {
+	resp := n.namespaceSvr.UpdateNamespaces(ctx, []*apimodel.Namespace{{
+		Name:    utils.NewStringValue(model.ToPolarisNamespace(namespaceID)),
+		Comment: utils.NewStringValue(desc),
+	}})
+	if apimodel.Code(resp.GetCode().GetValue()) != apimodel.Code_ExecuteSuccess {
+		if len(resp.GetResponses()) > 0 {
+			return model.ToNacosError(resp.GetResponses()[0])
+		}
+		return model.ToNacosError(resp)
+	}
+	return nil
+}
+
+// handleDeleteNamespace com.alibaba.nacos.console.controller.NamespaceController#deleteNamespace
+func (n *NacosV1Server) handleDeleteNamespace(ctx context.Context, namespaceID string) error {
+	if model.ToPolarisNamespace(namespaceID) == model.ConvertPolarisNamespaceVal {
+		return &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_InvalidParam),
+			ErrMsg:  "public namespace can not be deleted",
+		}
+	}
+	resp := n.namespaceSvr.DeleteNamespace(ctx, &apimodel.Namespace{
+		Name: utils.NewStringValue(namespaceID),
+	})
+	if apimodel.Code(resp.GetCode().GetValue()) != apimodel.Code_ExecuteSuccess {
+		return model.ToNacosError(resp)
+	}
+	return nil
+}
+
+func (n *NacosV1Server) toNamespaceView(item *apimodel.Namespace) *model.NamespaceView {
+	name := item.GetName().GetValue()
+	view := &model.NamespaceView{
+		Namespace:         name,
+		NamespaceShowName: name,
+		NamespaceDesc:     item.GetComment().GetValue(),
+		Quota:             model.DefaultNamespaceQuota,
+		Type:              model.NamespaceTypeCustom,
+	}
+	if name == model.ConvertPolarisNamespaceVal {
+		view.Namespace = model.DefaultNacosConfigNamespace
+		view.NamespaceShowName = model.DefaultNacosNamespace
+		view.Type = model.NamespaceTypeGlobal
+	}
+	if n.store != nil {
+		count, _, _ := n.store.Cache().ConfigFile().QueryReleases(&api.ConfigReleaseArgs{
+			BaseConfigArgs: api.BaseConfigArgs{
+				Namespace: name,
+			},
+			OnlyActive: true,
+			NoPage:     true,
+		})
+		view.ConfigCount = int(count)
+	}
+	return view
+}
@@ -43,6 +43,7 @@ func WithConnLimitConfig(connLimitConfig *connlimit.Config) option {
 
 func WithNamespaceSvr(namespaceSvr namespace.NamespaceOperateServer) option {
 	return func(svr *NacosV1Server) {
+		svr.namespaceSvr = namespaceSvr
 		svr.discoverOpt.NamespaceSvr = namespaceSvr
 		svr.configOpt.NamespaceSvr = namespaceSvr
 	}
@@ -43,6 +43,7 @@ import (
 	"github.com/polarismesh/polaris/common/metrics"
 	"github.com/polarismesh/polaris/common/secure"
 	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/namespace"
 	"github.com/polarismesh/polaris/plugin"
 )
 
@@ -88,7 +89,8 @@ type NacosV1Server struct {
 	pushCenter core.PushCenter
 	store      *core.NacosDataStorage
 
-	checker auth.StrategyServer
+	checker      auth.StrategyServer
+	namespaceSvr namespace.NamespaceOperateServer
 
 	discoverOpt *discover.ServerOption
 	discoverSvr *discover.DiscoverServer
@@ -212,6 +214,16 @@ func (h *NacosV1Server) createRestfulContainer() (*restful.Container, error) {
 		return nil, err
 	}
 	wsContainer.Add(clientSvc)
+	historySvc, err := h.configSvr.GetHistoryServer()
+	if err != nil {
+		return nil, err
+	}
+	wsContainer.Add(historySvc)
+	consoleSvc, err := h.GetConsoleServer()
+	if err != nil {
+		return nil, err
+	}
+	wsContainer.Add(consoleSvc)
 	debugSvc, err := h.GetDebugServer()
 	if err != nil {
 		return nil, err
@@ -321,11 +333,11 @@ func (h *NacosV1Server) enterAuth(req *restful.Request, rsp *restful.Response) e
 	rid := req.HeaderParameter("Request-Id")
 
 	address := req.Request.RemoteAddr
-	segments := strings.Split(address, ":")
-	if len(segments) != 2 {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
 		return nil
 	}
-	if !h.whitelist.Contain(segments[0]) {
+	if !h.whitelist.Contain(plugin.WhitelistEntry{IP: host, API: req.Request.URL.Path}) {
 		log.Error("nacos http server http access is not allowed",
 			zap.String("client", address),
 			utils.ZapRequestID(rid))
@@ -0,0 +1,408 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/apiserver/nacosserver/core"
+	"github.com/polarismesh/polaris/apiserver/nacosserver/model"
+	_ "github.com/polarismesh/polaris/cache"
+	"github.com/polarismesh/polaris/common/utils"
+	_ "github.com/polarismesh/polaris/plugin/healthchecker/memory"
+	_ "github.com/polarismesh/polaris/plugin/history/logger"
+	_ "github.com/polarismesh/polaris/plugin/password"
+	_ "github.com/polarismesh/polaris/store/boltdb"
+	testsuit "github.com/polarismesh/polaris/test/suit"
+)
+
+type nacosClient struct {
+	t     *testing.T
+	url   string
+	token string
+}
+
+func (c *nacosClient) do(method, path string, params url.Values) (int, []byte) {
+	var body io.Reader
+	target := c.url + path
+	if method == http.MethodPost || method == http.MethodPut {
+		body = strings.NewReader(params.Encode())
+	} else {
+		target += "?" + params.Encode()
+	}
+	req, err := http.NewRequest(method, target, body)
+	assert.NoError(c.t, err)
+	req.Header.Set(utils.HeaderAuthTokenKey, c.token)
+	req.Header.Set("Content-Type", model.MIME)
+	rsp, err := http.DefaultClient.Do(req)
+	assert.NoError(c.t, err)
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+	data, err := io.ReadAll(rsp.Body)
+	assert.NoError(c.t, err)
+	return rsp.StatusCode, data
+}
+
+func newNacosV1ServerForTest(t *testing.T) (*testsuit.DiscoverTestSuit, *nacosClient) {
+	discoverSuit := &testsuit.DiscoverTestSuit{}
+	if err := discoverSuit.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(discoverSuit.Destroy)
+
+	svr, err := NewNacosV1Server(core.NewNacosDataStorage(discoverSuit.CacheMgr()),
+		WithNamespaceSvr(discoverSuit.NamespaceServer()),
+		WithDiscoverSvr(discoverSuit.DiscoverServer(), discoverSuit.OriginDiscoverServer(),
+			discoverSuit.HealthCheckServer()),
+		WithConfigSvr(discoverSuit.ConfigServer(), discoverSuit.OriginConfigServer()),
+		WithAuthSvr(discoverSuit.UserServer(), discoverSuit.StrategyServer()),
+	)
+	assert.NoError(t, err)
+	container, err := svr.createRestfulContainer()
+	assert.NoError(t, err)
+	httpSvr := httptest.NewServer(container)
+	t.Cleanup(httpSvr.Close)
+	return discoverSuit, &nacosClient{t: t, url: httpSvr.URL, token: utils.ParseAuthToken(discoverSuit.DefaultCtx)}
+}
+
+func TestNacosV1_Service(t *testing.T) {
+	discoverSuit, client := newNacosV1ServerForTest(t)
+	t.Cleanup(func() {
+		discoverSuit.CleanService("order__nacos-svc", "default")
+	})
+
+	code, data := client.do(http.MethodPost, "/nacos/v1/ns/service", url.Values{
+		"serviceName":      {"nacos-svc"},
+		"groupName":        {"order"},
+		"protectThreshold": {"0.5"},
+		"metadata":         {`{"owner":"ops"}`},
+	})
+	assert.Equal(t, http.StatusOK, code, string(data))
+	assert.Equal(t, "ok", string(data))
+	// 重复创建
+	code, _ = client.do(http.MethodPost, "/nacos/v1/ns/service", url.Values{
+		"serviceName": {"order@@nacos-svc"},
+	})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, data = client.do(http.MethodPost, "/nacos/v1/ns/instance", url.Values{
+		"serviceName": {"order@@nacos-svc"},
+		"ip":          {"127.0.0.20"},
+		"port":        {"8080"},
+		"metadata":    {`{"env":"test"}`},
+	})
+	assert.Equal(t, http.StatusOK, code, string(data))
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+
+	code, data = client.do(http.MethodGet, "/nacos/v1/ns/service", url.Values{
+		"serviceName": {"nacos-svc"},
+		"groupName":   {"order"},
+	})
+	assert.Equal(t, http.StatusOK, code, string(data))
+	detail := &model.ServiceDetail{}
+	assert.NoError(t, json.Unmarshal(data, detail))
+	assert.Equal(t, "public", detail.Namespace)
+	assert.Equal(t, "order", detail.GroupName)
+	assert.Equal(t, 0.5, detail.ProtectThreshold)
+	assert.Equal(t, map[string]string{"owner": "ops"}, detail.Metadata)
+	assert.Len(t, detail.Clusters, 1)
+	assert.Equal(t, model.DefaultServiceClusterName, detail.Clusters[0].Name)
+
+	code, data = client.do(http.MethodGet, "/nacos/v1/ns/instance", url.Values{
+		"serviceName": {"nacos-svc"},
+		"groupName":   {"order"},
+		"ip":          {"127.0.0.20"},
+		"port":        {"8080"},
+	})
+	assert.Equal(t, http.StatusOK, code, string(data))
+	instance := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(data, &instance))
+	assert.Equal(t, "order@@nacos-svc", instance["service"])
+	code, _ = client.do(http.MethodGet, "/nacos/v1/ns/instance", url.Values{
+		"serviceName": {"order@@nacos-svc"},
+		"ip":          {"127.0.0.21"},
+		"port":        {"8080"},
+	})
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, data = client.do(http.MethodGet, "/nacos/v1/ns/catalog/services", url.Values{
+		"pageNo":           {"1"},
+		"pageSize":         {"10"},
+		"serviceNameParam": {"nacos-s"},
+		"withInstances":    {"false"},
+	})
+	assert.Equal(t, http.StatusOK, code, string(data))
+	catalog := struct {
+		Count       int                     `json:"count"`
+		ServiceList []*model.CatalogService `json:"serviceList"`
+	}{}
+	assert.NoError(t, json.Unmarshal(data, &catalog))
+	assert.Equal(t, 1, catalog.Count)
+	assert.Equal(t, "order", catalog.ServiceList[0].GroupName)
+	assert.Equal(t, 1, catalog.ServiceList[0].IpCount)
+
+	code, data = client.do(http.MethodGet, "/nacos/v1/ns/catalog/instances", url.Values{
+		"serviceName": {"order@@nacos-svc"},
+		"clusterName": {model.DefaultServiceClusterName},
+		"pageNo":      {"1"},
+		"pageSize":    {"10"},
+	})
+	assert.Equal(t, http.StatusOK, code, string(data))
+	instances := struct {
+		Count int               `json:"count"`
+		List  []*model.Instance `json:"list"`
+	}{}
+	assert.NoError(t, json.Unmarshal(data, &instances))
+	assert.Equal(t, 1, instances.Count)
+	assert.Equal(t, "test", instances.List[0].Metadata["env"])
+
+	code, data = client.do(http.MethodPut, "/nacos/v1/ns/service", url.Values{
+		"serviceName":      {"order@@nacos-svc"},
+		"protectThreshold": {"0.8"},
+	})
+	assert.Equal(t, http.StatusOK, code, string(data))
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+	_, data = client.do(http.MethodGet, "/nacos/v1/ns/catalog/service", url.Values{
+		"serviceName": {"order@@nacos-svc"},
+	})
+	catalogService := struct {
+		Service  *model.ServiceDetail `json:"service"`
+		Clusters []*model.ClusterInfo `json:"clusters"`
+	}{}
+	assert.NoError(t, json.Unmarshal(data, &catalogService))
+	assert.Equal(t, 0.8, catalogService.Service.ProtectThreshold)
+	assert.Empty(t, catalogService.Service.Metadata)
+	assert.Len(t, catalogService.Clusters, 1)
+
+	code, _ = client.do(http.MethodDelete, "/nacos/v1/ns/instance", url.Values{
+		"serviceName": {"order@@nacos-svc"},
+		"ip":          {"127.0.0.20"},
+		"port":        {"8080"},
+	})
+	assert.Equal(t, http.StatusOK, code)
+	code, data = client.do(http.MethodDelete, "/nacos/v1/ns/service", url.Values{
+		"serviceName": {"order@@nacos-svc"},
+	})
+	assert.Equal(t, http.StatusOK, code, string(data))
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+	code, _ = client.do(http.MethodGet, "/nacos/v1/ns/service", url.Values{
+		"serviceName": {"order@@nacos-svc"},
+	})
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestNacosV1_Namespace(t *testing.T) {
+	discoverSuit, client := newNacosV1ServerForTest(t)
+	t.Cleanup(func() {
+		discoverSuit.CleanNamespace("nacos-ns")
+	})
+
+	code, data := client.do(http.MethodPost, "/nacos/v1/console/namespaces", url.Values{
+		"customNamespaceId": {"nacos-ns"},
+		"namespaceName":     {"nacos-ns"},
+		"namespaceDesc":     {"created by nacos"},
+	})
+	assert.Equal(t, http.StatusOK, code, string(data))
+	assert.Equal(t, "true", string(data))
+
+	_, data = client.do(http.MethodGet, "/nacos/v1/console/namespaces", url.Values{
+		"checkNamespaceIdExist": {"true"},
+		"customNamespaceId":     {"nacos-ns"},
+	})
+	assert.Equal(t, "true", string(data))
+
+	code, data = client.do(http.MethodPut, "/nacos/v1/console/namespaces", url.Values{
+		"namespace":         {"nacos-ns"},
+		"namespaceShowName": {"nacos-ns"},
+		"namespaceDesc":     {"updated"},
+	})
+	assert.Equal(t, http.StatusOK, code, string(data))
+
+	code, data = client.do(http.MethodGet, "/nacos/v1/console/namespaces", url.Values{})
+	assert.Equal(t, http.StatusOK, code, string(data))
+	result := struct {
+		Code int                    `json:"code"`
+		Data []*model.NamespaceView `json:"data"`
+	}{}
+	assert.NoError(t, json.Unmarshal(data, &result))
+	assert.Equal(t, http.StatusOK, result.Code)
+	assert.Equal(t, "", result.Data[0].Namespace)
+	assert.Equal(t, "public", result.Data[0].NamespaceShowName)
+	var found *model.NamespaceView
+	for _, item := range result.Data {
+		if item.Namespace == "nacos-ns" {
+			found = item
+		}
+	}
+	assert.NotNil(t, found)
+	assert.Equal(t, "updated", found.NamespaceDesc)
+	assert.Equal(t, model.NamespaceTypeCustom, found.Type)
+
+	code, _ = client.do(http.MethodDelete, "/nacos/v1/console/namespaces", url.Values{
+		"namespaceId": {"public"},
+	})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, data = client.do(http.MethodDelete, "/nacos/v1/console/namespaces", url.Values{
+		"namespaceId": {"nacos-ns"},
+	})
+	assert.Equal(t, http.StatusOK, code, string(data))
+	code, _ = client.do(http.MethodGet, "/nacos/v1/console/namespaces", url.Values{
+		"show":        {"all"},
+		"namespaceId": {"nacos-ns"},
+	})
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestNacosV1_ConfigHistory(t *testing.T) {
+	discoverSuit, client := newNacosV1ServerForTest(t)
+
+	for _, content := range []string{"a=1", "a=2"} {
+		code, data := client.do(http.MethodPost, "/nacos/v1/cs/configs", url.Values{
+			"dataId":  {"nacos-history.properties"},
+			"group":   {"DEFAULT_GROUP"},
+			"content": {content},
+		})
+		assert.Equal(t, http.StatusOK, code, string(data))
+	}
+	// 名称包含关系的配置不会出现在历史中
+	code, data := client.do(http.MethodPost, "/nacos/v1/cs/configs", url.Values{
+		"dataId":  {"nacos-history.properties.bak"},
+		"group":   {"DEFAULT_GROUP"},
+		"content": {"a=3"},
+	})
+	assert.Equal(t, http.StatusOK, code, string(data))
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+
+	base := url.Values{
+		"dataId": {"nacos-history.properties"},
+		"group":  {"DEFAULT_GROUP"},
+	}
+	code, data = client.do(http.MethodGet, "/nacos/v1/cs/history", url.Values{
+		"search":   {"accurate"},
+		"dataId":   base["dataId"],
+		"group":    base["group"],
+		"pageNo":   {"1"},
+		"pageSize": {"10"},
+	})
+	assert.Equal(t, http.StatusOK, code, string(data))
+	page := &model.ConfigHistoryPage{}
+	assert.NoError(t, json.Unmarshal(data, page))
+	assert.Equal(t, 2, page.TotalCount)
+	assert.Equal(t, model.ConfigOpTypeUpdate, page.PageItems[0].OpType)
+	assert.Equal(t, model.ConfigOpTypeInsert, page.PageItems[1].OpType)
+	assert.Empty(t, page.PageItems[0].Content)
+
+	detailQuery := url.Values{"nid": {page.PageItems[0].Id}, "dataId": base["dataId"], "group": base["group"]}
+	_, data = client.do(http.MethodGet, "/nacos/v1/cs/history", detailQuery)
+	detail := &model.ConfigHistoryInfo{}
+	assert.NoError(t, json.Unmarshal(data, detail))
+	assert.Equal(t, "a=2", detail.Content)
+
+	previousQuery := url.Values{"id": {page.PageItems[0].Id}, "dataId": base["dataId"], "group": base["group"]}
+	_, data = client.do(http.MethodGet, "/nacos/v1/cs/history/previous", previousQuery)
+	previous := &model.ConfigHistoryInfo{}
+	assert.NoError(t, json.Unmarshal(data, previous))
+	assert.Equal(t, "a=1", previous.Content)
+
+	code, data = client.do(http.MethodGet, "/nacos/v1/cs/history/configs", url.Values{})
+	assert.Equal(t, http.StatusOK, code, string(data))
+	configs := []*model.ConfigInfoWrapper{}
+	assert.NoError(t, json.Unmarshal(data, &configs))
+	assert.GreaterOrEqual(t, len(configs), 2)
+}
+
+func TestNacosV1_ConfigBeta(t *testing.T) {
+	discoverSuit, client := newNacosV1ServerForTest(t)
+
+	base := url.Values{
+		"dataId": {"nacos-beta.properties"},
+		"group":  {"DEFAULT_GROUP"},
+	}
+	withValues := func(kvs ...string) url.Values {
+		ret := url.Values{"dataId": base["dataId"], "group": base["group"]}
+		for i := 0; i+1 < len(kvs); i += 2 {
+			ret.Set(kvs[i], kvs[i+1])
+		}
+		return ret
+	}
+
+	code, data := client.do(http.MethodPost, "/nacos/v1/cs/configs", withValues("content", "a=1"))
+	assert.Equal(t, http.StatusOK, code, string(data))
+	// 测试客户端的地址为 127.0.0.1，命中 beta 规则
+	code, data = client.do(http.MethodPost, "/nacos/v1/cs/configs",
+		withValues("content", "a=beta", model.ParamBetaIps, "127.0.0.1, 10.0.0.1"))
+	assert.Equal(t, http.StatusOK, code, string(data))
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+
+	code, data = client.do(http.MethodGet, "/nacos/v1/cs/configs", withValues())
+	assert.Equal(t, http.StatusOK, code, string(data))
+	assert.Equal(t, "a=beta", string(data))
+
+	code, data = client.do(http.MethodGet, "/nacos/v1/cs/configs", withValues(model.ParamBeta, "true"))
+	assert.Equal(t, http.StatusOK, code, string(data))
+	betaResult := &struct {
+		Code int                    `json:"code"`
+		Data *model.ConfigInfo4Beta `json:"data"`
+	}{}
+	assert.NoError(t, json.Unmarshal(data, betaResult))
+	assert.Equal(t, http.StatusOK, betaResult.Code)
+	assert.Equal(t, "a=beta", betaResult.Data.Content)
+	assert.Equal(t, "127.0.0.1,10.0.0.1", betaResult.Data.BetaIps)
+
+	// 重复 beta 发布会覆盖之前的灰度规则
+	code, data = client.do(http.MethodPost, "/nacos/v1/cs/configs",
+		withValues("content", "a=beta2", model.ParamBetaIps, "10.0.0.2"))
+	assert.Equal(t, http.StatusOK, code, string(data))
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+
+	_, data = client.do(http.MethodGet, "/nacos/v1/cs/configs", withValues())
+	assert.Equal(t, "a=1", string(data))
+
+	code, data = client.do(http.MethodDelete, "/nacos/v1/cs/configs", withValues(model.ParamBeta, "true"))
+	assert.Equal(t, http.StatusOK, code, string(data))
+	stopResult := &model.RestResult{}
+	assert.NoError(t, json.Unmarshal(data, stopResult))
+	assert.Equal(t, true, stopResult.Data)
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+
+	_, data = client.do(http.MethodGet, "/nacos/v1/cs/configs", withValues(model.ParamBeta, "true"))
+	betaResult.Data = nil
+	assert.NoError(t, json.Unmarshal(data, betaResult))
+	assert.Nil(t, betaResult.Data)
+
+	// tag 发布只对携带相同 tag 的客户端生效
+	code, data = client.do(http.MethodPost, "/nacos/v1/cs/configs",
+		withValues("content", "a=tag", model.ParamTag, "canary", "config_tags", "x,y"))
+	assert.Equal(t, http.StatusOK, code, string(data))
+	time.Sleep(discoverSuit.UpdateCacheInterval())
+
+	_, data = client.do(http.MethodGet, "/nacos/v1/cs/configs", withValues(model.ParamTag, "canary"))
+	assert.Equal(t, "a=tag", string(data))
+	_, data = client.do(http.MethodGet, "/nacos/v1/cs/configs", withValues())
+	assert.Equal(t, "a=1", string(data))
+}
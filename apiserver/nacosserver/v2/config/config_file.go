@@ -25,6 +25,7 @@ import (
 	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
 	"go.uber.org/zap"
 
+	"github.com/polarismesh/polaris/apiserver/nacosserver/core"
 	nacosmodel "github.com/polarismesh/polaris/apiserver/nacosserver/model"
 	nacospb "github.com/polarismesh/polaris/apiserver/nacosserver/v2/pb"
 	"github.com/polarismesh/polaris/apiserver/nacosserver/v2/remote"
@@ -62,7 +63,9 @@ func (h *ConfigServer) handlePublishConfigRequest(ctx context.Context, req nacos
 		})
 	}()
 
-	if configReq.CasMd5 != "" {
+	if configReq.IsGray() {
+		resp = core.PublishGrayConfig(ctx, h.cacheSvr, h.configSvr, configReq.ToSpec(), configReq.ToBetaLabels())
+	} else if configReq.CasMd5 != "" {
 		resp = h.configSvr.CasUpsertAndReleaseConfigFileFromClient(ctx, configReq.ToSpec())
 	} else {
 		resp = h.configSvr.UpsertAndReleaseConfigFileFromClient(ctx, configReq.ToSpec())
@@ -113,6 +116,20 @@ func (h *ConfigServer) handleGetConfigRequest(ctx context.Context, req nacospb.B
 	}()
 
 	queryReq := configReq.ToQuerySpec()
+	if configReq.Tag != "" {
+		if !core.HitTagGrayRelease(h.cacheSvr, queryReq.GetNamespace().GetValue(), configReq.Group,
+			configReq.DataId, configReq.Tag) {
+			rsp = &nacospb.ConfigQueryResponse{
+				Response: &nacospb.Response{
+					ResultCode: int(nacosmodel.Response_Fail.Code),
+					ErrorCode:  ErrorConfigNotFound,
+					Message:    "config data not exist",
+				},
+			}
+			return rsp, nil
+		}
+		queryReq.Tags = nacosmodel.BuildClientTags(utils.ParseClientIP(ctx), configReq.Tag)
+	}
 	queryResp := h.configSvr.GetConfigFileWithCache(ctx, queryReq)
 	if queryResp.GetCode().GetValue() != uint32(apimodel.Code_ExecuteSuccess) {
 		nacoslog.Error("[NACOS-V2][Config] query config file fail", zap.String("tenant", configReq.Tenant),
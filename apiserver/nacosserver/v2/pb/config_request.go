@@ -18,6 +18,7 @@ package nacos_grpc_service
 
 import (
 	"github.com/polarismesh/specification/source/go/api/v1/config_manage"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/polarismesh/polaris/apiserver/nacosserver/model"
@@ -172,7 +173,16 @@ func (c *ConfigPublishRequest) ToSpec() *config_manage.ConfigFilePublishInfo {
 		ret.Format = utils.NewStringValue(val)
 	}
 
+	if val, ok := c.AdditionMap["desc"]; ok {
+		ret.Comment = utils.NewStringValue(val)
+		ret.ReleaseDescription = utils.NewStringValue(val)
+	}
+
 	for k, v := range c.AdditionMap {
+		// betaIps 以及 tag 用于灰度发布，空值的参数不作为配置标签
+		if v == "" || k == model.ParamBetaIps || k == model.ParamTag {
+			continue
+		}
 		ret.Tags = append(ret.Tags, &config_manage.ConfigFileTag{
 			Key:   wrapperspb.String(k),
 			Value: wrapperspb.String(v),
@@ -182,6 +192,16 @@ func (c *ConfigPublishRequest) ToSpec() *config_manage.ConfigFilePublishInfo {
 	return ret
 }
 
+// IsGray 是否为 beta/tag 发布
+func (c *ConfigPublishRequest) IsGray() bool {
+	return c.AdditionMap[model.ParamBetaIps] != "" || c.AdditionMap[model.ParamTag] != ""
+}
+
+// ToBetaLabels 转为 polaris 灰度发布的客户端匹配规则
+func (c *ConfigPublishRequest) ToBetaLabels() []*apimodel.ClientLabel {
+	return model.BuildBetaLabels(c.AdditionMap[model.ParamBetaIps], c.AdditionMap[model.ParamTag])
+}
+
 func (c *ConfigPublishRequest) RequestMeta() interface{} {
 	return c
 }
@@ -0,0 +1,66 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package auth
+
+import "github.com/polarismesh/polaris/common/model"
+
+const (
+	// AccessTokenPrincipalUser 用户的个人访问令牌
+	AccessTokenPrincipalUser = "user"
+	// AccessTokenPrincipalGroup 用户组的个人访问令牌
+	AccessTokenPrincipalGroup = "group"
+)
+
+// AccessTokenRequest 创建个人访问令牌的请求
+type AccessTokenRequest struct {
+	// Name 令牌名称，同一个用户/用户组下不允许重复
+	Name string `json:"name"`
+	// PrincipalID 用户或者用户组 ID
+	PrincipalID string `json:"principal_id"`
+	// PrincipalType user 或者 group，默认为 user
+	PrincipalType string `json:"principal_type"`
+	// ExpiresIn 有效期，单位为秒
+	ExpiresIn int64 `json:"expires_in"`
+	// ReadOnly 是否只允许执行读操作
+	ReadOnly bool `json:"read_only"`
+	// Scope 允许访问的资源范围，为空时与用户/用户组的权限一致
+	Scope *model.AccessTokenScope `json:"scope,omitempty"`
+}
+
+// AccessTokenView 个人访问令牌的展示信息
+type AccessTokenView struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	PrincipalID   string `json:"principal_id"`
+	PrincipalType string `json:"principal_type"`
+	Owner         string `json:"owner"`
+	// Token 令牌原文，只在创建时返回
+	Token        string                  `json:"token,omitempty"`
+	Scope        *model.AccessTokenScope `json:"scope,omitempty"`
+	ReadOnly     bool                    `json:"read_only"`
+	ExpireTime   string                  `json:"expire_time"`
+	LastUsedTime string                  `json:"last_used_time"`
+	CreateTime   string                  `json:"create_time"`
+}
+
+// AccessTokenList 个人访问令牌的查询结果
+type AccessTokenList struct {
+	Amount uint32             `json:"amount"`
+	Size   uint32             `json:"size"`
+	Tokens []*AccessTokenView `json:"tokens"`
+}
@@ -68,6 +68,16 @@ type UserServer interface {
 	ResetUserToken(ctx context.Context, user *apisecurity.User) *apiservice.Response
 	// Login 登录动作
 	Login(req *apisecurity.LoginRequest) *apiservice.Response
+	// OIDCAuthorize 生成跳转至 OIDC 身份提供方的授权地址，以及需要通过 cookie 绑定浏览器的随机串
+	OIDCAuthorize(ctx context.Context) (*OIDCAuthorization, *apiservice.Response)
+	// OIDCLogin 使用 OIDC 授权码登录，binding 为浏览器 cookie 中的随机串，返回北极星用户的 token
+	OIDCLogin(ctx context.Context, code, state, binding string) *apiservice.Response
+	// CreateAccessToken 为用户或者用户组创建个人访问令牌，令牌原文只在创建时返回
+	CreateAccessToken(ctx context.Context, req *AccessTokenRequest) (*AccessTokenView, *apiservice.Response)
+	// GetAccessTokens 查询用户或者用户组的个人访问令牌
+	GetAccessTokens(ctx context.Context, query map[string]string) (*AccessTokenList, *apiservice.Response)
+	// RevokeAccessToken 吊销个人访问令牌
+	RevokeAccessToken(ctx context.Context, id string) *apiservice.Response
 	GroupOperator
 }
 
@@ -101,6 +111,8 @@ type StrategyServer interface {
 	CreateStrategy(ctx context.Context, strategy *apisecurity.AuthStrategy) *apiservice.Response
 	// UpdateStrategies 批量更新策略
 	UpdateStrategies(ctx context.Context, reqs []*apisecurity.ModifyAuthStrategy) *apiservice.BatchWriteResponse
+	// UpdateStrategyAction 修改策略的动作，拒绝策略优先于允许策略
+	UpdateStrategyAction(ctx context.Context, id string, action string) *apiservice.Response
 	// DeleteStrategies 删除策略
 	DeleteStrategies(ctx context.Context, reqs []*apisecurity.AuthStrategy) *apiservice.BatchWriteResponse
 	// GetStrategies 获取资源列表
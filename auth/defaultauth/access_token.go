@@ -0,0 +1,326 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package defaultauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/auth"
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/model"
+	authcommon "github.com/polarismesh/polaris/common/model/auth"
+	commonstore "github.com/polarismesh/polaris/common/store"
+	commontime "github.com/polarismesh/polaris/common/time"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+const (
+	// maxAccessTokensPerPrincipal 每个用户/用户组最多拥有的个人访问令牌数量
+	maxAccessTokensPerPrincipal = 50
+	// accessTokenUsageFlushInterval 令牌最后使用时间的落盘周期
+	accessTokenUsageFlushInterval = 30 * time.Second
+)
+
+// createAccessToken 生成个人访问令牌，格式与用户 token 一致，随机部分使用完整的 uuid
+func createAccessToken(id string) (string, error) {
+	token := fmt.Sprintf(TokenPattern, strings.ReplaceAll(uuid.NewString(), "-", ""),
+		fmt.Sprintf("%s/%s", model.TokenForAccessToken, id))
+	return encryptMessage([]byte(AuthOption.Salt), token)
+}
+
+// accessTokenSecret 计算令牌的摘要，存储层只保存摘要
+func accessTokenSecret(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAccessToken 为用户或者用户组创建个人访问令牌
+func (svr *Server) CreateAccessToken(ctx context.Context,
+	req *auth.AccessTokenRequest) (*auth.AccessTokenView, *apiservice.Response) {
+	requestID := utils.ParseRequestID(ctx)
+	if req == nil {
+		return nil, api.NewAuthResponse(apimodel.Code_EmptyRequest)
+	}
+	if err := checkName(utils.NewStringValue(req.Name)); err != nil {
+		return nil, api.NewAuthResponseWithMsg(apimodel.Code_InvalidParameter, "invalid token name: "+err.Error())
+	}
+	if req.ExpiresIn <= 0 {
+		return nil, api.NewAuthResponseWithMsg(apimodel.Code_InvalidParameter, "expires_in must be greater than 0")
+	}
+	if err := req.Scope.Verify(); err != nil {
+		return nil, api.NewAuthResponseWithMsg(apimodel.Code_InvalidParameter, err.Error())
+	}
+	principalType, errResp := parseAccessTokenPrincipalType(req.PrincipalType)
+	if errResp != nil {
+		return nil, errResp
+	}
+	owner, errResp := svr.checkAccessTokenPrincipal(ctx, req.PrincipalID, principalType)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	total, exists, err := svr.storage.GetAccessTokens(map[string]string{
+		"principal_id":   req.PrincipalID,
+		"principal_type": strconv.Itoa(int(principalType)),
+	}, 0, maxAccessTokensPerPrincipal)
+	if err != nil {
+		log.Error("[Auth][AccessToken] list access tokens", utils.ZapRequestID(requestID), zap.Error(err))
+		return nil, api.NewAuthResponse(commonstore.StoreCode2APICode(err))
+	}
+	if total >= maxAccessTokensPerPrincipal {
+		return nil, api.NewAuthResponseWithMsg(apimodel.Code_InvalidParameter,
+			fmt.Sprintf("a principal can have at most %d access tokens", maxAccessTokensPerPrincipal))
+	}
+	for _, item := range exists {
+		if item.Name == req.Name {
+			return nil, api.NewAuthResponse(apimodel.Code_ExistedResource)
+		}
+	}
+
+	data := &model.AccessToken{
+		ID:            utils.NewUUID(),
+		Name:          req.Name,
+		PrincipalID:   req.PrincipalID,
+		PrincipalType: principalType,
+		Owner:         owner,
+		ReadOnly:      req.ReadOnly,
+		ExpireTime:    time.Now().Add(time.Duration(req.ExpiresIn) * time.Second),
+	}
+	if !req.Scope.IsEmpty() {
+		data.Scope = req.Scope
+	}
+	token, err := createAccessToken(data.ID)
+	if err != nil {
+		log.Error("[Auth][AccessToken] create access token", utils.ZapRequestID(requestID), zap.Error(err))
+		return nil, api.NewAuthResponse(apimodel.Code_ExecuteException)
+	}
+	data.Secret = accessTokenSecret(token)
+
+	if err := svr.storage.AddAccessToken(data); err != nil {
+		log.Error("[Auth][AccessToken] save access token", utils.ZapRequestID(requestID), zap.Error(err))
+		return nil, api.NewAuthResponse(commonstore.StoreCode2APICode(err))
+	}
+	log.Info("[Auth][AccessToken] create access token", utils.ZapRequestID(requestID),
+		zap.String("id", data.ID), zap.String("principal", data.PrincipalID))
+	svr.RecordHistory(accessTokenRecordEntry(ctx, data, model.OCreate))
+
+	view := accessToken2View(data)
+	view.Token = token
+	return view, nil
+}
+
+// GetAccessTokens 查询用户或者用户组的个人访问令牌，不返回令牌原文
+func (svr *Server) GetAccessTokens(ctx context.Context,
+	query map[string]string) (*auth.AccessTokenList, *apiservice.Response) {
+	requestID := utils.ParseRequestID(ctx)
+	principalType, errResp := parseAccessTokenPrincipalType(query["principal_type"])
+	if errResp != nil {
+		return nil, errResp
+	}
+	principalID := query["principal_id"]
+	if _, errResp := svr.checkAccessTokenPrincipal(ctx, principalID, principalType); errResp != nil {
+		return nil, errResp
+	}
+	offset, limit, err := utils.ParseOffsetAndLimit(query)
+	if err != nil {
+		return nil, api.NewAuthResponse(apimodel.Code_InvalidParameter)
+	}
+
+	total, tokens, err := svr.storage.GetAccessTokens(map[string]string{
+		"principal_id":   principalID,
+		"principal_type": strconv.Itoa(int(principalType)),
+	}, offset, limit)
+	if err != nil {
+		log.Error("[Auth][AccessToken] list access tokens", utils.ZapRequestID(requestID), zap.Error(err))
+		return nil, api.NewAuthResponse(commonstore.StoreCode2APICode(err))
+	}
+
+	ret := &auth.AccessTokenList{
+		Amount: total,
+		Size:   uint32(len(tokens)),
+		Tokens: make([]*auth.AccessTokenView, 0, len(tokens)),
+	}
+	for _, token := range tokens {
+		ret.Tokens = append(ret.Tokens, accessToken2View(token))
+	}
+	return ret, nil
+}
+
+// RevokeAccessToken 吊销个人访问令牌
+func (svr *Server) RevokeAccessToken(ctx context.Context, id string) *apiservice.Response {
+	requestID := utils.ParseRequestID(ctx)
+	if id == "" {
+		return api.NewAuthResponse(apimodel.Code_InvalidParameter)
+	}
+	token, err := svr.storage.GetAccessToken(id)
+	if err != nil {
+		log.Error("[Auth][AccessToken] get access token", utils.ZapRequestID(requestID), zap.Error(err))
+		return api.NewAuthResponse(commonstore.StoreCode2APICode(err))
+	}
+	if token == nil {
+		return api.NewAuthResponse(apimodel.Code_NotFoundResource)
+	}
+	if _, errResp := svr.checkAccessTokenPrincipal(ctx, token.PrincipalID, token.PrincipalType); errResp != nil {
+		return errResp
+	}
+	if err := svr.storage.DeleteAccessToken(id); err != nil {
+		log.Error("[Auth][AccessToken] revoke access token", utils.ZapRequestID(requestID), zap.Error(err))
+		return api.NewAuthResponse(commonstore.StoreCode2APICode(err))
+	}
+	log.Info("[Auth][AccessToken] revoke access token", utils.ZapRequestID(requestID), zap.String("id", id))
+	svr.RecordHistory(accessTokenRecordEntry(ctx, token, model.ODelete))
+	return api.NewAuthResponse(apimodel.Code_ExecuteSuccess)
+}
+
+// checkAccessTokenPrincipal 检查是否可以管理该用户/用户组的个人访问令牌，返回用户/用户组所属的主账户
+//
+//	case 1. 用户：自己、所属的主账户以及超级账户
+//	case 2. 用户组：所属的主账户以及超级账户
+func (svr *Server) checkAccessTokenPrincipal(ctx context.Context, principalID string,
+	principalType model.PrincipalType) (string, *apiservice.Response) {
+	if principalID == "" {
+		return "", api.NewAuthResponseWithMsg(apimodel.Code_InvalidParameter, "principal_id is required")
+	}
+	if principalType == model.PrincipalUser {
+		user := svr.cacheMgn.User().GetUserByID(principalID)
+		if user == nil {
+			return "", api.NewAuthResponse(apimodel.Code_NotFoundUser)
+		}
+		if !checkUserViewPermission(ctx, user) {
+			return "", api.NewAuthResponse(apimodel.Code_NotAllowedAccess)
+		}
+		if user.Owner == "" {
+			return user.ID, nil
+		}
+		return user.Owner, nil
+	}
+
+	group := svr.cacheMgn.User().GetGroup(principalID)
+	if group == nil {
+		return "", api.NewAuthResponse(apimodel.Code_NotFoundUserGroup)
+	}
+	if authcommon.ParseUserRole(ctx) != model.AdminUserRole && group.Owner != utils.ParseUserID(ctx) {
+		return "", api.NewAuthResponse(apimodel.Code_NotAllowedAccess)
+	}
+	return group.Owner, nil
+}
+
+func parseAccessTokenPrincipalType(val string) (model.PrincipalType, *apiservice.Response) {
+	switch val {
+	case "", auth.AccessTokenPrincipalUser:
+		return model.PrincipalUser, nil
+	case auth.AccessTokenPrincipalGroup:
+		return model.PrincipalGroup, nil
+	default:
+		return 0, api.NewAuthResponseWithMsg(apimodel.Code_InvalidParameter,
+			"principal_type must be user or group")
+	}
+}
+
+func accessToken2View(token *model.AccessToken) *auth.AccessTokenView {
+	view := &auth.AccessTokenView{
+		ID:            token.ID,
+		Name:          token.Name,
+		PrincipalID:   token.PrincipalID,
+		PrincipalType: auth.AccessTokenPrincipalUser,
+		Owner:         token.Owner,
+		Scope:         token.Scope,
+		ReadOnly:      token.ReadOnly,
+		CreateTime:    commontime.Time2String(token.CreateTime),
+	}
+	if token.PrincipalType == model.PrincipalGroup {
+		view.PrincipalType = auth.AccessTokenPrincipalGroup
+	}
+	if !token.ExpireTime.IsZero() {
+		view.ExpireTime = commontime.Time2String(token.ExpireTime)
+	}
+	if !token.LastUsedTime.IsZero() {
+		view.LastUsedTime = commontime.Time2String(token.LastUsedTime)
+	}
+	return view
+}
+
+// accessTokenRecordEntry 生成个人访问令牌的操作记录，不记录令牌原文以及摘要
+func accessTokenRecordEntry(ctx context.Context, token *model.AccessToken,
+	operationType model.OperationType) *model.RecordEntry {
+	detail, _ := json.Marshal(accessToken2View(token))
+	return &model.RecordEntry{
+		ResourceType:  model.RAccessToken,
+		ResourceName:  fmt.Sprintf("%s(%s)", token.Name, token.ID),
+		OperationType: operationType,
+		Operator:      utils.ParseOperator(ctx),
+		Detail:        string(detail),
+		HappenTime:    time.Now(),
+	}
+}
+
+// accessTokenUsage 记录个人访问令牌的最后使用时间，周期性批量落盘，避免每次请求都写存储
+type accessTokenUsage struct {
+	storage  store.Store
+	lock     sync.Mutex
+	lastUsed map[string]time.Time
+}
+
+func newAccessTokenUsage(storage store.Store) *accessTokenUsage {
+	return &accessTokenUsage{
+		storage:  storage,
+		lastUsed: map[string]time.Time{},
+	}
+}
+
+func (u *accessTokenUsage) record(id string) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.lastUsed[id] = time.Now()
+}
+
+func (u *accessTokenUsage) run() {
+	ticker := time.NewTicker(accessTokenUsageFlushInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		u.flush()
+	}
+}
+
+func (u *accessTokenUsage) flush() {
+	u.lock.Lock()
+	lastUsed := u.lastUsed
+	u.lastUsed = map[string]time.Time{}
+	u.lock.Unlock()
+
+	if len(lastUsed) == 0 {
+		return
+	}
+	if err := u.storage.UpdateAccessTokensLastUsed(lastUsed); err != nil {
+		log.Error("[Auth][AccessToken] update access tokens last used time", zap.Error(err))
+	}
+}
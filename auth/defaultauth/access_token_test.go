@@ -0,0 +1,182 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package defaultauth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apisecurity "github.com/polarismesh/specification/source/go/api/v1/security"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/auth"
+	"github.com/polarismesh/polaris/auth/defaultauth"
+	"github.com/polarismesh/polaris/cache"
+	cachetypes "github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	storemock "github.com/polarismesh/polaris/store/mock"
+)
+
+func Test_DefaultAuthChecker_AccessToken(t *testing.T) {
+	reset(false)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	users := createMockUser(3)
+	groups := createMockUserGroup(users)
+
+	newToken := func(id string, principal string, principalType model.PrincipalType,
+		expire time.Time) (string, *model.AccessToken) {
+		token, secret, err := defaultauth.TestCreateAccessToken(id)
+		assert.NoError(t, err)
+		return token, &model.AccessToken{
+			ID:            id,
+			Name:          id,
+			PrincipalID:   principal,
+			PrincipalType: principalType,
+			Owner:         users[0].ID,
+			Secret:        secret,
+			ExpireTime:    expire,
+			Valid:         true,
+			ModifyTime:    time.Now(),
+		}
+	}
+
+	userToken, userPAT := newToken("pat-user", users[1].ID, model.PrincipalUser, time.Now().Add(time.Hour))
+	groupToken, groupPAT := newToken("pat-group", groups[1].ID, model.PrincipalGroup, time.Now().Add(time.Hour))
+	expiredToken, expiredPAT := newToken("pat-expired", users[1].ID, model.PrincipalUser, time.Now().Add(-time.Hour))
+	readOnlyToken, readOnlyPAT := newToken("pat-readonly", users[1].ID, model.PrincipalUser, time.Now().Add(time.Hour))
+	readOnlyPAT.ReadOnly = true
+	scopedToken, scopedPAT := newToken("pat-scoped", users[1].ID, model.PrincipalUser, time.Now().Add(time.Hour))
+	scopedPAT.Scope = &model.AccessTokenScope{Namespaces: []string{"default"}}
+
+	storage := storemock.NewMockStore(ctrl)
+	storage.EXPECT().GetServicesCount().AnyTimes().Return(uint32(1), nil)
+	storage.EXPECT().GetUnixSecond(gomock.Any()).AnyTimes().Return(time.Now().Unix(), nil)
+	storage.EXPECT().GetUsersForCache(gomock.Any(), gomock.Any()).AnyTimes().Return(users, nil)
+	storage.EXPECT().GetGroupsForCache(gomock.Any(), gomock.Any()).AnyTimes().Return(groups, nil)
+	storage.EXPECT().GetAccessTokensForCache(gomock.Any(), gomock.Any()).AnyTimes().
+		Return([]*model.AccessToken{userPAT, groupPAT, expiredPAT, readOnlyPAT, scopedPAT}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cacheMgn, err := cache.TestCacheInitialize(ctx, &cache.Config{}, storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = cacheMgn.OpenResourceCache([]cachetypes.ConfigEntry{
+		{
+			Name: cachetypes.UsersName,
+		},
+	}...)
+	_ = cacheMgn.TestUpdate()
+
+	t.Cleanup(func() {
+		cancel()
+		cacheMgn.Close()
+	})
+
+	checker := &defaultauth.DefaultAuthChecker{}
+	checker.Initialize(&auth.Config{
+		User: &auth.UserConfig{
+			Option: map[string]interface{}{},
+		},
+		Strategy: &auth.StrategyConfig{
+			Option: map[string]interface{}{},
+		},
+	}, nil, cacheMgn)
+	checker.SetCacheMgr(cacheMgn)
+
+	newAuthCtx := func(token string, op model.ResourceOperation) *model.AcquireContext {
+		ctx := context.WithValue(context.Background(), utils.ContextAuthTokenKey, token)
+		return model.NewAcquireContext(
+			model.WithRequestContext(ctx),
+			model.WithOperation(op),
+			model.WithModule(model.CoreModule),
+			model.WithMethod("Test_DefaultAuthChecker_AccessToken"),
+		)
+	}
+
+	t.Run("用户的个人访问令牌", func(t *testing.T) {
+		reset(true)
+		authCtx := newAuthCtx(userToken, model.Read)
+		assert.NoError(t, checker.VerifyCredential(authCtx))
+		assert.Equal(t, users[1].ID, utils.ParseUserID(authCtx.GetRequestContext()))
+		operator := authCtx.GetAttachment(model.TokenDetailInfoKey).(defaultauth.OperatorInfo)
+		assert.True(t, operator.IsUserToken)
+		assert.Equal(t, userPAT.ID, operator.AccessToken.ID)
+	})
+
+	t.Run("用户组的个人访问令牌", func(t *testing.T) {
+		reset(true)
+		authCtx := newAuthCtx(groupToken, model.Read)
+		assert.NoError(t, checker.VerifyCredential(authCtx))
+		assert.Equal(t, groups[1].ID, utils.ParseUserID(authCtx.GetRequestContext()))
+		operator := authCtx.GetAttachment(model.TokenDetailInfoKey).(defaultauth.OperatorInfo)
+		assert.False(t, operator.IsUserToken)
+	})
+
+	t.Run("过期的个人访问令牌", func(t *testing.T) {
+		reset(false)
+		authCtx := newAuthCtx(expiredToken, model.Read)
+		assert.ErrorIs(t, checker.VerifyCredential(authCtx), model.ErrorTokenExpired)
+	})
+
+	t.Run("摘要不匹配的个人访问令牌", func(t *testing.T) {
+		reset(true)
+		// 复用已存在的令牌 ID，但是令牌原文不同
+		forged, _, err := defaultauth.TestCreateAccessToken(userPAT.ID)
+		assert.NoError(t, err)
+		authCtx := newAuthCtx(forged, model.Read)
+		assert.ErrorIs(t, checker.VerifyCredential(authCtx), model.ErrorTokenNotExist)
+	})
+
+	t.Run("只读的个人访问令牌不允许写操作", func(t *testing.T) {
+		reset(true)
+		_, err := checker.CheckPermission(newAuthCtx(readOnlyToken, model.Modify))
+		assert.ErrorIs(t, err, model.ErrorTokenReadOnly)
+	})
+
+	t.Run("个人访问令牌不允许访问鉴权相关的读写接口", func(t *testing.T) {
+		reset(true)
+		for _, token := range []string{userToken, readOnlyToken, scopedToken} {
+			ctx := context.WithValue(context.Background(), utils.ContextAuthTokenKey, token)
+			for _, isWrite := range []bool{false, true} {
+				rsp := defaultauth.TestVerifyAuth(ctx, isWrite, checker)
+				assert.NotNil(t, rsp)
+				assert.Equal(t, uint32(apimodel.Code_OperationRoleForbidden), rsp.GetCode().GetValue())
+			}
+		}
+	})
+
+	t.Run("限制资源范围的个人访问令牌", func(t *testing.T) {
+		reset(true)
+		// 请求中没有可识别的资源时默认拒绝
+		_, err := checker.CheckPermission(newAuthCtx(scopedToken, model.Read))
+		assert.ErrorIs(t, err, model.ErrorTokenOutOfScope)
+
+		authCtx := newAuthCtx(scopedToken, model.Read)
+		authCtx.SetAccessResources(map[apisecurity.ResourceType][]model.ResourceEntry{
+			apisecurity.ResourceType_Namespaces: {{ID: "other"}},
+		})
+		_, err = checker.CheckPermission(authCtx)
+		assert.ErrorIs(t, err, model.ErrorTokenOutOfScope)
+	})
+}
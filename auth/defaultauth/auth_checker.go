@@ -19,12 +19,16 @@ package defaultauth
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	apisecurity "github.com/polarismesh/specification/source/go/api/v1/security"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/polarismesh/polaris/auth"
 	cachetypes "github.com/polarismesh/polaris/cache/api"
@@ -46,6 +50,10 @@ var (
 // DefaultAuthChecker 北极星自带的默认鉴权中心
 type DefaultAuthChecker struct {
 	cacheMgn cachetypes.CacheManager
+	// tokenUsage 记录个人访问令牌的最后使用时间
+	tokenUsage *accessTokenUsage
+	// strategySync 合并并发的策略缓存强制同步
+	strategySync singleflight.Group
 }
 
 func (d *DefaultAuthChecker) SetCacheMgr(mgr cachetypes.CacheManager) {
@@ -70,14 +78,14 @@ func (d *DefaultAuthChecker) Initialize(options *auth.Config, s store.Store, cac
 		if len(options.Option) > 0 {
 			log.Warn("auth.user.option or auth.strategy.option has set, auth.option will ignore")
 		}
-		strategyContentBytes, err = json.Marshal(options.Strategy.Option)
+		strategyContentBytes, err = json.Marshal(flatOption(options.Strategy.Option))
 		if err != nil {
 			return err
 		}
 		if err := json.Unmarshal(strategyContentBytes, cfg); err != nil {
 			return err
 		}
-		userContentBytes, err = json.Marshal(options.User.Option)
+		userContentBytes, err = json.Marshal(flatOption(options.User.Option))
 		if err != nil {
 			return err
 		}
@@ -104,6 +112,10 @@ func (d *DefaultAuthChecker) Initialize(options *auth.Config, s store.Store, cac
 	}
 	AuthOption = cfg
 	d.cacheMgn = cacheMgr
+	if s != nil {
+		d.tokenUsage = newAccessTokenUsage(s)
+		go d.tokenUsage.run()
+	}
 	return nil
 }
 
@@ -162,6 +174,9 @@ func (d *DefaultAuthChecker) checkMaintainPermission(preCtx *model.AcquireContex
 	if tokenInfo.Disable {
 		return false, model.ErrorTokenDisabled
 	}
+	if err := checkAccessTokenRestriction(tokenInfo.AccessToken); err != nil {
+		return false, err
+	}
 	if !tokenInfo.IsUserToken {
 		return false, errors.New("only user role can access maintain API")
 	}
@@ -176,7 +191,7 @@ func (d *DefaultAuthChecker) checkMaintainPermission(preCtx *model.AcquireContex
 //	step 1. 判断是否开启了鉴权
 //	step 2. 对token进行检查判断
 //		case 1. 如果 token 被禁用
-//				a. 读操作，直接放通
+//				a. 读操作，只受拒绝策略的限制
 //				b. 写操作，快速失败
 //	step 3. 拉取token对应的操作者相关信息，注入到请求上下文中
 //	step 4. 进行权限检查
@@ -185,15 +200,14 @@ func (d *DefaultAuthChecker) CheckPermission(authCtx *model.AcquireContext) (boo
 		return false, err
 	}
 
-	if authCtx.GetOperation() == model.Read {
-		return true, nil
-	}
-
 	operatorInfo := authCtx.GetAttachment(model.TokenDetailInfoKey).(OperatorInfo)
 	// 这里需要检查当 token 被禁止的情况，如果 token 被禁止，无论是否可以操作目标资源，都无法进行写操作
-	if operatorInfo.Disable {
+	if authCtx.GetOperation() != model.Read && operatorInfo.Disable {
 		return false, model.ErrorTokenDisabled
 	}
+	if err := d.checkAccessTokenScope(authCtx, operatorInfo.AccessToken); err != nil {
+		return false, err
+	}
 
 	log.Debug("[Auth][Checker] check permission args", utils.RequestID(authCtx.GetRequestContext()),
 		zap.String("method", authCtx.GetMethod()), zap.Any("resources", authCtx.GetAccessResources()))
@@ -203,8 +217,10 @@ func (d *DefaultAuthChecker) CheckPermission(authCtx *model.AcquireContext) (boo
 		return ok, nil
 	}
 
-	// 强制同步一次db中strategy数据到cache
-	if err = d.cacheMgn.AuthStrategy().ForceSync(); err != nil {
+	// 强制同步一次db中strategy数据到cache，并发的同步请求合并为一次
+	if _, err, _ = d.strategySync.Do("strategy", func() (interface{}, error) {
+		return nil, d.cacheMgn.AuthStrategy().ForceSync()
+	}); err != nil {
 		log.Error("[Auth][Checker] force sync strategy to cache failed",
 			utils.RequestID(authCtx.GetRequestContext()), zap.Error(err))
 		return false, err
@@ -338,6 +354,15 @@ func (d *DefaultAuthChecker) decodeToken(t string) (OperatorInfo, error) {
 		return OperatorInfo{}, model.ErrorTokenInvalid
 	}
 
+	if detail[0] == model.TokenForAccessToken {
+		// 个人访问令牌对应的用户/用户组在 checkToken 中根据令牌信息确定
+		return OperatorInfo{
+			Origin:        t,
+			AccessTokenID: detail[1],
+			Role:          model.UnknownUserRole,
+		}, nil
+	}
+
 	tokenInfo := OperatorInfo{
 		Origin:      t,
 		IsUserToken: detail[0] == model.TokenForUser,
@@ -354,6 +379,12 @@ func (d *DefaultAuthChecker) checkToken(tokenInfo *OperatorInfo) (string, bool,
 		return "", false, nil
 	}
 
+	if tokenInfo.AccessTokenID != "" {
+		if err := d.checkAccessToken(tokenInfo); err != nil {
+			return "", false, err
+		}
+	}
+
 	id := tokenInfo.OperatorID
 	if tokenInfo.IsUserToken {
 		user := d.Cache().User().GetUserByID(id)
@@ -361,7 +392,7 @@ func (d *DefaultAuthChecker) checkToken(tokenInfo *OperatorInfo) (string, bool,
 			return "", false, model.ErrorNoUser
 		}
 
-		if tokenInfo.Origin != user.Token {
+		if tokenInfo.AccessToken == nil && tokenInfo.Origin != user.Token {
 			return "", false, model.ErrorTokenNotExist
 		}
 
@@ -377,7 +408,7 @@ func (d *DefaultAuthChecker) checkToken(tokenInfo *OperatorInfo) (string, bool,
 		return "", false, model.ErrorNoUserGroup
 	}
 
-	if tokenInfo.Origin != group.Token {
+	if tokenInfo.AccessToken == nil && tokenInfo.Origin != group.Token {
 		return "", false, model.ErrorTokenNotExist
 	}
 
@@ -385,12 +416,104 @@ func (d *DefaultAuthChecker) checkToken(tokenInfo *OperatorInfo) (string, bool,
 	return group.Owner, false, nil
 }
 
-func (d *DefaultAuthChecker) isResourceEditable(
+// checkAccessToken 校验个人访问令牌，校验通过后以令牌所属的用户/用户组作为操作者
+func (d *DefaultAuthChecker) checkAccessToken(tokenInfo *OperatorInfo) error {
+	token := d.Cache().User().GetAccessToken(tokenInfo.AccessTokenID)
+	if token == nil {
+		return model.ErrorTokenNotExist
+	}
+	if subtle.ConstantTimeCompare([]byte(accessTokenSecret(tokenInfo.Origin)), []byte(token.Secret)) != 1 {
+		return model.ErrorTokenNotExist
+	}
+	if token.IsExpired(time.Now()) {
+		return model.ErrorTokenExpired
+	}
+	tokenInfo.AccessToken = token
+	tokenInfo.OperatorID = token.PrincipalID
+	tokenInfo.IsUserToken = token.PrincipalType == model.PrincipalUser
+	if d.tokenUsage != nil {
+		d.tokenUsage.record(token.ID)
+	}
+	return nil
+}
+
+// checkAccessTokenRestriction 存在只读或者资源范围限制的个人访问令牌不允许执行运维写操作
+func checkAccessTokenRestriction(token *model.AccessToken) error {
+	if token == nil {
+		return nil
+	}
+	if token.ReadOnly {
+		return model.ErrorTokenReadOnly
+	}
+	if !token.Scope.IsEmpty() {
+		return model.ErrorTokenOutOfScope
+	}
+	return nil
+}
+
+// checkAccessTokenScope 检查本次请求是否满足个人访问令牌的只读以及资源范围限制
+// 限制了资源范围的令牌默认拒绝，只有请求中能够识别出资源并且全部资源都在范围内时才放通，
+// 无法确定所属命名空间的资源一律拒绝
+func (d *DefaultAuthChecker) checkAccessTokenScope(authCtx *model.AcquireContext, token *model.AccessToken) error {
+	if token == nil {
+		return nil
+	}
+	if token.ReadOnly && authCtx.GetOperation() != model.Read {
+		return model.ErrorTokenReadOnly
+	}
+	if token.Scope.IsEmpty() {
+		return nil
+	}
+	var count int
+	for resourceType, entries := range authCtx.GetAccessResources() {
+		for _, entry := range entries {
+			count++
+			if !d.isInAccessTokenScope(token.Scope, resourceType, entry) {
+				log.Info("[Auth][Checker] resource out of access token scope",
+					utils.RequestID(authCtx.GetRequestContext()), zap.String("token", token.ID),
+					zap.String("type", resourceType.String()), zap.String("resource", entry.ID))
+				return model.ErrorTokenOutOfScope
+			}
+		}
+	}
+	if count == 0 {
+		log.Info("[Auth][Checker] request without resources is out of access token scope",
+			utils.RequestID(authCtx.GetRequestContext()), zap.String("token", token.ID),
+			zap.String("method", authCtx.GetMethod()))
+		return model.ErrorTokenOutOfScope
+	}
+	return nil
+}
+
+func (d *DefaultAuthChecker) isInAccessTokenScope(scope *model.AccessTokenScope,
+	resourceType apisecurity.ResourceType, entry model.ResourceEntry) bool {
+	switch resourceType {
+	case apisecurity.ResourceType_Namespaces:
+		return scope.AllowNamespace(entry.ID)
+	case apisecurity.ResourceType_Services:
+		svc := d.cacheMgn.Service().GetServiceByID(entry.ID)
+		return svc != nil && scope.AllowService(svc.Namespace, svc.Name)
+	case apisecurity.ResourceType_ConfigGroups:
+		id, err := strconv.ParseUint(entry.ID, 10, 64)
+		if err != nil {
+			return false
+		}
+		group := d.cacheMgn.ConfigGroup().GetGroupByID(id)
+		return group != nil && scope.AllowConfigGroup(group.Namespace, group.Name)
+	default:
+		return false
+	}
+}
+
+// isResourceOperable 检查资源是否可以执行本次请求的操作以及方法
+func (d *DefaultAuthChecker) isResourceOperable(
 	principal model.Principal,
 	resourceType apisecurity.ResourceType,
-	resEntries []model.ResourceEntry) bool {
+	resEntries []model.ResourceEntry,
+	authCtx *model.AcquireContext) bool {
 	for _, entry := range resEntries {
-		if !d.cacheMgn.AuthStrategy().IsResourceEditable(principal, resourceType, entry.ID) {
+		if !d.cacheMgn.AuthStrategy().IsResourceOperable(principal, resourceType, entry.ID,
+			authCtx.GetOperation(), authCtx.GetMethod()) {
 			return false
 		}
 	}
@@ -413,9 +536,9 @@ func (d *DefaultAuthChecker) doCheckPermission(authCtx *model.AcquireContext) (b
 		PrincipalID:   principleID,
 		PrincipalRole: principleType,
 	}
-	checkNamespace = d.isResourceEditable(p, apisecurity.ResourceType_Namespaces, nsResEntries)
-	checkSvc = d.isResourceEditable(p, apisecurity.ResourceType_Services, svcResEntries)
-	checkCfgGroup = d.isResourceEditable(p, apisecurity.ResourceType_ConfigGroups, cfgResEntries)
+	checkNamespace = d.isResourceOperable(p, apisecurity.ResourceType_Namespaces, nsResEntries, authCtx)
+	checkSvc = d.isResourceOperable(p, apisecurity.ResourceType_Services, svcResEntries, authCtx)
+	checkCfgGroup = d.isResourceOperable(p, apisecurity.ResourceType_ConfigGroups, cfgResEntries, authCtx)
 
 	checkAllResEntries := checkNamespace && checkSvc && checkCfgGroup
 
@@ -425,9 +548,3 @@ func (d *DefaultAuthChecker) doCheckPermission(authCtx *model.AcquireContext) (b
 	}
 	return checkAllResEntries, err
 }
-
-// checkAction 检查操作是否和策略匹配
-func (d *DefaultAuthChecker) checkAction(expect string, actual model.ResourceOperation, method string) bool {
-	// TODO 后续可针对读写操作进行鉴权, 并且可以针对具体的方法调用进行鉴权控制
-	return true
-}
@@ -52,6 +52,19 @@ func (cfg *AuthConfig) Verify() error {
 	return nil
 }
 
+// flatOption 过滤掉嵌套的配置项（例如 oidc），嵌套的配置项由对应的功能单独解析，不参与 AuthConfig 的解析
+func flatOption(option map[string]interface{}) map[string]interface{} {
+	ret := make(map[string]interface{}, len(option))
+	for k, v := range option {
+		switch v.(type) {
+		case map[interface{}]interface{}, map[string]interface{}:
+			continue
+		}
+		ret[k] = v
+	}
+	return ret
+}
+
 // DefaultAuthConfig 返回一个默认的鉴权配置
 func DefaultAuthConfig() *AuthConfig {
 	return &AuthConfig{
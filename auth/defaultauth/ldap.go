@@ -0,0 +1,553 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package defaultauth
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apisecurity "github.com/polarismesh/specification/source/go/api/v1/security"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"go.uber.org/zap"
+
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/ldap"
+	"github.com/polarismesh/polaris/common/model"
+	commonstore "github.com/polarismesh/polaris/common/store"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+const (
+	// ldapOptionKey auth.user.option 中 LDAP 目录集成的配置项
+	ldapOptionKey = "ldap"
+	// LDAPUserSource 由 LDAP 目录同步或者登录创建的用户的来源
+	LDAPUserSource = "LDAP"
+	// ldapPageSize 分页查询本地 LDAP 用户的大小
+	ldapPageSize = 100
+	// ldapSearchPageSize 分页查询目录的大小，需要小于目录服务的单次查询数量限制，Active Directory 默认为 1000
+	ldapSearchPageSize = 500
+)
+
+var errLDAPUserNotFound = errors.New("ldap user not found")
+
+// LDAPConfig LDAP 目录集成配置
+type LDAPConfig struct {
+	// Enable 是否开启 LDAP 目录集成
+	Enable bool `mapstructure:"enable"`
+	// URL 目录服务地址，ldap://host:389 或者 ldaps://host:636
+	URL string `mapstructure:"url"`
+	// StartTLS 使用 ldap:// 时是否通过 StartTLS 加密连接
+	StartTLS bool `mapstructure:"startTLS"`
+	// InsecureSkipVerify 是否跳过目录服务证书的校验
+	InsecureSkipVerify bool `mapstructure:"insecureSkipVerify"`
+	// BindDN 查询目录使用的服务账号，为空时匿名查询
+	BindDN string `mapstructure:"bindDN"`
+	// BindPassword 服务账号的密码
+	BindPassword string `mapstructure:"bindPassword"`
+	// UserBaseDN 查询用户的根节点
+	UserBaseDN string `mapstructure:"userBaseDN"`
+	// UserFilter 查询用户的过滤条件，%s 会被替换为用户名，不满足该条件的用户视为已被禁用
+	UserFilter string `mapstructure:"userFilter"`
+	// UsernameAttribute 作为北极星用户名的属性
+	UsernameAttribute string `mapstructure:"usernameAttribute"`
+	// EmailAttribute 邮箱属性
+	EmailAttribute string `mapstructure:"emailAttribute"`
+	// GroupBaseDN 查询用户组的根节点
+	GroupBaseDN string `mapstructure:"groupBaseDN"`
+	// GroupFilter 查询用户组的过滤条件
+	GroupFilter string `mapstructure:"groupFilter"`
+	// GroupNameAttribute 作为北极星用户组名称的属性
+	GroupNameAttribute string `mapstructure:"groupNameAttribute"`
+	// MemberAttribute 用户组成员属性
+	MemberAttribute string `mapstructure:"memberAttribute"`
+	// MemberIsUsername 成员属性的值是否为用户名（例如 posixGroup 的 memberUid），默认为用户 DN
+	MemberIsUsername bool `mapstructure:"memberIsUsername"`
+	// Groups 需要同步的用户组名称，为空时同步所有满足 GroupFilter 的用户组
+	Groups []string `mapstructure:"groups"`
+	// Owner 目录用户所属的主账户名称，默认为 polaris
+	Owner string `mapstructure:"owner"`
+	// SyncInterval 同步用户组以及吊销失效用户 token 的周期
+	SyncInterval time.Duration `mapstructure:"syncInterval"`
+	// Timeout 访问目录服务的超时时间
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// shouldSyncGroup 判断目录用户组是否需要同步
+func (c *LDAPConfig) shouldSyncGroup(name string) bool {
+	if len(c.Groups) == 0 {
+		return true
+	}
+	for _, group := range c.Groups {
+		if group == name {
+			return true
+		}
+	}
+	return false
+}
+
+type ldapDirectory struct {
+	cfg *LDAPConfig
+}
+
+// parseLDAPConfig 解析 LDAP 配置
+func parseLDAPConfig(raw interface{}) (*LDAPConfig, error) {
+	cfg := &LDAPConfig{
+		UserFilter:         "(&(objectClass=person)(uid=%s))",
+		UsernameAttribute:  "uid",
+		EmailAttribute:     "mail",
+		GroupFilter:        "(objectClass=groupOfNames)",
+		GroupNameAttribute: "cn",
+		MemberAttribute:    "member",
+		Owner:              "polaris",
+		SyncInterval:       5 * time.Minute,
+		Timeout:            10 * time.Second,
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     cfg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(raw); err != nil {
+		return nil, err
+	}
+	if cfg.Enable {
+		if cfg.URL == "" || cfg.UserBaseDN == "" {
+			return nil, errors.New("ldap url and userBaseDN are required")
+		}
+		if strings.Count(cfg.UserFilter, "%s") != 1 {
+			return nil, errors.New("ldap userFilter must contain exactly one %s")
+		}
+		if cfg.SyncInterval <= 0 {
+			return nil, fmt.Errorf("ldap syncInterval must be positive, got %s", cfg.SyncInterval)
+		}
+	}
+	return cfg, nil
+}
+
+// initLDAP 根据 auth.user.option.ldap 初始化 LDAP 目录集成，并启动用户组的周期同步
+func (svr *Server) initLDAP(option map[string]interface{}) error {
+	raw, ok := option[ldapOptionKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	cfg, err := parseLDAPConfig(raw)
+	if err != nil {
+		return fmt.Errorf("[Auth][LDAP] parse config: %w", err)
+	}
+	if !cfg.Enable {
+		return nil
+	}
+	svr.ldap = &ldapDirectory{cfg: cfg}
+	if err := svr.storage.StartLeaderElection(store.ElectionKeyLDAPSync); err != nil {
+		return fmt.Errorf("[Auth][LDAP] start leader election: %w", err)
+	}
+	go svr.runLDAPSync()
+	log.Info("[Auth][LDAP] ldap directory enabled", zap.String("url", cfg.URL))
+	return nil
+}
+
+func (svr *Server) runLDAPSync() {
+	ticker := time.NewTicker(svr.ldap.cfg.SyncInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if !svr.storage.IsLeader(store.ElectionKeyLDAPSync) {
+			continue
+		}
+		if err := svr.syncLDAP(context.Background()); err != nil {
+			log.Error("[Auth][LDAP] sync directory", zap.Error(err))
+		}
+	}
+}
+
+// dial 建立目录连接，并使用服务账号进行绑定
+func (d *ldapDirectory) dial() (*ldap.Conn, error) {
+	conn, err := ldap.Dial(ldap.Config{
+		URL:       d.cfg.URL,
+		StartTLS:  d.cfg.StartTLS,
+		TLSConfig: &tls.Config{InsecureSkipVerify: d.cfg.InsecureSkipVerify},
+		Timeout:   d.cfg.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if d.cfg.BindDN != "" {
+		if err := conn.Bind(d.cfg.BindDN, d.cfg.BindPassword); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("bind service account: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// authenticate 查询用户条目并使用用户的 DN 以及密码进行绑定
+func (d *ldapDirectory) authenticate(username, password string) (*ldap.Entry, error) {
+	conn, err := d.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entries, err := conn.Search(&ldap.SearchRequest{
+		BaseDN:     d.cfg.UserBaseDN,
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     fmt.Sprintf(d.cfg.UserFilter, ldap.EscapeFilter(username)),
+		Attributes: []string{d.cfg.UsernameAttribute, d.cfg.EmailAttribute},
+		SizeLimit:  2,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) != 1 {
+		return nil, errLDAPUserNotFound
+	}
+	if err := conn.Bind(entries[0].DN, password); err != nil {
+		return nil, err
+	}
+	return entries[0], nil
+}
+
+// ldapLogin 通过目录服务校验密码，首次登录时自动创建子账户
+func (svr *Server) ldapLogin(req *apisecurity.LoginRequest, user *model.User) *apiservice.Response {
+	cfg := svr.ldap.cfg
+	username := req.GetName().GetValue()
+	if owner := req.GetOwner().GetValue(); owner != "" && owner != cfg.Owner {
+		return api.NewAuthResponse(apimodel.Code_NotFoundUser)
+	}
+
+	entry, err := svr.ldap.authenticate(username, req.GetPassword().GetValue())
+	if err != nil {
+		if errors.Is(err, errLDAPUserNotFound) || ldap.IsInvalidCredentials(err) {
+			return api.NewAuthResponseWithMsg(
+				apimodel.Code_NotAllowedAccess, model.ErrorWrongUsernameOrPassword.Error())
+		}
+		log.Error("[Auth][LDAP] authenticate user", zap.String("name", username), zap.Error(err))
+		return api.NewAuthResponseWithMsg(apimodel.Code_ExecuteException, err.Error())
+	}
+	if user != nil {
+		return loginResponse(user)
+	}
+
+	owner, errResp := svr.getLDAPOwner()
+	if errResp != nil {
+		return errResp
+	}
+	if name := entry.Value(cfg.UsernameAttribute); name != "" {
+		username = name
+	}
+	if user, err = svr.storage.GetUserByName(username, owner.ID); err != nil {
+		log.Error("[Auth][LDAP] get user by name", zap.String("name", username), zap.Error(err))
+		return api.NewAuthResponse(commonstore.StoreCode2APICode(err))
+	}
+	if user != nil && user.Source != LDAPUserSource {
+		return api.NewAuthResponseWithMsg(apimodel.Code_NotAllowedAccess,
+			fmt.Sprintf("user %s exists and is not managed by ldap", username))
+	}
+	if user == nil {
+		if err := checkName(utils.NewStringValue(username)); err != nil {
+			return api.NewAuthResponseWithMsg(apimodel.Code_InvalidUserName, err.Error())
+		}
+		user, err = svr.createExternalUser(context.Background(), owner, username,
+			entry.Value(cfg.EmailAttribute), LDAPUserSource)
+		if err != nil {
+			log.Error("[Auth][LDAP] create user", zap.String("name", username), zap.Error(err))
+			return api.NewAuthResponse(commonstore.StoreCode2APICode(err))
+		}
+	}
+	return loginResponse(user)
+}
+
+func (svr *Server) getLDAPOwner() (*model.User, *apiservice.Response) {
+	owner, err := svr.storage.GetUserByName(svr.ldap.cfg.Owner, "")
+	if err != nil {
+		log.Error("[Auth][LDAP] get owner user", zap.Error(err))
+		return nil, api.NewAuthResponse(commonstore.StoreCode2APICode(err))
+	}
+	if owner == nil || owner.Type != model.OwnerUserRole {
+		return nil, api.NewAuthResponseWithMsg(apimodel.Code_NotFoundOwnerUser,
+			fmt.Sprintf("ldap owner %s not found", svr.ldap.cfg.Owner))
+	}
+	return owner, nil
+}
+
+// syncLDAP 将选定的目录用户组同步为北极星用户组，并吊销目录中已删除或者已禁用的用户的 token
+func (svr *Server) syncLDAP(ctx context.Context) error {
+	cfg := svr.ldap.cfg
+	owner, errResp := svr.getLDAPOwner()
+	if errResp != nil {
+		return errors.New(errResp.GetInfo().GetValue())
+	}
+
+	conn, err := svr.ldap.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	dirUsers, err := conn.Search(&ldap.SearchRequest{
+		BaseDN:     cfg.UserBaseDN,
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     strings.ReplaceAll(cfg.UserFilter, "%s", "*"),
+		Attributes: []string{cfg.UsernameAttribute, cfg.EmailAttribute},
+		PageSize:   ldapSearchPageSize,
+	})
+	if err != nil {
+		return fmt.Errorf("search users: %w", err)
+	}
+	var dirGroups []*ldap.Entry
+	if cfg.GroupBaseDN != "" {
+		dirGroups, err = conn.Search(&ldap.SearchRequest{
+			BaseDN:     cfg.GroupBaseDN,
+			Scope:      ldap.ScopeWholeSubtree,
+			Filter:     cfg.GroupFilter,
+			Attributes: []string{cfg.GroupNameAttribute, cfg.MemberAttribute},
+			PageSize:   ldapSearchPageSize,
+		})
+		if err != nil {
+			return fmt.Errorf("search groups: %w", err)
+		}
+	}
+
+	dnToName := map[string]string{}
+	activeUsers := map[string]*ldap.Entry{}
+	for _, entry := range dirUsers {
+		name := entry.Value(cfg.UsernameAttribute)
+		if name == "" {
+			continue
+		}
+		dnToName[strings.ToLower(entry.DN)] = name
+		activeUsers[name] = entry
+	}
+
+	localUsers, err := svr.listLDAPUsers(owner)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(dirGroups, func(i, j int) bool {
+		return dirGroups[i].Value(cfg.GroupNameAttribute) < dirGroups[j].Value(cfg.GroupNameAttribute)
+	})
+	for _, dirGroup := range dirGroups {
+		name := dirGroup.Value(cfg.GroupNameAttribute)
+		if name == "" || !cfg.shouldSyncGroup(name) {
+			continue
+		}
+		memberIds := map[string]struct{}{}
+		for _, member := range dirGroup.Values(cfg.MemberAttribute) {
+			username := member
+			if !cfg.MemberIsUsername {
+				username = dnToName[strings.ToLower(member)]
+			}
+			entry, ok := activeUsers[username]
+			if !ok {
+				continue
+			}
+			user, err := svr.ensureLDAPUser(ctx, owner, localUsers, username, entry.Value(cfg.EmailAttribute))
+			if err != nil {
+				return err
+			}
+			if user != nil {
+				memberIds[user.ID] = struct{}{}
+			}
+		}
+		if err := svr.syncLDAPGroup(ctx, owner, name, memberIds, localUsers); err != nil {
+			return err
+		}
+	}
+
+	// 目录中没有查询到任何用户时不做吊销，避免配置错误导致所有用户的 token 被吊销
+	if len(activeUsers) == 0 {
+		log.Warn("[Auth][LDAP] no active user found in directory, skip token revocation")
+		return nil
+	}
+	for name, user := range localUsers {
+		if _, ok := activeUsers[name]; ok {
+			continue
+		}
+		if err := svr.revokeLDAPUserToken(ctx, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listLDAPUsers 查询主账户下所有 LDAP 来源的子账户
+func (svr *Server) listLDAPUsers(owner *model.User) (map[string]*model.User, error) {
+	ret := map[string]*model.User{}
+	for offset := uint32(0); ; offset += ldapPageSize {
+		total, users, err := svr.storage.GetUsers(map[string]string{
+			"owner":  owner.ID,
+			"source": LDAPUserSource,
+		}, offset, ldapPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			if user.Owner == owner.ID && user.Source == LDAPUserSource {
+				ret[user.Name] = user
+			}
+		}
+		if len(users) == 0 || offset+ldapPageSize >= total {
+			return ret, nil
+		}
+	}
+}
+
+// ensureLDAPUser 获取目录用户对应的子账户，不存在时创建，同名的非 LDAP 来源子账户不参与同步
+func (svr *Server) ensureLDAPUser(ctx context.Context, owner *model.User, localUsers map[string]*model.User,
+	username, email string) (*model.User, error) {
+	if user, ok := localUsers[username]; ok {
+		return user, nil
+	}
+	if err := checkName(utils.NewStringValue(username)); err != nil {
+		log.Warn("[Auth][LDAP] skip invalid username", zap.String("name", username), zap.Error(err))
+		return nil, nil
+	}
+	user, err := svr.storage.GetUserByName(username, owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		log.Warn("[Auth][LDAP] local user with same name exists, skip", zap.String("name", username))
+		return nil, nil
+	}
+	if user, err = svr.createExternalUser(ctx, owner, username, email, LDAPUserSource); err != nil {
+		return nil, err
+	}
+	localUsers[username] = user
+	return user, nil
+}
+
+// syncLDAPGroup 同步一个用户组的成员，只会移出 LDAP 来源的用户，手工加入的本地用户保持不变
+func (svr *Server) syncLDAPGroup(ctx context.Context, owner *model.User, name string,
+	memberIds map[string]struct{}, localUsers map[string]*model.User) error {
+	group, err := svr.storage.GetGroupByName(name, owner.ID)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		detail, err := createGroupModel(&apisecurity.UserGroup{
+			Name:    utils.NewStringValue(name),
+			Owner:   utils.NewStringValue(owner.ID),
+			Comment: utils.NewStringValue("synced from ldap"),
+		})
+		if err != nil {
+			return err
+		}
+		detail.UserIds = memberIds
+		if err := svr.storage.AddGroup(detail); err != nil {
+			return err
+		}
+		log.Info("[Auth][LDAP] create user group", zap.String("group", name), zap.Int("members", len(memberIds)))
+		svr.RecordHistory(userGroupRecordEntry(ctx, userGroup2Api(detail.UserGroup), detail.UserGroup,
+			model.OCreate))
+		return nil
+	}
+
+	detail, err := svr.storage.GetGroup(group.ID)
+	if err != nil {
+		return err
+	}
+	if detail == nil {
+		return nil
+	}
+	ldapUserIds := map[string]struct{}{}
+	for _, user := range localUsers {
+		ldapUserIds[user.ID] = struct{}{}
+	}
+	modify := &model.ModifyUserGroup{
+		ID:          group.ID,
+		Owner:       group.Owner,
+		Token:       group.Token,
+		TokenEnable: group.TokenEnable,
+		Comment:     group.Comment,
+	}
+	for id := range memberIds {
+		if _, ok := detail.UserIds[id]; !ok {
+			modify.AddUserIds = append(modify.AddUserIds, id)
+		}
+	}
+	for id := range detail.UserIds {
+		_, isLDAPUser := ldapUserIds[id]
+		if _, ok := memberIds[id]; !ok && isLDAPUser {
+			modify.RemoveUserIds = append(modify.RemoveUserIds, id)
+		}
+	}
+	if len(modify.AddUserIds) == 0 && len(modify.RemoveUserIds) == 0 {
+		return nil
+	}
+	sort.Strings(modify.AddUserIds)
+	sort.Strings(modify.RemoveUserIds)
+	if err := svr.storage.UpdateGroup(modify); err != nil {
+		return err
+	}
+	log.Info("[Auth][LDAP] sync user group", zap.String("group", name),
+		zap.Strings("add", modify.AddUserIds), zap.Strings("remove", modify.RemoveUserIds))
+	return nil
+}
+
+// revokeLDAPUserToken 吊销目录中已删除或者已禁用的用户的 token，重置后禁用，需要管理员手工重新启用，
+// 同时吊销该用户的全部个人访问令牌
+func (svr *Server) revokeLDAPUserToken(ctx context.Context, user *model.User) error {
+	if user.TokenEnable {
+		token, err := createUserToken(user.ID)
+		if err != nil {
+			return err
+		}
+		user.Token = token
+		user.TokenEnable = false
+		if err := svr.storage.UpdateUser(user); err != nil {
+			return err
+		}
+		log.Info("[Auth][LDAP] revoke user token", zap.String("name", user.Name))
+		svr.RecordHistory(userRecordEntry(ctx, user2Api(user), user, model.OUpdateToken))
+	}
+
+	var tokens []*model.AccessToken
+	for offset := uint32(0); ; offset += ldapPageSize {
+		total, items, err := svr.storage.GetAccessTokens(map[string]string{
+			"principal_id":   user.ID,
+			"principal_type": strconv.Itoa(int(model.PrincipalUser)),
+		}, offset, ldapPageSize)
+		if err != nil {
+			return err
+		}
+		tokens = append(tokens, items...)
+		if len(items) == 0 || offset+ldapPageSize >= total {
+			break
+		}
+	}
+	for _, token := range tokens {
+		if err := svr.storage.DeleteAccessToken(token.ID); err != nil {
+			return err
+		}
+		log.Info("[Auth][LDAP] revoke access token", zap.String("name", user.Name), zap.String("id", token.ID))
+		svr.RecordHistory(accessTokenRecordEntry(ctx, token, model.ODelete))
+	}
+	return nil
+}
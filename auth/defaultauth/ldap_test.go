@@ -0,0 +1,206 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package defaultauth_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	apisecurity "github.com/polarismesh/specification/source/go/api/v1/security"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/auth/defaultauth"
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/ldap/ldaptest"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	storemock "github.com/polarismesh/polaris/store/mock"
+)
+
+func newLDAPTestServer(t *testing.T) *ldaptest.Server {
+	srv, err := ldaptest.NewServer()
+	assert.NoError(t, err)
+	srv.AddEntry("cn=admin,dc=example,dc=com", map[string][]string{
+		"objectClass": {"person"}, "userPassword": {"admin-pwd"},
+	})
+	srv.AddEntry("uid=alice,ou=people,dc=example,dc=com", map[string][]string{
+		"objectClass": {"person"}, "uid": {"alice"}, "mail": {"alice@example.com"}, "userPassword": {"alice-pwd"},
+	})
+	srv.AddEntry("uid=bob,ou=people,dc=example,dc=com", map[string][]string{
+		"objectClass": {"person"}, "uid": {"bob"}, "userPassword": {"bob-pwd"},
+	})
+	srv.AddEntry("cn=dev,ou=groups,dc=example,dc=com", map[string][]string{
+		"objectClass": {"groupOfNames"}, "cn": {"dev"},
+		"member": {"uid=alice,ou=people,dc=example,dc=com", "uid=bob,ou=people,dc=example,dc=com"},
+	})
+	srv.AddEntry("cn=ops,ou=groups,dc=example,dc=com", map[string][]string{
+		"objectClass": {"groupOfNames"}, "cn": {"ops"}, "member": {"uid=bob,ou=people,dc=example,dc=com"},
+	})
+	return srv
+}
+
+func newLDAPServer(t *testing.T, storage *storemock.MockStore, url string) *defaultauth.Server {
+	svr := defaultauth.NewServer(storage, nil, nil, nil)
+	assert.NoError(t, defaultauth.TestInitLDAP(svr, map[string]interface{}{
+		"ldap": map[interface{}]interface{}{
+			"enable":       true,
+			"url":          url,
+			"bindDN":       "cn=admin,dc=example,dc=com",
+			"bindPassword": "admin-pwd",
+			"userBaseDN":   "ou=people,dc=example,dc=com",
+			"groupBaseDN":  "ou=groups,dc=example,dc=com",
+			"groups":       []interface{}{"dev"},
+		},
+	}))
+	return svr
+}
+
+func Test_server_LDAPLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	directory := newLDAPTestServer(t)
+	defer directory.Close()
+
+	storage := storemock.NewMockStore(ctrl)
+	svr := newLDAPServer(t, storage, directory.URL())
+	owner := &model.User{ID: "owner-id", Name: "polaris", Type: model.OwnerUserRole}
+	storage.EXPECT().GetUserByName("polaris", "").AnyTimes().Return(owner, nil)
+
+	loginReq := func(name, password string) *apisecurity.LoginRequest {
+		return &apisecurity.LoginRequest{
+			Name:     utils.NewStringValue(name),
+			Password: utils.NewStringValue(password),
+		}
+	}
+
+	t.Run("首次登录自动创建子账户", func(t *testing.T) {
+		var created *model.User
+		storage.EXPECT().GetUserByName("alice", owner.ID).Return(nil, nil)
+		storage.EXPECT().AddUser(gomock.Any()).DoAndReturn(func(user *model.User) error {
+			created = user
+			return nil
+		})
+
+		resp := defaultauth.TestLDAPLogin(svr, loginReq("alice", "alice-pwd"), nil)
+		assert.Equal(t, api.ExecuteSuccess, resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+		assert.Equal(t, model.SubAccountUserRole, created.Type)
+		assert.Equal(t, owner.ID, created.Owner)
+		assert.Equal(t, defaultauth.LDAPUserSource, created.Source)
+		assert.Equal(t, "alice@example.com", created.Email)
+		assert.Equal(t, created.Token, resp.GetLoginResponse().GetToken().GetValue())
+	})
+
+	t.Run("已同步的用户登录", func(t *testing.T) {
+		alice := &model.User{ID: "alice-id", Name: "alice", Owner: owner.ID, Source: defaultauth.LDAPUserSource,
+			Type: model.SubAccountUserRole, Token: "alice-token"}
+		resp := defaultauth.TestLDAPLogin(svr, loginReq("alice", "alice-pwd"), alice)
+		assert.Equal(t, api.ExecuteSuccess, resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+		assert.Equal(t, "alice-token", resp.GetLoginResponse().GetToken().GetValue())
+	})
+
+	t.Run("密码错误", func(t *testing.T) {
+		resp := defaultauth.TestLDAPLogin(svr, loginReq("alice", "wrong"), nil)
+		assert.Equal(t, api.NotAllowedAccess, resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+		resp = defaultauth.TestLDAPLogin(svr, loginReq("alice", ""), nil)
+		assert.Equal(t, api.NotAllowedAccess, resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+	})
+
+	t.Run("目录中不存在的用户", func(t *testing.T) {
+		resp := defaultauth.TestLDAPLogin(svr, loginReq("carol", "carol-pwd"), nil)
+		assert.Equal(t, api.NotAllowedAccess, resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+		resp = defaultauth.TestLDAPLogin(svr, loginReq("*", "alice-pwd"), nil)
+		assert.Equal(t, api.NotAllowedAccess, resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+	})
+
+	t.Run("同名的本地账户不允许登录", func(t *testing.T) {
+		storage.EXPECT().GetUserByName("bob", owner.ID).Return(&model.User{
+			ID: "bob-id", Name: "bob", Owner: owner.ID, Source: "Polaris"}, nil)
+		resp := defaultauth.TestLDAPLogin(svr, loginReq("bob", "bob-pwd"), nil)
+		assert.Equal(t, api.NotAllowedAccess, resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+	})
+}
+
+func Test_server_SyncLDAP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	directory := newLDAPTestServer(t)
+	defer directory.Close()
+	// 目录服务限制单次查询的数量，同步时需要分页查询
+	directory.SetSizeLimit(1)
+
+	storage := storemock.NewMockStore(ctrl)
+	svr := newLDAPServer(t, storage, directory.URL())
+	owner := &model.User{ID: "owner-id", Name: "polaris", Type: model.OwnerUserRole}
+	storage.EXPECT().GetUserByName("polaris", "").AnyTimes().Return(owner, nil)
+
+	alice := &model.User{ID: "alice-id", Name: "alice", Owner: owner.ID, Source: defaultauth.LDAPUserSource,
+		Type: model.SubAccountUserRole, Token: "alice-token", TokenEnable: true}
+	carol := &model.User{ID: "carol-id", Name: "carol", Owner: owner.ID, Source: defaultauth.LDAPUserSource,
+		Type: model.SubAccountUserRole, Token: "carol-token", TokenEnable: true}
+	storage.EXPECT().GetUsers(map[string]string{"owner": owner.ID, "source": defaultauth.LDAPUserSource},
+		uint32(0), uint32(100)).Return(uint32(2), []*model.User{alice, carol}, nil)
+
+	t.Run("同步用户组并吊销目录中已删除的用户", func(t *testing.T) {
+		var bob *model.User
+		storage.EXPECT().GetUserByName("bob", owner.ID).Return(nil, nil)
+		storage.EXPECT().AddUser(gomock.Any()).DoAndReturn(func(user *model.User) error {
+			bob = user
+			return nil
+		})
+		dev := &model.UserGroup{ID: "dev-id", Name: "dev", Owner: owner.ID, Token: "dev-token", TokenEnable: true}
+		storage.EXPECT().GetGroupByName("dev", owner.ID).Return(dev, nil)
+		storage.EXPECT().GetGroup(dev.ID).Return(&model.UserGroupDetail{UserGroup: dev, UserIds: map[string]struct{}{
+			carol.ID: {}, "local-id": {},
+		}}, nil)
+		storage.EXPECT().UpdateGroup(gomock.Any()).DoAndReturn(func(group *model.ModifyUserGroup) error {
+			assert.Equal(t, dev.ID, group.ID)
+			assert.Equal(t, dev.Token, group.Token)
+			assert.ElementsMatch(t, []string{alice.ID, bob.ID}, group.AddUserIds)
+			assert.Equal(t, []string{carol.ID}, group.RemoveUserIds)
+			return nil
+		})
+		storage.EXPECT().UpdateUser(gomock.Any()).DoAndReturn(func(user *model.User) error {
+			assert.Equal(t, carol.ID, user.ID)
+			assert.False(t, user.TokenEnable)
+			assert.NotEqual(t, "carol-token", user.Token)
+			return nil
+		})
+		storage.EXPECT().GetAccessTokens(map[string]string{"principal_id": carol.ID, "principal_type": "1"},
+			uint32(0), uint32(100)).Return(uint32(1), []*model.AccessToken{{ID: "pat-id", PrincipalID: carol.ID,
+			PrincipalType: model.PrincipalUser}}, nil)
+		storage.EXPECT().DeleteAccessToken("pat-id").Return(nil)
+
+		assert.NoError(t, defaultauth.TestSyncLDAP(svr))
+		assert.Equal(t, defaultauth.LDAPUserSource, bob.Source)
+	})
+}
+
+func Test_server_LDAPConfig(t *testing.T) {
+	svr := defaultauth.NewServer(nil, nil, nil, nil)
+	err := defaultauth.TestInitLDAP(svr, map[string]interface{}{
+		"ldap": map[interface{}]interface{}{
+			"enable":       true,
+			"url":          "ldap://127.0.0.1:389",
+			"userBaseDN":   "ou=people,dc=example,dc=com",
+			"syncInterval": "0s",
+		},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,466 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package defaultauth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apisecurity "github.com/polarismesh/specification/source/go/api/v1/security"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/auth"
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/oidc"
+	commonstore "github.com/polarismesh/polaris/common/store"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+const (
+	// oidcOptionKey auth.user.option 中 OIDC 单点登录的配置项
+	oidcOptionKey = "oidc"
+	// OIDCUserSource 通过 OIDC 登录自动创建的用户的来源
+	OIDCUserSource = "OIDC"
+)
+
+// OIDCConfig OIDC 单点登录配置
+type OIDCConfig struct {
+	// Enable 是否开启 OIDC 登录
+	Enable bool `mapstructure:"enable"`
+	// Issuer 身份提供方地址
+	Issuer string `mapstructure:"issuer"`
+	// ClientID 在身份提供方注册的客户端 ID
+	ClientID string `mapstructure:"clientId"`
+	// ClientSecret 客户端密钥，公共客户端仅依赖 PKCE 时可以不填
+	ClientSecret string `mapstructure:"clientSecret"`
+	// RedirectURL 身份提供方授权完成后的回调地址
+	RedirectURL string `mapstructure:"redirectUrl"`
+	// Scopes 申请的 scope，默认为 openid profile email
+	Scopes []string `mapstructure:"scopes"`
+	// UsernameClaim 作为北极星用户名的声明，默认为 preferred_username
+	UsernameClaim string `mapstructure:"usernameClaim"`
+	// GroupsClaim 用户组的声明，默认为 groups
+	GroupsClaim string `mapstructure:"groupsClaim"`
+	// Owner 自动创建的子账户所属的主账户名称，默认为 polaris
+	Owner string `mapstructure:"owner"`
+	// GroupMapping 身份提供方用户组到北极星用户组名称的映射，为空时按照同名用户组进行关联且只做加入操作，
+	// 配置后用户每次登录都会按照映射结果加入或者移出映射中的北极星用户组
+	GroupMapping map[string]string `mapstructure:"groupMapping"`
+	// LinkLocalUser 是否允许使用同名的非 OIDC 来源的子账户登录
+	LinkLocalUser bool `mapstructure:"linkLocalUser"`
+	// StateSecret 加密 state 的密钥，多个节点需要配置相同的密钥，使得授权回调可以由任意节点处理，
+	// 为空时使用进程内随机生成的密钥，授权回调只能由发起授权的节点处理
+	StateSecret string `mapstructure:"stateSecret"`
+	// StateTTL 授权请求的有效期
+	StateTTL time.Duration `mapstructure:"stateTtl"`
+	// Timeout 访问身份提供方的超时时间
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// oidcState 授权请求的上下文，加密后作为 state 参数，使得授权回调可以由任意节点处理
+type oidcState struct {
+	Verifier string `json:"v"`
+	Nonce    string `json:"n"`
+	// Binding 与浏览器 cookie 中的随机串一致时才允许完成登录
+	Binding  string `json:"b"`
+	ExpireAt int64  `json:"e"`
+}
+
+type oidcLogin struct {
+	cfg      *OIDCConfig
+	provider *oidc.Provider
+	// stateKey 加密 state 的 AES-256 密钥
+	stateKey []byte
+}
+
+// parseOIDCConfig 解析 OIDC 配置
+func parseOIDCConfig(raw interface{}) (*OIDCConfig, error) {
+	cfg := &OIDCConfig{
+		UsernameClaim: "preferred_username",
+		GroupsClaim:   "groups",
+		Owner:         "polaris",
+		StateTTL:      10 * time.Minute,
+		Timeout:       10 * time.Second,
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     cfg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(raw); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// initOIDC 根据 auth.user.option.oidc 初始化 OIDC 单点登录
+func (svr *Server) initOIDC(option map[string]interface{}) error {
+	raw, ok := option[oidcOptionKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	cfg, err := parseOIDCConfig(raw)
+	if err != nil {
+		return fmt.Errorf("[Auth][OIDC] parse config: %w", err)
+	}
+	if !cfg.Enable {
+		return nil
+	}
+	provider, err := oidc.NewProvider(oidc.Config{
+		Issuer:       cfg.Issuer,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       cfg.Scopes,
+		HTTPClient:   &http.Client{Timeout: cfg.Timeout},
+	})
+	if err != nil {
+		return fmt.Errorf("[Auth][OIDC] %w", err)
+	}
+	stateKey, err := oidcStateKey(cfg.StateSecret)
+	if err != nil {
+		return fmt.Errorf("[Auth][OIDC] %w", err)
+	}
+	if cfg.StateSecret == "" {
+		log.Warn("[Auth][OIDC] stateSecret is empty, the callback must be handled by the node that started the login")
+	}
+	svr.oidc = &oidcLogin{cfg: cfg, provider: provider, stateKey: stateKey}
+	log.Info("[Auth][OIDC] oidc login enabled", zap.String("issuer", cfg.Issuer))
+	return nil
+}
+
+// OIDCAuthorize 生成跳转至身份提供方的授权地址，使用授权码 + PKCE 模式，
+// 返回的 Nonce 需要下发到浏览器的 cookie 中，授权回调时校验，保证回调来自发起登录的浏览器
+func (svr *Server) OIDCAuthorize(ctx context.Context) (*auth.OIDCAuthorization, *apiservice.Response) {
+	if svr.oidc == nil {
+		return nil, api.NewAuthResponseWithMsg(apimodel.Code_BadRequest, "oidc login is not enabled")
+	}
+	requestID := utils.ParseRequestID(ctx)
+
+	verifier, err := oidc.NewRandomString(32)
+	if err != nil {
+		return nil, api.NewAuthResponseWithMsg(apimodel.Code_ExecuteException, err.Error())
+	}
+	nonce, err := oidc.NewRandomString(16)
+	if err != nil {
+		return nil, api.NewAuthResponseWithMsg(apimodel.Code_ExecuteException, err.Error())
+	}
+	binding, err := oidc.NewRandomString(16)
+	if err != nil {
+		return nil, api.NewAuthResponseWithMsg(apimodel.Code_ExecuteException, err.Error())
+	}
+	expireAt := time.Now().Add(svr.oidc.cfg.StateTTL)
+	state, err := svr.oidc.sealState(&oidcState{
+		Verifier: verifier,
+		Nonce:    nonce,
+		Binding:  binding,
+		ExpireAt: expireAt.Unix(),
+	})
+	if err != nil {
+		return nil, api.NewAuthResponseWithMsg(apimodel.Code_ExecuteException, err.Error())
+	}
+	authURL, err := svr.oidc.provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		log.Error("[Auth][OIDC] build authorization url", utils.ZapRequestID(requestID), zap.Error(err))
+		return nil, api.NewAuthResponseWithMsg(apimodel.Code_ExecuteException, err.Error())
+	}
+	return &auth.OIDCAuthorization{URL: authURL, Nonce: binding, ExpireAt: expireAt}, nil
+}
+
+// OIDCLogin 使用身份提供方回调的授权码完成登录，首次登录时自动创建子账户，并按照用户组声明同步用户组关系，
+// binding 为浏览器 cookie 中保存的 OIDCAuthorize 返回的 Nonce
+func (svr *Server) OIDCLogin(ctx context.Context, code, state, binding string) *apiservice.Response {
+	if svr.oidc == nil {
+		return api.NewAuthResponseWithMsg(apimodel.Code_BadRequest, "oidc login is not enabled")
+	}
+	if code == "" || state == "" {
+		return api.NewAuthResponseWithMsg(apimodel.Code_InvalidParameter, "code and state are required")
+	}
+	requestID := utils.ParseRequestID(ctx)
+
+	authState, err := svr.oidc.openState(state)
+	if err != nil || time.Now().Unix() > authState.ExpireAt {
+		return api.NewAuthResponseWithMsg(apimodel.Code_NotAllowedAccess, "oidc state is invalid or expired")
+	}
+	if binding == "" || subtle.ConstantTimeCompare([]byte(binding), []byte(authState.Binding)) != 1 {
+		return api.NewAuthResponseWithMsg(apimodel.Code_NotAllowedAccess,
+			"oidc login is not started by this browser")
+	}
+	token, err := svr.oidc.provider.Exchange(ctx, code, authState.Verifier)
+	if err != nil {
+		log.Error("[Auth][OIDC] exchange authorization code", utils.ZapRequestID(requestID), zap.Error(err))
+		return api.NewAuthResponseWithMsg(apimodel.Code_NotAllowedAccess, err.Error())
+	}
+	claims, err := svr.oidc.provider.VerifyIDToken(ctx, token.IDToken, authState.Nonce)
+	if err != nil {
+		log.Error("[Auth][OIDC] verify id_token", utils.ZapRequestID(requestID), zap.Error(err))
+		return api.NewAuthResponseWithMsg(apimodel.Code_NotAllowedAccess, err.Error())
+	}
+	svr.mergeOIDCUserInfo(ctx, token, claims)
+
+	cfg := svr.oidc.cfg
+	username := claims.String(cfg.UsernameClaim)
+	if err := checkName(utils.NewStringValue(username)); err != nil {
+		return api.NewAuthResponseWithMsg(apimodel.Code_InvalidUserName,
+			fmt.Sprintf("oidc claim %s: %s", cfg.UsernameClaim, err.Error()))
+	}
+
+	owner, err := svr.storage.GetUserByName(cfg.Owner, "")
+	if err != nil {
+		log.Error("[Auth][OIDC] get owner user", utils.ZapRequestID(requestID), zap.Error(err))
+		return api.NewAuthResponse(commonstore.StoreCode2APICode(err))
+	}
+	if owner == nil || owner.Type != model.OwnerUserRole {
+		return api.NewAuthResponseWithMsg(apimodel.Code_NotFoundOwnerUser,
+			fmt.Sprintf("oidc owner %s not found", cfg.Owner))
+	}
+	if owner.Name == username {
+		return api.NewAuthResponseWithMsg(apimodel.Code_NotAllowedAccess, "oidc user can not be the owner")
+	}
+
+	user, err := svr.storage.GetUserByName(username, owner.ID)
+	if err != nil {
+		log.Error("[Auth][OIDC] get user by name", utils.ZapRequestID(requestID), zap.Error(err))
+		return api.NewAuthResponse(commonstore.StoreCode2APICode(err))
+	}
+	if user == nil {
+		user, err = svr.createExternalUser(ctx, owner, username, claims.String("email"), OIDCUserSource)
+		if err != nil {
+			log.Error("[Auth][OIDC] create user", utils.ZapRequestID(requestID), zap.Error(err))
+			return api.NewAuthResponse(commonstore.StoreCode2APICode(err))
+		}
+	} else if user.Source != OIDCUserSource && !cfg.LinkLocalUser {
+		return api.NewAuthResponseWithMsg(apimodel.Code_NotAllowedAccess,
+			fmt.Sprintf("user %s exists and is not managed by oidc", username))
+	}
+
+	if err := svr.syncOIDCGroups(ctx, owner, user, claims.Strings(cfg.GroupsClaim)); err != nil {
+		log.Error("[Auth][OIDC] sync user groups", utils.ZapRequestID(requestID), zap.Error(err))
+		return api.NewAuthResponse(commonstore.StoreCode2APICode(err))
+	}
+
+	log.Info("[Auth][OIDC] user login", utils.ZapRequestID(requestID), zap.String("name", username),
+		zap.String("sub", claims.String("sub")))
+	return loginResponse(user)
+}
+
+// mergeOIDCUserInfo id_token 中缺少用户名或者用户组声明时，尝试从 userinfo 中补充
+func (svr *Server) mergeOIDCUserInfo(ctx context.Context, token *oidc.Token, claims oidc.Claims) {
+	cfg := svr.oidc.cfg
+	if token.AccessToken == "" {
+		return
+	}
+	if _, ok := claims[cfg.UsernameClaim]; ok {
+		if _, ok := claims[cfg.GroupsClaim]; ok {
+			return
+		}
+	}
+	userInfo, err := svr.oidc.provider.UserInfo(ctx, token.AccessToken)
+	if err != nil {
+		log.Warn("[Auth][OIDC] query userinfo", zap.Error(err))
+		return
+	}
+	// userinfo 的 sub 必须与 id_token 一致
+	if userInfo.String("sub") != claims.String("sub") {
+		return
+	}
+	for _, name := range []string{cfg.UsernameClaim, cfg.GroupsClaim, "email"} {
+		if _, ok := claims[name]; !ok {
+			if v, ok := userInfo[name]; ok {
+				claims[name] = v
+			}
+		}
+	}
+}
+
+// createExternalUser 创建外部身份源（OIDC、LDAP）对应的子账户，密码随机生成，因此只能通过外部身份源登录
+func (svr *Server) createExternalUser(ctx context.Context, owner *model.User, username, email,
+	source string) (*model.User, error) {
+	password, err := oidc.NewRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	req := &apisecurity.User{
+		Name:     utils.NewStringValue(username),
+		Password: utils.NewStringValue(password),
+		Owner:    utils.NewStringValue(owner.ID),
+		Source:   utils.NewStringValue(source),
+		Comment:  utils.NewStringValue("created by " + strings.ToLower(source) + " login"),
+	}
+	user, err := createUserModel(req, model.OwnerUserRole)
+	if err != nil {
+		return nil, err
+	}
+	user.Email = email
+	if err := svr.storage.AddUser(user); err != nil {
+		return nil, err
+	}
+
+	log.Info("[Auth][User] create external user", utils.ZapRequestID(utils.ParseRequestID(ctx)),
+		zap.String("name", username), zap.String("owner", owner.Name), zap.String("source", source))
+	req.Password = nil
+	svr.RecordHistory(userRecordEntry(ctx, req, user, model.OCreate))
+	return user, nil
+}
+
+// syncOIDCGroups 按照身份提供方的用户组声明同步用户所属的北极星用户组，北极星中不存在的用户组会被忽略
+func (svr *Server) syncOIDCGroups(ctx context.Context, owner, user *model.User, idpGroups []string) error {
+	mapping := svr.oidc.cfg.GroupMapping
+	targets := map[string]bool{}
+	for _, name := range idpGroups {
+		if len(mapping) == 0 {
+			targets[name] = true
+		} else if target, ok := mapping[name]; ok {
+			targets[target] = true
+		}
+	}
+	// 配置了映射时，映射中的用户组由 OIDC 管理，不在声明中的需要移出
+	managed := map[string]bool{}
+	for name := range targets {
+		managed[name] = true
+	}
+	for _, target := range mapping {
+		managed[target] = true
+	}
+	names := make([]string, 0, len(managed))
+	for name := range managed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		group, err := svr.storage.GetGroupByName(name, owner.ID)
+		if err != nil {
+			return err
+		}
+		if group == nil {
+			log.Debug("[Auth][OIDC] user group not found, skip", zap.String("group", name))
+			continue
+		}
+		detail, err := svr.storage.GetGroup(group.ID)
+		if err != nil {
+			return err
+		}
+		if detail == nil {
+			continue
+		}
+		_, isMember := detail.UserIds[user.ID]
+		modify := &model.ModifyUserGroup{
+			ID:          group.ID,
+			Owner:       group.Owner,
+			Token:       group.Token,
+			TokenEnable: group.TokenEnable,
+			Comment:     group.Comment,
+		}
+		switch {
+		case targets[name] && !isMember:
+			modify.AddUserIds = []string{user.ID}
+		case !targets[name] && isMember:
+			modify.RemoveUserIds = []string{user.ID}
+		default:
+			continue
+		}
+		if err := svr.storage.UpdateGroup(modify); err != nil {
+			return err
+		}
+		log.Info("[Auth][OIDC] sync user group", utils.ZapRequestID(utils.ParseRequestID(ctx)),
+			zap.String("user", user.Name), zap.String("group", name), zap.Bool("join", targets[name]))
+	}
+	return nil
+}
+
+// oidcStateKey 由配置的密钥派生出 state 的加密密钥，未配置时随机生成
+func oidcStateKey(secret string) ([]byte, error) {
+	if secret == "" {
+		key := make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	sum := sha256.Sum256([]byte("polaris/oidc-state/" + secret))
+	return sum[:], nil
+}
+
+// sealState 使用 AES-GCM 加密授权请求上下文，避免 PKCE code_verifier 泄露以及 state 被篡改
+func (o *oidcLogin) sealState(state *oidcState) (string, error) {
+	plain, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(o.stateKey)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(gcm.Seal(nonce, nonce, plain, nil)), nil
+}
+
+// openState 解密授权请求上下文
+func (o *oidcLogin) openState(raw string) (*oidcState, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(o.stateKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("oidc state too short")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, err
+	}
+	state := &oidcState{}
+	if err := json.Unmarshal(plain, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
@@ -0,0 +1,167 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package defaultauth_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/auth/defaultauth"
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/oidc/oidctest"
+	storemock "github.com/polarismesh/polaris/store/mock"
+)
+
+func Test_server_OIDCLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	issuer, err := oidctest.NewIssuer("polaris-console", "secret")
+	assert.NoError(t, err)
+	defer issuer.Close()
+
+	storage := storemock.NewMockStore(ctrl)
+	svr := defaultauth.NewServer(storage, nil, nil, nil)
+	assert.NoError(t, defaultauth.TestInitOIDC(svr, map[string]interface{}{
+		"oidc": map[interface{}]interface{}{
+			"enable":       true,
+			"issuer":       issuer.URL,
+			"clientId":     "polaris-console",
+			"clientSecret": "secret",
+			"redirectUrl":  "http://127.0.0.1:8090/core/v1/user/login/oidc/callback",
+			"stateSecret":  "state-secret",
+			"groupMapping": map[interface{}]interface{}{
+				"idp-dev": "dev",
+				"idp-ops": "ops",
+			},
+		},
+	}))
+
+	owner := &model.User{ID: "owner-id", Name: "polaris", Type: model.OwnerUserRole}
+	devGroup := &model.UserGroup{ID: "dev-id", Name: "dev", Owner: owner.ID, Token: "dev-token", TokenEnable: true}
+	opsGroup := &model.UserGroup{ID: "ops-id", Name: "ops", Owner: owner.ID, Token: "ops-token", TokenEnable: true}
+	storage.EXPECT().GetUserByName("polaris", "").AnyTimes().Return(owner, nil)
+	storage.EXPECT().GetGroupByName("dev", owner.ID).AnyTimes().Return(devGroup, nil)
+	storage.EXPECT().GetGroupByName("ops", owner.ID).AnyTimes().Return(opsGroup, nil)
+
+	login := func(claims map[string]interface{}) *apiservice.Response {
+		ctx := context.Background()
+		authorization, errResp := svr.OIDCAuthorize(ctx)
+		assert.Nil(t, errResp)
+		assert.NotEmpty(t, authorization.Nonce)
+		code, state, err := issuer.Authorize(authorization.URL, claims)
+		assert.NoError(t, err)
+		return svr.OIDCLogin(ctx, code, state, authorization.Nonce)
+	}
+
+	t.Run("首次登录自动创建子账户并加入用户组", func(t *testing.T) {
+		var created *model.User
+		storage.EXPECT().GetUserByName("alice", owner.ID).Return(nil, nil)
+		storage.EXPECT().AddUser(gomock.Any()).DoAndReturn(func(user *model.User) error {
+			created = user
+			return nil
+		})
+		storage.EXPECT().GetGroup(devGroup.ID).Return(&model.UserGroupDetail{
+			UserGroup: devGroup, UserIds: map[string]struct{}{}}, nil)
+		storage.EXPECT().GetGroup(opsGroup.ID).Return(&model.UserGroupDetail{
+			UserGroup: opsGroup, UserIds: map[string]struct{}{}}, nil)
+		storage.EXPECT().UpdateGroup(gomock.Any()).DoAndReturn(func(group *model.ModifyUserGroup) error {
+			assert.Equal(t, devGroup.ID, group.ID)
+			assert.Equal(t, devGroup.Token, group.Token)
+			assert.Equal(t, []string{created.ID}, group.AddUserIds)
+			return nil
+		})
+
+		resp := login(map[string]interface{}{
+			"sub":                "1001",
+			"preferred_username": "alice",
+			"email":              "alice@example.com",
+			"groups":             []string{"idp-dev", "idp-unknown"},
+		})
+		assert.Equal(t, api.ExecuteSuccess, resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+		assert.Equal(t, model.SubAccountUserRole, created.Type)
+		assert.Equal(t, owner.ID, created.Owner)
+		assert.Equal(t, defaultauth.OIDCUserSource, created.Source)
+		assert.Equal(t, "alice@example.com", created.Email)
+		assert.Equal(t, created.Token, resp.GetLoginResponse().GetToken().GetValue())
+		assert.Equal(t, owner.ID, resp.GetLoginResponse().GetOwnerId().GetValue())
+	})
+
+	t.Run("再次登录按照用户组声明调整用户组", func(t *testing.T) {
+		alice := &model.User{ID: "alice-id", Name: "alice", Owner: owner.ID, Source: defaultauth.OIDCUserSource,
+			Type: model.SubAccountUserRole, Token: "alice-token"}
+		storage.EXPECT().GetUserByName("alice", owner.ID).Return(alice, nil)
+		storage.EXPECT().GetGroup(devGroup.ID).Return(&model.UserGroupDetail{
+			UserGroup: devGroup, UserIds: map[string]struct{}{alice.ID: {}}}, nil)
+		storage.EXPECT().GetGroup(opsGroup.ID).Return(&model.UserGroupDetail{
+			UserGroup: opsGroup, UserIds: map[string]struct{}{}}, nil)
+		storage.EXPECT().UpdateGroup(gomock.Any()).Times(2).DoAndReturn(func(group *model.ModifyUserGroup) error {
+			switch group.ID {
+			case devGroup.ID:
+				assert.Equal(t, []string{alice.ID}, group.RemoveUserIds)
+			case opsGroup.ID:
+				assert.Equal(t, []string{alice.ID}, group.AddUserIds)
+			}
+			return nil
+		})
+
+		resp := login(map[string]interface{}{
+			"sub":                "1001",
+			"preferred_username": "alice",
+			"groups":             []string{"idp-ops"},
+		})
+		assert.Equal(t, api.ExecuteSuccess, resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+		assert.Equal(t, "alice-token", resp.GetLoginResponse().GetToken().GetValue())
+	})
+
+	t.Run("同名的本地账户不允许登录", func(t *testing.T) {
+		storage.EXPECT().GetUserByName("bob", owner.ID).Return(&model.User{
+			ID: "bob-id", Name: "bob", Owner: owner.ID, Source: "Polaris"}, nil)
+
+		resp := login(map[string]interface{}{"sub": "1002", "preferred_username": "bob"})
+		assert.Equal(t, api.NotAllowedAccess, resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+	})
+
+	t.Run("state非法", func(t *testing.T) {
+		resp := svr.OIDCLogin(context.Background(), "code", "invalid-state", "nonce")
+		assert.Equal(t, api.NotAllowedAccess, resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+	})
+
+	t.Run("授权回调不是来自发起登录的浏览器", func(t *testing.T) {
+		authorization, errResp := svr.OIDCAuthorize(context.Background())
+		assert.Nil(t, errResp)
+		code, state, err := issuer.Authorize(authorization.URL, map[string]interface{}{
+			"sub": "1003", "preferred_username": "mallory"})
+		assert.NoError(t, err)
+
+		resp := svr.OIDCLogin(context.Background(), code, state, "")
+		assert.Equal(t, api.NotAllowedAccess, resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+		resp = svr.OIDCLogin(context.Background(), code, state, "other-nonce")
+		assert.Equal(t, api.NotAllowedAccess, resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+	})
+
+	t.Run("未开启OIDC登录", func(t *testing.T) {
+		_, errResp := defaultauth.NewServer(storage, nil, nil, nil).OIDCAuthorize(context.Background())
+		assert.Equal(t, api.BadRequest, errResp.GetCode().GetValue())
+	})
+}
@@ -54,6 +54,10 @@ type Server struct {
 	history  plugin.History
 	cacheMgn cachetypes.CacheManager
 	authMgn  *DefaultAuthChecker
+	// oidc OIDC 单点登录，未开启时为 nil
+	oidc *oidcLogin
+	// ldap LDAP 目录集成，未开启时为 nil
+	ldap *ldapDirectory
 }
 
 // initialize
@@ -75,6 +79,10 @@ func (svr *Server) Login(req *apisecurity.LoginRequest) *apiservice.Response {
 		ownerName = username
 	}
 	user := svr.cacheMgn.User().GetUserByName(username, ownerName)
+	// 开启 LDAP 后，本地不存在的用户以及 LDAP 来源的用户都通过目录服务校验密码
+	if svr.ldap != nil && (user == nil || user.Source == LDAPUserSource) {
+		return svr.ldapLogin(req, user)
+	}
 	if user == nil {
 		return api.NewAuthResponse(apimodel.Code_NotFoundUser)
 	}
@@ -89,6 +97,11 @@ func (svr *Server) Login(req *apisecurity.LoginRequest) *apiservice.Response {
 		return api.NewAuthResponseWithMsg(apimodel.Code_ExecuteException, model.ErrorWrongUsernameOrPassword.Error())
 	}
 
+	return loginResponse(user)
+}
+
+// loginResponse 构造登录成功的响应，返回用户的 token
+func loginResponse(user *model.User) *apiservice.Response {
 	return api.NewLoginResponse(apimodel.Code_ExecuteSuccess, &apisecurity.LoginResponse{
 		UserId:  utils.NewStringValue(user.ID),
 		OwnerId: utils.NewStringValue(user.Owner),
@@ -140,6 +140,78 @@ func (svr *Server) UpdateStrategy(ctx context.Context, req *apisecurity.ModifyAu
 	return api.NewModifyAuthStrategyResponse(apimodel.Code_ExecuteSuccess, req)
 }
 
+// UpdateStrategyAction 修改鉴权策略的动作，支持 READ_WRITE、ONLY_READ、ALLOW:<操作/方法列表>、DENY:<操作/方法列表>
+// 其中操作为 READ、CREATE、MODIFY、DELETE、*，其余的视为接口方法名，例如 DENY:DELETE,UpdateInstances
+func (svr *Server) UpdateStrategyAction(ctx context.Context, id string, action string) *apiservice.Response {
+	requestID := utils.ParseRequestID(ctx)
+	req := &apisecurity.AuthStrategy{Id: utils.NewStringValue(id)}
+
+	parsed, err := model.ParseStrategyAction(action)
+	if err != nil {
+		return api.NewAuthStrategyResponseWithMsg(apimodel.Code_InvalidParameter, err.Error(), req)
+	}
+
+	strategy, err := svr.storage.GetStrategyDetail(id)
+	if err != nil {
+		log.Error("[Auth][Strategy] get strategy from store", utils.ZapRequestID(requestID),
+			zap.Error(err))
+		return api.NewAuthStrategyResponse(commonstore.StoreCode2APICode(err), req)
+	}
+	if strategy == nil {
+		return api.NewAuthStrategyResponse(apimodel.Code_NotFoundAuthStrategyRule, req)
+	}
+	userId := utils.ParseUserID(ctx)
+	if authcommon.ParseUserRole(ctx) != model.AdminUserRole {
+		if !utils.ParseIsOwner(ctx) || userId != strategy.Owner {
+			return api.NewAuthStrategyResponse(apimodel.Code_NotAllowedAccess, req)
+		}
+	}
+	// 主账户的默认策略禁止编辑
+	if strategy.Default && len(strategy.Principals) == 1 &&
+		strategy.Principals[0].PrincipalRole == model.PrincipalUser &&
+		strategy.Principals[0].PrincipalID == utils.ParseOwnerID(ctx) {
+		return api.NewAuthResponse(apimodel.Code_NotAllowModifyOwnerDefaultStrategy)
+	}
+
+	req.Name = utils.NewStringValue(strategy.Name)
+	req.Action = toAPIAction(strategy.Action)
+	if parsed.String() == strategy.Action {
+		return api.NewAuthStrategyResponse(apimodel.Code_NoNeedUpdate, req)
+	}
+
+	data := &model.ModifyStrategyDetail{
+		ID:         strategy.ID,
+		Name:       strategy.Name,
+		Action:     parsed.String(),
+		Comment:    strategy.Comment,
+		ModifyTime: time.Now(),
+	}
+	if err := svr.storage.UpdateStrategy(data); err != nil {
+		log.Error("[Auth][Strategy] update strategy action into store",
+			utils.ZapRequestID(requestID), zap.Error(err))
+		return api.NewAuthResponseWithMsg(commonstore.StoreCode2APICode(err), err.Error())
+	}
+
+	log.Info("[Auth][Strategy] update strategy action into store", utils.ZapRequestID(requestID),
+		zap.String("name", strategy.Name), zap.String("action", data.Action))
+	svr.RecordHistory(authModifyStrategyRecordEntry(ctx, &apisecurity.ModifyAuthStrategy{
+		Id:   utils.NewStringValue(strategy.ID),
+		Name: utils.NewStringValue(strategy.Name),
+	}, data, model.OUpdate))
+
+	req.Action = toAPIAction(data.Action)
+	return api.NewAuthStrategyResponse(apimodel.Code_ExecuteSuccess, req)
+}
+
+// toAPIAction 将策略动作转换为 API 的读写枚举，只有允许全部写操作的策略为 READ_WRITE
+func toAPIAction(action string) apisecurity.AuthAction {
+	parsed, err := model.ParseStrategyAction(action)
+	if err != nil || !parsed.AllowAllWrite() {
+		return apisecurity.AuthAction_ONLY_READ
+	}
+	return apisecurity.AuthAction_READ_WRITE
+}
+
 // DeleteStrategies 批量删除鉴权策略
 func (svr *Server) DeleteStrategies(
 	ctx context.Context, reqs []*apisecurity.AuthStrategy) *apiservice.BatchWriteResponse {
@@ -445,7 +517,7 @@ func (svr *Server) authStrategy2Api(s *model.StrategyDetail) *apisecurity.AuthSt
 		Comment:         utils.NewStringValue(s.Comment),
 		Ctime:           utils.NewStringValue(commontime.Time2String(s.CreateTime)),
 		Mtime:           utils.NewStringValue(commontime.Time2String(s.ModifyTime)),
-		Action:          apisecurity.AuthAction(apisecurity.AuthAction_value[s.Action]),
+		Action:          toAPIAction(s.Action),
 		DefaultStrategy: utils.NewBoolValue(s.Default),
 	}
 
@@ -477,7 +549,7 @@ func (svr *Server) authStrategyFull2Api(data *model.StrategyDetail) *apisecurity
 		Comment:         utils.NewStringValue(data.Comment),
 		Ctime:           utils.NewStringValue(commontime.Time2String(data.CreateTime)),
 		Mtime:           utils.NewStringValue(commontime.Time2String(data.ModifyTime)),
-		Action:          apisecurity.AuthAction(apisecurity.AuthAction_value[data.Action]),
+		Action:          toAPIAction(data.Action),
 		DefaultStrategy: utils.NewBoolValue(data.Default),
 	}
 
@@ -98,6 +98,16 @@ func (svr *StrategyAuthAbility) UpdateStrategies(ctx context.Context,
 	return svr.target.UpdateStrategies(ctx, reqs)
 }
 
+// UpdateStrategyAction update the action of a strategy.
+func (svr *StrategyAuthAbility) UpdateStrategyAction(ctx context.Context, id string, action string) *apiservice.Response {
+	ctx, rsp := verifyAuth(ctx, WriteOp, MustOwner, svr.authMgn)
+	if rsp != nil {
+		return rsp
+	}
+
+	return svr.target.UpdateStrategyAction(ctx, id, action)
+}
+
 // DeleteStrategies delete strategy.
 func (svr *StrategyAuthAbility) DeleteStrategies(ctx context.Context,
 	reqs []*apisecurity.AuthStrategy) *apiservice.BatchWriteResponse {
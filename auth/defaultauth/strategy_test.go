@@ -87,6 +87,7 @@ func newStrategyTest(t *testing.T) *StrategyTest {
 	storage.EXPECT().GetUnixSecond(gomock.Any()).AnyTimes().Return(time.Now().Unix(), nil)
 	storage.EXPECT().GetUsersForCache(gomock.Any(), gomock.Any()).AnyTimes().Return(users, nil)
 	storage.EXPECT().GetGroupsForCache(gomock.Any(), gomock.Any()).AnyTimes().Return(groups, nil)
+	storage.EXPECT().GetAccessTokensForCache(gomock.Any(), gomock.Any()).AnyTimes().Return(nil, nil)
 	storage.EXPECT().GetStrategyDetailsForCache(gomock.Any(), gomock.Any()).AnyTimes().Return(allStrategies, nil)
 	storage.EXPECT().GetMoreNamespaces(gomock.Any()).AnyTimes().Return(namespaces, nil)
 	storage.EXPECT().GetMoreServices(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(serviceMap, nil)
@@ -635,6 +636,42 @@ func Test_DeleteStrategy(t *testing.T) {
 
 }
 
+func Test_UpdateStrategyAction(t *testing.T) {
+	strategyTest := newStrategyTest(t)
+	defer strategyTest.Clean()
+
+	t.Run("正常修改鉴权策略动作", func(t *testing.T) {
+		strategyTest.storage.EXPECT().GetStrategyDetail(gomock.Any()).Return(strategyTest.strategies[0], nil)
+		strategyTest.storage.EXPECT().UpdateStrategy(gomock.Any()).DoAndReturn(func(data *model.ModifyStrategyDetail) error {
+			assert.Equal(t, "DENY:DELETE,UpdateInstances", data.Action)
+			return nil
+		})
+
+		valCtx := context.WithValue(context.Background(), utils.ContextAuthTokenKey, strategyTest.users[0].Token)
+		resp := strategyTest.svr.UpdateStrategyAction(valCtx, strategyTest.strategies[0].ID, "deny:delete,UpdateInstances")
+		assert.Equal(t, api.ExecuteSuccess, resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+	})
+
+	t.Run("修改鉴权策略动作-动作非法", func(t *testing.T) {
+		valCtx := context.WithValue(context.Background(), utils.ContextAuthTokenKey, strategyTest.users[0].Token)
+		resp := strategyTest.svr.UpdateStrategyAction(valCtx, strategyTest.strategies[0].ID, "ALLOW:")
+		assert.Equal(t, api.InvalidParameter, resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+	})
+
+	t.Run("修改鉴权策略动作-目标owner不为自己", func(t *testing.T) {
+		oldOwner := strategyTest.strategies[0].Owner
+		defer func() {
+			strategyTest.strategies[0].Owner = oldOwner
+		}()
+		strategyTest.strategies[0].Owner = strategyTest.users[2].ID
+
+		strategyTest.storage.EXPECT().GetStrategyDetail(gomock.Any()).Return(strategyTest.strategies[0], nil)
+		valCtx := context.WithValue(context.Background(), utils.ContextAuthTokenKey, strategyTest.users[0].Token)
+		resp := strategyTest.svr.UpdateStrategyAction(valCtx, strategyTest.strategies[0].ID, model.ActionOnlyRead)
+		assert.Equal(t, api.NotAllowedAccess, resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+	})
+}
+
 func Test_GetStrategy(t *testing.T) {
 	strategyTest := newStrategyTest(t)
 	defer strategyTest.Clean()
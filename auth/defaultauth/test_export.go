@@ -21,6 +21,10 @@ import (
 	"context"
 
 	"github.com/golang/protobuf/ptypes/wrappers"
+	apisecurity "github.com/polarismesh/specification/source/go/api/v1/security"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+
+	"github.com/polarismesh/polaris/common/model"
 )
 
 func TestCheckPassword(password *wrappers.StringValue) error {
@@ -42,3 +46,40 @@ func TestDecryptMessage(key []byte, message string) (string, error) {
 func TestParseStrategySearchArgs(ctx context.Context, searchFilters map[string]string) map[string]string {
 	return parseStrategySearchArgs(ctx, searchFilters)
 }
+
+func TestInitOIDC(svr *Server, option map[string]interface{}) error {
+	return svr.initOIDC(option)
+}
+
+// TestInitLDAP 仅初始化 LDAP 目录配置，不启动周期同步任务
+func TestInitLDAP(svr *Server, option map[string]interface{}) error {
+	cfg, err := parseLDAPConfig(option[ldapOptionKey])
+	if err != nil {
+		return err
+	}
+	svr.ldap = &ldapDirectory{cfg: cfg}
+	return nil
+}
+
+func TestSyncLDAP(svr *Server) error {
+	return svr.syncLDAP(context.Background())
+}
+
+func TestLDAPLogin(svr *Server, req *apisecurity.LoginRequest, user *model.User) *apiservice.Response {
+	return svr.ldapLogin(req, user)
+}
+
+// TestCreateAccessToken 生成个人访问令牌，返回令牌原文以及存储使用的摘要
+func TestCreateAccessToken(id string) (string, string, error) {
+	token, err := createAccessToken(id)
+	if err != nil {
+		return "", "", err
+	}
+	return token, accessTokenSecret(token), nil
+}
+
+// TestVerifyAuth 用户、用户组以及鉴权策略接口的鉴权检查
+func TestVerifyAuth(ctx context.Context, isWrite bool, authMgn *DefaultAuthChecker) *apiservice.Response {
+	_, rsp := verifyAuth(ctx, isWrite, NotOwner, authMgn)
+	return rsp
+}
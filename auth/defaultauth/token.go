@@ -54,6 +54,12 @@ type OperatorInfo struct {
 
 	// 是否属于匿名操作者
 	Anonymous bool
+
+	// AccessTokenID 个人访问令牌的 ID，使用用户/用户组自身的 token 时为空
+	AccessTokenID string
+
+	// AccessToken 校验通过的个人访问令牌
+	AccessToken *model.AccessToken
 }
 
 func newAnonymous() OperatorInfo {
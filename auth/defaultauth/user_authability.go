@@ -65,6 +65,12 @@ func (svr *UserAuthAbility) Initialize(authOpt *auth.Config, storage store.Store
 		cacheMgn: cacheMgn,
 		authMgn:  authMgn,
 	}
+	if err := svr.target.initOIDC(authOpt.User.Option); err != nil {
+		return err
+	}
+	if err := svr.target.initLDAP(authOpt.User.Option); err != nil {
+		return err
+	}
 	svr.GroupAuthAbility = &GroupAuthAbility{
 		authMgn: svr.authMgn,
 		target:  svr.target,
@@ -182,7 +188,49 @@ func (svr *UserAuthAbility) ResetUserToken(ctx context.Context, user *apisecurit
 	return svr.target.ResetUserToken(ctx, user)
 }
 
+// CreateAccessToken 创建个人访问令牌，允许子账户为自己创建
+func (svr *UserAuthAbility) CreateAccessToken(ctx context.Context,
+	req *auth.AccessTokenRequest) (*auth.AccessTokenView, *apiservice.Response) {
+	ctx, rsp := verifyAuth(ctx, WriteOp, NotOwner, svr.authMgn)
+	if rsp != nil {
+		return nil, rsp
+	}
+
+	return svr.target.CreateAccessToken(ctx, req)
+}
+
+// GetAccessTokens 查询个人访问令牌，任意账户均可以操作
+func (svr *UserAuthAbility) GetAccessTokens(ctx context.Context,
+	query map[string]string) (*auth.AccessTokenList, *apiservice.Response) {
+	ctx, rsp := verifyAuth(ctx, ReadOp, NotOwner, svr.authMgn)
+	if rsp != nil {
+		return nil, rsp
+	}
+
+	return svr.target.GetAccessTokens(ctx, query)
+}
+
+// RevokeAccessToken 吊销个人访问令牌，允许子账户吊销自己的令牌
+func (svr *UserAuthAbility) RevokeAccessToken(ctx context.Context, id string) *apiservice.Response {
+	ctx, rsp := verifyAuth(ctx, WriteOp, NotOwner, svr.authMgn)
+	if rsp != nil {
+		return rsp
+	}
+
+	return svr.target.RevokeAccessToken(ctx, id)
+}
+
 // Login login Servers
 func (svr *UserAuthAbility) Login(req *apisecurity.LoginRequest) *apiservice.Response {
 	return svr.target.Login(req)
 }
+
+// OIDCAuthorize 生成 OIDC 授权跳转地址
+func (svr *UserAuthAbility) OIDCAuthorize(ctx context.Context) (*auth.OIDCAuthorization, *apiservice.Response) {
+	return svr.target.OIDCAuthorize(ctx)
+}
+
+// OIDCLogin 使用 OIDC 授权码登录
+func (svr *UserAuthAbility) OIDCLogin(ctx context.Context, code, state, binding string) *apiservice.Response {
+	return svr.target.OIDCLogin(ctx, code, state, binding)
+}
@@ -176,6 +176,13 @@ func verifyAuth(ctx context.Context, isWrite bool,
 		return nil, api.NewAuthResponse(apimodel.Code_TokenDisabled)
 	}
 
+	// 个人访问令牌不允许访问用户、用户组、鉴权策略以及令牌相关的接口，读接口同样会返回账户的主 token，
+	// 放通后受限的令牌可以借此获得账户的全部权限
+	if tokenInfo.AccessToken != nil {
+		log.Error("[Auth][Server] access token can not access auth resources", utils.ZapRequestID(reqId))
+		return nil, api.NewAuthResponse(apimodel.Code_OperationRoleForbidden)
+	}
+
 	if !tokenInfo.IsUserToken {
 		log.Error("[Auth][Server] only user role can access this API", utils.ZapRequestID(reqId))
 		return nil, api.NewAuthResponse(apimodel.Code_OperationRoleForbidden)
@@ -904,3 +904,17 @@ func (mr *MockStrategyServerMockRecorder) UpdateStrategies(ctx, reqs interface{}
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStrategies", reflect.TypeOf((*MockStrategyServer)(nil).UpdateStrategies), ctx, reqs)
 }
+
+// UpdateStrategyAction mocks base method.
+func (m *MockStrategyServer) UpdateStrategyAction(ctx context.Context, id, action string) *service_manage.Response {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStrategyAction", ctx, id, action)
+	ret0, _ := ret[0].(*service_manage.Response)
+	return ret0
+}
+
+// UpdateStrategyAction indicates an expected call of UpdateStrategyAction.
+func (mr *MockStrategyServerMockRecorder) UpdateStrategyAction(ctx, id, action interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStrategyAction", reflect.TypeOf((*MockStrategyServer)(nil).UpdateStrategyAction), ctx, id, action)
+}
@@ -0,0 +1,30 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package auth
+
+import "time"
+
+// OIDCAuthorization 跳转至 OIDC 身份提供方的授权信息
+type OIDCAuthorization struct {
+	// URL 身份提供方的授权地址
+	URL string
+	// Nonce 绑定发起登录的浏览器的随机串，需要通过 cookie 下发并在授权回调时一并提交，避免登录 CSRF
+	Nonce string
+	// ExpireAt 授权请求的过期时间
+	ExpireAt time.Time
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/polarismesh/polaris/apiserver"
 	"github.com/polarismesh/polaris/auth"
 	"github.com/polarismesh/polaris/cache"
+	"github.com/polarismesh/polaris/common/eventhub"
 	"github.com/polarismesh/polaris/common/log"
 	"github.com/polarismesh/polaris/config"
 	"github.com/polarismesh/polaris/namespace"
@@ -43,6 +44,7 @@ type Config struct {
 	Bootstrap    Bootstrap          `yaml:"bootstrap"`
 	APIServers   []apiserver.Config `yaml:"apiservers"`
 	Cache        cache.Config       `yaml:"cache"`
+	EventHub     eventhub.Config    `yaml:"eventhub"`
 	Namespace    namespace.Config   `yaml:"namespace"`
 	Naming       service.Config     `yaml:"naming"`
 	Config       config.Config      `yaml:"config"`
@@ -99,12 +99,20 @@ func Start(configFilePath string) {
 
 	metrics.InitMetrics()
 	eventhub.InitEventHub()
+	if err = eventhub.InitPersistentTopics(&cfg.EventHub); err != nil {
+		fmt.Printf("[ERROR] init eventhub persistent topics fail: %v\n", err)
+		return
+	}
 
 	// 设置插件配置
 	plugin.SetPluginConfig(&cfg.Plugin)
 
 	// 初始化存储层
 	store.SetStoreConfig(&cfg.Store)
+	// 开启存储变更通知后，写操作会发布变更事件，缓存据此做定向的增量刷新
+	if notifier := plugin.GetChangeNotifier(); notifier != nil {
+		store.SetChangeNotifier(notifier)
+	}
 	var s store.Store
 	s, err = store.GetStore()
 	if err != nil {
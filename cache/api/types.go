@@ -27,6 +27,7 @@ import (
 	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
 	apitraffic "github.com/polarismesh/specification/source/go/api/v1/traffic_manage"
 
+	"github.com/polarismesh/polaris/common/eventhub"
 	"github.com/polarismesh/polaris/common/metrics"
 	"github.com/polarismesh/polaris/common/model"
 	"github.com/polarismesh/polaris/store"
@@ -519,6 +520,8 @@ type (
 		IsOwner(id string) bool
 		// GetUserLinkGroupIds
 		GetUserLinkGroupIds(id string) []string
+		// GetAccessToken 获取个人访问令牌
+		GetAccessToken(id string) *model.AccessToken
 	}
 
 	// StrategyCache is a cache for strategy rules.
@@ -532,6 +535,9 @@ type (
 		IsResourceLinkStrategy(resType apisecurity.ResourceType, resId string) bool
 		// IsResourceEditable 判断该资源是否可以操作
 		IsResourceEditable(principal model.Principal, resType apisecurity.ResourceType, resId string) bool
+		// IsResourceOperable 判断该资源是否可以执行指定的操作，拒绝策略优先
+		IsResourceOperable(principal model.Principal, resType apisecurity.ResourceType, resId string,
+			op model.ResourceOperation, method string) bool
 		// ForceSync 强制同步鉴权策略到cache (串行)
 		ForceSync() error
 	}
@@ -667,6 +673,13 @@ func (bc *BaseCache) DoCacheUpdate(name string, executor func() (map[string]time
 	if err != nil {
 		return err
 	}
+	if total > 0 && !bc.IsFirstUpdate() {
+		// 通知关注该资源的订阅者，资源可能发生了变化
+		_ = eventhub.Publish(eventhub.CacheResourceUpdateTopic, &eventhub.CacheResourceUpdateEvent{
+			Name:  name,
+			Total: total,
+		})
+	}
 
 	bc.lock.Lock()
 	defer bc.lock.Unlock()
@@ -36,6 +36,9 @@ const (
 	removePrincipalChSize = 8
 )
 
+// readWriteAction 未设置动作的策略按照 READ_WRITE 处理
+var readWriteAction, _ = model.ParseStrategyAction(model.ActionReadWrite)
+
 // strategyCache
 type strategyCache struct {
 	*types.BaseCache
@@ -154,10 +157,18 @@ func buildEnchanceStrategyDetail(strategy *model.StrategyDetail) *model.Strategy
 		}
 	}
 
+	action, err := model.ParseStrategyAction(strategy.Action)
+	if err != nil {
+		// 无法识别的动作按照只读处理, 避免放大权限
+		log.Errorf("[Cache][AuthStrategy] strategy %s parse action err: %s", strategy.ID, err.Error())
+		action, _ = model.ParseStrategyAction(model.ActionOnlyRead)
+	}
+
 	return &model.StrategyDetailCache{
 		StrategyDetail: strategy,
 		UserPrincipal:  users,
 		GroupPrincipal: groups,
+		StrategyAction: action,
 	}
 }
 
@@ -303,39 +314,72 @@ func (sc *strategyCache) Name() string {
 	return types.StrategyRuleName
 }
 
-// 对于 check 逻辑，如果是计算 * 策略，则必须要求 * 资源下必须有策略
-// 如果是具体的资源ID，则该资源下不必有策略，如果没有策略就认为这个资源是可以被任何人编辑的
-func (sc *strategyCache) checkResourceEditable(strategIds *utils.SyncSet[string], principal model.Principal, mustCheck bool) bool {
-	// 是否可以编辑
-	editable := false
-	// 是否真的包含策略
-	isCheck := strategIds.Len() != 0
-
-	// 如果根本没有遍历过，则表示该资源下没有对应的策略列表，直接返回可编辑状态即可
-	if !isCheck && !mustCheck {
-		return true
+// 对于 check 逻辑，如果是计算 * 策略，则必须要求 * 资源下必须有允许策略
+// 如果是具体的资源ID，则该资源下不必有允许策略，如果没有允许策略就认为这个资源是可以被任何人操作的
+// 返回 principals 是否被允许以及是否被拒绝策略明确拒绝
+func (sc *strategyCache) checkResourceOperable(strategIds *utils.SyncSet[string], principals []model.Principal,
+	mustCheck bool, op model.ResourceOperation, method string) (bool, bool) {
+	var allowed, denied, hasAllow bool
+	if strategIds == nil {
+		return !mustCheck, false
 	}
 
 	strategIds.Range(func(strategyId string) {
-		isCheck = true
-		if rule, ok := sc.strategys.Load(strategyId); ok {
-			if principal.PrincipalRole == model.PrincipalUser {
-				_, exist := rule.UserPrincipal[principal.PrincipalID]
-				editable = editable || exist
-			} else {
-				_, exist := rule.GroupPrincipal[principal.PrincipalID]
-				editable = editable || exist
-			}
+		rule, ok := sc.strategys.Load(strategyId)
+		if !ok {
+			return
+		}
+		action := rule.StrategyAction
+		if action == nil {
+			action = readWriteAction
+		}
+		if !action.Deny {
+			hasAllow = true
+		}
+		if !hasPrincipal(rule, principals) || !action.Match(op, method) {
+			return
+		}
+		if action.Deny {
+			denied = true
+		} else {
+			allowed = true
 		}
 	})
 
-	return editable
+	// 资源下没有任何允许策略，直接返回可操作状态即可
+	if !hasAllow && !mustCheck {
+		allowed = true
+	}
+	return allowed, denied
 }
 
-// IsResourceEditable 判断当前资源是否可以操作
-// 这里需要考虑两种情况，一种是 “ * ” 策略，另一种是明确指出了具体的资源ID的策略
+func hasPrincipal(rule *model.StrategyDetailCache, principals []model.Principal) bool {
+	for i := range principals {
+		item := principals[i]
+		if item.PrincipalRole == model.PrincipalUser {
+			if _, exist := rule.UserPrincipal[item.PrincipalID]; exist {
+				return true
+			}
+		} else {
+			if _, exist := rule.GroupPrincipal[item.PrincipalID]; exist {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsResourceEditable 判断当前资源是否可以编辑
 func (sc *strategyCache) IsResourceEditable(
 	principal model.Principal, resType apisecurity.ResourceType, resId string) bool {
+	return sc.IsResourceOperable(principal, resType, resId, model.Modify, "")
+}
+
+// IsResourceOperable 判断当前资源是否可以执行指定的操作
+// 这里需要考虑两种情况，一种是 “ * ” 策略，另一种是明确指出了具体的资源ID的策略
+// 拒绝策略优先于允许策略；读操作默认放通，只受拒绝策略的限制
+func (sc *strategyCache) IsResourceOperable(principal model.Principal, resType apisecurity.ResourceType,
+	resId string, op model.ResourceOperation, method string) bool {
 	var (
 		valAll, val *utils.SyncSet[string]
 		ok          bool
@@ -352,11 +396,6 @@ func (sc *strategyCache) IsResourceEditable(
 		valAll, _ = sc.configGroup2Strategy.Load("*")
 	}
 
-	// 代表该资源没有关联到任何策略，任何人都可以编辑
-	if !ok {
-		return true
-	}
-
 	principals := make([]model.Principal, 0, 4)
 	principals = append(principals, principal)
 	if principal.PrincipalRole == model.PrincipalUser {
@@ -369,18 +408,22 @@ func (sc *strategyCache) IsResourceEditable(
 		}
 	}
 
-	for i := range principals {
-		item := principals[i]
-		if valAll != nil && sc.checkResourceEditable(valAll, item, true) {
-			return true
-		}
-
-		if sc.checkResourceEditable(val, item, false) {
-			return true
-		}
+	allowedAll, deniedAll := sc.checkResourceOperable(valAll, principals, true, op, method)
+	if deniedAll {
+		return false
 	}
-
-	return false
+	// 代表该资源没有关联到任何策略，任何人都可以操作
+	if !ok {
+		return true
+	}
+	allowed, denied := sc.checkResourceOperable(val, principals, false, op, method)
+	if denied {
+		return false
+	}
+	if op == model.Read {
+		return true
+	}
+	return allowedAll || allowed
 }
 
 func (sc *strategyCache) GetStrategyDetailsByUID(uid string) []*model.StrategyDetail {
@@ -437,3 +437,65 @@ func testBuildPrincipalMap(principals []model.Principal, role model.PrincipalTyp
 
 	return ret
 }
+
+func Test_strategyCache_IsResourceOperable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCacheMgr := cachemock.NewMockCacheManager(ctrl)
+	mockStore := mock.NewMockStore(ctrl)
+	t.Cleanup(func() {
+		ctrl.Finish()
+	})
+
+	userCache := NewUserCache(mockStore, mockCacheMgr)
+	strategyCache := NewStrategyCache(mockStore, mockCacheMgr).(*strategyCache)
+	mockCacheMgr.EXPECT().GetCacher(types.CacheUser).Return(userCache).AnyTimes()
+	_ = userCache.Initialize(map[string]interface{}{})
+	_ = strategyCache.Initialize(map[string]interface{}{})
+
+	newRule := func(id, action, user, resId string) *model.StrategyDetail {
+		return &model.StrategyDetail{
+			ID:     id,
+			Name:   id,
+			Action: action,
+			Principals: []model.Principal{
+				{PrincipalID: user, PrincipalRole: model.PrincipalUser},
+			},
+			Valid: true,
+			Resources: []model.StrategyResource{
+				{StrategyID: id, ResType: int32(apisecurity.ResourceType_Namespaces), ResID: resId},
+			},
+		}
+	}
+	strategyCache.setStrategys([]*model.StrategyDetail{
+		newRule("rule-rw", model.ActionReadWrite, "admin", "prod"),
+		newRule("rule-ro", model.ActionOnlyRead, "dev", "prod"),
+		newRule("rule-update", "ALLOW:READ,UpdateInstances", "ops", "prod"),
+		newRule("rule-deny", "DENY:DELETE", "admin", "*"),
+		newRule("rule-deny-read", "DENY:*", "guest", "prod"),
+	})
+
+	check := func(user string, op model.ResourceOperation, method string) bool {
+		return strategyCache.IsResourceOperable(model.Principal{
+			PrincipalID:   user,
+			PrincipalRole: model.PrincipalUser,
+		}, apisecurity.ResourceType_Namespaces, "prod", op, method)
+	}
+
+	// read-write strategy, but delete is denied on all namespaces
+	assert.True(t, check("admin", model.Modify, "UpdateNamespaces"))
+	assert.False(t, check("admin", model.Delete, "DeleteNamespaces"))
+	// read only strategy
+	assert.True(t, check("dev", model.Read, "GetNamespaces"))
+	assert.False(t, check("dev", model.Modify, "UpdateNamespaces"))
+	assert.False(t, strategyCache.IsResourceEditable(model.Principal{
+		PrincipalID:   "dev",
+		PrincipalRole: model.PrincipalUser,
+	}, apisecurity.ResourceType_Namespaces, "prod"))
+	// allow by method
+	assert.True(t, check("ops", model.Modify, "UpdateInstances"))
+	assert.False(t, check("ops", model.Modify, "UpdateNamespaces"))
+	// read is open unless denied
+	assert.True(t, check("nobody", model.Read, "GetNamespaces"))
+	assert.False(t, check("nobody", model.Modify, "UpdateNamespaces"))
+	assert.False(t, check("guest", model.Read, "GetNamespaces"))
+}
@@ -44,6 +44,10 @@ type userRefreshResult struct {
 	groupAdd    int
 	groupUpdate int
 	groupDel    int
+
+	tokenAdd    int
+	tokenUpdate int
+	tokenDel    int
 }
 
 // userCache 用户信息缓存
@@ -61,6 +65,8 @@ type userCache struct {
 	groups *utils.SyncMap[string, *model.UserGroupDetail]
 	// userid -> groups
 	user2Groups *utils.SyncMap[string, *utils.SyncSet[string]]
+	// tokenid -> access token
+	accessTokens *utils.SyncMap[string, *model.AccessToken]
 
 	lastUserMtime  int64
 	lastGroupMtime int64
@@ -82,6 +88,7 @@ func (uc *userCache) Initialize(_ map[string]interface{}) error {
 	uc.name2Users = utils.NewSyncMap[string, *model.User]()
 	uc.groups = utils.NewSyncMap[string, *model.UserGroupDetail]()
 	uc.user2Groups = utils.NewSyncMap[string, *utils.SyncSet[string]]()
+	uc.accessTokens = utils.NewSyncMap[string, *model.AccessToken]()
 	uc.adminUser = atomic.Value{}
 	uc.singleFlight = new(singleflight.Group)
 	return nil
@@ -109,7 +116,13 @@ func (uc *userCache) realUpdate() (map[string]time.Time, int64, error) {
 		log.Errorf("[Cache][Group] update group err: %s", err.Error())
 		return nil, -1, err
 	}
+	tokens, err := uc.storage.GetAccessTokensForCache(uc.LastFetchTime(), uc.IsFirstUpdate())
+	if err != nil {
+		log.Errorf("[Cache][AccessToken] update access token err: %s", err.Error())
+		return nil, -1, err
+	}
 	lastMimes, refreshRet := uc.setUserAndGroups(users, groups)
+	uc.handlerAccessTokenCacheUpdate(lastMimes, &refreshRet, tokens)
 
 	timeDiff := time.Since(start)
 	if timeDiff > time.Second {
@@ -124,8 +137,13 @@ func (uc *userCache) realUpdate() (map[string]time.Time, int64, error) {
 			zap.Int("update", refreshRet.groupUpdate),
 			zap.Int("delete", refreshRet.groupDel),
 			zap.Time("last", time.Unix(uc.lastGroupMtime, 0)), zap.Duration("used", time.Since(start)))
+
+		log.Info("[Cache][AccessToken] get more access token",
+			zap.Int("add", refreshRet.tokenAdd),
+			zap.Int("update", refreshRet.tokenUpdate),
+			zap.Int("delete", refreshRet.tokenDel), zap.Duration("used", time.Since(start)))
 	}
-	return lastMimes, int64(len(users) + len(groups)), nil
+	return lastMimes, int64(len(users) + len(groups) + len(tokens)), nil
 }
 
 func (uc *userCache) setUserAndGroups(users []*model.User,
@@ -258,12 +276,40 @@ func (uc *userCache) handlerGroupCacheUpdate(lastMimes map[string]time.Time, ret
 	lastMimes["group"] = time.Unix(lastGroupMtime, 0)
 }
 
+// handlerAccessTokenCacheUpdate 处理个人访问令牌更新，吊销的令牌直接从缓存中移除
+func (uc *userCache) handlerAccessTokenCacheUpdate(lastMimes map[string]time.Time, ret *userRefreshResult,
+	tokens []*model.AccessToken) {
+
+	lastTokenMtime := uc.LastMtime("access_token").Unix()
+
+	for i := range tokens {
+		token := tokens[i]
+
+		lastTokenMtime = int64(math.Max(float64(lastTokenMtime), float64(token.ModifyTime.Unix())))
+
+		if !token.Valid {
+			uc.accessTokens.Delete(token.ID)
+			ret.tokenDel++
+			continue
+		}
+		if _, ok := uc.accessTokens.Load(token.ID); ok {
+			ret.tokenUpdate++
+		} else {
+			ret.tokenAdd++
+		}
+		uc.accessTokens.Store(token.ID, token)
+	}
+
+	lastMimes["access_token"] = time.Unix(lastTokenMtime, 0)
+}
+
 func (uc *userCache) Clear() error {
 	uc.BaseCache.Clear()
 	uc.users = utils.NewSyncMap[string, *model.User]()
 	uc.name2Users = utils.NewSyncMap[string, *model.User]()
 	uc.groups = utils.NewSyncMap[string, *model.UserGroupDetail]()
 	uc.user2Groups = utils.NewSyncMap[string, *utils.SyncSet[string]]()
+	uc.accessTokens = utils.NewSyncMap[string, *model.AccessToken]()
 	uc.adminUser = atomic.Value{}
 	uc.lastUserMtime = 0
 	uc.lastGroupMtime = 0
@@ -351,3 +397,15 @@ func (uc *userCache) GetUserLinkGroupIds(userId string) []string {
 	}
 	return val.ToSlice()
 }
+
+// GetAccessToken 根据令牌ID获取个人访问令牌缓存对象
+func (uc *userCache) GetAccessToken(id string) *model.AccessToken {
+	if id == "" {
+		return nil
+	}
+	val, ok := uc.accessTokens.Load(id)
+	if !ok {
+		return nil
+	}
+	return val
+}
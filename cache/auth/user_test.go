@@ -145,6 +145,7 @@ func TestUserCache_UpdateNormal(t *testing.T) {
 		}
 		store.EXPECT().GetUsersForCache(gomock.Any(), gomock.Any()).Return(copyUsers, nil).Times(1)
 		store.EXPECT().GetGroupsForCache(gomock.Any(), gomock.Any()).Return(copyGroups, nil).Times(1)
+		store.EXPECT().GetAccessTokensForCache(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
 
 		assert.NoError(t, uc.Update())
 
@@ -202,6 +203,7 @@ func TestUserCache_UpdateNormal(t *testing.T) {
 
 		store.EXPECT().GetUsersForCache(gomock.Any(), gomock.Any()).Return(copyUsers, nil).Times(1)
 		store.EXPECT().GetGroupsForCache(gomock.Any(), gomock.Any()).Return(copyGroups, nil).Times(1)
+		store.EXPECT().GetAccessTokensForCache(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
 
 		assert.NoError(t, uc.Update())
 
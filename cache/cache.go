@@ -21,20 +21,53 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	types "github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/common/model"
 	"github.com/polarismesh/polaris/common/utils"
 	"github.com/polarismesh/polaris/store"
 )
 
 var (
 	cacheSet = map[string]int{}
+
+	// changeResourceCaches 存储变更事件的资源类型与需要刷新的缓存
+	changeResourceCaches = map[model.ChangeResource][]string{
+		model.ChangeService:           {types.ServiceName},
+		model.ChangeInstance:          {types.InstanceName},
+		model.ChangeRoutingConfig:     {types.RoutingConfigName},
+		model.ChangeRateLimit:         {types.RateLimitConfigName},
+		model.ChangeCircuitBreaker:    {types.CircuitBreakerName},
+		model.ChangeFaultDetect:       {types.FaultDetectRuleName},
+		model.ChangeConfigGroup:       {types.ConfigGroupCacheName},
+		model.ChangeConfigFileRelease: {types.ConfigFileCacheName},
+		// 用户、用户组的增删会同时维护其默认鉴权策略
+		model.ChangeUser:        {types.UsersName, types.StrategyRuleName},
+		model.ChangeUserGroup:   {types.UsersName, types.StrategyRuleName},
+		model.ChangeAccessToken: {types.UsersName},
+		model.ChangeStrategy:    {types.StrategyRuleName},
+	}
+	// eventDrivenCaches 由存储变更事件驱动刷新的缓存
+	eventDrivenCaches = func() map[string]struct{} {
+		ret := map[string]struct{}{}
+		for _, names := range changeResourceCaches {
+			for _, name := range names {
+				ret[name] = struct{}{}
+			}
+		}
+		return ret
+	}()
 )
 
 const (
 	// UpdateCacheInterval 缓存更新时间间隔
 	UpdateCacheInterval = 1 * time.Second
+	// DefaultFallbackInterval 开启存储变更通知后，由变更事件驱动的缓存默认的兜底轮询间隔
+	DefaultFallbackInterval = 10 * time.Second
+
+	changeEventQueueSize = 1024
 )
 
 // CacheManager 名字服务缓存
@@ -42,6 +75,10 @@ type CacheManager struct {
 	storage  store.Store
 	caches   []types.Cache
 	needLoad *utils.SyncSet[string]
+	// changes 订阅到的存储变更事件
+	changes chan []*model.ChangeEvent
+	// resync 变更事件存在丢失，需要刷新全部由变更事件驱动的缓存
+	resync int32
 }
 
 // Initialize 缓存对象初始化
@@ -78,8 +115,12 @@ func (nc *CacheManager) OpenResourceCache(entries ...types.ConfigEntry) error {
 
 // update 缓存更新
 func (nc *CacheManager) update() error {
+	return nc.updateCaches(nc.needLoad.ToSlice())
+}
+
+// updateCaches 并发更新指定的缓存
+func (nc *CacheManager) updateCaches(entries []string) error {
 	var wg sync.WaitGroup
-	entries := nc.needLoad.ToSlice()
 	for i := range entries {
 		name := entries[i]
 		index, exist := cacheSet[name]
@@ -129,6 +170,15 @@ func (nc *CacheManager) Start(ctx context.Context) error {
 	}
 	log.Infof("[Cache] cache update done")
 
+	if notifier := store.GetChangeNotifier(); notifier != nil {
+		log.Infof("[Cache] cache refresh driven by store change events, fallback interval %s",
+			nc.GetFallbackInterval())
+		nc.changes = make(chan []*model.ChangeEvent, changeEventQueueSize)
+		notifier.Subscribe(nc.onChangeEvents)
+		go nc.runWithChangeFeed(ctx)
+		return nil
+	}
+
 	// 启动协程，开始定时更新缓存数据
 	go func() {
 		ticker := time.NewTicker(nc.GetUpdateCacheInterval())
@@ -147,6 +197,126 @@ func (nc *CacheManager) Start(ctx context.Context) error {
 	return nil
 }
 
+// runWithChangeFeed 由存储变更事件驱动缓存的增量刷新
+//
+//	case 1. 收到变更事件，只刷新变更资源对应的缓存
+//	case 2. 没有变更事件覆盖的缓存，仍然按照 UpdateCacheInterval 轮询
+//	case 3. 所有缓存按照 FallbackInterval 兜底轮询，避免变更事件丢失导致缓存长时间不一致
+func (nc *CacheManager) runWithChangeFeed(ctx context.Context) {
+	ticker := time.NewTicker(nc.GetUpdateCacheInterval())
+	defer ticker.Stop()
+	fallback := time.NewTicker(nc.GetFallbackInterval())
+	defer fallback.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = nc.updateCaches(nc.pollingCaches())
+		case <-fallback.C:
+			_ = nc.update()
+		case events := <-nc.changes:
+			_ = nc.updateCaches(nc.changedCaches(events))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// onChangeEvents 接收存储变更事件，不能阻塞变更通知的分发
+func (nc *CacheManager) onChangeEvents(events []*model.ChangeEvent) {
+	select {
+	case nc.changes <- events:
+	default:
+		if atomic.SwapInt32(&nc.resync, 1) == 0 {
+			log.Warnf("[Cache] change event queue is full, resync all event driven caches")
+		}
+	}
+}
+
+// changedCaches 合并积压的变更事件，计算需要刷新的缓存，已经是最新版本的资源不会触发刷新
+func (nc *CacheManager) changedCaches(events []*model.ChangeEvent) []string {
+	targets := map[string]struct{}{}
+	collect := func(events []*model.ChangeEvent) {
+		for _, event := range events {
+			if event.Resource == model.ChangeAll {
+				atomic.StoreInt32(&nc.resync, 1)
+				continue
+			}
+			if nc.isChangeApplied(event) {
+				continue
+			}
+			for _, name := range changeResourceCaches[event.Resource] {
+				targets[name] = struct{}{}
+			}
+		}
+	}
+	collect(events)
+	for drained := false; !drained; {
+		select {
+		case more := <-nc.changes:
+			collect(more)
+		default:
+			drained = true
+		}
+	}
+	if atomic.SwapInt32(&nc.resync, 0) == 1 {
+		for _, names := range changeResourceCaches {
+			for _, name := range names {
+				targets[name] = struct{}{}
+			}
+		}
+	}
+
+	ret := make([]string, 0, len(targets))
+	for name := range targets {
+		if nc.needLoad.Contains(name) {
+			ret = append(ret, name)
+		}
+	}
+	return ret
+}
+
+// isChangeApplied 变更事件携带了版本号时，判断缓存中是否已经是该版本
+func (nc *CacheManager) isChangeApplied(event *model.ChangeEvent) bool {
+	if event.ID == "" {
+		return false
+	}
+	switch event.Resource {
+	case model.ChangeInstance:
+		if !nc.needLoad.Contains(types.InstanceName) {
+			return false
+		}
+		ins := nc.Instance().GetInstance(event.ID)
+		if event.Deleted {
+			return ins == nil
+		}
+		return event.Revision != "" && ins != nil && ins.Revision() == event.Revision
+	case model.ChangeService:
+		if !nc.needLoad.Contains(types.ServiceName) {
+			return false
+		}
+		svc := nc.Service().GetServiceByID(event.ID)
+		if event.Deleted {
+			return svc == nil
+		}
+		return event.Revision != "" && svc != nil && svc.Revision == event.Revision
+	default:
+		return false
+	}
+}
+
+// pollingCaches 没有变更事件覆盖的缓存
+func (nc *CacheManager) pollingCaches() []string {
+	entries := nc.needLoad.ToSlice()
+	ret := make([]string, 0, len(entries))
+	for _, name := range entries {
+		if _, ok := eventDrivenCaches[name]; !ok {
+			ret = append(ret, name)
+		}
+	}
+	return ret
+}
+
 // Clear 主动清除缓存数据
 func (nc *CacheManager) Clear() error {
 	return nc.clear()
@@ -157,6 +327,14 @@ func (nc *CacheManager) GetUpdateCacheInterval() time.Duration {
 	return UpdateCacheInterval
 }
 
+// GetFallbackInterval 获取开启存储变更通知后，由变更事件驱动的缓存兜底轮询的间隔
+func (nc *CacheManager) GetFallbackInterval() time.Duration {
+	if config != nil && config.FallbackInterval > 0 {
+		return config.FallbackInterval
+	}
+	return DefaultFallbackInterval
+}
+
 // Service 获取Service缓存信息
 func (nc *CacheManager) Service() types.ServiceCache {
 	return nc.caches[types.CacheService].(types.ServiceCache)
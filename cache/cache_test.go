@@ -0,0 +1,69 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package cache
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	types "github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+func TestCacheManager_ChangedCaches(t *testing.T) {
+	nc := &CacheManager{
+		caches:   make([]types.Cache, types.CacheLast),
+		needLoad: utils.NewSyncSet[string](),
+		changes:  make(chan []*model.ChangeEvent, 1),
+	}
+	nc.needLoad.Add(types.UsersName)
+	nc.needLoad.Add(types.StrategyRuleName)
+	nc.needLoad.Add(types.RoutingConfigName)
+	nc.needLoad.Add(types.NamespaceName)
+
+	sorted := func(names []string) []string {
+		sort.Strings(names)
+		return names
+	}
+
+	t.Run("只刷新变更资源对应的缓存", func(t *testing.T) {
+		targets := nc.changedCaches([]*model.ChangeEvent{
+			{Resource: model.ChangeUser, ID: "user-1"},
+			// 实例缓存未开启
+			{Resource: model.ChangeInstance, ID: "ins-1"},
+		})
+		assert.Equal(t, []string{types.StrategyRuleName, types.UsersName}, sorted(targets))
+	})
+
+	t.Run("没有变更事件覆盖的缓存继续轮询", func(t *testing.T) {
+		assert.Equal(t, []string{types.NamespaceName}, nc.pollingCaches())
+	})
+
+	t.Run("变更事件丢失时刷新全部由事件驱动的缓存", func(t *testing.T) {
+		nc.onChangeEvents([]*model.ChangeEvent{{Resource: model.ChangeStrategy}})
+		// 队列已满，触发全量刷新
+		nc.onChangeEvents([]*model.ChangeEvent{{Resource: model.ChangeStrategy}})
+		targets := nc.changedCaches(<-nc.changes)
+		assert.Equal(t, []string{types.RoutingConfigName, types.StrategyRuleName, types.UsersName}, sorted(targets))
+
+		targets = nc.changedCaches([]*model.ChangeEvent{{Resource: model.ChangeAll}})
+		assert.Equal(t, []string{types.RoutingConfigName, types.StrategyRuleName, types.UsersName}, sorted(targets))
+	})
+}
@@ -23,6 +23,8 @@ import "time"
 type Config struct {
 	// DiffTime 设置拉取时间范围, [T1 - abs(DiffTime), T1]
 	DiffTime time.Duration `yaml:"diffTime"`
+	// FallbackInterval 开启存储变更通知后，由变更事件驱动的缓存兜底轮询存储的时间间隔
+	FallbackInterval time.Duration `yaml:"fallbackInterval"`
 }
 
 var (
@@ -2214,32 +2214,32 @@ func (mr *MockConfigFileCacheMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockConfigFileCache)(nil).Close))
 }
 
-// GetActiveRelease mocks base method.
-func (m *MockConfigFileCache) GetActiveRelease(namespace, group, fileName string) *model.ConfigFileRelease {
+// GetActiveGrayRelease mocks base method.
+func (m *MockConfigFileCache) GetActiveGrayRelease(namespace, group, fileName string) *model.ConfigFileRelease {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetActiveRelease", namespace, group, fileName)
+	ret := m.ctrl.Call(m, "GetActiveGrayRelease", namespace, group, fileName)
 	ret0, _ := ret[0].(*model.ConfigFileRelease)
 	return ret0
 }
 
-// GetActiveRelease indicates an expected call of GetActiveRelease.
-func (mr *MockConfigFileCacheMockRecorder) GetActiveRelease(namespace, group, fileName interface{}) *gomock.Call {
+// GetActiveGrayRelease indicates an expected call of GetActiveGrayRelease.
+func (mr *MockConfigFileCacheMockRecorder) GetActiveGrayRelease(namespace, group, fileName interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveRelease", reflect.TypeOf((*MockConfigFileCache)(nil).GetActiveRelease), namespace, group, fileName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveGrayRelease", reflect.TypeOf((*MockConfigFileCache)(nil).GetActiveGrayRelease), namespace, group, fileName)
 }
 
-// GetActiveGrayRelease mocks base method.
-func (m *MockConfigFileCache) GetActiveGrayRelease(namespace, group, fileName string) *model.ConfigFileRelease {
+// GetActiveRelease mocks base method.
+func (m *MockConfigFileCache) GetActiveRelease(namespace, group, fileName string) *model.ConfigFileRelease {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetActiveGrayRelease", namespace, group, fileName)
+	ret := m.ctrl.Call(m, "GetActiveRelease", namespace, group, fileName)
 	ret0, _ := ret[0].(*model.ConfigFileRelease)
 	return ret0
 }
 
-// GetActiveGrayRelease indicates an expected call of GetActiveGrayRelease.
-func (mr *MockConfigFileCacheMockRecorder) GetActiveGrayRelease(namespace, group, fileName interface{}) *gomock.Call {
+// GetActiveRelease indicates an expected call of GetActiveRelease.
+func (mr *MockConfigFileCacheMockRecorder) GetActiveRelease(namespace, group, fileName interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveGrayRelease", reflect.TypeOf((*MockConfigFileCache)(nil).GetActiveGrayRelease), namespace, group, fileName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveRelease", reflect.TypeOf((*MockConfigFileCache)(nil).GetActiveRelease), namespace, group, fileName)
 }
 
 // GetGroupActiveReleases mocks base method.
@@ -2380,6 +2380,20 @@ func (mr *MockUserCacheMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockUserCache)(nil).Close))
 }
 
+// GetAccessToken mocks base method.
+func (m *MockUserCache) GetAccessToken(id string) *model.AccessToken {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessToken", id)
+	ret0, _ := ret[0].(*model.AccessToken)
+	return ret0
+}
+
+// GetAccessToken indicates an expected call of GetAccessToken.
+func (mr *MockUserCacheMockRecorder) GetAccessToken(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessToken", reflect.TypeOf((*MockUserCache)(nil).GetAccessToken), id)
+}
+
 // GetAdmin mocks base method.
 func (m *MockUserCache) GetAdmin() *model.User {
 	m.ctrl.T.Helper()
@@ -2655,6 +2669,20 @@ func (mr *MockStrategyCacheMockRecorder) IsResourceLinkStrategy(resType, resId i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsResourceLinkStrategy", reflect.TypeOf((*MockStrategyCache)(nil).IsResourceLinkStrategy), resType, resId)
 }
 
+// IsResourceOperable mocks base method.
+func (m *MockStrategyCache) IsResourceOperable(principal model.Principal, resType security.ResourceType, resId string, op model.ResourceOperation, method string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsResourceOperable", principal, resType, resId, op, method)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsResourceOperable indicates an expected call of IsResourceOperable.
+func (mr *MockStrategyCacheMockRecorder) IsResourceOperable(principal, resType, resId, op, method interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsResourceOperable", reflect.TypeOf((*MockStrategyCache)(nil).IsResourceOperable), principal, resType, resId, op, method)
+}
+
 // Name mocks base method.
 func (m *MockStrategyCache) Name() string {
 	m.ctrl.T.Helper()
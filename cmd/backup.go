@@ -0,0 +1,136 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	boot_config "github.com/polarismesh/polaris/bootstrap/config"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/store"
+	"github.com/polarismesh/polaris/store/migrate"
+)
+
+var (
+	backupConfig    = ""
+	backupOutput    = ""
+	backupInstances = false
+	restoreConfig   = ""
+	restoreInput    = ""
+	restorePolicy   = migrate.ConflictSkip
+
+	backupCmd = &cobra.Command{
+		Use:   "backup",
+		Short: "backup governance data to an archive",
+		Long: "write namespaces, services, instances, rules, service contracts, config files, releases, " +
+			"templates and auth objects to a versioned archive, the resources are read one after another " +
+			"and the archive is not a point-in-time snapshot, stop writes for an exact copy",
+		RunE: func(c *cobra.Command, args []string) error {
+			return runBackup()
+		},
+	}
+
+	restoreCmd = &cobra.Command{
+		Use:   "restore",
+		Short: "restore governance data from an archive",
+		Long: "restore the archive created by the backup command into the store, " +
+			"the existing data is handled by the conflict policy: skip, overwrite or fail",
+		RunE: func(c *cobra.Command, args []string) error {
+			return runRestore()
+		},
+	}
+)
+
+// init 解析命令参数
+func init() {
+	flags := backupCmd.Flags()
+	flags.StringVarP(&backupConfig, "config", "c", "polaris-server.yaml", "config file path")
+	flags.StringVarP(&backupOutput, "output", "o", "", "archive file path")
+	flags.BoolVar(&backupInstances, "instances", false, "include the service instances")
+	_ = backupCmd.MarkFlagRequired("output")
+
+	flags = restoreCmd.Flags()
+	flags.StringVarP(&restoreConfig, "config", "c", "polaris-server.yaml", "config file path")
+	flags.StringVarP(&restoreInput, "input", "i", "", "archive file path")
+	flags.StringVar(&restorePolicy, "policy", migrate.ConflictSkip,
+		"conflict policy for the existing data: skip, overwrite or fail")
+	_ = restoreCmd.MarkFlagRequired("input")
+}
+
+func runBackup() error {
+	s, err := openConfigStore(backupConfig)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = s.Destroy()
+	}()
+
+	f, err := os.Create(backupOutput)
+	if err != nil {
+		return err
+	}
+	manifest, err := migrate.Backup(s, f, migrate.BackupOptions{WithInstances: backupInstances})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(backupOutput)
+		return err
+	}
+	fmt.Printf("backup of store %s at %s written to %s\n", manifest.Store,
+		manifest.CreateTime.Format("2006-01-02 15:04:05"), backupOutput)
+	return nil
+}
+
+func runRestore() error {
+	f, err := os.Open(restoreInput)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	s, err := openConfigStore(restoreConfig)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = s.Destroy()
+	}()
+
+	results, err := migrate.Restore(s, f, migrate.RestoreOptions{Policy: restorePolicy})
+	for _, ret := range results {
+		fmt.Printf("[%s] total %d, written %d, skipped %d\n", ret.Resource, ret.Source, ret.Created, ret.Existed)
+	}
+	return err
+}
+
+// openConfigStore 打开配置文件中的存储插件
+func openConfigStore(path string) (store.Store, error) {
+	conf, err := boot_config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	plugin.SetPluginConfig(&conf.Plugin)
+	return openMigrateStore(&conf.Store)
+}
@@ -0,0 +1,182 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/polarismesh/polaris/admin/gitops"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+var (
+	gitopsServer = ""
+	gitopsToken  = ""
+	gitopsPath   = ""
+	gitopsDryRun = false
+
+	gitopsCmd = &cobra.Command{
+		Use:   "gitops",
+		Short: "reconcile governance resources with manifests",
+		Long: "reconcile the route rules, rate limits, circuit breaker rules and config files of a running " +
+			"polaris server with the YAML manifests in a directory or a zip/tar.gz archive",
+		RunE: func(c *cobra.Command, args []string) error {
+			return runGitOps()
+		},
+	}
+)
+
+// init 解析命令参数
+func init() {
+	flags := gitopsCmd.Flags()
+	flags.StringVar(&gitopsServer, "server", "http://127.0.0.1:8090", "http address of the polaris server")
+	flags.StringVar(&gitopsToken, "token", "", "auth token of the operator")
+	flags.StringVarP(&gitopsPath, "path", "p", "", "manifests directory or zip/tar.gz archive")
+	flags.BoolVar(&gitopsDryRun, "dry-run", false, "only print the plan")
+	_ = gitopsCmd.MarkFlagRequired("path")
+}
+
+func runGitOps() error {
+	archive, contentType, err := loadManifestsArchive(gitopsPath)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/maintain/v1/gitops/reconcile?dry_run=%t", strings.TrimRight(gitopsServer, "/"),
+		gitopsDryRun)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(archive))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if gitopsToken != "" {
+		req.Header.Set(utils.HeaderAuthTokenKey, gitopsToken)
+	}
+	client := &http.Client{Timeout: 5 * time.Minute}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+	body, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("reconcile fail, status %d: %s", rsp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	plan := &gitops.Plan{}
+	if err := json.Unmarshal(body, plan); err != nil {
+		return err
+	}
+	return printPlan(os.Stdout, plan)
+}
+
+// loadManifestsArchive 目录会被打包为 zip，归档文件原样上传
+func loadManifestsArchive(path string) ([]byte, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", err
+		}
+		if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+			return data, "application/gzip", nil
+		}
+		return data, "application/zip", nil
+	}
+
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// 跳过 .git 等隐藏目录
+			if file != path && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(file))
+		if ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		rel, err := filepath.Rel(path, file)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		f, err := w.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(content)
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "application/zip", nil
+}
+
+func printPlan(w io.Writer, plan *gitops.Plan) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "KIND\tKEY\tACTION\tDRIFT\tMESSAGE")
+	for _, change := range plan.Changes {
+		msg := change.Reason
+		if change.Error != "" {
+			msg = "error: " + change.Error
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\n", change.Kind, change.Key, change.Action, change.Drift, msg)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if plan.DryRun {
+		_, _ = fmt.Fprintln(w, "dry run, nothing changed")
+	}
+	if failed := plan.Failed(); failed > 0 {
+		return fmt.Errorf("%d change(s) failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	boot_config "github.com/polarismesh/polaris/bootstrap/config"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/store"
+	"github.com/polarismesh/polaris/store/migrate"
+)
+
+var (
+	migrateSourceConfig = ""
+	migrateTargetConfig = ""
+	migrateCheckpoint   = ""
+	migrateResume       = false
+	migrateVerifyOnly   = false
+	migrateBatchSize    = migrate.DefaultBatchSize
+
+	migrateCmd = &cobra.Command{
+		Use:   "migrate",
+		Short: "migrate data between stores",
+		Long: "copy namespaces, services, instances, rules, config files, releases, users and strategies " +
+			"from the store of the source config to the store of the target config, then verify the counts",
+		RunE: func(c *cobra.Command, args []string) error {
+			return runMigrate()
+		},
+	}
+)
+
+// init 解析命令参数
+func init() {
+	flags := migrateCmd.Flags()
+	flags.StringVarP(&migrateSourceConfig, "source", "s", "", "config file path of the source store")
+	flags.StringVarP(&migrateTargetConfig, "target", "t", "", "config file path of the target store")
+	flags.StringVar(&migrateCheckpoint, "checkpoint", "polaris-migrate.checkpoint", "checkpoint file path")
+	flags.BoolVar(&migrateResume, "resume", false, "resume from the checkpoint file")
+	flags.BoolVar(&migrateVerifyOnly, "verify-only", false, "only verify the target store")
+	flags.IntVar(&migrateBatchSize, "batch-size", migrate.DefaultBatchSize, "records per batch")
+	_ = migrateCmd.MarkFlagRequired("source")
+	_ = migrateCmd.MarkFlagRequired("target")
+}
+
+func runMigrate() error {
+	srcConf, err := boot_config.Load(migrateSourceConfig)
+	if err != nil {
+		return err
+	}
+	dstConf, err := boot_config.Load(migrateTargetConfig)
+	if err != nil {
+		return err
+	}
+	// 存储插件以单例的方式注册，源和目标不能是同一个插件
+	if srcConf.Store.Name == dstConf.Store.Name {
+		return errors.New("source and target must use different store plugins")
+	}
+	plugin.SetPluginConfig(&dstConf.Plugin)
+
+	src, err := openMigrateStore(&srcConf.Store)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = src.Destroy()
+	}()
+	dst, err := openMigrateStore(&dstConf.Store)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = dst.Destroy()
+	}()
+
+	m := migrate.NewMigrator(src, dst, migrate.Options{
+		Checkpoint: migrateCheckpoint,
+		Resume:     migrateResume,
+		BatchSize:  migrateBatchSize,
+		Out:        os.Stdout,
+	})
+	if !migrateVerifyOnly {
+		if _, err := m.Run(); err != nil {
+			return err
+		}
+	}
+	results, err := m.Verify()
+	if err != nil {
+		return err
+	}
+	return migrate.PrintResults(os.Stdout, results)
+}
+
+func openMigrateStore(conf *store.Config) (store.Store, error) {
+	s, ok := store.StoreSlots[conf.Name]
+	if !ok {
+		return nil, fmt.Errorf("store `%s` not found", conf.Name)
+	}
+	if err := s.Initialize(conf); err != nil {
+		return nil, fmt.Errorf("initialize store `%s` fail: %w", conf.Name, err)
+	}
+	return s, nil
+}
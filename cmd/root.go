@@ -35,6 +35,10 @@ func init() {
 	rootCmd.AddCommand(startCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(revisionCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(gitopsCmd)
 }
 
 // Execute 执行命令行解析
@@ -0,0 +1,150 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package eventhub
+
+import (
+	"context"
+	"time"
+
+	"github.com/polarismesh/polaris/common/log"
+)
+
+const (
+	durableReadBatch  = 128
+	durableRetryDelay = time.Second
+)
+
+// durableSubscription subscription read events from the topic log, and commit the offset after handled,
+// so the subscriber can resume from the last handled event after restart
+type durableSubscription struct {
+	name     string
+	topic    string
+	log      *topicLog
+	codec    Codec
+	handler  Handler
+	offset   uint64
+	notifyCh chan struct{}
+	closeCh  chan struct{}
+}
+
+func newDurableSubscription(t *topic, handler Handler, opts *SubOptions) (*durableSubscription, error) {
+	sub := &durableSubscription{
+		name:     opts.Durable,
+		topic:    t.name,
+		log:      t.log,
+		codec:    t.codec,
+		handler:  handler,
+		notifyCh: make(chan struct{}, 1),
+		closeCh:  make(chan struct{}),
+	}
+	if opts.StartOffset > 0 {
+		sub.offset = opts.StartOffset
+		return sub, nil
+	}
+	committed, exist, err := t.log.committed(sub.name)
+	if err != nil {
+		return nil, err
+	}
+	if exist {
+		sub.offset = committed + 1
+		return sub, nil
+	}
+	// 首次订阅，从最新的事件开始消费
+	last, err := t.log.lastOffset()
+	if err != nil {
+		return nil, err
+	}
+	sub.offset = last + 1
+	return sub, nil
+}
+
+// notify wake up the subscription when new event appended
+func (s *durableSubscription) notify() {
+	select {
+	case s.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *durableSubscription) receive(ctx context.Context) {
+	for {
+		handled, err := s.consume(ctx)
+		if err != nil {
+			log.Errorf("[EventHub] durable subscription:%s topic:%s consume error:%s", s.name, s.topic, err.Error())
+		}
+		if handled > 0 && err == nil {
+			continue
+		}
+		var retryCh <-chan time.Time
+		if err != nil {
+			retryCh = time.After(durableRetryDelay)
+		}
+		select {
+		case <-s.notifyCh:
+		case <-retryCh:
+		case <-s.closeCh:
+			log.Infof("[EventHub] durable subscription:%s receive close", s.name)
+			return
+		case <-ctx.Done():
+			log.Infof("[EventHub] durable subscription:%s receive close by context cancel", s.name)
+			return
+		}
+	}
+}
+
+// consume read a batch of events from the log and handle them, return the count of handled events
+func (s *durableSubscription) consume(ctx context.Context) (int, error) {
+	entries, err := s.log.read(s.offset, durableReadBatch)
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) > 0 && entries[0].offset > s.offset {
+		log.Warnf("[EventHub] durable subscription:%s topic:%s events %d-%d were dropped before consumed",
+			s.name, s.topic, s.offset, entries[0].offset-1)
+	}
+	for i := range entries {
+		select {
+		case <-s.closeCh:
+			return i, nil
+		case <-ctx.Done():
+			return i, nil
+		default:
+		}
+		entry := entries[i]
+		event, err := s.codec.Decode(entry.data)
+		if err != nil {
+			log.Errorf("[EventHub] durable subscription:%s topic:%s decode event %d error:%s",
+				s.name, s.topic, entry.offset, err.Error())
+		} else {
+			event = s.handler.PreProcess(ctx, event)
+			if err := s.handler.OnEvent(ctx, event); err != nil {
+				log.Errorf("[EventHub] durable subscription:%s handler event %d error:%s",
+					s.name, entry.offset, err.Error())
+			}
+		}
+		if err := s.log.commit(s.name, entry.offset); err != nil {
+			return i, err
+		}
+		s.offset = entry.offset + 1
+	}
+	return len(entries), nil
+}
+
+func (s *durableSubscription) close() {
+	close(s.closeCh)
+}
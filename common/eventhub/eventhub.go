@@ -29,7 +29,8 @@ var (
 )
 
 var (
-	ErrorEventhubNotInitialize = errors.New("eventhub not initialize")
+	ErrorEventhubNotInitialize    = errors.New("eventhub not initialize")
+	ErrorDurableSubscriptionExist = errors.New("durable subscription already exist")
 )
 
 // InitEventHub initialize event hub
@@ -67,7 +68,10 @@ func RegisterPublisher(topic string, opt PublishOption) error {
 }
 
 func (eh *eventHub) RegisterPublisher(topic string, opt PublishOption) error {
-	_ = eh.createTopic(topic, opt)
+	t := eh.createTopic(topic, opt)
+	if opt.Persistent != nil {
+		return t.enablePersistent(opt.Persistent)
+	}
 	return nil
 }
 
@@ -87,6 +91,19 @@ func (eh *eventHub) Publish(topic string, event Event) error {
 	return nil
 }
 
+// CommittedOffset the offset which durable subscription has handled
+func CommittedOffset(topic string, durable string) (uint64, error) {
+	if globalEventHub == nil {
+		return 0, ErrorEventhubNotInitialize
+	}
+	t := globalEventHub.loadOrStoreTopic(topic)
+	if !t.persistent() {
+		return 0, ErrorTopicNotPersistent
+	}
+	offset, _, err := t.log.committed(durable)
+	return offset, err
+}
+
 // Subscribe subscribe topic
 func Subscribe(topic string, handler Handler, opts ...SubOption) (*SubscribtionContext, error) {
 	if globalEventHub == nil {
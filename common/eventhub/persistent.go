@@ -0,0 +1,260 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package eventhub
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+const (
+	// DefaultMaxEntries default max events kept in the topic log
+	DefaultMaxEntries = 100000
+
+	eventsBucket  = "events"
+	offsetsBucket = "offsets"
+)
+
+var (
+	ErrorTopicNotPersistent = errors.New("topic is not persistent")
+	ErrorCodecNotFound      = errors.New("codec of persistent topic not found")
+)
+
+// Codec encode and decode the events of the persistent topic
+type Codec interface {
+	// Encode encode event to bytes
+	Encode(Event) ([]byte, error)
+	// Decode decode bytes to event
+	Decode([]byte) (Event, error)
+}
+
+// JSONCodec encode event by json, decode the bytes to the type T
+type JSONCodec[T any] struct {
+}
+
+// Encode encode event to bytes
+func (c JSONCodec[T]) Encode(event Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// Decode decode bytes to event
+func (c JSONCodec[T]) Decode(data []byte) (Event, error) {
+	var event T
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// builtinCodecs codecs of the builtin topics
+var builtinCodecs = map[string]Codec{
+	InstanceEventTopic:     JSONCodec[model.InstanceEvent]{},
+	ConfigFilePublishTopic: JSONCodec[*PublishConfigFileEvent]{},
+}
+
+// Config eventhub config
+type Config struct {
+	// PersistentDir directory to save the logs of persistent topics
+	PersistentDir string `yaml:"persistentDir"`
+	// MaxEntries max events kept in each topic log
+	MaxEntries int `yaml:"maxEntries"`
+	// PersistentTopics topics which need to keep the events on disk
+	PersistentTopics []string `yaml:"persistentTopics"`
+}
+
+// PersistentOption persistent topic option
+type PersistentOption struct {
+	// Dir directory to save the topic log
+	Dir string
+	// MaxEntries max events kept in the topic log, the oldest events are dropped when exceed
+	MaxEntries int
+	// Codec encode and decode events, builtin topic use the builtin codec when empty
+	Codec Codec
+}
+
+// InitPersistentTopics register the persistent topics in the config
+func InitPersistentTopics(cfg *Config) error {
+	if cfg == nil || len(cfg.PersistentTopics) == 0 {
+		return nil
+	}
+	for _, name := range cfg.PersistentTopics {
+		if err := RegisterPublisher(name, PublishOption{
+			Persistent: &PersistentOption{
+				Dir:        cfg.PersistentDir,
+				MaxEntries: cfg.MaxEntries,
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// logEntry one event in topic log
+type logEntry struct {
+	offset uint64
+	data   []byte
+}
+
+// topicLog bounded on-disk log of one topic, also keeps the offsets of the durable subscribers
+type topicLog struct {
+	db         *bolt.DB
+	maxEntries int
+}
+
+func openTopicLog(name string, opt *PersistentOption) (*topicLog, error) {
+	dir := opt.Dir
+	if dir == "" {
+		dir = "./eventhub"
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(filepath.Join(dir, name+".log"), 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(eventsBucket)); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists([]byte(offsetsBucket))
+		return err
+	}); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	maxEntries := opt.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &topicLog{db: db, maxEntries: maxEntries}, nil
+}
+
+// append save the event to log and drop the oldest events when exceed the max entries
+func (l *topicLog) append(data []byte) (uint64, error) {
+	var offset uint64
+	err := l.db.Batch(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(eventsBucket))
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		offset = seq
+		if err := bucket.Put(encodeOffset(offset), data); err != nil {
+			return err
+		}
+		if offset <= uint64(l.maxEntries) {
+			return nil
+		}
+		// 超过上限，删除最旧的事件
+		minOffset := offset - uint64(l.maxEntries) + 1
+		cursor := bucket.Cursor()
+		for k, _ := cursor.First(); k != nil && decodeOffset(k) < minOffset; k, _ = cursor.Next() {
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return offset, err
+}
+
+// read read at most limit events whose offset >= from
+func (l *topicLog) read(from uint64, limit int) ([]logEntry, error) {
+	entries := make([]logEntry, 0, limit)
+	err := l.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket([]byte(eventsBucket)).Cursor()
+		for k, v := cursor.Seek(encodeOffset(from)); k != nil && len(entries) < limit; k, v = cursor.Next() {
+			data := make([]byte, len(v))
+			copy(data, v)
+			entries = append(entries, logEntry{offset: decodeOffset(k), data: data})
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// firstOffset the oldest offset kept in the log, return 0 when log is empty
+func (l *topicLog) firstOffset() (uint64, error) {
+	var offset uint64
+	err := l.db.View(func(tx *bolt.Tx) error {
+		k, _ := tx.Bucket([]byte(eventsBucket)).Cursor().First()
+		if k != nil {
+			offset = decodeOffset(k)
+		}
+		return nil
+	})
+	return offset, err
+}
+
+// lastOffset the newest offset of the log
+func (l *topicLog) lastOffset() (uint64, error) {
+	var offset uint64
+	err := l.db.View(func(tx *bolt.Tx) error {
+		offset = tx.Bucket([]byte(eventsBucket)).Sequence()
+		return nil
+	})
+	return offset, err
+}
+
+// commit save the offset which the subscriber has handled
+func (l *topicLog) commit(subscriber string, offset uint64) error {
+	return l.db.Batch(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(offsetsBucket)).Put([]byte(subscriber), encodeOffset(offset))
+	})
+}
+
+// committed the offset which the subscriber has handled
+func (l *topicLog) committed(subscriber string) (uint64, bool, error) {
+	var (
+		offset uint64
+		exist  bool
+	)
+	err := l.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket([]byte(offsetsBucket)).Get([]byte(subscriber))
+		if v != nil {
+			offset = decodeOffset(v)
+			exist = true
+		}
+		return nil
+	})
+	return offset, exist, err
+}
+
+func (l *topicLog) close() error {
+	return l.db.Close()
+}
+
+func encodeOffset(offset uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, offset)
+	return buf
+}
+
+func decodeOffset(data []byte) uint64 {
+	return binary.BigEndian.Uint64(data)
+}
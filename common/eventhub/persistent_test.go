@@ -0,0 +1,163 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package eventhub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+type testEvent struct {
+	Seq int `json:"seq"`
+}
+
+func waitEvents(t *testing.T, ch chan testEvent, n int) []int {
+	ret := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		select {
+		case e := <-ch:
+			ret = append(ret, e.Seq)
+		case <-time.After(3 * time.Second):
+			t.Fatalf("wait event timeout, received %v", ret)
+		}
+	}
+	return ret
+}
+
+func newPersistentHub(t *testing.T, dir string, maxEntries int) *eventHub {
+	eh := createEventhub()
+	err := eh.RegisterPublisher("durable", PublishOption{
+		Persistent: &PersistentOption{
+			Dir:        dir,
+			MaxEntries: maxEntries,
+			Codec:      JSONCodec[testEvent]{},
+		},
+	})
+	assert.NoError(t, err)
+	return eh
+}
+
+func subscribeChan(t *testing.T, eh *eventHub, opts ...SubOption) (chan testEvent, *SubscribtionContext) {
+	ch := make(chan testEvent, 64)
+	subCtx, err := eh.Subscribe("durable", &funcSubscriber{handlerFunc: func(ctx context.Context, a any) error {
+		ch <- a.(testEvent)
+		return nil
+	}}, opts...)
+	assert.NoError(t, err)
+	return ch, subCtx
+}
+
+func TestEventHub_DurableResume(t *testing.T) {
+	dir := t.TempDir()
+	eh := newPersistentHub(t, dir, 100)
+	ch, subCtx := subscribeChan(t, eh, WithDurable("sub1"))
+
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, eh.Publish("durable", testEvent{Seq: i}))
+	}
+	assert.Equal(t, []int{1, 2, 3}, waitEvents(t, ch, 3))
+
+	// same durable name can not subscribe twice
+	_, err := eh.Subscribe("durable", &printEventHandler{}, WithDurable("sub1"))
+	assert.Equal(t, ErrorDurableSubscriptionExist, err)
+
+	subCtx.Cancel()
+	// events published while the subscriber is offline
+	for i := 4; i <= 5; i++ {
+		assert.NoError(t, eh.Publish("durable", testEvent{Seq: i}))
+	}
+	eh.shutdown()
+
+	// restart, subscriber resume from the last committed offset
+	eh = newPersistentHub(t, dir, 100)
+	defer eh.shutdown()
+	ch, _ = subscribeChan(t, eh, WithDurable("sub1"))
+	assert.Equal(t, []int{4, 5}, waitEvents(t, ch, 2))
+
+	// replay from the specific offset
+	replay, _ := subscribeChan(t, eh, WithDurable("replay"), WithStartOffset(2))
+	assert.Equal(t, []int{2, 3, 4, 5}, waitEvents(t, replay, 4))
+}
+
+func TestEventHub_DurableBounded(t *testing.T) {
+	eh := newPersistentHub(t, t.TempDir(), 3)
+	defer eh.shutdown()
+	for i := 1; i <= 5; i++ {
+		assert.NoError(t, eh.Publish("durable", testEvent{Seq: i}))
+	}
+	tp := eh.loadOrStoreTopic("durable")
+	first, err := tp.log.firstOffset()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), first)
+
+	// dropped events are skipped
+	ch, _ := subscribeChan(t, eh, WithDurable("sub1"), WithStartOffset(1))
+	assert.Equal(t, []int{3, 4, 5}, waitEvents(t, ch, 3))
+}
+
+func TestEventHub_DurableNotPersistent(t *testing.T) {
+	eh := createEventhub()
+	defer eh.shutdown()
+	// durable subscription degrade to memory subscription
+	ch := make(chan int, 1)
+	_, err := eh.Subscribe("memory", &funcSubscriber{handlerFunc: func(ctx context.Context, a any) error {
+		ch <- a.(int)
+		return nil
+	}}, WithDurable("sub1"))
+	assert.NoError(t, err)
+	assert.NoError(t, eh.Publish("memory", 1))
+	select {
+	case v := <-ch:
+		assert.Equal(t, 1, v)
+	case <-time.After(3 * time.Second):
+		t.Fatal("wait event timeout")
+	}
+
+	err = eh.RegisterPublisher("unknown", PublishOption{Persistent: &PersistentOption{Dir: t.TempDir()}})
+	assert.Equal(t, ErrorCodecNotFound, err)
+}
+
+func TestBuiltinCodec_InstanceEvent(t *testing.T) {
+	codec := builtinCodecs[InstanceEventTopic]
+	data, err := codec.Encode(model.InstanceEvent{
+		Id:        "ins-1",
+		Namespace: "default",
+		Service:   "svc",
+		Instance: &apiservice.Instance{
+			Host: wrapperspb.String("127.0.0.1"),
+			Port: wrapperspb.UInt32(8080),
+		},
+		EType: model.EventInstanceOnline,
+	})
+	assert.NoError(t, err)
+	event, err := codec.Decode(data)
+	assert.NoError(t, err)
+	insEvent, ok := event.(model.InstanceEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "ins-1", insEvent.Id)
+	assert.Equal(t, "127.0.0.1", insEvent.Instance.GetHost().GetValue())
+	assert.Equal(t, uint32(8080), insEvent.Instance.GetPort().GetValue())
+	assert.Equal(t, model.EventInstanceOnline, insEvent.EType)
+}
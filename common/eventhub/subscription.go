@@ -136,6 +136,11 @@ type SubOption func(s *SubOptions)
 // SubOptions subscripion options
 type SubOptions struct {
 	QueueSize int
+	// Durable name of durable subscription, it will consume events from the topic log
+	// and resume from the last committed offset, only works on persistent topic
+	Durable string
+	// StartOffset offset which durable subscription start to consume, 0 means the last committed offset
+	StartOffset uint64
 }
 
 // WithQueueSize set event queue size
@@ -145,7 +150,23 @@ func WithQueueSize(size int) SubOption {
 	}
 }
 
+// WithDurable subscribe topic with durable name
+func WithDurable(name string) SubOption {
+	return func(s *SubOptions) {
+		s.Durable = name
+	}
+}
+
+// WithStartOffset set the offset which durable subscription start to consume
+func WithStartOffset(offset uint64) SubOption {
+	return func(s *SubOptions) {
+		s.StartOffset = offset
+	}
+}
+
 // PublishOption .
 type PublishOption struct {
 	WaitHaveSub bool
+	// Persistent keep the events of topic on disk when not nil
+	Persistent *PersistentOption
 }
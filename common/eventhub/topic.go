@@ -29,37 +29,100 @@ import (
 //go:generate gotests -w -all topic.go
 
 type topic struct {
-	name    string
-	queue   chan Event
-	closeCh chan struct{}
-	subs    map[string]*subscription
-	mu      sync.RWMutex
+	name     string
+	queue    chan Event
+	closeCh  chan struct{}
+	subs     map[string]*subscription
+	durables map[string]*durableSubscription
+	// log 持久化 topic 的事件日志，为空时事件只保存在内存中
+	log   *topicLog
+	codec Codec
+	mu    sync.RWMutex
 }
 
 func newTopic(name string) *topic {
 	t := &topic{
-		name:    name,
-		queue:   make(chan Event, defaultQueueSize),
-		closeCh: make(chan struct{}),
-		subs:    make(map[string]*subscription),
+		name:     name,
+		queue:    make(chan Event, defaultQueueSize),
+		closeCh:  make(chan struct{}),
+		subs:     make(map[string]*subscription),
+		durables: make(map[string]*durableSubscription),
 	}
 	return t
 }
 
+// enablePersistent keep the events of topic on disk
+func (t *topic) enablePersistent(opt *PersistentOption) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.log != nil {
+		return nil
+	}
+	codec := opt.Codec
+	if codec == nil {
+		codec = builtinCodecs[t.name]
+	}
+	if codec == nil {
+		return ErrorCodecNotFound
+	}
+	topicLog, err := openTopicLog(t.name, opt)
+	if err != nil {
+		return err
+	}
+	t.log = topicLog
+	t.codec = codec
+	log.Infof("[EventHub] topic:%s enable persistent, dir:%s", t.name, opt.Dir)
+	return nil
+}
+
+func (t *topic) persistent() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.log != nil
+}
+
 // publish publish msg to topic
 func (t *topic) publish(ctx context.Context, event Event) {
 	if log.DebugEnabled() {
 		log.Debugf("[EventHub] publish topic:%s, event:%v", t.name, event)
 	}
+	if t.persistent() {
+		t.append(event)
+	}
 	t.queue <- event
 }
 
+// append save event to topic log and wake up the durable subscriptions
+func (t *topic) append(event Event) {
+	data, err := t.codec.Encode(event)
+	if err != nil {
+		log.Errorf("[EventHub] topic:%s encode event error:%s", t.name, err.Error())
+		return
+	}
+	if _, err := t.log.append(data); err != nil {
+		log.Errorf("[EventHub] topic:%s append event to log error:%s", t.name, err.Error())
+		return
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, sub := range t.durables {
+		sub.notify()
+	}
+}
+
 // subscribe subscribe msg from topic
 func (t *topic) subscribe(ctx context.Context, handler Handler,
 	opts ...SubOption) (*SubscribtionContext, error) {
 
 	subID := uuid.NewString()
 	sub := newSubscription(subID, handler, opts...)
+	if sub.opts.Durable != "" {
+		if t.persistent() {
+			return t.subscribeDurable(ctx, handler, sub.opts)
+		}
+		log.Warnf("[EventHub] topic:%s is not persistent, durable subscription:%s degrade to memory",
+			t.name, sub.opts.Durable)
+	}
 
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -78,6 +141,38 @@ func (t *topic) subscribe(ctx context.Context, handler Handler,
 	return subscribtionCtx, nil
 }
 
+// subscribeDurable subscribe msg from topic log, resume from the last committed offset
+func (t *topic) subscribeDurable(ctx context.Context, handler Handler,
+	opts *SubOptions) (*SubscribtionContext, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.durables[opts.Durable]; ok {
+		return nil, ErrorDurableSubscriptionExist
+	}
+	sub, err := newDurableSubscription(t, handler, opts)
+	if err != nil {
+		return nil, err
+	}
+	t.durables[sub.name] = sub
+
+	newCtx, cancel := context.WithCancel(ctx)
+	subscribtionCtx := &SubscribtionContext{
+		subID: sub.name,
+		cancel: func() {
+			cancel()
+			t.unsubscribeDurable(sub.name)
+		},
+	}
+	go sub.receive(newCtx)
+	return subscribtionCtx, nil
+}
+
+func (t *topic) unsubscribeDurable(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.durables, name)
+}
+
 // unsubscribe unsubscrib msg from topic
 func (t *topic) unsubscribe(name string) {
 	sub, ok := t.subs[name]
@@ -98,6 +193,15 @@ func (t *topic) close(ctx context.Context) {
 		sub.close()
 		delete(t.subs, sub.name)
 	}
+	for _, sub := range t.durables {
+		sub.close()
+		delete(t.durables, sub.name)
+	}
+	if t.log != nil {
+		if err := t.log.close(); err != nil {
+			log.Errorf("[EventHub] topic:%s close log error:%s", t.name, err.Error())
+		}
+	}
 }
 
 // run read msg from topic queue and send to all subscription
@@ -33,6 +33,8 @@ const (
 	CacheClientEventTopic = "cache_client_event"
 	// CacheNamespaceEventTopic record cache occur namespace add/update/del event
 	CacheNamespaceEventTopic = "cache_namespace_event"
+	// CacheResourceUpdateTopic record cache resource has been updated from store
+	CacheResourceUpdateTopic = "cache_resource_update"
 )
 
 // PublishConfigFileEvent 事件对象，包含类型和事件消息
@@ -62,6 +64,12 @@ type CacheClientEvent struct {
 	EventType EventType
 }
 
+// CacheResourceUpdateEvent the cache named Name has loaded Total changed records from store
+type CacheResourceUpdateEvent struct {
+	Name  string
+	Total int64
+}
+
 type CacheNamespaceEvent struct {
 	OldItem   *model.Namespace
 	Item      *model.Namespace
@@ -0,0 +1,361 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package ldap
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/polarismesh/polaris/common/ldap/internal/protocol"
+)
+
+// 查询范围
+const (
+	ScopeBaseObject   = 0
+	ScopeSingleLevel  = 1
+	ScopeWholeSubtree = 2
+)
+
+// 常用的结果码
+const (
+	ResultSuccess            = 0
+	ResultSizeLimitExceeded  = 4
+	ResultNoSuchObject       = 32
+	ResultInvalidCredentials = 49
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Error LDAP 服务端返回的错误结果
+type Error struct {
+	ResultCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("ldap: result code %d: %s", e.ResultCode, e.Message)
+}
+
+// IsInvalidCredentials 判断是否为账号或密码错误
+func IsInvalidCredentials(err error) bool {
+	var ldapErr *Error
+	return errors.As(err, &ldapErr) && ldapErr.ResultCode == ResultInvalidCredentials
+}
+
+// Config LDAP 连接配置
+type Config struct {
+	// URL ldap://host:389 或者 ldaps://host:636
+	URL string
+	// StartTLS 使用 ldap:// 连接时是否通过 StartTLS 升级为加密连接
+	StartTLS bool
+	// TLSConfig ldaps 以及 StartTLS 使用的 tls 配置
+	TLSConfig *tls.Config
+	// Timeout 建立连接以及每次请求的超时时间
+	Timeout time.Duration
+}
+
+// SearchRequest 查询请求
+type SearchRequest struct {
+	BaseDN     string
+	Scope      int
+	Filter     string
+	Attributes []string
+	SizeLimit  int
+	// PageSize 大于 0 时分页查询，避免查询结果超过服务端的数量限制，例如 Active Directory 的 MaxPageSize
+	PageSize int
+}
+
+// Entry 查询结果中的一个条目，属性名统一为小写
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Values 获取属性的所有值
+func (e *Entry) Values(name string) []string {
+	return e.Attributes[strings.ToLower(name)]
+}
+
+// Value 获取属性的第一个值
+func (e *Entry) Value(name string) string {
+	values := e.Values(name)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Conn LDAP 连接，请求串行执行，不支持并发使用
+type Conn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	msgID   int64
+	timeout time.Duration
+}
+
+// Dial 建立 LDAP 连接
+func Dial(cfg Config) (*Conn, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	host := u.Host
+	tlsConfig := cfg.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	switch u.Scheme {
+	case "ldap":
+		if u.Port() == "" {
+			host = net.JoinHostPort(host, "389")
+		}
+		conn, err = dialer.Dial("tcp", host)
+	case "ldaps":
+		if u.Port() == "" {
+			host = net.JoinHostPort(host, "636")
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", host, tlsConfig)
+	default:
+		return nil, fmt.Errorf("ldap: unsupported url scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Conn{conn: conn, reader: bufio.NewReader(conn), timeout: timeout}
+	if cfg.StartTLS && u.Scheme == "ldap" {
+		if err := c.startTLS(tlsConfig); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Bind 使用简单认证绑定身份，密码为空时直接拒绝，避免被服务端当作匿名绑定而认证成功
+func (c *Conn) Bind(dn, password string) error {
+	if dn != "" && password == "" {
+		return &Error{ResultCode: ResultInvalidCredentials, Message: "empty password"}
+	}
+	req := protocol.NewSequence(protocol.OpBindRequest,
+		protocol.NewInt(protocol.TagInteger, 3),
+		protocol.NewString(protocol.TagOctetString, dn),
+		protocol.NewString(protocol.AuthSimple, password),
+	)
+	resp, err := c.roundTrip(req)
+	if err != nil {
+		return err
+	}
+	return checkResult(resp, protocol.OpBindResponse)
+}
+
+// Search 执行查询，设置了 PageSize 时使用分页查询控制项逐页读取，直到服务端返回空的游标
+func (c *Conn) Search(req *SearchRequest) ([]*Entry, error) {
+	filter, err := compileFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	attrs := protocol.NewSequence(protocol.ClassUniversal | protocol.TagSequence)
+	for _, attr := range req.Attributes {
+		attrs.Append(protocol.NewString(protocol.TagOctetString, attr))
+	}
+	op := protocol.NewSequence(protocol.OpSearchRequest,
+		protocol.NewString(protocol.TagOctetString, req.BaseDN),
+		protocol.NewInt(protocol.TagEnumerated, int64(req.Scope)),
+		protocol.NewInt(protocol.TagEnumerated, 0),
+		protocol.NewInt(protocol.TagInteger, int64(req.SizeLimit)),
+		protocol.NewInt(protocol.TagInteger, int64(c.timeout/time.Second)),
+		protocol.NewBool(protocol.TagBoolean, false),
+		filter,
+		attrs,
+	)
+
+	var (
+		entries []*Entry
+		cookie  string
+	)
+	for {
+		var controls []*protocol.Packet
+		if req.PageSize > 0 {
+			controls = append(controls, protocol.NewPagedResultsControl(int64(req.PageSize), cookie, false))
+		}
+		page, done, err := c.searchPage(op, controls)
+		entries = append(entries, page...)
+		if err != nil {
+			return entries, err
+		}
+		if req.PageSize <= 0 {
+			return entries, nil
+		}
+		_, next, ok, err := protocol.ParsePagedResultsControl(done)
+		if err != nil {
+			return nil, err
+		}
+		// 服务端不支持分页时会忽略非关键的控制项，一次返回全部结果
+		if !ok || next == "" {
+			return entries, nil
+		}
+		cookie = next
+	}
+}
+
+// searchPage 发送一次查询请求，读取查询结果直到 SearchResultDone，返回查询结果以及 SearchResultDone 所在的消息
+func (c *Conn) searchPage(op *protocol.Packet, controls []*protocol.Packet) ([]*Entry, *protocol.Packet, error) {
+	msgID, err := c.send(op, controls...)
+	if err != nil {
+		return nil, nil, err
+	}
+	var entries []*Entry
+	for {
+		msg, err := c.receive(msgID)
+		if err != nil {
+			return nil, nil, err
+		}
+		resp := msg.Child(1)
+		switch resp.Tag {
+		case protocol.OpSearchResultEntry:
+			entries = append(entries, parseEntry(resp))
+		case protocol.OpSearchResultReference:
+			// 不跟随引用
+		case protocol.OpSearchResultDone:
+			return entries, msg, checkResult(resp, protocol.OpSearchResultDone)
+		default:
+			return nil, nil, fmt.Errorf("ldap: unexpected search response tag 0x%x", resp.Tag)
+		}
+	}
+}
+
+// Close 发送 unbind 请求并关闭连接
+func (c *Conn) Close() error {
+	_, _ = c.send(&protocol.Packet{Tag: protocol.OpUnbindRequest})
+	return c.conn.Close()
+}
+
+func (c *Conn) startTLS(tlsConfig *tls.Config) error {
+	resp, err := c.roundTrip(protocol.NewSequence(protocol.OpExtendedRequest, protocol.NewString(protocol.ClassContext|0, protocol.OIDStartTLS)))
+	if err != nil {
+		return err
+	}
+	if err := checkResult(resp, protocol.OpExtendedResponse); err != nil {
+		return err
+	}
+	tlsConn := tls.Client(c.conn, tlsConfig)
+	_ = tlsConn.SetDeadline(time.Now().Add(c.timeout))
+	if err := tlsConn.Handshake(); err != nil {
+		return err
+	}
+	c.conn = tlsConn
+	c.reader = bufio.NewReader(tlsConn)
+	return nil
+}
+
+func (c *Conn) roundTrip(op *protocol.Packet) (*protocol.Packet, error) {
+	msgID, err := c.send(op)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := c.receive(msgID)
+	if err != nil {
+		return nil, err
+	}
+	return msg.Child(1), nil
+}
+
+func (c *Conn) send(op *protocol.Packet, controls ...*protocol.Packet) (int64, error) {
+	c.msgID++
+	msg := protocol.NewSequence(protocol.ClassUniversal|protocol.TagSequence,
+		protocol.NewInt(protocol.TagInteger, c.msgID), op)
+	if len(controls) > 0 {
+		msg.Append(protocol.NewSequence(protocol.TagControls, controls...))
+	}
+	_ = c.conn.SetDeadline(time.Now().Add(c.timeout))
+	if _, err := c.conn.Write(msg.Bytes()); err != nil {
+		return 0, err
+	}
+	return c.msgID, nil
+}
+
+// receive 读取指定请求的响应消息
+func (c *Conn) receive(msgID int64) (*protocol.Packet, error) {
+	_ = c.conn.SetDeadline(time.Now().Add(c.timeout))
+	for {
+		msg, err := protocol.ReadPacket(c.reader)
+		if err != nil {
+			return nil, err
+		}
+		if len(msg.Children) < 2 {
+			return nil, errors.New("ldap: malformed message")
+		}
+		id, err := msg.Child(0).Int()
+		if err != nil {
+			return nil, err
+		}
+		// 忽略其他消息，例如服务端主动发送的 Notice of Disconnection
+		if id == msgID {
+			return msg, nil
+		}
+		if id == 0 {
+			return nil, errors.New("ldap: server closed the connection")
+		}
+	}
+}
+
+func checkResult(resp *protocol.Packet, expectTag byte) error {
+	if resp.Tag != expectTag {
+		return fmt.Errorf("ldap: unexpected response tag 0x%x", resp.Tag)
+	}
+	code, err := resp.Child(0).Int()
+	if err != nil {
+		return err
+	}
+	if code != ResultSuccess {
+		return &Error{ResultCode: int(code), Message: resp.Child(2).String()}
+	}
+	return nil
+}
+
+func parseEntry(resp *protocol.Packet) *Entry {
+	entry := &Entry{
+		DN:         resp.Child(0).String(),
+		Attributes: map[string][]string{},
+	}
+	for _, attr := range resp.Child(1).Children {
+		name := strings.ToLower(attr.Child(0).String())
+		for _, val := range attr.Child(1).Children {
+			entry.Attributes[name] = append(entry.Attributes[name], val.String())
+		}
+	}
+	return entry
+}
@@ -0,0 +1,192 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package ldap
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/polarismesh/polaris/common/ldap/internal/protocol"
+)
+
+// EscapeFilter 对过滤条件中的值进行转义，避免 LDAP 注入
+func EscapeFilter(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch c {
+		case '\\', '*', '(', ')', 0:
+			fmt.Fprintf(&b, "\\%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// compileFilter 将 RFC 4515 格式的过滤条件编译为 BER 编码
+func compileFilter(filter string) (*protocol.Packet, error) {
+	filter = strings.TrimSpace(filter)
+	if !strings.HasPrefix(filter, "(") {
+		filter = "(" + filter + ")"
+	}
+	p, pos, err := parseFilter(filter, 0)
+	if err != nil {
+		return nil, err
+	}
+	if pos != len(filter) {
+		return nil, fmt.Errorf("ldap: unexpected filter content %q", filter[pos:])
+	}
+	return p, nil
+}
+
+// parseFilter 解析 filter[pos] 开始的一个由括号包裹的过滤条件，返回解析结束的位置
+func parseFilter(filter string, pos int) (*protocol.Packet, int, error) {
+	if pos >= len(filter) || filter[pos] != '(' {
+		return nil, pos, fmt.Errorf("ldap: filter missing '(' at %d", pos)
+	}
+	pos++
+	if pos >= len(filter) {
+		return nil, pos, fmt.Errorf("ldap: filter unexpected end")
+	}
+
+	var p *protocol.Packet
+	switch filter[pos] {
+	case '&', '|':
+		tag := byte(protocol.FilterAnd)
+		if filter[pos] == '|' {
+			tag = protocol.FilterOr
+		}
+		p = &protocol.Packet{Tag: tag}
+		pos++
+		for pos < len(filter) && filter[pos] == '(' {
+			child, next, err := parseFilter(filter, pos)
+			if err != nil {
+				return nil, next, err
+			}
+			p.Append(child)
+			pos = next
+		}
+		if len(p.Children) == 0 {
+			return nil, pos, fmt.Errorf("ldap: empty filter list at %d", pos)
+		}
+	case '!':
+		child, next, err := parseFilter(filter, pos+1)
+		if err != nil {
+			return nil, next, err
+		}
+		p = &protocol.Packet{Tag: protocol.FilterNot, Children: []*protocol.Packet{child}}
+		pos = next
+	default:
+		end := strings.IndexByte(filter[pos:], ')')
+		if end < 0 {
+			return nil, pos, fmt.Errorf("ldap: filter missing ')'")
+		}
+		item, err := parseItem(filter[pos : pos+end])
+		if err != nil {
+			return nil, pos, err
+		}
+		p = item
+		pos += end
+	}
+
+	if pos >= len(filter) || filter[pos] != ')' {
+		return nil, pos, fmt.Errorf("ldap: filter missing ')' at %d", pos)
+	}
+	return p, pos + 1, nil
+}
+
+// parseItem 解析 attr=value、attr>=value、attr<=value、attr~=value 形式的简单条件
+func parseItem(item string) (*protocol.Packet, error) {
+	idx := strings.IndexByte(item, '=')
+	if idx <= 0 {
+		return nil, fmt.Errorf("ldap: invalid filter item %q", item)
+	}
+	attr, value := item[:idx], item[idx+1:]
+	tag := byte(protocol.FilterEqualityMatch)
+	switch attr[len(attr)-1] {
+	case '>':
+		tag, attr = protocol.FilterGreaterOrEqual, attr[:len(attr)-1]
+	case '<':
+		tag, attr = protocol.FilterLessOrEqual, attr[:len(attr)-1]
+	case '~':
+		tag, attr = protocol.FilterApproxMatch, attr[:len(attr)-1]
+	}
+	if attr == "" {
+		return nil, fmt.Errorf("ldap: invalid filter item %q", item)
+	}
+
+	if tag == protocol.FilterEqualityMatch && value == "*" {
+		return protocol.NewString(protocol.FilterPresent, attr), nil
+	}
+	if tag == protocol.FilterEqualityMatch && strings.Contains(value, "*") {
+		parts := strings.Split(value, "*")
+		subs := protocol.NewSequence(protocol.ClassUniversal | protocol.TagSequence)
+		for i, part := range parts {
+			if part == "" {
+				continue
+			}
+			unescaped, err := unescapeFilter(part)
+			if err != nil {
+				return nil, err
+			}
+			subTag := byte(protocol.SubstringAny)
+			if i == 0 {
+				subTag = protocol.SubstringInitial
+			} else if i == len(parts)-1 {
+				subTag = protocol.SubstringFinal
+			}
+			subs.Append(protocol.NewString(subTag, unescaped))
+		}
+		return &protocol.Packet{Tag: protocol.FilterSubstrings, Children: []*protocol.Packet{
+			protocol.NewString(protocol.TagOctetString, attr), subs,
+		}}, nil
+	}
+
+	unescaped, err := unescapeFilter(value)
+	if err != nil {
+		return nil, err
+	}
+	return &protocol.Packet{Tag: tag, Children: []*protocol.Packet{
+		protocol.NewString(protocol.TagOctetString, attr), protocol.NewString(protocol.TagOctetString, unescaped),
+	}}, nil
+}
+
+func unescapeFilter(value string) (string, error) {
+	if !strings.Contains(value, "\\") {
+		return value, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			b.WriteByte(value[i])
+			continue
+		}
+		if i+3 > len(value) {
+			return "", fmt.Errorf("ldap: invalid filter escape in %q", value)
+		}
+		decoded, err := hex.DecodeString(value[i+1 : i+3])
+		if err != nil {
+			return "", fmt.Errorf("ldap: invalid filter escape in %q", value)
+		}
+		b.Write(decoded)
+		i += 2
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,238 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package protocol
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// BER 编码的标签，仅支持 LDAP 协议需要用到的单字节标签
+const (
+	ClassUniversal   byte = 0x00
+	ClassApplication byte = 0x40
+	ClassContext     byte = 0x80
+	TypeConstructed  byte = 0x20
+
+	TagBoolean     byte = 0x01
+	TagInteger     byte = 0x02
+	TagOctetString byte = 0x04
+	TagEnumerated  byte = 0x0a
+	TagSequence    byte = 0x10 | TypeConstructed
+	TagSet         byte = 0x11 | TypeConstructed
+
+	// maxPacketSize 单个 LDAP 消息的最大长度
+	maxPacketSize = 16 << 20
+)
+
+// Packet BER 编码的一个元素
+type Packet struct {
+	Tag      byte
+	Value    []byte
+	Children []*Packet
+}
+
+// Constructed 是否为包含子元素的结构化元素
+func (p *Packet) Constructed() bool {
+	return p.Tag&TypeConstructed != 0
+}
+
+// Append 追加子元素
+func (p *Packet) Append(children ...*Packet) *Packet {
+	p.Children = append(p.Children, children...)
+	return p
+}
+
+// NewSequence 创建结构化元素
+func NewSequence(tag byte, children ...*Packet) *Packet {
+	return &Packet{Tag: tag | TypeConstructed, Children: children}
+}
+
+// NewString 创建字符串元素
+func NewString(tag byte, s string) *Packet {
+	return &Packet{Tag: tag, Value: []byte(s)}
+}
+
+// NewBool 创建布尔元素
+func NewBool(tag byte, b bool) *Packet {
+	if b {
+		return &Packet{Tag: tag, Value: []byte{0xff}}
+	}
+	return &Packet{Tag: tag, Value: []byte{0x00}}
+}
+
+// NewInt 创建整数元素
+func NewInt(tag byte, v int64) *Packet {
+	// 二进制补码，去掉多余的前导字节
+	buf := make([]byte, 8)
+	for i := 7; i >= 0; i-- {
+		buf[i] = byte(v)
+		v >>= 8
+	}
+	i := 0
+	for i < 7 && ((buf[i] == 0x00 && buf[i+1]&0x80 == 0) || (buf[i] == 0xff && buf[i+1]&0x80 != 0)) {
+		i++
+	}
+	return &Packet{Tag: tag, Value: buf[i:]}
+}
+
+// Int 按照整数解析元素的值
+func (p *Packet) Int() (int64, error) {
+	if len(p.Value) == 0 || len(p.Value) > 8 {
+		return 0, fmt.Errorf("ldap: invalid integer length %d", len(p.Value))
+	}
+	var v int64
+	if p.Value[0]&0x80 != 0 {
+		v = -1
+	}
+	for _, b := range p.Value {
+		v = v<<8 | int64(b)
+	}
+	return v, nil
+}
+
+// String 按照字符串解析元素的值
+func (p *Packet) String() string {
+	return string(p.Value)
+}
+
+// Child 获取第 i 个子元素，不存在时返回一个空元素，避免调用方反复判断越界
+func (p *Packet) Child(i int) *Packet {
+	if i < 0 || i >= len(p.Children) {
+		return &Packet{}
+	}
+	return p.Children[i]
+}
+
+// Bytes 编码为字节
+func (p *Packet) Bytes() []byte {
+	content := p.Value
+	if p.Constructed() {
+		content = nil
+		for _, c := range p.Children {
+			content = append(content, c.Bytes()...)
+		}
+	}
+	out := []byte{p.Tag}
+	out = append(out, encodeLength(len(content))...)
+	return append(out, content...)
+}
+
+func encodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var buf []byte
+	for n > 0 {
+		buf = append([]byte{byte(n)}, buf...)
+		n >>= 8
+	}
+	return append([]byte{0x80 | byte(len(buf))}, buf...)
+}
+
+// ReadPacket 从连接中读取一个完整的 BER 元素
+func ReadPacket(r *bufio.Reader) (*Packet, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	length, err := readLength(r)
+	if err != nil {
+		return nil, err
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+	return parsePacket(tag, content)
+}
+
+func readLength(r io.ByteReader) (int, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if first&0x80 == 0 {
+		return int(first), nil
+	}
+	size := int(first & 0x7f)
+	if size == 0 || size > 4 {
+		return 0, errors.New("ldap: unsupported ber length")
+	}
+	length := 0
+	for i := 0; i < size; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		length = length<<8 | int(b)
+	}
+	if length > maxPacketSize {
+		return 0, fmt.Errorf("ldap: Packet too large (%d bytes)", length)
+	}
+	return length, nil
+}
+
+func parsePacket(tag byte, content []byte) (*Packet, error) {
+	if tag&0x1f == 0x1f {
+		return nil, errors.New("ldap: unsupported ber high tag number")
+	}
+	p := &Packet{Tag: tag}
+	if !p.Constructed() {
+		p.Value = content
+		return p, nil
+	}
+	for len(content) > 0 {
+		if len(content) < 2 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		childTag := content[0]
+		reader := &sliceReader{data: content[1:]}
+		length, err := readLength(reader)
+		if err != nil {
+			return nil, err
+		}
+		offset := 1 + reader.pos
+		if offset+length > len(content) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		child, err := parsePacket(childTag, content[offset:offset+length])
+		if err != nil {
+			return nil, err
+		}
+		p.Children = append(p.Children, child)
+		content = content[offset+length:]
+	}
+	return p, nil
+}
+
+type sliceReader struct {
+	data []byte
+	pos  int
+}
+
+func (s *sliceReader) ReadByte() (byte, error) {
+	if s.pos >= len(s.data) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b := s.data[s.pos]
+	s.pos++
+	return b, nil
+}
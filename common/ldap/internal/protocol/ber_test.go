@@ -0,0 +1,50 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_packetInt(t *testing.T) {
+	for _, v := range []int64{0, 1, 127, 128, 255, 256, -1, -128, -129, 1 << 40} {
+		p := NewInt(TagInteger, v)
+		decoded, err := parsePacket(p.Tag, p.Value)
+		assert.NoError(t, err)
+		got, err := decoded.Int()
+		assert.NoError(t, err)
+		assert.Equal(t, v, got)
+	}
+}
+
+func Test_pagedResultsControl(t *testing.T) {
+	msg := NewSequence(ClassUniversal|TagSequence, NewInt(TagInteger, 1),
+		NewSequence(OpSearchResultDone),
+		NewSequence(TagControls, NewPagedResultsControl(100, "cookie", true)))
+	size, cookie, ok, err := ParsePagedResultsControl(msg)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(100), size)
+	assert.Equal(t, "cookie", cookie)
+
+	_, _, ok, err = ParsePagedResultsControl(NewSequence(ClassUniversal|TagSequence, NewInt(TagInteger, 1)))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
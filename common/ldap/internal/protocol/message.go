@@ -0,0 +1,104 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package protocol
+
+import (
+	"bufio"
+	"bytes"
+)
+
+// LDAP 协议操作的标签
+const (
+	OpBindRequest           = ClassApplication | TypeConstructed | 0
+	OpBindResponse          = ClassApplication | TypeConstructed | 1
+	OpUnbindRequest         = ClassApplication | 2
+	OpSearchRequest         = ClassApplication | TypeConstructed | 3
+	OpSearchResultEntry     = ClassApplication | TypeConstructed | 4
+	OpSearchResultDone      = ClassApplication | TypeConstructed | 5
+	OpSearchResultReference = ClassApplication | TypeConstructed | 19
+	OpExtendedRequest       = ClassApplication | TypeConstructed | 23
+	OpExtendedResponse      = ClassApplication | TypeConstructed | 24
+
+	// TagControls LDAP 消息中可选的控制项列表
+	TagControls = ClassContext | TypeConstructed | 0
+
+	AuthSimple = ClassContext | 0
+)
+
+// 过滤条件的 BER 标签
+const (
+	FilterAnd            = ClassContext | TypeConstructed | 0
+	FilterOr             = ClassContext | TypeConstructed | 1
+	FilterNot            = ClassContext | TypeConstructed | 2
+	FilterEqualityMatch  = ClassContext | TypeConstructed | 3
+	FilterSubstrings     = ClassContext | TypeConstructed | 4
+	FilterGreaterOrEqual = ClassContext | TypeConstructed | 5
+	FilterLessOrEqual    = ClassContext | TypeConstructed | 6
+	FilterPresent        = ClassContext | 7
+	FilterApproxMatch    = ClassContext | TypeConstructed | 8
+
+	SubstringInitial = ClassContext | 0
+	SubstringAny     = ClassContext | 1
+	SubstringFinal   = ClassContext | 2
+)
+
+// 扩展操作以及控制项的 OID
+const (
+	OIDStartTLS = "1.3.6.1.4.1.1466.20037"
+	// OIDPagedResults RFC 2696 分页查询控制项
+	OIDPagedResults = "1.2.840.113556.1.4.319"
+)
+
+// NewPagedResultsControl 创建分页查询控制项，size 为每页的大小，cookie 为上一页返回的游标
+func NewPagedResultsControl(size int64, cookie string, critical bool) *Packet {
+	value := NewSequence(ClassUniversal|TagSequence, NewInt(TagInteger, size), NewString(TagOctetString, cookie))
+	return NewSequence(ClassUniversal|TagSequence,
+		NewString(TagOctetString, OIDPagedResults),
+		NewBool(TagBoolean, critical),
+		NewString(TagOctetString, string(value.Bytes())),
+	)
+}
+
+// ParsePagedResultsControl 从 LDAP 消息的控制项中解析分页查询控制项，不存在时返回 false
+func ParsePagedResultsControl(msg *Packet) (int64, string, bool, error) {
+	controls := msg.Child(2)
+	if controls.Tag != TagControls {
+		return 0, "", false, nil
+	}
+	for _, control := range controls.Children {
+		if control.Child(0).String() != OIDPagedResults {
+			continue
+		}
+		var raw []byte
+		for _, item := range control.Children[1:] {
+			if item.Tag == TagOctetString {
+				raw = item.Value
+			}
+		}
+		value, err := ReadPacket(bufio.NewReader(bytes.NewReader(raw)))
+		if err != nil {
+			return 0, "", false, err
+		}
+		size, err := value.Child(0).Int()
+		if err != nil {
+			return 0, "", false, err
+		}
+		return size, value.Child(1).String(), true, nil
+	}
+	return 0, "", false, nil
+}
@@ -0,0 +1,139 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package ldap_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/ldap"
+	"github.com/polarismesh/polaris/common/ldap/ldaptest"
+)
+
+func TestConn_SearchFilter(t *testing.T) {
+	server, err := ldaptest.NewServer()
+	assert.NoError(t, err)
+	defer server.Close()
+	server.AddEntry("uid=alice", map[string][]string{
+		"uid":         {"alice"},
+		"objectClass": {"person", "inetOrgPerson"},
+		"cn":          {"Alice (Ops)"},
+	})
+	conn, err := ldap.Dial(ldap.Config{URL: server.URL()})
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	cases := map[string]bool{
+		"(uid=alice)":                              true,
+		"uid=ALICE":                                true,
+		"(&(objectClass=person)(uid=alice))":       true,
+		"(&(objectClass=person)(!(uid=alice)))":    false,
+		"(|(uid=bob)(uid=al*))":                    true,
+		"(cn=*" + ldap.EscapeFilter("(Ops)") + ")": true,
+		"(cn=Alice \\28Ops\\29)":                   true,
+		"(mail=*)":                                 false,
+		"(uid=" + ldap.EscapeFilter("*") + ")":     false,
+		"(&(objectClass=person)(uid=" + ldap.EscapeFilter("a*)(uid=*") + "))": false,
+	}
+	for filter, expect := range cases {
+		// 过滤条件经过编码后由服务端解码并匹配
+		entries, err := conn.Search(&ldap.SearchRequest{BaseDN: "uid=alice", Scope: ldap.ScopeBaseObject, Filter: filter})
+		assert.NoError(t, err, filter)
+		assert.Equal(t, expect, len(entries) == 1, filter)
+	}
+
+	for _, invalid := range []string{"(uid=alice", "(&)", "(=alice)", "(uid=\\zz)", "(uid=a)(uid=b)"} {
+		_, err := conn.Search(&ldap.SearchRequest{BaseDN: "uid=alice", Scope: ldap.ScopeBaseObject, Filter: invalid})
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestConn_PagedSearch(t *testing.T) {
+	server, err := ldaptest.NewServer()
+	assert.NoError(t, err)
+	defer server.Close()
+	for i := 0; i < 5; i++ {
+		server.AddEntry(fmt.Sprintf("uid=user-%d,dc=example,dc=com", i), map[string][]string{
+			"objectClass": {"person"},
+		})
+	}
+	server.SetSizeLimit(2)
+	conn, err := ldap.Dial(ldap.Config{URL: server.URL()})
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	req := &ldap.SearchRequest{BaseDN: "dc=example,dc=com", Scope: ldap.ScopeWholeSubtree, Filter: "(objectClass=person)"}
+	_, err = conn.Search(req)
+	assert.Error(t, err)
+
+	req.PageSize = 2
+	entries, err := conn.Search(req)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 5)
+}
+
+func TestConn_BindAndSearch(t *testing.T) {
+	server, err := ldaptest.NewServer()
+	assert.NoError(t, err)
+	defer server.Close()
+	server.AddEntry("cn=admin,dc=example,dc=com", map[string][]string{"userPassword": {"admin"}})
+	server.AddEntry("uid=alice,ou=people,dc=example,dc=com", map[string][]string{
+		"objectClass":  {"person"},
+		"uid":          {"alice"},
+		"mail":         {"alice@example.com"},
+		"userPassword": {"alice-pwd"},
+	})
+	server.AddEntry("uid=bob,ou=people,dc=example,dc=com", map[string][]string{
+		"objectClass": {"person"},
+		"uid":         {"bob"},
+	})
+
+	conn, err := ldap.Dial(ldap.Config{URL: server.URL()})
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	assert.NoError(t, conn.Bind("cn=admin,dc=example,dc=com", "admin"))
+	entries, err := conn.Search(&ldap.SearchRequest{
+		BaseDN:     "ou=people,dc=example,dc=com",
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     "(&(objectClass=person)(uid=alice))",
+		Attributes: []string{"uid", "mail"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "uid=alice,ou=people,dc=example,dc=com", entries[0].DN)
+	assert.Equal(t, "alice@example.com", entries[0].Value("mail"))
+	assert.Empty(t, entries[0].Values("userPassword"))
+
+	entries, err = conn.Search(&ldap.SearchRequest{
+		BaseDN: "dc=example,dc=com",
+		Scope:  ldap.ScopeWholeSubtree,
+		Filter: "(objectClass=person)",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	assert.NoError(t, conn.Bind("uid=alice,ou=people,dc=example,dc=com", "alice-pwd"))
+	err = conn.Bind("uid=alice,ou=people,dc=example,dc=com", "wrong")
+	assert.True(t, ldap.IsInvalidCredentials(err))
+	// 空密码不能被当作匿名绑定
+	err = conn.Bind("uid=alice,ou=people,dc=example,dc=com", "")
+	assert.True(t, ldap.IsInvalidCredentials(err))
+}
@@ -0,0 +1,310 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+// Package ldaptest 提供测试使用的内存 LDAP 服务
+package ldaptest
+
+import (
+	"bufio"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/polarismesh/polaris/common/ldap"
+	"github.com/polarismesh/polaris/common/ldap/internal/protocol"
+)
+
+// Server 用于测试的内存 LDAP 服务，支持简单认证、查询以及分页查询，条目的 userPassword 属性作为绑定密码
+type Server struct {
+	listener  net.Listener
+	lock      sync.RWMutex
+	entries   map[string]*ldap.Entry
+	sizeLimit int
+	wg        sync.WaitGroup
+}
+
+// NewServer 在本地随机端口启动测试用的 LDAP 服务
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{listener: listener, entries: map[string]*ldap.Entry{}}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// URL 服务地址
+func (s *Server) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+// SetSizeLimit 设置非分页查询最多返回的条目数量，超过时返回 sizeLimitExceeded，模拟 Active Directory 的行为
+func (s *Server) SetSizeLimit(limit int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.sizeLimit = limit
+}
+
+// AddEntry 添加或者覆盖一个条目
+func (s *Server) AddEntry(dn string, attrs map[string][]string) {
+	entry := &ldap.Entry{DN: dn, Attributes: map[string][]string{}}
+	for k, v := range attrs {
+		entry.Attributes[strings.ToLower(k)] = v
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.entries[strings.ToLower(dn)] = entry
+}
+
+// RemoveEntry 删除一个条目
+func (s *Server) RemoveEntry(dn string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.entries, strings.ToLower(dn))
+}
+
+// Close 关闭服务
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		msg, err := protocol.ReadPacket(reader)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+		msgID, _ := msg.Child(0).Int()
+		op := msg.Child(1)
+		var (
+			responses []*protocol.Packet
+			controls  []*protocol.Packet
+		)
+		switch op.Tag {
+		case protocol.OpBindRequest:
+			responses = append(responses, newResult(protocol.OpBindResponse,
+				s.bind(op.Child(1).String(), op.Child(2).String())))
+		case protocol.OpSearchRequest:
+			results, code, control := s.search(msg)
+			responses = append(results, newResult(protocol.OpSearchResultDone, code))
+			if control != nil {
+				controls = append(controls, control)
+			}
+		case protocol.OpUnbindRequest:
+			return
+		default:
+			responses = append(responses, newResult(protocol.OpExtendedResponse, 2))
+		}
+		for i, resp := range responses {
+			out := protocol.NewSequence(protocol.ClassUniversal|protocol.TagSequence,
+				protocol.NewInt(protocol.TagInteger, msgID), resp)
+			if i == len(responses)-1 && len(controls) > 0 {
+				out.Append(protocol.NewSequence(protocol.TagControls, controls...))
+			}
+			if _, err := conn.Write(out.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func newResult(tag byte, code int) *protocol.Packet {
+	return protocol.NewSequence(tag,
+		protocol.NewInt(protocol.TagEnumerated, int64(code)),
+		protocol.NewString(protocol.TagOctetString, ""),
+		protocol.NewString(protocol.TagOctetString, ""),
+	)
+}
+
+func (s *Server) bind(dn, password string) int {
+	if dn == "" {
+		return ldap.ResultSuccess
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	entry, ok := s.entries[strings.ToLower(dn)]
+	if !ok || password == "" || entry.Value("userPassword") != password {
+		return ldap.ResultInvalidCredentials
+	}
+	return ldap.ResultSuccess
+}
+
+// search 返回当前页的查询结果、结果码以及分页控制项，游标为下一页的起始位置
+func (s *Server) search(msg *protocol.Packet) ([]*protocol.Packet, int, *protocol.Packet) {
+	op := msg.Child(1)
+	base := strings.ToLower(op.Child(0).String())
+	scope, _ := op.Child(1).Int()
+	filter := op.Child(6)
+	var attrs []string
+	for _, attr := range op.Child(7).Children {
+		attrs = append(attrs, strings.ToLower(attr.String()))
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	var matched []*ldap.Entry
+	for dn, entry := range s.entries {
+		if inScope(dn, base, int(scope)) && matchFilter(filter, entry) {
+			matched = append(matched, entry)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].DN < matched[j].DN
+	})
+
+	code := ldap.ResultSuccess
+	var control *protocol.Packet
+	size, cookie, paged, err := protocol.ParsePagedResultsControl(msg)
+	switch {
+	case err != nil:
+		return nil, 2, nil
+	case paged:
+		start, _ := strconv.Atoi(cookie)
+		if start > len(matched) {
+			start = len(matched)
+		}
+		end := start + int(size)
+		next := strconv.Itoa(end)
+		if end >= len(matched) {
+			end, next = len(matched), ""
+		}
+		matched = matched[start:end]
+		control = protocol.NewPagedResultsControl(0, next, false)
+	case s.sizeLimit > 0 && len(matched) > s.sizeLimit:
+		matched = matched[:s.sizeLimit]
+		code = ldap.ResultSizeLimitExceeded
+	}
+
+	results := make([]*protocol.Packet, 0, len(matched))
+	for _, entry := range matched {
+		attrList := protocol.NewSequence(protocol.ClassUniversal | protocol.TagSequence)
+		for name, values := range entry.Attributes {
+			if name == "userpassword" || (len(attrs) > 0 && !contains(attrs, name)) {
+				continue
+			}
+			vals := &protocol.Packet{Tag: protocol.ClassUniversal | protocol.TagSet}
+			for _, v := range values {
+				vals.Append(protocol.NewString(protocol.TagOctetString, v))
+			}
+			attrList.Append(protocol.NewSequence(protocol.ClassUniversal|protocol.TagSequence,
+				protocol.NewString(protocol.TagOctetString, name), vals))
+		}
+		results = append(results, protocol.NewSequence(protocol.OpSearchResultEntry,
+			protocol.NewString(protocol.TagOctetString, entry.DN), attrList))
+	}
+	return results, code, control
+}
+func inScope(dn, base string, scope int) bool {
+	switch scope {
+	case ldap.ScopeBaseObject:
+		return dn == base
+	case ldap.ScopeSingleLevel:
+		idx := strings.IndexByte(dn, ',')
+		return idx > 0 && dn[idx+1:] == base
+	default:
+		return dn == base || strings.HasSuffix(dn, ","+base)
+	}
+}
+
+func matchFilter(filter *protocol.Packet, entry *ldap.Entry) bool {
+	switch filter.Tag {
+	case protocol.FilterAnd:
+		for _, child := range filter.Children {
+			if !matchFilter(child, entry) {
+				return false
+			}
+		}
+		return true
+	case protocol.FilterOr:
+		for _, child := range filter.Children {
+			if matchFilter(child, entry) {
+				return true
+			}
+		}
+		return false
+	case protocol.FilterNot:
+		return !matchFilter(filter.Child(0), entry)
+	case protocol.FilterPresent:
+		return len(entry.Values(filter.String())) > 0
+	case protocol.FilterEqualityMatch, protocol.FilterApproxMatch:
+		for _, v := range entry.Values(filter.Child(0).String()) {
+			if strings.EqualFold(v, filter.Child(1).String()) {
+				return true
+			}
+		}
+		return false
+	case protocol.FilterSubstrings:
+		for _, v := range entry.Values(filter.Child(0).String()) {
+			if matchSubstrings(strings.ToLower(v), filter.Child(1).Children) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+func matchSubstrings(value string, subs []*protocol.Packet) bool {
+	for _, sub := range subs {
+		part := strings.ToLower(sub.String())
+		switch sub.Tag {
+		case protocol.SubstringInitial:
+			if !strings.HasPrefix(value, part) {
+				return false
+			}
+			value = value[len(part):]
+		case protocol.SubstringFinal:
+			return strings.HasSuffix(value, part)
+		default:
+			idx := strings.Index(value, part)
+			if idx < 0 {
+				return false
+			}
+			value = value[idx+len(part):]
+		}
+	}
+	return true
+}
+
+func contains(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrorTokenExpired 访问令牌已经过期
+	ErrorTokenExpired error = errors.New("token already expired")
+
+	// ErrorTokenReadOnly 只读访问令牌不允许执行写操作
+	ErrorTokenReadOnly error = errors.New("token is read only")
+
+	// ErrorTokenOutOfScope 访问的资源不在访问令牌的授权范围内
+	ErrorTokenOutOfScope error = errors.New("resource out of token scope")
+)
+
+const (
+	// TokenForAccessToken 个人访问令牌
+	TokenForAccessToken string = "pat"
+)
+
+// AccessToken 个人访问令牌，同一个用户/用户组可以拥有多个，每个令牌具有有效期，并且可以限制资源范围以及只读
+type AccessToken struct {
+	ID            string
+	Name          string
+	PrincipalID   string
+	PrincipalType PrincipalType
+	// Owner 用户/用户组所属的主账户 ID
+	Owner string
+	// Secret 令牌的摘要，令牌原文只会在创建时返回
+	Secret       string
+	Scope        *AccessTokenScope
+	ReadOnly     bool
+	ExpireTime   time.Time
+	LastUsedTime time.Time
+	Valid        bool
+	CreateTime   time.Time
+	ModifyTime   time.Time
+}
+
+// IsExpired 令牌是否已经过期
+func (t *AccessToken) IsExpired(now time.Time) bool {
+	return !t.ExpireTime.IsZero() && !now.Before(t.ExpireTime)
+}
+
+// IsRestricted 令牌是否存在资源范围或者只读的限制
+func (t *AccessToken) IsRestricted() bool {
+	return t.ReadOnly || !t.Scope.IsEmpty()
+}
+
+// AccessTokenScope 访问令牌的资源范围，为空时不限制
+type AccessTokenScope struct {
+	// Namespaces 允许访问的命名空间，命名空间下的服务以及配置分组均可访问
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Services 允许访问的服务，格式为 namespace/service
+	Services []string `json:"services,omitempty"`
+	// ConfigGroups 允许访问的配置分组，格式为 namespace/group
+	ConfigGroups []string `json:"config_groups,omitempty"`
+}
+
+// IsEmpty 是否没有设置资源范围
+func (s *AccessTokenScope) IsEmpty() bool {
+	return s == nil || (len(s.Namespaces) == 0 && len(s.Services) == 0 && len(s.ConfigGroups) == 0)
+}
+
+// Verify 检查资源范围的格式
+func (s *AccessTokenScope) Verify() error {
+	if s == nil {
+		return nil
+	}
+	for _, ns := range s.Namespaces {
+		if ns == "" || strings.Contains(ns, "/") {
+			return errors.New("invalid namespace in token scope: " + ns)
+		}
+	}
+	for _, items := range [][]string{s.Services, s.ConfigGroups} {
+		for _, item := range items {
+			if ns, name, ok := strings.Cut(item, "/"); !ok || ns == "" || name == "" {
+				return errors.New("token scope resource must be namespace/name: " + item)
+			}
+		}
+	}
+	return nil
+}
+
+// AllowNamespace 命名空间是否在范围内，当命名空间下存在授权的服务或者配置分组时，命名空间本身也允许访问
+func (s *AccessTokenScope) AllowNamespace(namespace string) bool {
+	if s.IsEmpty() {
+		return true
+	}
+	for _, ns := range s.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	prefix := namespace + "/"
+	for _, items := range [][]string{s.Services, s.ConfigGroups} {
+		for _, item := range items {
+			if strings.HasPrefix(item, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AllowService 服务是否在范围内
+func (s *AccessTokenScope) AllowService(namespace, service string) bool {
+	if s.IsEmpty() {
+		return true
+	}
+	return s.allowResource(s.Services, namespace, service)
+}
+
+// AllowConfigGroup 配置分组是否在范围内
+func (s *AccessTokenScope) AllowConfigGroup(namespace, group string) bool {
+	if s.IsEmpty() {
+		return true
+	}
+	return s.allowResource(s.ConfigGroups, namespace, group)
+}
+
+func (s *AccessTokenScope) allowResource(items []string, namespace, name string) bool {
+	for _, ns := range s.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	key := namespace + "/" + name
+	for _, item := range items {
+		if item == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessTokenScope(t *testing.T) {
+	var empty *AccessTokenScope
+	assert.True(t, empty.IsEmpty())
+	assert.NoError(t, empty.Verify())
+	assert.True(t, empty.AllowService("default", "svc"))
+
+	scope := &AccessTokenScope{
+		Namespaces:   []string{"ns-a"},
+		Services:     []string{"ns-b/svc-1"},
+		ConfigGroups: []string{"ns-c/group-1"},
+	}
+	assert.NoError(t, scope.Verify())
+	assert.False(t, scope.IsEmpty())
+
+	assert.True(t, scope.AllowNamespace("ns-a"))
+	assert.True(t, scope.AllowNamespace("ns-b"))
+	assert.True(t, scope.AllowNamespace("ns-c"))
+	assert.False(t, scope.AllowNamespace("ns-d"))
+
+	assert.True(t, scope.AllowService("ns-a", "any"))
+	assert.True(t, scope.AllowService("ns-b", "svc-1"))
+	assert.False(t, scope.AllowService("ns-b", "svc-2"))
+	assert.False(t, scope.AllowService("ns-c", "group-1"))
+
+	assert.True(t, scope.AllowConfigGroup("ns-a", "any"))
+	assert.True(t, scope.AllowConfigGroup("ns-c", "group-1"))
+	assert.False(t, scope.AllowConfigGroup("ns-b", "svc-1"))
+
+	assert.Error(t, (&AccessTokenScope{Services: []string{"svc"}}).Verify())
+	assert.Error(t, (&AccessTokenScope{ConfigGroups: []string{"ns/"}}).Verify())
+	assert.Error(t, (&AccessTokenScope{Namespaces: []string{"a/b"}}).Verify())
+}
+
+func TestAccessToken_IsExpired(t *testing.T) {
+	now := time.Now()
+	token := &AccessToken{ExpireTime: now.Add(time.Minute)}
+	assert.False(t, token.IsExpired(now))
+	assert.True(t, token.IsExpired(now.Add(time.Minute)))
+	assert.False(t, token.IsRestricted())
+
+	token.ReadOnly = true
+	assert.True(t, token.IsRestricted())
+}
@@ -291,6 +291,8 @@ type StrategyDetailCache struct {
 	*StrategyDetail
 	UserPrincipal  map[string]Principal
 	GroupPrincipal map[string]Principal
+	// StrategyAction 解析后的策略动作
+	StrategyAction *StrategyAction
 }
 
 // ModifyStrategyDetail 修改鉴权策略详细
@@ -0,0 +1,174 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// ActionReadWrite 允许所有操作, 老版本策略的默认动作
+	ActionReadWrite = "READ_WRITE"
+	// ActionOnlyRead 只允许读操作
+	ActionOnlyRead = "ONLY_READ"
+
+	// actionAllowPrefix 允许指定的操作, 例如 ALLOW:READ,MODIFY,CreateInstances
+	actionAllowPrefix = "ALLOW:"
+	// actionDenyPrefix 拒绝指定的操作, 拒绝策略优先于允许策略, 例如 DENY:DELETE
+	actionDenyPrefix = "DENY:"
+	// actionAll 所有的操作
+	actionAll = "*"
+)
+
+var (
+	operationNames = map[string]ResourceOperation{
+		"READ":   Read,
+		"CREATE": Create,
+		"MODIFY": Modify,
+		"DELETE": Delete,
+	}
+	operationLabels = map[ResourceOperation]string{
+		Read:   "READ",
+		Create: "CREATE",
+		Modify: "MODIFY",
+		Delete: "DELETE",
+	}
+)
+
+// StrategyAction 鉴权策略动作, 描述策略允许或者拒绝哪些操作
+type StrategyAction struct {
+	// Deny 是否为拒绝策略
+	Deny bool
+	// All 匹配所有的操作
+	All bool
+	// Operations 匹配的操作类型
+	Operations map[ResourceOperation]struct{}
+	// Methods 匹配的接口方法, 例如 CreateInstances
+	Methods map[string]struct{}
+}
+
+// ParseStrategyAction 解析鉴权策略动作, 为空时按照 READ_WRITE 处理
+func ParseStrategyAction(action string) (*StrategyAction, error) {
+	ret := &StrategyAction{
+		Operations: map[ResourceOperation]struct{}{},
+		Methods:    map[string]struct{}{},
+	}
+	action = strings.TrimSpace(action)
+	upper := strings.ToUpper(action)
+	var items string
+	switch {
+	case upper == "" || upper == ActionReadWrite:
+		ret.All = true
+		return ret, nil
+	case upper == ActionOnlyRead:
+		ret.Operations[Read] = struct{}{}
+		return ret, nil
+	case strings.HasPrefix(upper, actionAllowPrefix):
+		items = action[len(actionAllowPrefix):]
+	case strings.HasPrefix(upper, actionDenyPrefix):
+		ret.Deny = true
+		items = action[len(actionDenyPrefix):]
+	default:
+		return nil, fmt.Errorf("invalid strategy action %q", action)
+	}
+
+	for _, item := range strings.Split(items, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if item == actionAll {
+			ret.All = true
+			continue
+		}
+		if op, ok := operationNames[strings.ToUpper(item)]; ok {
+			ret.Operations[op] = struct{}{}
+			continue
+		}
+		if !isMethodName(item) {
+			return nil, fmt.Errorf("invalid strategy action item %q", item)
+		}
+		ret.Methods[item] = struct{}{}
+	}
+	if !ret.All && len(ret.Operations) == 0 && len(ret.Methods) == 0 {
+		return nil, fmt.Errorf("strategy action %q has no operation", action)
+	}
+	return ret, nil
+}
+
+func isMethodName(s string) bool {
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// Match 判断操作是否命中该动作
+func (a *StrategyAction) Match(op ResourceOperation, method string) bool {
+	if a.All {
+		return true
+	}
+	if _, ok := a.Operations[op]; ok {
+		return true
+	}
+	_, ok := a.Methods[method]
+	return ok
+}
+
+// AllowAllWrite 是否允许所有的写操作
+func (a *StrategyAction) AllowAllWrite() bool {
+	if a.Deny {
+		return false
+	}
+	return a.All || (a.Match(Create, "") && a.Match(Modify, "") && a.Match(Delete, ""))
+}
+
+// String 动作的规范化表示
+func (a *StrategyAction) String() string {
+	if !a.Deny {
+		if a.All {
+			return ActionReadWrite
+		}
+		if len(a.Operations) == 1 && len(a.Methods) == 0 && a.Match(Read, "") {
+			return ActionOnlyRead
+		}
+	}
+	items := make([]string, 0, len(a.Operations)+len(a.Methods)+1)
+	if a.All {
+		items = append(items, actionAll)
+	}
+	for _, op := range []ResourceOperation{Read, Create, Modify, Delete} {
+		if _, ok := a.Operations[op]; ok {
+			items = append(items, operationLabels[op])
+		}
+	}
+	methods := make([]string, 0, len(a.Methods))
+	for method := range a.Methods {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	items = append(items, methods...)
+	prefix := actionAllowPrefix
+	if a.Deny {
+		prefix = actionDenyPrefix
+	}
+	return prefix + strings.Join(items, ",")
+}
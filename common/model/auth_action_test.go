@@ -0,0 +1,59 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStrategyAction(t *testing.T) {
+	action, err := ParseStrategyAction("")
+	assert.NoError(t, err)
+	assert.True(t, action.Match(Delete, "DeleteNamespaces"))
+	assert.Equal(t, ActionReadWrite, action.String())
+
+	action, err = ParseStrategyAction(ActionOnlyRead)
+	assert.NoError(t, err)
+	assert.True(t, action.Match(Read, "GetNamespaces"))
+	assert.False(t, action.Match(Modify, "UpdateNamespaces"))
+	assert.False(t, action.AllowAllWrite())
+	assert.Equal(t, ActionOnlyRead, action.String())
+
+	action, err = ParseStrategyAction("DENY: delete, UpdateInstances ,")
+	assert.NoError(t, err)
+	assert.True(t, action.Deny)
+	assert.True(t, action.Match(Delete, "DeleteInstances"))
+	assert.True(t, action.Match(Modify, "UpdateInstances"))
+	assert.False(t, action.Match(Modify, "UpdateNamespaces"))
+	assert.Equal(t, "DENY:DELETE,UpdateInstances", action.String())
+
+	action, err = ParseStrategyAction("ALLOW:READ,CREATE,MODIFY,DELETE")
+	assert.NoError(t, err)
+	assert.True(t, action.AllowAllWrite())
+
+	action, err = ParseStrategyAction("allow:read,GetInstances")
+	assert.NoError(t, err)
+	assert.False(t, action.Deny)
+	assert.Equal(t, "ALLOW:READ,GetInstances", action.String())
+
+	for _, invalid := range []string{"WRITE", "ALLOW:", "DENY:Update-Instances"} {
+		_, err = ParseStrategyAction(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
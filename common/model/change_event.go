@@ -0,0 +1,61 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package model
+
+// ChangeResource 存储层变更事件对应的资源类型
+type ChangeResource string
+
+const (
+	// ChangeAll 变更事件可能存在丢失，需要刷新所有由变更事件驱动的缓存
+	ChangeAll ChangeResource = "*"
+	// ChangeService 服务以及服务别名
+	ChangeService ChangeResource = "service"
+	// ChangeInstance 服务实例
+	ChangeInstance ChangeResource = "instance"
+	// ChangeRoutingConfig 路由规则（v1 & v2）
+	ChangeRoutingConfig ChangeResource = "routingConfig"
+	// ChangeRateLimit 限流规则
+	ChangeRateLimit ChangeResource = "rateLimit"
+	// ChangeCircuitBreaker 熔断规则
+	ChangeCircuitBreaker ChangeResource = "circuitBreaker"
+	// ChangeFaultDetect 主动探测规则
+	ChangeFaultDetect ChangeResource = "faultDetect"
+	// ChangeConfigGroup 配置分组
+	ChangeConfigGroup ChangeResource = "configGroup"
+	// ChangeConfigFileRelease 配置文件发布
+	ChangeConfigFileRelease ChangeResource = "configFileRelease"
+	// ChangeUser 用户
+	ChangeUser ChangeResource = "user"
+	// ChangeUserGroup 用户组
+	ChangeUserGroup ChangeResource = "userGroup"
+	// ChangeAccessToken 个人访问令牌
+	ChangeAccessToken ChangeResource = "accessToken"
+	// ChangeStrategy 鉴权策略
+	ChangeStrategy ChangeResource = "strategy"
+)
+
+// ChangeEvent 存储层的资源变更事件，由写入数据的节点发布，各个节点的缓存据此做定向的增量刷新
+type ChangeEvent struct {
+	// Resource 变更的资源类型
+	Resource ChangeResource `json:"resource"`
+	// ID 变更的资源 ID，无法确定时为空
+	ID string `json:"id,omitempty"`
+	// Revision 变更后的资源版本，删除或者无法确定版本时为空
+	Revision string `json:"revision,omitempty"`
+	// Deleted 资源是否被删除
+	Deleted bool `json:"deleted,omitempty"`
+}
@@ -490,6 +490,32 @@ const (
 	MetadataInternalMetaTraceSampling   = "internal-trace_sampling"
 )
 
+const (
+	// MetadataProbeType 主动探测类型，取值为 tcp/http/grpc，设置在实例或服务的 metadata 上即开启主动探测
+	MetadataProbeType = "internal-probe-type"
+	// MetadataProbePort 探测端口，不设置时使用实例端口
+	MetadataProbePort = "internal-probe-port"
+	// MetadataProbeInterval 探测间隔，例如 5s
+	MetadataProbeInterval = "internal-probe-interval"
+	// MetadataProbeTimeout 单次探测超时时间，例如 1s
+	MetadataProbeTimeout = "internal-probe-timeout"
+	// MetadataProbeHealthyThreshold 连续成功多少次后转为健康
+	MetadataProbeHealthyThreshold = "internal-probe-healthy-threshold"
+	// MetadataProbeUnhealthyThreshold 连续失败多少次后转为不健康
+	MetadataProbeUnhealthyThreshold = "internal-probe-unhealthy-threshold"
+	// MetadataProbeHTTPPath HTTP 探测的请求路径
+	MetadataProbeHTTPPath = "internal-probe-http-path"
+	// MetadataProbeHTTPExpectStatus HTTP 探测期望的状态码，多个用逗号分隔，例如 200,204
+	MetadataProbeHTTPExpectStatus = "internal-probe-http-expect-status"
+	// MetadataProbeHTTPExpectBody HTTP 探测期望响应体包含的内容
+	MetadataProbeHTTPExpectBody = "internal-probe-http-expect-body"
+	// MetadataProbeGRPCService gRPC 健康检查协议中的 service 参数
+	MetadataProbeGRPCService = "internal-probe-grpc-service"
+)
+
+// ProbeMetadataPrefix 主动探测相关 metadata 的公共前缀
+const ProbeMetadataPrefix = "internal-probe-"
+
 // Instance 组合了api的Instance对象
 type Instance struct {
 	Proto             *apiservice.Instance
@@ -62,6 +62,7 @@ const (
 	RUserGroup          Resource = "UserGroup"
 	RUserGroupRelation  Resource = "UserGroupRelation"
 	RAuthStrategy       Resource = "AuthStrategy"
+	RAccessToken        Resource = "AccessToken"
 	RConfigGroup        Resource = "ConfigGroup"
 	RConfigFile         Resource = "ConfigFile"
 	RConfigFileRelease  Resource = "ConfigFileRelease"
@@ -72,14 +73,15 @@ const (
 
 // RecordEntry Operation records
 type RecordEntry struct {
-	ResourceType  Resource
-	ResourceName  string
-	Namespace     string
-	Operator      string
-	OperationType OperationType
-	Detail        string
-	Server        string
-	HappenTime    time.Time
+	ID            uint64        `json:"id"`
+	ResourceType  Resource      `json:"resource_type"`
+	ResourceName  string        `json:"resource_name"`
+	Namespace     string        `json:"namespace"`
+	Operator      string        `json:"operator"`
+	OperationType OperationType `json:"operation_type"`
+	Detail        string        `json:"detail"`
+	Server        string        `json:"server"`
+	HappenTime    time.Time     `json:"happen_time"`
 }
 
 func (r *RecordEntry) String() string {
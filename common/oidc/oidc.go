@@ -0,0 +1,286 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	discoveryPath  = "/.well-known/openid-configuration"
+	defaultTimeout = 10 * time.Second
+	// maxResponseSize 身份提供方返回内容的最大长度
+	maxResponseSize = 1 << 20
+)
+
+// DefaultScopes 默认申请的 scope
+var DefaultScopes = []string{"openid", "profile", "email"}
+
+// Config OIDC 客户端配置
+type Config struct {
+	// Issuer 身份提供方地址，用于 discovery 以及校验 id_token 的 iss
+	Issuer string
+	// ClientID 在身份提供方注册的客户端 ID
+	ClientID string
+	// ClientSecret 客户端密钥，公共客户端仅依赖 PKCE 时可以为空
+	ClientSecret string
+	// RedirectURL 授权完成后的回调地址
+	RedirectURL string
+	// Scopes 申请的 scope 列表
+	Scopes []string
+	// HTTPClient 访问身份提供方使用的 http 客户端
+	HTTPClient *http.Client
+}
+
+// Metadata 身份提供方 discovery 元数据
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Token 授权码换取到的 token
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Claims id_token 或者 userinfo 中的声明
+type Claims map[string]interface{}
+
+// String 获取字符串类型的声明
+func (c Claims) String(name string) string {
+	v, _ := c[name].(string)
+	return v
+}
+
+// Strings 获取字符串数组类型的声明，单个字符串视为只有一个元素的数组
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		ret := make([]string, 0, len(v))
+		for i := range v {
+			if s, ok := v[i].(string); ok {
+				ret = append(ret, s)
+			}
+		}
+		return ret
+	default:
+		return nil
+	}
+}
+
+// Provider OIDC 身份提供方客户端，discovery 元数据以及签名公钥在首次使用时加载
+type Provider struct {
+	cfg    Config
+	client *http.Client
+	now    func() time.Time
+
+	lock     sync.Mutex
+	metadata *Metadata
+	keys     map[string]interface{}
+}
+
+// NewProvider 创建身份提供方客户端
+func NewProvider(cfg Config) (*Provider, error) {
+	if cfg.Issuer == "" {
+		return nil, errors.New("oidc issuer is empty")
+	}
+	if cfg.ClientID == "" {
+		return nil, errors.New("oidc client id is empty")
+	}
+	if cfg.RedirectURL == "" {
+		return nil, errors.New("oidc redirect url is empty")
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = DefaultScopes
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	return &Provider{
+		cfg:    cfg,
+		client: client,
+		now:    time.Now,
+	}, nil
+}
+
+// Metadata 获取身份提供方的 discovery 元数据
+func (p *Provider) Metadata(ctx context.Context) (*Metadata, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	md := &Metadata{}
+	wellKnown := strings.TrimSuffix(p.cfg.Issuer, "/") + discoveryPath
+	if err := p.getJSON(ctx, wellKnown, "", md); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if strings.TrimSuffix(md.Issuer, "/") != strings.TrimSuffix(p.cfg.Issuer, "/") {
+		return nil, fmt.Errorf("oidc discovery issuer %q not match %q", md.Issuer, p.cfg.Issuer)
+	}
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+		return nil, errors.New("oidc discovery metadata missing endpoints")
+	}
+	p.metadata = md
+	return md, nil
+}
+
+// AuthCodeURL 生成授权码模式的跳转地址，codeVerifier 用于计算 PKCE 的 S256 code_challenge
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	md, err := p.Metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(md.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", p.cfg.RedirectURL)
+	query.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// Exchange 使用授权码以及 PKCE code_verifier 换取 token
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*Token, error) {
+	md, err := p.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, md.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	token := &Token{}
+	if err := p.doJSON(req, token); err != nil {
+		return nil, fmt.Errorf("oidc exchange code: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("oidc token response missing id_token")
+	}
+	return token, nil
+}
+
+// UserInfo 通过 access_token 查询用户信息
+func (p *Provider) UserInfo(ctx context.Context, accessToken string) (Claims, error) {
+	md, err := p.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if md.UserInfoEndpoint == "" {
+		return nil, errors.New("oidc provider not support userinfo")
+	}
+	claims := Claims{}
+	if err := p.getJSON(ctx, md.UserInfoEndpoint, accessToken, &claims); err != nil {
+		return nil, fmt.Errorf("oidc userinfo: %w", err)
+	}
+	return claims, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, target, accessToken string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return p.doJSON(req, out)
+}
+
+func (p *Provider) doJSON(req *http.Request, out interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		oauthErr := struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}{}
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+			return fmt.Errorf("%s: %s", oauthErr.Error, oauthErr.Description)
+		}
+		return fmt.Errorf("unexpected http status %d", resp.StatusCode)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// NewRandomString 生成指定字节数的随机字符串，可用于 state、nonce 以及 PKCE code_verifier
+func NewRandomString(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CodeChallenge 计算 PKCE S256 的 code_challenge
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
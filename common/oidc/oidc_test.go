@@ -0,0 +1,127 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/oidc"
+	"github.com/polarismesh/polaris/common/oidc/oidctest"
+)
+
+func TestProvider_AuthorizationCodeFlow(t *testing.T) {
+	issuer, err := oidctest.NewIssuer("polaris", "secret")
+	assert.NoError(t, err)
+	defer issuer.Close()
+
+	provider, err := oidc.NewProvider(oidc.Config{
+		Issuer:       issuer.URL,
+		ClientID:     "polaris",
+		ClientSecret: "secret",
+		RedirectURL:  "http://127.0.0.1:8090/core/v1/user/login/oidc/callback",
+	})
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	verifier, err := oidc.NewRandomString(32)
+	assert.NoError(t, err)
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	assert.NoError(t, err)
+	u, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, "openid profile email", u.Query().Get("scope"))
+	assert.Equal(t, oidc.CodeChallenge(verifier), u.Query().Get("code_challenge"))
+
+	t.Run("正常登录", func(t *testing.T) {
+		code, state, err := issuer.Authorize(authURL, map[string]interface{}{
+			"sub":                "1001",
+			"preferred_username": "alice",
+			"groups":             []string{"dev", "ops"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "state-1", state)
+
+		token, err := provider.Exchange(ctx, code, verifier)
+		assert.NoError(t, err)
+		claims, err := provider.VerifyIDToken(ctx, token.IDToken, "nonce-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", claims.String("preferred_username"))
+		assert.Equal(t, []string{"dev", "ops"}, claims.Strings("groups"))
+
+		userInfo, err := provider.UserInfo(ctx, token.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, "1001", userInfo.String("sub"))
+	})
+
+	t.Run("code_verifier不匹配", func(t *testing.T) {
+		code, _, err := issuer.Authorize(authURL, map[string]interface{}{"sub": "1001"})
+		assert.NoError(t, err)
+		_, err = provider.Exchange(ctx, code, "other-verifier")
+		assert.Error(t, err)
+	})
+
+	t.Run("nonce不匹配", func(t *testing.T) {
+		code, _, err := issuer.Authorize(authURL, map[string]interface{}{"sub": "1001"})
+		assert.NoError(t, err)
+		token, err := provider.Exchange(ctx, code, verifier)
+		assert.NoError(t, err)
+		_, err = provider.VerifyIDToken(ctx, token.IDToken, "nonce-2")
+		assert.Error(t, err)
+	})
+
+	t.Run("id_token校验失败", func(t *testing.T) {
+		now := time.Now()
+		base := map[string]interface{}{
+			"iss":   issuer.URL,
+			"aud":   "polaris",
+			"exp":   now.Add(time.Minute).Unix(),
+			"nonce": "nonce-1",
+		}
+		invalids := []map[string]interface{}{
+			{"iss": "http://evil.example.com"},
+			{"aud": "other-client"},
+			{"exp": now.Add(-time.Hour).Unix()},
+		}
+		for _, invalid := range invalids {
+			claims := map[string]interface{}{}
+			for k, v := range base {
+				claims[k] = v
+			}
+			for k, v := range invalid {
+				claims[k] = v
+			}
+			idToken, err := issuer.SignIDToken(claims)
+			assert.NoError(t, err)
+			_, err = provider.VerifyIDToken(ctx, idToken, "nonce-1")
+			assert.Error(t, err, invalid)
+		}
+
+		idToken, err := issuer.SignIDToken(base)
+		assert.NoError(t, err)
+		_, err = provider.VerifyIDToken(ctx, idToken, "nonce-1")
+		assert.NoError(t, err)
+		// 篡改签名
+		_, err = provider.VerifyIDToken(ctx, idToken[:len(idToken)-4]+"AAAA", "nonce-1")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,230 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+// Package oidctest 提供用于测试的本地 OIDC 身份提供方
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const keyID = "stub-key"
+
+type authRequest struct {
+	claims      map[string]interface{}
+	nonce       string
+	challenge   string
+	redirectURI string
+}
+
+// Issuer 本地的 OIDC 身份提供方，支持 discovery、jwks、授权码 + PKCE 换取 token 以及 userinfo
+type Issuer struct {
+	*httptest.Server
+	ClientID     string
+	ClientSecret string
+	// UserInfo 不为空时，userinfo 接口返回该声明，否则返回授权时的声明
+	UserInfo map[string]interface{}
+
+	key   *rsa.PrivateKey
+	lock  sync.Mutex
+	codes map[string]*authRequest
+	seq   int
+}
+
+// NewIssuer 启动一个本地身份提供方，使用完毕后需要调用 Close
+func NewIssuer(clientID, clientSecret string) (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	issuer := &Issuer{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		codes:        map[string]*authRequest{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.handleDiscovery)
+	mux.HandleFunc("/jwks", issuer.handleJWKS)
+	mux.HandleFunc("/token", issuer.handleToken)
+	mux.HandleFunc("/userinfo", issuer.handleUserInfo)
+	issuer.Server = httptest.NewServer(mux)
+	return issuer, nil
+}
+
+// Authorize 模拟用户在身份提供方完成登录，解析授权跳转地址并签发授权码，返回授权码以及原样返回的 state
+func (i *Issuer) Authorize(authURL string, claims map[string]interface{}) (string, string, error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	query := u.Query()
+	if query.Get("client_id") != i.ClientID || query.Get("response_type") != "code" {
+		return "", "", errors.New("invalid authorization request")
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		return "", "", errors.New("pkce is required")
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.seq++
+	code := "code-" + strconv.Itoa(i.seq)
+	i.codes[code] = &authRequest{
+		claims:      claims,
+		nonce:       query.Get("nonce"),
+		challenge:   query.Get("code_challenge"),
+		redirectURI: query.Get("redirect_uri"),
+	}
+	return code, query.Get("state"), nil
+}
+
+// SignIDToken 使用身份提供方的密钥签发 id_token
+func (i *Issuer) SignIDToken(claims map[string]interface{}) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": keyID, "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, i.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func (i *Issuer) handleDiscovery(rsp http.ResponseWriter, _ *http.Request) {
+	writeJSON(rsp, http.StatusOK, map[string]string{
+		"issuer":                 i.URL,
+		"authorization_endpoint": i.URL + "/authorize",
+		"token_endpoint":         i.URL + "/token",
+		"userinfo_endpoint":      i.URL + "/userinfo",
+		"jwks_uri":               i.URL + "/jwks",
+	})
+}
+
+func (i *Issuer) handleJWKS(rsp http.ResponseWriter, _ *http.Request) {
+	pub := i.key.PublicKey
+	writeJSON(rsp, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (i *Issuer) handleToken(rsp http.ResponseWriter, req *http.Request) {
+	if err := req.ParseForm(); err != nil || req.PostForm.Get("grant_type") != "authorization_code" {
+		writeError(rsp, "invalid_request")
+		return
+	}
+	if i.ClientSecret != "" {
+		id, secret, ok := req.BasicAuth()
+		if !ok || id != i.ClientID || secret != i.ClientSecret {
+			writeJSON(rsp, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+			return
+		}
+	}
+
+	i.lock.Lock()
+	code := req.PostForm.Get("code")
+	authReq, ok := i.codes[code]
+	delete(i.codes, code)
+	i.lock.Unlock()
+	if !ok || authReq.redirectURI != req.PostForm.Get("redirect_uri") {
+		writeError(rsp, "invalid_grant")
+		return
+	}
+	sum := sha256.Sum256([]byte(req.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != authReq.challenge {
+		writeError(rsp, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":   i.URL,
+		"aud":   i.ClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": authReq.nonce,
+	}
+	for k, v := range authReq.claims {
+		claims[k] = v
+	}
+	idToken, err := i.SignIDToken(claims)
+	if err != nil {
+		writeError(rsp, "server_error")
+		return
+	}
+
+	i.lock.Lock()
+	i.codes["access/"+code] = authReq
+	i.lock.Unlock()
+	writeJSON(rsp, http.StatusOK, map[string]interface{}{
+		"access_token": "access/" + code,
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+		"expires_in":   300,
+	})
+}
+
+func (i *Issuer) handleUserInfo(rsp http.ResponseWriter, req *http.Request) {
+	accessToken := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	i.lock.Lock()
+	authReq, ok := i.codes[accessToken]
+	i.lock.Unlock()
+	if !ok {
+		writeJSON(rsp, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+		return
+	}
+	if i.UserInfo != nil {
+		writeJSON(rsp, http.StatusOK, i.UserInfo)
+		return
+	}
+	writeJSON(rsp, http.StatusOK, authReq.claims)
+}
+
+func writeError(rsp http.ResponseWriter, code string) {
+	writeJSON(rsp, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(rsp http.ResponseWriter, status int, body interface{}) {
+	rsp.Header().Set("Content-Type", "application/json")
+	rsp.WriteHeader(status)
+	_ = json.NewEncoder(rsp).Encode(body)
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/polarismesh/polaris/common/rsa"
 	commontime "github.com/polarismesh/polaris/common/time"
 	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/plugin"
 )
 
 type (
@@ -68,7 +69,7 @@ func (s *Server) GetConfigFileWithCache(ctx context.Context,
 	if req.GetVersion().GetValue() >= release.Version {
 		return api.NewConfigClientResponse(apimodel.Code_DataNoChange, req)
 	}
-	configFile, err := toClientInfo(req, release)
+	configFile, err := toClientInfo(req, release, s.cryptoManager)
 	if err != nil {
 		log.Error("[Config][Service] get config file to client", utils.RequestID(ctx), zap.Error(err))
 		return api.NewConfigClientResponseWithInfo(apimodel.Code_ExecuteException, err.Error())
@@ -247,8 +248,8 @@ func (s *Server) checkClientConfigFile(ctx context.Context, files []*apiconfig.C
 	return api.NewConfigClientResponse(apimodel.Code_DataNoChange, nil), true
 }
 
-func toClientInfo(client *apiconfig.ClientConfigFileInfo,
-	release *model.ConfigFileRelease) (*apiconfig.ClientConfigFileInfo, error) {
+func toClientInfo(client *apiconfig.ClientConfigFileInfo, release *model.ConfigFileRelease,
+	cryptoManager plugin.CryptoManager) (*apiconfig.ClientConfigFileInfo, error) {
 
 	namespace := client.GetNamespace().GetValue()
 	group := client.GetGroup().GetValue()
@@ -278,11 +279,24 @@ func toClientInfo(client *apiconfig.ClientConfigFileInfo,
 	dataKey := release.GetEncryptDataKey()
 	encryptAlgo := release.GetEncryptAlgo()
 	if dataKey != "" && encryptAlgo != "" {
-		dataKeyBytes, err := base64.StdEncoding.DecodeString(dataKey)
+		var crypto plugin.Crypto
+		if cryptoManager != nil {
+			crypto, _ = cryptoManager.GetCrypto(encryptAlgo)
+		}
+		dataKeyBytes, err := plugin.DecodeDataKey(crypto, dataKey)
 		if err != nil {
 			log.Error("[Config][Service] decode data key error.", zap.String("dataKey", dataKey), zap.Error(err))
 			return nil, err
 		}
+		// 数据密钥被主密钥保护时，客户端拿到的是解开后的数据密钥以及内容加密算法
+		if wrapper, ok := crypto.(plugin.KeyWrapper); ok {
+			dataKey = base64.StdEncoding.EncodeToString(dataKeyBytes)
+			for _, tag := range configFile.Tags {
+				if tag.GetKey().GetValue() == model.MetaKeyConfigFileEncryptAlgo {
+					tag.Value = utils.NewStringValue(wrapper.ContentAlgorithm())
+				}
+			}
+		}
 		if publicKey != "" {
 			cipherDataKey, err := rsa.EncryptToBase64(dataKeyBytes, publicKey)
 			if err != nil {
@@ -19,7 +19,6 @@ package config
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 
 	apiconfig "github.com/polarismesh/specification/source/go/api/v1/config_manage"
@@ -29,6 +28,7 @@ import (
 	api "github.com/polarismesh/polaris/common/api/v1"
 	"github.com/polarismesh/polaris/common/model"
 	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/plugin"
 )
 
 // ConfigFileChain
@@ -187,7 +187,7 @@ func (chain *CryptoConfigFileChain) decryptConfigFileContent(dataKey, algorithm,
 	if crypto == nil {
 		return "", nil
 	}
-	dateKeyBytes, err := base64.StdEncoding.DecodeString(dataKey)
+	dateKeyBytes, err := plugin.DecodeDataKey(crypto, dataKey)
 	if err != nil {
 		return "", err
 	}
@@ -226,7 +226,7 @@ func (chain *CryptoConfigFileChain) encryptConfigFile(ctx context.Context, confi
 			return err
 		}
 	} else {
-		dateKeyBytes, err = base64.StdEncoding.DecodeString(dataKey)
+		dateKeyBytes, err = plugin.DecodeDataKey(crypto, dataKey)
 		if err != nil {
 			return err
 		}
@@ -236,11 +236,15 @@ func (chain *CryptoConfigFileChain) encryptConfigFile(ctx context.Context, confi
 	if err != nil {
 		return err
 	}
+	encodedDataKey, err := plugin.EncodeDataKey(crypto, dateKeyBytes)
+	if err != nil {
+		return err
+	}
 	configFile.Content = cipherContent
 	if len(configFile.Metadata) == 0 {
 		configFile.Metadata = map[string]string{}
 	}
-	configFile.Metadata[model.MetaKeyConfigFileDataKey] = base64.StdEncoding.EncodeToString(dateKeyBytes)
+	configFile.Metadata[model.MetaKeyConfigFileDataKey] = encodedDataKey
 	configFile.Metadata[model.MetaKeyConfigFileEncryptAlgo] = algorithm
 	configFile.Metadata[model.MetaKeyConfigFileUseEncrypted] = "true"
 
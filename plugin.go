@@ -18,6 +18,8 @@
 package main
 
 import (
+	_ "github.com/polarismesh/polaris/apiserver/consulserver"
+	_ "github.com/polarismesh/polaris/apiserver/dnsserver"
 	_ "github.com/polarismesh/polaris/apiserver/eurekaserver"
 	_ "github.com/polarismesh/polaris/apiserver/grpcserver/config"
 	_ "github.com/polarismesh/polaris/apiserver/grpcserver/discover"
@@ -33,20 +35,29 @@ import (
 	_ "github.com/polarismesh/polaris/cache/namespace"
 	_ "github.com/polarismesh/polaris/cache/service"
 	_ "github.com/polarismesh/polaris/config/interceptor"
+	_ "github.com/polarismesh/polaris/plugin/changenotifier/local"
+	_ "github.com/polarismesh/polaris/plugin/changenotifier/peer"
+	_ "github.com/polarismesh/polaris/plugin/cmdb/file"
 	_ "github.com/polarismesh/polaris/plugin/cmdb/memory"
 	_ "github.com/polarismesh/polaris/plugin/crypto/aes"
+	_ "github.com/polarismesh/polaris/plugin/crypto/kms"
 	_ "github.com/polarismesh/polaris/plugin/discoverevent/local"
+	_ "github.com/polarismesh/polaris/plugin/discoverevent/webhook"
 	_ "github.com/polarismesh/polaris/plugin/healthchecker/leader"
 	_ "github.com/polarismesh/polaris/plugin/healthchecker/memory"
+	_ "github.com/polarismesh/polaris/plugin/healthchecker/probe"
 	_ "github.com/polarismesh/polaris/plugin/healthchecker/redis"
 	_ "github.com/polarismesh/polaris/plugin/history/logger"
+	_ "github.com/polarismesh/polaris/plugin/history/storage"
 	_ "github.com/polarismesh/polaris/plugin/password"
 	_ "github.com/polarismesh/polaris/plugin/ratelimit/lrurate"
 	_ "github.com/polarismesh/polaris/plugin/ratelimit/token"
 	_ "github.com/polarismesh/polaris/plugin/statis/logger"
+	_ "github.com/polarismesh/polaris/plugin/statis/otlp"
 	_ "github.com/polarismesh/polaris/plugin/statis/prometheus"
 	_ "github.com/polarismesh/polaris/plugin/whitelist"
 	_ "github.com/polarismesh/polaris/service/interceptor"
 	_ "github.com/polarismesh/polaris/store/boltdb"
 	_ "github.com/polarismesh/polaris/store/mysql"
+	_ "github.com/polarismesh/polaris/store/raftdb"
 )
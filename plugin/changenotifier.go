@@ -0,0 +1,53 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package plugin
+
+import (
+	"os"
+	"sync"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+var (
+	changeNotifierOnce sync.Once
+)
+
+// ChangeNotifier 存储变更通知插件，写入数据的节点发布变更的资源，各个节点的缓存据此做定向的增量刷新
+type ChangeNotifier interface {
+	Plugin
+	// Publish 发布变更事件
+	Publish(events []*model.ChangeEvent)
+	// Subscribe 订阅变更事件，包括本节点以及其他节点发布的事件
+	Subscribe(handler func(events []*model.ChangeEvent))
+}
+
+// GetChangeNotifier 获取存储变更通知插件，未配置时返回 nil，缓存退化为定时轮询存储
+func GetChangeNotifier() ChangeNotifier {
+	c := &config.ChangeNotifier
+	plugin, exist := pluginSet[c.Name]
+	if !exist {
+		return nil
+	}
+	changeNotifierOnce.Do(func() {
+		if err := plugin.Initialize(c); err != nil {
+			log.Errorf("ChangeNotifier plugin init err: %s", err.Error())
+			os.Exit(-1)
+		}
+	})
+	return plugin.(ChangeNotifier)
+}
@@ -0,0 +1,136 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package local
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+const (
+	// maxDispatchBatch 单次分发给订阅者的最大事件批次数量
+	maxDispatchBatch = 64
+)
+
+// Dispatcher 将变更事件异步分发给本节点的订阅者，发布方不会被订阅者阻塞
+type Dispatcher struct {
+	queue    chan []*model.ChangeEvent
+	lock     sync.RWMutex
+	handlers []func(events []*model.ChangeEvent)
+	overflow atomic.Bool
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewDispatcher 创建变更事件分发器
+func NewDispatcher(queueSize int) *Dispatcher {
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+	return &Dispatcher{
+		queue: make(chan []*model.ChangeEvent, queueSize),
+	}
+}
+
+// Start 启动分发协程
+func (d *Dispatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.run(ctx)
+	}()
+}
+
+// Stop 停止分发协程
+func (d *Dispatcher) Stop() {
+	if d.cancel != nil {
+		d.cancel()
+		d.wg.Wait()
+	}
+}
+
+// Subscribe 订阅变更事件
+func (d *Dispatcher) Subscribe(handler func(events []*model.ChangeEvent)) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.handlers = append(d.handlers, handler)
+}
+
+// Dispatch 投递变更事件，队列已满时丢弃事件，并在下一次分发时通知订阅者全量刷新
+func (d *Dispatcher) Dispatch(events []*model.ChangeEvent) {
+	if len(events) == 0 {
+		return
+	}
+	select {
+	case d.queue <- events:
+	default:
+		if !d.overflow.Swap(true) {
+			log.Warn("[ChangeNotifier] change event queue is full, subscribers will resync all caches")
+		}
+		// 保证队列满的情况下订阅者也能感知到事件丢失
+		select {
+		case d.queue <- []*model.ChangeEvent{{Resource: model.ChangeAll}}:
+		default:
+		}
+	}
+}
+
+func (d *Dispatcher) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case events := <-d.queue:
+			// 合并队列中积压的事件，减少订阅者的刷新次数
+		merge:
+			for i := 1; i < maxDispatchBatch; i++ {
+				select {
+				case more := <-d.queue:
+					events = append(events, more...)
+				default:
+					break merge
+				}
+			}
+			if d.overflow.Swap(false) {
+				events = append(events, &model.ChangeEvent{Resource: model.ChangeAll})
+			}
+			d.notify(events)
+		}
+	}
+}
+
+func (d *Dispatcher) notify(events []*model.ChangeEvent) {
+	d.lock.RLock()
+	handlers := d.handlers
+	d.lock.RUnlock()
+	for i := range handlers {
+		func() {
+			defer func() {
+				if err := recover(); err != nil {
+					log.Errorf("[ChangeNotifier] handle change events panic: %+v, stack\n%s", err, string(debug.Stack()))
+				}
+			}()
+			handlers[i](events)
+		}()
+	}
+}
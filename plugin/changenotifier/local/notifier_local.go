@@ -0,0 +1,88 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package local
+
+import (
+	"encoding/json"
+
+	commonlog "github.com/polarismesh/polaris/common/log"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/plugin"
+)
+
+const (
+	// PluginName 进程内的存储变更通知，适用于单节点部署或者各节点独立存储的场景
+	PluginName       = "changeNotifierLocal"
+	defaultQueueSize = 1024
+)
+
+var log = commonlog.RegisterScope(PluginName, "", 0)
+
+func init() {
+	n := &changeNotifierLocal{}
+	plugin.RegisterPlugin(n.Name(), n)
+}
+
+// Config 进程内变更通知的配置
+type Config struct {
+	// QueueSize 待分发的变更事件队列长度
+	QueueSize int `json:"queueSize"`
+}
+
+type changeNotifierLocal struct {
+	dispatcher *Dispatcher
+}
+
+// Name 插件名称
+func (n *changeNotifierLocal) Name() string {
+	return PluginName
+}
+
+// Initialize 初始化插件
+func (n *changeNotifierLocal) Initialize(conf *plugin.ConfigEntry) error {
+	cfg := &Config{QueueSize: defaultQueueSize}
+	if len(conf.Option) != 0 {
+		data, err := json.Marshal(conf.Option)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return err
+		}
+	}
+	n.dispatcher = NewDispatcher(cfg.QueueSize)
+	n.dispatcher.Start()
+	return nil
+}
+
+// Destroy 销毁插件
+func (n *changeNotifierLocal) Destroy() error {
+	if n.dispatcher != nil {
+		n.dispatcher.Stop()
+	}
+	return nil
+}
+
+// Publish 发布变更事件
+func (n *changeNotifierLocal) Publish(events []*model.ChangeEvent) {
+	n.dispatcher.Dispatch(events)
+}
+
+// Subscribe 订阅变更事件
+func (n *changeNotifierLocal) Subscribe(handler func(events []*model.ChangeEvent)) {
+	n.dispatcher.Subscribe(handler)
+}
@@ -0,0 +1,70 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package local
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/plugin"
+)
+
+func TestChangeNotifierLocal(t *testing.T) {
+	n := &changeNotifierLocal{}
+	assert.NoError(t, n.Initialize(&plugin.ConfigEntry{Name: PluginName}))
+	defer func() {
+		_ = n.Destroy()
+	}()
+
+	received := make(chan []*model.ChangeEvent, 8)
+	n.Subscribe(func(events []*model.ChangeEvent) {
+		received <- events
+	})
+	n.Publish([]*model.ChangeEvent{{Resource: model.ChangeInstance, ID: "ins-1", Revision: "rev-1"}})
+
+	select {
+	case events := <-received:
+		assert.Len(t, events, 1)
+		assert.Equal(t, "ins-1", events[0].ID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("change events not received")
+	}
+}
+
+func TestDispatcher_Overflow(t *testing.T) {
+	d := NewDispatcher(1)
+	received := make(chan []*model.ChangeEvent, 8)
+	d.Subscribe(func(events []*model.ChangeEvent) {
+		received <- events
+	})
+	// 分发协程未启动，第二批事件会因为队列已满而丢弃
+	d.Dispatch([]*model.ChangeEvent{{Resource: model.ChangeService, ID: "svc-1"}})
+	d.Dispatch([]*model.ChangeEvent{{Resource: model.ChangeService, ID: "svc-2"}})
+	d.Start()
+	defer d.Stop()
+
+	select {
+	case events := <-received:
+		last := events[len(events)-1]
+		assert.Equal(t, model.ChangeAll, last.Resource)
+	case <-time.After(5 * time.Second):
+		t.Fatal("change events not received")
+	}
+}
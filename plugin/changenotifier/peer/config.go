@@ -0,0 +1,96 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package peer
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Config 节点间变更通知的配置
+type Config struct {
+	// QueueSize 本节点待分发以及待广播的变更事件队列长度
+	QueueSize int `json:"queueSize"`
+	// ListenAddress 接收其他节点变更事件的监听地址
+	ListenAddress string `json:"listenAddress"`
+	// Peers 其他 polaris 节点接收变更事件的地址，格式为 host:port，可以包含本节点，本节点发出的事件会被忽略
+	Peers []string `json:"peers"`
+	// Secret 节点间共享的 HMAC-SHA256 签名密钥，必须配置，避免任意客户端伪造变更事件
+	Secret string `json:"secret"`
+	// BatchSize 单次广播的最大事件数量
+	BatchSize int `json:"batchSize"`
+	// FlushInterval 广播事件的合并等待时间
+	FlushInterval string `json:"flushInterval"`
+	// Timeout 广播请求的超时时间
+	Timeout string `json:"timeout"`
+
+	flushInterval time.Duration
+	timeout       time.Duration
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		QueueSize:     4096,
+		ListenAddress: "0.0.0.0:8766",
+		BatchSize:     512,
+		FlushInterval: "20ms",
+		Timeout:       "1s",
+	}
+}
+
+// Validate 检查配置是否正确配置
+func (c *Config) Validate() error {
+	if c.QueueSize <= 0 {
+		return errors.New("QueueSize is <= 0")
+	}
+	if c.BatchSize <= 0 {
+		return errors.New("BatchSize is <= 0")
+	}
+	if c.Secret == "" {
+		return errors.New("Secret is empty")
+	}
+	if _, _, err := net.SplitHostPort(c.ListenAddress); err != nil {
+		return fmt.Errorf("ListenAddress %s is invalid: %w", c.ListenAddress, err)
+	}
+	for i, peer := range c.Peers {
+		if _, _, err := net.SplitHostPort(peer); err != nil {
+			return fmt.Errorf("Peers[%d] %s is invalid: %w", i, peer, err)
+		}
+	}
+	var err error
+	if c.flushInterval, err = parsePositiveDuration("FlushInterval", c.FlushInterval); err != nil {
+		return err
+	}
+	if c.timeout, err = parsePositiveDuration("Timeout", c.Timeout); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parsePositiveDuration(name, value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s %s is invalid: %w", name, value, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s is <= 0", name)
+	}
+	return d, nil
+}
@@ -0,0 +1,287 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package peer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	commonlog "github.com/polarismesh/polaris/common/log"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/plugin/changenotifier/local"
+)
+
+const (
+	// PluginName 节点间的存储变更通知，写入节点把变更事件广播给其他 polaris 节点
+	PluginName = "changeNotifierPeer"
+
+	// ChangesPath 接收变更事件的请求路径
+	ChangesPath = "/changes"
+	// HeaderTimestamp 广播时间戳，参与签名
+	HeaderTimestamp = "X-Polaris-Timestamp"
+	// HeaderSignature 广播内容签名，格式为 hex(hmac-sha256(secret, timestamp + "." + body))
+	HeaderSignature = "X-Polaris-Signature"
+
+	maxBodySize      = 8 << 20
+	maxTimestampSkew = 5 * time.Minute
+)
+
+var log = commonlog.RegisterScope(PluginName, "", 0)
+
+func init() {
+	n := &changeNotifierPeer{}
+	plugin.RegisterPlugin(n.Name(), n)
+}
+
+// Payload 节点间广播的变更事件
+type Payload struct {
+	// Source 发布事件的节点
+	Source string               `json:"source"`
+	Events []*model.ChangeEvent `json:"events"`
+}
+
+type changeNotifierPeer struct {
+	cfg        *Config
+	nodeID     string
+	dispatcher *local.Dispatcher
+	broadcast  chan []*model.ChangeEvent
+	client     *http.Client
+	server     *http.Server
+	listener   net.Listener
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// Name 插件名称
+func (n *changeNotifierPeer) Name() string {
+	return PluginName
+}
+
+// Initialize 初始化插件，启动变更事件的接收服务以及广播协程
+func (n *changeNotifierPeer) Initialize(conf *plugin.ConfigEntry) error {
+	data, err := json.Marshal(conf.Option)
+	if err != nil {
+		return err
+	}
+	cfg := DefaultConfig()
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", cfg.ListenAddress)
+	if err != nil {
+		return err
+	}
+
+	n.cfg = cfg
+	n.nodeID = utils.NewUUID()
+	n.dispatcher = local.NewDispatcher(cfg.QueueSize)
+	n.broadcast = make(chan []*model.ChangeEvent, cfg.QueueSize)
+	n.client = &http.Client{Timeout: cfg.timeout}
+	n.listener = listener
+	mux := http.NewServeMux()
+	mux.HandleFunc(ChangesPath, n.handleChanges)
+	n.server = &http.Server{Handler: mux, ReadHeaderTimeout: cfg.timeout}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n.cancel = cancel
+	n.dispatcher.Start()
+	n.wg.Add(2)
+	go func() {
+		defer n.wg.Done()
+		if err := n.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("[ChangeNotifier][Peer] serve on %s fail: %s", cfg.ListenAddress, err.Error())
+		}
+	}()
+	go func() {
+		defer n.wg.Done()
+		n.runBroadcast(ctx)
+	}()
+	log.Infof("[ChangeNotifier][Peer] node %s listen on %s, peers %v", n.nodeID, listener.Addr(), cfg.Peers)
+	return nil
+}
+
+// Destroy 销毁插件
+func (n *changeNotifierPeer) Destroy() error {
+	if n.cancel == nil {
+		return nil
+	}
+	n.cancel()
+	_ = n.server.Close()
+	n.wg.Wait()
+	n.dispatcher.Stop()
+	return nil
+}
+
+// Publish 本节点的订阅者立即收到事件，同时异步广播给其他节点
+func (n *changeNotifierPeer) Publish(events []*model.ChangeEvent) {
+	if len(events) == 0 {
+		return
+	}
+	n.dispatcher.Dispatch(events)
+	if len(n.cfg.Peers) == 0 {
+		return
+	}
+	select {
+	case n.broadcast <- events:
+	default:
+		// 广播失败时其他节点依赖缓存的兜底轮询
+		log.Warn("[ChangeNotifier][Peer] broadcast queue is full, drop change events")
+	}
+}
+
+// Subscribe 订阅变更事件
+func (n *changeNotifierPeer) Subscribe(handler func(events []*model.ChangeEvent)) {
+	n.dispatcher.Subscribe(handler)
+}
+
+func (n *changeNotifierPeer) runBroadcast(ctx context.Context) {
+	ticker := time.NewTicker(n.cfg.flushInterval)
+	defer ticker.Stop()
+
+	pending := make([]*model.ChangeEvent, 0, n.cfg.BatchSize)
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		n.send(ctx, pending)
+		pending = make([]*model.ChangeEvent, 0, n.cfg.BatchSize)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case events := <-n.broadcast:
+			pending = append(pending, events...)
+			if len(pending) >= n.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// send 把变更事件并发发送给所有节点，发送失败不重试，由缓存的兜底轮询保证最终一致
+func (n *changeNotifierPeer) send(ctx context.Context, events []*model.ChangeEvent) {
+	body, err := json.Marshal(&Payload{Source: n.nodeID, Events: events})
+	if err != nil {
+		log.Errorf("[ChangeNotifier][Peer] marshal change events fail: %s", err.Error())
+		return
+	}
+	var wg sync.WaitGroup
+	for i := range n.cfg.Peers {
+		peer := n.cfg.Peers[i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := n.post(ctx, peer, body); err != nil {
+				log.Warnf("[ChangeNotifier][Peer] send %d change events to %s fail: %s", len(events), peer, err.Error())
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (n *changeNotifierPeer) post(ctx context.Context, peer string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+peer+ChangesPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(n.cfg.Secret, timestamp, body))
+	rsp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	_, _ = io.Copy(io.Discard, rsp.Body)
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", rsp.StatusCode)
+	}
+	return nil
+}
+
+// handleChanges 接收其他节点广播的变更事件，只分发给本节点的订阅者，不会再次广播
+func (n *changeNotifierPeer) handleChanges(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err := n.verify(r, body); err != nil {
+		log.Warnf("[ChangeNotifier][Peer] reject change events from %s: %s", r.RemoteAddr, err.Error())
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	payload := &Payload{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if payload.Source != n.nodeID {
+		n.dispatcher.Dispatch(payload.Events)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (n *changeNotifierPeer) verify(r *http.Request, body []byte) error {
+	timestamp := r.Header.Get(HeaderTimestamp)
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid timestamp")
+	}
+	if skew := time.Since(time.Unix(sec, 0)); skew > maxTimestampSkew || skew < -maxTimestampSkew {
+		return errors.New("timestamp expired")
+	}
+	expect := Sign(n.cfg.Secret, timestamp, body)
+	if !hmac.Equal([]byte(expect), []byte(r.Header.Get(HeaderSignature))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// Sign 计算广播内容的签名
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,124 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package peer
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/plugin"
+)
+
+func newTestNotifier(t *testing.T, peers []string) *changeNotifierPeer {
+	n := &changeNotifierPeer{}
+	err := n.Initialize(&plugin.ConfigEntry{
+		Name: PluginName,
+		Option: map[string]interface{}{
+			"listenAddress": "127.0.0.1:0",
+			"peers":         peers,
+			"secret":        "polaris",
+			"flushInterval": "10ms",
+		},
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = n.Destroy()
+	})
+	return n
+}
+
+func TestChangeNotifierPeer(t *testing.T) {
+	receiver := newTestNotifier(t, nil)
+	received := make(chan []*model.ChangeEvent, 8)
+	receiver.Subscribe(func(events []*model.ChangeEvent) {
+		received <- events
+	})
+
+	sender := newTestNotifier(t, []string{receiver.listener.Addr().String()})
+	local := make(chan []*model.ChangeEvent, 8)
+	sender.Subscribe(func(events []*model.ChangeEvent) {
+		local <- events
+	})
+	sender.Publish([]*model.ChangeEvent{{Resource: model.ChangeInstance, ID: "ins-1", Revision: "rev-1"}})
+
+	for _, ch := range []chan []*model.ChangeEvent{local, received} {
+		select {
+		case events := <-ch:
+			assert.Len(t, events, 1)
+			assert.Equal(t, "ins-1", events[0].ID)
+			assert.Equal(t, "rev-1", events[0].Revision)
+		case <-time.After(5 * time.Second):
+			t.Fatal("change events not received")
+		}
+	}
+}
+
+func TestChangeNotifierPeer_Verify(t *testing.T) {
+	receiver := newTestNotifier(t, nil)
+	received := make(chan []*model.ChangeEvent, 8)
+	receiver.Subscribe(func(events []*model.ChangeEvent) {
+		received <- events
+	})
+	url := "http://" + receiver.listener.Addr().String() + ChangesPath
+
+	post := func(body []byte, timestamp string) int {
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		assert.NoError(t, err)
+		if timestamp != "" {
+			req.Header.Set(HeaderTimestamp, timestamp)
+			req.Header.Set(HeaderSignature, Sign("polaris", timestamp, body))
+		}
+		rsp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		_ = rsp.Body.Close()
+		return rsp.StatusCode
+	}
+
+	body := []byte(`{"source":"other","events":[{"resource":"service"}]}`)
+	// 未签名
+	assert.Equal(t, http.StatusUnauthorized, post(body, ""))
+	// 时间戳过期
+	assert.Equal(t, http.StatusUnauthorized, post(body, "1"))
+	// 本节点发出的事件被忽略
+	self := []byte(`{"source":"` + receiver.nodeID + `","events":[{"resource":"instance"}]}`)
+	assert.Equal(t, http.StatusOK, post(self, strconv.FormatInt(time.Now().Unix(), 10)))
+	assert.Equal(t, http.StatusOK, post(body, strconv.FormatInt(time.Now().Unix(), 10)))
+
+	select {
+	case events := <-received:
+		assert.Len(t, events, 1)
+		assert.Equal(t, model.ChangeService, events[0].Resource)
+	case <-time.After(5 * time.Second):
+		t.Fatal("change events not received")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Secret = "polaris"
+	assert.NoError(t, cfg.Validate())
+
+	// 没有配置签名密钥时任意客户端都可以伪造变更事件
+	cfg.Secret = ""
+	assert.Error(t, cfg.Validate())
+}
@@ -0,0 +1,194 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package file
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"go.uber.org/zap"
+
+	commonlog "github.com/polarismesh/polaris/common/log"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/plugin"
+)
+
+const (
+	// PluginName plugin name
+	PluginName = "file"
+)
+
+var (
+	log = commonlog.GetScopeOrDefaultByName("cmdb")
+)
+
+// init 自注册到插件列表
+func init() {
+	plugin.RegisterPlugin(PluginName, &FileCMDB{})
+}
+
+// Config 插件配置
+type Config struct {
+	// Path 本地文件路径，支持 csv/yaml/json
+	Path string `mapstructure:"path"`
+	// URL 远程地址，和 Path 二选一
+	URL string `mapstructure:"url"`
+	// Token 请求远程地址时携带的 Authorization 头部
+	Token string `mapstructure:"token"`
+	// Format 数据格式 csv/yaml，为空时根据文件后缀判断
+	Format string `mapstructure:"format"`
+	// Interval 检查数据是否变化的周期
+	Interval time.Duration `mapstructure:"interval"`
+	// Timeout 请求远程地址的超时时间
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// FileCMDB 从文件或者远程地址加载 host 与位置信息的映射，数据变化后自动重新加载
+type FileCMDB struct {
+	source Source
+	cancel context.CancelFunc
+	table  atomic.Value
+}
+
+// Name 返回插件名
+func (m *FileCMDB) Name() string {
+	return PluginName
+}
+
+// Initialize 初始化函数
+func (m *FileCMDB) Initialize(c *plugin.ConfigEntry) error {
+	cfg := &Config{
+		Interval: 10 * time.Second,
+		Timeout:  10 * time.Second,
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     cfg,
+	})
+	if err != nil {
+		return err
+	}
+	if err := decoder.Decode(c.Option); err != nil {
+		return err
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Second
+	}
+
+	switch {
+	case cfg.Path != "":
+		m.source, err = newFileSource(cfg.Path, cfg.Format)
+	case cfg.URL != "":
+		m.source, err = newHTTPSource(cfg.URL, cfg.Token, cfg.Format, cfg.Timeout)
+	default:
+		err = errors.New("[CMDB][File] path or url is required")
+	}
+	if err != nil {
+		return err
+	}
+
+	// 首次加载失败直接报错，避免以空数据启动
+	if err := m.reload(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	go m.watch(ctx, cfg.Interval)
+	return nil
+}
+
+// reload 数据发生变化时重新加载，解析失败时保留旧的数据
+func (m *FileCMDB) reload() error {
+	data, format, changed, err := m.source.Load()
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	table, err := Parse(format, data)
+	if err != nil {
+		return err
+	}
+	m.table.Store(table)
+	log.Info("[CMDB][File] reload cmdb data", zap.String("source", m.source.String()),
+		zap.Int32("size", table.Size()))
+	return nil
+}
+
+func (m *FileCMDB) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.reload(); err != nil {
+				log.Error("[CMDB][File] reload cmdb data fail, keep the last data",
+					zap.String("source", m.source.String()), zap.Error(err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Destroy 销毁函数
+func (m *FileCMDB) Destroy() error {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	return nil
+}
+
+func (m *FileCMDB) load() *Table {
+	val := m.table.Load()
+	if val == nil {
+		return nil
+	}
+	return val.(*Table)
+}
+
+// GetLocation 实现CMDB插件接口
+func (m *FileCMDB) GetLocation(host string) (*model.Location, error) {
+	table := m.load()
+	if table == nil {
+		return nil, nil
+	}
+	return table.Lookup(host), nil
+}
+
+// Range 实现CMDB插件接口
+func (m *FileCMDB) Range(handler func(host string, location *model.Location) (bool, error)) error {
+	table := m.load()
+	if table == nil {
+		return nil
+	}
+	return table.Range(handler)
+}
+
+// Size 实现CMDB插件接口
+func (m *FileCMDB) Size() int32 {
+	table := m.load()
+	if table == nil {
+		return 0
+	}
+	return table.Size()
+}
@@ -0,0 +1,151 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package file
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/plugin"
+)
+
+const testCSV = `ip,region,zone,campus,region_id,zone_id,campus_id
+# single host
+127.0.0.1,ap-guangzhou,ap-guangzhou-1,campus-1,1,11,111
+10.0.0.0/8,ap-shanghai,ap-shanghai-1,campus-2
+10.1.0.0/16,ap-shanghai,ap-shanghai-2,campus-3
+*,ap-beijing,ap-beijing-1,campus-4
+`
+
+const testYAML = `
+entries:
+  - ip: 127.0.0.1
+    region: ap-chengdu
+    zone: ap-chengdu-1
+    campus: campus-5
+  - ip: 192.168.0.0/16
+    region: ap-chengdu
+    zone: ap-chengdu-2
+    campus: campus-6
+`
+
+func newTestCMDB(t *testing.T, option map[string]interface{}) *FileCMDB {
+	m := &FileCMDB{}
+	assert.NoError(t, m.Initialize(&plugin.ConfigEntry{Name: PluginName, Option: option}))
+	t.Cleanup(func() {
+		_ = m.Destroy()
+	})
+	return m
+}
+
+func zoneOf(t *testing.T, m *FileCMDB, host string) string {
+	loc, err := m.GetLocation(host)
+	assert.NoError(t, err)
+	if loc == nil {
+		return ""
+	}
+	return loc.Proto.GetZone().GetValue()
+}
+
+func TestFileCMDB_CSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cmdb.csv")
+	assert.NoError(t, os.WriteFile(path, []byte(testCSV), 0600))
+	m := newTestCMDB(t, map[string]interface{}{"path": path, "interval": "50ms"})
+
+	loc, err := m.GetLocation("127.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ap-guangzhou", loc.Proto.GetRegion().GetValue())
+	assert.Equal(t, uint32(11), loc.ZoneID)
+	// longest prefix first
+	assert.Equal(t, "ap-shanghai-2", zoneOf(t, m, "10.1.2.3"))
+	assert.Equal(t, "ap-shanghai-1", zoneOf(t, m, "10.2.2.3"))
+	assert.Equal(t, "ap-beijing-1", zoneOf(t, m, "172.16.0.1"))
+	assert.Equal(t, int32(4), m.Size())
+
+	hosts := map[string]string{}
+	assert.NoError(t, m.Range(func(host string, location *model.Location) (bool, error) {
+		hosts[host] = location.Proto.GetCampus().GetValue()
+		return true, nil
+	}))
+	assert.Equal(t, "campus-3", hosts["10.1.0.0/16"])
+	assert.Equal(t, "campus-4", hosts[DefaultEntry])
+
+	// invalid content keep the last data
+	assert.NoError(t, os.WriteFile(path, []byte("bad-ip,a,b,c\n"), 0600))
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, "ap-guangzhou-1", zoneOf(t, m, "127.0.0.1"))
+
+	// hot reload after the file changed
+	assert.NoError(t, os.WriteFile(path, []byte("127.0.0.1,ap-nanjing,ap-nanjing-1,campus-7\n"), 0600))
+	assert.Eventually(t, func() bool {
+		return zoneOf(t, m, "127.0.0.1") == "ap-nanjing-1"
+	}, 3*time.Second, 50*time.Millisecond)
+	assert.Equal(t, "", zoneOf(t, m, "10.1.2.3"))
+	assert.Equal(t, int32(1), m.Size())
+}
+
+func TestFileCMDB_HTTP(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(testYAML))
+	}))
+	defer svr.Close()
+
+	m := newTestCMDB(t, map[string]interface{}{
+		"url":      svr.URL + "/cmdb.yaml",
+		"token":    "token",
+		"interval": "50ms",
+	})
+	assert.Equal(t, "ap-chengdu-1", zoneOf(t, m, "127.0.0.1"))
+	assert.Equal(t, "ap-chengdu-2", zoneOf(t, m, "192.168.1.1"))
+	assert.Equal(t, "", zoneOf(t, m, "10.0.0.1"))
+	assert.Equal(t, int32(2), m.Size())
+
+	source := m.source.(*httpSource)
+	_, _, changed, err := source.Load()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestFileCMDB_Invalid(t *testing.T) {
+	m := &FileCMDB{}
+	assert.Error(t, m.Initialize(&plugin.ConfigEntry{Name: PluginName, Option: map[string]interface{}{}}))
+	assert.Error(t, m.Initialize(&plugin.ConfigEntry{Name: PluginName, Option: map[string]interface{}{
+		"path": filepath.Join(t.TempDir(), "cmdb.txt"),
+	}}))
+
+	_, err := Parse(FormatCSV, []byte("10.0.0.0/33,a,b,c\n"))
+	assert.Error(t, err)
+	_, err = Parse(FormatCSV, []byte("127.0.0.1,a,b\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,159 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package file
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Source cmdb 数据源
+type Source interface {
+	// Load 加载数据，数据没有变化时 changed 为 false
+	Load() (data []byte, format string, changed bool, err error)
+	// String 数据源描述
+	String() string
+}
+
+// formatOf 根据文件后缀判断数据格式
+func formatOf(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCSV
+	case ".yaml", ".yml", ".json":
+		return FormatYAML
+	default:
+		return ""
+	}
+}
+
+// fileSource 本地文件，通过修改时间以及大小判断文件是否发生变化
+type fileSource struct {
+	path    string
+	format  string
+	modTime time.Time
+	size    int64
+}
+
+func newFileSource(path, format string) (*fileSource, error) {
+	if format == "" {
+		format = formatOf(path)
+	}
+	if format == "" {
+		return nil, fmt.Errorf("unknown format of cmdb file %s, please set the format option", path)
+	}
+	return &fileSource{path: path, format: format}, nil
+}
+
+func (s *fileSource) Load() ([]byte, string, bool, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return nil, s.format, false, nil
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, "", false, err
+	}
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	return data, s.format, true, nil
+}
+
+func (s *fileSource) String() string {
+	return "file:" + s.path
+}
+
+// httpSource 远程地址，通过 ETag/Last-Modified 判断数据是否发生变化
+type httpSource struct {
+	url          string
+	token        string
+	format       string
+	client       *http.Client
+	etag         string
+	lastModified string
+}
+
+func newHTTPSource(address, token, format string, timeout time.Duration) (*httpSource, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		format = formatOf(u.Path)
+	}
+	return &httpSource{
+		url:    address,
+		token:  token,
+		format: format,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (s *httpSource) Load() ([]byte, string, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", s.token)
+	}
+	if s.etag != "" {
+		req.Header.Set("If-None-Match", s.etag)
+	}
+	if s.lastModified != "" {
+		req.Header.Set("If-Modified-Since", s.lastModified)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, s.format, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", false, fmt.Errorf("fetch cmdb data from %s fail, status %d", s.url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", false, err
+	}
+	format := s.format
+	if format == "" {
+		format = FormatYAML
+		if strings.Contains(resp.Header.Get("Content-Type"), "csv") {
+			format = FormatCSV
+		}
+	}
+	s.etag = resp.Header.Get("ETag")
+	s.lastModified = resp.Header.Get("Last-Modified")
+	return data, format, true, nil
+}
+
+func (s *httpSource) String() string {
+	return "url:" + s.url
+}
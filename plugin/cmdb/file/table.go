@@ -0,0 +1,258 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package file
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"gopkg.in/yaml.v2"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+const (
+	// FormatCSV csv 格式，列依次为 ip,region,zone,campus[,region_id,zone_id,campus_id]
+	FormatCSV = "csv"
+	// FormatYAML yaml 格式，json 作为 yaml 的子集同样支持
+	FormatYAML = "yaml"
+
+	// DefaultEntry ip 配置为 * 的条目，所有未匹配的 host 使用该位置信息
+	DefaultEntry = "*"
+)
+
+// Entry 一条 host/网段 与位置信息的映射
+type Entry struct {
+	// IP 单个 host ip，或者 CIDR 网段，或者 *
+	IP       string `yaml:"ip" json:"ip"`
+	Region   string `yaml:"region" json:"region"`
+	Zone     string `yaml:"zone" json:"zone"`
+	Campus   string `yaml:"campus" json:"campus"`
+	RegionID uint32 `yaml:"regionId" json:"regionId"`
+	ZoneID   uint32 `yaml:"zoneId" json:"zoneId"`
+	CampusID uint32 `yaml:"campusId" json:"campusId"`
+}
+
+// yamlFile yaml 文件的结构
+type yamlFile struct {
+	Entries []Entry `yaml:"entries"`
+}
+
+func (e Entry) location() *model.Location {
+	return &model.Location{
+		Proto: &apimodel.Location{
+			Region: wrapperspb.String(e.Region),
+			Zone:   wrapperspb.String(e.Zone),
+			Campus: wrapperspb.String(e.Campus),
+		},
+		RegionID: e.RegionID,
+		ZoneID:   e.ZoneID,
+		CampusID: e.CampusID,
+		Valid:    true,
+	}
+}
+
+// cidrEntry 网段条目
+type cidrEntry struct {
+	cidr     string
+	ipNet    *net.IPNet
+	ones     int
+	location *model.Location
+}
+
+// Table 加载后的 cmdb 数据，创建后只读
+type Table struct {
+	hosts    map[string]*model.Location
+	cidrs    []cidrEntry
+	fallback *model.Location
+}
+
+// NewTable 根据条目构建 cmdb 数据
+func NewTable(entries []Entry) (*Table, error) {
+	t := &Table{
+		hosts: make(map[string]*model.Location, len(entries)),
+		cidrs: make([]cidrEntry, 0, 8),
+	}
+	for i := range entries {
+		entry := entries[i]
+		entry.IP = strings.TrimSpace(entry.IP)
+		switch {
+		case entry.IP == "":
+			return nil, fmt.Errorf("entry %d: ip is empty", i+1)
+		case entry.IP == DefaultEntry:
+			t.fallback = entry.location()
+		case strings.Contains(entry.IP, "/"):
+			_, ipNet, err := net.ParseCIDR(entry.IP)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %w", i+1, err)
+			}
+			ones, _ := ipNet.Mask.Size()
+			t.cidrs = append(t.cidrs, cidrEntry{
+				cidr:     ipNet.String(),
+				ipNet:    ipNet,
+				ones:     ones,
+				location: entry.location(),
+			})
+		default:
+			if net.ParseIP(entry.IP) == nil {
+				return nil, fmt.Errorf("entry %d: invalid ip %s", i+1, entry.IP)
+			}
+			t.hosts[entry.IP] = entry.location()
+		}
+	}
+	// 最长前缀优先匹配
+	sort.SliceStable(t.cidrs, func(i, j int) bool {
+		return t.cidrs[i].ones > t.cidrs[j].ones
+	})
+	return t, nil
+}
+
+// Lookup 依次按照 host、网段、默认条目查找位置信息
+func (t *Table) Lookup(host string) *model.Location {
+	if loc, ok := t.hosts[host]; ok {
+		return loc
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		for i := range t.cidrs {
+			if t.cidrs[i].ipNet.Contains(ip) {
+				return t.cidrs[i].location
+			}
+		}
+	}
+	return t.fallback
+}
+
+// Range 遍历全部条目，网段条目以 CIDR 作为 host
+func (t *Table) Range(handler func(host string, location *model.Location) (bool, error)) error {
+	for host, loc := range t.hosts {
+		next, err := handler(host, loc)
+		if err != nil {
+			return err
+		}
+		if !next {
+			return nil
+		}
+	}
+	for i := range t.cidrs {
+		next, err := handler(t.cidrs[i].cidr, t.cidrs[i].location)
+		if err != nil {
+			return err
+		}
+		if !next {
+			return nil
+		}
+	}
+	if t.fallback != nil {
+		if _, err := handler(DefaultEntry, t.fallback); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Size 条目个数
+func (t *Table) Size() int32 {
+	size := len(t.hosts) + len(t.cidrs)
+	if t.fallback != nil {
+		size++
+	}
+	return int32(size)
+}
+
+// Parse 按照格式解析 cmdb 数据
+func Parse(format string, data []byte) (*Table, error) {
+	var (
+		entries []Entry
+		err     error
+	)
+	switch format {
+	case FormatCSV:
+		entries, err = parseCSV(data)
+	case FormatYAML:
+		entries, err = parseYAML(data)
+	default:
+		return nil, fmt.Errorf("unsupported cmdb format %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewTable(entries)
+}
+
+func parseYAML(data []byte) ([]Entry, error) {
+	file := &yamlFile{}
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, err
+	}
+	return file.Entries, nil
+}
+
+func parseCSV(data []byte) ([]Entry, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	entries := make([]Entry, 0, 32)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		// 跳过表头
+		if len(entries) == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "ip") {
+			continue
+		}
+		if len(record) < 4 {
+			return nil, fmt.Errorf("line %d: expect at least 4 columns, ip,region,zone,campus", line)
+		}
+		entry := Entry{
+			IP:     record[0],
+			Region: strings.TrimSpace(record[1]),
+			Zone:   strings.TrimSpace(record[2]),
+			Campus: strings.TrimSpace(record[3]),
+		}
+		ids := []*uint32{&entry.RegionID, &entry.ZoneID, &entry.CampusID}
+		for i := 4; i < len(record) && i < 7; i++ {
+			val := strings.TrimSpace(record[i])
+			if val == "" {
+				continue
+			}
+			id, err := strconv.ParseUint(val, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			*ids[i-4] = uint32(id)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -18,6 +18,7 @@
 package plugin
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
 	"sync"
@@ -36,6 +37,36 @@ type Crypto interface {
 	Decrypt(cryptotext string, key []byte) (string, error)
 }
 
+// KeyWrapper crypto plugin which protects the data keys by the master keys (envelope encryption)
+type KeyWrapper interface {
+	// WrapKey encrypt the data key by the primary master key
+	WrapKey(dataKey []byte) (string, error)
+	// UnwrapKey decrypt the wrapped data key
+	UnwrapKey(wrapped string) ([]byte, error)
+	// KeyVersion the version of the master key which wraps the data key, empty for the unwrapped data key
+	KeyVersion(wrapped string) string
+	// PrimaryKeyVersion the version of the master key which wraps the new data keys
+	PrimaryKeyVersion() string
+	// ContentAlgorithm the algorithm which the clients use to decrypt the content by the unwrapped data key
+	ContentAlgorithm() string
+}
+
+// EncodeDataKey encode the data key to save, the data key is wrapped if the crypto is a KeyWrapper
+func EncodeDataKey(crypto Crypto, dataKey []byte) (string, error) {
+	if wrapper, ok := crypto.(KeyWrapper); ok {
+		return wrapper.WrapKey(dataKey)
+	}
+	return base64.StdEncoding.EncodeToString(dataKey), nil
+}
+
+// DecodeDataKey decode the saved data key
+func DecodeDataKey(crypto Crypto, dataKey string) ([]byte, error) {
+	if wrapper, ok := crypto.(KeyWrapper); ok {
+		return wrapper.UnwrapKey(dataKey)
+	}
+	return base64.StdEncoding.DecodeString(dataKey)
+}
+
 // GetCrypto get the crypto plugin
 func GetCryptoManager() CryptoManager {
 	if cryptoManager != nil {
@@ -0,0 +1,240 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package kms
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// AlgorithmAESGCM 使用 AES-GCM 对称主密钥包装数据密钥
+	AlgorithmAESGCM = "AES-GCM"
+	// AlgorithmRSAOAEP 使用 RSA-OAEP(SHA-256) 非对称主密钥包装数据密钥
+	AlgorithmRSAOAEP = "RSA-OAEP"
+
+	// wrappedPrefix 被主密钥包装后的数据密钥前缀, 格式为 kms:<version>:<base64 ciphertext>
+	wrappedPrefix = "kms:"
+)
+
+var (
+	ErrorMasterKeyNotFound = errors.New("master key version not found")
+	ErrorInvalidWrappedKey = errors.New("invalid wrapped data key")
+)
+
+// MasterKey 主密钥, 只用于包装/解包数据密钥
+type MasterKey interface {
+	// Version 主密钥版本
+	Version() string
+	// Wrap 加密数据密钥
+	Wrap(dataKey []byte) ([]byte, error)
+	// Unwrap 解密数据密钥
+	Unwrap(ciphertext []byte) ([]byte, error)
+}
+
+// KeySpec 主密钥描述
+type KeySpec struct {
+	// Version 主密钥版本, 不能包含 ':'
+	Version string `yaml:"version"`
+	// Algorithm AES-GCM or RSA-OAEP
+	Algorithm string `yaml:"algorithm"`
+	// Key base64 编码的 AES 密钥, 长度为 16/24/32 字节
+	Key string `yaml:"key"`
+	// PrivateKey PEM 格式的 RSA 私钥, PKCS1 或者 PKCS8
+	PrivateKey string `yaml:"privateKey"`
+}
+
+// NewMasterKey 根据描述创建主密钥
+func NewMasterKey(spec KeySpec) (MasterKey, error) {
+	if spec.Version == "" || strings.Contains(spec.Version, ":") {
+		return nil, fmt.Errorf("invalid master key version %q", spec.Version)
+	}
+	switch strings.ToUpper(spec.Algorithm) {
+	case AlgorithmAESGCM, "":
+		return newAESGCMKey(spec)
+	case AlgorithmRSAOAEP:
+		return newRSAOAEPKey(spec)
+	default:
+		return nil, fmt.Errorf("master key %s unsupported algorithm %s", spec.Version, spec.Algorithm)
+	}
+}
+
+type aesGCMKey struct {
+	version string
+	aead    cipher.AEAD
+}
+
+func newAESGCMKey(spec KeySpec) (MasterKey, error) {
+	key, err := base64.StdEncoding.DecodeString(spec.Key)
+	if err != nil {
+		return nil, fmt.Errorf("master key %s decode key: %w", spec.Version, err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("master key %s: %w", spec.Version, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("master key %s: %w", spec.Version, err)
+	}
+	return &aesGCMKey{version: spec.Version, aead: aead}, nil
+}
+
+func (k *aesGCMKey) Version() string {
+	return k.version
+}
+
+// Wrap 随机 nonce 放在密文前面, 主密钥版本作为附加数据防止密文被挪用到其他版本
+func (k *aesGCMKey) Wrap(dataKey []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return k.aead.Seal(nonce, nonce, dataKey, []byte(k.version)), nil
+}
+
+func (k *aesGCMKey) Unwrap(ciphertext []byte) ([]byte, error) {
+	size := k.aead.NonceSize()
+	if len(ciphertext) < size {
+		return nil, ErrorInvalidWrappedKey
+	}
+	return k.aead.Open(nil, ciphertext[:size], ciphertext[size:], []byte(k.version))
+}
+
+type rsaOAEPKey struct {
+	version    string
+	privateKey *rsa.PrivateKey
+}
+
+func newRSAOAEPKey(spec KeySpec) (MasterKey, error) {
+	block, _ := pem.Decode([]byte(spec.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("master key %s private key is not pem format", spec.Version)
+	}
+	var (
+		privateKey *rsa.PrivateKey
+		err        error
+	)
+	if block.Type == "RSA PRIVATE KEY" {
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		var key interface{}
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err == nil {
+			var ok bool
+			if privateKey, ok = key.(*rsa.PrivateKey); !ok {
+				err = errors.New("not rsa private key")
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("master key %s parse private key: %w", spec.Version, err)
+	}
+	return &rsaOAEPKey{version: spec.Version, privateKey: privateKey}, nil
+}
+
+func (k *rsaOAEPKey) Version() string {
+	return k.version
+}
+
+func (k *rsaOAEPKey) Wrap(dataKey []byte) ([]byte, error) {
+	return rsa.EncryptOAEP(sha256.New(), rand.Reader, &k.privateKey.PublicKey, dataKey, []byte(k.version))
+}
+
+func (k *rsaOAEPKey) Unwrap(ciphertext []byte) ([]byte, error) {
+	return rsa.DecryptOAEP(sha256.New(), rand.Reader, k.privateKey, ciphertext, []byte(k.version))
+}
+
+// KeyRing 主密钥集合, 新的数据密钥使用 primary 版本包装, 旧版本保留用于解包
+type KeyRing struct {
+	primary string
+	keys    map[string]MasterKey
+}
+
+// NewKeyRing 创建主密钥集合
+func NewKeyRing(primary string, specs []KeySpec) (*KeyRing, error) {
+	ring := &KeyRing{
+		primary: primary,
+		keys:    make(map[string]MasterKey, len(specs)),
+	}
+	for _, spec := range specs {
+		key, err := NewMasterKey(spec)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := ring.keys[key.Version()]; ok {
+			return nil, fmt.Errorf("master key version %s duplicated", key.Version())
+		}
+		ring.keys[key.Version()] = key
+	}
+	if _, ok := ring.keys[primary]; !ok {
+		return nil, fmt.Errorf("primary master key %q not found", primary)
+	}
+	return ring, nil
+}
+
+// Primary 当前主版本
+func (r *KeyRing) Primary() string {
+	return r.primary
+}
+
+// Wrap 使用主版本的主密钥包装数据密钥
+func (r *KeyRing) Wrap(dataKey []byte) (string, error) {
+	ciphertext, err := r.keys[r.primary].Wrap(dataKey)
+	if err != nil {
+		return "", err
+	}
+	return wrappedPrefix + r.primary + ":" + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Unwrap 解包数据密钥, 未被包装的数据密钥按照 base64 直接解码, 兼容切换插件前加密的配置
+func (r *KeyRing) Unwrap(wrapped string) ([]byte, error) {
+	version, ciphertext, ok := parseWrapped(wrapped)
+	if !ok {
+		return base64.StdEncoding.DecodeString(wrapped)
+	}
+	key, exist := r.keys[version]
+	if !exist {
+		return nil, fmt.Errorf("%w: %s", ErrorMasterKeyNotFound, version)
+	}
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrorInvalidWrappedKey, err)
+	}
+	return key.Unwrap(data)
+}
+
+// parseWrapped 解析被包装的数据密钥, 返回主密钥版本以及密文
+func parseWrapped(wrapped string) (string, string, bool) {
+	if !strings.HasPrefix(wrapped, wrappedPrefix) {
+		return "", "", false
+	}
+	version, ciphertext, ok := strings.Cut(strings.TrimPrefix(wrapped, wrappedPrefix), ":")
+	if !ok || version == "" {
+		return "", "", false
+	}
+	return version, ciphertext, true
+}
@@ -0,0 +1,179 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package kms
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	commonlog "github.com/polarismesh/polaris/common/log"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/plugin/crypto/aes"
+)
+
+const (
+	// PluginName plugin name
+	PluginName = "KMS"
+	// defaultReloadInterval 默认重新加载主密钥的间隔
+	defaultReloadInterval = time.Minute
+)
+
+var log = commonlog.GetScopeOrDefaultByName(commonlog.ConfigLoggerName)
+
+func init() {
+	plugin.RegisterPlugin(PluginName, &KMSCrypto{})
+}
+
+// Config kms plugin config
+type Config struct {
+	// Provider 主密钥来源, 默认为 file
+	Provider string `mapstructure:"provider"`
+	// ReloadInterval 重新加载主密钥的间隔, 用于感知主密钥轮转
+	ReloadInterval time.Duration `mapstructure:"reloadInterval"`
+}
+
+// KMSCrypto 信封加密: 配置内容使用数据密钥 AES 加密, 数据密钥再由主密钥包装后保存,
+// 客户端下发的仍然是解包后的数据密钥, 因此对 SDK 透明
+type KMSCrypto struct {
+	aes.AESCrypto
+	provider KeyProvider
+	ring     atomic.Value
+	cancel   context.CancelFunc
+}
+
+// Name 返回插件名字
+func (k *KMSCrypto) Name() string {
+	return PluginName
+}
+
+// Initialize 插件初始化
+func (k *KMSCrypto) Initialize(c *plugin.ConfigEntry) error {
+	cfg := &Config{
+		Provider:       FileProviderName,
+		ReloadInterval: defaultReloadInterval,
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     cfg,
+	})
+	if err != nil {
+		return err
+	}
+	if err := decoder.Decode(c.Option); err != nil {
+		return err
+	}
+	provider, err := newKeyProvider(cfg.Provider)
+	if err != nil {
+		return err
+	}
+	if err := provider.Initialize(c.Option); err != nil {
+		return err
+	}
+	k.provider = provider
+	if err := k.reload(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	k.cancel = cancel
+	if cfg.ReloadInterval > 0 {
+		go k.run(ctx, cfg.ReloadInterval)
+	}
+	return nil
+}
+
+// Destroy 销毁插件
+func (k *KMSCrypto) Destroy() error {
+	if k.cancel != nil {
+		k.cancel()
+	}
+	return nil
+}
+
+func (k *KMSCrypto) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := k.reload(); err != nil {
+				log.Errorf("[Plugin][KMS] reload master keys from %s fail, keep the last keys: %v",
+					k.provider.Name(), err)
+			}
+		}
+	}
+}
+
+func (k *KMSCrypto) reload() error {
+	ring, err := k.provider.Load()
+	if err != nil {
+		return err
+	}
+	if last := k.keyRing(); last == nil || last.Primary() != ring.Primary() {
+		log.Infof("[Plugin][KMS] primary master key version is %s", ring.Primary())
+	}
+	k.ring.Store(ring)
+	return nil
+}
+
+func (k *KMSCrypto) keyRing() *KeyRing {
+	ring, _ := k.ring.Load().(*KeyRing)
+	return ring
+}
+
+// WrapKey 使用主版本的主密钥包装数据密钥
+func (k *KMSCrypto) WrapKey(dataKey []byte) (string, error) {
+	ring := k.keyRing()
+	if ring == nil {
+		return "", errors.New("kms master keys not loaded")
+	}
+	return ring.Wrap(dataKey)
+}
+
+// UnwrapKey 解包数据密钥
+func (k *KMSCrypto) UnwrapKey(wrapped string) ([]byte, error) {
+	ring := k.keyRing()
+	if ring == nil {
+		return nil, errors.New("kms master keys not loaded")
+	}
+	return ring.Unwrap(wrapped)
+}
+
+// KeyVersion 包装数据密钥的主密钥版本, 未被包装时返回空
+func (k *KMSCrypto) KeyVersion(wrapped string) string {
+	version, _, _ := parseWrapped(wrapped)
+	return version
+}
+
+// PrimaryKeyVersion 当前主版本
+func (k *KMSCrypto) PrimaryKeyVersion() string {
+	ring := k.keyRing()
+	if ring == nil {
+		return ""
+	}
+	return ring.Primary()
+}
+
+// ContentAlgorithm 客户端使用 AES 算法以及解包后的数据密钥解密配置
+func (k *KMSCrypto) ContentAlgorithm() string {
+	return aes.PluginName
+}
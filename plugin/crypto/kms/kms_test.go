@@ -0,0 +1,138 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package kms
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+
+	"github.com/polarismesh/polaris/plugin"
+)
+
+func newAESKeySpec(t *testing.T, version string) KeySpec {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+	return KeySpec{Version: version, Algorithm: AlgorithmAESGCM, Key: base64.StdEncoding.EncodeToString(key)}
+}
+
+func newRSAKeySpec(t *testing.T, version string) KeySpec {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return KeySpec{Version: version, Algorithm: AlgorithmRSAOAEP, PrivateKey: string(data)}
+}
+
+func writeKeyFile(t *testing.T, path string, keyFile *KeyFile) {
+	data, err := yaml.Marshal(keyFile)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, data, 0600))
+}
+
+func TestKMSCrypto_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	v1 := newAESKeySpec(t, "v1")
+	v2 := newRSAKeySpec(t, "v2")
+	writeKeyFile(t, path, &KeyFile{Primary: "v1", Keys: []KeySpec{v1}})
+
+	crypto := &KMSCrypto{}
+	assert.NoError(t, crypto.Initialize(&plugin.ConfigEntry{
+		Name:   PluginName,
+		Option: map[string]interface{}{"keyFile": path, "reloadInterval": "0s"},
+	}))
+	defer crypto.Destroy()
+	assert.Equal(t, "v1", crypto.PrimaryKeyVersion())
+
+	dataKey, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	wrapped, err := plugin.EncodeDataKey(crypto, dataKey)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(wrapped, "kms:v1:"))
+	assert.Equal(t, "v1", crypto.KeyVersion(wrapped))
+
+	ciphertext, err := crypto.Encrypt("polaris", dataKey)
+	assert.NoError(t, err)
+	unwrapped, err := plugin.DecodeDataKey(crypto, wrapped)
+	assert.NoError(t, err)
+	plaintext, err := crypto.Decrypt(ciphertext, unwrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, "polaris", plaintext)
+
+	// rotate to v2, data keys wrapped by v1 can still be unwrapped
+	writeKeyFile(t, path, &KeyFile{Primary: "v2", Keys: []KeySpec{v1, v2}})
+	assert.NoError(t, crypto.reload())
+	assert.Equal(t, "v2", crypto.PrimaryKeyVersion())
+	unwrapped, err = crypto.UnwrapKey(wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+
+	rewrapped, err := crypto.WrapKey(unwrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", crypto.KeyVersion(rewrapped))
+	unwrapped, err = crypto.UnwrapKey(rewrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+
+	// invalid key file, keep the last keys
+	assert.NoError(t, os.WriteFile(path, []byte("primary: v3"), 0600))
+	assert.Error(t, crypto.reload())
+	assert.Equal(t, "v2", crypto.PrimaryKeyVersion())
+
+	// v1 removed, the data keys wrapped by v1 can not be unwrapped
+	writeKeyFile(t, path, &KeyFile{Primary: "v2", Keys: []KeySpec{v2}})
+	assert.NoError(t, crypto.reload())
+	_, err = crypto.UnwrapKey(wrapped)
+	assert.ErrorIs(t, err, ErrorMasterKeyNotFound)
+}
+
+func TestKMSCrypto_LegacyDataKey(t *testing.T) {
+	ring, err := NewKeyRing("v1", []KeySpec{newAESKeySpec(t, "v1")})
+	assert.NoError(t, err)
+	crypto := &KMSCrypto{}
+	crypto.ring.Store(ring)
+
+	dataKey := []byte("0123456789abcdef")
+	legacy := base64.StdEncoding.EncodeToString(dataKey)
+	assert.Equal(t, "", crypto.KeyVersion(legacy))
+	unwrapped, err := crypto.UnwrapKey(legacy)
+	assert.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+	assert.Equal(t, "AES", crypto.ContentAlgorithm())
+}
+
+func TestNewKeyRing(t *testing.T) {
+	_, err := NewKeyRing("v2", []KeySpec{newAESKeySpec(t, "v1")})
+	assert.Error(t, err)
+	_, err = NewKeyRing("v1", []KeySpec{newAESKeySpec(t, "v1"), newAESKeySpec(t, "v1")})
+	assert.Error(t, err)
+	_, err = NewKeyRing("a:b", []KeySpec{newAESKeySpec(t, "a:b")})
+	assert.Error(t, err)
+	_, err = NewKeyRing("v1", []KeySpec{{Version: "v1", Algorithm: "DES", Key: "MTIz"}})
+	assert.Error(t, err)
+	_, err = NewKeyRing("v1", []KeySpec{{Version: "v1", Algorithm: AlgorithmRSAOAEP, PrivateKey: "bad"}})
+	assert.Error(t, err)
+}
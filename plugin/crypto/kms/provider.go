@@ -0,0 +1,105 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package kms
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// FileProviderName 从本地密钥文件读取主密钥
+	FileProviderName = "file"
+)
+
+// KeyProvider 主密钥来源, 可以通过 RegisterKeyProvider 接入外部的 KMS 服务
+type KeyProvider interface {
+	// Name provider 名称
+	Name() string
+	// Initialize 初始化 provider
+	Initialize(option map[string]interface{}) error
+	// Load 加载最新的主密钥集合, 定期调用用于感知主密钥轮转
+	Load() (*KeyRing, error)
+}
+
+var (
+	providersLock sync.RWMutex
+	providers     = map[string]func() KeyProvider{
+		FileProviderName: func() KeyProvider {
+			return &fileKeyProvider{}
+		},
+	}
+)
+
+// RegisterKeyProvider 注册主密钥来源
+func RegisterKeyProvider(name string, factory func() KeyProvider) {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+	providers[name] = factory
+}
+
+func newKeyProvider(name string) (KeyProvider, error) {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+	factory, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("kms key provider %s not found", name)
+	}
+	return factory(), nil
+}
+
+// KeyFile 本地密钥文件内容
+type KeyFile struct {
+	// Primary 用于包装新数据密钥的主密钥版本
+	Primary string `yaml:"primary"`
+	// Keys 所有的主密钥, 轮转后旧版本需要保留, 直至所有使用旧版本的数据密钥都被重新包装
+	Keys []KeySpec `yaml:"keys"`
+}
+
+// fileKeyProvider 从本地 yaml 密钥文件读取主密钥
+type fileKeyProvider struct {
+	path string
+}
+
+func (p *fileKeyProvider) Name() string {
+	return FileProviderName
+}
+
+func (p *fileKeyProvider) Initialize(option map[string]interface{}) error {
+	path, _ := option["keyFile"].(string)
+	if path == "" {
+		return errors.New("kms file key provider keyFile is empty")
+	}
+	p.path = path
+	return nil
+}
+
+func (p *fileKeyProvider) Load() (*KeyRing, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, err
+	}
+	keyFile := &KeyFile{}
+	if err := yaml.Unmarshal(data, keyFile); err != nil {
+		return nil, fmt.Errorf("parse kms key file %s: %w", p.path, err)
+	}
+	return NewKeyRing(keyFile.Primary, keyFile.Keys)
+}
@@ -0,0 +1,149 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+// EndpointConfig webhook 推送目标配置
+type EndpointConfig struct {
+	// Name 推送目标名称
+	Name string `json:"name"`
+	// URL 推送地址
+	URL string `json:"url"`
+	// Secret HMAC-SHA256 签名密钥，为空时不签名
+	Secret string `json:"secret"`
+	// Headers 额外的请求头
+	Headers map[string]string `json:"headers"`
+	// Namespaces 只推送这些命名空间的事件，为空表示全部
+	Namespaces []string `json:"namespaces"`
+	// Services 只推送这些服务的事件，为空表示全部
+	Services []string `json:"services"`
+}
+
+// WebhookConfig webhook 实例事件插件配置
+type WebhookConfig struct {
+	QueueSize      int               `json:"queueSize"`
+	BatchSize      int               `json:"batchSize"`
+	FlushInterval  string            `json:"flushInterval"`
+	Timeout        string            `json:"timeout"`
+	MaxRetries     int               `json:"maxRetries"`
+	RetryBackoff   string            `json:"retryBackoff"`
+	MaxBackoff     string            `json:"maxBackoff"`
+	DeadLetterPath string            `json:"deadLetterPath"`
+	EventTypes     []string          `json:"eventTypes"`
+	Endpoints      []*EndpointConfig `json:"endpoints"`
+
+	flushInterval time.Duration
+	timeout       time.Duration
+	retryBackoff  time.Duration
+	maxBackoff    time.Duration
+}
+
+// Validate 检查配置是否正确配置
+func (c *WebhookConfig) Validate() error {
+	if c.QueueSize <= 0 {
+		return errors.New("QueueSize is <= 0")
+	}
+	if c.BatchSize <= 0 {
+		return errors.New("BatchSize is <= 0")
+	}
+	if c.MaxRetries < 0 {
+		return errors.New("MaxRetries is < 0")
+	}
+	if c.DeadLetterPath == "" {
+		return errors.New("DeadLetterPath is empty")
+	}
+	if len(c.Endpoints) == 0 {
+		return errors.New("Endpoints is empty")
+	}
+	var err error
+	if c.flushInterval, err = parsePositiveDuration("FlushInterval", c.FlushInterval); err != nil {
+		return err
+	}
+	if c.timeout, err = parsePositiveDuration("Timeout", c.Timeout); err != nil {
+		return err
+	}
+	if c.retryBackoff, err = parsePositiveDuration("RetryBackoff", c.RetryBackoff); err != nil {
+		return err
+	}
+	if c.maxBackoff, err = parsePositiveDuration("MaxBackoff", c.MaxBackoff); err != nil {
+		return err
+	}
+	for i, endpoint := range c.Endpoints {
+		if endpoint == nil || endpoint.URL == "" {
+			return fmt.Errorf("Endpoints[%d] url is empty", i)
+		}
+		u, err := url.Parse(endpoint.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("Endpoints[%d] url %s is invalid", i, endpoint.URL)
+		}
+		if endpoint.Name == "" {
+			endpoint.Name = u.Host
+		}
+	}
+	return nil
+}
+
+// subscribeEvents 需要推送的事件类型
+func (c *WebhookConfig) subscribeEvents() map[model.InstanceEventType]struct{} {
+	ret := make(map[model.InstanceEventType]struct{}, len(c.EventTypes))
+	for _, eventType := range c.EventTypes {
+		ret[model.InstanceEventType(eventType)] = struct{}{}
+	}
+	return ret
+}
+
+func parsePositiveDuration(name, value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s %s is invalid: %w", name, value, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s is <= 0", name)
+	}
+	return d, nil
+}
+
+// DefaultWebhookConfig 创建一个默认的 webhook 事件插件配置
+func DefaultWebhookConfig() *WebhookConfig {
+	return &WebhookConfig{
+		QueueSize:      1024,
+		BatchSize:      100,
+		FlushInterval:  "1s",
+		Timeout:        "3s",
+		MaxRetries:     3,
+		RetryBackoff:   "500ms",
+		MaxBackoff:     "10s",
+		DeadLetterPath: "./discover-event/webhook-deadletter.log",
+		EventTypes: []string{
+			string(model.EventInstanceOnline),
+			string(model.EventInstanceOffline),
+			string(model.EventInstanceOpenIsolate),
+			string(model.EventInstanceCloseIsolate),
+			string(model.EventInstanceTurnHealth),
+			string(model.EventInstanceTurnUnHealth),
+		},
+	}
+}
@@ -0,0 +1,377 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	commonlog "github.com/polarismesh/polaris/common/log"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/plugin"
+)
+
+const (
+	PluginName = "discoverEventWebhook"
+
+	// HeaderTimestamp 推送时间戳，参与签名
+	HeaderTimestamp = "X-Polaris-Timestamp"
+	// HeaderSignature 推送内容签名，格式为 sha256=hex(hmac-sha256(secret, timestamp + "." + body))
+	HeaderSignature = "X-Polaris-Signature"
+
+	endpointQueueSize = 64
+)
+
+var log = commonlog.RegisterScope(PluginName, "", 0)
+
+func init() {
+	d := &discoverEventWebhook{}
+	plugin.RegisterPlugin(d.Name(), d)
+}
+
+// WebhookPayload 推送给 webhook 的内容
+type WebhookPayload struct {
+	// Server 产生事件的 polaris-server 节点
+	Server string                `json:"server"`
+	Events []model.InstanceEvent `json:"events"`
+}
+
+type discoverEventWebhook struct {
+	cfg             *WebhookConfig
+	eventCh         chan model.InstanceEvent
+	subscribeEvents map[model.InstanceEventType]struct{}
+	workers         []*endpointWorker
+	deadLetter      *deadLetterWriter
+	cancel          context.CancelFunc
+	wg              sync.WaitGroup
+}
+
+// Name 插件名称
+func (w *discoverEventWebhook) Name() string {
+	return PluginName
+}
+
+// Initialize 根据配置文件进行初始化插件 discoverEventWebhook
+func (w *discoverEventWebhook) Initialize(conf *plugin.ConfigEntry) error {
+	contentBytes, err := json.Marshal(conf.Option)
+	if err != nil {
+		return err
+	}
+	cfg := DefaultWebhookConfig()
+	if err := json.Unmarshal(contentBytes, cfg); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	w.cfg = cfg
+	w.eventCh = make(chan model.InstanceEvent, cfg.QueueSize)
+	w.subscribeEvents = cfg.subscribeEvents()
+	w.deadLetter = &deadLetterWriter{path: cfg.DeadLetterPath}
+	client := &http.Client{Timeout: cfg.timeout}
+	w.workers = make([]*endpointWorker, 0, len(cfg.Endpoints))
+	for _, endpoint := range cfg.Endpoints {
+		w.workers = append(w.workers, newEndpointWorker(endpoint, cfg, client, w.deadLetter))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	for i := range w.workers {
+		worker := w.workers[i]
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			worker.run(ctx)
+		}()
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.Run(ctx)
+	}()
+	return nil
+}
+
+// Destroy 执行插件销毁
+func (w *discoverEventWebhook) Destroy() error {
+	if w.cancel != nil {
+		w.cancel()
+		w.wg.Wait()
+	}
+	return nil
+}
+
+// PublishEvent 发布一个服务事件
+func (w *discoverEventWebhook) PublishEvent(event model.InstanceEvent) {
+	if _, ok := w.subscribeEvents[event.EType]; !ok {
+		return
+	}
+	select {
+	case w.eventCh <- event:
+	default:
+		log.Warnf("[DiscoverEvent][Webhook] event queue is full, drop event %s", event.String())
+	}
+}
+
+// Run 攒批后分发给各个推送目标
+func (w *discoverEventWebhook) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]model.InstanceEvent, 0, w.cfg.BatchSize)
+	for {
+		select {
+		case event := <-w.eventCh:
+			// 确保事件是顺序的
+			event.CreateTime = time.Now()
+			batch = append(batch, event)
+			if len(batch) >= w.cfg.BatchSize {
+				w.dispatch(batch)
+				batch = make([]model.InstanceEvent, 0, w.cfg.BatchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.dispatch(batch)
+				batch = make([]model.InstanceEvent, 0, w.cfg.BatchSize)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *discoverEventWebhook) dispatch(batch []model.InstanceEvent) {
+	for _, worker := range w.workers {
+		events := worker.filter(batch)
+		if len(events) == 0 {
+			continue
+		}
+		select {
+		case worker.batchCh <- events:
+		default:
+			worker.fail(events, fmt.Errorf("endpoint queue is full"))
+		}
+	}
+}
+
+// endpointWorker 负责一个推送目标的发送，避免慢的推送目标影响其他目标
+type endpointWorker struct {
+	endpoint   *EndpointConfig
+	cfg        *WebhookConfig
+	client     *http.Client
+	deadLetter *deadLetterWriter
+	namespaces map[string]struct{}
+	services   map[string]struct{}
+	batchCh    chan []model.InstanceEvent
+}
+
+func newEndpointWorker(endpoint *EndpointConfig, cfg *WebhookConfig, client *http.Client,
+	deadLetter *deadLetterWriter) *endpointWorker {
+	worker := &endpointWorker{
+		endpoint:   endpoint,
+		cfg:        cfg,
+		client:     client,
+		deadLetter: deadLetter,
+		namespaces: make(map[string]struct{}, len(endpoint.Namespaces)),
+		services:   make(map[string]struct{}, len(endpoint.Services)),
+		batchCh:    make(chan []model.InstanceEvent, endpointQueueSize),
+	}
+	for _, namespace := range endpoint.Namespaces {
+		worker.namespaces[namespace] = struct{}{}
+	}
+	for _, service := range endpoint.Services {
+		worker.services[service] = struct{}{}
+	}
+	return worker
+}
+
+// filter 过滤出推送目标关心的事件
+func (e *endpointWorker) filter(batch []model.InstanceEvent) []model.InstanceEvent {
+	if len(e.namespaces) == 0 && len(e.services) == 0 {
+		return batch
+	}
+	ret := make([]model.InstanceEvent, 0, len(batch))
+	for _, event := range batch {
+		if len(e.namespaces) != 0 {
+			if _, ok := e.namespaces[event.Namespace]; !ok {
+				continue
+			}
+		}
+		if len(e.services) != 0 {
+			if _, ok := e.services[event.Service]; !ok {
+				continue
+			}
+		}
+		ret = append(ret, event)
+	}
+	return ret
+}
+
+func (e *endpointWorker) run(ctx context.Context) {
+	for {
+		select {
+		case events := <-e.batchCh:
+			e.deliverWithRetry(ctx, events)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *endpointWorker) deliverWithRetry(ctx context.Context, events []model.InstanceEvent) {
+	body, err := json.Marshal(&WebhookPayload{
+		Server: utils.LocalHost,
+		Events: events,
+	})
+	if err != nil {
+		log.Errorf("[DiscoverEvent][Webhook] marshal events fail: %s", err.Error())
+		return
+	}
+	backoff := e.cfg.retryBackoff
+	for attempt := 0; ; attempt++ {
+		err = e.deliver(ctx, body)
+		if err == nil {
+			return
+		}
+		if attempt >= e.cfg.MaxRetries {
+			break
+		}
+		log.Warnf("[DiscoverEvent][Webhook] push to %s fail, retry after %s: %s",
+			e.endpoint.Name, backoff, err.Error())
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			e.failWithBody(body, ctx.Err())
+			return
+		}
+		backoff *= 2
+		if backoff > e.cfg.maxBackoff {
+			backoff = e.cfg.maxBackoff
+		}
+	}
+	e.failWithBody(body, err)
+}
+
+func (e *endpointWorker) deliver(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderTimestamp, timestamp)
+	for k, v := range e.endpoint.Headers {
+		req.Header.Set(k, v)
+	}
+	if e.endpoint.Secret != "" {
+		req.Header.Set(HeaderSignature, "sha256="+Sign(e.endpoint.Secret, timestamp, body))
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (e *endpointWorker) fail(events []model.InstanceEvent, reason error) {
+	body, err := json.Marshal(&WebhookPayload{
+		Server: utils.LocalHost,
+		Events: events,
+	})
+	if err != nil {
+		log.Errorf("[DiscoverEvent][Webhook] marshal events fail: %s", err.Error())
+		return
+	}
+	e.failWithBody(body, reason)
+}
+
+func (e *endpointWorker) failWithBody(body []byte, reason error) {
+	log.Errorf("[DiscoverEvent][Webhook] push to %s fail, write to dead letter: %s",
+		e.endpoint.Name, reason.Error())
+	if err := e.deadLetter.write(&deadLetter{
+		Endpoint: e.endpoint.Name,
+		URL:      e.endpoint.URL,
+		Error:    reason.Error(),
+		Time:     time.Now(),
+		Payload:  body,
+	}); err != nil {
+		log.Errorf("[DiscoverEvent][Webhook] write dead letter fail: %s", err.Error())
+	}
+}
+
+// Sign 计算推送内容的签名，接收方可以用相同的方式校验
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// deadLetter 推送失败的记录
+type deadLetter struct {
+	Endpoint string          `json:"endpoint"`
+	URL      string          `json:"url"`
+	Error    string          `json:"error"`
+	Time     time.Time       `json:"time"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// deadLetterWriter 推送失败的事件按行写入死信文件，便于事后补偿
+type deadLetterWriter struct {
+	lock sync.Mutex
+	path string
+}
+
+func (d *deadLetterWriter) write(record *deadLetter) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if err := os.MkdirAll(filepath.Dir(d.path), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(d.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
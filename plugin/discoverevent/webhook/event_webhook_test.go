@@ -0,0 +1,163 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/plugin"
+)
+
+func newTestEvent(namespace, service string, eType model.InstanceEventType) model.InstanceEvent {
+	return model.InstanceEvent{
+		Id:        "ins-1",
+		Namespace: namespace,
+		Service:   service,
+		Instance: &apiservice.Instance{
+			Host: wrapperspb.String("127.0.0.1"),
+			Port: wrapperspb.UInt32(8080),
+		},
+		EType: eType,
+	}
+}
+
+func newTestPlugin(t *testing.T, option map[string]interface{}) *discoverEventWebhook {
+	w := &discoverEventWebhook{}
+	assert.NoError(t, w.Initialize(&plugin.ConfigEntry{Name: PluginName, Option: option}))
+	t.Cleanup(func() {
+		_ = w.Destroy()
+	})
+	return w
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := DefaultWebhookConfig()
+	assert.Error(t, cfg.Validate())
+
+	cfg.Endpoints = []*EndpointConfig{{URL: "ftp://127.0.0.1"}}
+	assert.Error(t, cfg.Validate())
+
+	cfg.Endpoints = []*EndpointConfig{{URL: "http://127.0.0.1:8080/hook"}}
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, "127.0.0.1:8080", cfg.Endpoints[0].Name)
+
+	cfg.RetryBackoff = "-1s"
+	assert.Error(t, cfg.Validate())
+}
+
+func TestWebhook_Push(t *testing.T) {
+	received := make(chan *WebhookPayload, 8)
+	var requests int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// first request fail, retry should succeed
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		expect := "sha256=" + Sign("secret", r.Header.Get(HeaderTimestamp), body)
+		if r.Header.Get(HeaderSignature) != expect || r.Header.Get("X-Token") != "abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		payload := &WebhookPayload{}
+		_ = json.Unmarshal(body, payload)
+		received <- payload
+	}))
+	defer svr.Close()
+
+	w := newTestPlugin(t, map[string]interface{}{
+		"flushInterval":  "500ms",
+		"retryBackoff":   "10ms",
+		"deadLetterPath": filepath.Join(t.TempDir(), "deadletter.log"),
+		"endpoints": []map[string]interface{}{
+			{
+				"url":        svr.URL,
+				"secret":     "secret",
+				"headers":    map[string]string{"X-Token": "abc"},
+				"namespaces": []string{"default"},
+			},
+		},
+	})
+
+	w.PublishEvent(newTestEvent("default", "svc", model.EventInstanceOnline))
+	// filtered by namespace
+	w.PublishEvent(newTestEvent("other", "svc", model.EventInstanceOnline))
+	// not subscribed event type
+	w.PublishEvent(newTestEvent("default", "svc", model.EventInstanceSendHeartbeat))
+	w.PublishEvent(newTestEvent("default", "svc", model.EventInstanceOffline))
+
+	select {
+	case payload := <-received:
+		assert.Equal(t, 2, len(payload.Events))
+		assert.Equal(t, model.EventInstanceOnline, payload.Events[0].EType)
+		assert.Equal(t, model.EventInstanceOffline, payload.Events[1].EType)
+		assert.Equal(t, "127.0.0.1", payload.Events[0].Instance.GetHost().GetValue())
+	case <-time.After(3 * time.Second):
+		t.Fatal("wait webhook push timeout")
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestWebhook_DeadLetter(t *testing.T) {
+	var requests int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer svr.Close()
+
+	deadLetterPath := filepath.Join(t.TempDir(), "deadletter.log")
+	w := newTestPlugin(t, map[string]interface{}{
+		"flushInterval":  "50ms",
+		"maxRetries":     2,
+		"retryBackoff":   "10ms",
+		"deadLetterPath": deadLetterPath,
+		"endpoints": []map[string]interface{}{
+			{"name": "oncall", "url": svr.URL},
+		},
+	})
+	w.PublishEvent(newTestEvent("default", "svc", model.EventInstanceTurnUnHealth))
+
+	assert.Eventually(t, func() bool {
+		data, err := os.ReadFile(deadLetterPath)
+		return err == nil && strings.Contains(string(data), "InstanceTurnUnHealth")
+	}, 3*time.Second, 50*time.Millisecond)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	data, err := os.ReadFile(deadLetterPath)
+	assert.NoError(t, err)
+	record := &deadLetter{}
+	assert.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(string(data))), record))
+	assert.Equal(t, "oncall", record.Endpoint)
+	assert.Contains(t, record.Error, "502")
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/polarismesh/polaris/common/model"
 )
@@ -38,6 +39,8 @@ type CheckRequest struct {
 	QueryRequest
 	ExpireDurationSec uint32
 	CurTimeSec        func() int64
+	// Metadata the check options of the instance, merged from service metadata and instance metadata
+	Metadata map[string]string
 }
 
 // CheckResponse check heartbeat response
@@ -75,6 +78,8 @@ type HealthCheckType int32
 
 const (
 	HealthCheckerHeartbeat HealthCheckType = iota + 1
+	// HealthCheckerProbe server actively probes the instance, no heartbeat is needed
+	HealthCheckerProbe
 )
 
 var (
@@ -102,6 +107,16 @@ type HealthChecker interface {
 	DebugHandlers() []model.DebugHandler
 }
 
+// ProbeHealthChecker health checker which probes the instance actively,
+// the check interval is decided by the instance instead of the heartbeat ttl
+type ProbeHealthChecker interface {
+	HealthChecker
+	// CheckInterval returns the check interval of the instance with the check metadata
+	CheckInterval(metadata map[string]string) time.Duration
+	// ValidateMetadata checks whether the probe config in the check metadata is valid
+	ValidateMetadata(metadata map[string]string) error
+}
+
 // GetHealthChecker get the health checker by name
 func GetHealthChecker(name string, cfg *ConfigEntry) HealthChecker {
 	plugin, exist := pluginSet[name]
@@ -0,0 +1,220 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package probe
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	commonLog "github.com/polarismesh/polaris/common/log"
+	"github.com/polarismesh/polaris/common/model"
+	commontime "github.com/polarismesh/polaris/common/time"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/plugin"
+)
+
+const (
+	// PluginName plugin name
+	PluginName = "probe"
+)
+
+var log = commonLog.GetScopeOrDefaultByName(commonLog.HealthcheckLoggerName)
+
+// ProbeRecord the probe result of one instance
+type ProbeRecord struct {
+	mutex *sync.Mutex
+	// LastProbeSec last probe time
+	LastProbeSec int64
+	// LastSuccessSec last success probe time
+	LastSuccessSec int64
+	// Successes consecutive success count
+	Successes int
+	// Failures consecutive failure count
+	Failures int
+	// LastError last probe error
+	LastError string
+}
+
+// ProbeHealthChecker probe instances actively by tcp/http/grpc
+type ProbeHealthChecker struct {
+	cfg            *Config
+	records        *utils.SyncMap[string, *ProbeRecord]
+	suspendTimeSec int64
+	probers        map[string]Prober
+}
+
+// Name return plugin name
+func (r *ProbeHealthChecker) Name() string {
+	return PluginName
+}
+
+// Initialize initialize plugin
+func (r *ProbeHealthChecker) Initialize(c *plugin.ConfigEntry) error {
+	cfg, err := unmarshal(c.Option)
+	if err != nil {
+		return err
+	}
+	r.cfg = cfg
+	r.records = utils.NewSyncMap[string, *ProbeRecord]()
+	r.probers = probers
+	return nil
+}
+
+// Destroy plugin destruction
+func (r *ProbeHealthChecker) Destroy() error {
+	return nil
+}
+
+// Type for health check plugin, only one same type plugin is allowed
+func (r *ProbeHealthChecker) Type() plugin.HealthCheckType {
+	return plugin.HealthCheckerProbe
+}
+
+// CheckInterval returns the probe interval of the instance
+func (r *ProbeHealthChecker) CheckInterval(metadata map[string]string) time.Duration {
+	opts, err := ParseOptions(metadata, r.cfg)
+	if err != nil {
+		return r.cfg.Interval
+	}
+	return opts.Interval
+}
+
+// ValidateMetadata checks the probe options of the instance
+func (r *ProbeHealthChecker) ValidateMetadata(metadata map[string]string) error {
+	_, err := ParseOptions(metadata, r.cfg)
+	return err
+}
+
+// Report the instance is probed by server, so heartbeat is ignored
+func (r *ProbeHealthChecker) Report(ctx context.Context, request *plugin.ReportRequest) error {
+	return nil
+}
+
+// Query queries the last success probe time
+func (r *ProbeHealthChecker) Query(ctx context.Context, request *plugin.QueryRequest) (*plugin.QueryResponse, error) {
+	record, ok := r.records.Load(request.InstanceId)
+	if !ok {
+		return &plugin.QueryResponse{}, nil
+	}
+	record.mutex.Lock()
+	defer record.mutex.Unlock()
+	return &plugin.QueryResponse{
+		Exists:           true,
+		LastHeartbeatSec: record.LastSuccessSec,
+		Count:            int64(record.Successes),
+	}, nil
+}
+
+func (r *ProbeHealthChecker) skipCheck(instanceId string, expireDurationSec int64) bool {
+	suspendTimeSec := r.SuspendTimeSec()
+	localCurTimeSec := commontime.CurrentMillisecond() / 1000
+	if suspendTimeSec > 0 && localCurTimeSec >= suspendTimeSec && localCurTimeSec-suspendTimeSec < expireDurationSec {
+		log.Infof("[Health Check][ProbeCheck]health check probe suspended, "+
+			"suspendTimeSec is %d, localCurTimeSec is %d, expireDurationSec is %d, instanceId %s",
+			suspendTimeSec, localCurTimeSec, expireDurationSec, instanceId)
+		return true
+	}
+	return false
+}
+
+// Check probe the instance and judge the health status by the consecutive probe results
+func (r *ProbeHealthChecker) Check(request *plugin.CheckRequest) (*plugin.CheckResponse, error) {
+	opts, err := ParseOptions(request.Metadata, r.cfg)
+	if err != nil {
+		return nil, err
+	}
+	record, _ := r.records.ComputeIfAbsent(request.InstanceId, func(k string) *ProbeRecord {
+		return &ProbeRecord{mutex: &sync.Mutex{}}
+	})
+
+	port := request.Port
+	if opts.Port > 0 {
+		port = opts.Port
+	}
+	address := buildAddress(request.Host, port)
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	probeErr := r.probers[opts.Type](ctx, address, opts)
+	cancel()
+
+	curTimeSec := request.CurTimeSec()
+	record.mutex.Lock()
+	defer record.mutex.Unlock()
+	record.LastProbeSec = curTimeSec
+	if probeErr == nil {
+		record.LastSuccessSec = curTimeSec
+		record.Successes++
+		record.Failures = 0
+		record.LastError = ""
+	} else {
+		record.Successes = 0
+		record.Failures++
+		record.LastError = probeErr.Error()
+		log.Debugf("[Health Check][ProbeCheck]probe %s %s fail, instanceId %s, failures %d, err %s",
+			opts.Type, address, request.InstanceId, record.Failures, probeErr.Error())
+	}
+
+	checkResp := &plugin.CheckResponse{
+		LastHeartbeatTimeSec: record.LastSuccessSec,
+	}
+	if r.skipCheck(request.InstanceId, int64(request.ExpireDurationSec)) {
+		checkResp.Healthy = request.Healthy
+		checkResp.StayUnchanged = true
+		return checkResp, nil
+	}
+	if request.Healthy {
+		checkResp.Healthy = record.Failures < opts.UnhealthyThreshold
+	} else {
+		checkResp.Healthy = record.Successes >= opts.HealthyThreshold
+	}
+	checkResp.StayUnchanged = checkResp.Healthy == request.Healthy
+	if !checkResp.StayUnchanged {
+		log.Infof("[Health Check][ProbeCheck]probe %s %s, instanceId %s turn healthy to %v, last error %s",
+			opts.Type, address, request.InstanceId, checkResp.Healthy, record.LastError)
+	}
+	return checkResp, nil
+}
+
+// Delete delete the id
+func (r *ProbeHealthChecker) Delete(ctx context.Context, id string) error {
+	r.records.Delete(id)
+	return nil
+}
+
+// Suspend health checker for entire expired duration manually
+func (r *ProbeHealthChecker) Suspend() {
+	curTimeMilli := commontime.CurrentMillisecond() / 1000
+	log.Infof("[Health Check][ProbeCheck] suspend checker, start time %d", curTimeMilli)
+	atomic.StoreInt64(&r.suspendTimeSec, curTimeMilli)
+}
+
+// SuspendTimeSec get suspend time in seconds
+func (r *ProbeHealthChecker) SuspendTimeSec() int64 {
+	return atomic.LoadInt64(&r.suspendTimeSec)
+}
+
+// DebugHandlers return debug handlers
+func (r *ProbeHealthChecker) DebugHandlers() []model.DebugHandler {
+	return []model.DebugHandler{}
+}
+
+func init() {
+	d := &ProbeHealthChecker{}
+	plugin.RegisterPlugin(d.Name(), d)
+}
@@ -0,0 +1,227 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package probe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/plugin"
+)
+
+func newTestChecker(t *testing.T) *ProbeHealthChecker {
+	checker := &ProbeHealthChecker{}
+	err := checker.Initialize(&plugin.ConfigEntry{
+		Name: PluginName,
+		Option: map[string]interface{}{
+			"interval":           "2s",
+			"timeout":            "500ms",
+			"unhealthyThreshold": 2,
+		},
+	})
+	assert.NoError(t, err)
+	return checker
+}
+
+func splitAddress(t *testing.T, address string) (string, uint32) {
+	host, portStr, err := net.SplitHostPort(address)
+	assert.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	assert.NoError(t, err)
+	return host, uint32(port)
+}
+
+func newCheckRequest(host string, port uint32, healthy bool, metadata map[string]string) *plugin.CheckRequest {
+	return &plugin.CheckRequest{
+		QueryRequest: plugin.QueryRequest{
+			InstanceId: "ins-1",
+			Host:       host,
+			Port:       port,
+			Healthy:    healthy,
+		},
+		ExpireDurationSec: 2,
+		CurTimeSec: func() int64 {
+			return time.Now().Unix()
+		},
+		Metadata: metadata,
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	defaults := &Config{
+		Interval:           DefaultInterval,
+		Timeout:            DefaultTimeout,
+		HealthyThreshold:   DefaultHealthyThreshold,
+		UnhealthyThreshold: DefaultUnhealthyThreshold,
+	}
+	opts, err := ParseOptions(map[string]string{
+		model.MetadataProbeType:             "HTTP",
+		model.MetadataProbeInterval:         "10",
+		model.MetadataProbeTimeout:          "200ms",
+		model.MetadataProbeHTTPPath:         "health",
+		model.MetadataProbeHTTPExpectStatus: "200, 204",
+		model.MetadataProbePort:             "8081",
+	}, defaults)
+	assert.NoError(t, err)
+	assert.Equal(t, ProbeHTTP, opts.Type)
+	assert.Equal(t, 10*time.Second, opts.Interval)
+	assert.Equal(t, 200*time.Millisecond, opts.Timeout)
+	assert.Equal(t, "/health", opts.HTTPPath)
+	assert.Equal(t, []int{200, 204}, opts.HTTPExpectStatus)
+	assert.Equal(t, uint32(8081), opts.Port)
+	assert.Equal(t, DefaultUnhealthyThreshold, opts.UnhealthyThreshold)
+
+	_, err = ParseOptions(map[string]string{model.MetadataProbeType: "udp"}, defaults)
+	assert.Error(t, err)
+	_, err = ParseOptions(map[string]string{
+		model.MetadataProbeType:     ProbeTCP,
+		model.MetadataProbeInterval: "-1s",
+	}, defaults)
+	assert.Error(t, err)
+}
+
+func TestProbeHealthChecker_TCP(t *testing.T) {
+	checker := newTestChecker(t)
+	assert.Equal(t, plugin.HealthCheckerProbe, checker.Type())
+	assert.Equal(t, 2*time.Second, checker.CheckInterval(map[string]string{model.MetadataProbeType: ProbeTCP}))
+	assert.NoError(t, checker.ValidateMetadata(map[string]string{model.MetadataProbeType: ProbeTCP}))
+	assert.Error(t, checker.ValidateMetadata(map[string]string{model.MetadataProbeType: "udp"}))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	host, port := splitAddress(t, ln.Addr().String())
+	metadata := map[string]string{model.MetadataProbeType: ProbeTCP}
+
+	// unhealthy instance turn healthy after one success probe
+	resp, err := checker.Check(newCheckRequest(host, port, false, metadata))
+	assert.NoError(t, err)
+	assert.True(t, resp.Healthy)
+	assert.False(t, resp.StayUnchanged)
+
+	queryResp, err := checker.Query(context.Background(), &plugin.QueryRequest{InstanceId: "ins-1"})
+	assert.NoError(t, err)
+	assert.True(t, queryResp.Exists)
+	assert.True(t, queryResp.LastHeartbeatSec > 0)
+
+	_ = ln.Close()
+	// healthy instance stay healthy until failures reach the unhealthy threshold
+	resp, err = checker.Check(newCheckRequest(host, port, true, metadata))
+	assert.NoError(t, err)
+	assert.True(t, resp.Healthy)
+	assert.True(t, resp.StayUnchanged)
+
+	resp, err = checker.Check(newCheckRequest(host, port, true, metadata))
+	assert.NoError(t, err)
+	assert.False(t, resp.Healthy)
+	assert.False(t, resp.StayUnchanged)
+
+	assert.NoError(t, checker.Delete(context.Background(), "ins-1"))
+	queryResp, err = checker.Query(context.Background(), &plugin.QueryRequest{InstanceId: "ins-1"})
+	assert.NoError(t, err)
+	assert.False(t, queryResp.Exists)
+}
+
+func TestProbeHealthChecker_HTTP(t *testing.T) {
+	checker := newTestChecker(t)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"UP"}`))
+	}))
+	defer svr.Close()
+	host, port := splitAddress(t, svr.Listener.Addr().String())
+
+	metadata := map[string]string{
+		model.MetadataProbeType:     ProbeHTTP,
+		model.MetadataProbeHTTPPath: "/health",
+	}
+	resp, err := checker.Check(newCheckRequest(host, port, false, metadata))
+	assert.NoError(t, err)
+	assert.True(t, resp.Healthy)
+
+	metadata[model.MetadataProbeHTTPExpectBody] = "DOWN"
+	metadata[model.MetadataProbeUnhealthyThreshold] = "1"
+	resp, err = checker.Check(newCheckRequest(host, port, true, metadata))
+	assert.NoError(t, err)
+	assert.False(t, resp.Healthy)
+	assert.False(t, resp.StayUnchanged)
+
+	delete(metadata, model.MetadataProbeHTTPExpectBody)
+	metadata[model.MetadataProbeHTTPPath] = "/not_exist"
+	metadata[model.MetadataProbeHTTPExpectStatus] = "404"
+	resp, err = checker.Check(newCheckRequest(host, port, false, metadata))
+	assert.NoError(t, err)
+	assert.True(t, resp.Healthy)
+}
+
+func TestProbeHealthChecker_GRPC(t *testing.T) {
+	checker := newTestChecker(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	healthSvr := health.NewServer()
+	grpcSvr := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcSvr, healthSvr)
+	go func() {
+		_ = grpcSvr.Serve(ln)
+	}()
+	defer grpcSvr.Stop()
+	host, port := splitAddress(t, ln.Addr().String())
+
+	metadata := map[string]string{
+		model.MetadataProbeType:               ProbeGRPC,
+		model.MetadataProbeGRPCService:        "demo",
+		model.MetadataProbeUnhealthyThreshold: "1",
+	}
+	healthSvr.SetServingStatus("demo", healthpb.HealthCheckResponse_SERVING)
+	resp, err := checker.Check(newCheckRequest(host, port, false, metadata))
+	assert.NoError(t, err)
+	assert.True(t, resp.Healthy)
+
+	healthSvr.SetServingStatus("demo", healthpb.HealthCheckResponse_NOT_SERVING)
+	resp, err = checker.Check(newCheckRequest(host, port, true, metadata))
+	assert.NoError(t, err)
+	assert.False(t, resp.Healthy)
+	assert.False(t, resp.StayUnchanged)
+}
+
+func TestProbeHealthChecker_Suspend(t *testing.T) {
+	checker := newTestChecker(t)
+	checker.Suspend()
+	metadata := map[string]string{
+		model.MetadataProbeType:               ProbeTCP,
+		model.MetadataProbeUnhealthyThreshold: "1",
+	}
+	// nothing listen on port 1, but the checker is suspended
+	resp, err := checker.Check(newCheckRequest("127.0.0.1", 1, true, metadata))
+	assert.NoError(t, err)
+	assert.True(t, resp.StayUnchanged)
+	assert.True(t, resp.Healthy)
+}
@@ -0,0 +1,187 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package probe
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+const (
+	ProbeTCP  = "tcp"
+	ProbeHTTP = "http"
+	ProbeGRPC = "grpc"
+)
+
+const (
+	DefaultInterval           = 5 * time.Second
+	DefaultTimeout            = time.Second
+	DefaultHealthyThreshold   = 1
+	DefaultUnhealthyThreshold = 3
+	DefaultHTTPPath           = "/"
+)
+
+// Config 插件配置，作为实例未在 metadata 中设置探测参数时的默认值
+type Config struct {
+	Interval           time.Duration `json:"interval"`
+	Timeout            time.Duration `json:"timeout"`
+	HealthyThreshold   int           `json:"healthyThreshold"`
+	UnhealthyThreshold int           `json:"unhealthyThreshold"`
+}
+
+func unmarshal(options map[string]interface{}) (*Config, error) {
+	config := &Config{
+		Interval:           DefaultInterval,
+		Timeout:            DefaultTimeout,
+		HealthyThreshold:   DefaultHealthyThreshold,
+		UnhealthyThreshold: DefaultUnhealthyThreshold,
+	}
+	decodeConfig := &mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     config,
+		TagName:    "json",
+	}
+	decoder, err := mapstructure.NewDecoder(decodeConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err = decoder.Decode(options); err != nil {
+		return nil, err
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.HealthyThreshold <= 0 {
+		config.HealthyThreshold = DefaultHealthyThreshold
+	}
+	if config.UnhealthyThreshold <= 0 {
+		config.UnhealthyThreshold = DefaultUnhealthyThreshold
+	}
+	return config, nil
+}
+
+// Options 单个实例的探测参数
+type Options struct {
+	Type               string
+	Port               uint32
+	Interval           time.Duration
+	Timeout            time.Duration
+	HealthyThreshold   int
+	UnhealthyThreshold int
+	HTTPPath           string
+	HTTPExpectStatus   []int
+	HTTPExpectBody     string
+	GRPCService        string
+}
+
+// ParseOptions 从 metadata 中解析探测参数，未设置的参数使用插件配置的默认值
+func ParseOptions(metadata map[string]string, defaults *Config) (*Options, error) {
+	opts := &Options{
+		Type:               strings.ToLower(strings.TrimSpace(metadata[model.MetadataProbeType])),
+		Interval:           defaults.Interval,
+		Timeout:            defaults.Timeout,
+		HealthyThreshold:   defaults.HealthyThreshold,
+		UnhealthyThreshold: defaults.UnhealthyThreshold,
+		HTTPPath:           DefaultHTTPPath,
+		HTTPExpectBody:     metadata[model.MetadataProbeHTTPExpectBody],
+		GRPCService:        metadata[model.MetadataProbeGRPCService],
+	}
+	switch opts.Type {
+	case ProbeTCP, ProbeHTTP, ProbeGRPC:
+	default:
+		return nil, fmt.Errorf("unsupported probe type %q", opts.Type)
+	}
+	if v, ok := metadata[model.MetadataProbePort]; ok && v != "" {
+		port, err := strconv.ParseUint(v, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", model.MetadataProbePort, v, err)
+		}
+		opts.Port = uint32(port)
+	}
+	if v, ok := metadata[model.MetadataProbeInterval]; ok && v != "" {
+		interval, err := parseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", model.MetadataProbeInterval, v, err)
+		}
+		opts.Interval = interval
+	}
+	if v, ok := metadata[model.MetadataProbeTimeout]; ok && v != "" {
+		timeout, err := parseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", model.MetadataProbeTimeout, v, err)
+		}
+		opts.Timeout = timeout
+	}
+	if v, ok := metadata[model.MetadataProbeHealthyThreshold]; ok && v != "" {
+		threshold, err := strconv.Atoi(v)
+		if err != nil || threshold <= 0 {
+			return nil, fmt.Errorf("invalid %s %q", model.MetadataProbeHealthyThreshold, v)
+		}
+		opts.HealthyThreshold = threshold
+	}
+	if v, ok := metadata[model.MetadataProbeUnhealthyThreshold]; ok && v != "" {
+		threshold, err := strconv.Atoi(v)
+		if err != nil || threshold <= 0 {
+			return nil, fmt.Errorf("invalid %s %q", model.MetadataProbeUnhealthyThreshold, v)
+		}
+		opts.UnhealthyThreshold = threshold
+	}
+	if v, ok := metadata[model.MetadataProbeHTTPPath]; ok && v != "" {
+		if !strings.HasPrefix(v, "/") {
+			v = "/" + v
+		}
+		opts.HTTPPath = v
+	}
+	if v, ok := metadata[model.MetadataProbeHTTPExpectStatus]; ok && v != "" {
+		for _, item := range strings.Split(v, ",") {
+			code, err := strconv.Atoi(strings.TrimSpace(item))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q: %w", model.MetadataProbeHTTPExpectStatus, v, err)
+			}
+			opts.HTTPExpectStatus = append(opts.HTTPExpectStatus, code)
+		}
+	}
+	return opts, nil
+}
+
+// parseDuration 兼容 "5s" 以及纯数字（单位秒）两种写法
+func parseDuration(v string) (time.Duration, error) {
+	if sec, err := strconv.Atoi(v); err == nil {
+		if sec <= 0 {
+			return 0, fmt.Errorf("must be positive")
+		}
+		return time.Duration(sec) * time.Second, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return d, nil
+}
@@ -0,0 +1,130 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package probe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// maxHTTPBodySize 校验响应体时最多读取的字节数
+	maxHTTPBodySize = 64 * 1024
+)
+
+// Prober 执行一次探测，返回 nil 表示探测成功
+type Prober func(ctx context.Context, address string, opts *Options) error
+
+var probers = map[string]Prober{
+	ProbeTCP:  probeTCP,
+	ProbeHTTP: probeHTTP,
+	ProbeGRPC: probeGRPC,
+}
+
+func probeTCP(ctx context.Context, address string, opts *Options) error {
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+var probeHTTPClient = &http.Client{
+	Transport: &http.Transport{
+		DisableKeepAlives: true,
+		Proxy:             nil,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func probeHTTP(ctx context.Context, address string, opts *Options) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+address+opts.HTTPPath, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "polaris-health-probe")
+	resp, err := probeHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if !matchStatus(resp.StatusCode, opts.HTTPExpectStatus) {
+		return fmt.Errorf("unexpected http status %d", resp.StatusCode)
+	}
+	if len(opts.HTTPExpectBody) == 0 {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBodySize))
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(string(body), opts.HTTPExpectBody) {
+		return fmt.Errorf("http body not contains %q", opts.HTTPExpectBody)
+	}
+	return nil
+}
+
+// matchStatus 未配置期望状态码时，2xx 与 3xx 均认为成功
+func matchStatus(code int, expects []int) bool {
+	if len(expects) == 0 {
+		return code >= http.StatusOK && code < http.StatusBadRequest
+	}
+	for _, expect := range expects {
+		if code == expect {
+			return true
+		}
+	}
+	return false
+}
+
+func probeGRPC(ctx context.Context, address string, opts *Options) error {
+	conn, err := grpc.DialContext(ctx, address, grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{
+		Service: opts.GRPCService,
+	})
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("grpc health status %s", resp.GetStatus().String())
+	}
+	return nil
+}
+
+func buildAddress(host string, port uint32) string {
+	return net.JoinHostPort(host, strconv.FormatUint(uint64(port), 10))
+}
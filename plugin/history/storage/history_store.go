@@ -0,0 +1,168 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	commonLog "github.com/polarismesh/polaris/common/log"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/store"
+)
+
+// 把操作记录保存到存储层中，便于控制台查询
+const (
+	// PluginName plugin name
+	PluginName = "HistoryStore"
+)
+
+var log = commonLog.GetScopeOrDefaultByName(commonLog.DefaultLoggerName)
+
+// init 初始化注册函数
+func init() {
+	plugin.RegisterPlugin(PluginName, &HistoryStore{})
+}
+
+// Config 插件配置
+type Config struct {
+	// QueueSize 待写入的操作记录队列大小，队列满时丢弃
+	QueueSize int `mapstructure:"queueSize"`
+	// BatchSize 每批写入的记录数
+	BatchSize int `mapstructure:"batchSize"`
+	// FlushInterval 攒批的最长等待时间
+	FlushInterval time.Duration `mapstructure:"flushInterval"`
+}
+
+// HistoryStore 操作记录持久化到存储层
+type HistoryStore struct {
+	cfg     *Config
+	storage store.Store
+	queue   chan *model.RecordEntry
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// Name 返回插件名字
+func (h *HistoryStore) Name() string {
+	return PluginName
+}
+
+// Initialize 插件初始化
+func (h *HistoryStore) Initialize(c *plugin.ConfigEntry) error {
+	cfg := &Config{
+		QueueSize:     10240,
+		BatchSize:     100,
+		FlushInterval: time.Second,
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     cfg,
+	})
+	if err != nil {
+		return err
+	}
+	if err := decoder.Decode(c.Option); err != nil {
+		return err
+	}
+	if cfg.QueueSize <= 0 || cfg.BatchSize <= 0 || cfg.FlushInterval <= 0 {
+		cfg.QueueSize, cfg.BatchSize, cfg.FlushInterval = 10240, 100, time.Second
+	}
+	if h.storage == nil {
+		if h.storage, err = store.GetStore(); err != nil {
+			return err
+		}
+	}
+	h.cfg = cfg
+	h.queue = make(chan *model.RecordEntry, cfg.QueueSize)
+	h.done = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	go h.run(ctx)
+	return nil
+}
+
+// Destroy 销毁插件，写入剩余的操作记录
+func (h *HistoryStore) Destroy() error {
+	if h.cancel != nil {
+		h.cancel()
+		<-h.done
+	}
+	return nil
+}
+
+// Record 异步写入操作记录，避免影响请求耗时
+func (h *HistoryStore) Record(entry *model.RecordEntry) {
+	record := *entry
+	if record.Server == "" {
+		record.Server = utils.LocalHost
+	}
+	if record.HappenTime.IsZero() {
+		record.HappenTime = time.Now()
+	}
+	select {
+	case h.queue <- &record:
+	default:
+		log.Warnf("[History][Store] record queue is full, drop record %s", record.String())
+	}
+}
+
+func (h *HistoryStore) run(ctx context.Context) {
+	defer close(h.done)
+	ticker := time.NewTicker(h.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*model.RecordEntry, 0, h.cfg.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := h.storage.AddOperationRecords(batch); err != nil {
+			log.Errorf("[History][Store] save %d records err: %s", len(batch), err.Error())
+		}
+		batch = make([]*model.RecordEntry, 0, h.cfg.BatchSize)
+	}
+	for {
+		select {
+		case record := <-h.queue:
+			batch = append(batch, record)
+			if len(batch) >= h.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			for {
+				select {
+				case record := <-h.queue:
+					batch = append(batch, record)
+					if len(batch) >= h.cfg.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
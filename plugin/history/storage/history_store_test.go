@@ -0,0 +1,74 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package storage
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/store/mock"
+)
+
+func TestHistoryStore_Record(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		lock  sync.Mutex
+		saved []*model.RecordEntry
+	)
+	mockStore := mock.NewMockStore(ctrl)
+	mockStore.EXPECT().AddOperationRecords(gomock.Any()).DoAndReturn(func(records []*model.RecordEntry) error {
+		lock.Lock()
+		defer lock.Unlock()
+		saved = append(saved, records...)
+		return nil
+	}).AnyTimes()
+
+	h := &HistoryStore{storage: mockStore}
+	assert.NoError(t, h.Initialize(&plugin.ConfigEntry{
+		Name: PluginName,
+		Option: map[string]interface{}{
+			"batchSize":     2,
+			"flushInterval": "1h",
+		},
+	}))
+
+	for i := 0; i < 3; i++ {
+		h.Record(&model.RecordEntry{
+			ResourceType:  model.RRouting,
+			ResourceName:  "rule",
+			Namespace:     "default",
+			Operator:      "polaris",
+			OperationType: model.OUpdate,
+		})
+	}
+	// remaining records are saved when destroy
+	assert.NoError(t, h.Destroy())
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, 3, len(saved))
+	assert.NotEmpty(t, saved[0].Server)
+	assert.False(t, saved[0].HappenTime.IsZero())
+}
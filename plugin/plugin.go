@@ -67,6 +67,7 @@ type Config struct {
 	MeshResourceValidate ConfigEntry      `yaml:"meshResourceValidate"`
 	DiscoverEvent        PluginChanConfig `yaml:"discoverEvent"`
 	Crypto               PluginChanConfig `yaml:"crypto"`
+	ChangeNotifier       ConfigEntry      `yaml:"changeNotifier"`
 }
 
 // PluginChanConfig 插件执行链配置
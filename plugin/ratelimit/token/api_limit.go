@@ -22,13 +22,16 @@ import (
 	"sync"
 
 	"golang.org/x/time/rate"
+
+	"github.com/polarismesh/polaris/plugin"
 )
 
 // apiRatelimit 接口限流类
 type apiRatelimit struct {
-	rules  map[string]*BucketRatelimit // 存储规则
-	apis   sync.Map                    // 存储api -> apiLimiter
-	config *APILimitConfig
+	rules   map[string]*BucketRatelimit // 存储规则
+	apis    sync.Map                    // 存储api -> apiLimiter
+	config  *APILimitConfig
+	cluster *cluster
 }
 
 // newAPIRatelimit 新建一个接口限流类
@@ -109,10 +112,34 @@ func (art *apiRatelimit) parseApis(apis []*APILimitInfo) error {
 // createLimiter 创建一个私有limiter
 func (art *apiRatelimit) createLimiter(name string, limit *BucketRatelimit) *apiLimiter {
 	limiter := newAPILimiter(name, limit.Open, limit.Rate, limit.Bucket)
+	limiter.limit = limit
 	art.apis.Store(name, limiter)
 	return limiter
 }
 
+// bindCluster 开启集群限流
+func (art *apiRatelimit) bindCluster(c *cluster) {
+	if !art.isOpen() || c == nil {
+		return
+	}
+	art.cluster = c
+	c.onResize(art.resize)
+	art.resize()
+}
+
+// resize 集群节点数变化时，调整每个接口的令牌桶
+func (art *apiRatelimit) resize() {
+	art.apis.Range(func(key, value interface{}) bool {
+		limiter := value.(*apiLimiter)
+		if limiter.open {
+			limit, bucket := art.cluster.share(limiter.limit)
+			limiter.SetLimit(limit)
+			limiter.SetBurst(bucket)
+		}
+		return true
+	})
+}
+
 // 获取limiter
 func (art *apiRatelimit) acquireLimiter(name string) *apiLimiter {
 	if value, ok := art.apis.Load(name); ok {
@@ -140,6 +167,11 @@ func (art *apiRatelimit) allow(name string) bool {
 		// 找不到limiter，默认返回true
 		return true
 	}
+	if limiter.open {
+		if allowed, ok := art.cluster.allow(plugin.RatelimitStr[plugin.APIRatelimit], name, limiter.limit); ok {
+			return allowed
+		}
+	}
 
 	return limiter.Allow()
 }
@@ -147,9 +179,10 @@ func (art *apiRatelimit) allow(name string) bool {
 // 封装rate.Limiter
 // 每个API接口对应一个apiLimiter
 type apiLimiter struct {
-	open          bool   // 该接口是否开启限流
-	name          string // 接口名
-	*rate.Limiter        // 令牌桶对象
+	open          bool             // 该接口是否开启限流
+	name          string           // 接口名
+	limit         *BucketRatelimit // 接口的限流规则
+	*rate.Limiter                  // 令牌桶对象
 }
 
 // newAPILimiter 新建一个apiLimiter
@@ -0,0 +1,283 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/time/rate"
+
+	"github.com/polarismesh/polaris/common/redispool"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+const (
+	// ClusterModeStore 通过存储层的选主记录感知集群节点数，每个节点使用 1/N 的配额
+	ClusterModeStore = "store"
+	// ClusterModeRedis 所有节点通过 redis 共享令牌桶
+	ClusterModeRedis = "redis"
+
+	defaultSyncInterval  = 5 * time.Second
+	defaultRetryInterval = 5 * time.Second
+	defaultRedisTimeout  = 200 * time.Millisecond
+	defaultKeyPrefix     = "polaris:ratelimit:"
+)
+
+// tokenBucketScript 基于 redis 的令牌桶，返回 1 表示获取令牌成功
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1])
+local ts = tonumber(data[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) * rate / 1000)
+	ts = now
+end
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HMSET', KEYS[1], 'tokens', tokens, 'ts', ts)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return allowed
+`)
+
+// ClusterConfig 集群限流配置
+type ClusterConfig struct {
+	// 是否开启集群限流，不开启时每个节点独立限流
+	Open bool `mapstructure:"open"`
+
+	// 集群限流模式，store 或者 redis
+	Mode string `mapstructure:"mode"`
+
+	// store 模式下刷新集群节点数的周期
+	SyncInterval time.Duration `mapstructure:"sync-interval"`
+
+	// redis 模式的 redis 配置，与 redispool 的配置一致
+	Redis map[string]interface{} `mapstructure:"redis"`
+
+	// redis key 的前缀
+	KeyPrefix string `mapstructure:"key-prefix"`
+
+	// 单次访问 redis 的超时时间
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// redis 不可用时降级为本地限流，经过该时间后重新尝试 redis
+	RetryInterval time.Duration `mapstructure:"retry-interval"`
+}
+
+// quotaBackend 集群共享的令牌桶
+type quotaBackend interface {
+	// acquire 获取一个令牌
+	acquire(ctx context.Context, key string, limit *BucketRatelimit) (bool, error)
+}
+
+// redisBackend 基于 redis 的共享令牌桶
+type redisBackend struct {
+	client redis.UniversalClient
+}
+
+func (r *redisBackend) acquire(ctx context.Context, key string, limit *BucketRatelimit) (bool, error) {
+	ret, err := tokenBucketScript.Run(ctx, r.client, []string{key},
+		limit.Rate, limit.Bucket, time.Now().UnixMilli()).Int()
+	if err != nil {
+		return false, err
+	}
+	return ret == 1, nil
+}
+
+// cluster 集群限流，为空时表示未开启
+type cluster struct {
+	config  *ClusterConfig
+	storage store.AdminStore
+	backend quotaBackend
+	// members store 模式下的存活节点数
+	members int32
+	// degradeUntil redis 不可用时，在该时间之前使用本地限流
+	degradeUntil int64
+	lock         sync.Mutex
+	resizers     []func()
+	cancel       context.CancelFunc
+}
+
+// newCluster 新建集群限流，未开启时返回 nil
+func newCluster(config *ClusterConfig) (*cluster, error) {
+	if config == nil || !config.Open {
+		log.Infof("[Plugin][%s] cluster ratelimit is not open", PluginName)
+		return nil, nil
+	}
+	if config.SyncInterval <= 0 {
+		config.SyncInterval = defaultSyncInterval
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = defaultRetryInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultRedisTimeout
+	}
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = defaultKeyPrefix
+	}
+
+	c := &cluster{config: config, members: 1}
+	switch config.Mode {
+	case ClusterModeStore:
+		s, err := store.GetStore()
+		if err != nil {
+			return nil, err
+		}
+		c.storage = s
+	case ClusterModeRedis:
+		data, err := json.Marshal(config.Redis)
+		if err != nil {
+			return nil, err
+		}
+		redisConfig := &redispool.Config{}
+		if err := json.Unmarshal(data, redisConfig); err != nil {
+			return nil, err
+		}
+		c.backend = &redisBackend{client: redispool.NewRedisClient(redisConfig)}
+	default:
+		return nil, fmt.Errorf("cluster ratelimit mode(%s) is invalid", config.Mode)
+	}
+	log.Infof("[Plugin][%s] cluster ratelimit open, mode %s", PluginName, config.Mode)
+	return c, nil
+}
+
+// start 开始感知集群节点
+func (c *cluster) start() error {
+	if c == nil || c.config.Mode != ClusterModeStore {
+		return nil
+	}
+	if err := c.storage.StartLeaderElection(store.ElectionKeyRateLimitMemberPrefix + utils.LocalHost); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.syncMembers()
+	go func() {
+		ticker := time.NewTicker(c.config.SyncInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.syncMembers()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// stop 停止感知集群节点
+func (c *cluster) stop() {
+	if c == nil || c.cancel == nil {
+		return
+	}
+	c.cancel()
+	_ = c.storage.ReleaseLeaderElection(store.ElectionKeyRateLimitMemberPrefix + utils.LocalHost)
+}
+
+// syncMembers 根据有效的选主记录统计存活节点数，存储不可用时保持上一次的节点数
+func (c *cluster) syncMembers() {
+	elections, err := c.storage.ListLeaderElections()
+	if err != nil {
+		log.Errorf("[Plugin][%s] list cluster members err: %s, keep %d members",
+			PluginName, err.Error(), atomic.LoadInt32(&c.members))
+		return
+	}
+	var members int32
+	for _, item := range elections {
+		if strings.HasPrefix(item.ElectKey, store.ElectionKeyRateLimitMemberPrefix) && item.Valid {
+			members++
+		}
+	}
+	if members == 0 {
+		members = 1
+	}
+	if old := atomic.SwapInt32(&c.members, members); old != members {
+		log.Infof("[Plugin][%s] cluster members changed from %d to %d", PluginName, old, members)
+		c.lock.Lock()
+		resizers := c.resizers
+		c.lock.Unlock()
+		for _, resize := range resizers {
+			resize()
+		}
+	}
+}
+
+// onResize 注册节点数变化时的回调，用于调整本地令牌桶
+func (c *cluster) onResize(resize func()) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.resizers = append(c.resizers, resize)
+}
+
+// share 本节点的令牌桶大小以及速率
+func (c *cluster) share(limit *BucketRatelimit) (rate.Limit, int) {
+	if c == nil || c.config.Mode != ClusterModeStore {
+		return rate.Limit(limit.Rate), limit.Bucket
+	}
+	members := int(atomic.LoadInt32(&c.members))
+	bucket := int(math.Ceil(float64(limit.Bucket) / float64(members)))
+	if bucket < 1 {
+		bucket = 1
+	}
+	return rate.Limit(float64(limit.Rate) / float64(members)), bucket
+}
+
+// allow 从 redis 获取令牌，ok 为 false 时表示需要使用本地限流
+func (c *cluster) allow(scope, key string, limit *BucketRatelimit) (allowed bool, ok bool) {
+	if c == nil || c.backend == nil {
+		return false, false
+	}
+	now := time.Now()
+	if now.UnixNano() < atomic.LoadInt64(&c.degradeUntil) {
+		return false, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+	allowed, err := c.backend.acquire(ctx, c.config.KeyPrefix+scope+":"+key, limit)
+	if err != nil {
+		log.Errorf("[Plugin][%s] acquire token from redis err: %s, degrade to local ratelimit in %v",
+			PluginName, err.Error(), c.config.RetryInterval)
+		atomic.StoreInt64(&c.degradeUntil, now.Add(c.config.RetryInterval).UnixNano())
+		return false, false
+	}
+	return allowed, true
+}
@@ -0,0 +1,183 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package token
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/store"
+	"github.com/polarismesh/polaris/store/mock"
+)
+
+// fakeBackend 模拟 redis 共享令牌桶
+type fakeBackend struct {
+	err     error
+	allowed bool
+	calls   int
+}
+
+func (f *fakeBackend) acquire(ctx context.Context, key string, limit *BucketRatelimit) (bool, error) {
+	f.calls++
+	return f.allowed, f.err
+}
+
+func countAllowed(l limiter, key string, times int) int {
+	allowed := 0
+	for i := 0; i < times; i++ {
+		if l.allow(key) {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func newTestResourceLimiter(t *testing.T) *resourceRatelimit {
+	r, err := newResourceRatelimit(plugin.IPRatelimit, &ResourceLimitConfig{
+		Open:                   true,
+		Global:                 &BucketRatelimit{Open: true, Bucket: 10, Rate: 1},
+		MaxResourceCacheAmount: 16,
+	})
+	assert.NoError(t, err)
+	return r
+}
+
+func TestCluster_StoreMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := mock.NewMockStore(ctrl)
+	mockStore.EXPECT().StartLeaderElection(gomock.Any()).Return(nil)
+	mockStore.EXPECT().ReleaseLeaderElection(gomock.Any()).Return(nil)
+	elections := []*model.LeaderElection{
+		{ElectKey: store.ElectionKeyRateLimitMemberPrefix + "127.0.0.1", Valid: true},
+		{ElectKey: store.ElectionKeyRateLimitMemberPrefix + "127.0.0.2", Valid: true},
+		{ElectKey: store.ElectionKeyRateLimitMemberPrefix + "127.0.0.3", Valid: false},
+		{ElectKey: "polaris.checker", Valid: true},
+	}
+	gomock.InOrder(
+		mockStore.EXPECT().ListLeaderElections().Return(elections, nil),
+		mockStore.EXPECT().ListLeaderElections().Return(nil, errors.New("store unreachable")),
+		mockStore.EXPECT().ListLeaderElections().Return(elections[:1], nil),
+	)
+
+	c := &cluster{
+		config:  &ClusterConfig{Open: true, Mode: ClusterModeStore, SyncInterval: time.Hour},
+		storage: mockStore,
+		members: 1,
+	}
+	r := newTestResourceLimiter(t)
+	r.bindCluster(c)
+	assert.NoError(t, c.start())
+	defer c.stop()
+
+	// 2 valid members, every node owns half of the bucket
+	assert.Equal(t, 5, countAllowed(r, "ip-1", 10))
+
+	// store unreachable, keep the last members
+	c.syncMembers()
+	assert.Equal(t, 5, countAllowed(r, "ip-2", 10))
+
+	// the other member is gone, existing bucket is resized
+	c.syncMembers()
+	limit, bucket := c.share(r.config.Global)
+	assert.Equal(t, 10, bucket)
+	assert.Equal(t, float64(1), float64(limit))
+	assert.Equal(t, 10, countAllowed(r, "ip-3", 20))
+}
+
+func TestCluster_RedisMode(t *testing.T) {
+	backend := &fakeBackend{allowed: false}
+	c := &cluster{
+		config: &ClusterConfig{
+			Open:          true,
+			Mode:          ClusterModeRedis,
+			KeyPrefix:     defaultKeyPrefix,
+			Timeout:       defaultRedisTimeout,
+			RetryInterval: 200 * time.Millisecond,
+		},
+		backend: backend,
+		members: 1,
+	}
+	r := newTestResourceLimiter(t)
+	r.bindCluster(c)
+
+	// global quota is exhausted, the local bucket is ignored
+	assert.Equal(t, 0, countAllowed(r, "ip-1", 5))
+	assert.Equal(t, 5, backend.calls)
+
+	// backend unreachable, degrade to local ratelimit
+	backend.err = errors.New("redis unreachable")
+	assert.Equal(t, 10, countAllowed(r, "ip-1", 20))
+	assert.Equal(t, 6, backend.calls)
+
+	// retry the backend after the retry interval
+	backend.err = nil
+	backend.allowed = true
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, 5, countAllowed(r, "ip-1", 5))
+	assert.Equal(t, 11, backend.calls)
+}
+
+func TestTokenBucket_ClusterRedisUnreachable(t *testing.T) {
+	tb := &tokenBucket{}
+	err := tb.Initialize(&plugin.ConfigEntry{
+		Name: PluginName,
+		Option: map[string]interface{}{
+			"api-limit": map[string]interface{}{
+				"open": true,
+				"rules": []interface{}{
+					map[string]interface{}{
+						"name":  "rule",
+						"limit": map[string]interface{}{"open": true, "bucket": 3, "rate": 1},
+					},
+				},
+				"apis": []interface{}{
+					map[string]interface{}{"name": "POST:/v1/instances", "rule": "rule"},
+				},
+			},
+			"cluster": map[string]interface{}{
+				"open":           true,
+				"mode":           ClusterModeRedis,
+				"timeout":        "100ms",
+				"retry-interval": "1m",
+				"redis": map[string]interface{}{
+					"kvAddr":         "127.0.0.1:1",
+					"connectTimeout": "100ms",
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	defer func() {
+		_ = tb.Destroy()
+	}()
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		if tb.Allow(plugin.APIRatelimit, "POST:/v1/instances") {
+			allowed++
+		}
+	}
+	assert.Equal(t, 3, allowed)
+}
@@ -36,6 +36,9 @@ type Config struct {
 
 	// 基于实例的限流配置
 	InstanceLimitConf *ResourceLimitConfig `mapstructure:"instance-limit"`
+
+	// 集群限流配置
+	ClusterConf *ClusterConfig `mapstructure:"cluster"`
 }
 
 // BucketRatelimit 针对令牌桶的具体配置
@@ -102,7 +105,14 @@ func decodeConfig(data map[string]interface{}) (*Config, error) {
 		return nil, fmt.Errorf("plugin(%s) option is empty", PluginName)
 	}
 	var config Config
-	if err := mapstructure.Decode(data, &config); err != nil {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     &config,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(data); err != nil {
 		log.Errorf("[Plugin][%s] decode config err: %s", PluginName, err.Error())
 		return nil, err
 	}
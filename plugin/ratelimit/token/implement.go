@@ -32,11 +32,19 @@ func (tb *tokenBucket) initialize(c *plugin.ConfigEntry) error {
 	tb.config = config
 	tb.limiters = make(map[plugin.RatelimitType]limiter)
 
+	// 集群限流
+	cluster, err := newCluster(config.ClusterConf)
+	if err != nil {
+		return err
+	}
+	tb.cluster = cluster
+
 	// IP限流
 	irt, err := newResourceRatelimit(plugin.IPRatelimit, config.IPLimitConf)
 	if err != nil {
 		return err
 	}
+	irt.bindCluster(cluster)
 	tb.limiters[plugin.IPRatelimit] = irt
 
 	// 接口限流
@@ -44,6 +52,7 @@ func (tb *tokenBucket) initialize(c *plugin.ConfigEntry) error {
 	if err != nil {
 		return err
 	}
+	art.bindCluster(cluster)
 	tb.limiters[plugin.APIRatelimit] = art
 
 	// 操作实例限流
@@ -51,9 +60,10 @@ func (tb *tokenBucket) initialize(c *plugin.ConfigEntry) error {
 	if err != nil {
 		return err
 	}
+	instance.bindCluster(cluster)
 	tb.limiters[plugin.InstanceRatelimit] = instance
 
-	return nil
+	return cluster.start()
 }
 
 // allow 插件的限流实现函数
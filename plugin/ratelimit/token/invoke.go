@@ -24,6 +24,7 @@ import (
 // tokenBucket 实现Plugin接口
 type tokenBucket struct {
 	config   *Config
+	cluster  *cluster
 	limiters map[plugin.RatelimitType]limiter
 }
 
@@ -39,6 +40,7 @@ func (tb *tokenBucket) Initialize(c *plugin.ConfigEntry) error {
 
 // Destroy 实现Plugin接口，Destroy方法
 func (tb *tokenBucket) Destroy() error {
+	tb.cluster.stop()
 	return nil
 }
 
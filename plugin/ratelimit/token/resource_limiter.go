@@ -32,6 +32,7 @@ type resourceRatelimit struct {
 	resources *lru.Cache
 	whiteList map[string]bool
 	config    *ResourceLimitConfig
+	cluster   *cluster
 }
 
 // 新建资源限制器
@@ -79,6 +80,15 @@ func (r *resourceRatelimit) initialize(config *ResourceLimitConfig) error {
 	return nil
 }
 
+// bindCluster 开启集群限流
+func (r *resourceRatelimit) bindCluster(c *cluster) {
+	if !r.isOpen() || c == nil {
+		return
+	}
+	r.cluster = c
+	c.onResize(r.resize)
+}
+
 // 限流是否开启
 func (r *resourceRatelimit) isOpen() bool {
 	return r.config != nil && r.config.Open
@@ -99,10 +109,13 @@ func (r *resourceRatelimit) allow(key string) bool {
 		return true
 	}
 
+	if allowed, ok := r.cluster.allow(r.typStr, key, r.config.Global); ok {
+		return allowed
+	}
+
 	value, ok := r.resources.Get(key)
 	if !ok {
-		r.resources.ContainsOrAdd(key,
-			rate.NewLimiter(rate.Limit(r.config.Global.Rate), r.config.Global.Bucket))
+		r.resources.ContainsOrAdd(key, rate.NewLimiter(r.cluster.share(r.config.Global)))
 		// 上面已经加了value，这里正常情况会有value
 		value, ok = r.resources.Get(key)
 		if !ok {
@@ -115,3 +128,14 @@ func (r *resourceRatelimit) allow(key string) bool {
 
 	return value.(*rate.Limiter).Allow()
 }
+
+// resize 集群节点数变化时，调整已有的令牌桶
+func (r *resourceRatelimit) resize() {
+	limit, bucket := r.cluster.share(r.config.Global)
+	for _, key := range r.resources.Keys() {
+		if value, ok := r.resources.Peek(key); ok {
+			value.(*rate.Limiter).SetLimit(limit)
+			value.(*rate.Limiter).SetBurst(bucket)
+		}
+	}
+}
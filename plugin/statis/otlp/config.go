@@ -0,0 +1,113 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package otlp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	// ProtocolGRPC 通过 gRPC 上报
+	ProtocolGRPC = "grpc"
+	// ProtocolHTTP 通过 HTTP 上报 protobuf 编码的数据
+	ProtocolHTTP = "http"
+
+	defaultGRPCEndpoint = "127.0.0.1:4317"
+	defaultHTTPEndpoint = "127.0.0.1:4318"
+	defaultURLPath      = "/v1/metrics"
+	defaultInterval     = 60
+	defaultTimeout      = 10 * time.Second
+)
+
+// Config otlp 插件配置
+type Config struct {
+	// Endpoint collector 地址，host:port
+	Endpoint string `mapstructure:"endpoint"`
+	// Protocol 上报协议，grpc 或者 http
+	Protocol string `mapstructure:"protocol"`
+	// URLPath http 协议上报的路径
+	URLPath string `mapstructure:"urlPath"`
+	// Insecure 是否使用明文连接
+	Insecure bool `mapstructure:"insecure"`
+	// Headers 上报时携带的额外头部，例如鉴权信息
+	Headers map[string]string `mapstructure:"headers"`
+	// Interval 上报周期，单位秒
+	Interval int `mapstructure:"interval"`
+	// Timeout 单次上报的超时时间
+	Timeout time.Duration `mapstructure:"timeout"`
+	// ResourceAttributes 额外的 resource 属性
+	ResourceAttributes map[string]string `mapstructure:"resourceAttributes"`
+}
+
+func unmarshal(option map[string]interface{}) (*Config, error) {
+	cfg := &Config{
+		Protocol: ProtocolGRPC,
+		Insecure: true,
+		Interval: defaultInterval,
+		Timeout:  defaultTimeout,
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           cfg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(option); err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) validate() error {
+	c.Protocol = strings.ToLower(c.Protocol)
+	switch c.Protocol {
+	case ProtocolGRPC:
+		if c.Endpoint == "" {
+			c.Endpoint = defaultGRPCEndpoint
+		}
+	case ProtocolHTTP:
+		if c.Endpoint == "" {
+			c.Endpoint = defaultHTTPEndpoint
+		}
+		if c.URLPath == "" {
+			c.URLPath = defaultURLPath
+		}
+		if !strings.HasPrefix(c.URLPath, "/") {
+			c.URLPath = "/" + c.URLPath
+		}
+	default:
+		return fmt.Errorf("[Statis][OTLP] unsupported protocol %s", c.Protocol)
+	}
+	if c.Interval <= 0 {
+		return errors.New("[Statis][OTLP] interval must be greater than 0")
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaultTimeout
+	}
+	return nil
+}
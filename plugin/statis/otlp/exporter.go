@@ -0,0 +1,138 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// exporter 把指标发送到 otlp collector
+type exporter interface {
+	export(ctx context.Context, req *collectorpb.ExportMetricsServiceRequest) error
+	close() error
+}
+
+func newExporter(cfg *Config) (exporter, error) {
+	if cfg.Protocol == ProtocolHTTP {
+		return newHTTPExporter(cfg), nil
+	}
+	return newGRPCExporter(cfg)
+}
+
+// grpcExporter 通过 otlp/gRPC 上报
+type grpcExporter struct {
+	conn    *grpc.ClientConn
+	client  collectorpb.MetricsServiceClient
+	headers metadata.MD
+}
+
+func newGRPCExporter(cfg *Config) (*grpcExporter, error) {
+	creds := insecure.NewCredentials()
+	if !cfg.Insecure {
+		creds = credentials.NewTLS(&tls.Config{})
+	}
+	conn, err := grpc.Dial(cfg.Endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	return &grpcExporter{
+		conn:    conn,
+		client:  collectorpb.NewMetricsServiceClient(conn),
+		headers: metadata.New(cfg.Headers),
+	}, nil
+}
+
+func (e *grpcExporter) export(ctx context.Context, req *collectorpb.ExportMetricsServiceRequest) error {
+	if len(e.headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, e.headers)
+	}
+	resp, err := e.client.Export(ctx, req)
+	if err != nil {
+		return err
+	}
+	if rejected := resp.GetPartialSuccess().GetRejectedDataPoints(); rejected > 0 {
+		log.Warnf("[Statis][OTLP] collector rejected %d data points, %s",
+			rejected, resp.GetPartialSuccess().GetErrorMessage())
+	}
+	return nil
+}
+
+func (e *grpcExporter) close() error {
+	return e.conn.Close()
+}
+
+// httpExporter 通过 otlp/HTTP 上报 protobuf 编码的数据
+type httpExporter struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newHTTPExporter(cfg *Config) *httpExporter {
+	scheme := "https"
+	if cfg.Insecure {
+		scheme = "http"
+	}
+	return &httpExporter{
+		url:     scheme + "://" + cfg.Endpoint + cfg.URLPath,
+		headers: cfg.Headers,
+		client:  &http.Client{},
+	}
+}
+
+func (e *httpExporter) export(ctx context.Context, req *collectorpb.ExportMetricsServiceRequest) error {
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range e.headers {
+		httpReq.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("[Statis][OTLP] export to %s fail, status %d", e.url, resp.StatusCode)
+	}
+	return nil
+}
+
+func (e *httpExporter) close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,191 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package otlp
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+)
+
+type metricKind int
+
+const (
+	kindGauge metricKind = iota
+	kindSum
+)
+
+// dataPoint 单个时间序列的当前值
+type dataPoint struct {
+	labels map[string]string
+	value  float64
+}
+
+// metricSeries 同名指标的全部时间序列
+type metricSeries struct {
+	kind   metricKind
+	desc   string
+	points map[string]*dataPoint
+}
+
+// registry 保存指标的最新值，每个上报周期整体导出一次
+type registry struct {
+	lock      sync.Mutex
+	startTime time.Time
+	metrics   map[string]*metricSeries
+}
+
+func newRegistry() *registry {
+	return &registry{
+		startTime: time.Now(),
+		metrics:   map[string]*metricSeries{},
+	}
+}
+
+func (r *registry) series(name, desc string, kind metricKind) *metricSeries {
+	s, ok := r.metrics[name]
+	if !ok {
+		s = &metricSeries{kind: kind, desc: desc, points: map[string]*dataPoint{}}
+		r.metrics[name] = s
+	}
+	return s
+}
+
+// setGauge 设置 gauge 指标的值
+func (r *registry) setGauge(name, desc string, labels map[string]string, value float64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	key := seriesKey(labels)
+	r.series(name, desc, kindGauge).points[key] = &dataPoint{labels: copyLabels(labels), value: value}
+}
+
+// deleteGauge 删除 gauge 指标的时间序列
+func (r *registry) deleteGauge(name string, labels map[string]string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	s, ok := r.metrics[name]
+	if !ok {
+		return
+	}
+	delete(s.points, seriesKey(labels))
+}
+
+// addSum 累加单调递增的 sum 指标
+func (r *registry) addSum(name, desc string, labels map[string]string, delta float64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	key := seriesKey(labels)
+	s := r.series(name, desc, kindSum)
+	point, ok := s.points[key]
+	if !ok {
+		point = &dataPoint{labels: copyLabels(labels)}
+		s.points[key] = point
+	}
+	point.value += delta
+}
+
+// snapshot 把当前的指标值转换为 otlp 的指标
+func (r *registry) snapshot(now time.Time) []*metricspb.Metric {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	nowNano := uint64(now.UnixNano())
+	startNano := uint64(r.startTime.UnixNano())
+	ret := make([]*metricspb.Metric, 0, len(names))
+	for _, name := range names {
+		s := r.metrics[name]
+		if len(s.points) == 0 {
+			continue
+		}
+		points := make([]*metricspb.NumberDataPoint, 0, len(s.points))
+		for _, p := range s.points {
+			point := &metricspb.NumberDataPoint{
+				Attributes:   toAttributes(p.labels),
+				TimeUnixNano: nowNano,
+				Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: p.value},
+			}
+			if s.kind == kindSum {
+				point.StartTimeUnixNano = startNano
+			}
+			points = append(points, point)
+		}
+		metric := &metricspb.Metric{Name: name, Description: s.desc}
+		switch s.kind {
+		case kindSum:
+			metric.Data = &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+				DataPoints:             points,
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				IsMonotonic:            true,
+			}}
+		default:
+			metric.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: points}}
+		}
+		ret = append(ret, metric)
+	}
+	return ret
+}
+
+func seriesKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	builder := strings.Builder{}
+	for _, k := range keys {
+		builder.WriteString(k)
+		builder.WriteString("=")
+		builder.WriteString(labels[k])
+		builder.WriteString(",")
+	}
+	return builder.String()
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	ret := make(map[string]string, len(labels))
+	for k, v := range labels {
+		ret[k] = v
+	}
+	return ret
+}
+
+func toAttributes(labels map[string]string) []*commonpb.KeyValue {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	attrs := make([]*commonpb.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, &commonpb.KeyValue{
+			Key:   k,
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: labels[k]}},
+		})
+	}
+	return attrs
+}
@@ -0,0 +1,265 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package otlp
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+
+	commonLog "github.com/polarismesh/polaris/common/log"
+	"github.com/polarismesh/polaris/common/metrics"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/common/version"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/plugin/statis/base"
+)
+
+const (
+	// PluginName plugin name
+	PluginName = "otlp"
+
+	// MetricForClientDiscoverTotal total number of client discover requests
+	MetricForClientDiscoverTotal = "client_discover_total"
+	// MetricForClientDiscoverCostTime total time consumed by client discover requests, in milliseconds
+	MetricForClientDiscoverCostTime = "client_discover_cost_time_ms"
+
+	instrumentationScope = "github.com/polarismesh/polaris/plugin/statis/otlp"
+)
+
+var log = commonLog.GetScopeOrDefaultByName(commonLog.DefaultLoggerName)
+
+func init() {
+	s := &StatisWorker{}
+	plugin.RegisterPlugin(s.Name(), s)
+}
+
+// StatisWorker 以 otlp 协议把指标上报到 opentelemetry collector
+type StatisWorker struct {
+	*base.BaseWorker
+	cfg      *Config
+	cancel   context.CancelFunc
+	registry *registry
+	resource *resourcepb.Resource
+	exporter exporter
+}
+
+// Name 获取统计插件名称
+func (s *StatisWorker) Name() string {
+	return PluginName
+}
+
+// Initialize 初始化统计插件
+func (s *StatisWorker) Initialize(conf *plugin.ConfigEntry) error {
+	cfg, err := unmarshal(conf.Option)
+	if err != nil {
+		return err
+	}
+	exp, err := newExporter(cfg)
+	if err != nil {
+		return err
+	}
+	s.cfg = cfg
+	s.exporter = exp
+	s.registry = newRegistry()
+	s.resource = buildResource(cfg.ResourceAttributes)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	baseWorker, err := base.NewBaseWorker(ctx, s.metricsHandle)
+	if err != nil {
+		cancel()
+		_ = exp.close()
+		return err
+	}
+	s.BaseWorker = baseWorker
+
+	interval := time.Duration(cfg.Interval) * time.Second
+	go s.Run(ctx, interval)
+	go s.runExport(ctx, interval)
+	return nil
+}
+
+// Destroy 销毁统计插件
+func (s *StatisWorker) Destroy() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	if s.exporter != nil {
+		// 退出前把最后一批指标上报出去
+		s.flush(context.Background())
+		return s.exporter.close()
+	}
+	return nil
+}
+
+// ReportCallMetrics report call metrics info
+func (s *StatisWorker) ReportCallMetrics(metric metrics.CallMetric) {
+	s.BaseWorker.ReportCallMetrics(metric)
+}
+
+// ReportDiscoveryMetrics report discovery metrics
+func (s *StatisWorker) ReportDiscoveryMetrics(ms ...metrics.DiscoveryMetric) {
+	for i := range ms {
+		m := ms[i]
+		switch m.Type {
+		case metrics.ServiceMetrics:
+			s.registry.setGauge("service_count", "service count", m.Labels, float64(m.Total))
+			s.registry.setGauge("service_online_count", "online service count", m.Labels, float64(m.Online))
+			s.registry.setGauge("service_abnormal_count", "abnormal service count", m.Labels, float64(m.Abnormal))
+			s.registry.setGauge("service_offline_count", "offline service count", m.Labels, float64(m.Offline))
+		case metrics.InstanceMetrics:
+			s.registry.setGauge("instance_count", "instance count", m.Labels, float64(m.Total))
+			s.registry.setGauge("instance_online_count", "online instance count", m.Labels, float64(m.Online))
+			s.registry.setGauge("instance_abnormal_count", "abnormal instance count", m.Labels,
+				float64(m.Abnormal))
+			s.registry.setGauge("instance_isolate_count", "isolate instance count", m.Labels, float64(m.Isolate))
+		case metrics.ClientMetrics:
+			s.registry.setGauge("client_total", "client instance total number", nil, float64(m.Total))
+		}
+	}
+}
+
+// ReportConfigMetrics report config_center metrics
+func (s *StatisWorker) ReportConfigMetrics(ms ...metrics.ConfigMetrics) {
+	for i := range ms {
+		m := ms[i]
+		switch m.Type {
+		case metrics.ConfigGroupMetric:
+			s.registry.setGauge("config_group_count", "total number of config group", m.Labels, float64(m.Total))
+		case metrics.FileMetric:
+			s.registry.setGauge("config_file_count", "total number of config_file each config group",
+				m.Labels, float64(m.Total))
+		case metrics.ReleaseFileMetric:
+			s.registry.setGauge("config_release_file_count", "total number of config_release_file each config group",
+				m.Labels, float64(m.Total))
+		}
+	}
+}
+
+// ReportDiscoverCall report discover service times
+func (s *StatisWorker) ReportDiscoverCall(metric metrics.ClientDiscoverMetric) {
+	// 客户端 IP 不作为标签，避免时间序列过多
+	labels := map[string]string{
+		"action":    metric.Action,
+		"namespace": metric.Namespace,
+		"success":   strconv.FormatBool(metric.Success),
+	}
+	s.registry.addSum(MetricForClientDiscoverTotal, "total number of client discover requests", labels, 1)
+	s.registry.addSum(MetricForClientDiscoverCostTime, "total time consumed by client discover requests",
+		labels, float64(metric.CostTime))
+}
+
+func (s *StatisWorker) metricsHandle(mt metrics.CallMetricType, start time.Time,
+	staticsSlice []*base.APICallStatisItem) {
+	if mt != metrics.ServerCallMetric {
+		return
+	}
+	for _, item := range staticsSlice {
+		labels := base.BuildMetricLabels(item)
+		if item.Count == 0 && item.ZeroDuration > base.MaxZeroDuration {
+			for _, desc := range base.MetricDescList {
+				s.registry.deleteGauge(desc.Name, labels)
+			}
+			continue
+		}
+		var avgTime float64
+		if item.Count > 0 {
+			avgTime = float64(item.AccTime) / float64(item.Count) / 1e6
+		}
+		values := map[string]float64{
+			base.MetricForClientRqTimeout:       float64(item.AccTime) / 1e6,
+			base.MetricForClientRqIntervalCount: float64(item.Count),
+			base.MetricForClientRqTimeoutMax:    float64(item.MaxTime) / 1e6,
+			base.MetricForClientRqTimeoutMin:    float64(item.MinTime) / 1e6,
+			base.MetricForClientRqTimeoutAvg:    avgTime,
+		}
+		for _, desc := range base.MetricDescList {
+			s.registry.setGauge(desc.Name, desc.Help, labels, values[desc.Name])
+		}
+	}
+}
+
+func (s *StatisWorker) runExport(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.flush(ctx)
+		}
+	}
+}
+
+// flush 把当前的全部指标上报到 collector
+func (s *StatisWorker) flush(ctx context.Context) {
+	req := s.buildRequest(time.Now())
+	if req == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	if err := s.exporter.export(ctx, req); err != nil {
+		log.Errorf("[Statis][OTLP] export metrics to %s error, %v", s.cfg.Endpoint, err)
+	}
+}
+
+func (s *StatisWorker) buildRequest(now time.Time) *collectorpb.ExportMetricsServiceRequest {
+	ms := s.registry.snapshot(now)
+	if len(ms) == 0 {
+		return nil
+	}
+	return &collectorpb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{
+			{
+				Resource: s.resource,
+				ScopeMetrics: []*metricspb.ScopeMetrics{
+					{
+						Scope: &commonpb.InstrumentationScope{
+							Name:    instrumentationScope,
+							Version: version.Get(),
+						},
+						Metrics: ms,
+					},
+				},
+			},
+		},
+	}
+}
+
+// buildResource 构建描述当前 server 节点的 resource
+func buildResource(extra map[string]string) *resourcepb.Resource {
+	attrs := map[string]string{
+		"service.name":          "polaris-server",
+		"service.version":       version.Get(),
+		"service.instance.id":   utils.LocalHost,
+		"host.name":             utils.LocalHost,
+		metrics.LabelServerNode: utils.LocalHost,
+	}
+	for k, v := range extra {
+		attrs[k] = v
+	}
+	return &resourcepb.Resource{Attributes: toAttributes(attrs)}
+}
@@ -0,0 +1,232 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package otlp
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/polarismesh/polaris/common/metrics"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/plugin/statis/base"
+)
+
+// fakeCollector in-process stand-in of the otlp collector
+type fakeCollector struct {
+	collectorpb.UnimplementedMetricsServiceServer
+	lock    sync.Mutex
+	reqs    []*collectorpb.ExportMetricsServiceRequest
+	headers []string
+}
+
+func (c *fakeCollector) Export(ctx context.Context,
+	req *collectorpb.ExportMetricsServiceRequest) (*collectorpb.ExportMetricsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	c.add(req, md.Get("x-token"))
+	return &collectorpb.ExportMetricsServiceResponse{}, nil
+}
+
+func (c *fakeCollector) add(req *collectorpb.ExportMetricsServiceRequest, headers []string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.reqs = append(c.reqs, req)
+	c.headers = append(c.headers, headers...)
+}
+
+func (c *fakeCollector) last(t *testing.T) *collectorpb.ExportMetricsServiceRequest {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if !assert.NotEmpty(t, c.reqs) {
+		t.FailNow()
+	}
+	return c.reqs[len(c.reqs)-1]
+}
+
+func newTestWorker(t *testing.T, option map[string]interface{}) *StatisWorker {
+	s := &StatisWorker{}
+	assert.NoError(t, s.Initialize(&plugin.ConfigEntry{Name: PluginName, Option: option}))
+	return s
+}
+
+func findMetric(req *collectorpb.ExportMetricsServiceRequest, name string) *metricspb.Metric {
+	for _, rm := range req.GetResourceMetrics() {
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				if m.GetName() == name {
+					return m
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func resourceAttributes(req *collectorpb.ExportMetricsServiceRequest) map[string]string {
+	ret := map[string]string{}
+	for _, kv := range req.GetResourceMetrics()[0].GetResource().GetAttributes() {
+		ret[kv.GetKey()] = kv.GetValue().GetStringValue()
+	}
+	return ret
+}
+
+func reportTestMetrics(s *StatisWorker) {
+	s.metricsHandle(metrics.ServerCallMetric, time.Now(), []*base.APICallStatisItem{
+		{
+			API:      "/v1/Discover",
+			Code:     200000,
+			Protocol: "grpc",
+			Count:    2,
+			AccTime:  int64(30 * time.Millisecond),
+			MaxTime:  int64(20 * time.Millisecond),
+			MinTime:  int64(10 * time.Millisecond),
+		},
+	})
+	s.ReportDiscoveryMetrics(metrics.DiscoveryMetric{
+		Type:   metrics.InstanceMetrics,
+		Total:  3,
+		Online: 2,
+		Labels: map[string]string{metrics.LabelNamespace: "default", metrics.LabelService: "svc"},
+	})
+	s.ReportConfigMetrics(metrics.ConfigMetrics{
+		Type:   metrics.FileMetric,
+		Total:  5,
+		Labels: map[string]string{metrics.LabelNamespace: "default", metrics.LabelGroup: "group"},
+	})
+	for i := 0; i < 2; i++ {
+		s.ReportDiscoverCall(metrics.ClientDiscoverMetric{
+			ClientIP:  "127.0.0.1",
+			Action:    metrics.ActionDiscoverInstance,
+			Namespace: "default",
+			CostTime:  5,
+			Success:   true,
+		})
+	}
+}
+
+func TestStatisWorker_GRPC(t *testing.T) {
+	collector := &fakeCollector{}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	svr := grpc.NewServer()
+	collectorpb.RegisterMetricsServiceServer(svr, collector)
+	go func() {
+		_ = svr.Serve(ln)
+	}()
+	defer svr.Stop()
+
+	s := newTestWorker(t, map[string]interface{}{
+		"endpoint": ln.Addr().String(),
+		"headers":  map[string]interface{}{"x-token": "polaris"},
+		"resourceAttributes": map[string]interface{}{
+			"deployment.environment": "test",
+		},
+	})
+	reportTestMetrics(s)
+	s.flush(context.Background())
+
+	req := collector.last(t)
+	attrs := resourceAttributes(req)
+	assert.Equal(t, "polaris-server", attrs["service.name"])
+	assert.Equal(t, "test", attrs["deployment.environment"])
+	assert.Contains(t, attrs, metrics.LabelServerNode)
+	assert.Equal(t, []string{"polaris"}, collector.headers)
+
+	avg := findMetric(req, base.MetricForClientRqTimeoutAvg)
+	if assert.NotNil(t, avg) {
+		assert.Equal(t, float64(15), avg.GetGauge().GetDataPoints()[0].GetAsDouble())
+	}
+	instance := findMetric(req, "instance_online_count")
+	if assert.NotNil(t, instance) {
+		assert.Equal(t, float64(2), instance.GetGauge().GetDataPoints()[0].GetAsDouble())
+	}
+	assert.NotNil(t, findMetric(req, "config_file_count"))
+	discover := findMetric(req, MetricForClientDiscoverTotal)
+	if assert.NotNil(t, discover) {
+		assert.True(t, discover.GetSum().GetIsMonotonic())
+		assert.Equal(t, float64(2), discover.GetSum().GetDataPoints()[0].GetAsDouble())
+	}
+
+	// api not called for a long time, the series should be removed
+	s.metricsHandle(metrics.ServerCallMetric, time.Now(), []*base.APICallStatisItem{
+		{API: "/v1/Discover", Code: 200000, Protocol: "grpc", ZeroDuration: base.MaxZeroDuration + 1},
+	})
+	s.flush(context.Background())
+	assert.Nil(t, findMetric(collector.last(t), base.MetricForClientRqTimeoutAvg))
+	assert.NoError(t, s.Destroy())
+}
+
+func TestStatisWorker_HTTP(t *testing.T) {
+	collector := &fakeCollector{}
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/metrics" || r.Header.Get("Content-Type") != "application/x-protobuf" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		req := &collectorpb.ExportMetricsServiceRequest{}
+		if err := proto.Unmarshal(body, req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		collector.add(req, r.Header.Values("X-Token"))
+	}))
+	defer svr.Close()
+
+	s := newTestWorker(t, map[string]interface{}{
+		"endpoint": strings.TrimPrefix(svr.URL, "http://"),
+		"protocol": "HTTP",
+		"interval": "1",
+		"headers":  map[string]interface{}{"X-Token": "polaris"},
+	})
+	reportTestMetrics(s)
+
+	// the export loop push metrics periodically
+	assert.Eventually(t, func() bool {
+		collector.lock.Lock()
+		defer collector.lock.Unlock()
+		return len(collector.reqs) > 0
+	}, 5*time.Second, 100*time.Millisecond)
+	req := collector.last(t)
+	assert.NotNil(t, findMetric(req, base.MetricForClientRqIntervalCount))
+	assert.NotNil(t, findMetric(req, MetricForClientDiscoverCostTime))
+	assert.Contains(t, collector.headers, "polaris")
+	assert.NoError(t, s.Destroy())
+}
+
+func TestConfig_Invalid(t *testing.T) {
+	_, err := unmarshal(map[string]interface{}{"protocol": "udp"})
+	assert.Error(t, err)
+	cfg, err := unmarshal(map[string]interface{}{"protocol": "http"})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultHTTPEndpoint, cfg.Endpoint)
+	assert.Equal(t, defaultURLPath, cfg.URLPath)
+}
@@ -26,10 +26,17 @@ var (
 	whitelistOnce sync.Once
 )
 
+// WhitelistEntry the access to check, API is the request path, empty API only checks the IP
+type WhitelistEntry struct {
+	IP  string
+	API string
+}
+
 // Whitelist White list interface
 type Whitelist interface {
 	Plugin
 
+	// Contain entry is the ip string or WhitelistEntry
 	Contain(entry interface{}) bool
 }
 
@@ -18,19 +18,45 @@
 package whitelist
 
 import (
+	"context"
 	"errors"
+	"sync/atomic"
 
+	"github.com/mitchellh/mapstructure"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+
+	"github.com/polarismesh/polaris/common/eventhub"
+	commonlog "github.com/polarismesh/polaris/common/log"
+	"github.com/polarismesh/polaris/common/model"
 	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/store"
 )
 
 const PluginName = "whitelist"
 
+var log = commonlog.GetScopeOrDefaultByName(commonlog.DefaultLoggerName)
+
 func init() {
 	plugin.RegisterPlugin(PluginName, &ipWhitelist{})
 }
 
+// Config 插件配置
+type Config struct {
+	RuleConfig `mapstructure:",squash"`
+	// ConfigFile 保存白名单规则的配置文件，发布后动态生效，覆盖插件配置中的规则
+	ConfigFile *model.ConfigFileKey `mapstructure:"configFile"`
+}
+
 type ipWhitelist struct {
 	ips map[string]bool
+	// static 插件配置中的规则
+	static *ruleSet
+	// rules 当前生效的规则
+	rules      atomic.Value
+	configFile *model.ConfigFileKey
+	loadFile   func(key *model.ConfigFileKey) (*model.ConfigFileRelease, error)
+	subCtx     *eventhub.SubscribtionContext
 }
 
 // Name 插件名称
@@ -41,23 +67,129 @@ func (i *ipWhitelist) Name() string {
 // Initialize 初始化IP白名单插件
 func (i *ipWhitelist) Initialize(conf *plugin.ConfigEntry) error {
 	i.ips = make(map[string]bool)
-	ips, ok := conf.Option["ip"].([]interface{})
-	if !ok {
+	raw, exist := conf.Option["ip"]
+	ips, ok := raw.([]interface{})
+	if exist && !ok {
 		return errors.New("whitelist plugin initialize error")
 	}
 	for _, ip := range ips {
 		i.ips[ip.(string)] = true
 	}
+
+	cfg := &Config{}
+	if err := mapstructure.Decode(conf.Option, cfg); err != nil {
+		return err
+	}
+	if !exist && len(cfg.APIs) == 0 && cfg.ConfigFile == nil {
+		return errors.New("whitelist plugin initialize error")
+	}
+	static, err := newRuleSet(&cfg.RuleConfig)
+	if err != nil {
+		return err
+	}
+	i.static = static
+	i.rules.Store(static)
+
+	if cfg.ConfigFile == nil {
+		return nil
+	}
+	i.configFile = cfg.ConfigFile
+	if i.loadFile == nil {
+		s, err := store.GetStore()
+		if err != nil {
+			return err
+		}
+		i.loadFile = s.GetConfigFileActiveRelease
+	}
+	i.reload()
+	subCtx, err := eventhub.Subscribe(eventhub.ConfigFilePublishTopic, i)
+	if err != nil {
+		return err
+	}
+	i.subCtx = subCtx
 	return nil
 }
 
 // Destroy 销毁插件
 func (i *ipWhitelist) Destroy() error {
+	if i.subCtx != nil {
+		i.subCtx.Cancel()
+	}
+	return nil
+}
+
+// PreProcess do preprocess logic for event
+func (i *ipWhitelist) PreProcess(_ context.Context, value any) any {
+	return value
+}
+
+// OnEvent 白名单配置文件发布后重新加载规则
+func (i *ipWhitelist) OnEvent(_ context.Context, value any) error {
+	event, ok := value.(*eventhub.PublishConfigFileEvent)
+	if !ok || event.Message == nil || event.Message.ConfigFileReleaseKey == nil {
+		return nil
+	}
+	release := event.Message
+	if release.ReleaseType != model.ReleaseTypeFull || release.Namespace != i.configFile.Namespace ||
+		release.Group != i.configFile.Group || release.FileName != i.configFile.Name {
+		return nil
+	}
+	i.reload()
 	return nil
 }
 
+// reload 加载配置文件中的规则，配置文件不存在时使用插件配置中的规则，解析失败时保持当前规则
+func (i *ipWhitelist) reload() {
+	release, err := i.loadFile(i.configFile)
+	if err != nil {
+		log.Error("[Plugin][Whitelist] load whitelist config file fail", zap.String("file", i.configFile.String()),
+			zap.Error(err))
+		return
+	}
+	if release == nil || !release.Valid {
+		log.Info("[Plugin][Whitelist] whitelist config file not released, use the static rules",
+			zap.String("file", i.configFile.String()))
+		i.rules.Store(i.static)
+		return
+	}
+	if release.IsEncrypted() {
+		log.Error("[Plugin][Whitelist] encrypted whitelist config file is not supported",
+			zap.String("file", i.configFile.String()))
+		return
+	}
+	conf := &RuleConfig{}
+	if err := yaml.Unmarshal([]byte(release.Content), conf); err != nil {
+		log.Error("[Plugin][Whitelist] parse whitelist config file fail", zap.String("file", i.configFile.String()),
+			zap.Error(err))
+		return
+	}
+	rules, err := newRuleSet(conf)
+	if err != nil {
+		log.Error("[Plugin][Whitelist] invalid whitelist config file", zap.String("file", i.configFile.String()),
+			zap.Error(err))
+		return
+	}
+	i.rules.Store(rules)
+	log.Info("[Plugin][Whitelist] reload whitelist from config file", zap.String("file", i.configFile.String()),
+		zap.Uint64("version", release.Version))
+}
+
 // Contain 白名单是否包含IP
 func (i *ipWhitelist) Contain(entry interface{}) bool {
-	ip, _ := entry.(string)
-	return i.ips[ip]
+	var target plugin.WhitelistEntry
+	switch val := entry.(type) {
+	case string:
+		target.IP = val
+	case plugin.WhitelistEntry:
+		target = val
+	case *plugin.WhitelistEntry:
+		target = *val
+	default:
+		return false
+	}
+	rules, _ := i.rules.Load().(*ruleSet)
+	if rules == nil {
+		return i.ips[target.IP]
+	}
+	return rules.contain(target.IP, target.API)
 }
@@ -19,10 +19,14 @@ package whitelist
 
 import (
 	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/polarismesh/polaris/common/eventhub"
+	"github.com/polarismesh/polaris/common/model"
 	"github.com/polarismesh/polaris/plugin"
 )
 
@@ -152,3 +156,134 @@ func Test_ipWhitelist_Contain(t *testing.T) {
 		})
 	}
 }
+
+func Test_ipWhitelist_CIDRAndAPI(t *testing.T) {
+	i := &ipWhitelist{}
+	err := i.Initialize(&plugin.ConfigEntry{
+		Name: PluginName,
+		Option: map[string]interface{}{
+			"ip": []interface{}{"127.0.0.1", "10.0.0.0/8", "fd00::/8"},
+			"apis": []interface{}{
+				map[string]interface{}{"prefix": "/maintain/v1", "ip": []interface{}{"192.168.1.0/24"}},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.True(t, i.Contain("10.1.2.3"))
+	assert.True(t, i.Contain("fd00::1"))
+	assert.False(t, i.Contain("::1"))
+	assert.False(t, i.Contain("192.168.1.10"))
+	assert.True(t, i.Contain(plugin.WhitelistEntry{IP: "192.168.1.10", API: "/maintain/v1/log/outputlevel"}))
+	assert.False(t, i.Contain(&plugin.WhitelistEntry{IP: "192.168.2.10", API: "/maintain/v1/log/outputlevel"}))
+	assert.True(t, i.Contain(plugin.WhitelistEntry{IP: "127.0.0.1", API: "/maintain/v1/log/outputlevel"}))
+
+	// only lock down the admin api
+	i = &ipWhitelist{}
+	err = i.Initialize(&plugin.ConfigEntry{
+		Name: PluginName,
+		Option: map[string]interface{}{
+			"apis": []interface{}{
+				map[string]interface{}{"prefix": "/maintain/v1", "ip": []interface{}{"::1"}},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, i.Contain(plugin.WhitelistEntry{IP: "1.1.1.1", API: "/naming/v1/instances"}))
+	assert.True(t, i.Contain(plugin.WhitelistEntry{IP: "::1", API: "/maintain/v1/cmdb/info"}))
+	assert.False(t, i.Contain(plugin.WhitelistEntry{IP: "1.1.1.1", API: "/maintain/v1/cmdb/info"}))
+
+	err = (&ipWhitelist{}).Initialize(&plugin.ConfigEntry{
+		Name:   PluginName,
+		Option: map[string]interface{}{"ip": []interface{}{"10.0.0.0/33"}},
+	})
+	assert.Error(t, err)
+}
+
+func Test_ipWhitelist_ConfigFile(t *testing.T) {
+	eventhub.InitEventHub()
+	release := &model.ConfigFileRelease{
+		SimpleConfigFileRelease: &model.SimpleConfigFileRelease{
+			ConfigFileReleaseKey: &model.ConfigFileReleaseKey{
+				Namespace: "Polaris",
+				Group:     "polaris-server",
+				FileName:  "whitelist.yaml",
+			},
+			Version: 1,
+			Valid:   true,
+			Active:  true,
+		},
+		Content: "ip: [10.0.0.0/8]\n",
+	}
+	var lock sync.Mutex
+	i := &ipWhitelist{
+		loadFile: func(key *model.ConfigFileKey) (*model.ConfigFileRelease, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			if release == nil {
+				return nil, nil
+			}
+			ret := *release
+			return &ret, nil
+		},
+	}
+	err := i.Initialize(&plugin.ConfigEntry{
+		Name: PluginName,
+		Option: map[string]interface{}{
+			"ip": []interface{}{"127.0.0.1"},
+			"configFile": map[string]interface{}{
+				"namespace": "Polaris",
+				"group":     "polaris-server",
+				"name":      "whitelist.yaml",
+			},
+		},
+	})
+	assert.NoError(t, err)
+	defer func() {
+		_ = i.Destroy()
+	}()
+	// rules in config file take effect
+	assert.True(t, i.Contain("10.0.0.1"))
+	assert.False(t, i.Contain("127.0.0.1"))
+
+	publish := func(content string, valid bool) {
+		lock.Lock()
+		release.Content = content
+		release.Version++
+		lock.Unlock()
+		if !valid {
+			lock.Lock()
+			release = nil
+			lock.Unlock()
+		}
+		_ = eventhub.Publish(eventhub.ConfigFilePublishTopic, &eventhub.PublishConfigFileEvent{
+			Message: &model.SimpleConfigFileRelease{
+				ConfigFileReleaseKey: &model.ConfigFileReleaseKey{
+					Namespace: "Polaris",
+					Group:     "polaris-server",
+					FileName:  "whitelist.yaml",
+				},
+				Active: true,
+				Valid:  true,
+			},
+		})
+	}
+
+	publish("apis:\n  - prefix: /maintain/v1\n    ip: [\"192.168.0.0/16\"]\n", true)
+	assert.Eventually(t, func() bool {
+		return i.Contain(plugin.WhitelistEntry{IP: "1.1.1.1", API: "/naming/v1/instances"})
+	}, 3*time.Second, 20*time.Millisecond)
+	assert.True(t, i.Contain(plugin.WhitelistEntry{IP: "192.168.1.1", API: "/maintain/v1/cmdb/info"}))
+	assert.False(t, i.Contain(plugin.WhitelistEntry{IP: "1.1.1.1", API: "/maintain/v1/cmdb/info"}))
+
+	// invalid content keep the current rules
+	publish("ip: [not-an-ip]\n", true)
+	time.Sleep(200 * time.Millisecond)
+	assert.True(t, i.Contain(plugin.WhitelistEntry{IP: "192.168.1.1", API: "/maintain/v1/cmdb/info"}))
+
+	// config file removed, fallback to the static rules
+	publish("", false)
+	assert.Eventually(t, func() bool {
+		return i.Contain("127.0.0.1")
+	}, 3*time.Second, 20*time.Millisecond)
+}
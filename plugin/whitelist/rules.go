@@ -0,0 +1,139 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package whitelist
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// RuleConfig 白名单规则，插件配置以及配置中心的白名单文件使用相同的结构
+type RuleConfig struct {
+	// IP 对所有接口生效的 IP 或者网段，支持 IPv4 以及 IPv6
+	IP []string `mapstructure:"ip" yaml:"ip"`
+	// APIs 只对指定接口生效的白名单
+	APIs []APIRuleConfig `mapstructure:"apis" yaml:"apis"`
+}
+
+// APIRuleConfig 接口级别的白名单
+type APIRuleConfig struct {
+	// Prefix 接口路径前缀，例如 /maintain/v1
+	Prefix string `mapstructure:"prefix" yaml:"prefix"`
+	// IP 允许访问的 IP 或者网段
+	IP []string `mapstructure:"ip" yaml:"ip"`
+}
+
+// ipMatcher 匹配单个 IP 以及网段
+type ipMatcher struct {
+	hosts map[string]struct{}
+	nets  []*net.IPNet
+}
+
+func newIPMatcher(items []string) (*ipMatcher, error) {
+	m := &ipMatcher{hosts: make(map[string]struct{}, len(items))}
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if strings.Contains(item, "/") {
+			_, ipNet, err := net.ParseCIDR(item)
+			if err != nil {
+				return nil, err
+			}
+			m.nets = append(m.nets, ipNet)
+			continue
+		}
+		ip := net.ParseIP(item)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid whitelist ip %s", item)
+		}
+		m.hosts[ip.String()] = struct{}{}
+	}
+	return m, nil
+}
+
+func (m *ipMatcher) empty() bool {
+	return len(m.hosts) == 0 && len(m.nets) == 0
+}
+
+func (m *ipMatcher) match(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if _, ok := m.hosts[ip.String()]; ok {
+		return true
+	}
+	for _, ipNet := range m.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// apiRule 接口级别的白名单
+type apiRule struct {
+	prefix  string
+	matcher *ipMatcher
+}
+
+// ruleSet 解析后的白名单规则，创建后只读
+type ruleSet struct {
+	global *ipMatcher
+	apis   []apiRule
+}
+
+func newRuleSet(conf *RuleConfig) (*ruleSet, error) {
+	global, err := newIPMatcher(conf.IP)
+	if err != nil {
+		return nil, err
+	}
+	rs := &ruleSet{global: global, apis: make([]apiRule, 0, len(conf.APIs))}
+	for _, item := range conf.APIs {
+		if item.Prefix == "" {
+			return nil, fmt.Errorf("whitelist api prefix is empty")
+		}
+		matcher, err := newIPMatcher(item.IP)
+		if err != nil {
+			return nil, err
+		}
+		rs.apis = append(rs.apis, apiRule{prefix: item.Prefix, matcher: matcher})
+	}
+	// 最长前缀优先匹配
+	sort.SliceStable(rs.apis, func(i, j int) bool {
+		return len(rs.apis[i].prefix) > len(rs.apis[j].prefix)
+	})
+	return rs, nil
+}
+
+// contain 命中全局白名单，或者命中接口的白名单时允许访问；
+// 全局白名单为空并且没有对应的接口规则时，不做限制
+func (rs *ruleSet) contain(host, api string) bool {
+	ip := net.ParseIP(host)
+	if rs.global.match(ip) {
+		return true
+	}
+	if api != "" {
+		for _, rule := range rs.apis {
+			if strings.HasPrefix(api, rule.prefix) {
+				return rule.matcher.match(ip)
+			}
+		}
+	}
+	return rs.global.empty()
+}
@@ -20,6 +20,7 @@ package healthcheck
 import (
 	"context"
 	"runtime"
+	"strings"
 
 	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
 
@@ -115,11 +116,11 @@ func (c *CacheProvider) OnCreated(value interface{}) {
 			c.sendEvent(CacheEvent{selfServiceInstancesChanged: true})
 			return
 		}
-		hcEnable, checker := c.isHealthCheckEnable(instProto)
+		hcEnable, checker := c.isHealthCheckEnable(actual)
 		if !hcEnable {
 			return
 		}
-		storeServiceInstance(newInstanceWithChecker(actual, checker), c.healthCheckInstances)
+		storeServiceInstance(c.newInstanceWithChecker(actual, checker), c.healthCheckInstances)
 		c.sendEvent(CacheEvent{healthCheckInstancesChanged: true})
 	case *model.Client:
 		checker, ok := c.getHealthChecker(apiservice.HealthCheck_HEARTBEAT)
@@ -136,7 +137,20 @@ func (c *CacheProvider) getHealthChecker(hcType apiservice.HealthCheck_HealthChe
 	return checker, ok
 }
 
-func (c *CacheProvider) isHealthCheckEnable(instance *apiservice.Instance) (bool, plugin.HealthChecker) {
+func (c *CacheProvider) isHealthCheckEnable(ins *model.Instance) (bool, plugin.HealthChecker) {
+	// 配置了主动探测的实例，由服务端主动探测，不依赖客户端心跳；探测参数不合法时按照未配置主动探测处理
+	if checker, ok := c.svr.checkers[int32(plugin.HealthCheckerProbe)].(plugin.ProbeHealthChecker); ok {
+		metadata := c.getCheckMetadata(ins)
+		if _, enable := metadata[model.MetadataProbeType]; enable {
+			err := checker.ValidateMetadata(metadata)
+			if err == nil {
+				return true, checker
+			}
+			log.Warnf("[Health Check][Cache]ignore invalid probe config of instance %s:%d, id is %s, %s",
+				ins.Host(), ins.Port(), ins.ID(), err.Error())
+		}
+	}
+	instance := ins.Proto
 	if !instance.GetEnableHealthCheck().GetValue() || instance.GetHealthCheck() == nil {
 		return false, nil
 	}
@@ -147,6 +161,26 @@ func (c *CacheProvider) isHealthCheckEnable(instance *apiservice.Instance) (bool
 	return true, checker
 }
 
+// getCheckMetadata 获取实例的探测参数，实例 metadata 中的配置优先于服务 metadata
+func (c *CacheProvider) getCheckMetadata(ins *model.Instance) map[string]string {
+	ret := map[string]string{}
+	if c.svr.serviceCache != nil {
+		if svc := c.svr.serviceCache.GetServiceByID(ins.ServiceID); svc != nil {
+			for k, v := range svc.Meta {
+				if strings.HasPrefix(k, model.ProbeMetadataPrefix) {
+					ret[k] = v
+				}
+			}
+		}
+	}
+	for k, v := range ins.Metadata() {
+		if strings.HasPrefix(k, model.ProbeMetadataPrefix) {
+			ret[k] = v
+		}
+	}
+	return ret
+}
+
 // OnUpdated callback when cache value updated
 func (c *CacheProvider) OnUpdated(value interface{}) {
 	switch actual := value.(type) {
@@ -161,7 +195,7 @@ func (c *CacheProvider) OnUpdated(value interface{}) {
 		// check exists
 		instanceId := actual.ID()
 		value, exists := c.healthCheckInstances.Get(instanceId)
-		hcEnable, checker := c.isHealthCheckEnable(instProto)
+		hcEnable, checker := c.isHealthCheckEnable(actual)
 		if !hcEnable {
 			if !exists {
 				// instance is unhealthy, not exist, just return.
@@ -187,7 +221,7 @@ func (c *CacheProvider) OnUpdated(value interface{}) {
 				actual.Host(), actual.Port(), instanceId)
 			//   In the concurrent scenario, when the healthCheckInstance.Revision() of the same health instance is the same,
 			//   if it arrives here at the same time, it will be saved multiple times
-			c.healthCheckInstances.Put(instanceId, newInstanceWithChecker(actual, checker))
+			c.healthCheckInstances.Put(instanceId, c.newInstanceWithChecker(actual, checker))
 			c.sendEvent(CacheEvent{healthCheckInstancesChanged: true})
 		}
 	case *model.Client:
@@ -211,7 +245,7 @@ func (c *CacheProvider) OnDeleted(value interface{}) {
 			c.sendEvent(CacheEvent{selfServiceInstancesChanged: true})
 			return
 		}
-		if !instProto.GetEnableHealthCheck().GetValue() || instProto.GetHealthCheck() == nil {
+		if _, ok := c.healthCheckInstances.Get(actual.ID()); !ok {
 			return
 		}
 		deleteServiceInstance(instProto, c.healthCheckInstances)
@@ -257,6 +291,15 @@ func (c *CacheProvider) GetSelfServiceInstance(instanceId string) *model.Instanc
 	return ins
 }
 
+// GetInstanceChecker get the checker of the instance by id
+func (c *CacheProvider) GetInstanceChecker(instanceId string) (plugin.HealthChecker, bool) {
+	value, ok := c.healthCheckInstances.Get(instanceId)
+	if !ok || value.GetChecker() == nil {
+		return nil, false
+	}
+	return value.GetChecker(), true
+}
+
 // GetInstance get instance by id
 func (c *CacheProvider) GetInstance(instanceId string) *model.Instance {
 	value, ok := c.healthCheckInstances.Get(instanceId)
@@ -382,6 +425,8 @@ type InstanceWithChecker struct {
 	instance  *model.Instance
 	checker   plugin.HealthChecker
 	hashValue uint
+	// metadata 主动探测的参数，仅对主动探测类型的 checker 有效
+	metadata map[string]string
 }
 
 // GetInstance 获取服务实例
@@ -412,6 +457,15 @@ func newInstanceWithChecker(instance *model.Instance, checker plugin.HealthCheck
 	}
 }
 
+func (c *CacheProvider) newInstanceWithChecker(instance *model.Instance,
+	checker plugin.HealthChecker) *InstanceWithChecker {
+	ic := newInstanceWithChecker(instance, checker)
+	if checker != nil && checker.Type() == plugin.HealthCheckerProbe {
+		ic.metadata = c.getCheckMetadata(instance)
+	}
+	return ic
+}
+
 // ClientWithChecker instance and checker combine
 type ClientWithChecker struct {
 	client    *model.Client
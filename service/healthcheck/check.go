@@ -79,6 +79,7 @@ type itemValue struct {
 	ttlDurationSec    uint32
 	expireDurationSec uint32
 	checker           plugin.HealthChecker
+	metadata          map[string]string
 }
 
 type InstanceEventHealthCheckHandler struct {
@@ -226,21 +227,25 @@ func (c *CheckScheduler) upsertInstanceChecker(instanceWithChecker *InstanceWith
 	c.rwMutex.Lock()
 	defer c.rwMutex.Unlock()
 	instance := instanceWithChecker.instance
-	ttl := instance.HealthCheck().GetHeartbeat().GetTtl().GetValue()
+	ttl, expireDurationSec := getCheckDurationSec(instanceWithChecker)
 	var (
 		instValue *itemValue
 		exist     bool
 	)
 	instValue, exist = c.scheduledInstances[instance.ID()]
 	if exist {
-		if ttl == instValue.ttlDurationSec {
+		if ttl == instValue.ttlDurationSec && instanceWithChecker.checker == instValue.checker {
+			instValue.mutex.Lock()
+			instValue.metadata = instanceWithChecker.metadata
+			instValue.mutex.Unlock()
 			return true, instValue
 		}
 		// force update check info
 		instValue.mutex.Lock()
 		oldTtl := instValue.ttlDurationSec
 		instValue.checker = instanceWithChecker.checker
-		instValue.expireDurationSec = getExpireDurationSec(instance.Proto)
+		instValue.metadata = instanceWithChecker.metadata
+		instValue.expireDurationSec = expireDurationSec
 		instValue.ttlDurationSec = ttl
 		instValue.mutex.Unlock()
 		if log.DebugEnabled() {
@@ -253,9 +258,10 @@ func (c *CheckScheduler) upsertInstanceChecker(instanceWithChecker *InstanceWith
 			host:              instance.Host(),
 			port:              instance.Port(),
 			id:                instance.ID(),
-			expireDurationSec: getExpireDurationSec(instance.Proto),
+			expireDurationSec: expireDurationSec,
 			checker:           instanceWithChecker.checker,
 			ttlDurationSec:    ttl,
+			metadata:          instanceWithChecker.metadata,
 		}
 	}
 	c.scheduledInstances[instance.ID()] = instValue
@@ -332,6 +338,19 @@ func getExpireDurationSec(instance *apiservice.Instance) uint32 {
 	return expireTtlCount * ttlValue
 }
 
+// getCheckDurationSec 心跳类型按照心跳 ttl 计算检查周期，主动探测类型每个探测周期检查一次
+func getCheckDurationSec(instanceWithChecker *InstanceWithChecker) (uint32, uint32) {
+	if probeChecker, ok := instanceWithChecker.checker.(plugin.ProbeHealthChecker); ok {
+		intervalSec := uint32(probeChecker.CheckInterval(instanceWithChecker.metadata).Seconds())
+		if intervalSec == 0 {
+			intervalSec = 1
+		}
+		return intervalSec, intervalSec
+	}
+	instance := instanceWithChecker.instance
+	return instance.HealthCheck().GetHeartbeat().GetTtl().GetValue(), getExpireDurationSec(instance.Proto)
+}
+
 func getRandDelayMilli() uint32 {
 	delayMilli := srand.Intn(1000)
 	return uint32(delayMilli)
@@ -459,6 +478,7 @@ func (c *CheckScheduler) checkCallbackInstance(value interface{}) {
 		},
 		CurTimeSec:        c.svr.currentTimeSec,
 		ExpireDurationSec: instanceValue.expireDurationSec,
+		Metadata:          instanceValue.metadata,
 	}
 	checkResp, err = instanceValue.checker.Check(request)
 	if err != nil {
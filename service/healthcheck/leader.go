@@ -107,13 +107,15 @@ func (handler *LeaderChangeEventHandler) stopCheckSelfServiceInstances() {
 
 // startCheckSelfServiceInstances
 func (handler *LeaderChangeEventHandler) doCheckSelfServiceInstance(cachedInstance *model.Instance) {
-	hcEnable, checker := handler.cacheProvider.isHealthCheckEnable(cachedInstance.Proto)
+	hcEnable, checker := handler.cacheProvider.isHealthCheckEnable(cachedInstance)
 	if !hcEnable {
 		log.Warnf("[Health Check][Check] selfService instance %s:%d not enable healthcheck",
 			cachedInstance.Host(), cachedInstance.Port())
 		return
 	}
 
+	instanceWithChecker := handler.cacheProvider.newInstanceWithChecker(cachedInstance, checker)
+	_, expireDurationSec := getCheckDurationSec(instanceWithChecker)
 	request := &plugin.CheckRequest{
 		QueryRequest: plugin.QueryRequest{
 			InstanceId: cachedInstance.ID(),
@@ -122,7 +124,8 @@ func (handler *LeaderChangeEventHandler) doCheckSelfServiceInstance(cachedInstan
 			Healthy:    cachedInstance.Healthy(),
 		},
 		CurTimeSec:        handler.svr.currentTimeSec,
-		ExpireDurationSec: getExpireDurationSec(cachedInstance.Proto),
+		ExpireDurationSec: expireDurationSec,
+		Metadata:          instanceWithChecker.metadata,
 	}
 	checkResp, err := checker.Check(request)
 	if err != nil {
@@ -284,7 +284,10 @@ func (s *Server) handleInstanceEventWorker(ctx context.Context) {
 					log.Errorf("[Health Check] cannot get instance from cache, instance id is %s", event.Id)
 					break
 				}
-				checker, ok := s.checkers[int32(insCache.HealthCheck().GetType())]
+				checker, ok := s.cacheProvider.GetInstanceChecker(event.Id)
+				if !ok {
+					checker, ok = s.checkers[int32(insCache.HealthCheck().GetType())]
+				}
 				if !ok {
 					log.Errorf("[Health Check]heart beat type not found checkType %d",
 						int32(insCache.HealthCheck().GetType()))
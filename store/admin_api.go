@@ -26,6 +26,12 @@ import (
 const (
 	ElectionKeySelfServiceChecker = "polaris.checker"
 	ElectionKeyMaintainJobPrefix  = "MaintainJob."
+	// ElectionKeyRateLimitMemberPrefix 集群限流时，每个节点以自身地址参与选主，用于统计存活节点
+	ElectionKeyRateLimitMemberPrefix = "RateLimitMember."
+	// ElectionKeyLDAPSync 只有主节点执行 LDAP 用户以及用户组的同步
+	ElectionKeyLDAPSync = "polaris.auth.ldap"
+	// ElectionKeyEurekaSyncPrefix 只有主节点从已有的 eureka 集群拉取数据，后缀为同步源的名称
+	ElectionKeyEurekaSyncPrefix = "polaris.eureka.sync."
 )
 
 type AdminStore interface {
@@ -51,6 +57,16 @@ type AdminStore interface {
 	BatchCleanDeletedClients(timeout time.Duration, batchSize uint32) (uint32, error)
 }
 
+// OperationRecordStore 操作记录存储接口
+type OperationRecordStore interface {
+	// AddOperationRecords batch save operation records
+	AddOperationRecords(records []*model.RecordEntry) error
+	// GetOperationRecords query operation records order by happen time desc
+	GetOperationRecords(filter map[string]string, offset, limit uint32) (uint32, []*model.RecordEntry, error)
+	// CleanOperationRecords delete operation records which happen before endTime
+	CleanOperationRecords(endTime time.Time, limit uint64) (uint64, error)
+}
+
 // LeaderChangeEvent
 type LeaderChangeEvent struct {
 	Key        string
@@ -47,6 +47,8 @@ type Store interface {
 	ClientStore
 	// AdminStore Maintain inteface
 	AdminStore
+	// OperationRecordStore operation history interface
+	OperationRecordStore
 	// GrayStore mgr gray resource
 	GrayStore
 }
@@ -72,6 +72,30 @@ type GroupStore interface {
 	GetGroupsForCache(mtime time.Time, firstUpdate bool) ([]*model.UserGroupDetail, error)
 }
 
+// AccessTokenStore Personal access token storage operation interface
+type AccessTokenStore interface {
+
+	// AddAccessToken Create a personal access token
+	AddAccessToken(token *model.AccessToken) error
+
+	// DeleteAccessToken Revoke a personal access token, the token is logically deleted
+	DeleteAccessToken(id string) error
+
+	// GetAccessToken Get a personal access token
+	GetAccessToken(id string) (*model.AccessToken, error)
+
+	// GetAccessTokens Query personal access tokens, support filter by principal_id, principal_type and owner
+	GetAccessTokens(filters map[string]string, offset uint32, limit uint32) (uint32, []*model.AccessToken, error)
+
+	// UpdateAccessTokensLastUsed Record the last used time of personal access tokens
+	// 只更新最后使用时间，不修改 mtime，避免触发 cache 的增量更新
+	UpdateAccessTokensLastUsed(lastUsed map[string]time.Time) error
+
+	// GetAccessTokensForCache Used to refresh personal access token cache
+	// 此方法用于 cache 增量更新，需要注意 mtime 应为数据库时间戳
+	GetAccessTokensForCache(mtime time.Time, firstUpdate bool) ([]*model.AccessToken, error)
+}
+
 // StrategyStore Authentication policy related storage operation interface
 type StrategyStore interface {
 
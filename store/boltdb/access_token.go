@@ -0,0 +1,253 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package boltdb
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+const (
+	tblAccessToken string = "access_token"
+
+	AccessTokenFieldPrincipalID   string = "PrincipalID"
+	AccessTokenFieldPrincipalType string = "PrincipalType"
+	AccessTokenFieldOwner         string = "Owner"
+	AccessTokenFieldLastUsedTime  string = "LastUsedTime"
+	AccessTokenFieldValid         string = "Valid"
+	AccessTokenFieldModifyTime    string = "ModifyTime"
+)
+
+// accessTokenForStore 个人访问令牌的存储结构，资源范围以 json 格式保存
+type accessTokenForStore struct {
+	ID            string
+	Name          string
+	PrincipalID   string
+	PrincipalType int
+	Owner         string
+	Secret        string
+	Scope         string
+	ReadOnly      bool
+	ExpireTime    time.Time
+	LastUsedTime  time.Time
+	Valid         bool
+	CreateTime    time.Time
+	ModifyTime    time.Time
+}
+
+type accessTokenStore struct {
+	handler BoltHandler
+}
+
+// AddAccessToken 创建个人访问令牌
+func (a *accessTokenStore) AddAccessToken(token *model.AccessToken) error {
+	if token.ID == "" || token.PrincipalID == "" || token.Secret == "" {
+		return store.NewStatusError(store.EmptyParamsErr, "add access token missing some params")
+	}
+	tn := a.handler.Now()
+	token.Valid = true
+	token.CreateTime = tn
+	token.ModifyTime = tn
+
+	data, err := converToAccessTokenStore(token)
+	if err != nil {
+		return store.Error(err)
+	}
+	if err := a.handler.SaveValue(tblAccessToken, token.ID, data); err != nil {
+		log.Error("[Store][AccessToken] save access token", zap.String("id", token.ID), zap.Error(err))
+		return store.Error(err)
+	}
+	return nil
+}
+
+// DeleteAccessToken 吊销个人访问令牌
+func (a *accessTokenStore) DeleteAccessToken(id string) error {
+	properties := map[string]interface{}{
+		AccessTokenFieldValid:      false,
+		AccessTokenFieldModifyTime: a.handler.Now(),
+	}
+	if err := a.handler.UpdateValue(tblAccessToken, id, properties); err != nil {
+		log.Error("[Store][AccessToken] delete access token", zap.String("id", id), zap.Error(err))
+		return store.Error(err)
+	}
+	return nil
+}
+
+// GetAccessToken 获取个人访问令牌
+func (a *accessTokenStore) GetAccessToken(id string) (*model.AccessToken, error) {
+	ret, err := a.handler.LoadValues(tblAccessToken, []string{id}, &accessTokenForStore{})
+	if err != nil {
+		return nil, store.Error(err)
+	}
+	val, ok := ret[id]
+	if !ok {
+		return nil, nil
+	}
+	token := converToAccessTokenModel(val.(*accessTokenForStore))
+	if !token.Valid {
+		return nil, nil
+	}
+	return token, nil
+}
+
+// GetAccessTokens 查询个人访问令牌，按照创建时间倒序
+func (a *accessTokenStore) GetAccessTokens(filters map[string]string,
+	offset uint32, limit uint32) (uint32, []*model.AccessToken, error) {
+	fields := []string{AccessTokenFieldPrincipalID, AccessTokenFieldPrincipalType, AccessTokenFieldOwner,
+		AccessTokenFieldValid}
+	ret, err := a.handler.LoadValuesByFilter(tblAccessToken, fields, &accessTokenForStore{},
+		func(m map[string]interface{}) bool {
+			if valid, _ := m[AccessTokenFieldValid].(bool); !valid {
+				return false
+			}
+			if id, ok := filters["principal_id"]; ok && m[AccessTokenFieldPrincipalID].(string) != id {
+				return false
+			}
+			if val, ok := filters["principal_type"]; ok {
+				typ, err := strconv.ParseInt(val, 10, 64)
+				if saveVal, _ := m[AccessTokenFieldPrincipalType].(int64); err != nil || saveVal != typ {
+					return false
+				}
+			}
+			if owner, ok := filters["owner"]; ok && m[AccessTokenFieldOwner].(string) != owner {
+				return false
+			}
+			return true
+		})
+	if err != nil {
+		return 0, nil, store.Error(err)
+	}
+
+	tokens := make([]*model.AccessToken, 0, len(ret))
+	for _, v := range ret {
+		tokens = append(tokens, converToAccessTokenModel(v.(*accessTokenForStore)))
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].CreateTime.Equal(tokens[j].CreateTime) {
+			return tokens[i].ID < tokens[j].ID
+		}
+		return tokens[i].CreateTime.After(tokens[j].CreateTime)
+	})
+
+	total := uint32(len(tokens))
+	if offset >= total {
+		return total, []*model.AccessToken{}, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return total, tokens[offset:end], nil
+}
+
+// UpdateAccessTokensLastUsed 记录个人访问令牌的最后使用时间
+func (a *accessTokenStore) UpdateAccessTokensLastUsed(lastUsed map[string]time.Time) error {
+	if len(lastUsed) == 0 {
+		return nil
+	}
+	err := a.handler.Execute(true, func(tx *bolt.Tx) error {
+		for id, usedTime := range lastUsed {
+			if err := updateValue(tx, tblAccessToken, id, map[string]interface{}{
+				AccessTokenFieldLastUsedTime: usedTime,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return store.Error(err)
+}
+
+// GetAccessTokensForCache 获取增量更新的个人访问令牌
+func (a *accessTokenStore) GetAccessTokensForCache(mtime time.Time,
+	firstUpdate bool) ([]*model.AccessToken, error) {
+	ret, err := a.handler.LoadValuesByFilter(tblAccessToken, []string{AccessTokenFieldModifyTime},
+		&accessTokenForStore{}, func(m map[string]interface{}) bool {
+			mt, _ := m[AccessTokenFieldModifyTime].(time.Time)
+			return !mt.Before(mtime)
+		})
+	if err != nil {
+		log.Error("[Store][AccessToken] get access tokens for cache", zap.Error(err))
+		return nil, store.Error(err)
+	}
+
+	tokens := make([]*model.AccessToken, 0, len(ret))
+	for _, v := range ret {
+		tokens = append(tokens, converToAccessTokenModel(v.(*accessTokenForStore)))
+	}
+	return tokens, nil
+}
+
+func converToAccessTokenStore(token *model.AccessToken) (*accessTokenForStore, error) {
+	var scope string
+	if !token.Scope.IsEmpty() {
+		data, err := json.Marshal(token.Scope)
+		if err != nil {
+			return nil, err
+		}
+		scope = string(data)
+	}
+	return &accessTokenForStore{
+		ID:            token.ID,
+		Name:          token.Name,
+		PrincipalID:   token.PrincipalID,
+		PrincipalType: int(token.PrincipalType),
+		Owner:         token.Owner,
+		Secret:        token.Secret,
+		Scope:         scope,
+		ReadOnly:      token.ReadOnly,
+		ExpireTime:    token.ExpireTime,
+		LastUsedTime:  token.LastUsedTime,
+		Valid:         token.Valid,
+		CreateTime:    token.CreateTime,
+		ModifyTime:    token.ModifyTime,
+	}, nil
+}
+
+func converToAccessTokenModel(token *accessTokenForStore) *model.AccessToken {
+	ret := &model.AccessToken{
+		ID:            token.ID,
+		Name:          token.Name,
+		PrincipalID:   token.PrincipalID,
+		PrincipalType: model.PrincipalType(token.PrincipalType),
+		Owner:         token.Owner,
+		Secret:        token.Secret,
+		ReadOnly:      token.ReadOnly,
+		ExpireTime:    token.ExpireTime,
+		LastUsedTime:  token.LastUsedTime,
+		Valid:         token.Valid,
+		CreateTime:    token.CreateTime,
+		ModifyTime:    token.ModifyTime,
+	}
+	if token.Scope != "" {
+		scope := &model.AccessTokenScope{}
+		if err := json.Unmarshal([]byte(token.Scope), scope); err != nil {
+			log.Error("[Store][AccessToken] unmarshal token scope", zap.String("id", token.ID), zap.Error(err))
+		}
+		ret.Scope = scope
+	}
+	return ret
+}
@@ -0,0 +1,86 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package boltdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+func Test_accessTokenStore(t *testing.T) {
+	CreateTableDBHandlerAndRun(t, tblAccessToken, func(t *testing.T, handler BoltHandler) {
+		s := &accessTokenStore{handler: handler}
+		start := time.Now().Add(-time.Second)
+		expire := time.Now().Add(time.Hour).Truncate(time.Second)
+
+		tokens := []*model.AccessToken{
+			{ID: "t1", Name: "ci", PrincipalID: "u1", PrincipalType: model.PrincipalUser, Owner: "owner",
+				Secret: "s1", ExpireTime: expire, ReadOnly: true,
+				Scope: &model.AccessTokenScope{Namespaces: []string{"default"}, Services: []string{"Test/svc"}}},
+			{ID: "t2", Name: "deploy", PrincipalID: "u1", PrincipalType: model.PrincipalUser, Owner: "owner",
+				Secret: "s2", ExpireTime: expire},
+			{ID: "t3", Name: "ci", PrincipalID: "g1", PrincipalType: model.PrincipalGroup, Owner: "owner",
+				Secret: "s3", ExpireTime: expire},
+		}
+		for _, token := range tokens {
+			assert.NoError(t, s.AddAccessToken(token))
+		}
+		assert.Error(t, s.AddAccessToken(&model.AccessToken{ID: "t4"}))
+
+		ret, err := s.GetAccessToken("t1")
+		assert.NoError(t, err)
+		assert.True(t, ret.Valid)
+		assert.True(t, ret.ReadOnly)
+		assert.Equal(t, tokens[0].Scope, ret.Scope)
+		assert.True(t, expire.Equal(ret.ExpireTime))
+
+		total, list, err := s.GetAccessTokens(map[string]string{"principal_id": "u1", "principal_type": "1"}, 0, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(2), total)
+		assert.Equal(t, 2, len(list))
+		total, _, err = s.GetAccessTokens(map[string]string{"owner": "owner"}, 0, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(3), total)
+
+		used := time.Now().Truncate(time.Second)
+		assert.NoError(t, s.UpdateAccessTokensLastUsed(map[string]time.Time{"t2": used}))
+		ret, err = s.GetAccessToken("t2")
+		assert.NoError(t, err)
+		assert.True(t, used.Equal(ret.LastUsedTime))
+
+		assert.NoError(t, s.DeleteAccessToken("t2"))
+		ret, err = s.GetAccessToken("t2")
+		assert.NoError(t, err)
+		assert.Nil(t, ret)
+		total, _, err = s.GetAccessTokens(map[string]string{"principal_id": "u1", "principal_type": "1"}, 0, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(1), total)
+
+		// 吊销的令牌需要同步到缓存中进行删除
+		cached, err := s.GetAccessTokensForCache(start, false)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(cached))
+		for _, token := range cached {
+			assert.Equal(t, token.ID != "t2", token.Valid)
+		}
+	})
+}
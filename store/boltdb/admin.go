@@ -19,10 +19,12 @@ package boltdb
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	bolt "go.etcd.io/bbolt"
 
 	"github.com/polarismesh/polaris/common/eventhub"
 	"github.com/polarismesh/polaris/common/model"
@@ -103,49 +105,22 @@ func (m *adminStore) ReleaseLeaderElection(key string) error {
 
 // BatchCleanDeletedInstances
 func (m *adminStore) BatchCleanDeletedInstances(timeout time.Duration, batchSize uint32) (uint32, error) {
-	mtime := time.Now().Add(-timeout)
-	fields := []string{insFieldValid, insFieldModifyTime}
-	values, err := m.handler.LoadValuesByFilter(tblNameInstance, fields, &model.Instance{},
-		func(m map[string]interface{}) bool {
-			valid, ok := m[insFieldValid]
-			if !ok {
-				return false
-			}
-			if valid.(bool) {
-				return false
-			}
-
-			modifyTime, ok := m[insFieldModifyTime]
-			if !ok {
-				return false
-			}
-			if modifyTime.(time.Time).After(mtime) {
-				return false
-			}
-
-			return true
-		})
+	mtime := m.handler.Now().Add(-timeout)
+	ids, err := m.ListDeletedInstances(mtime, batchSize)
 	if err != nil {
 		return 0, err
 	}
-	if len(values) == 0 {
-		return 0, nil
-	}
+	return m.CleanDeletedInstances(ids, mtime)
+}
 
-	var count uint32 = 0
-	keys := make([]string, 0, batchSize)
-	for k := range values {
-		keys = append(keys, k)
-		count++
-		if count >= batchSize {
-			break
-		}
-	}
-	err = m.handler.DeleteValues(tblNameInstance, keys)
-	if err != nil {
-		return count, err
-	}
-	return count, nil
+// ListDeletedInstances 按照 ID 的顺序获取 mtime 之前被软删除的实例，最多返回 limit 个
+func (m *adminStore) ListDeletedInstances(mtime time.Time, limit uint32) ([]string, error) {
+	return m.listDeleted(tblNameInstance, insFieldValid, insFieldModifyTime, &model.Instance{}, mtime, limit)
+}
+
+// CleanDeletedInstances 清理 ids 中在 mtime 之前被软删除的实例，期间重新注册的实例不会被清理
+func (m *adminStore) CleanDeletedInstances(ids []string, mtime time.Time) (uint32, error) {
+	return m.cleanDeleted(tblNameInstance, insFieldValid, insFieldModifyTime, &model.Instance{}, ids, mtime)
 }
 
 func (m *adminStore) GetUnHealthyInstances(timeout time.Duration, limit uint32) ([]string, error) {
@@ -210,47 +185,87 @@ func (m *adminStore) getUnHealthyInstancesBefore(mtime time.Time, limit uint32)
 
 // BatchCleanDeletedClients
 func (m *adminStore) BatchCleanDeletedClients(timeout time.Duration, batchSize uint32) (uint32, error) {
-	mtime := time.Now().Add(-timeout)
-	fields := []string{ClientFieldValid, ClientFieldMtime}
-	values, err := m.handler.LoadValuesByFilter(tblClient, fields, &model.Client{},
-		func(m map[string]interface{}) bool {
-			valid, ok := m[ClientFieldValid]
-			if !ok {
-				return false
-			}
-			if valid.(bool) {
-				return false
-			}
-
-			modifyTime, ok := m[ClientFieldMtime]
-			if !ok {
-				return false
-			}
-			if modifyTime.(time.Time).After(mtime) {
-				return false
-			}
-
-			return true
-		})
+	mtime := m.handler.Now().Add(-timeout)
+	ids, err := m.ListDeletedClients(mtime, batchSize)
 	if err != nil {
 		return 0, err
 	}
-	if len(values) == 0 {
-		return 0, nil
+	return m.CleanDeletedClients(ids, mtime)
+}
+
+// ListDeletedClients 按照 ID 的顺序获取 mtime 之前被软删除的客户端，最多返回 limit 个
+func (m *adminStore) ListDeletedClients(mtime time.Time, limit uint32) ([]string, error) {
+	return m.listDeleted(tblClient, ClientFieldValid, ClientFieldMtime, &model.Client{}, mtime, limit)
+}
+
+// CleanDeletedClients 清理 ids 中在 mtime 之前被软删除的客户端，期间重新上报的客户端不会被清理
+func (m *adminStore) CleanDeletedClients(ids []string, mtime time.Time) (uint32, error) {
+	return m.cleanDeleted(tblClient, ClientFieldValid, ClientFieldMtime, &model.Client{}, ids, mtime)
+}
+
+// deletedBefore 数据是否在 mtime 之前被软删除
+func deletedBefore(validField, mtimeField string, mtime time.Time) func(map[string]interface{}) bool {
+	return func(m map[string]interface{}) bool {
+		valid, ok := m[validField]
+		if !ok || valid.(bool) {
+			return false
+		}
+		modifyTime, ok := m[mtimeField]
+		if !ok {
+			return false
+		}
+		return !modifyTime.(time.Time).After(mtime)
 	}
+}
 
-	var count uint32 = 0
-	keys := make([]string, 0, batchSize)
+// listDeleted 返回的 key 按照字典序排序，保证多次调用以及不同节点上的结果一致
+func (m *adminStore) listDeleted(typ, validField, mtimeField string, typObject interface{},
+	mtime time.Time, limit uint32) ([]string, error) {
+	values, err := m.handler.LoadValuesByFilter(typ, []string{validField, mtimeField}, typObject,
+		deletedBefore(validField, mtimeField, mtime))
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(values))
 	for k := range values {
 		keys = append(keys, k)
-		count++
-		if count >= batchSize {
-			break
-		}
 	}
-	err = m.handler.DeleteValues(tblClient, keys)
+	sort.Strings(keys)
+	if uint32(len(keys)) > limit {
+		keys = keys[:limit]
+	}
+	return keys, nil
+}
+
+// cleanDeleted 只清理 ids 中仍然处于软删除状态并且在 mtime 之前删除的数据
+func (m *adminStore) cleanDeleted(typ, validField, mtimeField string, typObject interface{},
+	ids []string, mtime time.Time) (uint32, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	var count uint32
+	fields := []string{validField, mtimeField}
+	filter := deletedBefore(validField, mtimeField, mtime)
+	err := m.handler.Execute(true, func(tx *bolt.Tx) error {
+		keys := make([]string, 0, len(ids))
+		for _, id := range ids {
+			bucket := getBucket(tx, typ, id)
+			if bucket == nil {
+				continue
+			}
+			match, err := matchObject(bucket, fields, typObject, filter)
+			if err != nil {
+				return err
+			}
+			if match {
+				keys = append(keys, id)
+			}
+		}
+		count = uint32(len(keys))
+		return deleteValues(tx, typ, keys)
+	})
 	if err != nil {
-		return count, err
+		return 0, err
 	}
 	return count, nil
 }
@@ -0,0 +1,114 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package boltdb
+
+import (
+	"io"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/polarismesh/polaris/store"
+)
+
+// NewBoltStore 创建一个未注册到插件列表中的 boltdb 存储实例，
+// 供需要在本地维护一份完整数据的存储插件（如 raft 复制存储）作为状态机使用
+func NewBoltStore() store.Store {
+	return &boltStore{}
+}
+
+// SetClock 替换写操作使用的时间来源，raft 复制存储使用 leader 提交命令时的时间，保证各节点写入的时间字段一致
+func (m *boltStore) SetClock(clock func() time.Time) {
+	m.handler.SetClock(clock)
+}
+
+// Backup 将当前 boltdb 的完整数据以数据文件的格式写入 w
+func (m *boltStore) Backup(w io.Writer) error {
+	return m.handler.Execute(false, func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
+}
+
+// Restore 使用 r 中的 boltdb 数据文件覆盖当前的全部数据，整个过程在一个写事务中完成，
+// 读操作不会观察到中间状态
+func (m *boltStore) Restore(r io.Reader) error {
+	f, err := os.CreateTemp("", "polaris-restore-*.bolt")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	src, err := bolt.Open(f.Name(), 0600, &bolt.Options{Timeout: defaultTimeoutForFileLock, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+
+	return src.View(func(srcTx *bolt.Tx) error {
+		return m.handler.Execute(true, func(tx *bolt.Tx) error {
+			var names [][]byte
+			if err := tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+				names = append(names, append([]byte(nil), name...))
+				return nil
+			}); err != nil {
+				return err
+			}
+			for _, name := range names {
+				if err := tx.DeleteBucket(name); err != nil {
+					return err
+				}
+			}
+			return srcTx.ForEach(func(name []byte, srcBucket *bolt.Bucket) error {
+				bucket, err := tx.CreateBucket(name)
+				if err != nil {
+					return err
+				}
+				return copyBucket(bucket, srcBucket)
+			})
+		})
+	})
+}
+
+func copyBucket(dst *bolt.Bucket, src *bolt.Bucket) error {
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+	return src.ForEach(func(k, v []byte) error {
+		if v != nil {
+			return dst.Put(k, v)
+		}
+		child, err := dst.CreateBucket(k)
+		if err != nil {
+			return err
+		}
+		return copyBucket(child, src.Bucket(k))
+	})
+}
@@ -0,0 +1,85 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package boltdb
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+func newTestBoltStore(t *testing.T, path string) *boltStore {
+	s := NewBoltStore().(*boltStore)
+	if err := s.Initialize(&store.Config{Option: map[string]interface{}{"path": path}}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = s.Destroy()
+	})
+	return s
+}
+
+func TestBoltStore_BackupRestore(t *testing.T) {
+	dir := t.TempDir()
+	source := newTestBoltStore(t, filepath.Join(dir, "source.bolt"))
+	target := newTestBoltStore(t, filepath.Join(dir, "target.bolt"))
+
+	err := source.AddNamespace(&model.Namespace{
+		Name:       "backup-ns",
+		Token:      "backup-token",
+		Owner:      "polaris",
+		Valid:      true,
+		CreateTime: time.Now(),
+		ModifyTime: time.Now(),
+	})
+	assert.NoError(t, err)
+	err = target.AddNamespace(&model.Namespace{
+		Name:       "target-only-ns",
+		Owner:      "polaris",
+		Valid:      true,
+		CreateTime: time.Now(),
+		ModifyTime: time.Now(),
+	})
+	assert.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, source.Backup(buf))
+	assert.NoError(t, target.Restore(buf))
+
+	ns, err := target.GetNamespace("backup-ns")
+	assert.NoError(t, err)
+	assert.NotNil(t, ns)
+	assert.Equal(t, "backup-token", ns.Token)
+
+	ns, err = target.GetNamespace("target-only-ns")
+	assert.NoError(t, err)
+	assert.Nil(t, ns)
+
+	// 恢复后的数据依旧可以正常写入
+	sid, err := target.GenNextL5Sid(1)
+	assert.NoError(t, err)
+	expectSid, err := source.GenNextL5Sid(1)
+	assert.NoError(t, err)
+	assert.Equal(t, expectSid, sid)
+}
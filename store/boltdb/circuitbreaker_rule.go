@@ -72,10 +72,10 @@ type circuitBreakerStore struct {
 	handler BoltHandler
 }
 
-func initCircuitBreakerRule(cb *model.CircuitBreakerRule) {
+func initCircuitBreakerRule(cb *model.CircuitBreakerRule, tn time.Time) {
 	cb.Valid = true
-	cb.CreateTime = time.Now()
-	cb.ModifyTime = time.Now()
+	cb.CreateTime = tn
+	cb.ModifyTime = tn
 }
 
 // cleanCircuitBreaker 彻底清理熔断规则
@@ -93,7 +93,7 @@ func (c *circuitBreakerStore) cleanCircuitBreakerRule(id string) error {
 func (c *circuitBreakerStore) CreateCircuitBreakerRule(cbRule *model.CircuitBreakerRule) error {
 	dbOp := c.handler
 
-	initCircuitBreakerRule(cbRule)
+	initCircuitBreakerRule(cbRule, c.handler.Now())
 	if err := c.cleanCircuitBreakerRule(cbRule.ID); err != nil {
 		log.Errorf("[Store][circuitBreaker] clean circuit breaker rule(%s) err: %s",
 			cbRule.ID, err.Error())
@@ -116,7 +116,7 @@ func (c *circuitBreakerStore) UpdateCircuitBreakerRule(cbRule *model.CircuitBrea
 		CommonFieldNamespace:   cbRule.Namespace,
 		CommonFieldRevision:    cbRule.Revision,
 		CommonFieldDescription: cbRule.Description,
-		CommonFieldModifyTime:  time.Now(),
+		CommonFieldModifyTime:  c.handler.Now(),
 		CbFieldLevel:           cbRule.Level,
 		CbFieldSrcService:      cbRule.SrcService,
 		CbFieldSrcNamespace:    cbRule.SrcNamespace,
@@ -126,7 +126,7 @@ func (c *circuitBreakerStore) UpdateCircuitBreakerRule(cbRule *model.CircuitBrea
 		CbFieldRule:            cbRule.Rule,
 	}
 	if cbRule.Enable {
-		properties[CommonFieldEnableTime] = time.Now()
+		properties[CommonFieldEnableTime] = c.handler.Now()
 	} else {
 		properties[CommonFieldEnableTime] = time.Unix(0, 0)
 	}
@@ -144,7 +144,7 @@ func (c *circuitBreakerStore) DeleteCircuitBreakerRule(id string) error {
 
 		properties := make(map[string]interface{})
 		properties[CommonFieldValid] = false
-		properties[CommonFieldModifyTime] = time.Now()
+		properties[CommonFieldModifyTime] = c.handler.Now()
 
 		if err := updateValue(tx, tblCircuitBreakerRule, id, properties); err != nil {
 			log.Errorf("[Store][CircuitBreaker] delete rule(%s) err: %s", id, err.Error())
@@ -408,9 +408,9 @@ func (c *circuitBreakerStore) EnableCircuitBreakerRule(cbRule *model.CircuitBrea
 		properties := make(map[string]interface{})
 		properties[CommonFieldEnable] = cbRule.Enable
 		properties[CommonFieldRevision] = cbRule.Revision
-		properties[CommonFieldModifyTime] = time.Now()
+		properties[CommonFieldModifyTime] = c.handler.Now()
 		if cbRule.Enable {
-			properties[CommonFieldEnableTime] = time.Now()
+			properties[CommonFieldEnableTime] = c.handler.Now()
 		} else {
 			properties[CommonFieldEnableTime] = time.Unix(0, 0)
 		}
@@ -51,7 +51,7 @@ func Test_ConvertToClientObject(t *testing.T) {
 		},
 	}
 
-	ret, err := convertToClientObject(model.NewClient(client))
+	ret, err := convertToClientObject(model.NewClient(client), time.Now())
 	assert.NoError(t, err)
 
 	cop, err := convertToModelClient(ret)
@@ -79,7 +79,7 @@ func (fg *configFileGroupStore) CreateConfigFileGroup(
 		}
 		fileGroup.Id = nextId
 		fileGroup.Valid = true
-		fileGroup.CreateTime = time.Now()
+		fileGroup.CreateTime = fg.handler.Now()
 		fileGroup.ModifyTime = fileGroup.CreateTime
 
 		key := fmt.Sprintf("%s@@%s", fileGroup.Namespace, fileGroup.Name)
@@ -170,7 +170,7 @@ func (fg *configFileGroupStore) DeleteConfigFileGroup(namespace, name string) er
 	key := fmt.Sprintf("%s@@%s", namespace, name)
 	properties := make(map[string]interface{})
 	properties[FileGroupFieldValid] = false
-	properties[FileGroupFieldModifyTime] = time.Now()
+	properties[FileGroupFieldModifyTime] = fg.handler.Now()
 
 	if err := fg.handler.UpdateValue(tblConfigFileGroup, key, properties); err != nil {
 		log.Error("[ConfigFileGroup] do delete", zap.Error(err))
@@ -192,7 +192,7 @@ func (fg *configFileGroupStore) UpdateConfigFileGroup(fileGroup *model.ConfigFil
 	properties[FileGroupFieldBusiness] = fileGroup.Business
 	properties[FileGroupFieldDepartment] = fileGroup.Department
 	properties[FileGroupFieldMetadata] = fileGroup.Metadata
-	properties[FileGroupFieldModifyTime] = time.Now()
+	properties[FileGroupFieldModifyTime] = fg.handler.Now()
 
 	if err := fg.handler.UpdateValue(tblConfigFileGroup, key, properties); err != nil {
 		log.Error("[ConfigFileGroup] do update", zap.Error(err))
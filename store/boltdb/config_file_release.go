@@ -70,14 +70,16 @@ func newConfigFileReleaseStore(handler BoltHandler) *configFileReleaseStore {
 func (cfr *configFileReleaseStore) CreateConfigFileReleaseTx(proxyTx store.Tx,
 	fileRelease *model.ConfigFileRelease) error {
 	tx := proxyTx.GetDelegateTx().(*bolt.Tx)
-	// 是否存在当前 release
+	// 是否存在当前 release，已经删除的 release 直接覆盖
 	values := map[string]interface{}{}
 	if err := loadValues(tx, tblConfigFileRelease, []string{fileRelease.ReleaseKey()},
 		&ConfigFileRelease{}, values); err != nil {
 		return err
 	}
-	if len(values) != 0 {
-		return store.NewStatusError(store.DuplicateEntryErr, "exist record")
+	for _, v := range values {
+		if v.(*ConfigFileRelease).Flag != 1 {
+			return store.NewStatusError(store.DuplicateEntryErr, "exist record")
+		}
 	}
 
 	table, err := tx.CreateBucketIfNotExists([]byte(tblConfigFileRelease))
@@ -90,7 +92,7 @@ func (cfr *configFileReleaseStore) CreateConfigFileReleaseTx(proxyTx store.Tx,
 	}
 	fileRelease.Id = nextId
 	fileRelease.Valid = true
-	tN := time.Now()
+	tN := cfr.handler.Now()
 	fileRelease.CreateTime = tN
 	fileRelease.ModifyTime = tN
 
@@ -232,7 +234,7 @@ func (cfr *configFileReleaseStore) DeleteConfigFileReleaseTx(tx store.Tx, data *
 
 	properties[FileReleaseFieldValid] = false
 	properties[FileReleaseFieldFlag] = 1
-	properties[FileReleaseFieldModifyTime] = time.Now()
+	properties[FileReleaseFieldModifyTime] = cfr.handler.Now()
 	if err := updateValue(dbTx, tblConfigFileRelease, data.ReleaseKey(), properties); err != nil {
 		log.Error("[ConfigFileRelease] delete info", zap.Error(err))
 		return store.Error(err)
@@ -240,6 +242,21 @@ func (cfr *configFileReleaseStore) DeleteConfigFileReleaseTx(tx store.Tx, data *
 	return nil
 }
 
+// UpdateConfigFileReleaseMetadataTx update the metadata of the release
+func (cfr *configFileReleaseStore) UpdateConfigFileReleaseMetadataTx(tx store.Tx,
+	release *model.ConfigFileRelease) error {
+	dbTx := tx.GetDelegateTx().(*bolt.Tx)
+	properties := map[string]interface{}{
+		FileReleaseFieldMetadata:   release.Metadata,
+		FileReleaseFieldModifyTime: cfr.handler.Now(),
+	}
+	if err := updateValue(dbTx, tblConfigFileRelease, release.ReleaseKey(), properties); err != nil {
+		log.Error("[ConfigFileRelease] update metadata", zap.Error(err))
+		return store.Error(err)
+	}
+	return nil
+}
+
 // CountConfigReleases count the release data
 func (cfr *configFileReleaseStore) CountConfigReleases(namespace, group string, onlyActive bool) (uint64, error) {
 	fields := []string{FileReleaseFieldNamespace, FileReleaseFieldGroup, FileReleaseFieldValid, FileReleaseFieldActive}
@@ -292,7 +309,7 @@ func (cfr *configFileReleaseStore) CleanConfigFileReleasesTx(tx store.Tx, namesp
 	properties := map[string]interface{}{
 		FileReleaseFieldFlag:       1,
 		FileReleaseFieldValid:      false,
-		FileReleaseFieldModifyTime: time.Now(),
+		FileReleaseFieldModifyTime: cfr.handler.Now(),
 	}
 	for key := range values {
 		if err := updateValue(dbTx, tblConfigFileRelease, key, properties); err != nil {
@@ -338,7 +355,7 @@ func (cfr *configFileReleaseStore) ActiveConfigFileReleaseTx(tx store.Tx, releas
 	properties := make(map[string]interface{})
 	properties[FileReleaseFieldVersion] = maxVersion + 1
 	properties[FileReleaseFieldActive] = true
-	properties[FileReleaseFieldModifyTime] = time.Now()
+	properties[FileReleaseFieldModifyTime] = cfr.handler.Now()
 	return updateValue(dbTx, tblConfigFileRelease, release.ReleaseKey(), properties)
 }
 
@@ -346,7 +363,7 @@ func (cfr *configFileReleaseStore) InactiveConfigFileReleaseTx(tx store.Tx, rele
 	dbTx := tx.GetDelegateTx().(*bolt.Tx)
 	properties := make(map[string]interface{})
 	properties[FileReleaseFieldActive] = false
-	properties[FileReleaseFieldModifyTime] = time.Now()
+	properties[FileReleaseFieldModifyTime] = cfr.handler.Now()
 	return updateValue(dbTx, tblConfigFileRelease, release.ReleaseKey(), properties)
 }
 
@@ -392,7 +409,7 @@ func (cfr *configFileReleaseStore) inactiveConfigFileRelease(tx *bolt.Tx,
 	}
 	properties := map[string]interface{}{
 		FileReleaseFieldActive:     false,
-		FileReleaseFieldModifyTime: time.Now(),
+		FileReleaseFieldModifyTime: cfr.handler.Now(),
 	}
 	for key := range values {
 		if err := updateValue(tx, tblConfigFileRelease, key, properties); err != nil {
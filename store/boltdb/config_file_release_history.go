@@ -50,6 +50,7 @@ const (
 	FileHistoryFieldCreateTime string = "CreateTime"
 	FileHistoryFieldModifyTime string = "ModifyTime"
 	FileHistoryFieldValid      string = "Valid"
+	FileHistoryFieldMetadata   string = "Metadata"
 )
 
 type configFileReleaseHistoryStore struct {
@@ -78,7 +79,7 @@ func (rh *configFileReleaseHistoryStore) CreateConfigFileReleaseHistory(
 		history.Id = nextId
 		key := strconv.FormatUint(history.Id, 10)
 		history.Valid = true
-		history.CreateTime = time.Now()
+		history.CreateTime = rh.handler.Now()
 		history.ModifyTime = history.CreateTime
 
 		if err := saveValue(tx, tblConfigFileReleaseHistory, key, history); err != nil {
@@ -190,6 +191,16 @@ func (rh *configFileReleaseHistoryStore) CleanConfigFileReleaseHistory(endTime t
 	return rh.handler.DeleteValues(tblConfigFileReleaseHistory, needDel)
 }
 
+// UpdateConfigFileReleaseHistoryMetadata 更新配置发布历史的标签
+func (rh *configFileReleaseHistoryStore) UpdateConfigFileReleaseHistoryMetadata(
+	history *model.ConfigFileReleaseHistory) error {
+	properties := map[string]interface{}{
+		FileHistoryFieldMetadata: history.Metadata,
+	}
+	err := rh.handler.UpdateValue(tblConfigFileReleaseHistory, strconv.FormatUint(history.Id, 10), properties)
+	return store.Error(err)
+}
+
 // doConfigFileGroupPage 进行分页
 func doConfigFileHistoryPage(ret map[string]interface{}, offset, limit uint32) []*model.ConfigFileReleaseHistory {
 	var (
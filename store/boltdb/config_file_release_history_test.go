@@ -97,4 +97,22 @@ func Test_configFileReleaseHistoryStore(t *testing.T) {
 			assert.Equal(t, total, len(idMap))
 		})
 	})
+
+	t.Run("更新配置发布历史的标签", func(t *testing.T) {
+		CreateTableDBHandlerAndRun(t, tblConfigFileReleaseHistory, func(t *testing.T, handler BoltHandler) {
+			store := newConfigFileReleaseHistoryStore(handler)
+			history := mockConfigFileHistory(1, "")[0]
+			assert.NoError(t, store.CreateConfigFileReleaseHistory(history))
+
+			history.Metadata = map[string]string{
+				model.MetaKeyConfigFileDataKey: "v2:key",
+			}
+			assert.NoError(t, store.UpdateConfigFileReleaseHistoryMetadata(history))
+
+			val, err := store.GetLatestConfigFileReleaseHistory(history.Namespace, history.Group, history.FileName)
+			assert.NoError(t, err)
+			assert.Equal(t, "v2:key", val.GetEncryptDataKey())
+			assert.Equal(t, history.Content, val.Content)
+		})
+	})
 }
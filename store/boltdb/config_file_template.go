@@ -18,8 +18,6 @@
 package boltdb
 
 import (
-	"time"
-
 	bolt "go.etcd.io/bbolt"
 	"go.uber.org/zap"
 
@@ -115,8 +113,8 @@ func (cf *configFileTemplateStore) CreateConfigFileTemplate(
 	}
 
 	template.Id = nextId
-	template.CreateTime = time.Now()
-	template.ModifyTime = time.Now()
+	template.CreateTime = cf.handler.Now()
+	template.ModifyTime = cf.handler.Now()
 
 	key := template.Name
 	if err := saveValue(tx, tblConfigFileTemplate, key, template); err != nil {
@@ -82,11 +82,13 @@ type boltStore struct {
 
 	// adminStore store
 	*adminStore
+	*operationRecordStore
 	// 工具
 	*toolStore
 	// 鉴权模块相关
 	*userStore
 	*groupStore
+	*accessTokenStore
 	*strategyStore
 	*grayStore
 
@@ -234,7 +236,7 @@ var (
 
 func (m *boltStore) initNamingStoreData() error {
 	for _, namespace := range namespacesToInit {
-		curTime := time.Now()
+		curTime := m.handler.Now()
 		err := m.AddNamespace(&model.Namespace{
 			Name:       namespace,
 			Token:      utils.NewUUID(),
@@ -248,7 +250,7 @@ func (m *boltStore) initNamingStoreData() error {
 		}
 	}
 	for svc, id := range servicesToInit {
-		curTime := time.Now()
+		curTime := m.handler.Now()
 		err := m.AddService(&model.Service{
 			ID:         id,
 			Name:       svc,
@@ -336,6 +338,7 @@ func (m *boltStore) newAuthModuleStore() {
 	m.userStore = &userStore{handler: m.handler}
 	m.strategyStore = &strategyStore{handler: m.handler}
 	m.groupStore = &groupStore{handler: m.handler}
+	m.accessTokenStore = &accessTokenStore{handler: m.handler}
 }
 
 func (m *boltStore) newConfigModuleStore() {
@@ -348,6 +351,7 @@ func (m *boltStore) newConfigModuleStore() {
 
 func (m *boltStore) newMaintainModuleStore() {
 	m.adminStore = &adminStore{handler: m.handler, leMap: make(map[string]bool)}
+	m.operationRecordStore = &operationRecordStore{handler: m.handler}
 }
 
 // Destroy store
@@ -369,8 +373,9 @@ func (m *boltStore) StartTx() (store.Tx, error) {
 	return m.handler.StartTx()
 }
 
+// StartReadTx starting read only transactions
 func (m *boltStore) StartReadTx() (store.Tx, error) {
-	return m.handler.StartTx()
+	return m.handler.StartReadTx()
 }
 
 func init() {
@@ -37,10 +37,10 @@ const (
 	tblFaultDetectRule string = "faultdetect_rule"
 )
 
-func initFaultDetectRule(cb *model.FaultDetectRule) {
+func initFaultDetectRule(cb *model.FaultDetectRule, tn time.Time) {
 	cb.Valid = true
-	cb.CreateTime = time.Now()
-	cb.ModifyTime = time.Now()
+	cb.CreateTime = tn
+	cb.ModifyTime = tn
 }
 
 // cleanCircuitBreaker 彻底清理熔断规则
@@ -58,7 +58,7 @@ func (c *faultDetectStore) cleanFaultDetectRule(id string) error {
 func (c *faultDetectStore) CreateFaultDetectRule(fdRule *model.FaultDetectRule) error {
 	dbOp := c.handler
 
-	initFaultDetectRule(fdRule)
+	initFaultDetectRule(fdRule, c.handler.Now())
 	if err := c.cleanFaultDetectRule(fdRule.ID); err != nil {
 		log.Errorf("[Store][fault-detect] clean fault-detect rule(%s) err: %s",
 			fdRule.ID, err.Error())
@@ -77,7 +77,7 @@ func (c *faultDetectStore) CreateFaultDetectRule(fdRule *model.FaultDetectRule)
 func (c *faultDetectStore) UpdateFaultDetectRule(fdRule *model.FaultDetectRule) error {
 	dbOp := c.handler
 	fdRule.Valid = true
-	fdRule.ModifyTime = time.Now()
+	fdRule.ModifyTime = c.handler.Now()
 
 	if err := dbOp.SaveValue(tblFaultDetectRule, fdRule.ID, fdRule); err != nil {
 		log.Errorf("[Store][fault-detect] update rule(%s) exec err: %s", fdRule.ID, err.Error())
@@ -94,7 +94,7 @@ func (c *faultDetectStore) DeleteFaultDetectRule(id string) error {
 
 		properties := make(map[string]interface{})
 		properties[CommonFieldValid] = false
-		properties[CommonFieldModifyTime] = time.Now()
+		properties[CommonFieldModifyTime] = c.handler.Now()
 
 		if err := updateValue(tx, tblFaultDetectRule, id, properties); err != nil {
 			log.Errorf("[Store][fault-detect] delete rule(%s) err: %s", id, err.Error())
@@ -47,7 +47,7 @@ func newGrayStore(handler BoltHandler) *configFileReleaseStore {
 func (cfr *grayStore) CreateGrayResourceTx(proxyTx store.Tx, grayResource *model.GrayResource) error {
 	tx := proxyTx.GetDelegateTx().(*bolt.Tx)
 
-	tN := time.Now()
+	tN := cfr.handler.Now()
 	grayResource.CreateTime = tN
 	grayResource.ModifyTime = tN
 
@@ -63,7 +63,7 @@ func (cfr *grayStore) CleanGrayResource(proxyTx store.Tx, data *model.GrayResour
 	tx := proxyTx.GetDelegateTx().(*bolt.Tx)
 
 	properties := map[string]interface{}{
-		GrayResourceFieldModifyTime: time.Now(),
+		GrayResourceFieldModifyTime: cfr.handler.Now(),
 		CommonFieldValid:            false,
 	}
 
@@ -102,7 +102,7 @@ func (gs *groupStore) AddGroup(group *model.UserGroupDetail) error {
 func (gs *groupStore) addGroup(tx *bolt.Tx, group *model.UserGroupDetail) error {
 
 	group.Valid = true
-	group.CreateTime = time.Now()
+	group.CreateTime = gs.handler.Now()
 	group.ModifyTime = group.CreateTime
 
 	data := convertForGroupStore(group)
@@ -175,7 +175,7 @@ func (gs *groupStore) updateGroup(group *model.ModifyUserGroup) error {
 	ret.Comment = group.Comment
 	ret.Token = group.Token
 	ret.TokenEnable = group.TokenEnable
-	ret.ModifyTime = time.Now()
+	ret.ModifyTime = gs.handler.Now()
 
 	updateGroupRelation(ret, group)
 
@@ -226,14 +226,14 @@ func (gs *groupStore) deleteGroup(group *model.UserGroupDetail) error {
 
 	properties := make(map[string]interface{})
 	properties[GroupFieldValid] = false
-	properties[GroupFieldModifyTime] = time.Now()
+	properties[GroupFieldModifyTime] = gs.handler.Now()
 
 	if err := updateValue(tx, tblGroup, group.ID, properties); err != nil {
 		log.Error("[Store][Group] remove usergroup", zap.Error(err), zap.String("id", group.ID))
 		return err
 	}
 
-	if err := cleanLinkStrategy(tx, model.PrincipalGroup, group.ID, group.Owner); err != nil {
+	if err := cleanLinkStrategy(tx, model.PrincipalGroup, group.ID, group.Owner, gs.handler.Now()); err != nil {
 		log.Error("[Store][Group] clean usergroup default strategy",
 			zap.Error(err), zap.String("id", group.ID))
 		return err
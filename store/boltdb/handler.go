@@ -76,8 +76,17 @@ type BoltHandler interface {
 	// StartTx start new tx
 	StartTx() (store.Tx, error)
 
+	// StartReadTx start new read only tx
+	StartReadTx() (store.Tx, error)
+
 	// Close boltdb
 	Close() error
+
+	// Now the time used by write operations, such as CreateTime and ModifyTime
+	Now() time.Time
+
+	// SetClock replace the time source of write operations
+	SetClock(clock func() time.Time)
 }
 
 // BoltConfig config to initialize boltdb
@@ -114,7 +123,21 @@ func NewBoltHandler(config *BoltConfig) (BoltHandler, error) {
 }
 
 type boltHandler struct {
-	db *bolt.DB
+	db    *bolt.DB
+	clock func() time.Time
+}
+
+// Now the time used by write operations
+func (b *boltHandler) Now() time.Time {
+	if b.clock == nil {
+		return time.Now()
+	}
+	return b.clock()
+}
+
+// SetClock replace the time source of write operations
+func (b *boltHandler) SetClock(clock func() time.Time) {
+	b.clock = clock
 }
 
 func openBoltDB(path string) (*bolt.DB, error) {
@@ -612,3 +635,12 @@ func (b *boltHandler) StartTx() (store.Tx, error) {
 	}
 	return NewBoltTx(tx), nil
 }
+
+// StartReadTx start a new read only tx
+func (b *boltHandler) StartReadTx() (store.Tx, error) {
+	tx, err := b.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	return NewBoltTx(tx), nil
+}
@@ -69,8 +69,8 @@ func (n *namespaceStore) InitData() error {
 				Token:      namespaceToToken[namespace],
 				Owner:      "polaris",
 				Valid:      true,
-				CreateTime: time.Now(),
-				ModifyTime: time.Now(),
+				CreateTime: n.handler.Now(),
+				ModifyTime: n.handler.Now(),
 			})
 			if err != nil {
 				return err
@@ -91,7 +91,7 @@ func (n *namespaceStore) AddNamespace(namespace *model.Namespace) error {
 		return err
 	}
 
-	tn := time.Now()
+	tn := n.handler.Now()
 
 	namespace.CreateTime = tn
 	namespace.ModifyTime = tn
@@ -116,7 +116,7 @@ func (n *namespaceStore) UpdateNamespace(namespace *model.Namespace) error {
 	properties := make(map[string]interface{})
 	properties["Owner"] = namespace.Owner
 	properties["Comment"] = namespace.Comment
-	properties["ModifyTime"] = time.Now()
+	properties["ModifyTime"] = n.handler.Now()
 	properties["ServiceExportTo"] = utils.MustJson(namespace.ServiceExportTo)
 	return n.handler.UpdateValue(tblNameNamespace, namespace.Name, properties)
 }
@@ -129,7 +129,7 @@ func (n *namespaceStore) UpdateNamespaceToken(name string, token string) error {
 	}
 	properties := make(map[string]interface{})
 	properties["Token"] = token
-	properties["ModifyTime"] = time.Now()
+	properties["ModifyTime"] = n.handler.Now()
 	return n.handler.UpdateValue(tblNameNamespace, name, properties)
 }
 
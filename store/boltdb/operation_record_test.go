@@ -0,0 +1,80 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package boltdb
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+func Test_operationRecordStore(t *testing.T) {
+	CreateTableDBHandlerAndRun(t, tblOperationRecord, func(t *testing.T, handler BoltHandler) {
+		s := &operationRecordStore{handler: handler}
+		now := time.Now().Truncate(time.Second)
+		records := []*model.RecordEntry{
+			{ResourceType: model.RRouting, ResourceName: "rule-1", Namespace: "default", Operator: "alice",
+				OperationType: model.OCreate, HappenTime: now.Add(-48 * time.Hour)},
+			{ResourceType: model.RRouting, ResourceName: "rule-1", Namespace: "default", Operator: "bob",
+				OperationType: model.OUpdate, HappenTime: now.Add(-time.Hour)},
+			{ResourceType: model.RService, ResourceName: "svc", Namespace: "Test", Operator: "bob",
+				OperationType: model.ODelete, HappenTime: now},
+		}
+		assert.NoError(t, s.AddOperationRecords(records))
+		assert.NotZero(t, records[0].ID)
+
+		total, ret, err := s.GetOperationRecords(map[string]string{}, 0, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(3), total)
+		// order by happen time desc
+		assert.Equal(t, model.ODelete, ret[0].OperationType)
+		assert.Equal(t, model.OCreate, ret[2].OperationType)
+
+		total, ret, err = s.GetOperationRecords(map[string]string{
+			"resource_type": string(model.RRouting),
+			"resource_name": "rule-1",
+			"operator":      "bob",
+		}, 0, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(1), total)
+		assert.Equal(t, model.OUpdate, ret[0].OperationType)
+
+		total, _, err = s.GetOperationRecords(map[string]string{
+			"start_time": strconv.FormatInt(now.Add(-2*time.Hour).Unix(), 10),
+			"end_time":   strconv.FormatInt(now.Add(-time.Minute).Unix(), 10),
+		}, 0, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(1), total)
+
+		total, ret, err = s.GetOperationRecords(map[string]string{}, 2, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(3), total)
+		assert.Equal(t, 1, len(ret))
+
+		cleaned, err := s.CleanOperationRecords(now.Add(-24*time.Hour), 100)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), cleaned)
+		total, _, err = s.GetOperationRecords(map[string]string{}, 0, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(2), total)
+	})
+}
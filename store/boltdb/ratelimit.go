@@ -230,7 +230,7 @@ func (r *rateLimitStore) GetRateLimitsForCache(mtime time.Time,
 //	@return error
 func (r *rateLimitStore) createRateLimit(limit *model.RateLimit) error {
 	handler := r.handler
-	tNow := time.Now()
+	tNow := r.handler.Now()
 	limit.CreateTime = tNow
 	limit.ModifyTime = tNow
 	if !limit.Disable {
@@ -261,11 +261,11 @@ func (r *rateLimitStore) enableRateLimit(limit *model.RateLimit) error {
 		properties := make(map[string]interface{})
 		properties[RateLimitFieldDisable] = limit.Disable
 		properties[RateLimitFieldRevision] = limit.Revision
-		properties[RateLimitFieldModifyTime] = time.Now()
+		properties[RateLimitFieldModifyTime] = r.handler.Now()
 		if limit.Disable {
 			properties[RateLimitFieldEnableTime] = time.Unix(0, 0)
 		} else {
-			properties[RateLimitFieldEnableTime] = time.Now()
+			properties[RateLimitFieldEnableTime] = r.handler.Now()
 		}
 		// create ratelimit_config
 		if err := updateValue(tx, tblRateLimitConfig, limit.ID, properties); err != nil {
@@ -294,11 +294,11 @@ func (r *rateLimitStore) updateRateLimit(limit *model.RateLimit) error {
 		properties[RateLimitFieldPriority] = limit.Priority
 		properties[RateLimitFieldRule] = limit.Rule
 		properties[RateLimitFieldRevision] = limit.Revision
-		properties[RateLimitFieldModifyTime] = time.Now()
+		properties[RateLimitFieldModifyTime] = r.handler.Now()
 		if limit.Disable {
 			properties[RateLimitFieldEnableTime] = time.Unix(0, 0)
 		} else {
-			properties[RateLimitFieldEnableTime] = time.Now()
+			properties[RateLimitFieldEnableTime] = r.handler.Now()
 		}
 		// create ratelimit_config
 		if err := updateValue(tx, tblRateLimitConfig, limit.ID, properties); err != nil {
@@ -322,7 +322,7 @@ func (r *rateLimitStore) deleteRateLimit(limit *model.RateLimit) error {
 
 		properties := make(map[string]interface{})
 		properties[RateLimitFieldValid] = false
-		properties[RateLimitFieldModifyTime] = time.Now()
+		properties[RateLimitFieldModifyTime] = r.handler.Now()
 
 		if err := updateValue(tx, tblRateLimitConfig, limit.ID, properties); err != nil {
 			log.Errorf("[Store][RateLimit] delete rate_limit(%s, %s) err: %s",
@@ -60,7 +60,7 @@ func (r *routingStore) CreateRoutingConfig(conf *model.RoutingConfig) error {
 		return err
 	}
 
-	initRouting(conf)
+	initRouting(conf, r.handler.Now())
 
 	err := r.handler.SaveValue(tblNameRouting, conf.ID, conf)
 	if err != nil {
@@ -97,7 +97,7 @@ func (r *routingStore) UpdateRoutingConfig(conf *model.RoutingConfig) error {
 	properties[routingFieldInBounds] = conf.InBounds
 	properties[routingFieldOutBounds] = conf.OutBounds
 	properties[routingFieldRevision] = conf.Revision
-	properties[routingFieldModifyTime] = time.Now()
+	properties[routingFieldModifyTime] = r.handler.Now()
 
 	err := r.handler.UpdateValue(tblNameRouting, conf.ID, properties)
 	if err != nil {
@@ -116,7 +116,7 @@ func (r *routingStore) DeleteRoutingConfig(serviceID string) error {
 
 	properties := make(map[string]interface{})
 	properties[routingFieldValid] = false
-	properties[routingFieldModifyTime] = time.Now()
+	properties[routingFieldModifyTime] = r.handler.Now()
 
 	err := r.handler.UpdateValue(tblNameRouting, serviceID, properties)
 	if err != nil {
@@ -139,7 +139,7 @@ func (r *routingStore) DeleteRoutingConfigTx(tx store.Tx, serviceID string) erro
 
 	properties := make(map[string]interface{})
 	properties[routingFieldValid] = false
-	properties[routingFieldModifyTime] = time.Now()
+	properties[routingFieldModifyTime] = r.handler.Now()
 
 	boltTx := tx.GetDelegateTx().(*bolt.Tx)
 	err := updateValue(boltTx, tblNameRouting, serviceID, properties)
@@ -380,8 +380,7 @@ func getRealRouteConfList(routeConf []*model.ExtendRoutingConfig, offset, limit
 	return routeConf[beginIndex:endIndex]
 }
 
-func initRouting(r *model.RoutingConfig) {
-	currTime := time.Now()
+func initRouting(r *model.RoutingConfig, currTime time.Time) {
 	r.CreateTime = currTime
 	r.ModifyTime = currTime
 	r.Valid = true
@@ -51,7 +51,7 @@ type serviceContractStore struct {
 
 // CreateServiceContract 创建服务契约
 func (s *serviceContractStore) CreateServiceContract(contract *model.ServiceContract) error {
-	tn := time.Now()
+	tn := s.handler.Now()
 	contract.Valid = true
 	contract.CreateTime = tn
 	contract.ModifyTime = tn
@@ -69,7 +69,7 @@ func (s *serviceContractStore) UpdateServiceContract(contract *model.ServiceCont
 	properties := map[string]interface{}{
 		ContractFieldRevision:   contract.Revision,
 		ContractFieldContent:    contract.Content,
-		ContractFieldModifyTime: time.Now(),
+		ContractFieldModifyTime: s.handler.Now(),
 	}
 
 	if err := s.handler.UpdateValue(tblServiceContract, contract.ID, properties); err != nil {
@@ -82,7 +82,7 @@ func (s *serviceContractStore) UpdateServiceContract(contract *model.ServiceCont
 func (s *serviceContractStore) DeleteServiceContract(contract *model.ServiceContract) error {
 	properties := map[string]interface{}{
 		ContractFieldValid:      false,
-		ContractFieldModifyTime: time.Now(),
+		ContractFieldModifyTime: s.handler.Now(),
 	}
 
 	if err := s.handler.UpdateValue(tblServiceContract, contract.ID, properties); err != nil {
@@ -146,7 +146,7 @@ func (s *serviceContractStore) AddServiceContractInterfaces(contract *model.Enri
 		if len(values) == 0 {
 			return store.NewStatusError(store.NotFoundResource, "not found target service_contract")
 		}
-		tN := time.Now()
+		tN := s.handler.Now()
 		for i := range contract.Interfaces {
 			contract.Interfaces[i].CreateTime = tN
 			contract.Interfaces[i].ModifyTime = tN
@@ -156,7 +156,7 @@ func (s *serviceContractStore) AddServiceContractInterfaces(contract *model.Enri
 		enrichRecord := s.toModel(record)
 		enrichRecord.Interfaces = contract.Interfaces
 		enrichRecord.Revision = contract.Revision
-		enrichRecord.ModifyTime = time.Now()
+		enrichRecord.ModifyTime = s.handler.Now()
 
 		return saveValue(tx, tblServiceContract, contract.ID, s.toStore(enrichRecord))
 	})
@@ -180,7 +180,7 @@ func (s *serviceContractStore) AppendServiceContractInterfaces(contract *model.E
 		for i := range enrichRecord.Interfaces {
 			interfaceMap[enrichRecord.Interfaces[i].ID] = enrichRecord.Interfaces[i]
 		}
-		tN := time.Now()
+		tN := s.handler.Now()
 		for i := range contract.Interfaces {
 			contract.Interfaces[i].ModifyTime = tN
 			contract.Interfaces[i].CreateTime = tN
@@ -193,7 +193,7 @@ func (s *serviceContractStore) AppendServiceContractInterfaces(contract *model.E
 
 		enrichRecord.Interfaces = interfaceSlice
 		enrichRecord.Revision = contract.Revision
-		enrichRecord.ModifyTime = time.Now()
+		enrichRecord.ModifyTime = s.handler.Now()
 
 		return saveValue(tx, tblServiceContract, contract.ID, s.toStore(enrichRecord))
 	})
@@ -227,7 +227,7 @@ func (s *serviceContractStore) DeleteServiceContractInterfaces(contract *model.E
 
 		enrichRecord.Interfaces = interfaceSlice
 		enrichRecord.Revision = contract.Revision
-		enrichRecord.ModifyTime = time.Now()
+		enrichRecord.ModifyTime = s.handler.Now()
 
 		return saveValue(tx, tblServiceContract, contract.ID, s.toStore(enrichRecord))
 	})
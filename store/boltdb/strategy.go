@@ -89,7 +89,7 @@ func (ss *strategyStore) AddStrategy(strategy *model.StrategyDetail) error {
 			strategy.ID, strategy.Name, strategy.Owner))
 	}
 
-	initStrategy(strategy)
+	initStrategy(strategy, ss.handler.Now())
 
 	proxy, err := ss.handler.StartTx()
 	if err != nil {
@@ -168,7 +168,7 @@ func (ss *strategyStore) updateStrategy(tx *bolt.Tx, modify *model.ModifyStrateg
 	computeResources(false, modify.AddResources, saveVal)
 	computeResources(true, modify.RemoveResources, saveVal)
 
-	saveVal.ModifyTime = time.Now()
+	saveVal.ModifyTime = ss.handler.Now()
 
 	if err := saveValue(tx, tblStrategy, saveVal.ID, saveVal); err != nil {
 		log.Error("[Store][Strategy] update auth_strategy", zap.Error(err),
@@ -243,7 +243,7 @@ func (ss *strategyStore) DeleteStrategy(id string) error {
 
 	properties := make(map[string]interface{})
 	properties[StrategyFieldValid] = false
-	properties[StrategyFieldModifyTime] = time.Now()
+	properties[StrategyFieldModifyTime] = ss.handler.Now()
 
 	if err := ss.handler.UpdateValue(tblStrategy, id, properties); err != nil {
 		log.Error("[Store][Strategy] delete auth_strategy", zap.Error(err), zap.String("id", id))
@@ -285,7 +285,7 @@ func (ss *strategyStore) operateStrategyResources(remove bool, resources []model
 		}
 
 		computeResources(remove, ress, rule)
-		rule.ModifyTime = time.Now()
+		rule.ModifyTime = ss.handler.Now()
 		if err := saveValue(tx, tblStrategy, rule.ID, rule); err != nil {
 			log.Error("[Store][Strategy] operate strategy resource", zap.Error(err),
 				zap.Bool("remove", remove), zap.String("id", id))
@@ -758,7 +758,7 @@ func createDefaultStrategy(tx *bolt.Tx, role model.PrincipalType, principalId, n
 	return saveValue(tx, tblStrategy, strategy.ID, convertForStrategyStore(strategy))
 }
 
-func cleanLinkStrategy(tx *bolt.Tx, role model.PrincipalType, principalId, owner string) error {
+func cleanLinkStrategy(tx *bolt.Tx, role model.PrincipalType, principalId, owner string, tn time.Time) error {
 
 	fields := []string{StrategyFieldDefault, StrategyFieldUsersPrincipal, StrategyFieldGroupsPrincipal}
 	values := make(map[string]interface{})
@@ -801,7 +801,7 @@ func cleanLinkStrategy(tx *bolt.Tx, role model.PrincipalType, principalId, owner
 
 		properties := make(map[string]interface{})
 		properties[StrategyFieldValid] = false
-		properties[StrategyFieldModifyTime] = time.Now()
+		properties[StrategyFieldModifyTime] = tn
 
 		if err := updateValue(tx, tblStrategy, k, properties); err != nil {
 			log.Error("[Store][Strategy] clean link auth_strategy", zap.Error(err),
@@ -922,11 +922,10 @@ func convertForStrategyDetail(strategy *strategyForStore) *model.StrategyDetail
 	}
 }
 
-func initStrategy(rule *model.StrategyDetail) {
+func initStrategy(rule *model.StrategyDetail, tn time.Time) {
 	if rule != nil {
 		rule.Valid = true
 
-		tn := time.Now()
 		rule.CreateTime = tn
 		rule.ModifyTime = tn
 
@@ -78,7 +78,7 @@ type userStore struct {
 // AddUser 添加用户
 func (us *userStore) AddUser(user *model.User) error {
 
-	initUser(user)
+	initUser(user, us.handler.Now())
 
 	if user.ID == "" || user.Name == "" || user.Source == "" ||
 		user.Owner == "" || user.Token == "" {
@@ -146,7 +146,7 @@ func (us *userStore) UpdateUser(user *model.User) error {
 	properties[UserFieldEmail] = user.Email
 	properties[UserFieldMobile] = user.Mobile
 	properties[UserFieldPassword] = user.Password
-	properties[UserFieldModifyTime] = time.Now()
+	properties[UserFieldModifyTime] = us.handler.Now()
 
 	err := us.handler.UpdateValue(tblUser, user.ID, properties)
 	if err != nil {
@@ -179,7 +179,7 @@ func (us *userStore) deleteUser(user *model.User) error {
 
 	properties := make(map[string]interface{})
 	properties[UserFieldValid] = false
-	properties[UserFieldModifyTime] = time.Now()
+	properties[UserFieldModifyTime] = us.handler.Now()
 
 	if err := updateValue(tx, tblUser, user.ID, properties); err != nil {
 		log.Error("[Store][User] delete user by id", zap.Error(err), zap.String("id", user.ID))
@@ -191,7 +191,7 @@ func (us *userStore) deleteUser(user *model.User) error {
 		owner = user.ID
 	}
 
-	if err := cleanLinkStrategy(tx, model.PrincipalUser, user.ID, user.Owner); err != nil {
+	if err := cleanLinkStrategy(tx, model.PrincipalUser, user.ID, user.Owner, us.handler.Now()); err != nil {
 		return err
 	}
 
@@ -576,9 +576,8 @@ func converToUserModel(user *userForStore) *model.User {
 	}
 }
 
-func initUser(user *model.User) {
+func initUser(user *model.User, tn time.Time) {
 	if user != nil {
-		tn := time.Now()
 		user.Valid = true
 		user.CreateTime = tn
 		user.ModifyTime = tn
@@ -0,0 +1,508 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package store
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+// ChangeNotifier 存储层变更通知，写入数据的节点通过它发布变更的资源，缓存订阅后做定向的增量刷新
+type ChangeNotifier interface {
+	// Publish 发布变更事件
+	Publish(events []*model.ChangeEvent)
+	// Subscribe 订阅变更事件，包括本节点以及其他节点发布的事件
+	Subscribe(handler func(events []*model.ChangeEvent))
+}
+
+var (
+	changeNotifier  ChangeNotifier
+	notifyStoreOnce sync.Once
+	notifyStore     Store
+)
+
+// SetChangeNotifier 设置存储层的变更通知，需要在第一次 GetStore 之前设置
+func SetChangeNotifier(notifier ChangeNotifier) {
+	changeNotifier = notifier
+}
+
+// GetChangeNotifier 获取存储层的变更通知，未开启时返回 nil
+func GetChangeNotifier() ChangeNotifier {
+	return changeNotifier
+}
+
+// wrapNotifyStore 为存储层增加变更通知，所有通过 GetStore 获取存储的模块共享同一个对象
+func wrapNotifyStore(s Store) Store {
+	notifyStoreOnce.Do(func() {
+		notifyStore = NewNotifyStore(s, changeNotifier)
+	})
+	return notifyStore
+}
+
+// NewNotifyStore 创建一个在写操作成功之后发布变更事件的 Store，未覆盖的写操作依赖缓存的兜底轮询
+func NewNotifyStore(s Store, notifier ChangeNotifier) Store {
+	return &changeNotifyStore{Store: s, notifier: notifier}
+}
+
+type changeNotifyStore struct {
+	Store
+	notifier ChangeNotifier
+}
+
+// publish 写操作成功之后发布变更事件，带事务的写操作在事务提交之后再发布
+func (s *changeNotifyStore) publish(tx Tx, err error, events ...*model.ChangeEvent) error {
+	if err != nil || len(events) == 0 {
+		return err
+	}
+	if ntx, ok := tx.(*changeNotifyTx); ok {
+		ntx.append(events)
+		return nil
+	}
+	s.notifier.Publish(events)
+	return nil
+}
+
+func changeEvent(resource model.ChangeResource, id, revision string) *model.ChangeEvent {
+	return &model.ChangeEvent{Resource: resource, ID: id, Revision: revision}
+}
+
+func deleteEvent(resource model.ChangeResource, id string) *model.ChangeEvent {
+	return &model.ChangeEvent{Resource: resource, ID: id, Deleted: true}
+}
+
+func instanceEvents(ids []interface{}, revision string) []*model.ChangeEvent {
+	events := make([]*model.ChangeEvent, 0, len(ids))
+	for _, id := range ids {
+		if v, ok := id.(string); ok {
+			events = append(events, changeEvent(model.ChangeInstance, v, revision))
+		}
+	}
+	return events
+}
+
+// StartTx 开启的事务在提交成功之后发布事务中产生的变更事件
+func (s *changeNotifyStore) StartTx() (Tx, error) {
+	tx, err := s.Store.StartTx()
+	if err != nil {
+		return nil, err
+	}
+	return &changeNotifyTx{Tx: tx, notifier: s.notifier}, nil
+}
+
+type changeNotifyTx struct {
+	Tx
+	notifier ChangeNotifier
+	lock     sync.Mutex
+	events   []*model.ChangeEvent
+}
+
+func (tx *changeNotifyTx) append(events []*model.ChangeEvent) {
+	tx.lock.Lock()
+	defer tx.lock.Unlock()
+	tx.events = append(tx.events, events...)
+}
+
+// Commit 事务提交成功之后发布变更事件
+func (tx *changeNotifyTx) Commit() error {
+	if err := tx.Tx.Commit(); err != nil {
+		return err
+	}
+	tx.lock.Lock()
+	events := tx.events
+	tx.events = nil
+	tx.lock.Unlock()
+	if len(events) != 0 {
+		tx.notifier.Publish(events)
+	}
+	return nil
+}
+
+// Rollback 事务回滚时丢弃变更事件
+func (tx *changeNotifyTx) Rollback() error {
+	tx.lock.Lock()
+	tx.events = nil
+	tx.lock.Unlock()
+	return tx.Tx.Rollback()
+}
+
+// AddService 保存一个服务
+func (s *changeNotifyStore) AddService(service *model.Service) error {
+	return s.publish(nil, s.Store.AddService(service), changeEvent(model.ChangeService, service.ID, service.Revision))
+}
+
+// DeleteService 删除服务
+func (s *changeNotifyStore) DeleteService(id, serviceName, namespaceName string) error {
+	return s.publish(nil, s.Store.DeleteService(id, serviceName, namespaceName), deleteEvent(model.ChangeService, id))
+}
+
+// DeleteServiceAlias 删除服务别名
+func (s *changeNotifyStore) DeleteServiceAlias(name string, namespace string) error {
+	return s.publish(nil, s.Store.DeleteServiceAlias(name, namespace), changeEvent(model.ChangeService, "", ""))
+}
+
+// UpdateServiceAlias 修改服务别名
+func (s *changeNotifyStore) UpdateServiceAlias(alias *model.Service, needUpdateOwner bool) error {
+	return s.publish(nil, s.Store.UpdateServiceAlias(alias, needUpdateOwner),
+		changeEvent(model.ChangeService, alias.ID, alias.Revision))
+}
+
+// UpdateService 更新服务
+func (s *changeNotifyStore) UpdateService(service *model.Service, needUpdateOwner bool) error {
+	return s.publish(nil, s.Store.UpdateService(service, needUpdateOwner),
+		changeEvent(model.ChangeService, service.ID, service.Revision))
+}
+
+// UpdateServiceToken 更新服务token
+func (s *changeNotifyStore) UpdateServiceToken(serviceID string, token string, revision string) error {
+	return s.publish(nil, s.Store.UpdateServiceToken(serviceID, token, revision),
+		changeEvent(model.ChangeService, serviceID, revision))
+}
+
+// AddInstance 增加一个实例
+func (s *changeNotifyStore) AddInstance(instance *model.Instance) error {
+	return s.publish(nil, s.Store.AddInstance(instance),
+		changeEvent(model.ChangeInstance, instance.ID(), instance.Revision()))
+}
+
+// BatchAddInstances 增加多个实例
+func (s *changeNotifyStore) BatchAddInstances(instances []*model.Instance) error {
+	events := make([]*model.ChangeEvent, 0, len(instances))
+	for _, instance := range instances {
+		events = append(events, changeEvent(model.ChangeInstance, instance.ID(), instance.Revision()))
+	}
+	return s.publish(nil, s.Store.BatchAddInstances(instances), events...)
+}
+
+// UpdateInstance 更新实例
+func (s *changeNotifyStore) UpdateInstance(instance *model.Instance) error {
+	return s.publish(nil, s.Store.UpdateInstance(instance),
+		changeEvent(model.ChangeInstance, instance.ID(), instance.Revision()))
+}
+
+// DeleteInstance 删除一个实例，删除实例实际上是把valid置为false
+func (s *changeNotifyStore) DeleteInstance(instanceID string) error {
+	return s.publish(nil, s.Store.DeleteInstance(instanceID), deleteEvent(model.ChangeInstance, instanceID))
+}
+
+// BatchDeleteInstances 批量删除实例，flag=1
+func (s *changeNotifyStore) BatchDeleteInstances(ids []interface{}) error {
+	events := instanceEvents(ids, "")
+	for _, event := range events {
+		event.Deleted = true
+	}
+	return s.publish(nil, s.Store.BatchDeleteInstances(ids), events...)
+}
+
+// CleanInstance 清空一个实例，真正删除
+func (s *changeNotifyStore) CleanInstance(instanceID string) error {
+	return s.publish(nil, s.Store.CleanInstance(instanceID), deleteEvent(model.ChangeInstance, instanceID))
+}
+
+// SetInstanceHealthStatus 设置实例的健康状态
+func (s *changeNotifyStore) SetInstanceHealthStatus(instanceID string, flag int, revision string) error {
+	return s.publish(nil, s.Store.SetInstanceHealthStatus(instanceID, flag, revision),
+		changeEvent(model.ChangeInstance, instanceID, revision))
+}
+
+// BatchSetInstanceHealthStatus 批量设置实例的健康状态
+func (s *changeNotifyStore) BatchSetInstanceHealthStatus(ids []interface{}, healthy int, revision string) error {
+	return s.publish(nil, s.Store.BatchSetInstanceHealthStatus(ids, healthy, revision),
+		instanceEvents(ids, revision)...)
+}
+
+// BatchSetInstanceIsolate 批量修改实例的隔离状态
+func (s *changeNotifyStore) BatchSetInstanceIsolate(ids []interface{}, isolate int, revision string) error {
+	return s.publish(nil, s.Store.BatchSetInstanceIsolate(ids, isolate, revision),
+		instanceEvents(ids, revision)...)
+}
+
+// BatchAppendInstanceMetadata 追加实例 metadata
+func (s *changeNotifyStore) BatchAppendInstanceMetadata(requests []*InstanceMetadataRequest) error {
+	return s.publish(nil, s.Store.BatchAppendInstanceMetadata(requests), metadataEvents(requests)...)
+}
+
+// BatchRemoveInstanceMetadata 删除实例指定的 metadata
+func (s *changeNotifyStore) BatchRemoveInstanceMetadata(requests []*InstanceMetadataRequest) error {
+	return s.publish(nil, s.Store.BatchRemoveInstanceMetadata(requests), metadataEvents(requests)...)
+}
+
+func metadataEvents(requests []*InstanceMetadataRequest) []*model.ChangeEvent {
+	events := make([]*model.ChangeEvent, 0, len(requests))
+	for _, req := range requests {
+		events = append(events, changeEvent(model.ChangeInstance, req.InstanceID, req.Revision))
+	}
+	return events
+}
+
+// CreateRoutingConfig 新增一个路由配置
+func (s *changeNotifyStore) CreateRoutingConfig(conf *model.RoutingConfig) error {
+	return s.publish(nil, s.Store.CreateRoutingConfig(conf),
+		changeEvent(model.ChangeRoutingConfig, conf.ID, conf.Revision))
+}
+
+// UpdateRoutingConfig 更新一个路由配置
+func (s *changeNotifyStore) UpdateRoutingConfig(conf *model.RoutingConfig) error {
+	return s.publish(nil, s.Store.UpdateRoutingConfig(conf),
+		changeEvent(model.ChangeRoutingConfig, conf.ID, conf.Revision))
+}
+
+// DeleteRoutingConfig 删除一个路由配置
+func (s *changeNotifyStore) DeleteRoutingConfig(serviceID string) error {
+	return s.publish(nil, s.Store.DeleteRoutingConfig(serviceID), deleteEvent(model.ChangeRoutingConfig, serviceID))
+}
+
+// DeleteRoutingConfigTx 删除一个路由配置
+func (s *changeNotifyStore) DeleteRoutingConfigTx(tx Tx, serviceID string) error {
+	return s.publish(tx, s.Store.DeleteRoutingConfigTx(tx, serviceID), deleteEvent(model.ChangeRoutingConfig, serviceID))
+}
+
+// EnableRouting 设置路由规则是否启用
+func (s *changeNotifyStore) EnableRouting(conf *model.RouterConfig) error {
+	return s.publish(nil, s.Store.EnableRouting(conf), changeEvent(model.ChangeRoutingConfig, conf.ID, conf.Revision))
+}
+
+// CreateRoutingConfigV2 新增一个路由配置
+func (s *changeNotifyStore) CreateRoutingConfigV2(conf *model.RouterConfig) error {
+	return s.publish(nil, s.Store.CreateRoutingConfigV2(conf),
+		changeEvent(model.ChangeRoutingConfig, conf.ID, conf.Revision))
+}
+
+// CreateRoutingConfigV2Tx 新增一个路由配置
+func (s *changeNotifyStore) CreateRoutingConfigV2Tx(tx Tx, conf *model.RouterConfig) error {
+	return s.publish(tx, s.Store.CreateRoutingConfigV2Tx(tx, conf),
+		changeEvent(model.ChangeRoutingConfig, conf.ID, conf.Revision))
+}
+
+// UpdateRoutingConfigV2 更新一个路由配置
+func (s *changeNotifyStore) UpdateRoutingConfigV2(conf *model.RouterConfig) error {
+	return s.publish(nil, s.Store.UpdateRoutingConfigV2(conf),
+		changeEvent(model.ChangeRoutingConfig, conf.ID, conf.Revision))
+}
+
+// UpdateRoutingConfigV2Tx 更新一个路由配置
+func (s *changeNotifyStore) UpdateRoutingConfigV2Tx(tx Tx, conf *model.RouterConfig) error {
+	return s.publish(tx, s.Store.UpdateRoutingConfigV2Tx(tx, conf),
+		changeEvent(model.ChangeRoutingConfig, conf.ID, conf.Revision))
+}
+
+// DeleteRoutingConfigV2 删除一个路由配置
+func (s *changeNotifyStore) DeleteRoutingConfigV2(serviceID string) error {
+	return s.publish(nil, s.Store.DeleteRoutingConfigV2(serviceID), deleteEvent(model.ChangeRoutingConfig, serviceID))
+}
+
+// CreateRateLimit 新增限流规则
+func (s *changeNotifyStore) CreateRateLimit(limiting *model.RateLimit) error {
+	return s.publish(nil, s.Store.CreateRateLimit(limiting),
+		changeEvent(model.ChangeRateLimit, limiting.ID, limiting.Revision))
+}
+
+// UpdateRateLimit 更新限流规则
+func (s *changeNotifyStore) UpdateRateLimit(limiting *model.RateLimit) error {
+	return s.publish(nil, s.Store.UpdateRateLimit(limiting),
+		changeEvent(model.ChangeRateLimit, limiting.ID, limiting.Revision))
+}
+
+// EnableRateLimit 启用限流规则
+func (s *changeNotifyStore) EnableRateLimit(limit *model.RateLimit) error {
+	return s.publish(nil, s.Store.EnableRateLimit(limit), changeEvent(model.ChangeRateLimit, limit.ID, limit.Revision))
+}
+
+// DeleteRateLimit 删除限流规则
+func (s *changeNotifyStore) DeleteRateLimit(limiting *model.RateLimit) error {
+	return s.publish(nil, s.Store.DeleteRateLimit(limiting), deleteEvent(model.ChangeRateLimit, limiting.ID))
+}
+
+// CreateCircuitBreakerRule create general circuitbreaker rule
+func (s *changeNotifyStore) CreateCircuitBreakerRule(cbRule *model.CircuitBreakerRule) error {
+	return s.publish(nil, s.Store.CreateCircuitBreakerRule(cbRule),
+		changeEvent(model.ChangeCircuitBreaker, cbRule.ID, cbRule.Revision))
+}
+
+// UpdateCircuitBreakerRule update general circuitbreaker rule
+func (s *changeNotifyStore) UpdateCircuitBreakerRule(cbRule *model.CircuitBreakerRule) error {
+	return s.publish(nil, s.Store.UpdateCircuitBreakerRule(cbRule),
+		changeEvent(model.ChangeCircuitBreaker, cbRule.ID, cbRule.Revision))
+}
+
+// DeleteCircuitBreakerRule delete general circuitbreaker rule
+func (s *changeNotifyStore) DeleteCircuitBreakerRule(id string) error {
+	return s.publish(nil, s.Store.DeleteCircuitBreakerRule(id), deleteEvent(model.ChangeCircuitBreaker, id))
+}
+
+// EnableCircuitBreakerRule enable specific circuitbreaker rule
+func (s *changeNotifyStore) EnableCircuitBreakerRule(cbRule *model.CircuitBreakerRule) error {
+	return s.publish(nil, s.Store.EnableCircuitBreakerRule(cbRule),
+		changeEvent(model.ChangeCircuitBreaker, cbRule.ID, cbRule.Revision))
+}
+
+// CreateFaultDetectRule create fault detect rule
+func (s *changeNotifyStore) CreateFaultDetectRule(conf *model.FaultDetectRule) error {
+	return s.publish(nil, s.Store.CreateFaultDetectRule(conf),
+		changeEvent(model.ChangeFaultDetect, conf.ID, conf.Revision))
+}
+
+// UpdateFaultDetectRule update fault detect rule
+func (s *changeNotifyStore) UpdateFaultDetectRule(conf *model.FaultDetectRule) error {
+	return s.publish(nil, s.Store.UpdateFaultDetectRule(conf),
+		changeEvent(model.ChangeFaultDetect, conf.ID, conf.Revision))
+}
+
+// DeleteFaultDetectRule delete fault detect rule
+func (s *changeNotifyStore) DeleteFaultDetectRule(id string) error {
+	return s.publish(nil, s.Store.DeleteFaultDetectRule(id), deleteEvent(model.ChangeFaultDetect, id))
+}
+
+// CreateConfigFileGroup 创建配置文件组
+func (s *changeNotifyStore) CreateConfigFileGroup(fileGroup *model.ConfigFileGroup) (*model.ConfigFileGroup, error) {
+	ret, err := s.Store.CreateConfigFileGroup(fileGroup)
+	if err != nil {
+		return nil, err
+	}
+	id := fileGroup.Id
+	if ret != nil {
+		id = ret.Id
+	}
+	_ = s.publish(nil, nil, changeEvent(model.ChangeConfigGroup, strconv.FormatUint(id, 10), ""))
+	return ret, nil
+}
+
+// UpdateConfigFileGroup 更新配置文件组
+func (s *changeNotifyStore) UpdateConfigFileGroup(fileGroup *model.ConfigFileGroup) error {
+	return s.publish(nil, s.Store.UpdateConfigFileGroup(fileGroup),
+		changeEvent(model.ChangeConfigGroup, strconv.FormatUint(fileGroup.Id, 10), ""))
+}
+
+// DeleteConfigFileGroup 删除配置文件组
+func (s *changeNotifyStore) DeleteConfigFileGroup(namespace, name string) error {
+	return s.publish(nil, s.Store.DeleteConfigFileGroup(namespace, name), changeEvent(model.ChangeConfigGroup, "", ""))
+}
+
+// CreateConfigFileReleaseTx 创建配置文件发布
+func (s *changeNotifyStore) CreateConfigFileReleaseTx(tx Tx, fileRelease *model.ConfigFileRelease) error {
+	return s.publish(tx, s.Store.CreateConfigFileReleaseTx(tx, fileRelease), releaseEvent(fileRelease.ConfigFileReleaseKey))
+}
+
+// DeleteConfigFileReleaseTx 删除配置文件发布内容
+func (s *changeNotifyStore) DeleteConfigFileReleaseTx(tx Tx, data *model.ConfigFileReleaseKey) error {
+	return s.publish(tx, s.Store.DeleteConfigFileReleaseTx(tx, data), releaseEvent(data))
+}
+
+// ActiveConfigFileReleaseTx 指定激活发布的配置文件
+func (s *changeNotifyStore) ActiveConfigFileReleaseTx(tx Tx, release *model.ConfigFileRelease) error {
+	return s.publish(tx, s.Store.ActiveConfigFileReleaseTx(tx, release), releaseEvent(release.ConfigFileReleaseKey))
+}
+
+// InactiveConfigFileReleaseTx 指定失效发布的配置文件
+func (s *changeNotifyStore) InactiveConfigFileReleaseTx(tx Tx, release *model.ConfigFileRelease) error {
+	return s.publish(tx, s.Store.InactiveConfigFileReleaseTx(tx, release), releaseEvent(release.ConfigFileReleaseKey))
+}
+
+// UpdateConfigFileReleaseMetadataTx 更新配置文件发布的标签
+func (s *changeNotifyStore) UpdateConfigFileReleaseMetadataTx(tx Tx, release *model.ConfigFileRelease) error {
+	return s.publish(tx, s.Store.UpdateConfigFileReleaseMetadataTx(tx, release),
+		releaseEvent(release.ConfigFileReleaseKey))
+}
+
+// CleanConfigFileReleasesTx 清空配置文件发布
+func (s *changeNotifyStore) CleanConfigFileReleasesTx(tx Tx, namespace, group, fileName string) error {
+	return s.publish(tx, s.Store.CleanConfigFileReleasesTx(tx, namespace, group, fileName),
+		changeEvent(model.ChangeConfigFileRelease, "", ""))
+}
+
+func releaseEvent(key *model.ConfigFileReleaseKey) *model.ChangeEvent {
+	if key == nil {
+		return changeEvent(model.ChangeConfigFileRelease, "", "")
+	}
+	return changeEvent(model.ChangeConfigFileRelease, strconv.FormatUint(key.Id, 10), "")
+}
+
+// AddUser 添加用户
+func (s *changeNotifyStore) AddUser(user *model.User) error {
+	return s.publish(nil, s.Store.AddUser(user), changeEvent(model.ChangeUser, user.ID, ""))
+}
+
+// UpdateUser 更新用户
+func (s *changeNotifyStore) UpdateUser(user *model.User) error {
+	return s.publish(nil, s.Store.UpdateUser(user), changeEvent(model.ChangeUser, user.ID, ""))
+}
+
+// DeleteUser 删除用户
+func (s *changeNotifyStore) DeleteUser(user *model.User) error {
+	return s.publish(nil, s.Store.DeleteUser(user), deleteEvent(model.ChangeUser, user.ID))
+}
+
+// AddGroup 添加用户组
+func (s *changeNotifyStore) AddGroup(group *model.UserGroupDetail) error {
+	return s.publish(nil, s.Store.AddGroup(group), changeEvent(model.ChangeUserGroup, group.ID, ""))
+}
+
+// UpdateGroup 更新用户组
+func (s *changeNotifyStore) UpdateGroup(group *model.ModifyUserGroup) error {
+	return s.publish(nil, s.Store.UpdateGroup(group), changeEvent(model.ChangeUserGroup, group.ID, ""))
+}
+
+// DeleteGroup 删除用户组
+func (s *changeNotifyStore) DeleteGroup(group *model.UserGroupDetail) error {
+	return s.publish(nil, s.Store.DeleteGroup(group), deleteEvent(model.ChangeUserGroup, group.ID))
+}
+
+// AddAccessToken 保存个人访问令牌
+func (s *changeNotifyStore) AddAccessToken(token *model.AccessToken) error {
+	return s.publish(nil, s.Store.AddAccessToken(token), changeEvent(model.ChangeAccessToken, token.ID, ""))
+}
+
+// DeleteAccessToken 吊销个人访问令牌
+func (s *changeNotifyStore) DeleteAccessToken(id string) error {
+	return s.publish(nil, s.Store.DeleteAccessToken(id), deleteEvent(model.ChangeAccessToken, id))
+}
+
+// UpdateAccessTokensLastUsed 记录个人访问令牌的最后使用时间
+func (s *changeNotifyStore) UpdateAccessTokensLastUsed(lastUsed map[string]time.Time) error {
+	events := make([]*model.ChangeEvent, 0, len(lastUsed))
+	for id := range lastUsed {
+		events = append(events, changeEvent(model.ChangeAccessToken, id, ""))
+	}
+	return s.publish(nil, s.Store.UpdateAccessTokensLastUsed(lastUsed), events...)
+}
+
+// AddStrategy 创建鉴权策略
+func (s *changeNotifyStore) AddStrategy(strategy *model.StrategyDetail) error {
+	return s.publish(nil, s.Store.AddStrategy(strategy), changeEvent(model.ChangeStrategy, strategy.ID, ""))
+}
+
+// UpdateStrategy 更新鉴权策略
+func (s *changeNotifyStore) UpdateStrategy(strategy *model.ModifyStrategyDetail) error {
+	return s.publish(nil, s.Store.UpdateStrategy(strategy), changeEvent(model.ChangeStrategy, strategy.ID, ""))
+}
+
+// DeleteStrategy 删除鉴权策略
+func (s *changeNotifyStore) DeleteStrategy(id string) error {
+	return s.publish(nil, s.Store.DeleteStrategy(id), deleteEvent(model.ChangeStrategy, id))
+}
+
+// RemoveStrategyResources 清理鉴权策略关联的资源
+func (s *changeNotifyStore) RemoveStrategyResources(resources []model.StrategyResource) error {
+	return s.publish(nil, s.Store.RemoveStrategyResources(resources), changeEvent(model.ChangeStrategy, "", ""))
+}
+
+// LooseAddStrategyResources 向鉴权策略添加资源，资源已经存在时忽略
+func (s *changeNotifyStore) LooseAddStrategyResources(resources []model.StrategyResource) error {
+	return s.publish(nil, s.Store.LooseAddStrategyResources(resources), changeEvent(model.ChangeStrategy, "", ""))
+}
@@ -0,0 +1,123 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package store_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+	"github.com/polarismesh/polaris/store/mock"
+)
+
+type recordNotifier struct {
+	lock   sync.Mutex
+	events []*model.ChangeEvent
+}
+
+func (r *recordNotifier) Publish(events []*model.ChangeEvent) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events = append(r.events, events...)
+}
+
+func (r *recordNotifier) Subscribe(handler func(events []*model.ChangeEvent)) {
+}
+
+func (r *recordNotifier) take() []*model.ChangeEvent {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	ret := r.events
+	r.events = nil
+	return ret
+}
+
+func TestNotifyStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mock.NewMockStore(ctrl)
+	notifier := &recordNotifier{}
+	s := store.NewNotifyStore(mockStore, notifier)
+
+	t.Run("写操作成功后发布变更事件", func(t *testing.T) {
+		mockStore.EXPECT().BatchSetInstanceHealthStatus(gomock.Any(), 1, "rev-1").Return(nil)
+		assert.NoError(t, s.BatchSetInstanceHealthStatus([]interface{}{"ins-1", "ins-2"}, 1, "rev-1"))
+		events := notifier.take()
+		assert.Len(t, events, 2)
+		assert.Equal(t, model.ChangeInstance, events[0].Resource)
+		assert.Equal(t, "ins-2", events[1].ID)
+		assert.Equal(t, "rev-1", events[1].Revision)
+
+		mockStore.EXPECT().DeleteService("svc-1", "svc", "default").Return(nil)
+		assert.NoError(t, s.DeleteService("svc-1", "svc", "default"))
+		events = notifier.take()
+		assert.Len(t, events, 1)
+		assert.True(t, events[0].Deleted)
+	})
+
+	t.Run("写操作失败不发布变更事件", func(t *testing.T) {
+		mockStore.EXPECT().DeleteInstance("ins-1").Return(errors.New("mock error"))
+		assert.Error(t, s.DeleteInstance("ins-1"))
+		assert.Empty(t, notifier.take())
+	})
+
+	t.Run("事务提交后发布变更事件", func(t *testing.T) {
+		mockTx := mock.NewMockTx(ctrl)
+		mockStore.EXPECT().StartTx().Return(mockTx, nil)
+		mockTx.EXPECT().Commit().Return(nil)
+
+		tx, err := s.StartTx()
+		assert.NoError(t, err)
+		release := &model.ConfigFileRelease{
+			SimpleConfigFileRelease: &model.SimpleConfigFileRelease{
+				ConfigFileReleaseKey: &model.ConfigFileReleaseKey{Id: 10},
+			},
+		}
+		mockStore.EXPECT().CreateConfigFileReleaseTx(tx, release).Return(nil)
+		assert.NoError(t, s.CreateConfigFileReleaseTx(tx, release))
+		mockStore.EXPECT().UpdateConfigFileReleaseMetadataTx(tx, release).Return(nil)
+		assert.NoError(t, s.UpdateConfigFileReleaseMetadataTx(tx, release))
+		assert.Empty(t, notifier.take())
+
+		assert.NoError(t, tx.Commit())
+		events := notifier.take()
+		assert.Len(t, events, 2)
+		for _, event := range events {
+			assert.Equal(t, model.ChangeConfigFileRelease, event.Resource)
+			assert.Equal(t, "10", event.ID)
+		}
+	})
+
+	t.Run("事务回滚丢弃变更事件", func(t *testing.T) {
+		mockTx := mock.NewMockTx(ctrl)
+		mockStore.EXPECT().StartTx().Return(mockTx, nil)
+		mockTx.EXPECT().Rollback().Return(nil)
+
+		tx, err := s.StartTx()
+		assert.NoError(t, err)
+		mockStore.EXPECT().DeleteRoutingConfigTx(tx, "svc-1").Return(nil)
+		assert.NoError(t, s.DeleteRoutingConfigTx(tx, "svc-1"))
+		assert.NoError(t, tx.Rollback())
+		assert.Empty(t, notifier.take())
+	})
+}
@@ -96,6 +96,8 @@ type ConfigFileReleaseStore interface {
 	CountConfigReleases(namespace, group string, onlyActive bool) (uint64, error)
 	// GetConfigFileBetaReleaseTx 获取灰度发布的配置文件信息
 	GetConfigFileBetaReleaseTx(tx Tx, file *model.ConfigFileKey) (*model.ConfigFileRelease, error)
+	// UpdateConfigFileReleaseMetadataTx 更新配置文件发布的标签，用于主密钥轮转后重新包装数据密钥
+	UpdateConfigFileReleaseMetadataTx(tx Tx, release *model.ConfigFileRelease) error
 }
 
 // ConfigFileReleaseHistoryStore 配置文件发布历史存储接口
@@ -106,6 +108,8 @@ type ConfigFileReleaseHistoryStore interface {
 	QueryConfigFileReleaseHistories(filter map[string]string, offset, limit uint32) (uint32, []*model.ConfigFileReleaseHistory, error)
 	// CleanConfigFileReleaseHistory 清理配置发布历史
 	CleanConfigFileReleaseHistory(endTime time.Time, limit uint64) error
+	// UpdateConfigFileReleaseHistoryMetadata 更新配置发布历史的标签，用于主密钥轮转后重新包装数据密钥
+	UpdateConfigFileReleaseHistoryMetadata(history *model.ConfigFileReleaseHistory) error
 }
 
 // ConfigFileTemplateStore config file template store
@@ -45,6 +45,8 @@ type NamingModuleStore interface {
 	GroupStore
 	// StrategyStore 鉴权策略接口
 	StrategyStore
+	// AccessTokenStore 个人访问令牌接口
+	AccessTokenStore
 	// RoutingConfigStoreV2 路由策略 v2 接口
 	RoutingConfigStoreV2
 	// FaultDetectRuleStore fault detect rule interface
@@ -0,0 +1,296 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package migrate
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/polarismesh/polaris/common/version"
+	"github.com/polarismesh/polaris/store"
+)
+
+const (
+	// ArchiveFormatVersion 备份归档的格式版本，格式不兼容时递增
+	ArchiveFormatVersion = 1
+
+	// ConflictSkip 恢复时跳过已经存在的数据
+	ConflictSkip = "skip"
+	// ConflictOverwrite 恢复时使用归档数据覆盖已经存在的数据，不支持覆盖的资源跳过
+	ConflictOverwrite = "overwrite"
+	// ConflictFail 存在任意冲突数据时放弃恢复，不写入任何数据
+	ConflictFail = "fail"
+
+	// maxArchiveLine 归档中单条数据的最大长度
+	maxArchiveLine = 64 * 1024 * 1024
+)
+
+// Manifest 备份归档的描述信息
+type Manifest struct {
+	// FormatVersion 归档格式版本
+	FormatVersion int `json:"formatVersion"`
+	// ServerVersion 生成归档的服务端版本
+	ServerVersion string `json:"serverVersion"`
+	// Store 生成归档的存储插件
+	Store string `json:"store"`
+	// CreateTime 开始备份的时间，各类资源依次读取，归档不是某一时刻的一致性快照
+	CreateTime time.Time `json:"createTime"`
+	// WithInstances 归档中是否包含服务实例
+	WithInstances bool `json:"withInstances"`
+}
+
+// BackupOptions 备份选项
+type BackupOptions struct {
+	// WithInstances 是否备份服务实例，实例通常由客户端注册，默认不备份
+	WithInstances bool
+	// BatchSize 每批读取的数据量
+	BatchSize int
+}
+
+// RestoreOptions 恢复选项
+type RestoreOptions struct {
+	// Policy 数据冲突时的处理策略，默认为 ConflictSkip
+	Policy string
+}
+
+// archiveLine 归档中的一行数据，第一行为 manifest，最后一行为 summary，中间为资源数据
+type archiveLine struct {
+	Manifest *Manifest      `json:"manifest,omitempty"`
+	Summary  map[string]int `json:"summary,omitempty"`
+	Type     string         `json:"type,omitempty"`
+	Data     interface{}    `json:"data,omitempty"`
+}
+
+// rawArchiveLine 解码时延后解析资源数据
+type rawArchiveLine struct {
+	Manifest *Manifest       `json:"manifest,omitempty"`
+	Summary  map[string]int  `json:"summary,omitempty"`
+	Type     string          `json:"type,omitempty"`
+	Data     json.RawMessage `json:"data,omitempty"`
+}
+
+// Backup 按照依赖关系依次导出全部治理数据，输出为 gzip 压缩的 JSON Lines 归档。备份期间写入的数据
+// 可能部分出现在归档中，上级资源不在归档中的数据不会导出，恢复时不会出现引用缺失的数据
+func Backup(s store.Store, w io.Writer, opts BackupOptions) (*Manifest, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	manifest := &Manifest{
+		FormatVersion: ArchiveFormatVersion,
+		ServerVersion: version.Get(),
+		Store:         s.Name(),
+		CreateTime:    time.Now(),
+		WithInstances: opts.WithInstances,
+	}
+	gw := gzip.NewWriter(w)
+	encoder := json.NewEncoder(gw)
+	if err := encoder.Encode(&archiveLine{Manifest: manifest}); err != nil {
+		return nil, err
+	}
+	summary := make(map[string]int, len(resources))
+	state := newDumpState()
+	for _, res := range resources {
+		if _, ok := res.(*instanceResource); ok && !opts.WithInstances {
+			continue
+		}
+		summary[res.name()] = 0
+		err := res.dump(s, state, opts.BatchSize, func(item interface{}) error {
+			summary[res.name()]++
+			return encoder.Encode(&archiveLine{Type: res.name(), Data: item})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("backup %s: %w", res.name(), err)
+		}
+	}
+	if err := encoder.Encode(&archiveLine{Summary: summary}); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Restore 将归档中的数据恢复到存储中，先完整校验归档再写入数据，返回每类资源的恢复结果
+func Restore(s store.Store, r io.Reader, opts RestoreOptions) ([]*Result, error) {
+	switch opts.Policy {
+	case "":
+		opts.Policy = ConflictSkip
+	case ConflictSkip, ConflictOverwrite, ConflictFail:
+	default:
+		return nil, fmt.Errorf("unknown conflict policy `%s`", opts.Policy)
+	}
+
+	// 归档需要读取两遍，先落到临时文件中
+	f, err := os.CreateTemp("", "polaris-restore-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	if _, err := io.Copy(f, r); err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]resource, len(resources))
+	for _, res := range resources {
+		byName[res.name()] = res
+	}
+
+	// 第一遍：校验格式、完整性，以及 fail 策略下的数据冲突
+	conflicts := 0
+	if _, err := readArchive(f, byName, func(res resource, item interface{}) error {
+		if opts.Policy != ConflictFail {
+			return nil
+		}
+		ok, err := res.existsItem(s, item)
+		if ok {
+			conflicts++
+		}
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if conflicts > 0 {
+		return nil, fmt.Errorf("%d record(s) already exist in store, abort restore", conflicts)
+	}
+
+	// 第二遍：按照归档中的顺序写入数据，归档按照依赖关系生成
+	results := make([]*Result, 0, len(resources))
+	retByName := make(map[string]*Result, len(resources))
+	_, err = readArchive(f, byName, func(res resource, item interface{}) error {
+		ret, ok := retByName[res.name()]
+		if !ok {
+			ret = &Result{Resource: res.name()}
+			retByName[res.name()] = ret
+			results = append(results, ret)
+		}
+		ret.Source++
+		exist, err := res.existsItem(s, item)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			if err := res.createItem(s, item); err != nil {
+				return fmt.Errorf("restore %s: %w", res.name(), err)
+			}
+			ret.Created++
+			return nil
+		}
+		if opts.Policy == ConflictOverwrite {
+			err := res.updateItem(s, item)
+			if err == nil {
+				ret.Created++
+				return nil
+			}
+			if !errors.Is(err, errNotOverwritable) {
+				return fmt.Errorf("restore %s: %w", res.name(), err)
+			}
+		}
+		ret.Existed++
+		return nil
+	})
+	return results, err
+}
+
+// readArchive 从头读取归档，校验 manifest 以及 summary，对每条资源数据执行回调
+func readArchive(f *os.File, byName map[string]resource,
+	handle func(res resource, item interface{}) error) (*Manifest, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	defer func() {
+		_ = gr.Close()
+	}()
+
+	scanner := bufio.NewScanner(gr)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxArchiveLine)
+	var (
+		manifest *Manifest
+		summary  map[string]int
+		counts   = map[string]int{}
+	)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		if summary != nil {
+			return nil, fmt.Errorf("invalid archive: unexpected data after summary at line %d", lineNo)
+		}
+		line := &rawArchiveLine{}
+		if err := json.Unmarshal(scanner.Bytes(), line); err != nil {
+			return nil, fmt.Errorf("invalid archive: line %d: %w", lineNo, err)
+		}
+		switch {
+		case lineNo == 1:
+			if line.Manifest == nil {
+				return nil, errors.New("invalid archive: manifest not found")
+			}
+			if line.Manifest.FormatVersion != ArchiveFormatVersion {
+				return nil, fmt.Errorf("unsupported archive format version %d, expect %d",
+					line.Manifest.FormatVersion, ArchiveFormatVersion)
+			}
+			manifest = line.Manifest
+		case line.Summary != nil:
+			summary = line.Summary
+		default:
+			res, ok := byName[line.Type]
+			if !ok {
+				return nil, fmt.Errorf("invalid archive: unknown resource type `%s` at line %d", line.Type, lineNo)
+			}
+			item, err := res.decode(line.Data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid archive: line %d: %w", lineNo, err)
+			}
+			counts[line.Type]++
+			if err := handle(res, item); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	if manifest == nil {
+		return nil, errors.New("invalid archive: manifest not found")
+	}
+	// 缺少 summary 或者数量不一致说明归档被截断
+	if summary == nil {
+		return nil, errors.New("invalid archive: summary not found, the archive may be truncated")
+	}
+	for name, cnt := range summary {
+		if counts[name] != cnt {
+			return nil, fmt.Errorf("invalid archive: expect %d %s record(s), got %d", cnt, name, counts[name])
+		}
+	}
+	for name, cnt := range counts {
+		if _, ok := summary[name]; !ok {
+			return nil, fmt.Errorf("invalid archive: %d %s record(s) not in summary", cnt, name)
+		}
+	}
+	return manifest, nil
+}
@@ -0,0 +1,147 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package migrate
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+func TestBackupRestore(t *testing.T) {
+	dir := t.TempDir()
+	src := newTestStore(t, filepath.Join(dir, "source.bolt"))
+	seedSource(t, src)
+
+	archive := &bytes.Buffer{}
+	manifest, err := Backup(src, archive, BackupOptions{WithInstances: true, BatchSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, ArchiveFormatVersion, manifest.FormatVersion)
+	assert.True(t, manifest.WithInstances)
+
+	t.Run("restore into empty store", func(t *testing.T) {
+		dst := newTestStore(t, filepath.Join(dir, "empty.bolt"))
+		results, err := Restore(dst, bytes.NewReader(archive.Bytes()), RestoreOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, findResult(results, "service").Created)
+		assert.Equal(t, 3, findResult(results, "instance").Created)
+		assert.Equal(t, 2, findResult(results, "configRelease").Created)
+
+		ins, err := dst.GetInstance("ins-2")
+		assert.NoError(t, err)
+		assert.Equal(t, "ins-revision-2", ins.Revision())
+		active, err := dst.GetConfigFileActiveRelease(&model.ConfigFileKey{Namespace: "migrate-ns",
+			Group: "group", Name: "app.yaml"})
+		assert.NoError(t, err)
+		assert.Equal(t, "release-2", active.Name)
+
+		// 再次恢复时全部数据已经存在
+		results, err = Restore(dst, bytes.NewReader(archive.Bytes()), RestoreOptions{Policy: ConflictSkip})
+		assert.NoError(t, err)
+		for _, ret := range results {
+			assert.Zero(t, ret.Created, ret.Resource)
+		}
+		_, err = Restore(dst, bytes.NewReader(archive.Bytes()), RestoreOptions{Policy: ConflictFail})
+		assert.Error(t, err)
+	})
+
+	t.Run("overwrite existing store", func(t *testing.T) {
+		dst := newTestStore(t, filepath.Join(dir, "existing.bolt"))
+		_, err := Restore(dst, bytes.NewReader(archive.Bytes()), RestoreOptions{})
+		assert.NoError(t, err)
+		svc, err := dst.GetServiceByID("svc-1")
+		assert.NoError(t, err)
+		svc.Comment = "changed"
+		svc.Revision = "changed"
+		assert.NoError(t, dst.UpdateService(svc, true))
+		// 重新激活旧的发布并删除当前的发布
+		tx, err := dst.StartTx()
+		assert.NoError(t, err)
+		release1 := &model.ConfigFileRelease{SimpleConfigFileRelease: &model.SimpleConfigFileRelease{
+			ConfigFileReleaseKey: &model.ConfigFileReleaseKey{Name: "release-1", Namespace: "migrate-ns",
+				Group: "group", FileName: "app.yaml"}}}
+		assert.NoError(t, dst.ActiveConfigFileReleaseTx(tx, release1))
+		assert.NoError(t, dst.DeleteConfigFileReleaseTx(tx, &model.ConfigFileReleaseKey{Name: "release-2",
+			Namespace: "migrate-ns", Group: "group", FileName: "app.yaml"}))
+		assert.NoError(t, tx.Commit())
+
+		results, err := Restore(dst, bytes.NewReader(archive.Bytes()), RestoreOptions{Policy: ConflictOverwrite})
+		assert.NoError(t, err)
+		assert.Zero(t, findResult(results, "service").Existed)
+		assert.Zero(t, findResult(results, "configRelease").Existed)
+		svc, err = dst.GetServiceByID("svc-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "svc-revision", svc.Revision)
+		active, err := dst.GetConfigFileActiveRelease(&model.ConfigFileKey{Namespace: "migrate-ns",
+			Group: "group", Name: "app.yaml"})
+		assert.NoError(t, err)
+		assert.Equal(t, "release-2", active.Name)
+		assert.Equal(t, "a: 2", active.Content)
+	})
+
+	t.Run("reject invalid archive", func(t *testing.T) {
+		dst := newTestStore(t, filepath.Join(dir, "invalid.bolt"))
+		_, err := Restore(dst, bytes.NewReader(archive.Bytes()), RestoreOptions{Policy: "unknown"})
+		assert.Error(t, err)
+
+		// 截断的归档缺少 summary
+		gr, err := gzip.NewReader(bytes.NewReader(archive.Bytes()))
+		assert.NoError(t, err)
+		raw, err := io.ReadAll(gr)
+		assert.NoError(t, err)
+		truncated := &bytes.Buffer{}
+		gw := gzip.NewWriter(truncated)
+		_, _ = gw.Write(raw[:len(raw)/2])
+		assert.NoError(t, gw.Close())
+		_, err = Restore(dst, truncated, RestoreOptions{})
+		assert.Error(t, err)
+		svc, err := dst.GetServiceByID("svc-1")
+		assert.NoError(t, err)
+		assert.Nil(t, svc)
+	})
+}
+
+func TestBackupSkipOrphans(t *testing.T) {
+	dir := t.TempDir()
+	src := newTestStore(t, filepath.Join(dir, "source.bolt"))
+	seedSource(t, src)
+	// 模拟备份读取命名空间之后新增的命名空间，其下的服务以及配置分组不在归档中
+	assert.NoError(t, src.AddService(&model.Service{ID: "svc-orphan", Name: "svc", Namespace: "orphan-ns",
+		Token: "svc-token", Revision: "svc-revision", Owner: "polaris", Valid: true}))
+	_, err := src.CreateConfigFileGroup(&model.ConfigFileGroup{Name: "group", Namespace: "orphan-ns", Valid: true})
+	assert.NoError(t, err)
+
+	archive := &bytes.Buffer{}
+	_, err = Backup(src, archive, BackupOptions{WithInstances: true, BatchSize: 2})
+	assert.NoError(t, err)
+
+	dst := newTestStore(t, filepath.Join(dir, "target.bolt"))
+	results, err := Restore(dst, bytes.NewReader(archive.Bytes()), RestoreOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, findResult(results, "service").Created)
+	assert.Equal(t, 1, findResult(results, "configGroup").Created)
+	svc, err := dst.GetServiceByID("svc-orphan")
+	assert.NoError(t, err)
+	assert.Nil(t, svc)
+}
@@ -0,0 +1,79 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// checkpoint 迁移进度，记录已经完成迁移的资源类型，中断后可以从未完成的资源继续
+type checkpoint struct {
+	path   string
+	Source string          `json:"source"`
+	Target string          `json:"target"`
+	Done   map[string]bool `json:"done"`
+}
+
+// loadCheckpoint 加载迁移进度，resume 为 false 或者文件不存在时从头开始
+func loadCheckpoint(path, source, target string, resume bool) (*checkpoint, error) {
+	cp := &checkpoint{path: path, Source: source, Target: target, Done: map[string]bool{}}
+	if !resume || path == "" {
+		return cp, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	saved := &checkpoint{}
+	if err := json.Unmarshal(data, saved); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file %s: %w", path, err)
+	}
+	if saved.Source != source || saved.Target != target {
+		return nil, fmt.Errorf("checkpoint file %s is for %s -> %s, not %s -> %s", path,
+			saved.Source, saved.Target, source, target)
+	}
+	if saved.Done != nil {
+		cp.Done = saved.Done
+	}
+	return cp, nil
+}
+
+// finish 标记资源迁移完成并落盘
+func (c *checkpoint) finish(name string) error {
+	c.Done[name] = true
+	if c.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	// 先写临时文件再重命名，避免中断时留下不完整的进度文件
+	tmp := filepath.Join(filepath.Dir(c.path), "."+filepath.Base(c.path)+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
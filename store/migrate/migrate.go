@@ -0,0 +1,137 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+// Package migrate 在两个 store.Store 实现之间迁移数据，例如从单机的 boltdb 迁移到集群的 MySQL
+package migrate
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/polarismesh/polaris/store"
+)
+
+const (
+	// DefaultBatchSize 默认每批处理的数据量
+	DefaultBatchSize = 100
+)
+
+// Options 迁移选项
+type Options struct {
+	// Checkpoint 迁移进度文件，为空时不记录进度
+	Checkpoint string
+	// Resume 从进度文件中记录的位置继续迁移
+	Resume bool
+	// BatchSize 每批读取以及写入的数据量
+	BatchSize int
+	// Out 迁移过程以及校验结果的输出
+	Out io.Writer
+}
+
+// Result 一类资源的迁移或者校验结果
+type Result struct {
+	// Resource 资源类型
+	Resource string
+	// Source 源存储中的有效数据量
+	Source int
+	// Created 本次迁移写入目标存储的数据量
+	Created int
+	// Existed 目标存储中已经存在的数据量
+	Existed int
+}
+
+// Missing 目标存储中缺失的数据量
+func (r *Result) Missing() int {
+	return r.Source - r.Created - r.Existed
+}
+
+// Migrator 存储之间的数据迁移
+type Migrator struct {
+	src  store.Store
+	dst  store.Store
+	opts Options
+}
+
+// NewMigrator 创建数据迁移，src 以及 dst 需要已经完成初始化
+func NewMigrator(src, dst store.Store, opts Options) *Migrator {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.Out == nil {
+		opts.Out = io.Discard
+	}
+	return &Migrator{src: src, dst: dst, opts: opts}
+}
+
+// Run 按照依赖顺序迁移全部资源，保留资源原有的 ID 以及 revision，目标存储中已经存在的资源会被跳过
+func (m *Migrator) Run() ([]*Result, error) {
+	cp, err := loadCheckpoint(m.opts.Checkpoint, m.src.Name(), m.dst.Name(), m.opts.Resume)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*Result, 0, len(resources))
+	for _, res := range resources {
+		if cp.Done[res.name()] {
+			_, _ = fmt.Fprintf(m.opts.Out, "[%s] already migrated, skip\n", res.name())
+			continue
+		}
+		_, _ = fmt.Fprintf(m.opts.Out, "[%s] migrating\n", res.name())
+		ret := &Result{Resource: res.name()}
+		if err := res.migrate(m, ret); err != nil {
+			return results, fmt.Errorf("migrate %s: %w", res.name(), err)
+		}
+		_, _ = fmt.Fprintf(m.opts.Out, "[%s] source %d, created %d, existed %d\n", res.name(),
+			ret.Source, ret.Created, ret.Existed)
+		results = append(results, ret)
+		if err := cp.finish(res.name()); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// Verify 校验源存储中的每一条有效数据在目标存储中都存在
+func (m *Migrator) Verify() ([]*Result, error) {
+	results := make([]*Result, 0, len(resources))
+	for _, res := range resources {
+		ret := &Result{Resource: res.name()}
+		if err := res.verify(m, ret); err != nil {
+			return results, fmt.Errorf("verify %s: %w", res.name(), err)
+		}
+		results = append(results, ret)
+	}
+	return results, nil
+}
+
+// PrintResults 以表格的方式输出校验结果，存在缺失的数据时返回错误
+func PrintResults(w io.Writer, results []*Result) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RESOURCE\tSOURCE\tTARGET\tMISSING")
+	missing := 0
+	for _, ret := range results {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", ret.Resource, ret.Source, ret.Created+ret.Existed, ret.Missing())
+		missing += ret.Missing()
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if missing > 0 {
+		return fmt.Errorf("%d record(s) missing in target store", missing)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+	"github.com/polarismesh/polaris/store/boltdb"
+)
+
+func newTestStore(t *testing.T, path string) store.Store {
+	s := boltdb.NewBoltStore()
+	if err := s.Initialize(&store.Config{Option: map[string]interface{}{"path": path}}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = s.Destroy()
+	})
+	return s
+}
+
+func seedSource(t *testing.T, s store.Store) {
+	now := time.Now().Format("2006-01-02 15:04:05")
+	assert.NoError(t, s.AddNamespace(&model.Namespace{Name: "migrate-ns", Token: "ns-token", Owner: "polaris",
+		Valid: true}))
+	assert.NoError(t, s.AddService(&model.Service{ID: "svc-1", Name: "svc", Namespace: "migrate-ns",
+		Token: "svc-token", Revision: "svc-revision", Owner: "polaris", Meta: map[string]string{"k": "v"}, Valid: true}))
+	assert.NoError(t, s.AddService(&model.Service{ID: "alias-1", Name: "svc-alias", Namespace: "migrate-ns",
+		Reference: "svc-1", Token: "alias-token", Revision: "alias-revision", Owner: "polaris", Valid: true}))
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, s.AddInstance(&model.Instance{
+			Proto: &apiservice.Instance{
+				Id:       &wrappers.StringValue{Value: fmt.Sprintf("ins-%d", i)},
+				Host:     &wrappers.StringValue{Value: fmt.Sprintf("127.0.0.%d", i+1)},
+				Port:     &wrappers.UInt32Value{Value: 8080},
+				Revision: &wrappers.StringValue{Value: fmt.Sprintf("ins-revision-%d", i)},
+				Metadata: map[string]string{"idx": fmt.Sprint(i)},
+				Ctime:    &wrappers.StringValue{Value: now},
+				Mtime:    &wrappers.StringValue{Value: now},
+			},
+			ServiceID: "svc-1",
+			Valid:     true,
+		}))
+	}
+	assert.NoError(t, s.CreateRateLimit(&model.RateLimit{ID: "rl-1", ServiceID: "svc-1", Name: "rl",
+		Rule: "{}", Revision: "rl-revision", Valid: true}))
+
+	_, err := s.CreateConfigFileGroup(&model.ConfigFileGroup{Name: "group", Namespace: "migrate-ns", Valid: true})
+	assert.NoError(t, err)
+	tx, err := s.StartTx()
+	assert.NoError(t, err)
+	assert.NoError(t, s.CreateConfigFileTx(tx, &model.ConfigFile{Name: "app.yaml", Namespace: "migrate-ns",
+		Group: "group", Content: "a: 1", Format: "yaml", Valid: true, Metadata: map[string]string{"t": "v"}}))
+	for i := 1; i <= 2; i++ {
+		assert.NoError(t, s.CreateConfigFileReleaseTx(tx, &model.ConfigFileRelease{
+			SimpleConfigFileRelease: &model.SimpleConfigFileRelease{
+				ConfigFileReleaseKey: &model.ConfigFileReleaseKey{
+					Name:      fmt.Sprintf("release-%d", i),
+					Namespace: "migrate-ns",
+					Group:     "group",
+					FileName:  "app.yaml",
+				},
+				Md5:   fmt.Sprintf("md5-%d", i),
+				Valid: true,
+			},
+			Content: fmt.Sprintf("a: %d", i),
+		}))
+	}
+	assert.NoError(t, tx.Commit())
+
+	assert.NoError(t, s.AddUser(&model.User{ID: "user-1", Name: "sub", Owner: "polaris", Source: "Polaris",
+		Type: model.SubAccountUserRole, Token: "user-token", Password: "pwd", Valid: true}))
+	assert.NoError(t, s.AddGroup(&model.UserGroupDetail{
+		UserGroup: &model.UserGroup{ID: "group-1", Name: "group", Owner: "polaris", Token: "group-token", Valid: true},
+		UserIds:   map[string]struct{}{"user-1": {}},
+	}))
+	assert.NoError(t, s.AddStrategy(&model.StrategyDetail{ID: "strategy-1", Name: "strategy", Action: "READ_WRITE",
+		Owner: "polaris", Principals: []model.Principal{{PrincipalID: "user-1", PrincipalRole: model.PrincipalUser}},
+		Resources: []model.StrategyResource{{StrategyID: "strategy-1", ResType: 0, ResID: "migrate-ns"}},
+		Valid:     true, Revision: "strategy-revision"}))
+}
+
+func findResult(results []*Result, name string) *Result {
+	for _, ret := range results {
+		if ret.Resource == name {
+			return ret
+		}
+	}
+	return nil
+}
+
+func TestMigrator_Run(t *testing.T) {
+	dir := t.TempDir()
+	src := newTestStore(t, filepath.Join(dir, "source.bolt"))
+	dst := newTestStore(t, filepath.Join(dir, "target.bolt"))
+	seedSource(t, src)
+
+	checkpointFile := filepath.Join(dir, "checkpoint")
+	m := NewMigrator(src, dst, Options{Checkpoint: checkpointFile, BatchSize: 2})
+	results, err := m.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, findResult(results, "service").Created)
+	assert.Equal(t, 3, findResult(results, "instance").Created)
+	assert.Equal(t, 2, findResult(results, "configRelease").Created)
+	assert.Equal(t, 1, findResult(results, "user").Created)
+	// 内置的命名空间在两个存储中都存在
+	assert.Equal(t, 1, findResult(results, "namespace").Created)
+
+	svc, err := dst.GetServiceByID("svc-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "svc-revision", svc.Revision)
+	assert.Equal(t, "svc-token", svc.Token)
+	alias, err := dst.GetServiceByID("alias-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "svc-1", alias.Reference)
+
+	ins, err := dst.GetInstance("ins-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ins-revision-1", ins.Revision())
+	assert.Equal(t, "1", ins.Metadata()["idx"])
+
+	file, err := dst.GetConfigFile("migrate-ns", "group", "app.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "a: 1", file.Content)
+	active, err := dst.GetConfigFileActiveRelease(&model.ConfigFileKey{Namespace: "migrate-ns", Group: "group",
+		Name: "app.yaml"})
+	assert.NoError(t, err)
+	assert.Equal(t, "release-2", active.Name)
+
+	group, err := dst.GetGroup("group-1")
+	assert.NoError(t, err)
+	assert.Contains(t, group.UserIds, "user-1")
+	strategy, err := dst.GetStrategyDetail("strategy-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "strategy-revision", strategy.Revision)
+
+	verified, err := m.Verify()
+	assert.NoError(t, err)
+	buf := &bytes.Buffer{}
+	assert.NoError(t, PrintResults(buf, verified))
+	assert.Contains(t, buf.String(), "instance")
+
+	// 从进度文件恢复时跳过已经完成的资源
+	results, err = NewMigrator(src, dst, Options{Checkpoint: checkpointFile, Resume: true}).Run()
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	// 重新执行时目标存储中已存在的数据全部跳过
+	results, err = NewMigrator(src, dst, Options{Checkpoint: checkpointFile}).Run()
+	assert.NoError(t, err)
+	for _, ret := range results {
+		assert.Zero(t, ret.Created, ret.Resource)
+		assert.Zero(t, ret.Missing(), ret.Resource)
+	}
+}
+
+func TestMigrator_Verify(t *testing.T) {
+	dir := t.TempDir()
+	src := newTestStore(t, filepath.Join(dir, "source.bolt"))
+	dst := newTestStore(t, filepath.Join(dir, "target.bolt"))
+	seedSource(t, src)
+
+	results, err := NewMigrator(src, dst, Options{}).Verify()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, findResult(results, "instance").Missing())
+	assert.Error(t, PrintResults(&bytes.Buffer{}, results))
+}
+
+func TestLoadCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	cp, err := loadCheckpoint(path, "boltdbStore", "defaultStore", true)
+	assert.NoError(t, err)
+	assert.NoError(t, cp.finish("namespace"))
+
+	cp, err = loadCheckpoint(path, "boltdbStore", "defaultStore", true)
+	assert.NoError(t, err)
+	assert.True(t, cp.Done["namespace"])
+
+	_, err = loadCheckpoint(path, "defaultStore", "boltdbStore", true)
+	assert.Error(t, err)
+
+	cp, err = loadCheckpoint(path, "defaultStore", "boltdbStore", false)
+	assert.NoError(t, err)
+	assert.Empty(t, cp.Done)
+}
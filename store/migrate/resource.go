@@ -0,0 +1,963 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+// resource 一类需要迁移的资源
+type resource interface {
+	name() string
+	// migrate 将源存储中的有效数据写入目标存储，目标存储中已经存在的数据跳过
+	migrate(m *Migrator, ret *Result) error
+	// verify 统计源存储中的有效数据在目标存储中是否存在
+	verify(m *Migrator, ret *Result) error
+	// dump 遍历存储中的全部有效数据，state 记录已经写入归档的上级资源
+	dump(s store.Store, state *dumpState, batchSize int, handle func(item interface{}) error) error
+	// decode 解码一条归档数据
+	decode(data []byte) (interface{}, error)
+	// existsItem 判断归档数据在存储中是否已经存在
+	existsItem(s store.Store, item interface{}) (bool, error)
+	// createItem 写入一条归档数据
+	createItem(s store.Store, item interface{}) error
+	// updateItem 使用归档数据覆盖存储中已经存在的数据，不支持覆盖时返回 errNotOverwritable
+	updateItem(s store.Store, item interface{}) error
+}
+
+// errNotOverwritable 资源不支持覆盖写入
+var errNotOverwritable = errors.New("not overwritable")
+
+// resources 需要迁移的资源，按照依赖关系排序
+var resources = []resource{
+	namespaceResource,
+	serviceResource,
+	&instanceResource{},
+	routingResource,
+	routingV2Resource,
+	rateLimitResource,
+	circuitBreakerResource,
+	faultDetectResource,
+	serviceContractResource,
+	configGroupResource,
+	configFileResource,
+	configReleaseResource,
+	configTemplateResource,
+	userResource,
+	groupResource,
+	strategyResource,
+	accessTokenResource,
+}
+
+// fullSyncTime 全量拉取数据时使用的起始时间
+var fullSyncTime = time.Unix(0, 0)
+
+// dumpState 备份过程中已经写入归档的上级资源。各类资源依次读取，备份期间新增的下级资源可能引用
+// 归档中不存在的上级资源，这类数据不写入归档
+type dumpState struct {
+	namespaces   map[string]struct{}
+	services     map[string]*model.Service
+	configGroups map[string]struct{}
+	configFiles  map[string]struct{}
+}
+
+func newDumpState() *dumpState {
+	return &dumpState{
+		namespaces:   map[string]struct{}{},
+		services:     map[string]*model.Service{},
+		configGroups: map[string]struct{}{},
+		configFiles:  map[string]struct{}{},
+	}
+}
+
+// itemResource 逐条判断是否存在以及写入的资源
+type itemResource[T any] struct {
+	kind string
+	// list 按批次遍历源存储中的有效数据
+	list func(s store.Store, batchSize int, handle func(items []T) error) error
+	// exists 判断数据在存储中是否已经存在
+	exists func(s store.Store, item T) (bool, error)
+	// create 写入一条数据，保留原有的 ID 以及 revision
+	create func(s store.Store, item T) error
+	// update 覆盖已经存在的数据，为空表示不支持覆盖
+	update func(s store.Store, item T) error
+	// track 备份时判断数据依赖的上级资源是否已经写入归档，并记录写入归档的数据，为空表示没有依赖
+	track func(state *dumpState, item T) bool
+}
+
+func (r *itemResource[T]) name() string {
+	return r.kind
+}
+
+func (r *itemResource[T]) migrate(m *Migrator, ret *Result) error {
+	return r.list(m.src, m.opts.BatchSize, func(items []T) error {
+		for _, item := range items {
+			ret.Source++
+			ok, err := r.exists(m.dst, item)
+			if err != nil {
+				return err
+			}
+			if ok {
+				ret.Existed++
+				continue
+			}
+			if err := r.create(m.dst, item); err != nil {
+				return err
+			}
+			ret.Created++
+		}
+		return nil
+	})
+}
+
+func (r *itemResource[T]) verify(m *Migrator, ret *Result) error {
+	return r.list(m.src, m.opts.BatchSize, func(items []T) error {
+		for _, item := range items {
+			ret.Source++
+			ok, err := r.exists(m.dst, item)
+			if err != nil {
+				return err
+			}
+			if ok {
+				ret.Existed++
+			}
+		}
+		return nil
+	})
+}
+
+func (r *itemResource[T]) dump(s store.Store, state *dumpState, batchSize int,
+	handle func(item interface{}) error) error {
+	return r.list(s, batchSize, func(items []T) error {
+		for _, item := range items {
+			if r.track != nil && !r.track(state, item) {
+				continue
+			}
+			if err := handle(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *itemResource[T]) decode(data []byte) (interface{}, error) {
+	var item T
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *itemResource[T]) existsItem(s store.Store, item interface{}) (bool, error) {
+	return r.exists(s, item.(T))
+}
+
+func (r *itemResource[T]) createItem(s store.Store, item interface{}) error {
+	return r.create(s, item.(T))
+}
+
+func (r *itemResource[T]) updateItem(s store.Store, item interface{}) error {
+	if r.update == nil {
+		return errNotOverwritable
+	}
+	return r.update(s, item.(T))
+}
+
+// listSorted 过滤掉无效数据并按照 key 排序后分批回调，保证多次执行时的处理顺序一致
+func listSorted[T any](items []T, valid func(T) bool, key func(T) string, batchSize int,
+	handle func(items []T) error) error {
+	ret := make([]T, 0, len(items))
+	for _, item := range items {
+		if valid(item) {
+			ret = append(ret, item)
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return key(ret[i]) < key(ret[j])
+	})
+	for start := 0; start < len(ret); start += batchSize {
+		end := start + batchSize
+		if end > len(ret) {
+			end = len(ret)
+		}
+		if err := handle(ret[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inTx 在目标存储的事务中执行写操作
+func inTx(s store.Store, handle func(tx store.Tx) error) error {
+	tx, err := s.StartTx()
+	if err != nil {
+		return err
+	}
+	if err := handle(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+var namespaceResource = &itemResource[*model.Namespace]{
+	kind: "namespace",
+	list: func(s store.Store, batchSize int, handle func([]*model.Namespace) error) error {
+		items, err := s.GetMoreNamespaces(fullSyncTime)
+		if err != nil {
+			return err
+		}
+		return listSorted(items, func(item *model.Namespace) bool {
+			return item.Valid
+		}, func(item *model.Namespace) string {
+			return item.Name
+		}, batchSize, handle)
+	},
+	exists: func(s store.Store, item *model.Namespace) (bool, error) {
+		ns, err := s.GetNamespace(item.Name)
+		return ns != nil, err
+	},
+	create: func(s store.Store, item *model.Namespace) error {
+		return s.AddNamespace(item)
+	},
+	update: func(s store.Store, item *model.Namespace) error {
+		if err := s.UpdateNamespace(item); err != nil {
+			return err
+		}
+		return s.UpdateNamespaceToken(item.Name, item.Token)
+	},
+	track: func(state *dumpState, item *model.Namespace) bool {
+		state.namespaces[item.Name] = struct{}{}
+		return true
+	},
+}
+
+var serviceResource = &itemResource[*model.Service]{
+	kind: "service",
+	list: func(s store.Store, batchSize int, handle func([]*model.Service) error) error {
+		services, err := s.GetMoreServices(fullSyncTime, true, false, true)
+		if err != nil {
+			return err
+		}
+		items := make([]*model.Service, 0, len(services))
+		for _, svc := range services {
+			items = append(items, svc)
+		}
+		return listSorted(items, func(item *model.Service) bool {
+			return item.Valid
+		}, func(item *model.Service) string {
+			// 别名依赖于源服务，需要放在最后写入
+			if item.IsAlias() {
+				return "1/" + item.ID
+			}
+			return "0/" + item.ID
+		}, batchSize, handle)
+	},
+	exists: func(s store.Store, item *model.Service) (bool, error) {
+		svc, err := s.GetServiceByID(item.ID)
+		if err != nil || svc != nil {
+			return svc != nil, err
+		}
+		// 目标存储中已经有同名服务，例如内置的系统服务，不再重复写入
+		svc, err = s.GetService(item.Name, item.Namespace)
+		return svc != nil, err
+	},
+	create: func(s store.Store, item *model.Service) error {
+		return s.AddService(item)
+	},
+	update: func(s store.Store, item *model.Service) error {
+		svc, err := s.GetServiceByID(item.ID)
+		if err != nil {
+			return err
+		}
+		// 同名但是 ID 不同的服务无法覆盖
+		if svc == nil {
+			return errNotOverwritable
+		}
+		if item.IsAlias() {
+			return s.UpdateServiceAlias(item, true)
+		}
+		return s.UpdateService(item, true)
+	},
+	// 别名排在源服务之后读取，源服务不在归档中时别名也不写入
+	track: func(state *dumpState, item *model.Service) bool {
+		if _, ok := state.namespaces[item.Namespace]; !ok {
+			return false
+		}
+		if item.IsAlias() {
+			if _, ok := state.services[item.Reference]; !ok {
+				return false
+			}
+		}
+		state.services[item.ID] = item
+		return true
+	},
+}
+
+// instanceResource 服务实例，按照服务分批读取以及写入
+type instanceResource struct{}
+
+func (r *instanceResource) name() string {
+	return "instance"
+}
+
+func (r *instanceResource) migrate(m *Migrator, ret *Result) error {
+	return r.each(m, func(src, dst map[string]*model.Instance) error {
+		missing := make([]*model.Instance, 0, len(src))
+		for _, id := range sortedKeys(src) {
+			ret.Source++
+			if _, ok := dst[id]; ok {
+				ret.Existed++
+				continue
+			}
+			missing = append(missing, src[id])
+		}
+		for start := 0; start < len(missing); start += m.opts.BatchSize {
+			end := start + m.opts.BatchSize
+			if end > len(missing) {
+				end = len(missing)
+			}
+			if err := m.dst.BatchAddInstances(missing[start:end]); err != nil {
+				return err
+			}
+			ret.Created += end - start
+		}
+		return nil
+	})
+}
+
+func (r *instanceResource) verify(m *Migrator, ret *Result) error {
+	return r.each(m, func(src, dst map[string]*model.Instance) error {
+		for id := range src {
+			ret.Source++
+			if _, ok := dst[id]; ok {
+				ret.Existed++
+			}
+		}
+		return nil
+	})
+}
+
+// each 遍历源存储中的服务，回调该服务在源存储以及目标存储中的有效实例
+func (r *instanceResource) each(m *Migrator, handle func(src, dst map[string]*model.Instance) error) error {
+	return serviceResource.list(m.src, m.opts.BatchSize, func(services []*model.Service) error {
+		for _, svc := range services {
+			if svc.IsAlias() {
+				continue
+			}
+			src, err := loadInstances(m.src, svc.ID)
+			if err != nil {
+				return err
+			}
+			if len(src) == 0 {
+				continue
+			}
+			dst, err := loadInstances(m.dst, svc.ID)
+			if err != nil {
+				return err
+			}
+			if err := handle(src, dst); err != nil {
+				return fmt.Errorf("service %s/%s: %w", svc.Namespace, svc.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// dump 只导出已经写入归档的服务下的实例
+func (r *instanceResource) dump(s store.Store, state *dumpState, batchSize int,
+	handle func(item interface{}) error) error {
+	for _, svcID := range sortedKeys(state.services) {
+		if state.services[svcID].IsAlias() {
+			continue
+		}
+		instances, err := loadInstances(s, svcID)
+		if err != nil {
+			return err
+		}
+		for _, id := range sortedKeys(instances) {
+			if err := handle(instances[id]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *instanceResource) decode(data []byte) (interface{}, error) {
+	item := &model.Instance{}
+	if err := json.Unmarshal(data, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *instanceResource) existsItem(s store.Store, item interface{}) (bool, error) {
+	ins, err := s.GetInstance(item.(*model.Instance).ID())
+	return ins != nil, err
+}
+
+func (r *instanceResource) createItem(s store.Store, item interface{}) error {
+	return s.BatchAddInstances([]*model.Instance{item.(*model.Instance)})
+}
+
+// updateItem 批量写入实例时会覆盖已经存在的实例
+func (r *instanceResource) updateItem(s store.Store, item interface{}) error {
+	return s.BatchAddInstances([]*model.Instance{item.(*model.Instance)})
+}
+
+func loadInstances(s store.Store, serviceID string) (map[string]*model.Instance, error) {
+	tx, err := s.StartReadTx()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	instances, err := s.GetMoreInstances(tx, fullSyncTime, false, true, []string{serviceID})
+	if err != nil {
+		return nil, err
+	}
+	for id, ins := range instances {
+		if !ins.Valid {
+			delete(instances, id)
+		}
+	}
+	return instances, nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var routingResource = &itemResource[*model.RoutingConfig]{
+	kind: "routing",
+	list: func(s store.Store, batchSize int, handle func([]*model.RoutingConfig) error) error {
+		items, err := s.GetRoutingConfigsForCache(fullSyncTime, true)
+		if err != nil {
+			return err
+		}
+		return listSorted(items, func(item *model.RoutingConfig) bool {
+			return item.Valid
+		}, func(item *model.RoutingConfig) string {
+			return item.ID
+		}, batchSize, handle)
+	},
+	exists: func(s store.Store, item *model.RoutingConfig) (bool, error) {
+		conf, err := s.GetRoutingConfigWithID(item.ID)
+		return conf != nil, err
+	},
+	create: func(s store.Store, item *model.RoutingConfig) error {
+		return s.CreateRoutingConfig(item)
+	},
+	update: func(s store.Store, item *model.RoutingConfig) error {
+		return s.UpdateRoutingConfig(item)
+	},
+}
+
+var routingV2Resource = &itemResource[*model.RouterConfig]{
+	kind: "routingV2",
+	list: func(s store.Store, batchSize int, handle func([]*model.RouterConfig) error) error {
+		items, err := s.GetRoutingConfigsV2ForCache(fullSyncTime, true)
+		if err != nil {
+			return err
+		}
+		return listSorted(items, func(item *model.RouterConfig) bool {
+			return item.Valid
+		}, func(item *model.RouterConfig) string {
+			return item.ID
+		}, batchSize, handle)
+	},
+	exists: func(s store.Store, item *model.RouterConfig) (bool, error) {
+		conf, err := s.GetRoutingConfigV2WithID(item.ID)
+		return conf != nil, err
+	},
+	create: func(s store.Store, item *model.RouterConfig) error {
+		return s.CreateRoutingConfigV2(item)
+	},
+	update: func(s store.Store, item *model.RouterConfig) error {
+		if err := s.UpdateRoutingConfigV2(item); err != nil {
+			return err
+		}
+		return s.EnableRouting(item)
+	},
+}
+
+var rateLimitResource = &itemResource[*model.RateLimit]{
+	kind: "ratelimit",
+	list: func(s store.Store, batchSize int, handle func([]*model.RateLimit) error) error {
+		items, err := s.GetRateLimitsForCache(fullSyncTime, true)
+		if err != nil {
+			return err
+		}
+		return listSorted(items, func(item *model.RateLimit) bool {
+			return item.Valid
+		}, func(item *model.RateLimit) string {
+			return item.ID
+		}, batchSize, handle)
+	},
+	exists: func(s store.Store, item *model.RateLimit) (bool, error) {
+		rule, err := s.GetRateLimitWithID(item.ID)
+		return rule != nil, err
+	},
+	create: func(s store.Store, item *model.RateLimit) error {
+		return s.CreateRateLimit(item)
+	},
+	update: func(s store.Store, item *model.RateLimit) error {
+		if err := s.UpdateRateLimit(item); err != nil {
+			return err
+		}
+		return s.EnableRateLimit(item)
+	},
+}
+
+var circuitBreakerResource = &itemResource[*model.CircuitBreakerRule]{
+	kind: "circuitbreaker",
+	list: func(s store.Store, batchSize int, handle func([]*model.CircuitBreakerRule) error) error {
+		items, err := s.GetCircuitBreakerRulesForCache(fullSyncTime, true)
+		if err != nil {
+			return err
+		}
+		return listSorted(items, func(item *model.CircuitBreakerRule) bool {
+			return item.Valid
+		}, func(item *model.CircuitBreakerRule) string {
+			return item.ID
+		}, batchSize, handle)
+	},
+	exists: func(s store.Store, item *model.CircuitBreakerRule) (bool, error) {
+		return s.HasCircuitBreakerRule(item.ID)
+	},
+	create: func(s store.Store, item *model.CircuitBreakerRule) error {
+		return s.CreateCircuitBreakerRule(item)
+	},
+	update: func(s store.Store, item *model.CircuitBreakerRule) error {
+		if err := s.UpdateCircuitBreakerRule(item); err != nil {
+			return err
+		}
+		return s.EnableCircuitBreakerRule(item)
+	},
+}
+
+var faultDetectResource = &itemResource[*model.FaultDetectRule]{
+	kind: "faultdetect",
+	list: func(s store.Store, batchSize int, handle func([]*model.FaultDetectRule) error) error {
+		items, err := s.GetFaultDetectRulesForCache(fullSyncTime, true)
+		if err != nil {
+			return err
+		}
+		return listSorted(items, func(item *model.FaultDetectRule) bool {
+			return item.Valid
+		}, func(item *model.FaultDetectRule) string {
+			return item.ID
+		}, batchSize, handle)
+	},
+	exists: func(s store.Store, item *model.FaultDetectRule) (bool, error) {
+		return s.HasFaultDetectRule(item.ID)
+	},
+	create: func(s store.Store, item *model.FaultDetectRule) error {
+		return s.CreateFaultDetectRule(item)
+	},
+	update: func(s store.Store, item *model.FaultDetectRule) error {
+		return s.UpdateFaultDetectRule(item)
+	},
+}
+
+var serviceContractResource = &itemResource[*model.EnrichServiceContract]{
+	kind: "serviceContract",
+	list: func(s store.Store, batchSize int, handle func([]*model.EnrichServiceContract) error) error {
+		items, err := s.GetMoreServiceContracts(true, fullSyncTime)
+		if err != nil {
+			return err
+		}
+		return listSorted(items, func(item *model.EnrichServiceContract) bool {
+			return item.Valid
+		}, func(item *model.EnrichServiceContract) string {
+			return item.ID
+		}, batchSize, handle)
+	},
+	exists: func(s store.Store, item *model.EnrichServiceContract) (bool, error) {
+		contract, err := s.GetServiceContract(item.ID)
+		return contract != nil, err
+	},
+	create: func(s store.Store, item *model.EnrichServiceContract) error {
+		if err := s.CreateServiceContract(item.ServiceContract); err != nil {
+			return err
+		}
+		if len(item.Interfaces) == 0 {
+			return nil
+		}
+		return s.AddServiceContractInterfaces(item)
+	},
+	update: func(s store.Store, item *model.EnrichServiceContract) error {
+		saved, err := s.GetServiceContract(item.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.UpdateServiceContract(item.ServiceContract); err != nil {
+			return err
+		}
+		// 先删除已有的接口描述，再写入归档中的接口描述
+		if saved != nil && len(saved.Interfaces) > 0 {
+			if err := s.DeleteServiceContractInterfaces(saved); err != nil {
+				return err
+			}
+		}
+		if len(item.Interfaces) == 0 {
+			return nil
+		}
+		return s.AddServiceContractInterfaces(item)
+	},
+}
+
+var configGroupResource = &itemResource[*model.ConfigFileGroup]{
+	kind: "configGroup",
+	list: func(s store.Store, batchSize int, handle func([]*model.ConfigFileGroup) error) error {
+		items, err := s.GetMoreConfigGroup(true, fullSyncTime)
+		if err != nil {
+			return err
+		}
+		return listSorted(items, func(item *model.ConfigFileGroup) bool {
+			return item.Valid
+		}, func(item *model.ConfigFileGroup) string {
+			return item.Namespace + "/" + item.Name
+		}, batchSize, handle)
+	},
+	exists: func(s store.Store, item *model.ConfigFileGroup) (bool, error) {
+		group, err := s.GetConfigFileGroup(item.Namespace, item.Name)
+		return group != nil, err
+	},
+	create: func(s store.Store, item *model.ConfigFileGroup) error {
+		_, err := s.CreateConfigFileGroup(item)
+		return err
+	},
+	update: func(s store.Store, item *model.ConfigFileGroup) error {
+		return s.UpdateConfigFileGroup(item)
+	},
+	track: func(state *dumpState, item *model.ConfigFileGroup) bool {
+		if _, ok := state.namespaces[item.Namespace]; !ok {
+			return false
+		}
+		state.configGroups[item.Namespace+"/"+item.Name] = struct{}{}
+		return true
+	},
+}
+
+var configFileResource = &itemResource[*model.ConfigFile]{
+	kind: "configFile",
+	// 按照配置分组分页读取配置文件，避免一次性加载全部配置内容
+	list: func(s store.Store, batchSize int, handle func([]*model.ConfigFile) error) error {
+		return configGroupResource.list(s, batchSize, func(groups []*model.ConfigFileGroup) error {
+			for _, group := range groups {
+				filter := map[string]string{"namespace": group.Namespace, "group": group.Name}
+				for offset := uint32(0); ; offset += uint32(batchSize) {
+					total, files, err := s.QueryConfigFiles(filter, offset, uint32(batchSize))
+					if err != nil {
+						return err
+					}
+					if err := handle(files); err != nil {
+						return err
+					}
+					if len(files) == 0 || offset+uint32(len(files)) >= total {
+						break
+					}
+				}
+			}
+			return nil
+		})
+	},
+	exists: func(s store.Store, item *model.ConfigFile) (bool, error) {
+		file, err := s.GetConfigFile(item.Namespace, item.Group, item.Name)
+		return file != nil, err
+	},
+	create: func(s store.Store, item *model.ConfigFile) error {
+		return inTx(s, func(tx store.Tx) error {
+			return s.CreateConfigFileTx(tx, item)
+		})
+	},
+	update: func(s store.Store, item *model.ConfigFile) error {
+		return inTx(s, func(tx store.Tx) error {
+			return s.UpdateConfigFileTx(tx, item)
+		})
+	},
+	track: func(state *dumpState, item *model.ConfigFile) bool {
+		if _, ok := state.configGroups[item.Namespace+"/"+item.Group]; !ok {
+			return false
+		}
+		state.configFiles[item.Namespace+"/"+item.Group+"/"+item.Name] = struct{}{}
+		return true
+	},
+}
+
+var configReleaseResource = &itemResource[*model.ConfigFileRelease]{
+	kind: "configRelease",
+	list: func(s store.Store, batchSize int, handle func([]*model.ConfigFileRelease) error) error {
+		items, err := s.GetMoreReleaseFile(true, fullSyncTime)
+		if err != nil {
+			return err
+		}
+		return listSorted(items, func(item *model.ConfigFileRelease) bool {
+			return item.Valid
+		}, func(item *model.ConfigFileRelease) string {
+			// 写入发布记录时会将同一个文件的其他发布置为非激活状态，因此同一个文件下激活的发布最后写入，
+			// 其余的按照版本号升序写入
+			active := 0
+			if item.Active {
+				active = 1
+			}
+			return fmt.Sprintf("%s/%s/%s/%d/%020d", item.Namespace, item.Group, item.FileName, active, item.Version)
+		}, batchSize, handle)
+	},
+	exists: func(s store.Store, item *model.ConfigFileRelease) (bool, error) {
+		release, err := s.GetConfigFileRelease(&model.ConfigFileReleaseKey{
+			Name:        item.Name,
+			Namespace:   item.Namespace,
+			Group:       item.Group,
+			FileName:    item.FileName,
+			ReleaseType: item.ReleaseType,
+		})
+		return release != nil, err
+	},
+	create: func(s store.Store, item *model.ConfigFileRelease) error {
+		return inTx(s, func(tx store.Tx) error {
+			if err := s.CreateConfigFileReleaseTx(tx, item); err != nil {
+				return err
+			}
+			if item.Active {
+				return nil
+			}
+			return s.InactiveConfigFileReleaseTx(tx, item)
+		})
+	},
+	// 先删除已经存在的同名发布记录，再按照归档中的内容、标签以及激活状态重新写入
+	update: func(s store.Store, item *model.ConfigFileRelease) error {
+		return inTx(s, func(tx store.Tx) error {
+			if err := s.DeleteConfigFileReleaseTx(tx, item.ConfigFileReleaseKey); err != nil {
+				return err
+			}
+			if err := s.CreateConfigFileReleaseTx(tx, item); err != nil {
+				return err
+			}
+			if item.Active {
+				return nil
+			}
+			return s.InactiveConfigFileReleaseTx(tx, item)
+		})
+	},
+	track: func(state *dumpState, item *model.ConfigFileRelease) bool {
+		_, ok := state.configFiles[item.Namespace+"/"+item.Group+"/"+item.FileName]
+		return ok
+	},
+}
+
+var configTemplateResource = &itemResource[*model.ConfigFileTemplate]{
+	kind: "configTemplate",
+	list: func(s store.Store, batchSize int, handle func([]*model.ConfigFileTemplate) error) error {
+		items, err := s.QueryAllConfigFileTemplates()
+		if err != nil {
+			return err
+		}
+		return listSorted(items, func(item *model.ConfigFileTemplate) bool {
+			return true
+		}, func(item *model.ConfigFileTemplate) string {
+			return item.Name
+		}, batchSize, handle)
+	},
+	exists: func(s store.Store, item *model.ConfigFileTemplate) (bool, error) {
+		template, err := s.GetConfigFileTemplate(item.Name)
+		return template != nil, err
+	},
+	create: func(s store.Store, item *model.ConfigFileTemplate) error {
+		_, err := s.CreateConfigFileTemplate(item)
+		return err
+	},
+}
+
+var userResource = &itemResource[*model.User]{
+	kind: "user",
+	list: func(s store.Store, batchSize int, handle func([]*model.User) error) error {
+		items, err := s.GetUsersForCache(fullSyncTime, true)
+		if err != nil {
+			return err
+		}
+		return listSorted(items, func(item *model.User) bool {
+			return item.Valid
+		}, func(item *model.User) string {
+			// 主账户先于子账户写入
+			return fmt.Sprintf("%03d/%s", item.Type, item.ID)
+		}, batchSize, handle)
+	},
+	exists: func(s store.Store, item *model.User) (bool, error) {
+		user, err := s.GetUser(item.ID)
+		return user != nil, err
+	},
+	create: func(s store.Store, item *model.User) error {
+		return s.AddUser(item)
+	},
+	update: func(s store.Store, item *model.User) error {
+		return s.UpdateUser(item)
+	},
+}
+
+var groupResource = &itemResource[*model.UserGroupDetail]{
+	kind: "group",
+	list: func(s store.Store, batchSize int, handle func([]*model.UserGroupDetail) error) error {
+		items, err := s.GetGroupsForCache(fullSyncTime, true)
+		if err != nil {
+			return err
+		}
+		return listSorted(items, func(item *model.UserGroupDetail) bool {
+			return item.Valid
+		}, func(item *model.UserGroupDetail) string {
+			return item.ID
+		}, batchSize, handle)
+	},
+	exists: func(s store.Store, item *model.UserGroupDetail) (bool, error) {
+		group, err := s.GetGroup(item.ID)
+		return group != nil, err
+	},
+	create: func(s store.Store, item *model.UserGroupDetail) error {
+		return s.AddGroup(item)
+	},
+	update: func(s store.Store, item *model.UserGroupDetail) error {
+		saved, err := s.GetGroup(item.ID)
+		if err != nil {
+			return err
+		}
+		modify := &model.ModifyUserGroup{
+			ID:          item.ID,
+			Owner:       item.Owner,
+			Token:       item.Token,
+			TokenEnable: item.TokenEnable,
+			Comment:     item.Comment,
+		}
+		for id := range item.UserIds {
+			if _, ok := saved.UserIds[id]; !ok {
+				modify.AddUserIds = append(modify.AddUserIds, id)
+			}
+		}
+		for id := range saved.UserIds {
+			if _, ok := item.UserIds[id]; !ok {
+				modify.RemoveUserIds = append(modify.RemoveUserIds, id)
+			}
+		}
+		return s.UpdateGroup(modify)
+	},
+}
+
+var strategyResource = &itemResource[*model.StrategyDetail]{
+	kind: "strategy",
+	list: func(s store.Store, batchSize int, handle func([]*model.StrategyDetail) error) error {
+		items, err := s.GetStrategyDetailsForCache(fullSyncTime, true)
+		if err != nil {
+			return err
+		}
+		return listSorted(items, func(item *model.StrategyDetail) bool {
+			return item.Valid
+		}, func(item *model.StrategyDetail) string {
+			return item.ID
+		}, batchSize, handle)
+	},
+	exists: func(s store.Store, item *model.StrategyDetail) (bool, error) {
+		strategy, err := s.GetStrategyDetail(item.ID)
+		return strategy != nil, err
+	},
+	create: func(s store.Store, item *model.StrategyDetail) error {
+		return s.AddStrategy(item)
+	},
+	update: func(s store.Store, item *model.StrategyDetail) error {
+		saved, err := s.GetStrategyDetail(item.ID)
+		if err != nil {
+			return err
+		}
+		modify := &model.ModifyStrategyDetail{
+			ID:         item.ID,
+			Name:       item.Name,
+			Action:     item.Action,
+			Comment:    item.Comment,
+			ModifyTime: time.Now(),
+		}
+		modify.AddPrincipals, modify.RemovePrincipals = diffSlice(item.Principals, saved.Principals,
+			func(p model.Principal) string {
+				return fmt.Sprintf("%d/%s", p.PrincipalRole, p.PrincipalID)
+			})
+		modify.AddResources, modify.RemoveResources = diffSlice(item.Resources, saved.Resources,
+			func(r model.StrategyResource) string {
+				return fmt.Sprintf("%d/%s", r.ResType, r.ResID)
+			})
+		return s.UpdateStrategy(modify)
+	},
+}
+
+// diffSlice 比较期望的数据以及已有的数据，返回需要新增以及需要删除的数据
+func diffSlice[T any](expect, saved []T, key func(T) string) ([]T, []T) {
+	expectKeys := make(map[string]struct{}, len(expect))
+	for _, item := range expect {
+		expectKeys[key(item)] = struct{}{}
+	}
+	savedKeys := make(map[string]struct{}, len(saved))
+	for _, item := range saved {
+		savedKeys[key(item)] = struct{}{}
+	}
+	var add, remove []T
+	for _, item := range expect {
+		if _, ok := savedKeys[key(item)]; !ok {
+			add = append(add, item)
+		}
+	}
+	for _, item := range saved {
+		if _, ok := expectKeys[key(item)]; !ok {
+			remove = append(remove, item)
+		}
+	}
+	return add, remove
+}
+
+var accessTokenResource = &itemResource[*model.AccessToken]{
+	kind: "accessToken",
+	list: func(s store.Store, batchSize int, handle func([]*model.AccessToken) error) error {
+		items, err := s.GetAccessTokensForCache(fullSyncTime, true)
+		if err != nil {
+			return err
+		}
+		return listSorted(items, func(item *model.AccessToken) bool {
+			return item.Valid
+		}, func(item *model.AccessToken) string {
+			return item.ID
+		}, batchSize, handle)
+	},
+	exists: func(s store.Store, item *model.AccessToken) (bool, error) {
+		token, err := s.GetAccessToken(item.ID)
+		return token != nil, err
+	},
+	create: func(s store.Store, item *model.AccessToken) error {
+		return s.AddAccessToken(item)
+	},
+}
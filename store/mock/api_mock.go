@@ -50,6 +50,20 @@ func (mr *MockStoreMockRecorder) ActiveConfigFileReleaseTx(tx, release interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActiveConfigFileReleaseTx", reflect.TypeOf((*MockStore)(nil).ActiveConfigFileReleaseTx), tx, release)
 }
 
+// AddAccessToken mocks base method.
+func (m *MockStore) AddAccessToken(token *model.AccessToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAccessToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAccessToken indicates an expected call of AddAccessToken.
+func (mr *MockStoreMockRecorder) AddAccessToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccessToken", reflect.TypeOf((*MockStore)(nil).AddAccessToken), token)
+}
+
 // AddGroup mocks base method.
 func (m *MockStore) AddGroup(group *model.UserGroupDetail) error {
 	m.ctrl.T.Helper()
@@ -92,6 +106,20 @@ func (mr *MockStoreMockRecorder) AddNamespace(namespace interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddNamespace", reflect.TypeOf((*MockStore)(nil).AddNamespace), namespace)
 }
 
+// AddOperationRecords mocks base method.
+func (m *MockStore) AddOperationRecords(records []*model.RecordEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOperationRecords", records)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddOperationRecords indicates an expected call of AddOperationRecords.
+func (mr *MockStoreMockRecorder) AddOperationRecords(records interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOperationRecords", reflect.TypeOf((*MockStore)(nil).AddOperationRecords), records)
+}
+
 // AddService mocks base method.
 func (m *MockStore) AddService(service *model.Service) error {
 	m.ctrl.T.Helper()
@@ -375,6 +403,21 @@ func (mr *MockStoreMockRecorder) CleanInstance(instanceID interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanInstance", reflect.TypeOf((*MockStore)(nil).CleanInstance), instanceID)
 }
 
+// CleanOperationRecords mocks base method.
+func (m *MockStore) CleanOperationRecords(endTime time.Time, limit uint64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanOperationRecords", endTime, limit)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CleanOperationRecords indicates an expected call of CleanOperationRecords.
+func (mr *MockStoreMockRecorder) CleanOperationRecords(endTime, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanOperationRecords", reflect.TypeOf((*MockStore)(nil).CleanOperationRecords), endTime, limit)
+}
+
 // CountConfigFileEachGroup mocks base method.
 func (m *MockStore) CountConfigFileEachGroup() (map[string]map[string]int64, error) {
 	m.ctrl.T.Helper()
@@ -634,6 +677,20 @@ func (mr *MockStoreMockRecorder) CreateTransaction() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockStore)(nil).CreateTransaction))
 }
 
+// DeleteAccessToken mocks base method.
+func (m *MockStore) DeleteAccessToken(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccessToken", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccessToken indicates an expected call of DeleteAccessToken.
+func (mr *MockStoreMockRecorder) DeleteAccessToken(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccessToken", reflect.TypeOf((*MockStore)(nil).DeleteAccessToken), id)
+}
+
 // DeleteCircuitBreakerRule mocks base method.
 func (m *MockStore) DeleteCircuitBreakerRule(id string) error {
 	m.ctrl.T.Helper()
@@ -943,6 +1000,52 @@ func (mr *MockStoreMockRecorder) GenNextL5Sid(layoutID interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenNextL5Sid", reflect.TypeOf((*MockStore)(nil).GenNextL5Sid), layoutID)
 }
 
+// GetAccessToken mocks base method.
+func (m *MockStore) GetAccessToken(id string) (*model.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessToken", id)
+	ret0, _ := ret[0].(*model.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessToken indicates an expected call of GetAccessToken.
+func (mr *MockStoreMockRecorder) GetAccessToken(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessToken", reflect.TypeOf((*MockStore)(nil).GetAccessToken), id)
+}
+
+// GetAccessTokens mocks base method.
+func (m *MockStore) GetAccessTokens(filters map[string]string, offset, limit uint32) (uint32, []*model.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessTokens", filters, offset, limit)
+	ret0, _ := ret[0].(uint32)
+	ret1, _ := ret[1].([]*model.AccessToken)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAccessTokens indicates an expected call of GetAccessTokens.
+func (mr *MockStoreMockRecorder) GetAccessTokens(filters, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessTokens", reflect.TypeOf((*MockStore)(nil).GetAccessTokens), filters, offset, limit)
+}
+
+// GetAccessTokensForCache mocks base method.
+func (m *MockStore) GetAccessTokensForCache(mtime time.Time, firstUpdate bool) ([]*model.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessTokensForCache", mtime, firstUpdate)
+	ret0, _ := ret[0].([]*model.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessTokensForCache indicates an expected call of GetAccessTokensForCache.
+func (mr *MockStoreMockRecorder) GetAccessTokensForCache(mtime, firstUpdate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessTokensForCache", reflect.TypeOf((*MockStore)(nil).GetAccessTokensForCache), mtime, firstUpdate)
+}
+
 // GetCircuitBreakerRules mocks base method.
 func (m *MockStore) GetCircuitBreakerRules(filter map[string]string, offset, limit uint32) (uint32, []*model.CircuitBreakerRule, error) {
 	m.ctrl.T.Helper()
@@ -1564,6 +1667,22 @@ func (mr *MockStoreMockRecorder) GetNamespaces(filter, offset, limit interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNamespaces", reflect.TypeOf((*MockStore)(nil).GetNamespaces), filter, offset, limit)
 }
 
+// GetOperationRecords mocks base method.
+func (m *MockStore) GetOperationRecords(filter map[string]string, offset, limit uint32) (uint32, []*model.RecordEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOperationRecords", filter, offset, limit)
+	ret0, _ := ret[0].(uint32)
+	ret1, _ := ret[1].([]*model.RecordEntry)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetOperationRecords indicates an expected call of GetOperationRecords.
+func (mr *MockStoreMockRecorder) GetOperationRecords(filter, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperationRecords", reflect.TypeOf((*MockStore)(nil).GetOperationRecords), filter, offset, limit)
+}
+
 // GetRateLimitWithID mocks base method.
 func (m *MockStore) GetRateLimitWithID(id string) (*model.RateLimit, error) {
 	m.ctrl.T.Helper()
@@ -2357,6 +2476,20 @@ func (mr *MockStoreMockRecorder) StartTx() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartTx", reflect.TypeOf((*MockStore)(nil).StartTx))
 }
 
+// UpdateAccessTokensLastUsed mocks base method.
+func (m *MockStore) UpdateAccessTokensLastUsed(lastUsed map[string]time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccessTokensLastUsed", lastUsed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAccessTokensLastUsed indicates an expected call of UpdateAccessTokensLastUsed.
+func (mr *MockStoreMockRecorder) UpdateAccessTokensLastUsed(lastUsed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccessTokensLastUsed", reflect.TypeOf((*MockStore)(nil).UpdateAccessTokensLastUsed), lastUsed)
+}
+
 // UpdateCircuitBreakerRule mocks base method.
 func (m *MockStore) UpdateCircuitBreakerRule(cbRule *model.CircuitBreakerRule) error {
 	m.ctrl.T.Helper()
@@ -2385,6 +2518,34 @@ func (mr *MockStoreMockRecorder) UpdateConfigFileGroup(fileGroup interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConfigFileGroup", reflect.TypeOf((*MockStore)(nil).UpdateConfigFileGroup), fileGroup)
 }
 
+// UpdateConfigFileReleaseHistoryMetadata mocks base method.
+func (m *MockStore) UpdateConfigFileReleaseHistoryMetadata(history *model.ConfigFileReleaseHistory) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateConfigFileReleaseHistoryMetadata", history)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateConfigFileReleaseHistoryMetadata indicates an expected call of UpdateConfigFileReleaseHistoryMetadata.
+func (mr *MockStoreMockRecorder) UpdateConfigFileReleaseHistoryMetadata(history interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConfigFileReleaseHistoryMetadata", reflect.TypeOf((*MockStore)(nil).UpdateConfigFileReleaseHistoryMetadata), history)
+}
+
+// UpdateConfigFileReleaseMetadataTx mocks base method.
+func (m *MockStore) UpdateConfigFileReleaseMetadataTx(tx store.Tx, release *model.ConfigFileRelease) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateConfigFileReleaseMetadataTx", tx, release)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateConfigFileReleaseMetadataTx indicates an expected call of UpdateConfigFileReleaseMetadataTx.
+func (mr *MockStoreMockRecorder) UpdateConfigFileReleaseMetadataTx(tx, release interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConfigFileReleaseMetadataTx", reflect.TypeOf((*MockStore)(nil).UpdateConfigFileReleaseMetadataTx), tx, release)
+}
+
 // UpdateConfigFileTx mocks base method.
 func (m *MockStore) UpdateConfigFileTx(tx store.Tx, file *model.ConfigFile) error {
 	m.ctrl.T.Helper()
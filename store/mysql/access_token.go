@@ -0,0 +1,211 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package sqldb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+// accessTokenAttributeMapping 个人访问令牌查询条件与列的映射
+var accessTokenAttributeMapping = map[string]string{
+	"principal_id":   "principal_id",
+	"principal_type": "principal_type",
+	"owner":          "owner",
+}
+
+const accessTokenColumns = "id, name, principal_id, principal_type, owner, secret, scope, read_only, " +
+	" IFNULL(UNIX_TIMESTAMP(expire_time), 0), IFNULL(UNIX_TIMESTAMP(last_used_time), 0), flag, " +
+	" UNIX_TIMESTAMP(ctime), UNIX_TIMESTAMP(mtime)"
+
+type accessTokenStore struct {
+	master *BaseDB
+	slave  *BaseDB
+}
+
+// AddAccessToken 创建个人访问令牌
+func (a *accessTokenStore) AddAccessToken(token *model.AccessToken) error {
+	if token.ID == "" || token.PrincipalID == "" || token.Secret == "" {
+		return store.NewStatusError(store.EmptyParamsErr, "add access token missing some params")
+	}
+	var scope string
+	if !token.Scope.IsEmpty() {
+		data, err := json.Marshal(token.Scope)
+		if err != nil {
+			return store.Error(err)
+		}
+		scope = string(data)
+	}
+	var expireTime interface{}
+	if !token.ExpireTime.IsZero() {
+		expireTime = timeToTimestamp(token.ExpireTime)
+	}
+
+	addSql := "INSERT INTO access_token(id, name, principal_id, principal_type, owner, secret, scope, " +
+		" read_only, expire_time, flag, ctime, mtime) VALUES (?, ?, ?, ?, ?, ?, ?, ?, FROM_UNIXTIME(?), 0, " +
+		" sysdate(), sysdate())"
+	if _, err := a.master.Exec(addSql, token.ID, token.Name, token.PrincipalID, int(token.PrincipalType),
+		token.Owner, token.Secret, scope, boolToInt(token.ReadOnly), expireTime); err != nil {
+		log.Error("[Store][AccessToken] add access token", zap.String("id", token.ID), zap.Error(err))
+		return store.Error(err)
+	}
+	return nil
+}
+
+// DeleteAccessToken 吊销个人访问令牌
+func (a *accessTokenStore) DeleteAccessToken(id string) error {
+	if _, err := a.master.Exec("UPDATE access_token SET flag = 1, mtime = sysdate() WHERE id = ?", id); err != nil {
+		log.Error("[Store][AccessToken] delete access token", zap.String("id", id), zap.Error(err))
+		return store.Error(err)
+	}
+	return nil
+}
+
+// GetAccessToken 获取个人访问令牌
+func (a *accessTokenStore) GetAccessToken(id string) (*model.AccessToken, error) {
+	rows, err := a.master.Query("SELECT "+accessTokenColumns+" FROM access_token WHERE id = ? AND flag = 0", id)
+	if err != nil {
+		return nil, store.Error(err)
+	}
+	tokens, err := a.transferRows(rows)
+	if err != nil {
+		return nil, store.Error(err)
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	return tokens[0], nil
+}
+
+// GetAccessTokens 查询个人访问令牌，按照创建时间倒序
+func (a *accessTokenStore) GetAccessTokens(filters map[string]string,
+	offset uint32, limit uint32) (uint32, []*model.AccessToken, error) {
+	conditions := []string{"flag = 0"}
+	args := make([]interface{}, 0, len(filters))
+	for key, column := range accessTokenAttributeMapping {
+		if val, ok := filters[key]; ok {
+			conditions = append(conditions, column+" = ?")
+			args = append(args, val)
+		}
+	}
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var count uint32
+	if err := a.slave.QueryRow("SELECT COUNT(*) FROM access_token"+where, args...).Scan(&count); err != nil {
+		return 0, nil, store.Error(err)
+	}
+	args = append(args, offset, limit)
+	rows, err := a.slave.Query("SELECT "+accessTokenColumns+" FROM access_token"+where+
+		" ORDER BY ctime DESC, id LIMIT ?, ?", args...)
+	if err != nil {
+		return 0, nil, store.Error(err)
+	}
+	tokens, err := a.transferRows(rows)
+	if err != nil {
+		return 0, nil, store.Error(err)
+	}
+	return count, tokens, nil
+}
+
+// UpdateAccessTokensLastUsed 记录个人访问令牌的最后使用时间，显式保持 mtime 不变
+func (a *accessTokenStore) UpdateAccessTokensLastUsed(lastUsed map[string]time.Time) error {
+	if len(lastUsed) == 0 {
+		return nil
+	}
+	err := RetryTransaction("updateAccessTokensLastUsed", func() error {
+		tx, err := a.master.Begin()
+		if err != nil {
+			return err
+		}
+		defer func() { _ = tx.Rollback() }()
+		for id, usedTime := range lastUsed {
+			if _, err := tx.Exec("UPDATE access_token SET last_used_time = FROM_UNIXTIME(?), mtime = mtime "+
+				" WHERE id = ?", timeToTimestamp(usedTime), id); err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
+	})
+	return store.Error(err)
+}
+
+// GetAccessTokensForCache 获取增量更新的个人访问令牌
+func (a *accessTokenStore) GetAccessTokensForCache(mtime time.Time,
+	firstUpdate bool) ([]*model.AccessToken, error) {
+	querySql := "SELECT " + accessTokenColumns + " FROM access_token "
+	args := make([]interface{}, 0, 1)
+	if !firstUpdate {
+		querySql += " WHERE mtime >= FROM_UNIXTIME(?)"
+		args = append(args, timeToTimestamp(mtime))
+	}
+	rows, err := a.master.Query(querySql, args...)
+	if err != nil {
+		log.Error("[Store][AccessToken] get access tokens for cache", zap.Error(err))
+		return nil, store.Error(err)
+	}
+	return a.transferRows(rows)
+}
+
+func (a *accessTokenStore) transferRows(rows *sql.Rows) ([]*model.AccessToken, error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	tokens := make([]*model.AccessToken, 0, 4)
+	for rows.Next() {
+		var (
+			token                                  = &model.AccessToken{}
+			scope                                  string
+			principalType, readOnly, flag          int
+			expireTime, lastUsedTime, ctime, mtime int64
+		)
+		if err := rows.Scan(&token.ID, &token.Name, &token.PrincipalID, &principalType, &token.Owner,
+			&token.Secret, &scope, &readOnly, &expireTime, &lastUsedTime, &flag, &ctime, &mtime); err != nil {
+			return nil, err
+		}
+		token.PrincipalType = model.PrincipalType(principalType)
+		token.ReadOnly = readOnly == 1
+		token.Valid = flag == 0
+		if expireTime > 0 {
+			token.ExpireTime = time.Unix(expireTime, 0)
+		}
+		if lastUsedTime > 0 {
+			token.LastUsedTime = time.Unix(lastUsedTime, 0)
+		}
+		token.CreateTime = time.Unix(ctime, 0)
+		token.ModifyTime = time.Unix(mtime, 0)
+		if scope != "" {
+			token.Scope = &model.AccessTokenScope{}
+			if err := json.Unmarshal([]byte(scope), token.Scope); err != nil {
+				log.Error("[Store][AccessToken] unmarshal token scope", zap.String("id", token.ID), zap.Error(err))
+			}
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
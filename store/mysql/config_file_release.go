@@ -150,6 +150,24 @@ func (cfr *configFileReleaseStore) CleanConfigFileReleasesTx(tx store.Tx,
 	return nil
 }
 
+// UpdateConfigFileReleaseMetadataTx 更新配置文件发布的标签
+func (cfr *configFileReleaseStore) UpdateConfigFileReleaseMetadataTx(tx store.Tx,
+	release *model.ConfigFileRelease) error {
+	if tx == nil {
+		return ErrTxIsNil
+	}
+
+	dbTx := tx.GetDelegateTx().(*BaseTx)
+	s := "UPDATE config_file_release SET tags = ?, modify_time = sysdate() WHERE namespace = ? " +
+		" AND `group` = ? AND file_name = ? AND name = ?"
+	_, err := dbTx.Exec(s, utils.MustJson(release.Metadata), release.Namespace, release.Group,
+		release.FileName, release.Name)
+	if err != nil {
+		return store.Error(err)
+	}
+	return nil
+}
+
 // CleanDeletedConfigFileRelease 清理配置发布历史
 func (cfr *configFileReleaseStore) CleanDeletedConfigFileRelease(endTime time.Time, limit uint64) error {
 	delSql := "DELETE FROM config_file_release WHERE modify_time < ? AND flag = 1 LIMIT ?"
@@ -109,6 +109,14 @@ func (rh *configFileReleaseHistoryStore) CleanConfigFileReleaseHistory(endTime t
 	return err
 }
 
+// UpdateConfigFileReleaseHistoryMetadata 更新配置发布历史的标签
+func (rh *configFileReleaseHistoryStore) UpdateConfigFileReleaseHistoryMetadata(
+	history *model.ConfigFileReleaseHistory) error {
+	s := "UPDATE config_file_release_history SET tags = ? WHERE id = ?"
+	_, err := rh.master.Exec(s, utils.MustJson(history.Metadata), history.Id)
+	return store.Error(err)
+}
+
 func (rh *configFileReleaseHistoryStore) genSelectSql() string {
 	return "SELECT id, name, namespace, `group`, file_name, content, IFNULL(comment, ''), " +
 		" md5, format, tags, type, status, UNIX_TIMESTAMP(create_time), IFNULL(create_by, ''), " +
@@ -68,9 +68,11 @@ type stableStore struct {
 
 	*clientStore
 	*adminStore
+	*operationRecordStore
 	*toolStore
 	*userStore
 	*groupStore
+	*accessTokenStore
 	*strategyStore
 	*grayStore
 
@@ -102,6 +104,19 @@ func (s *stableStore) Initialize(conf *store.Config) error {
 	}
 	s.master = master
 
+	migrateConfig, err := parseMigrateConfig(conf.Option)
+	if err != nil {
+		return err
+	}
+	migrator, err := newSchemaMigrator(master, migrateConfig)
+	if err != nil {
+		return err
+	}
+	if err := migrator.migrate(); err != nil {
+		log.Errorf("[Store][database] migrate database schema err: %s", err.Error())
+		return err
+	}
+
 	if slaveConfig != nil {
 		log.Infof("[Store][database] use slave database config: %+v", slaveConfig)
 		slave, err := NewBaseDB(slaveConfig, plugin.GetParsePassword())
@@ -269,9 +284,11 @@ func (s *stableStore) newStore() {
 	s.clientStore = &clientStore{master: s.master, slave: s.slave}
 
 	s.adminStore = newAdminStore(s.master)
+	s.operationRecordStore = &operationRecordStore{master: s.master, slave: s.slave}
 	s.toolStore = &toolStore{db: s.master}
 	s.userStore = &userStore{master: s.master, slave: s.slave}
 	s.groupStore = &groupStore{master: s.master, slave: s.slave}
+	s.accessTokenStore = &accessTokenStore{master: s.master, slave: s.slave}
 	s.strategyStore = &strategyStore{master: s.master, slave: s.slave}
 	s.grayStore = &grayStore{master: s.master, slave: s.slave}
 }
@@ -0,0 +1,518 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package sqldb
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+// deltaScripts 各个版本之间的增量升级脚本
+//
+//go:embed scripts/delta/*.sql
+var deltaScripts embed.FS
+
+const (
+	// deltaScriptDir 增量脚本所在的目录
+	deltaScriptDir = "scripts/delta"
+	// defaultMigrateLockKey 执行升级时使用的启动锁，需要在 start_lock 表中存在
+	defaultMigrateLockKey = "sz"
+	// errNoSuchTable mysql 表不存在的错误码
+	errNoSuchTable = 1146
+
+	createSchemaVersionSQL = "CREATE TABLE IF NOT EXISTS `schema_version` (" +
+		"`version` VARCHAR(32) NOT NULL COMMENT 'schema version', " +
+		"`script` VARCHAR(128) NOT NULL DEFAULT '' COMMENT 'delta script', " +
+		"`checksum` VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'sha256 of the delta script', " +
+		"`applied` INT NOT NULL DEFAULT 0 COMMENT 'number of applied statements of a dirty script', " +
+		"`dirty` TINYINT(4) NOT NULL DEFAULT 0 COMMENT 'whether the delta script is partially applied', " +
+		"`applied_time` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'applied time', " +
+		"PRIMARY KEY (`version`)) ENGINE = InnoDB COMMENT = 'schema version'"
+	listSchemaVersionSQL  = "SELECT version, script, checksum, applied, dirty FROM schema_version"
+	startSchemaVersionSQL = "INSERT INTO schema_version (version, script, checksum, applied, dirty) " +
+		"VALUES (?, ?, ?, 0, 1) ON DUPLICATE KEY UPDATE script = VALUES(script), checksum = VALUES(checksum), dirty = 1"
+	progressSchemaVersionSQL = "UPDATE schema_version SET applied = ? WHERE version = ?"
+	finishSchemaVersionSQL   = "UPDATE schema_version SET applied = 0, dirty = 0, " +
+		"applied_time = CURRENT_TIMESTAMP WHERE version = ?"
+)
+
+var deltaNameRegex = regexp.MustCompile(`^v(\d+)_(\d+)_(\d+)-v(\d+)_(\d+)_(\d+)\.sql$`)
+
+// migrateConfig 数据库表结构升级配置
+type migrateConfig struct {
+	// Enable 启动时自动执行未应用的增量脚本
+	Enable bool `mapstructure:"enable"`
+	// DryRun 只在日志中输出升级计划，不执行
+	DryRun bool `mapstructure:"dryRun"`
+	// LockKey 升级期间持有的启动锁
+	LockKey string `mapstructure:"lockKey"`
+	// Baseline 尚未记录 schema_version 的存量数据库当前所处的版本，例如 1.18.0
+	Baseline string `mapstructure:"baseline"`
+}
+
+func parseMigrateConfig(opt map[string]interface{}) (*migrateConfig, error) {
+	conf := &migrateConfig{}
+	if raw, ok := opt["migrate"]; ok && raw != nil {
+		if err := mapstructure.Decode(raw, conf); err != nil {
+			return nil, fmt.Errorf("config Plugin %s:migrate is invalid: %w", STORENAME, err)
+		}
+	}
+	if conf.LockKey == "" {
+		conf.LockKey = defaultMigrateLockKey
+	}
+	if conf.Baseline != "" {
+		if _, err := parseSchemaVersion(conf.Baseline); err != nil {
+			return nil, err
+		}
+	}
+	return conf, nil
+}
+
+// schemaVersion 表结构版本号，major.minor.patch
+type schemaVersion [3]int
+
+func parseSchemaVersion(s string) (schemaVersion, error) {
+	var v schemaVersion
+	items := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(items) != 3 {
+		return v, fmt.Errorf("invalid schema version: %s", s)
+	}
+	for i := range items {
+		n, err := strconv.Atoi(items[i])
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid schema version: %s", s)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+func (v schemaVersion) compare(o schemaVersion) int {
+	for i := range v {
+		if v[i] != o[i] {
+			if v[i] < o[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func (v schemaVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+// deltaScript 一个增量升级脚本
+type deltaScript struct {
+	name       string
+	from       schemaVersion
+	to         schemaVersion
+	checksum   string
+	statements []string
+}
+
+// loadDeltaScripts 加载内置的增量脚本，并校验脚本之间是首尾相连的
+func loadDeltaScripts(fs embed.FS, dir string) ([]*deltaScript, error) {
+	entries, err := fs.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	scripts := make([]*deltaScript, 0, len(entries))
+	for _, entry := range entries {
+		matches := deltaNameRegex.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		from, _ := parseSchemaVersion(strings.Join(matches[1:4], "."))
+		to, _ := parseSchemaVersion(strings.Join(matches[4:7], "."))
+		content, err := fs.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(content)
+		scripts = append(scripts, &deltaScript{
+			name:       entry.Name(),
+			from:       from,
+			to:         to,
+			checksum:   hex.EncodeToString(sum[:]),
+			statements: splitStatements(string(content)),
+		})
+	}
+	if len(scripts) == 0 {
+		return nil, errors.New("no schema delta script found")
+	}
+	sort.Slice(scripts, func(i, j int) bool {
+		return scripts[i].from.compare(scripts[j].from) < 0
+	})
+	for i := 1; i < len(scripts); i++ {
+		if scripts[i-1].to != scripts[i].from {
+			return nil, fmt.Errorf("schema delta scripts are not continuous between %s and %s",
+				scripts[i-1].name, scripts[i].name)
+		}
+	}
+	return scripts, nil
+}
+
+// splitStatements 按照分号拆分 sql 脚本，忽略注释以及 USE 语句，库名以配置的 dbName 为准
+func splitStatements(content string) []string {
+	var (
+		statements []string
+		buf        strings.Builder
+		quote      byte
+	)
+	flush := func() {
+		stmt := strings.TrimSpace(buf.String())
+		buf.Reset()
+		if stmt == "" {
+			return
+		}
+		if fields := strings.Fields(stmt); strings.EqualFold(fields[0], "USE") {
+			return
+		}
+		statements = append(statements, stmt)
+	}
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if quote != 0 {
+			buf.WriteByte(c)
+			if c == '\\' && quote != '`' && i+1 < len(content) {
+				i++
+				buf.WriteByte(content[i])
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			buf.WriteByte(c)
+		case c == '#' || (c == '-' && strings.HasPrefix(content[i:], "-- ")) ||
+			(c == '-' && strings.HasPrefix(content[i:], "--\n")):
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				i = len(content)
+			} else {
+				i += end
+				buf.WriteByte('\n')
+			}
+		case c == '/' && strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				i = len(content)
+			} else {
+				i += end + 3
+				buf.WriteByte(' ')
+			}
+		case c == ';':
+			flush()
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	flush()
+	return statements
+}
+
+// migrationPlan 升级计划
+type migrationPlan struct {
+	// current 数据库当前的版本，为空表示未知
+	current *schemaVersion
+	// latest 当前程序支持的最新版本
+	latest schemaVersion
+	// dirty 上一次执行失败、只执行了一部分的增量脚本
+	dirty *appliedVersion
+	// pending 待执行的增量脚本
+	pending []*deltaScript
+}
+
+// resume 第一个待执行脚本中已经执行过的语句数量
+func (p *migrationPlan) resume() int {
+	if p.dirty == nil {
+		return 0
+	}
+	return p.dirty.applied
+}
+
+func (p *migrationPlan) String() string {
+	current := "unknown"
+	if p.current != nil {
+		current = p.current.String()
+	}
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "schema version %s, binary version %s, %d delta script(s) pending",
+		current, p.latest, len(p.pending))
+	if p.dirty != nil {
+		_, _ = fmt.Fprintf(&sb, ", %s is dirty with %d statement(s) applied", p.dirty.script, p.dirty.applied)
+	}
+	for i, script := range p.pending {
+		statements := script.statements
+		if i == 0 {
+			statements = statements[p.resume():]
+		}
+		_, _ = fmt.Fprintf(&sb, "\n-- %s (%s -> %s), %d statement(s)", script.name, script.from, script.to,
+			len(statements))
+		for _, stmt := range statements {
+			_, _ = fmt.Fprintf(&sb, "\n%s;", stmt)
+		}
+	}
+	return sb.String()
+}
+
+// appliedVersion schema_version 中的一条版本记录
+type appliedVersion struct {
+	version  schemaVersion
+	script   string
+	checksum string
+	// applied 脚本未执行完成时已经执行成功的语句数量
+	applied int
+	// dirty 脚本是否只执行了一部分
+	dirty bool
+}
+
+// schemaMigrator 数据库表结构升级执行器
+type schemaMigrator struct {
+	master  *BaseDB
+	conf    *migrateConfig
+	scripts []*deltaScript
+}
+
+func newSchemaMigrator(master *BaseDB, conf *migrateConfig) (*schemaMigrator, error) {
+	scripts, err := loadDeltaScripts(deltaScripts, deltaScriptDir)
+	if err != nil {
+		return nil, err
+	}
+	return &schemaMigrator{master: master, conf: conf, scripts: scripts}, nil
+}
+
+// latest 当前程序支持的最新表结构版本
+func (m *schemaMigrator) latest() schemaVersion {
+	return m.scripts[len(m.scripts)-1].to
+}
+
+// appliedVersions 查询 schema_version 中的全部版本记录，schema_version 不存在时返回空
+func (m *schemaMigrator) appliedVersions() ([]*appliedVersion, error) {
+	rows, err := m.master.Query(listSchemaVersionSQL)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == errNoSuchTable {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*appliedVersion
+	for rows.Next() {
+		var (
+			item   string
+			record = &appliedVersion{}
+		)
+		if err := rows.Scan(&item, &record.script, &record.checksum, &record.applied, &record.dirty); err != nil {
+			return nil, err
+		}
+		if record.version, err = parseSchemaVersion(item); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// verifyChecksum 校验已经执行过的增量脚本没有被修改过，初始化脚本以及基线没有记录 checksum，不做校验
+func (m *schemaMigrator) verifyChecksum(record *appliedVersion) error {
+	if record.checksum == "" {
+		return nil
+	}
+	for _, script := range m.scripts {
+		if script.name != record.script {
+			continue
+		}
+		if script.checksum != record.checksum {
+			return fmt.Errorf("checksum of schema delta script %s mismatch, the script has been modified "+
+				"after it was applied to version %s", script.name, record.version)
+		}
+		return nil
+	}
+	return nil
+}
+
+// plan 计算升级计划，数据库版本比程序新、已执行脚本的 checksum 不一致时拒绝启动
+func (m *schemaMigrator) plan() (*migrationPlan, error) {
+	records, err := m.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+	plan := &migrationPlan{latest: m.latest()}
+	for _, record := range records {
+		if err := m.verifyChecksum(record); err != nil {
+			return nil, err
+		}
+		if record.dirty {
+			if plan.dirty != nil {
+				return nil, fmt.Errorf("more than one dirty schema version: %s, %s",
+					plan.dirty.version, record.version)
+			}
+			plan.dirty = record
+			continue
+		}
+		if plan.current == nil || record.version.compare(*plan.current) > 0 {
+			v := record.version
+			plan.current = &v
+		}
+	}
+
+	// 上一次升级中途失败，从失败的脚本继续执行
+	if plan.dirty != nil {
+		for i, script := range m.scripts {
+			if script.name == plan.dirty.script {
+				plan.pending = m.scripts[i:]
+				return plan, nil
+			}
+		}
+		return nil, fmt.Errorf("schema version %s is dirty, but delta script %s is unknown",
+			plan.dirty.version, plan.dirty.script)
+	}
+
+	current := plan.current
+	if current == nil && m.conf.Baseline != "" {
+		baseline, _ := parseSchemaVersion(m.conf.Baseline)
+		current = &baseline
+	}
+	if current == nil {
+		return plan, nil
+	}
+	if current.compare(plan.latest) > 0 {
+		return nil, fmt.Errorf("database schema version %s is newer than %s supported by this server",
+			current, plan.latest)
+	}
+	start := -1
+	for i, script := range m.scripts {
+		if script.from == *current {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		if *current != plan.latest {
+			return nil, fmt.Errorf("no schema delta script starts from version %s", current)
+		}
+		return plan, nil
+	}
+	plan.pending = m.scripts[start:]
+	return plan, nil
+}
+
+// migrate 启动时检查表结构版本，按照配置打印或者执行升级计划
+func (m *schemaMigrator) migrate() error {
+	plan, err := m.plan()
+	if err != nil {
+		return err
+	}
+	if m.conf.DryRun {
+		log.Infof("[Store][database] schema migration plan (dry run):\n%s", plan)
+		return nil
+	}
+	if !m.conf.Enable {
+		if plan.dirty != nil {
+			log.Warnf("[Store][database] delta script %s is partially applied, %d statement(s) applied",
+				plan.dirty.script, plan.dirty.applied)
+		} else if plan.current == nil {
+			log.Warnf("[Store][database] schema_version is missing, can not check the database schema version")
+		} else if len(plan.pending) > 0 {
+			log.Warnf("[Store][database] database schema %s is older than %s, %d delta script(s) not applied",
+				plan.current, plan.latest, len(plan.pending))
+		}
+		return nil
+	}
+	if plan.current == nil && plan.dirty == nil && m.conf.Baseline == "" {
+		return errors.New("schema_version is missing, set store option migrate.baseline to " +
+			"the version of the existing database schema")
+	}
+	if len(plan.pending) == 0 {
+		return nil
+	}
+
+	// 持有启动锁，避免多个节点同时执行升级
+	lockTx, err := m.master.Begin()
+	if err != nil {
+		return err
+	}
+	lock := &transaction{tx: lockTx}
+	defer func() {
+		_ = lock.Commit()
+	}()
+	if err := lock.LockBootstrap(m.conf.LockKey, utils.LocalHost); err != nil {
+		return err
+	}
+	// 拿到锁之后重新计算，其他节点可能已经完成了升级
+	if _, err := m.master.Exec(createSchemaVersionSQL); err != nil {
+		return err
+	}
+	if plan, err = m.plan(); err != nil {
+		return err
+	}
+	for i, script := range plan.pending {
+		applied := 0
+		if i == 0 {
+			applied = plan.resume()
+		}
+		if err := m.apply(script, applied); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply 执行增量脚本中从 applied 开始的语句。mysql 的 DDL 语句会隐式提交，无法在事务中回滚，
+// 因此执行前先将版本标记为 dirty，每执行成功一条语句记录一次进度，执行失败后再次启动时从失败的语句继续执行
+func (m *schemaMigrator) apply(script *deltaScript, applied int) error {
+	log.Infof("[Store][database] apply schema delta script %s (%s -> %s) from statement %d",
+		script.name, script.from, script.to, applied+1)
+	version := script.to.String()
+	if _, err := m.master.Exec(startSchemaVersionSQL, version, script.name, script.checksum); err != nil {
+		return err
+	}
+	for i := applied; i < len(script.statements); i++ {
+		stmt := script.statements[i]
+		if _, err := m.master.Exec(stmt); err != nil {
+			log.Errorf("[Store][database] apply schema delta script %s, statement %q err: %s",
+				script.name, stmt, err.Error())
+			return fmt.Errorf("apply schema delta script %s, statement %d: %w", script.name, i+1, err)
+		}
+		if _, err := m.master.Exec(progressSchemaVersionSQL, i+1, version); err != nil {
+			return err
+		}
+	}
+	_, err := m.master.Exec(finishSchemaVersionSQL, version)
+	return err
+}
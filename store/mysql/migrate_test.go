@@ -0,0 +1,226 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package sqldb
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_splitStatements(t *testing.T) {
+	content := `/* header; with semicolon */
+-- Database: polaris_server;
+USE ` + "`polaris_server`" + `;
+
+# comment
+ALTER TABLE ` + "`a`" + ` ADD COLUMN ` + "`b`" + ` varchar(32) COMMENT 'x;y';
+INSERT INTO t (c) VALUES ('it\'s; -- not a comment', "d;e");
+SELECT 1`
+	stmts := splitStatements(content)
+	assert.Equal(t, []string{
+		"ALTER TABLE `a` ADD COLUMN `b` varchar(32) COMMENT 'x;y'",
+		`INSERT INTO t (c) VALUES ('it\'s; -- not a comment', "d;e")`,
+		"SELECT 1",
+	}, stmts)
+}
+
+func Test_loadDeltaScripts(t *testing.T) {
+	scripts, err := loadDeltaScripts(deltaScripts, deltaScriptDir)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1_6_0-v1_7_0.sql", scripts[0].name)
+	for i := range scripts {
+		assert.NotEmpty(t, scripts[i].statements, scripts[i].name)
+		assert.Len(t, scripts[i].checksum, 64)
+		for _, stmt := range scripts[i].statements {
+			assert.False(t, strings.HasPrefix(strings.ToUpper(stmt), "USE"), stmt)
+		}
+		if i > 0 {
+			assert.Equal(t, scripts[i-1].to, scripts[i].from)
+		}
+	}
+	assert.Equal(t, "1.19.0", scripts[len(scripts)-1].to.String())
+}
+
+func newTestMigrator(t *testing.T, conf *migrateConfig) (*schemaMigrator, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	if conf.LockKey == "" {
+		conf.LockKey = defaultMigrateLockKey
+	}
+	m, err := newSchemaMigrator(&BaseDB{DB: db}, conf)
+	assert.NoError(t, err)
+	return m, mock
+}
+
+func Test_schemaMigrator_plan(t *testing.T) {
+	t.Run("表结构比程序新", func(t *testing.T) {
+		m, mock := newTestMigrator(t, &migrateConfig{Enable: true})
+		mock.ExpectQuery(listSchemaVersionSQL).WillReturnRows(
+			schemaVersionRows().AddRow("1.18.0", "", "", 0, false).AddRow("9.0.0", "", "", 0, false))
+		err := m.migrate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "newer")
+	})
+
+	t.Run("表结构已是最新", func(t *testing.T) {
+		m, mock := newTestMigrator(t, &migrateConfig{Enable: true})
+		mock.ExpectQuery(listSchemaVersionSQL).WillReturnRows(
+			schemaVersionRows().AddRow("1.19.0", "", "", 0, false))
+		assert.NoError(t, m.migrate())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("缺少版本表且没有设置基线", func(t *testing.T) {
+		m, mock := newTestMigrator(t, &migrateConfig{Enable: true})
+		mock.ExpectQuery(listSchemaVersionSQL).WillReturnError(&mysql.MySQLError{Number: errNoSuchTable})
+		err := m.migrate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "baseline")
+	})
+
+	t.Run("未开启升级时只做检查", func(t *testing.T) {
+		m, mock := newTestMigrator(t, &migrateConfig{})
+		mock.ExpectQuery(listSchemaVersionSQL).WillReturnRows(
+			schemaVersionRows().AddRow("1.17.0", "", "", 0, false))
+		assert.NoError(t, m.migrate())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("根据基线计算待执行脚本", func(t *testing.T) {
+		m, mock := newTestMigrator(t, &migrateConfig{DryRun: true, Baseline: "1.17.3"})
+		mock.ExpectQuery(listSchemaVersionSQL).WillReturnError(&mysql.MySQLError{Number: errNoSuchTable})
+		plan, err := m.plan()
+		assert.NoError(t, err)
+		assert.Nil(t, plan.current)
+		assert.Len(t, plan.pending, 2)
+		assert.Equal(t, "v1_17_3-v1_18_0.sql", plan.pending[0].name)
+		assert.Contains(t, plan.String(), "2 delta script(s) pending")
+	})
+
+	t.Run("未知的版本", func(t *testing.T) {
+		m, mock := newTestMigrator(t, &migrateConfig{Enable: true})
+		mock.ExpectQuery(listSchemaVersionSQL).WillReturnRows(
+			schemaVersionRows().AddRow("1.9.0", "", "", 0, false))
+		_, err := m.plan()
+		assert.Error(t, err)
+	})
+}
+
+func Test_schemaMigrator_migrate(t *testing.T) {
+	m, mock := newTestMigrator(t, &migrateConfig{Enable: true})
+	pending := m.scripts[len(m.scripts)-1]
+
+	mock.ExpectQuery(listSchemaVersionSQL).WillReturnRows(schemaVersionRows().AddRow("1.18.0", "", "", 0, false))
+	mock.ExpectBegin()
+	mock.ExpectQuery("select count(*) from start_lock where lock_key = ?").WithArgs(defaultMigrateLockKey).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+	mock.ExpectExec("update start_lock set server = ? where lock_id = ? and lock_key = ?").
+		WithArgs(sqlmock.AnyArg(), 1, defaultMigrateLockKey).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(createSchemaVersionSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+	// 拿到锁之后重新检查版本
+	mock.ExpectQuery(listSchemaVersionSQL).WillReturnRows(schemaVersionRows().AddRow("1.18.0", "", "", 0, false))
+	mock.ExpectExec(startSchemaVersionSQL).WithArgs("1.19.0", pending.name, pending.checksum).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	for i, stmt := range pending.statements {
+		mock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(progressSchemaVersionSQL).WithArgs(i+1, "1.19.0").WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(finishSchemaVersionSQL).WithArgs("1.19.0").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, m.migrate())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_schemaMigrator_migrateFailed(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	assert.NoError(t, err)
+	defer db.Close()
+	m, err := newSchemaMigrator(&BaseDB{DB: db}, &migrateConfig{Enable: true, LockKey: "sz"})
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta(listSchemaVersionSQL)).
+		WillReturnRows(schemaVersionRows().AddRow("1.18.0", "", "", 0, false))
+	mock.ExpectBegin()
+	mock.ExpectQuery("select count").WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+	mock.ExpectExec("update start_lock").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS `schema_version`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(listSchemaVersionSQL)).
+		WillReturnRows(schemaVersionRows().AddRow("1.18.0", "", "", 0, false))
+	mock.ExpectExec(regexp.QuoteMeta(startSchemaVersionSQL)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(".*").WillReturnError(&mysql.MySQLError{Number: 1050, Message: "table exists"})
+	mock.ExpectCommit()
+
+	err = m.migrate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "v1_18_0-v1_19_0.sql")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_schemaMigrator_resumeDirty(t *testing.T) {
+	m, mock := newTestMigrator(t, &migrateConfig{Enable: true})
+	pending := m.scripts[len(m.scripts)-1]
+	applied := len(pending.statements) - 1
+
+	rows := func() *sqlmock.Rows {
+		return schemaVersionRows().AddRow("1.18.0", "", "", 0, false).
+			AddRow("1.19.0", pending.name, pending.checksum, applied, true)
+	}
+	mock.ExpectQuery(listSchemaVersionSQL).WillReturnRows(rows())
+	mock.ExpectBegin()
+	mock.ExpectQuery("select count(*) from start_lock where lock_key = ?").WithArgs(defaultMigrateLockKey).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+	mock.ExpectExec("update start_lock set server = ? where lock_id = ? and lock_key = ?").
+		WithArgs(sqlmock.AnyArg(), 1, defaultMigrateLockKey).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(createSchemaVersionSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(listSchemaVersionSQL).WillReturnRows(rows())
+	// 只执行上一次失败的语句以及之后的语句
+	mock.ExpectExec(startSchemaVersionSQL).WithArgs("1.19.0", pending.name, pending.checksum).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(pending.statements[applied]).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(progressSchemaVersionSQL).WithArgs(applied+1, "1.19.0").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(finishSchemaVersionSQL).WithArgs("1.19.0").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, m.migrate())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_schemaMigrator_checksumMismatch(t *testing.T) {
+	m, mock := newTestMigrator(t, &migrateConfig{})
+	script := m.scripts[len(m.scripts)-2]
+	mock.ExpectQuery(listSchemaVersionSQL).WillReturnRows(
+		schemaVersionRows().AddRow(script.to.String(), script.name, "modified", 0, false))
+	err := m.migrate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), script.name)
+}
+
+func schemaVersionRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"version", "script", "checksum", "applied", "dirty"})
+}
@@ -0,0 +1,144 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package sqldb
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+// operationRecordFilters 查询条件与列的映射
+var operationRecordFilters = []struct {
+	key    string
+	column string
+}{
+	{key: "resource_type", column: "resource_type"},
+	{key: "resource_name", column: "resource_name"},
+	{key: "namespace", column: "namespace"},
+	{key: "operator", column: "operator"},
+	{key: "operation_type", column: "operation_type"},
+}
+
+type operationRecordStore struct {
+	master *BaseDB
+	slave  *BaseDB
+}
+
+// AddOperationRecords batch save operation records
+func (o *operationRecordStore) AddOperationRecords(records []*model.RecordEntry) error {
+	if len(records) == 0 {
+		return nil
+	}
+	placeholders := make([]string, 0, len(records))
+	args := make([]interface{}, 0, len(records)*8)
+	for _, record := range records {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?, FROM_UNIXTIME(?))")
+		args = append(args, string(record.ResourceType), record.ResourceName, record.Namespace, record.Operator,
+			string(record.OperationType), record.Detail, record.Server, record.HappenTime.Unix())
+	}
+	s := "INSERT INTO operation_record(resource_type, resource_name, namespace, operator, " +
+		" operation_type, detail, server, happen_time) VALUES " + strings.Join(placeholders, ",")
+	if _, err := o.master.Exec(s, args...); err != nil {
+		return store.Error(err)
+	}
+	return nil
+}
+
+// GetOperationRecords query operation records order by happen time desc
+func (o *operationRecordStore) GetOperationRecords(filter map[string]string,
+	offset, limit uint32) (uint32, []*model.RecordEntry, error) {
+	conditions := []string{"1 = 1"}
+	args := make([]interface{}, 0, len(filter))
+	for _, item := range operationRecordFilters {
+		if val := filter[item.key]; val != "" {
+			conditions = append(conditions, item.column+" = ?")
+			args = append(args, val)
+		}
+	}
+	if val, err := strconv.ParseInt(filter["start_time"], 10, 64); err == nil {
+		conditions = append(conditions, "happen_time >= FROM_UNIXTIME(?)")
+		args = append(args, val)
+	}
+	if val, err := strconv.ParseInt(filter["end_time"], 10, 64); err == nil {
+		conditions = append(conditions, "happen_time <= FROM_UNIXTIME(?)")
+		args = append(args, val)
+	}
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var count uint32
+	if err := o.slave.QueryRow("SELECT COUNT(*) FROM operation_record"+where, args...).Scan(&count); err != nil {
+		return 0, nil, store.Error(err)
+	}
+	querySql := "SELECT id, resource_type, resource_name, namespace, operator, operation_type, " +
+		" IFNULL(detail, ''), server, UNIX_TIMESTAMP(happen_time) FROM operation_record" + where +
+		" ORDER BY happen_time DESC, id DESC LIMIT ?, ?"
+	args = append(args, offset, limit)
+	rows, err := o.slave.Query(querySql, args...)
+	if err != nil {
+		return 0, nil, store.Error(err)
+	}
+	records, err := o.transferRows(rows)
+	if err != nil {
+		return 0, nil, store.Error(err)
+	}
+	return count, records, nil
+}
+
+// CleanOperationRecords delete operation records which happen before endTime
+func (o *operationRecordStore) CleanOperationRecords(endTime time.Time, limit uint64) (uint64, error) {
+	result, err := o.master.Exec("DELETE FROM operation_record WHERE happen_time < FROM_UNIXTIME(?) LIMIT ?",
+		endTime.Unix(), limit)
+	if err != nil {
+		return 0, store.Error(err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, store.Error(err)
+	}
+	return uint64(rows), nil
+}
+
+func (o *operationRecordStore) transferRows(rows *sql.Rows) ([]*model.RecordEntry, error) {
+	defer rows.Close()
+
+	records := make([]*model.RecordEntry, 0, 16)
+	for rows.Next() {
+		var (
+			item                        = &model.RecordEntry{}
+			resourceType, operationType string
+			happenTime                  int64
+		)
+		if err := rows.Scan(&item.ID, &resourceType, &item.ResourceName, &item.Namespace, &item.Operator,
+			&operationType, &item.Detail, &item.Server, &happenTime); err != nil {
+			return nil, err
+		}
+		item.ResourceType = model.Resource(resourceType)
+		item.OperationType = model.OperationType(operationType)
+		item.HappenTime = time.Unix(happenTime, 0)
+		records = append(records, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,79 @@
+/*
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+--
+-- Database: `polaris_server`
+--
+USE `polaris_server`;
+
+/* 操作记录 */
+CREATE TABLE `operation_record`
+(
+    `id`             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键',
+    `resource_type`  VARCHAR(64)     NOT NULL COMMENT '资源类型',
+    `resource_name`  VARCHAR(256)    NOT NULL DEFAULT '' COMMENT '资源名称',
+    `namespace`      VARCHAR(64)     NOT NULL DEFAULT '' COMMENT '所属的namespace',
+    `operator`       VARCHAR(64)     NOT NULL DEFAULT '' COMMENT '操作人',
+    `operation_type` VARCHAR(32)     NOT NULL COMMENT '操作类型',
+    `detail`         LONGTEXT COMMENT '操作详情',
+    `server`         VARCHAR(128)    NOT NULL DEFAULT '' COMMENT '处理请求的 polaris-server 节点',
+    `happen_time`    TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '操作时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_resource` (`resource_type`, `namespace`, `resource_name`),
+    KEY `idx_operator` (`operator`),
+    KEY `idx_happen_time` (`happen_time`)
+) ENGINE = InnoDB COMMENT = '操作记录表';
+
+/* 鉴权策略支持按照操作、方法以及拒绝策略进行控制 */
+ALTER TABLE `auth_strategy`
+    MODIFY COLUMN `action` VARCHAR(512) NOT NULL COMMENT 'Action of this policy, READ_WRITE, ONLY_READ, ALLOW:<operations> or DENY:<operations>';
+
+/* 个人访问令牌 */
+CREATE TABLE `access_token`
+(
+    `id`             VARCHAR(128) NOT NULL COMMENT 'Access token ID',
+    `name`           VARCHAR(100) NOT NULL COMMENT 'Access token name',
+    `principal_id`   VARCHAR(128) NOT NULL COMMENT 'User or user group ID',
+    `principal_type` INT          NOT NULL COMMENT 'Principal type, 1 is user, 2 is user group',
+    `owner`          VARCHAR(128) NOT NULL COMMENT 'Main account ID',
+    `secret`         VARCHAR(128) NOT NULL COMMENT 'Digest of the token',
+    `scope`          TEXT COMMENT 'Namespace and resource scope in json, empty means no limit',
+    `read_only`      TINYINT(4)   NOT NULL DEFAULT 0 COMMENT 'Whether the token is read only',
+    `expire_time`    TIMESTAMP    NULL DEFAULT NULL COMMENT 'Expire time',
+    `last_used_time` TIMESTAMP    NULL DEFAULT NULL COMMENT 'Last used time',
+    `flag`           TINYINT(4)   NOT NULL DEFAULT '0' COMMENT 'Whether the token is valid, 0 is valid, 1 is revoked',
+    `ctime`          TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Create time',
+    `mtime`          TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Last updated time',
+    PRIMARY KEY (`id`),
+    KEY `principal` (`principal_id`, `principal_type`),
+    KEY `owner` (`owner`),
+    KEY `mtime` (`mtime`)
+) ENGINE = InnoDB COMMENT = '个人访问令牌表';
+
+/* 表结构版本，用于启动时检查以及执行增量升级脚本 */
+CREATE TABLE IF NOT EXISTS `schema_version`
+(
+    `version`      VARCHAR(32)  NOT NULL COMMENT 'schema version',
+    `script`       VARCHAR(128) NOT NULL DEFAULT '' COMMENT 'delta script',
+    `checksum`     VARCHAR(64)  NOT NULL DEFAULT '' COMMENT 'sha256 of the delta script',
+    `applied`      INT          NOT NULL DEFAULT 0 COMMENT 'number of applied statements of a dirty script',
+    `dirty`        TINYINT(4)   NOT NULL DEFAULT 0 COMMENT 'whether the delta script is partially applied',
+    `applied_time` TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'applied time',
+    PRIMARY KEY (`version`)
+) ENGINE = InnoDB COMMENT = 'schema version';
+
+INSERT IGNORE INTO `schema_version` (`version`, `script`)
+VALUES ('1.19.0', 'v1_18_0-v1_19_0.sql');
@@ -608,7 +608,7 @@ CREATE TABLE `auth_strategy`
 (
     `id`       VARCHAR(128) NOT NULL COMMENT 'Strategy ID',
     `name`     VARCHAR(100) NOT NULL COMMENT 'Policy name',
-    `action`   VARCHAR(32)  NOT NULL COMMENT 'Read and write permission for this policy, only_read = 0, read_write = 1',
+    `action`   VARCHAR(512) NOT NULL COMMENT 'Action of this policy, READ_WRITE, ONLY_READ, ALLOW:<operations> or DENY:<operations>',
     `owner`    VARCHAR(128) NOT NULL COMMENT 'The account ID to which this policy is',
     `comment`  VARCHAR(255) NOT NULL COMMENT 'describe',
     `default`  TINYINT(4)   NOT NULL DEFAULT '0',
@@ -930,3 +930,58 @@ CREATE TABLE `gray_resource`
     `flag`        TINYINT(4)            DEFAULT 0 COMMENT '逻辑删除标志位, 0 位有效, 1 为逻辑删除',
     PRIMARY KEY (`name`)
 ) ENGINE = InnoDB COMMENT = '灰度资源表';
+
+/* 操作记录 */
+CREATE TABLE `operation_record`
+(
+    `id`             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键',
+    `resource_type`  VARCHAR(64)     NOT NULL COMMENT '资源类型',
+    `resource_name`  VARCHAR(256)    NOT NULL DEFAULT '' COMMENT '资源名称',
+    `namespace`      VARCHAR(64)     NOT NULL DEFAULT '' COMMENT '所属的namespace',
+    `operator`       VARCHAR(64)     NOT NULL DEFAULT '' COMMENT '操作人',
+    `operation_type` VARCHAR(32)     NOT NULL COMMENT '操作类型',
+    `detail`         LONGTEXT COMMENT '操作详情',
+    `server`         VARCHAR(128)    NOT NULL DEFAULT '' COMMENT '处理请求的 polaris-server 节点',
+    `happen_time`    TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '操作时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_resource` (`resource_type`, `namespace`, `resource_name`),
+    KEY `idx_operator` (`operator`),
+    KEY `idx_happen_time` (`happen_time`)
+) ENGINE = InnoDB COMMENT = '操作记录表';
+
+/* 个人访问令牌 */
+CREATE TABLE `access_token`
+(
+    `id`             VARCHAR(128) NOT NULL COMMENT 'Access token ID',
+    `name`           VARCHAR(100) NOT NULL COMMENT 'Access token name',
+    `principal_id`   VARCHAR(128) NOT NULL COMMENT 'User or user group ID',
+    `principal_type` INT          NOT NULL COMMENT 'Principal type, 1 is user, 2 is user group',
+    `owner`          VARCHAR(128) NOT NULL COMMENT 'Main account ID',
+    `secret`         VARCHAR(128) NOT NULL COMMENT 'Digest of the token',
+    `scope`          TEXT COMMENT 'Namespace and resource scope in json, empty means no limit',
+    `read_only`      TINYINT(4)   NOT NULL DEFAULT 0 COMMENT 'Whether the token is read only',
+    `expire_time`    TIMESTAMP    NULL DEFAULT NULL COMMENT 'Expire time',
+    `last_used_time` TIMESTAMP    NULL DEFAULT NULL COMMENT 'Last used time',
+    `flag`           TINYINT(4)   NOT NULL DEFAULT '0' COMMENT 'Whether the token is valid, 0 is valid, 1 is revoked',
+    `ctime`          TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Create time',
+    `mtime`          TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Last updated time',
+    PRIMARY KEY (`id`),
+    KEY `principal` (`principal_id`, `principal_type`),
+    KEY `owner` (`owner`),
+    KEY `mtime` (`mtime`)
+) ENGINE = InnoDB COMMENT = '个人访问令牌表';
+
+/* 表结构版本，用于启动时检查以及执行增量升级脚本 */
+CREATE TABLE `schema_version`
+(
+    `version`      VARCHAR(32)  NOT NULL COMMENT 'schema version',
+    `script`       VARCHAR(128) NOT NULL DEFAULT '' COMMENT 'delta script',
+    `checksum`     VARCHAR(64)  NOT NULL DEFAULT '' COMMENT 'sha256 of the delta script',
+    `applied`      INT          NOT NULL DEFAULT 0 COMMENT 'number of applied statements of a dirty script',
+    `dirty`        TINYINT(4)   NOT NULL DEFAULT 0 COMMENT 'whether the delta script is partially applied',
+    `applied_time` TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'applied time',
+    PRIMARY KEY (`version`)
+) ENGINE = InnoDB COMMENT = 'schema version';
+
+INSERT INTO `schema_version` (`version`, `script`)
+VALUES ('1.19.0', 'polaris_server.sql');
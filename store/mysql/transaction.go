@@ -56,6 +56,10 @@ func (t *transaction) LockBootstrap(key string, server string) error {
 		t.failed = true
 		return err
 	}
+	if count == 0 {
+		t.failed = true
+		return errors.Errorf("start_lock has no row for lock_key %s", key)
+	}
 
 	bid, err := rand.Int(rand.Reader, big.NewInt(1024))
 	if err != nil {
@@ -0,0 +1,363 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package raftdb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/polarismesh/polaris/common/eventhub"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+const (
+	TickTime  = 2 * time.Second
+	LeaseTime = 10 * time.Second
+)
+
+// adminStore 选主流程与数据库存储一致：选主记录通过版本号做 CAS 更新，leader 定期续约，
+// 租期过期后其他节点才能发起选举，不同之处在于选主记录保存在 raft 状态机中
+type adminStore struct {
+	s         *raftStore
+	tickTime  time.Duration
+	leaseTime time.Duration
+	leMap     map[string]*leaderElectionStateMachine
+	mutex     sync.Mutex
+}
+
+func newAdminStore(s *raftStore) *adminStore {
+	return &adminStore{
+		s:         s,
+		tickTime:  TickTime,
+		leaseTime: LeaseTime,
+		leMap:     make(map[string]*leaderElectionStateMachine),
+	}
+}
+
+// host 参与选主的节点标识
+func (m *adminStore) host() string {
+	if m.s.cfg.Host != "" {
+		return m.s.cfg.Host
+	}
+	return utils.LocalHost
+}
+
+// CreateLeaderElection 创建选主记录，记录已经存在时不做处理
+func (m *adminStore) CreateLeaderElection(key string) error {
+	result, err := m.s.propose(&command{Type: commandCreateElection, Key: key})
+	if err != nil {
+		return store.Error(err)
+	}
+	return result.Err()
+}
+
+// GetVersion 获取选主记录的版本号
+func (m *adminStore) GetVersion(key string) (int64, error) {
+	record, ok := m.s.fsm.getElection(key)
+	if !ok {
+		return 0, fmt.Errorf("leader election %s not found", key)
+	}
+	return record.Version, nil
+}
+
+// CompareAndSwapVersion 版本号与 curVersion 一致时更新为 newVersion，并设置 leader
+func (m *adminStore) CompareAndSwapVersion(key string, curVersion int64, newVersion int64,
+	leader string) (bool, error) {
+	result, err := m.s.propose(&command{
+		Type:       commandCASElection,
+		Key:        key,
+		Owner:      leader,
+		Version:    curVersion,
+		NewVersion: newVersion,
+	})
+	if err != nil {
+		return false, store.Error(err)
+	}
+	var success bool
+	err = result.decode(&success)
+	return success, err
+}
+
+// CheckMtimeExpired 检查 leader 的租期是否已经过期
+func (m *adminStore) CheckMtimeExpired(key string, leaseTime time.Duration) (string, bool, error) {
+	record, ok := m.s.fsm.getElection(key)
+	if !ok {
+		return "", false, fmt.Errorf("leader election %s not found", key)
+	}
+	return record.Leader, time.Since(time.Unix(0, record.Mtime)) > leaseTime, nil
+}
+
+// StartLeaderElection start the election procedure
+func (m *adminStore) StartLeaderElection(key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, ok := m.leMap[key]; ok {
+		return nil
+	}
+	if err := m.CreateLeaderElection(key); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	le := &leaderElectionStateMachine{
+		electKey: key,
+		leStore:  m,
+		host:     m.host(),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	m.leMap[key] = le
+	go le.mainLoop()
+	return nil
+}
+
+// StopLeaderElections stop the election procedure
+func (m *adminStore) StopLeaderElections() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for k, le := range m.leMap {
+		le.cancel()
+		delete(m.leMap, k)
+	}
+}
+
+// IsLeader check leader
+func (m *adminStore) IsLeader(key string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	le, ok := m.leMap[key]
+	if !ok {
+		return false
+	}
+	return le.isLeaderAtomic()
+}
+
+// ListLeaderElections list election records
+func (m *adminStore) ListLeaderElections() ([]*model.LeaderElection, error) {
+	records := m.s.fsm.listElections()
+	out := make([]*model.LeaderElection, 0, len(records))
+	for _, record := range records {
+		item := &model.LeaderElection{
+			ElectKey:   record.Key,
+			Host:       record.Leader,
+			CreateTime: time.Unix(0, record.Ctime),
+			ModifyTime: time.Unix(0, record.Mtime),
+			Valid:      time.Since(time.Unix(0, record.Mtime)) <= m.leaseTime,
+		}
+		item.Ctime = item.CreateTime.Unix()
+		item.Mtime = item.ModifyTime.Unix()
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// ReleaseLeaderElection release election lock
+func (m *adminStore) ReleaseLeaderElection(key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	le, ok := m.leMap[key]
+	if !ok {
+		return fmt.Errorf("LeaderElection(%s) not started", key)
+	}
+	le.setReleaseSignal()
+	return nil
+}
+
+// leaderElectionStateMachine
+type leaderElectionStateMachine struct {
+	electKey         string
+	leStore          *adminStore
+	host             string
+	leaderFlag       int32
+	version          int64
+	ctx              context.Context
+	cancel           context.CancelFunc
+	releaseSignal    int32
+	releaseTickLimit int32
+	leader           string
+}
+
+// mainLoop
+func (le *leaderElectionStateMachine) mainLoop() {
+	le.changeToFollower("")
+	log.Infof("[Store][Raft] leader election started (%s)", le.electKey)
+	ticker := time.NewTicker(le.leStore.tickTime)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			le.tick()
+		case <-le.ctx.Done():
+			log.Infof("[Store][Raft] leader election stopped (%s)", le.electKey)
+			le.changeToFollower("")
+			return
+		}
+	}
+}
+
+// tick
+func (le *leaderElectionStateMachine) tick() {
+	if le.checkReleaseTickLimit() {
+		log.Infof("[Store][Raft] abandon leader election in this tick (%s)", le.electKey)
+		return
+	}
+	shouldRelease := le.checkAndClearReleaseSignal()
+	if le.isLeader() {
+		if shouldRelease {
+			log.Infof("[Store][Raft] release leader election (%s)", le.electKey)
+			le.changeToFollower("")
+			le.setReleaseTickLimit()
+			return
+		}
+		success, err := le.heartbeat()
+		if err == nil && success {
+			return
+		}
+		if err != nil {
+			log.Errorf("[Store][Raft] leader heartbeat err (%v), change to follower state (%s)", err, le.electKey)
+		}
+		if !success && err == nil {
+			log.Infof("[Store][Raft] leader heartbeat abort, change to follower state (%s)", le.electKey)
+		}
+	}
+	leader, dead, err := le.leStore.CheckMtimeExpired(le.electKey, le.leStore.leaseTime)
+	if err != nil {
+		log.Errorf("[Store][Raft] check leader dead err (%s), stay follower state (%s)",
+			err.Error(), le.electKey)
+		return
+	}
+	if !dead {
+		// 自己之前是 leader，并且租期还没过，调整自己为 leader
+		if leader == le.host {
+			le.changeToLeader()
+		}
+		// leader 信息出现变化，发布leader信息变化通知
+		if le.leader != leader {
+			le.changeToFollower(leader)
+		}
+		return
+	}
+	// 租期已经过期（比如当前节点处于少数派的网络分区中，续约无法提交），不能再认为自己是 leader
+	if le.isLeader() {
+		le.changeToFollower("")
+	}
+	success, err := le.elect()
+	if err != nil {
+		log.Errorf("[Store][Raft] elect leader err (%s), stay follower state (%s)", err.Error(), le.electKey)
+		return
+	}
+	if success {
+		le.changeToLeader()
+	}
+}
+
+func (le *leaderElectionStateMachine) publishLeaderChangeEvent() {
+	_ = eventhub.Publish(eventhub.LeaderChangeEventTopic, store.LeaderChangeEvent{
+		Key:        le.electKey,
+		Leader:     le.isLeader(),
+		LeaderHost: le.leader,
+	})
+}
+
+// changeToLeader
+func (le *leaderElectionStateMachine) changeToLeader() {
+	log.Infof("[Store][Raft] change from follower to leader (%s)", le.electKey)
+	atomic.StoreInt32(&le.leaderFlag, 1)
+	le.leader = le.host
+	le.publishLeaderChangeEvent()
+}
+
+// changeToFollower
+func (le *leaderElectionStateMachine) changeToFollower(leader string) {
+	log.Infof("[Store][Raft] change from leader to follower (%s)", le.electKey)
+	atomic.StoreInt32(&le.leaderFlag, 0)
+	le.leader = leader
+	le.publishLeaderChangeEvent()
+}
+
+// elect
+func (le *leaderElectionStateMachine) elect() (bool, error) {
+	curVersion, err := le.leStore.GetVersion(le.electKey)
+	if err != nil {
+		return false, err
+	}
+	le.version = curVersion + 1
+	return le.leStore.CompareAndSwapVersion(le.electKey, curVersion, le.version, le.host)
+}
+
+// heartbeat
+func (le *leaderElectionStateMachine) heartbeat() (bool, error) {
+	curVersion := le.version
+	le.version = curVersion + 1
+	return le.leStore.CompareAndSwapVersion(le.electKey, curVersion, le.version, le.host)
+}
+
+// isLeader
+func (le *leaderElectionStateMachine) isLeader() bool {
+	return le.leaderFlag > 0
+}
+
+// isLeaderAtomic
+func (le *leaderElectionStateMachine) isLeaderAtomic() bool {
+	return atomic.LoadInt32(&le.leaderFlag) > 0
+}
+
+func (le *leaderElectionStateMachine) setReleaseSignal() {
+	atomic.StoreInt32(&le.releaseSignal, 1)
+}
+
+func (le *leaderElectionStateMachine) checkAndClearReleaseSignal() bool {
+	return atomic.CompareAndSwapInt32(&le.releaseSignal, 1, 0)
+}
+
+func (le *leaderElectionStateMachine) checkReleaseTickLimit() bool {
+	if le.releaseTickLimit > 0 {
+		le.releaseTickLimit = le.releaseTickLimit - 1
+		return true
+	}
+	return false
+}
+
+func (le *leaderElectionStateMachine) setReleaseTickLimit() {
+	le.releaseTickLimit = int32(le.leStore.leaseTime / le.leStore.tickTime * 3)
+}
+
+// StartLeaderElection start leader election
+func (s *raftStore) StartLeaderElection(key string) error {
+	return s.admin.StartLeaderElection(key)
+}
+
+// IsLeader whether it is leader node
+func (s *raftStore) IsLeader(key string) bool {
+	return s.admin.IsLeader(key)
+}
+
+// ListLeaderElections list all leaderelection
+func (s *raftStore) ListLeaderElections() ([]*model.LeaderElection, error) {
+	return s.admin.ListLeaderElections()
+}
+
+// ReleaseLeaderElection force release leader status
+func (s *raftStore) ReleaseLeaderElection(key string) error {
+	return s.admin.ReleaseLeaderElection(key)
+}
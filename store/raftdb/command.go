@@ -0,0 +1,139 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package raftdb
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/polarismesh/polaris/store"
+)
+
+// commandType raft 日志中命令的类型
+type commandType uint8
+
+const (
+	// commandStore 调用本地存储的某个写方法
+	commandStore commandType = iota + 1
+	// commandBatch 事务提交，在本地存储的一个写事务中依次执行事务中缓存的写方法
+	commandBatch
+	// commandSeed 使用 leader 节点的初始化数据覆盖本地存储，保证集群内各节点的初始数据一致
+	commandSeed
+	// commandDeleteNamespace 事务对象中的删除命名空间
+	commandDeleteNamespace
+	// commandCreateElection 创建选主记录
+	commandCreateElection
+	// commandCASElection 对选主记录做 CAS 更新
+	commandCASElection
+	// commandLock 获取事务锁
+	commandLock
+	// commandUnlock 释放事务锁
+	commandUnlock
+)
+
+// command 提交到 raft 日志中的一条命令
+type command struct {
+	Type   commandType       `json:"type"`
+	Method string            `json:"method,omitempty"`
+	Args   []json.RawMessage `json:"args,omitempty"`
+	Batch  []*command        `json:"batch,omitempty"`
+	Data   []byte            `json:"data,omitempty"`
+	// Key 选主记录或者事务锁的 key
+	Key string `json:"key,omitempty"`
+	// Owner 选主的候选者或者事务锁的持有者
+	Owner string `json:"owner,omitempty"`
+	// Keys 批量释放的事务锁
+	Keys       []string `json:"keys,omitempty"`
+	Shared     bool     `json:"shared,omitempty"`
+	Version    int64    `json:"version,omitempty"`
+	NewVersion int64    `json:"newVersion,omitempty"`
+	// TTL 事务锁的持有时间，单位纳秒
+	TTL int64 `json:"ttl,omitempty"`
+	// Now 由 leader 在提交日志前设置的时间戳（纳秒），保证各节点状态机使用同一个时间
+	Now int64 `json:"now,omitempty"`
+}
+
+func newStoreCommand(method string, args ...interface{}) (*command, error) {
+	cmd := &command{
+		Type:   commandStore,
+		Method: method,
+		Args:   make([]json.RawMessage, 0, len(args)),
+	}
+	for i := range args {
+		data, err := json.Marshal(args[i])
+		if err != nil {
+			return nil, err
+		}
+		cmd.Args = append(cmd.Args, data)
+	}
+	return cmd, nil
+}
+
+// commandResult 命令在状态机中的执行结果
+type commandResult struct {
+	Values []json.RawMessage `json:"values,omitempty"`
+	Failed bool              `json:"failed,omitempty"`
+	// Code 存储层的错误码，只有 store.StatusError 才会设置
+	Code    store.StatusCode `json:"code,omitempty"`
+	Message string           `json:"message,omitempty"`
+	// Index 命令在 raft 日志中的序号
+	Index uint64 `json:"index"`
+}
+
+func newCommandResult(values []interface{}, err error) *commandResult {
+	result := &commandResult{}
+	for i := range values {
+		data, mErr := json.Marshal(values[i])
+		if mErr != nil && err == nil {
+			err = mErr
+		}
+		result.Values = append(result.Values, data)
+	}
+	if err != nil {
+		result.Failed = true
+		result.Message = err.Error()
+		if _, ok := err.(*store.StatusError); ok {
+			result.Code = store.Code(err)
+		}
+	}
+	return result
+}
+
+// Err 还原命令执行的错误
+func (r *commandResult) Err() error {
+	if !r.Failed {
+		return nil
+	}
+	if r.Code != store.Ok {
+		return store.NewStatusError(r.Code, r.Message)
+	}
+	return errors.New(r.Message)
+}
+
+// decode 将命令的返回值依次解析到 outs 中，并返回命令执行的错误
+func (r *commandResult) decode(outs ...interface{}) error {
+	for i := range outs {
+		if i >= len(r.Values) {
+			break
+		}
+		if err := json.Unmarshal(r.Values[i], outs[i]); err != nil {
+			return err
+		}
+	}
+	return r.Err()
+}
@@ -0,0 +1,141 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package raftdb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	defaultDataDir           = "./polaris-raft"
+	defaultApplyTimeout      = 10 * time.Second
+	defaultSnapshotInterval  = 2 * time.Minute
+	defaultSnapshotThreshold = 8192
+	defaultRetainSnapshots   = 2
+	defaultLockTTL           = 30 * time.Second
+	defaultBootstrapTimeout  = time.Minute
+)
+
+// PeerConfig raft 集群中的一个成员节点
+type PeerConfig struct {
+	// ID 节点在 raft 集群中的唯一标识
+	ID string `mapstructure:"id"`
+	// Address 节点间复制 raft 日志使用的 TCP 地址
+	Address string `mapstructure:"address"`
+	// ForwardAddress 跟随者将写请求转发给 leader 使用的 HTTP 地址
+	ForwardAddress string `mapstructure:"forwardAddress"`
+}
+
+// Config raft 存储插件的配置
+type Config struct {
+	// NodeID 当前节点的 ID，必须出现在 Peers 中
+	NodeID string `mapstructure:"nodeId"`
+	// DataDir raft 日志、快照以及本地 boltdb 状态机的存放目录
+	DataDir string `mapstructure:"dataDir"`
+	// Peers 集群的全部成员（包括当前节点），建议为 3 或者 5 个节点
+	Peers []*PeerConfig `mapstructure:"peers"`
+	// Host 参与 polaris 内部选主时使用的节点标识，默认为本机 IP
+	Host string `mapstructure:"host"`
+	// Secret 转发写请求时携带的共享密钥，所有节点必须配置相同的非空密钥
+	Secret string `mapstructure:"secret"`
+	// HeartbeatTimeout raft 心跳超时时间，为空时使用 raft 默认值
+	HeartbeatTimeout time.Duration `mapstructure:"heartbeatTimeout"`
+	// ElectionTimeout raft 选举超时时间，为空时使用 raft 默认值
+	ElectionTimeout time.Duration `mapstructure:"electionTimeout"`
+	// ApplyTimeout 一次写操作提交到集群的超时时间
+	ApplyTimeout time.Duration `mapstructure:"applyTimeout"`
+	// SnapshotInterval 检查是否需要生成快照的周期
+	SnapshotInterval time.Duration `mapstructure:"snapshotInterval"`
+	// SnapshotThreshold 距离上一次快照新增多少条日志后生成新的快照
+	SnapshotThreshold uint64 `mapstructure:"snapshotThreshold"`
+	// RetainSnapshots 保留的快照个数
+	RetainSnapshots int `mapstructure:"retainSnapshots"`
+	// LockTTL 事务锁的最长持有时间，超过后锁会被自动释放，避免持有者宕机后锁无法释放
+	LockTTL time.Duration `mapstructure:"lockTTL"`
+	// BootstrapTimeout 启动时等待集群选出 leader 并完成初始化数据同步的超时时间
+	BootstrapTimeout time.Duration `mapstructure:"bootstrapTimeout"`
+}
+
+func parseConfig(options map[string]interface{}) (*Config, error) {
+	config := &Config{
+		DataDir:           defaultDataDir,
+		ApplyTimeout:      defaultApplyTimeout,
+		SnapshotInterval:  defaultSnapshotInterval,
+		SnapshotThreshold: defaultSnapshotThreshold,
+		RetainSnapshots:   defaultRetainSnapshots,
+		LockTTL:           defaultLockTTL,
+		BootstrapTimeout:  defaultBootstrapTimeout,
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     config,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(options); err != nil {
+		return nil, err
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *Config) validate() error {
+	if c.NodeID == "" {
+		return errors.New("raft store nodeId is empty")
+	}
+	if len(c.Peers) == 0 {
+		return errors.New("raft store peers is empty")
+	}
+	if c.Secret == "" {
+		return errors.New("raft store secret is empty, peers must share a secret to forward writes")
+	}
+	ids := map[string]struct{}{}
+	for _, peer := range c.Peers {
+		if peer.ID == "" || peer.Address == "" || peer.ForwardAddress == "" {
+			return errors.New("raft store peer id, address and forwardAddress must not be empty")
+		}
+		if _, ok := ids[peer.ID]; ok {
+			return fmt.Errorf("raft store peer %s is duplicated", peer.ID)
+		}
+		ids[peer.ID] = struct{}{}
+	}
+	if c.self() == nil {
+		return fmt.Errorf("raft store nodeId %s not found in peers", c.NodeID)
+	}
+	return nil
+}
+
+func (c *Config) self() *PeerConfig {
+	return c.peer(c.NodeID)
+}
+
+func (c *Config) peer(id string) *PeerConfig {
+	for _, peer := range c.Peers {
+		if peer.ID == id {
+			return peer
+		}
+	}
+	return nil
+}
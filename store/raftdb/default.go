@@ -0,0 +1,495 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package raftdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/raft"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+	"github.com/polarismesh/polaris/store/boltdb"
+)
+
+const (
+	// STORENAME raft 复制存储的名字
+	STORENAME = "raftStore"
+
+	localStoreFile = "polaris.bolt"
+	raftLogFile    = "raft.db"
+
+	proposeRetryInterval = 50 * time.Millisecond
+	lockRetryInterval    = 100 * time.Millisecond
+	applyWaitInterval    = 5 * time.Millisecond
+)
+
+var (
+	// errNoLeader 集群当前没有 leader，通常出现在选举过程中
+	errNoLeader = errors.New("raft store cluster has no leader")
+	// errNotLeader 命令被转发到了非 leader 节点
+	errNotLeader = errors.New("raft store node is not leader")
+)
+
+func init() {
+	s := &raftStore{}
+	_ = store.RegisterStore(s)
+}
+
+// raftStore 通过 raft 将 boltdb 状态机复制到多个 polaris 节点，读操作直接读取本节点的 boltdb，
+// 写操作作为 raft 日志提交，由 leader 写入，跟随者收到的写请求会转发给 leader
+type raftStore struct {
+	// Store 本节点的 boltdb，负责全部的读操作
+	store.Store
+
+	cfg       *Config
+	local     localStore
+	fsm       *fsm
+	raft      *raft.Raft
+	forwarder forwarder
+	closers   []func() error
+
+	admin *adminStore
+
+	stopCh chan struct{}
+	once   sync.Once
+	start  bool
+}
+
+// Name 存储层的名字
+func (s *raftStore) Name() string {
+	return STORENAME
+}
+
+// Initialize 启动本节点的 raft，并等待集群完成初始化数据的同步
+func (s *raftStore) Initialize(c *store.Config) error {
+	if s.start {
+		return nil
+	}
+	cfg, err := parseConfig(c.Option)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cfg.DataDir, os.ModePerm); err != nil {
+		return err
+	}
+	loadFile, _ := c.Option["loadFile"].(string)
+	local, err := openLocalStore(filepath.Join(cfg.DataDir, localStoreFile), loadFile)
+	if err != nil {
+		return err
+	}
+	s.closers = append(s.closers, local.Destroy)
+	logs, err := newLogStore(filepath.Join(cfg.DataDir, raftLogFile))
+	if err != nil {
+		_ = s.close()
+		return err
+	}
+	s.closers = append(s.closers, logs.Close)
+	snapshots, err := raft.NewFileSnapshotStore(cfg.DataDir, cfg.RetainSnapshots, logWriter{})
+	if err != nil {
+		_ = s.close()
+		return err
+	}
+	advertise, err := net.ResolveTCPAddr("tcp", cfg.self().Address)
+	if err != nil {
+		_ = s.close()
+		return err
+	}
+	transport, err := raft.NewTCPTransport(cfg.self().Address, advertise, 3, 10*time.Second, logWriter{})
+	if err != nil {
+		_ = s.close()
+		return err
+	}
+	s.closers = append(s.closers, transport.Close)
+
+	newForwarder := func(handler forwardHandler) (forwarder, error) {
+		return newHTTPForwarder(cfg, handler)
+	}
+	if err := s.open(cfg, local, logs, logs, snapshots, transport, newForwarder); err != nil {
+		_ = s.close()
+		return err
+	}
+	s.start = true
+	return nil
+}
+
+// openLocalStore 本地 boltdb 只是状态机的载体，每次启动都会重建，再由 raft 的快照以及日志恢复到最新状态
+func openLocalStore(path string, loadFile string) (localStore, error) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	option := map[string]interface{}{"path": path}
+	if loadFile != "" {
+		option["loadFile"] = loadFile
+	}
+	s := boltdb.NewBoltStore()
+	if err := s.Initialize(&store.Config{Name: boltdb.STORENAME, Option: option}); err != nil {
+		return nil, err
+	}
+	local, ok := s.(localStore)
+	if !ok {
+		_ = s.Destroy()
+		return nil, errors.New("boltdb store not support backup and restore")
+	}
+	return local, nil
+}
+
+func (s *raftStore) open(cfg *Config, local localStore, logs raft.LogStore, stable raft.StableStore,
+	snapshots raft.SnapshotStore, transport raft.Transport,
+	newForwarder func(handler forwardHandler) (forwarder, error)) error {
+	s.cfg = cfg
+	s.local = local
+	s.Store = local
+	s.fsm = newFSM(local)
+	s.admin = newAdminStore(s)
+	s.stopCh = make(chan struct{})
+
+	notifyCh := make(chan bool, 8)
+	raftConfig := raft.DefaultConfig()
+	raftConfig.LocalID = raft.ServerID(cfg.NodeID)
+	raftConfig.LogOutput = logWriter{}
+	raftConfig.NotifyCh = notifyCh
+	raftConfig.SnapshotInterval = cfg.SnapshotInterval
+	raftConfig.SnapshotThreshold = cfg.SnapshotThreshold
+	if cfg.HeartbeatTimeout > 0 {
+		raftConfig.HeartbeatTimeout = cfg.HeartbeatTimeout
+		if raftConfig.LeaderLeaseTimeout > cfg.HeartbeatTimeout {
+			raftConfig.LeaderLeaseTimeout = cfg.HeartbeatTimeout
+		}
+	}
+	if cfg.ElectionTimeout > 0 {
+		raftConfig.ElectionTimeout = cfg.ElectionTimeout
+	}
+
+	hasState, err := raft.HasExistingState(logs, stable, snapshots)
+	if err != nil {
+		return err
+	}
+	r, err := raft.NewRaft(raftConfig, s.fsm, logs, stable, snapshots, transport)
+	if err != nil {
+		return err
+	}
+	s.raft = r
+	s.closers = append([]func() error{func() error {
+		return r.Shutdown().Error()
+	}}, s.closers...)
+
+	if !hasState {
+		// 所有节点使用相同的成员配置初始化集群，raft 保证只会有一个配置生效
+		servers := make([]raft.Server, 0, len(cfg.Peers))
+		for _, peer := range cfg.Peers {
+			servers = append(servers, raft.Server{
+				ID:      raft.ServerID(peer.ID),
+				Address: raft.ServerAddress(peer.Address),
+			})
+		}
+		if err := r.BootstrapCluster(raft.Configuration{Servers: servers}).Error(); err != nil &&
+			!errors.Is(err, raft.ErrCantBootstrap) {
+			return err
+		}
+	}
+
+	fwd, err := newForwarder(s.handleForward)
+	if err != nil {
+		return err
+	}
+	s.forwarder = fwd
+	s.closers = append([]func() error{fwd.Close}, s.closers...)
+
+	go s.watchLeadership(notifyCh)
+	return s.waitSeeded()
+}
+
+// watchLeadership 成为 leader 后检查集群是否已经完成了初始化数据的同步
+func (s *raftStore) watchLeadership(notifyCh <-chan bool) {
+	for {
+		select {
+		case isLeader := <-notifyCh:
+			if isLeader {
+				log.Info("[Store][Raft] become raft leader", zap.String("node", s.cfg.NodeID))
+				s.seed()
+			}
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// seed 新集群的各个节点在启动时各自生成了默认数据（命名空间 token 等存在随机值），
+// 由第一个 leader 将自己的本地数据作为初始数据提交，覆盖所有节点的本地存储
+func (s *raftStore) seed() {
+	// 确保之前的日志都已经应用到状态机，避免重启后重复提交初始数据
+	if err := s.raft.Barrier(s.cfg.ApplyTimeout).Error(); err != nil {
+		log.Error("[Store][Raft] wait raft barrier fail", zap.Error(err))
+		return
+	}
+	if s.fsm.isSeeded() {
+		return
+	}
+	data := &bytes.Buffer{}
+	if err := s.local.Backup(data); err != nil {
+		log.Error("[Store][Raft] backup local store fail", zap.Error(err))
+		return
+	}
+	result, err := s.applyCommand(&command{Type: commandSeed, Data: data.Bytes()})
+	if err == nil {
+		err = result.Err()
+	}
+	if err != nil {
+		log.Error("[Store][Raft] seed cluster data fail", zap.Error(err))
+		return
+	}
+	log.Info("[Store][Raft] seed cluster data success", zap.Int("size", data.Len()))
+}
+
+func (s *raftStore) waitSeeded() error {
+	deadline := time.Now().Add(s.cfg.BootstrapTimeout)
+	for !s.fsm.isSeeded() {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("raft store wait cluster bootstrap timeout after %s", s.cfg.BootstrapTimeout)
+		}
+		time.Sleep(proposeRetryInterval)
+	}
+	return nil
+}
+
+// call 将一次存储写操作提交到集群执行，outs 用于接收方法除 error 以外的返回值
+func (s *raftStore) call(method string, outs []interface{}, args ...interface{}) error {
+	cmd, err := newStoreCommand(method, args...)
+	if err != nil {
+		return store.Error(err)
+	}
+	result, err := s.propose(cmd)
+	if err != nil {
+		return store.Error(err)
+	}
+	return result.decode(outs...)
+}
+
+// callTx 将事务内的写操作缓存到事务中
+func (s *raftStore) callTx(tx store.Tx, method string, args ...interface{}) error {
+	rtx, err := toRaftTx(tx)
+	if err != nil {
+		return err
+	}
+	cmd, err := newStoreCommand(method, args...)
+	if err != nil {
+		return store.Error(err)
+	}
+	rtx.batch = append(rtx.batch, cmd)
+	return nil
+}
+
+// propose 提交命令，leader 直接写入 raft 日志，跟随者转发给 leader；
+// 只有确定命令没有被提交时（没有 leader 或者 leader 发生了切换）才会重试
+func (s *raftStore) propose(cmd *command) (*commandResult, error) {
+	deadline := time.Now().Add(s.cfg.ApplyTimeout)
+	for {
+		result, err := s.tryPropose(cmd)
+		if err == nil {
+			return result, nil
+		}
+		if (err != errNoLeader && err != errNotLeader) || time.Now().After(deadline) {
+			return nil, err
+		}
+		time.Sleep(proposeRetryInterval)
+	}
+}
+
+func (s *raftStore) tryPropose(cmd *command) (*commandResult, error) {
+	if s.raft.State() == raft.Leader {
+		return s.applyCommand(cmd)
+	}
+	_, leaderID := s.raft.LeaderWithID()
+	if leaderID == "" {
+		return nil, errNoLeader
+	}
+	peer := s.cfg.peer(string(leaderID))
+	if peer == nil {
+		return nil, fmt.Errorf("raft leader %s not found in peers", leaderID)
+	}
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.forwarder.Forward(peer, data)
+	if err != nil {
+		return nil, err
+	}
+	// 等待本节点的状态机追上 leader，保证写后读的一致性
+	s.waitApplied(result.Index)
+	return result, nil
+}
+
+// applyCommand 在 leader 上将命令写入 raft 日志，并等待本节点状态机执行完成
+func (s *raftStore) applyCommand(cmd *command) (*commandResult, error) {
+	if s.raft.State() != raft.Leader {
+		return nil, errNotLeader
+	}
+	cmd.Now = time.Now().UnixNano()
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, err
+	}
+	future := s.raft.Apply(data, s.cfg.ApplyTimeout)
+	if err := future.Error(); err != nil {
+		if errors.Is(err, raft.ErrNotLeader) {
+			return nil, errNotLeader
+		}
+		return nil, err
+	}
+	return future.Response().(*commandResult), nil
+}
+
+// handleForward 处理跟随者转发过来的命令
+func (s *raftStore) handleForward(data []byte) (*commandResult, error) {
+	cmd := &command{}
+	if err := json.Unmarshal(data, cmd); err != nil {
+		return nil, err
+	}
+	if err := checkForward(cmd); err != nil {
+		log.Warn("[Store][Raft] reject forwarded command", zap.Error(err))
+		return nil, err
+	}
+	return s.applyCommand(cmd)
+}
+
+func (s *raftStore) waitApplied(index uint64) {
+	deadline := time.Now().Add(s.cfg.ApplyTimeout)
+	for s.raft.AppliedIndex() < index {
+		if time.Now().After(deadline) {
+			log.Warn("[Store][Raft] wait local state machine apply timeout", zap.Uint64("index", index))
+			return
+		}
+		time.Sleep(applyWaitInterval)
+	}
+}
+
+// BatchAddClients insert the client info
+func (s *raftStore) BatchAddClients(clients []*model.Client) error {
+	protos := make([]*apiservice.Client, 0, len(clients))
+	for i := range clients {
+		protos = append(protos, clients[i].Proto())
+	}
+	return s.call("BatchAddClients", nil, protos)
+}
+
+// StartTx 开启一个事务，事务内的读操作使用本地只读事务，写操作在提交时整体复制到集群
+func (s *raftStore) StartTx() (store.Tx, error) {
+	return newRaftTx(s)
+}
+
+// StartReadTx 开启一个本地只读事务
+func (s *raftStore) StartReadTx() (store.Tx, error) {
+	return s.local.StartReadTx()
+}
+
+// CreateTransaction 创建事务对象，事务中的锁通过 raft 在集群内生效
+func (s *raftStore) CreateTransaction() (store.Transaction, error) {
+	local, err := s.local.CreateTransaction()
+	if err != nil {
+		return nil, err
+	}
+	return &transaction{s: s, local: local, owner: utils.NewUUID()}, nil
+}
+
+// LockConfigFile 加锁配置文件，锁在事务提交或者回滚时释放
+func (s *raftStore) LockConfigFile(tx store.Tx, file *model.ConfigFileKey) (*model.ConfigFile, error) {
+	rtx, err := toRaftTx(tx)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("configFile/%s/%s/%s", file.Namespace, file.Group, file.Name)
+	if err := rtx.lock(key); err != nil {
+		return nil, err
+	}
+	return s.Store.LockConfigFile(rtx.local, file)
+}
+
+// lock 获取事务锁，锁被其他持有者占用时持续重试，最多等待一个锁的持有时间
+func (s *raftStore) lock(owner, key string, shared bool) error {
+	deadline := time.Now().Add(s.cfg.LockTTL)
+	for {
+		result, err := s.propose(&command{
+			Type:   commandLock,
+			Key:    key,
+			Owner:  owner,
+			Shared: shared,
+			TTL:    int64(s.cfg.LockTTL),
+		})
+		if err != nil {
+			return store.Error(err)
+		}
+		var acquired bool
+		if err := result.decode(&acquired); err != nil {
+			return err
+		}
+		if acquired {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return store.Error(fmt.Errorf("wait for lock %s timeout", key))
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// unlock 释放 owner 持有的事务锁
+func (s *raftStore) unlock(owner string, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := s.propose(&command{Type: commandUnlock, Owner: owner, Keys: keys})
+	return store.Error(err)
+}
+
+// Destroy 停止选主，关闭 raft 以及本地存储
+func (s *raftStore) Destroy() error {
+	s.start = false
+	if s.admin != nil {
+		s.admin.StopLeaderElections()
+	}
+	return s.close()
+}
+
+func (s *raftStore) close() error {
+	s.once.Do(func() {
+		if s.stopCh != nil {
+			close(s.stopCh)
+		}
+	})
+	var errs []error
+	for _, closer := range s.closers {
+		if err := closer(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	s.closers = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,544 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package raftdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+// memForwarders 测试用的进程内转发，直接调用 leader 节点的处理函数
+type memForwarders struct {
+	lock     sync.RWMutex
+	handlers map[string]forwardHandler
+}
+
+type memForwarder struct {
+	id   string
+	hub  *memForwarders
+	stop bool
+}
+
+func (f *memForwarder) Forward(peer *PeerConfig, data []byte) (*commandResult, error) {
+	f.hub.lock.RLock()
+	handler, ok := f.hub.handlers[peer.ID]
+	f.hub.lock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("peer %s is down", peer.ID)
+	}
+	return handler(data)
+}
+
+func (f *memForwarder) Close() error {
+	f.hub.lock.Lock()
+	defer f.hub.lock.Unlock()
+	delete(f.hub.handlers, f.id)
+	return nil
+}
+
+type testCluster struct {
+	nodes []*raftStore
+}
+
+func newTestCluster(t *testing.T, size int) *testCluster {
+	peers := make([]*PeerConfig, 0, size)
+	transports := make([]*raft.InmemTransport, 0, size)
+	for i := 0; i < size; i++ {
+		id := fmt.Sprintf("node-%d", i+1)
+		peers = append(peers, &PeerConfig{ID: id, Address: id, ForwardAddress: id})
+		_, transport := raft.NewInmemTransport(raft.ServerAddress(id))
+		transports = append(transports, transport)
+	}
+	for i := range transports {
+		for j := range transports {
+			if i != j {
+				transports[i].Connect(transports[j].LocalAddr(), transports[j])
+			}
+		}
+	}
+
+	hub := &memForwarders{handlers: map[string]forwardHandler{}}
+	cluster := &testCluster{nodes: make([]*raftStore, size)}
+	wg := &sync.WaitGroup{}
+	errs := make([]error, size)
+	for i := 0; i < size; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cfg := &Config{
+				NodeID:            peers[i].ID,
+				DataDir:           t.TempDir(),
+				Peers:             peers,
+				Host:              fmt.Sprintf("127.0.0.%d", i+1),
+				HeartbeatTimeout:  100 * time.Millisecond,
+				ElectionTimeout:   100 * time.Millisecond,
+				ApplyTimeout:      5 * time.Second,
+				SnapshotInterval:  time.Minute,
+				SnapshotThreshold: defaultSnapshotThreshold,
+				LockTTL:           2 * time.Second,
+				BootstrapTimeout:  20 * time.Second,
+			}
+			local, err := openLocalStore(filepath.Join(cfg.DataDir, localStoreFile), "")
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			node := &raftStore{closers: []func() error{local.Destroy}}
+			node.start = true
+			logs := raft.NewInmemStore()
+			newForwarder := func(handler forwardHandler) (forwarder, error) {
+				hub.lock.Lock()
+				defer hub.lock.Unlock()
+				hub.handlers[cfg.NodeID] = handler
+				return &memForwarder{id: cfg.NodeID, hub: hub}, nil
+			}
+			errs[i] = node.open(cfg, local, logs, logs, raft.NewInmemSnapshotStore(), transports[i], newForwarder)
+			node.admin.tickTime = 50 * time.Millisecond
+			node.admin.leaseTime = 500 * time.Millisecond
+			cluster.nodes[i] = node
+		}(i)
+	}
+	wg.Wait()
+	t.Cleanup(func() {
+		for _, node := range cluster.nodes {
+			if node != nil {
+				_ = node.Destroy()
+			}
+		}
+	})
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cluster
+}
+
+func (c *testCluster) leader() *raftStore {
+	for _, node := range c.nodes {
+		if node.raft.State() == raft.Leader {
+			return node
+		}
+	}
+	return nil
+}
+
+func (c *testCluster) follower() *raftStore {
+	for _, node := range c.nodes {
+		if node.raft.State() == raft.Follower {
+			return node
+		}
+	}
+	return nil
+}
+
+func newTestNamespace(name string) *model.Namespace {
+	return &model.Namespace{
+		Name:       name,
+		Token:      name + "-token",
+		Owner:      "polaris",
+		Valid:      true,
+		CreateTime: time.Now(),
+		ModifyTime: time.Now(),
+	}
+}
+
+func Test_RaftStore_Replicate(t *testing.T) {
+	cluster := newTestCluster(t, 3)
+
+	t.Run("初始数据在各个节点一致", func(t *testing.T) {
+		expect, err := cluster.nodes[0].GetService("polaris.checker", "Polaris")
+		assert.NoError(t, err)
+		assert.NotNil(t, expect)
+		for _, node := range cluster.nodes[1:] {
+			svc, err := node.GetService("polaris.checker", "Polaris")
+			assert.NoError(t, err)
+			assert.Equal(t, expect.Token, svc.Token)
+			assert.Equal(t, expect.Revision, svc.Revision)
+		}
+	})
+
+	t.Run("跟随者写入后立即可读，并复制到其他节点", func(t *testing.T) {
+		follower := cluster.follower()
+		assert.NotNil(t, follower)
+		assert.NoError(t, follower.AddNamespace(newTestNamespace("raft-ns")))
+
+		ns, err := follower.GetNamespace("raft-ns")
+		assert.NoError(t, err)
+		assert.NotNil(t, ns)
+		for _, node := range cluster.nodes {
+			assert.Eventually(t, func() bool {
+				ns, err := node.GetNamespace("raft-ns")
+				return err == nil && ns != nil && ns.Token == "raft-ns-token"
+			}, 5*time.Second, 10*time.Millisecond)
+		}
+	})
+
+	t.Run("返回值以及错误在节点间传递", func(t *testing.T) {
+		sids := map[string]struct{}{}
+		for _, node := range cluster.nodes {
+			sid, err := node.GenNextL5Sid(1)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, sid)
+			sids[sid] = struct{}{}
+		}
+		assert.Len(t, sids, len(cluster.nodes))
+
+		err := cluster.follower().AddNamespace(&model.Namespace{})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "name is empty")
+	})
+
+	t.Run("客户端对象的不可导出字段", func(t *testing.T) {
+		follower := cluster.follower()
+		client := model.NewClient(&apiservice.Client{
+			Id:   utils.NewStringValue("raft-client"),
+			Host: utils.NewStringValue("127.0.0.1"),
+		})
+		err := follower.BatchAddClients([]*model.Client{client})
+		assert.NoError(t, err)
+
+		clients, err := cluster.leader().GetMoreClients(time.Time{}, true)
+		assert.NoError(t, err)
+		assert.Contains(t, clients, "raft-client")
+		assert.Equal(t, "127.0.0.1", clients["raft-client"].Proto().GetHost().GetValue())
+	})
+}
+
+func Test_RaftStore_Tx(t *testing.T) {
+	cluster := newTestCluster(t, 3)
+	follower := cluster.follower()
+
+	t.Run("事务提交后写操作生效", func(t *testing.T) {
+		tx, err := follower.StartTx()
+		assert.NoError(t, err)
+		err = follower.CreateConfigFileTx(tx, &model.ConfigFile{
+			Namespace: "default",
+			Group:     "group",
+			Name:      "commit.yaml",
+			Content:   "a: 1",
+		})
+		assert.NoError(t, err)
+
+		file, err := follower.GetConfigFile("default", "group", "commit.yaml")
+		assert.NoError(t, err)
+		assert.Nil(t, file)
+
+		assert.NoError(t, tx.Commit())
+		for _, node := range cluster.nodes {
+			assert.Eventually(t, func() bool {
+				file, err := node.GetConfigFile("default", "group", "commit.yaml")
+				return err == nil && file != nil && file.Content == "a: 1"
+			}, 5*time.Second, 10*time.Millisecond)
+		}
+	})
+
+	t.Run("事务回滚后写操作被丢弃", func(t *testing.T) {
+		tx, err := follower.StartTx()
+		assert.NoError(t, err)
+		err = follower.CreateConfigFileTx(tx, &model.ConfigFile{
+			Namespace: "default",
+			Group:     "group",
+			Name:      "rollback.yaml",
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Rollback())
+
+		file, err := cluster.leader().GetConfigFile("default", "group", "rollback.yaml")
+		assert.NoError(t, err)
+		assert.Nil(t, file)
+	})
+
+	t.Run("配置文件锁在节点间互斥", func(t *testing.T) {
+		key := &model.ConfigFileKey{Namespace: "default", Group: "group", Name: "commit.yaml"}
+		tx1, err := cluster.leader().StartTx()
+		assert.NoError(t, err)
+		file, err := cluster.leader().LockConfigFile(tx1, key)
+		assert.NoError(t, err)
+		assert.NotNil(t, file)
+
+		locked := make(chan struct{})
+		go func() {
+			defer close(locked)
+			tx2, err := follower.StartTx()
+			assert.NoError(t, err)
+			_, err = follower.LockConfigFile(tx2, key)
+			assert.NoError(t, err)
+			_ = tx2.Rollback()
+		}()
+
+		select {
+		case <-locked:
+			t.Fatal("config file lock should be exclusive")
+		case <-time.After(300 * time.Millisecond):
+		}
+		assert.NoError(t, tx1.Commit())
+		select {
+		case <-locked:
+		case <-time.After(5 * time.Second):
+			t.Fatal("wait config file lock timeout")
+		}
+	})
+}
+
+func Test_RaftStore_Transaction(t *testing.T) {
+	cluster := newTestCluster(t, 3)
+
+	tx1, err := cluster.nodes[0].CreateTransaction()
+	assert.NoError(t, err)
+	assert.NoError(t, tx1.LockBootstrap("bootstrap", "127.0.0.1"))
+
+	locked := make(chan struct{})
+	go func() {
+		defer close(locked)
+		tx2, err := cluster.nodes[1].CreateTransaction()
+		assert.NoError(t, err)
+		assert.NoError(t, tx2.LockBootstrap("bootstrap", "127.0.0.2"))
+		_ = tx2.Commit()
+	}()
+	select {
+	case <-locked:
+		t.Fatal("bootstrap lock should be exclusive")
+	case <-time.After(300 * time.Millisecond):
+	}
+	assert.NoError(t, tx1.Commit())
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("wait bootstrap lock timeout")
+	}
+
+	// 共享锁之间可以同时持有
+	tx3, err := cluster.nodes[0].CreateTransaction()
+	assert.NoError(t, err)
+	tx4, err := cluster.nodes[2].CreateTransaction()
+	assert.NoError(t, err)
+	svc, err := tx3.RLockService("polaris.checker", "Polaris")
+	assert.NoError(t, err)
+	assert.NotNil(t, svc)
+	_, err = tx4.RLockService("polaris.checker", "Polaris")
+	assert.NoError(t, err)
+	assert.NoError(t, tx3.Commit())
+	assert.NoError(t, tx4.Commit())
+
+	// 删除命名空间在所有节点生效
+	assert.NoError(t, cluster.nodes[0].AddNamespace(newTestNamespace("delete-ns")))
+	tx5, err := cluster.nodes[1].CreateTransaction()
+	assert.NoError(t, err)
+	ns, err := tx5.LockNamespace("delete-ns")
+	assert.NoError(t, err)
+	assert.NotNil(t, ns)
+	assert.NoError(t, tx5.DeleteNamespace("delete-ns"))
+	for _, node := range cluster.nodes {
+		assert.Eventually(t, func() bool {
+			ns, err := node.GetNamespace("delete-ns")
+			return err == nil && ns == nil
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+}
+
+func Test_RaftStore_LeaderElection(t *testing.T) {
+	cluster := newTestCluster(t, 3)
+	const key = "raft-election"
+
+	for _, node := range cluster.nodes {
+		assert.NoError(t, node.StartLeaderElection(key))
+	}
+	currentLeader := func() (*raftStore, int) {
+		var (
+			leader *raftStore
+			count  int
+		)
+		for _, node := range cluster.nodes {
+			if node.start && node.IsLeader(key) {
+				leader = node
+				count++
+			}
+		}
+		return leader, count
+	}
+	assert.Eventually(t, func() bool {
+		_, count := currentLeader()
+		return count == 1
+	}, 10*time.Second, 20*time.Millisecond)
+
+	leader, _ := currentLeader()
+	for _, node := range cluster.nodes {
+		assert.Eventually(t, func() bool {
+			elections, err := node.ListLeaderElections()
+			return err == nil && len(elections) == 1 && elections[0].Host == leader.admin.host() &&
+				elections[0].Valid
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	// 主动释放后由其他节点接替
+	assert.NoError(t, leader.ReleaseLeaderElection(key))
+	assert.Eventually(t, func() bool {
+		next, count := currentLeader()
+		return count == 1 && next != leader
+	}, 10*time.Second, 20*time.Millisecond)
+
+	// leader 节点宕机后由其他节点接替
+	leader, _ = currentLeader()
+	assert.NoError(t, leader.Destroy())
+	assert.Eventually(t, func() bool {
+		next, count := currentLeader()
+		return count == 1 && next != leader
+	}, 10*time.Second, 20*time.Millisecond)
+}
+
+type memSnapshotSink struct {
+	bytes.Buffer
+}
+
+func (s *memSnapshotSink) ID() string {
+	return "test"
+}
+
+func (s *memSnapshotSink) Cancel() error {
+	return errors.New("cancel")
+}
+
+func (s *memSnapshotSink) Close() error {
+	return nil
+}
+
+func Test_FSM_SnapshotRestore(t *testing.T) {
+	source, err := openLocalStore(filepath.Join(t.TempDir(), localStoreFile), "")
+	assert.NoError(t, err)
+	defer source.Destroy()
+	target, err := openLocalStore(filepath.Join(t.TempDir(), localStoreFile), "")
+	assert.NoError(t, err)
+	defer target.Destroy()
+
+	sourceFSM := newFSM(source)
+	apply := func(cmd *command) *commandResult {
+		data, err := json.Marshal(cmd)
+		assert.NoError(t, err)
+		return sourceFSM.Apply(&raft.Log{Index: 1, Data: data}).(*commandResult)
+	}
+	cmd, err := newStoreCommand("AddNamespace", newTestNamespace("snapshot-ns"))
+	assert.NoError(t, err)
+	assert.NoError(t, apply(cmd).Err())
+	assert.NoError(t, apply(&command{Type: commandCreateElection, Key: "snapshot", Now: time.Now().UnixNano()}).Err())
+
+	snapshot, err := sourceFSM.Snapshot()
+	assert.NoError(t, err)
+	sink := &memSnapshotSink{}
+	assert.NoError(t, snapshot.Persist(sink))
+
+	targetFSM := newFSM(target)
+	assert.NoError(t, targetFSM.Restore(io.NopCloser(&sink.Buffer)))
+	ns, err := target.GetNamespace("snapshot-ns")
+	assert.NoError(t, err)
+	assert.NotNil(t, ns)
+	_, ok := targetFSM.getElection("snapshot")
+	assert.True(t, ok)
+}
+
+func Test_FSM_Deterministic(t *testing.T) {
+	stores := make([]localStore, 0, 2)
+	fsms := make([]*fsm, 0, 2)
+	for i := 0; i < 2; i++ {
+		local, err := openLocalStore(filepath.Join(t.TempDir(), localStoreFile), "")
+		assert.NoError(t, err)
+		defer local.Destroy()
+		stores = append(stores, local)
+		fsms = append(fsms, newFSM(local))
+	}
+	var index uint64
+	applyAll := func(cmd *command) {
+		index++
+		data, err := json.Marshal(cmd)
+		assert.NoError(t, err)
+		for _, f := range fsms {
+			assert.NoError(t, f.Apply(&raft.Log{Index: index, Data: data}).(*commandResult).Err())
+		}
+	}
+
+	now := time.Now().Add(-time.Hour).UnixNano()
+	cmd, err := newStoreCommand("AddNamespace", newTestNamespace("deterministic-ns"))
+	assert.NoError(t, err)
+	cmd.Now = now
+	applyAll(cmd)
+	for _, local := range stores {
+		ns, err := local.GetNamespace("deterministic-ns")
+		assert.NoError(t, err)
+		assert.Equal(t, now, ns.ModifyTime.UnixNano())
+	}
+
+	t.Run("只清理命令中列出的实例", func(t *testing.T) {
+		ids := []string{"ins-1", "ins-2", "ins-3"}
+		for _, id := range ids {
+			cmd, err := newStoreCommand("AddInstance", &model.Instance{
+				Proto: &apiservice.Instance{
+					Id:        utils.NewStringValue(id),
+					Service:   utils.NewStringValue("svc"),
+					Namespace: utils.NewStringValue("default"),
+					Host:      utils.NewStringValue("127.0.0.1"),
+				},
+				ServiceID: "svc-id",
+			})
+			assert.NoError(t, err)
+			cmd.Now = now
+			applyAll(cmd)
+			cmd, err = newStoreCommand("DeleteInstance", id)
+			assert.NoError(t, err)
+			cmd.Now = now
+			applyAll(cmd)
+		}
+		deleted, err := stores[0].ListDeletedInstances(time.Unix(0, now), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"ins-1", "ins-2"}, deleted)
+
+		cmd, err := newStoreCommand("CleanDeletedInstances", deleted, time.Unix(0, now))
+		assert.NoError(t, err)
+		cmd.Now = time.Now().UnixNano()
+		applyAll(cmd)
+		for _, local := range stores {
+			left, err := local.ListDeletedInstances(time.Now(), 10)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"ins-3"}, left)
+		}
+	})
+
+	t.Run("拒绝执行非写方法", func(t *testing.T) {
+		cmd := &command{Type: commandStore, Method: "Destroy"}
+		data, err := json.Marshal(cmd)
+		assert.NoError(t, err)
+		result := fsms[0].Apply(&raft.Log{Index: index + 1, Data: data}).(*commandResult)
+		assert.Error(t, result.Err())
+		ns, err := stores[0].GetNamespace("deterministic-ns")
+		assert.NoError(t, err)
+		assert.NotNil(t, ns)
+	})
+}
@@ -0,0 +1,177 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package raftdb
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	forwardPath  = "/raft/apply"
+	headerSecret = "X-Polaris-Raft-Secret"
+)
+
+// errRejectedCommand 转发过来的命令不允许由其他节点发起
+var errRejectedCommand = errors.New("raft command can not be forwarded")
+
+// checkForward 跟随者只能转发写命令以及选主、事务锁相关的命令，初始化数据只能由 leader 自己提交，
+// 存储写命令的方法必须在 storeWriteMethods 中
+func checkForward(cmd *command) error {
+	switch cmd.Type {
+	case commandStore:
+		if !isStoreWriteMethod(cmd.Method) {
+			return fmt.Errorf("%w: store method %s", errRejectedCommand, cmd.Method)
+		}
+		return nil
+	case commandBatch:
+		for _, item := range cmd.Batch {
+			if item.Type != commandStore {
+				return fmt.Errorf("%w: type %d in batch", errRejectedCommand, item.Type)
+			}
+			if err := checkForward(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case commandDeleteNamespace, commandCreateElection, commandCASElection, commandLock, commandUnlock:
+		return nil
+	default:
+		return fmt.Errorf("%w: type %d", errRejectedCommand, cmd.Type)
+	}
+}
+
+// forwardHandler leader 处理转发过来的命令
+type forwardHandler func(data []byte) (*commandResult, error)
+
+// forwarder 跟随者通过 forwarder 将写命令交给 leader 执行
+type forwarder interface {
+	// Forward 将命令发送给 peer 执行，peer 不是 leader 时返回 errNotLeader
+	Forward(peer *PeerConfig, data []byte) (*commandResult, error)
+	// Close 停止接收转发的命令
+	Close() error
+}
+
+// httpForwarder 通过 HTTP 在节点间转发命令
+type httpForwarder struct {
+	secret string
+	client *http.Client
+	server *http.Server
+}
+
+func newHTTPForwarder(cfg *Config, handler forwardHandler) (*httpForwarder, error) {
+	f := &httpForwarder{
+		secret: cfg.Secret,
+		client: &http.Client{Timeout: cfg.ApplyTimeout},
+	}
+	address := cfg.self().ForwardAddress
+	if address == "" {
+		return nil, fmt.Errorf("raft store peer %s forwardAddress is empty", cfg.NodeID)
+	}
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(forwardPath, f.serve(handler))
+	f.server = &http.Server{Handler: mux}
+	go func() {
+		if err := f.server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Error("[Store][Raft] forward server stopped", zap.Error(err))
+		}
+	}()
+	return f, nil
+}
+
+func (f *httpForwarder) serve(handler forwardHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if f.secret == "" ||
+			subtle.ConstantTimeCompare([]byte(r.Header.Get(headerSecret)), []byte(f.secret)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err := handler(data)
+		if err == errNotLeader {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if errors.Is(err, errRejectedCommand) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(result)
+	}
+}
+
+// Forward 将命令发送给 peer 执行
+func (f *httpForwarder) Forward(peer *PeerConfig, data []byte) (*commandResult, error) {
+	req, err := http.NewRequest(http.MethodPost, "http://"+peer.ForwardAddress+forwardPath, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(headerSecret, f.secret)
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		result := &commandResult{}
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case http.StatusServiceUnavailable:
+		return nil, errNotLeader
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("forward to raft leader %s fail, status %d: %s",
+			peer.ID, resp.StatusCode, bytes.TrimSpace(body))
+	}
+}
+
+// Close 停止转发服务
+func (f *httpForwarder) Close() error {
+	return f.server.Close()
+}
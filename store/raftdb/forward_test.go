@@ -0,0 +1,98 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package raftdb
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/store"
+)
+
+func Test_HTTPForwarder(t *testing.T) {
+	leader := true
+	handler := func(data []byte) (*commandResult, error) {
+		if !leader {
+			return nil, errNotLeader
+		}
+		return newCommandResult([]interface{}{string(data)}, store.NewStatusError(store.NotFoundService, "not found")), nil
+	}
+	server := &httpForwarder{secret: "raft-secret", client: http.DefaultClient}
+	ts := httptest.NewServer(server.serve(handler))
+	defer ts.Close()
+	peer := &PeerConfig{ID: "node-1", ForwardAddress: ts.Listener.Addr().String()}
+
+	t.Run("返回值以及存储层错误码", func(t *testing.T) {
+		client := &httpForwarder{secret: "raft-secret", client: http.DefaultClient}
+		result, err := client.Forward(peer, []byte("cmd"))
+		assert.NoError(t, err)
+		var value string
+		err = result.decode(&value)
+		assert.Equal(t, "cmd", value)
+		assert.Equal(t, store.NotFoundService, store.Code(err))
+	})
+
+	t.Run("密钥不正确", func(t *testing.T) {
+		client := &httpForwarder{secret: "wrong", client: http.DefaultClient}
+		_, err := client.Forward(peer, []byte("cmd"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "401")
+	})
+
+	t.Run("目标节点不是leader", func(t *testing.T) {
+		leader = false
+		defer func() {
+			leader = true
+		}()
+		client := &httpForwarder{secret: "raft-secret", client: http.DefaultClient}
+		_, err := client.Forward(peer, []byte("cmd"))
+		assert.Equal(t, errNotLeader, err)
+	})
+	t.Run("未配置密钥时拒绝全部请求", func(t *testing.T) {
+		empty := &httpForwarder{client: http.DefaultClient}
+		ts := httptest.NewServer(empty.serve(handler))
+		defer ts.Close()
+		_, err := empty.Forward(&PeerConfig{ID: "node-2", ForwardAddress: ts.Listener.Addr().String()}, []byte("cmd"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "401")
+	})
+}
+
+func Test_CheckForward(t *testing.T) {
+	write, err := newStoreCommand("AddNamespace", newTestNamespace("forward-ns"))
+	assert.NoError(t, err)
+	assert.NoError(t, checkForward(write))
+	assert.NoError(t, checkForward(&command{Type: commandBatch, Batch: []*command{write}}))
+	assert.NoError(t, checkForward(&command{Type: commandLock, Key: "lock"}))
+
+	rejects := map[string]*command{
+		"初始化数据":      {Type: commandSeed, Data: []byte("data")},
+		"未知的命令类型":    {Type: commandType(100)},
+		"非写方法":       {Type: commandStore, Method: "Destroy"},
+		"事务中的非写方法":   {Type: commandBatch, Batch: []*command{write, {Type: commandStore, Method: "Restore"}}},
+		"事务中嵌套初始化数据": {Type: commandBatch, Batch: []*command{{Type: commandSeed}}},
+	}
+	for name, cmd := range rejects {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, checkForward(cmd), errRejectedCommand)
+		})
+	}
+}
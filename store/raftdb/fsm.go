@@ -0,0 +1,538 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package raftdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/raft"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+// localStore 每个节点本地保存完整数据的存储，raft 状态机的实际载体
+type localStore interface {
+	store.Store
+	// Backup 导出本地存储的全部数据
+	Backup(w io.Writer) error
+	// Restore 使用导出的数据覆盖本地存储
+	Restore(r io.Reader) error
+	// SetClock 替换本地存储写操作使用的时间来源
+	SetClock(clock func() time.Time)
+	// ListDeletedInstances 按照 ID 的顺序获取 mtime 之前被软删除的实例
+	ListDeletedInstances(mtime time.Time, limit uint32) ([]string, error)
+	// CleanDeletedInstances 清理 ids 中在 mtime 之前被软删除的实例
+	CleanDeletedInstances(ids []string, mtime time.Time) (uint32, error)
+	// ListDeletedClients 按照 ID 的顺序获取 mtime 之前被软删除的客户端
+	ListDeletedClients(mtime time.Time, limit uint32) ([]string, error)
+	// CleanDeletedClients 清理 ids 中在 mtime 之前被软删除的客户端
+	CleanDeletedClients(ids []string, mtime time.Time) (uint32, error)
+}
+
+// electionRecord 选主记录，语义与数据库存储中的 leader_election 表一致
+type electionRecord struct {
+	Key     string `json:"key"`
+	Leader  string `json:"leader"`
+	Version int64  `json:"version"`
+	// Ctime、Mtime 为 leader 提交命令时的时间戳（纳秒）
+	Ctime int64 `json:"ctime"`
+	Mtime int64 `json:"mtime"`
+}
+
+// lockRecord 事务锁
+type lockRecord struct {
+	Shared bool `json:"shared"`
+	// Owners 持有者 -> 过期时间（纳秒）
+	Owners map[string]int64 `json:"owners"`
+}
+
+// fsmState 状态机中除本地存储之外的数据
+type fsmState struct {
+	Seeded    bool                       `json:"seeded"`
+	Elections map[string]*electionRecord `json:"elections"`
+	Locks     map[string]*lockRecord     `json:"locks"`
+}
+
+var txType = reflect.TypeOf((*store.Tx)(nil)).Elem()
+
+// storeWriteMethods 允许通过 raft 日志在本地存储上执行的写方法，不在列表中的方法一律拒绝
+var storeWriteMethods = []string{
+	"ActiveConfigFileReleaseTx",
+	"AddAccessToken",
+	"AddGroup",
+	"AddInstance",
+	"AddNamespace",
+	"AddOperationRecords",
+	"AddService",
+	"AddServiceContractInterfaces",
+	"AddStrategy",
+	"AddUser",
+	"AppendServiceContractInterfaces",
+	"BatchAddClients",
+	"BatchAddInstances",
+	"BatchAppendInstanceMetadata",
+	"BatchDeleteClients",
+	"BatchDeleteInstances",
+	"BatchRemoveInstanceMetadata",
+	"BatchSetInstanceHealthStatus",
+	"BatchSetInstanceIsolate",
+	"CleanConfigFileReleaseHistory",
+	"CleanConfigFileReleasesTx",
+	"CleanDeletedClients",
+	"CleanDeletedInstances",
+	"CleanGrayResource",
+	"CleanInstance",
+	"CleanOperationRecords",
+	"CreateCircuitBreakerRule",
+	"CreateConfigFileGroup",
+	"CreateConfigFileReleaseHistory",
+	"CreateConfigFileReleaseTx",
+	"CreateConfigFileTemplate",
+	"CreateConfigFileTx",
+	"CreateFaultDetectRule",
+	"CreateGrayResourceTx",
+	"CreateRateLimit",
+	"CreateRoutingConfig",
+	"CreateRoutingConfigV2",
+	"CreateRoutingConfigV2Tx",
+	"CreateServiceContract",
+	"DeleteAccessToken",
+	"DeleteCircuitBreakerRule",
+	"DeleteConfigFileGroup",
+	"DeleteConfigFileReleaseTx",
+	"DeleteConfigFileTx",
+	"DeleteFaultDetectRule",
+	"DeleteGroup",
+	"DeleteInstance",
+	"DeleteRateLimit",
+	"DeleteRoutingConfig",
+	"DeleteRoutingConfigTx",
+	"DeleteRoutingConfigV2",
+	"DeleteService",
+	"DeleteServiceAlias",
+	"DeleteServiceContract",
+	"DeleteServiceContractInterfaces",
+	"DeleteStrategy",
+	"DeleteUser",
+	"EnableCircuitBreakerRule",
+	"EnableRateLimit",
+	"EnableRouting",
+	"GenNextL5Sid",
+	"InactiveConfigFileReleaseTx",
+	"LooseAddStrategyResources",
+	"RemoveStrategyResources",
+	"SetInstanceHealthStatus",
+	"SetL5Extend",
+	"UpdateAccessTokensLastUsed",
+	"UpdateCircuitBreakerRule",
+	"UpdateConfigFileGroup",
+	"UpdateConfigFileReleaseHistoryMetadata",
+	"UpdateConfigFileReleaseMetadataTx",
+	"UpdateConfigFileTx",
+	"UpdateFaultDetectRule",
+	"UpdateGroup",
+	"UpdateInstance",
+	"UpdateNamespace",
+	"UpdateNamespaceToken",
+	"UpdateRateLimit",
+	"UpdateRoutingConfig",
+	"UpdateRoutingConfigV2",
+	"UpdateRoutingConfigV2Tx",
+	"UpdateService",
+	"UpdateServiceAlias",
+	"UpdateServiceContract",
+	"UpdateServiceToken",
+	"UpdateStrategy",
+	"UpdateUser",
+}
+
+// argConverters 部分模型对象存在不可导出的字段，无法直接通过 JSON 在节点间传递，
+// 这类方法的参数在提交时先转换为可序列化的结构，在状态机中再还原
+var argConverters = map[string]func(args []json.RawMessage) ([]reflect.Value, error){
+	"BatchAddClients": func(args []json.RawMessage) ([]reflect.Value, error) {
+		var protos []*apiservice.Client
+		if len(args) > 0 {
+			if err := json.Unmarshal(args[0], &protos); err != nil {
+				return nil, err
+			}
+		}
+		clients := make([]*model.Client, 0, len(protos))
+		for i := range protos {
+			clients = append(clients, model.NewClient(protos[i]))
+		}
+		return []reflect.Value{reflect.ValueOf(clients)}, nil
+	},
+}
+
+// fsm raft 状态机，所有的写操作都在这里落到本地存储
+type fsm struct {
+	local   localStore
+	methods map[string]reflect.Value
+	// now 正在执行的命令由 leader 设置的时间戳（纳秒），作为本地存储写操作的时间
+	now int64
+
+	lock  sync.RWMutex
+	state *fsmState
+}
+
+func newFSM(local localStore) *fsm {
+	f := &fsm{
+		local:   local,
+		methods: make(map[string]reflect.Value, len(storeWriteMethods)),
+		state: &fsmState{
+			Elections: map[string]*electionRecord{},
+			Locks:     map[string]*lockRecord{},
+		},
+	}
+	target := reflect.ValueOf(local)
+	for _, name := range storeWriteMethods {
+		if method := target.MethodByName(name); method.IsValid() {
+			f.methods[name] = method
+		}
+	}
+	local.SetClock(f.clock)
+	return f
+}
+
+// clock 状态机执行命令时本地存储使用的时间，所有节点以及日志重放时都相同
+func (f *fsm) clock() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&f.now))
+}
+
+// isStoreWriteMethod 是否允许通过 raft 日志执行的写方法
+func isStoreWriteMethod(name string) bool {
+	for _, method := range storeWriteMethods {
+		if method == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply 执行一条已经提交的 raft 日志
+func (f *fsm) Apply(l *raft.Log) interface{} {
+	cmd := &command{}
+	var result *commandResult
+	if err := json.Unmarshal(l.Data, cmd); err != nil {
+		log.Error("[Store][Raft] decode command fail", zap.Uint64("index", l.Index), zap.Error(err))
+		result = newCommandResult(nil, err)
+	} else {
+		result = f.execute(cmd)
+	}
+	result.Index = l.Index
+	return result
+}
+
+func (f *fsm) execute(cmd *command) *commandResult {
+	atomic.StoreInt64(&f.now, cmd.Now)
+	switch cmd.Type {
+	case commandStore:
+		return newCommandResult(f.invoke(nil, cmd))
+	case commandBatch:
+		return newCommandResult(nil, f.executeBatch(cmd.Batch))
+	case commandSeed:
+		return newCommandResult(nil, f.seed(cmd.Data))
+	case commandDeleteNamespace:
+		tx, err := f.local.CreateTransaction()
+		if err != nil {
+			return newCommandResult(nil, err)
+		}
+		err = tx.DeleteNamespace(cmd.Key)
+		_ = tx.Commit()
+		return newCommandResult(nil, err)
+	case commandCreateElection:
+		f.createElection(cmd)
+		return newCommandResult(nil, nil)
+	case commandCASElection:
+		return newCommandResult([]interface{}{f.casElection(cmd)}, nil)
+	case commandLock:
+		return newCommandResult([]interface{}{f.tryLock(cmd)}, nil)
+	case commandUnlock:
+		f.unlock(cmd)
+		return newCommandResult(nil, nil)
+	default:
+		return newCommandResult(nil, fmt.Errorf("unknown raft command type %d", cmd.Type))
+	}
+}
+
+// invoke 调用本地存储中允许复制的同名写方法，tx 不为空时作为方法的第一个事务参数
+func (f *fsm) invoke(tx store.Tx, cmd *command) (values []interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("apply store method %s panic: %v", cmd.Method, r)
+			log.Error("[Store][Raft] apply store method panic", zap.String("method", cmd.Method), zap.Any("err", r))
+		}
+	}()
+
+	method, ok := f.methods[cmd.Method]
+	if !ok {
+		return nil, fmt.Errorf("unknown store method %s", cmd.Method)
+	}
+	in, err := f.decodeArgs(method.Type(), tx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	out := method.Call(in)
+	for i := 0; i < len(out)-1; i++ {
+		values = append(values, out[i].Interface())
+	}
+	if last := out[len(out)-1]; !last.IsNil() {
+		err = last.Interface().(error)
+	}
+	return values, err
+}
+
+func (f *fsm) decodeArgs(mt reflect.Type, tx store.Tx, cmd *command) ([]reflect.Value, error) {
+	in := make([]reflect.Value, 0, mt.NumIn())
+	if mt.NumIn() > 0 && mt.In(0) == txType {
+		if tx == nil {
+			return nil, fmt.Errorf("store method %s must be executed in transaction", cmd.Method)
+		}
+		in = append(in, reflect.ValueOf(tx))
+	}
+	if converter, ok := argConverters[cmd.Method]; ok {
+		args, err := converter(cmd.Args)
+		if err != nil {
+			return nil, err
+		}
+		return append(in, args...), nil
+	}
+	if mt.NumIn()-len(in) != len(cmd.Args) {
+		return nil, fmt.Errorf("store method %s args count not match", cmd.Method)
+	}
+	for i := range cmd.Args {
+		arg := reflect.New(mt.In(len(in)))
+		if err := json.Unmarshal(cmd.Args[i], arg.Interface()); err != nil {
+			return nil, err
+		}
+		in = append(in, arg.Elem())
+	}
+	return in, nil
+}
+
+// executeBatch 在本地存储的一个写事务中执行事务提交的全部写操作，任意一个失败则整体回滚
+func (f *fsm) executeBatch(batch []*command) error {
+	tx, err := f.local.StartTx()
+	if err != nil {
+		return err
+	}
+	for _, cmd := range batch {
+		if _, err := f.invoke(tx, cmd); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (f *fsm) seed(data []byte) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.state.Seeded {
+		return nil
+	}
+	if err := f.local.Restore(bytes.NewReader(data)); err != nil {
+		return err
+	}
+	f.state.Seeded = true
+	return nil
+}
+
+func (f *fsm) isSeeded() bool {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.state.Seeded
+}
+
+func (f *fsm) createElection(cmd *command) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if _, ok := f.state.Elections[cmd.Key]; ok {
+		return
+	}
+	f.state.Elections[cmd.Key] = &electionRecord{Key: cmd.Key, Ctime: cmd.Now, Mtime: cmd.Now}
+}
+
+func (f *fsm) casElection(cmd *command) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	record, ok := f.state.Elections[cmd.Key]
+	if !ok || record.Version != cmd.Version {
+		return false
+	}
+	record.Version = cmd.NewVersion
+	record.Leader = cmd.Owner
+	record.Mtime = cmd.Now
+	return true
+}
+
+func (f *fsm) getElection(key string) (electionRecord, bool) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	record, ok := f.state.Elections[key]
+	if !ok {
+		return electionRecord{}, false
+	}
+	return *record, true
+}
+
+func (f *fsm) listElections() []electionRecord {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	records := make([]electionRecord, 0, len(f.state.Elections))
+	for _, record := range f.state.Elections {
+		records = append(records, *record)
+	}
+	return records
+}
+
+// tryLock 获取事务锁，排它锁与任何其他持有者互斥，共享锁之间可以同时持有，过期的持有者会被直接清理
+func (f *fsm) tryLock(cmd *command) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	record, ok := f.state.Locks[cmd.Key]
+	if ok {
+		for owner, expire := range record.Owners {
+			if expire <= cmd.Now {
+				delete(record.Owners, owner)
+			}
+		}
+		if len(record.Owners) == 0 {
+			ok = false
+		}
+	}
+	expire := cmd.Now + cmd.TTL
+	if !ok {
+		f.state.Locks[cmd.Key] = &lockRecord{Shared: cmd.Shared, Owners: map[string]int64{cmd.Owner: expire}}
+		return true
+	}
+	_, held := record.Owners[cmd.Owner]
+	if held && len(record.Owners) == 1 {
+		record.Shared = record.Shared && cmd.Shared
+		record.Owners[cmd.Owner] = expire
+		return true
+	}
+	if record.Shared && cmd.Shared {
+		record.Owners[cmd.Owner] = expire
+		return true
+	}
+	return false
+}
+
+func (f *fsm) unlock(cmd *command) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, key := range cmd.Keys {
+		record, ok := f.state.Locks[key]
+		if !ok {
+			continue
+		}
+		delete(record.Owners, cmd.Owner)
+		if len(record.Owners) == 0 {
+			delete(f.state.Locks, key)
+		}
+	}
+}
+
+// Snapshot 生成状态机快照，包括选主记录、事务锁以及本地存储的全部数据
+func (f *fsm) Snapshot() (raft.FSMSnapshot, error) {
+	f.lock.RLock()
+	state, err := json.Marshal(f.state)
+	f.lock.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	data := &bytes.Buffer{}
+	if err := f.local.Backup(data); err != nil {
+		return nil, err
+	}
+	return &fsmSnapshot{state: state, data: data.Bytes()}, nil
+}
+
+// Restore 使用快照覆盖当前状态机
+func (f *fsm) Restore(rc io.ReadCloser) error {
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	var size uint32
+	if err := binary.Read(rc, binary.BigEndian, &size); err != nil {
+		return err
+	}
+	stateData := make([]byte, size)
+	if _, err := io.ReadFull(rc, stateData); err != nil {
+		return err
+	}
+	state := &fsmState{}
+	if err := json.Unmarshal(stateData, state); err != nil {
+		return err
+	}
+	if state.Elections == nil {
+		state.Elections = map[string]*electionRecord{}
+	}
+	if state.Locks == nil {
+		state.Locks = map[string]*lockRecord{}
+	}
+	if err := f.local.Restore(rc); err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	f.state = state
+	f.lock.Unlock()
+	return nil
+}
+
+type fsmSnapshot struct {
+	state []byte
+	data  []byte
+}
+
+// Persist 快照格式：4 字节的状态长度 + 状态 JSON + 本地存储数据
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	err := func() error {
+		if err := binary.Write(sink, binary.BigEndian, uint32(len(s.state))); err != nil {
+			return err
+		}
+		if _, err := sink.Write(s.state); err != nil {
+			return err
+		}
+		_, err := sink.Write(s.data)
+		return err
+	}()
+	if err != nil {
+		_ = sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+// Release 快照数据保存在内存中，无需释放
+func (s *fsmSnapshot) Release() {}
@@ -0,0 +1,178 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package raftdb
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/raft"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketLogs   = []byte("logs")
+	bucketStable = []byte("stable")
+
+	// errKeyNotFound raft 通过错误信息判断 StableStore 中的 key 是否存在
+	errKeyNotFound = errors.New("not found")
+)
+
+// logStore 基于 boltdb 实现的 raft.LogStore 以及 raft.StableStore
+type logStore struct {
+	db *bolt.DB
+}
+
+func newLogStore(path string) (*logStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(bucketLogs); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(bucketStable)
+		return err
+	}); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &logStore{db: db}, nil
+}
+
+// FirstIndex returns the first index written. 0 for no entries.
+func (s *logStore) FirstIndex() (uint64, error) {
+	var index uint64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if k, _ := tx.Bucket(bucketLogs).Cursor().First(); k != nil {
+			index = binary.BigEndian.Uint64(k)
+		}
+		return nil
+	})
+	return index, err
+}
+
+// LastIndex returns the last index written. 0 for no entries.
+func (s *logStore) LastIndex() (uint64, error) {
+	var index uint64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if k, _ := tx.Bucket(bucketLogs).Cursor().Last(); k != nil {
+			index = binary.BigEndian.Uint64(k)
+		}
+		return nil
+	})
+	return index, err
+}
+
+// GetLog gets a log entry at a given index.
+func (s *logStore) GetLog(index uint64, l *raft.Log) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucketLogs).Get(uint64ToBytes(index))
+		if v == nil {
+			return raft.ErrLogNotFound
+		}
+		return json.Unmarshal(v, l)
+	})
+}
+
+// StoreLog stores a log entry.
+func (s *logStore) StoreLog(l *raft.Log) error {
+	return s.StoreLogs([]*raft.Log{l})
+}
+
+// StoreLogs stores multiple log entries.
+func (s *logStore) StoreLogs(logs []*raft.Log) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketLogs)
+		for _, l := range logs {
+			v, err := json.Marshal(l)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(uint64ToBytes(l.Index), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteRange deletes a range of log entries. The range is inclusive.
+func (s *logStore) DeleteRange(min, max uint64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(bucketLogs).Cursor()
+		for k, _ := cursor.Seek(uint64ToBytes(min)); k != nil; k, _ = cursor.Next() {
+			if binary.BigEndian.Uint64(k) > max {
+				break
+			}
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Set 保存 raft 的元数据
+func (s *logStore) Set(key []byte, val []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStable).Put(key, val)
+	})
+}
+
+// Get 获取 raft 的元数据，不存在时返回 errKeyNotFound
+func (s *logStore) Get(key []byte) ([]byte, error) {
+	var val []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucketStable).Get(key)
+		if v == nil {
+			return errKeyNotFound
+		}
+		val = append([]byte(nil), v...)
+		return nil
+	})
+	return val, err
+}
+
+// SetUint64 保存 uint64 类型的元数据
+func (s *logStore) SetUint64(key []byte, val uint64) error {
+	return s.Set(key, uint64ToBytes(val))
+}
+
+// GetUint64 获取 uint64 类型的元数据，不存在时返回 0
+func (s *logStore) GetUint64(key []byte) (uint64, error) {
+	val, err := s.Get(key)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(val), nil
+}
+
+// Close 关闭底层的 boltdb
+func (s *logStore) Close() error {
+	return s.db.Close()
+}
+
+func uint64ToBytes(v uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, v)
+	return buf
+}
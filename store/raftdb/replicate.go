@@ -0,0 +1,527 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package raftdb
+
+import (
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+// 以下写操作均作为 raft 日志提交到集群，由各个节点的状态机在本地 boltdb 上执行；
+// 读操作则直接由嵌入的本地存储完成
+
+// AddAccessToken Create a personal access token
+func (s *raftStore) AddAccessToken(token *model.AccessToken) error {
+	return s.call("AddAccessToken", nil, token)
+}
+
+// AddGroup Add a user group
+func (s *raftStore) AddGroup(group *model.UserGroupDetail) error {
+	return s.call("AddGroup", nil, group)
+}
+
+// AddInstance 增加一个实例
+func (s *raftStore) AddInstance(instance *model.Instance) error {
+	return s.call("AddInstance", nil, instance)
+}
+
+// AddNamespace Save a namespace
+func (s *raftStore) AddNamespace(namespace *model.Namespace) error {
+	return s.call("AddNamespace", nil, namespace)
+}
+
+// AddOperationRecords batch save operation records
+func (s *raftStore) AddOperationRecords(records []*model.RecordEntry) error {
+	return s.call("AddOperationRecords", nil, records)
+}
+
+// AddService 保存一个服务
+func (s *raftStore) AddService(service *model.Service) error {
+	return s.call("AddService", nil, service)
+}
+
+// AddServiceContractInterfaces 创建服务契约API接口
+func (s *raftStore) AddServiceContractInterfaces(contract *model.EnrichServiceContract) error {
+	return s.call("AddServiceContractInterfaces", nil, contract)
+}
+
+// AddStrategy Create authentication strategy
+func (s *raftStore) AddStrategy(strategy *model.StrategyDetail) error {
+	return s.call("AddStrategy", nil, strategy)
+}
+
+// AddUser Create a user
+func (s *raftStore) AddUser(user *model.User) error {
+	return s.call("AddUser", nil, user)
+}
+
+// AppendServiceContractInterfaces 追加服务契约API接口
+func (s *raftStore) AppendServiceContractInterfaces(contract *model.EnrichServiceContract) error {
+	return s.call("AppendServiceContractInterfaces", nil, contract)
+}
+
+// BatchAddInstances 增加多个实例
+func (s *raftStore) BatchAddInstances(instances []*model.Instance) error {
+	return s.call("BatchAddInstances", nil, instances)
+}
+
+// BatchAppendInstanceMetadata 追加实例 metadata
+func (s *raftStore) BatchAppendInstanceMetadata(requests []*store.InstanceMetadataRequest) error {
+	return s.call("BatchAppendInstanceMetadata", nil, requests)
+}
+
+// BatchCleanDeletedClients batch clean soft deleted clients, 待清理的客户端在提交前确定，
+// 各节点的状态机只清理命令中列出的客户端
+func (s *raftStore) BatchCleanDeletedClients(timeout time.Duration, batchSize uint32) (uint32, error) {
+	mtime := time.Now().Add(-timeout)
+	ids, err := s.local.ListDeletedClients(mtime, batchSize)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	var ret uint32
+	err = s.call("CleanDeletedClients", []interface{}{&ret}, ids, mtime)
+	return ret, err
+}
+
+// BatchCleanDeletedInstances batch clean soft deleted instances, 待清理的实例在提交前确定，
+// 各节点的状态机只清理命令中列出的实例
+func (s *raftStore) BatchCleanDeletedInstances(timeout time.Duration, batchSize uint32) (uint32, error) {
+	mtime := time.Now().Add(-timeout)
+	ids, err := s.local.ListDeletedInstances(mtime, batchSize)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	var ret uint32
+	err = s.call("CleanDeletedInstances", []interface{}{&ret}, ids, mtime)
+	return ret, err
+}
+
+// BatchDeleteClients delete the client info
+func (s *raftStore) BatchDeleteClients(ids []string) error {
+	return s.call("BatchDeleteClients", nil, ids)
+}
+
+// BatchDeleteInstances 批量删除实例，flag=1
+func (s *raftStore) BatchDeleteInstances(ids []interface{}) error {
+	return s.call("BatchDeleteInstances", nil, ids)
+}
+
+// BatchRemoveInstanceMetadata 删除实例指定的 metadata
+func (s *raftStore) BatchRemoveInstanceMetadata(requests []*store.InstanceMetadataRequest) error {
+	return s.call("BatchRemoveInstanceMetadata", nil, requests)
+}
+
+// BatchSetInstanceHealthStatus 批量设置实例的健康状态
+func (s *raftStore) BatchSetInstanceHealthStatus(ids []interface{}, healthy int, revision string) error {
+	return s.call("BatchSetInstanceHealthStatus", nil, ids, healthy, revision)
+}
+
+// BatchSetInstanceIsolate 批量修改实例的隔离状态
+func (s *raftStore) BatchSetInstanceIsolate(ids []interface{}, isolate int, revision string) error {
+	return s.call("BatchSetInstanceIsolate", nil, ids, isolate, revision)
+}
+
+// CleanConfigFileReleaseHistory 清理配置发布历史
+func (s *raftStore) CleanConfigFileReleaseHistory(endTime time.Time, limit uint64) error {
+	return s.call("CleanConfigFileReleaseHistory", nil, endTime, limit)
+}
+
+// CleanInstance 清空一个实例，真正删除
+func (s *raftStore) CleanInstance(instanceID string) error {
+	return s.call("CleanInstance", nil, instanceID)
+}
+
+// CleanOperationRecords delete operation records which happen before endTime
+func (s *raftStore) CleanOperationRecords(endTime time.Time, limit uint64) (uint64, error) {
+	var ret uint64
+	err := s.call("CleanOperationRecords", []interface{}{&ret}, endTime, limit)
+	return ret, err
+}
+
+// CreateCircuitBreakerRule create general circuitbreaker rule
+func (s *raftStore) CreateCircuitBreakerRule(cbRule *model.CircuitBreakerRule) error {
+	return s.call("CreateCircuitBreakerRule", nil, cbRule)
+}
+
+// CreateConfigFileGroup 创建配置文件组
+func (s *raftStore) CreateConfigFileGroup(fileGroup *model.ConfigFileGroup) (*model.ConfigFileGroup, error) {
+	var ret *model.ConfigFileGroup
+	err := s.call("CreateConfigFileGroup", []interface{}{&ret}, fileGroup)
+	return ret, err
+}
+
+// CreateConfigFileReleaseHistory 创建配置文件发布历史记录
+func (s *raftStore) CreateConfigFileReleaseHistory(history *model.ConfigFileReleaseHistory) error {
+	return s.call("CreateConfigFileReleaseHistory", nil, history)
+}
+
+// CreateConfigFileTemplate create config file template
+func (s *raftStore) CreateConfigFileTemplate(template *model.ConfigFileTemplate) (*model.ConfigFileTemplate, error) {
+	var ret *model.ConfigFileTemplate
+	err := s.call("CreateConfigFileTemplate", []interface{}{&ret}, template)
+	return ret, err
+}
+
+// CreateFaultDetectRule create fault detect rule
+func (s *raftStore) CreateFaultDetectRule(conf *model.FaultDetectRule) error {
+	return s.call("CreateFaultDetectRule", nil, conf)
+}
+
+// CreateRateLimit 新增限流规则
+func (s *raftStore) CreateRateLimit(limiting *model.RateLimit) error {
+	return s.call("CreateRateLimit", nil, limiting)
+}
+
+// CreateRoutingConfig 新增一个路由配置
+func (s *raftStore) CreateRoutingConfig(conf *model.RoutingConfig) error {
+	return s.call("CreateRoutingConfig", nil, conf)
+}
+
+// CreateRoutingConfigV2 新增一个路由配置
+func (s *raftStore) CreateRoutingConfigV2(conf *model.RouterConfig) error {
+	return s.call("CreateRoutingConfigV2", nil, conf)
+}
+
+// CreateServiceContract 创建服务契约
+func (s *raftStore) CreateServiceContract(contract *model.ServiceContract) error {
+	return s.call("CreateServiceContract", nil, contract)
+}
+
+// DeleteAccessToken Revoke a personal access token, the token is logically deleted
+func (s *raftStore) DeleteAccessToken(id string) error {
+	return s.call("DeleteAccessToken", nil, id)
+}
+
+// DeleteCircuitBreakerRule delete general circuitbreaker rule
+func (s *raftStore) DeleteCircuitBreakerRule(id string) error {
+	return s.call("DeleteCircuitBreakerRule", nil, id)
+}
+
+// DeleteConfigFileGroup 删除配置文件组
+func (s *raftStore) DeleteConfigFileGroup(namespace, name string) error {
+	return s.call("DeleteConfigFileGroup", nil, namespace, name)
+}
+
+// DeleteFaultDetectRule delete fault detect rule
+func (s *raftStore) DeleteFaultDetectRule(id string) error {
+	return s.call("DeleteFaultDetectRule", nil, id)
+}
+
+// DeleteGroup Delete user group
+func (s *raftStore) DeleteGroup(group *model.UserGroupDetail) error {
+	return s.call("DeleteGroup", nil, group)
+}
+
+// DeleteInstance 删除一个实例，实际是把valid置为false
+func (s *raftStore) DeleteInstance(instanceID string) error {
+	return s.call("DeleteInstance", nil, instanceID)
+}
+
+// DeleteRateLimit 删除限流规则
+func (s *raftStore) DeleteRateLimit(limiting *model.RateLimit) error {
+	return s.call("DeleteRateLimit", nil, limiting)
+}
+
+// DeleteRoutingConfig 删除一个路由配置
+func (s *raftStore) DeleteRoutingConfig(serviceID string) error {
+	return s.call("DeleteRoutingConfig", nil, serviceID)
+}
+
+// DeleteRoutingConfigV2 删除一个路由配置
+func (s *raftStore) DeleteRoutingConfigV2(serviceID string) error {
+	return s.call("DeleteRoutingConfigV2", nil, serviceID)
+}
+
+// DeleteService 删除服务
+func (s *raftStore) DeleteService(id, serviceName, namespaceName string) error {
+	return s.call("DeleteService", nil, id, serviceName, namespaceName)
+}
+
+// DeleteServiceAlias 删除服务别名
+func (s *raftStore) DeleteServiceAlias(name string, namespace string) error {
+	return s.call("DeleteServiceAlias", nil, name, namespace)
+}
+
+// DeleteServiceContract 删除服务契约
+func (s *raftStore) DeleteServiceContract(contract *model.ServiceContract) error {
+	return s.call("DeleteServiceContract", nil, contract)
+}
+
+// DeleteServiceContractInterfaces 批量删除服务契约API接口
+func (s *raftStore) DeleteServiceContractInterfaces(contract *model.EnrichServiceContract) error {
+	return s.call("DeleteServiceContractInterfaces", nil, contract)
+}
+
+// DeleteStrategy Delete authentication strategy
+func (s *raftStore) DeleteStrategy(id string) error {
+	return s.call("DeleteStrategy", nil, id)
+}
+
+// DeleteUser delete users
+func (s *raftStore) DeleteUser(user *model.User) error {
+	return s.call("DeleteUser", nil, user)
+}
+
+// EnableCircuitBreakerRule enable specific circuitbreaker rule
+func (s *raftStore) EnableCircuitBreakerRule(cbRule *model.CircuitBreakerRule) error {
+	return s.call("EnableCircuitBreakerRule", nil, cbRule)
+}
+
+// EnableRateLimit 启用限流规则
+func (s *raftStore) EnableRateLimit(limit *model.RateLimit) error {
+	return s.call("EnableRateLimit", nil, limit)
+}
+
+// EnableRouting 设置路由规则是否启用
+func (s *raftStore) EnableRouting(conf *model.RouterConfig) error {
+	return s.call("EnableRouting", nil, conf)
+}
+
+// GenNextL5Sid 获取module
+func (s *raftStore) GenNextL5Sid(layoutID uint32) (string, error) {
+	var ret string
+	err := s.call("GenNextL5Sid", []interface{}{&ret}, layoutID)
+	return ret, err
+}
+
+// LooseAddStrategyResources Add the resources of the authentication strategy, ignoring the primary key conflict
+func (s *raftStore) LooseAddStrategyResources(resources []model.StrategyResource) error {
+	return s.call("LooseAddStrategyResources", nil, resources)
+}
+
+// RemoveStrategyResources Clean all the strategies associated with corresponding resources
+func (s *raftStore) RemoveStrategyResources(resources []model.StrategyResource) error {
+	return s.call("RemoveStrategyResources", nil, resources)
+}
+
+// SetInstanceHealthStatus 设置实例的健康状态
+func (s *raftStore) SetInstanceHealthStatus(instanceID string, flag int, revision string) error {
+	return s.call("SetInstanceHealthStatus", nil, instanceID, flag, revision)
+}
+
+// SetL5Extend 设置meta里保存的扩展数据，并返回剩余的meta
+func (s *raftStore) SetL5Extend(serviceID string, meta map[string]interface{}) (map[string]interface{}, error) {
+	var ret map[string]interface{}
+	err := s.call("SetL5Extend", []interface{}{&ret}, serviceID, meta)
+	return ret, err
+}
+
+// UpdateAccessTokensLastUsed Record the last used time of personal access tokens
+func (s *raftStore) UpdateAccessTokensLastUsed(lastUsed map[string]time.Time) error {
+	return s.call("UpdateAccessTokensLastUsed", nil, lastUsed)
+}
+
+// UpdateCircuitBreakerRule update general circuitbreaker rule
+func (s *raftStore) UpdateCircuitBreakerRule(cbRule *model.CircuitBreakerRule) error {
+	return s.call("UpdateCircuitBreakerRule", nil, cbRule)
+}
+
+// UpdateConfigFileGroup 更新配置文件组
+func (s *raftStore) UpdateConfigFileGroup(fileGroup *model.ConfigFileGroup) error {
+	return s.call("UpdateConfigFileGroup", nil, fileGroup)
+}
+
+// UpdateConfigFileReleaseHistoryMetadata 更新配置发布历史的标签
+func (s *raftStore) UpdateConfigFileReleaseHistoryMetadata(history *model.ConfigFileReleaseHistory) error {
+	return s.call("UpdateConfigFileReleaseHistoryMetadata", nil, history)
+}
+
+// UpdateFaultDetectRule update fault detect rule
+func (s *raftStore) UpdateFaultDetectRule(conf *model.FaultDetectRule) error {
+	return s.call("UpdateFaultDetectRule", nil, conf)
+}
+
+// UpdateGroup Update user group
+func (s *raftStore) UpdateGroup(group *model.ModifyUserGroup) error {
+	return s.call("UpdateGroup", nil, group)
+}
+
+// UpdateInstance 更新实例
+func (s *raftStore) UpdateInstance(instance *model.Instance) error {
+	return s.call("UpdateInstance", nil, instance)
+}
+
+// UpdateNamespace Update namespace
+func (s *raftStore) UpdateNamespace(namespace *model.Namespace) error {
+	return s.call("UpdateNamespace", nil, namespace)
+}
+
+// UpdateNamespaceToken Update namespace token
+func (s *raftStore) UpdateNamespaceToken(name string, token string) error {
+	return s.call("UpdateNamespaceToken", nil, name, token)
+}
+
+// UpdateRateLimit 更新限流规则
+func (s *raftStore) UpdateRateLimit(limiting *model.RateLimit) error {
+	return s.call("UpdateRateLimit", nil, limiting)
+}
+
+// UpdateRoutingConfig 更新一个路由配置
+func (s *raftStore) UpdateRoutingConfig(conf *model.RoutingConfig) error {
+	return s.call("UpdateRoutingConfig", nil, conf)
+}
+
+// UpdateRoutingConfigV2 更新一个路由配置
+func (s *raftStore) UpdateRoutingConfigV2(conf *model.RouterConfig) error {
+	return s.call("UpdateRoutingConfigV2", nil, conf)
+}
+
+// UpdateService 更新服务
+func (s *raftStore) UpdateService(service *model.Service, needUpdateOwner bool) error {
+	return s.call("UpdateService", nil, service, needUpdateOwner)
+}
+
+// UpdateServiceAlias 修改服务别名
+func (s *raftStore) UpdateServiceAlias(alias *model.Service, needUpdateOwner bool) error {
+	return s.call("UpdateServiceAlias", nil, alias, needUpdateOwner)
+}
+
+// UpdateServiceContract 更新服务契约
+func (s *raftStore) UpdateServiceContract(contract *model.ServiceContract) error {
+	return s.call("UpdateServiceContract", nil, contract)
+}
+
+// UpdateServiceToken 更新服务token
+func (s *raftStore) UpdateServiceToken(serviceID string, token string, revision string) error {
+	return s.call("UpdateServiceToken", nil, serviceID, token, revision)
+}
+
+// UpdateStrategy Update authentication strategy
+func (s *raftStore) UpdateStrategy(strategy *model.ModifyStrategyDetail) error {
+	return s.call("UpdateStrategy", nil, strategy)
+}
+
+// UpdateUser Update user
+func (s *raftStore) UpdateUser(user *model.User) error {
+	return s.call("UpdateUser", nil, user)
+}
+
+// 以下事务内的写操作先缓存在事务中，事务提交时作为一条 raft 日志整体提交
+
+// ActiveConfigFileReleaseTx 指定激活发布的配置文件（激活具有排他性，同一个配置文件的所有 release 中只能有一个处于 active == true 状态）
+func (s *raftStore) ActiveConfigFileReleaseTx(tx store.Tx, release *model.ConfigFileRelease) error {
+	return s.callTx(tx, "ActiveConfigFileReleaseTx", release)
+}
+
+// CleanConfigFileReleasesTx 清空配置文件发布
+func (s *raftStore) CleanConfigFileReleasesTx(tx store.Tx, namespace, group, fileName string) error {
+	return s.callTx(tx, "CleanConfigFileReleasesTx", namespace, group, fileName)
+}
+
+// CleanGrayResource 清理灰度资源
+func (s *raftStore) CleanGrayResource(tx store.Tx, data *model.GrayResource) error {
+	return s.callTx(tx, "CleanGrayResource", data)
+}
+
+// CreateConfigFileReleaseTx 创建配置文件发布
+func (s *raftStore) CreateConfigFileReleaseTx(tx store.Tx, fileRelease *model.ConfigFileRelease) error {
+	return s.callTx(tx, "CreateConfigFileReleaseTx", fileRelease)
+}
+
+// CreateConfigFileTx 创建配置文件
+func (s *raftStore) CreateConfigFileTx(tx store.Tx, file *model.ConfigFile) error {
+	return s.callTx(tx, "CreateConfigFileTx", file)
+}
+
+// CreateGrayResourceTx 创建灰度资源
+func (s *raftStore) CreateGrayResourceTx(tx store.Tx, data *model.GrayResource) error {
+	return s.callTx(tx, "CreateGrayResourceTx", data)
+}
+
+// CreateRoutingConfigV2Tx 新增一个路由配置
+func (s *raftStore) CreateRoutingConfigV2Tx(tx store.Tx, conf *model.RouterConfig) error {
+	return s.callTx(tx, "CreateRoutingConfigV2Tx", conf)
+}
+
+// DeleteConfigFileReleaseTx 删除配置文件发布内容
+func (s *raftStore) DeleteConfigFileReleaseTx(tx store.Tx, data *model.ConfigFileReleaseKey) error {
+	return s.callTx(tx, "DeleteConfigFileReleaseTx", data)
+}
+
+// DeleteConfigFileTx 删除配置文件
+func (s *raftStore) DeleteConfigFileTx(tx store.Tx, namespace, group, name string) error {
+	return s.callTx(tx, "DeleteConfigFileTx", namespace, group, name)
+}
+
+// DeleteRoutingConfigTx 删除一个路由配置
+func (s *raftStore) DeleteRoutingConfigTx(tx store.Tx, serviceID string) error {
+	return s.callTx(tx, "DeleteRoutingConfigTx", serviceID)
+}
+
+// InactiveConfigFileReleaseTx 指定失效发布的配置文件（失效具有排他性，同一个配置文件的所有 release 中能有多个处于 active == false 状态）
+func (s *raftStore) InactiveConfigFileReleaseTx(tx store.Tx, release *model.ConfigFileRelease) error {
+	return s.callTx(tx, "InactiveConfigFileReleaseTx", release)
+}
+
+// UpdateConfigFileReleaseMetadataTx 更新配置文件发布的标签
+func (s *raftStore) UpdateConfigFileReleaseMetadataTx(tx store.Tx, release *model.ConfigFileRelease) error {
+	return s.callTx(tx, "UpdateConfigFileReleaseMetadataTx", release)
+}
+
+// UpdateConfigFileTx 更新配置文件
+func (s *raftStore) UpdateConfigFileTx(tx store.Tx, file *model.ConfigFile) error {
+	return s.callTx(tx, "UpdateConfigFileTx", file)
+}
+
+// UpdateRoutingConfigV2Tx 更新一个路由配置
+func (s *raftStore) UpdateRoutingConfigV2Tx(tx store.Tx, conf *model.RouterConfig) error {
+	return s.callTx(tx, "UpdateRoutingConfigV2Tx", conf)
+}
+
+// 以下事务内的读操作使用事务持有的本地只读事务完成
+
+// GetConfigFileActiveReleaseTx 获取配置文件处于 Active 的配置发布记录
+func (s *raftStore) GetConfigFileActiveReleaseTx(tx store.Tx,
+	file *model.ConfigFileKey) (*model.ConfigFileRelease, error) {
+	return s.Store.GetConfigFileActiveReleaseTx(localTx(tx), file)
+}
+
+// GetConfigFileBetaReleaseTx 获取灰度发布的配置文件信息
+func (s *raftStore) GetConfigFileBetaReleaseTx(tx store.Tx,
+	file *model.ConfigFileKey) (*model.ConfigFileRelease, error) {
+	return s.Store.GetConfigFileBetaReleaseTx(localTx(tx), file)
+}
+
+// GetConfigFileReleaseTx 在已开启的事务中获取配置文件发布内容，只获取 flag=0 的记录
+func (s *raftStore) GetConfigFileReleaseTx(tx store.Tx,
+	req *model.ConfigFileReleaseKey) (*model.ConfigFileRelease, error) {
+	return s.Store.GetConfigFileReleaseTx(localTx(tx), req)
+}
+
+// GetConfigFileTx 获取配置文件
+func (s *raftStore) GetConfigFileTx(tx store.Tx, namespace, group, name string) (*model.ConfigFile, error) {
+	return s.Store.GetConfigFileTx(localTx(tx), namespace, group, name)
+}
+
+// GetInstancesCountTx 获取有效的实例总数
+func (s *raftStore) GetInstancesCountTx(tx store.Tx) (uint32, error) {
+	return s.Store.GetInstancesCountTx(localTx(tx))
+}
+
+// GetMoreInstances 根据mtime获取增量instances，返回所有store的变更信息 此方法用于 cache 增量更新，需要注意 mtime 应为数据库时间戳
+func (s *raftStore) GetMoreInstances(tx store.Tx,
+	mtime time.Time, firstUpdate, needMeta bool, serviceID []string) (map[string]*model.Instance, error) {
+	return s.Store.GetMoreInstances(localTx(tx), mtime, firstUpdate, needMeta, serviceID)
+}
+
+// GetRoutingConfigV2WithIDTx 根据服务ID拉取路由配置
+func (s *raftStore) GetRoutingConfigV2WithIDTx(tx store.Tx, id string) (*model.RouterConfig, error) {
+	return s.Store.GetRoutingConfigV2WithIDTx(localTx(tx), id)
+}
@@ -0,0 +1,100 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package raftdb
+
+import (
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+// transaction 事务对象，排它锁与共享锁都通过 raft 复制到集群，在 Commit 时统一释放
+type transaction struct {
+	s *raftStore
+	// local 本地存储的事务对象，负责加锁之后的读取
+	local     store.Transaction
+	owner     string
+	locks     []string
+	committed bool
+}
+
+// Commit 提交事务，释放事务持有的全部锁
+func (t *transaction) Commit() error {
+	if t.committed {
+		return nil
+	}
+	t.committed = true
+	return t.s.unlock(t.owner, t.locks)
+}
+
+// LockBootstrap 启动锁，限制Server启动的并发数
+func (t *transaction) LockBootstrap(key string, server string) error {
+	return t.lock("bootstrap/"+key, false)
+}
+
+// LockNamespace 排它锁namespace
+func (t *transaction) LockNamespace(name string) (*model.Namespace, error) {
+	if err := t.lock("namespace/"+name, false); err != nil {
+		return nil, err
+	}
+	return t.local.LockNamespace(name)
+}
+
+// RLockNamespace 共享锁namespace
+func (t *transaction) RLockNamespace(name string) (*model.Namespace, error) {
+	if err := t.lock("namespace/"+name, true); err != nil {
+		return nil, err
+	}
+	return t.local.LockNamespace(name)
+}
+
+// DeleteNamespace 删除namespace，并且提交事务
+func (t *transaction) DeleteNamespace(name string) error {
+	result, err := t.s.propose(&command{Type: commandDeleteNamespace, Key: name})
+	if err == nil {
+		err = result.Err()
+	}
+	if err != nil {
+		_ = t.Commit()
+		return store.Error(err)
+	}
+	return t.Commit()
+}
+
+// LockService 排它锁service
+func (t *transaction) LockService(name string, namespace string) (*model.Service, error) {
+	if err := t.lock("service/"+namespace+"/"+name, false); err != nil {
+		return nil, err
+	}
+	return t.local.LockService(name, namespace)
+}
+
+// RLockService 共享锁service
+func (t *transaction) RLockService(name string, namespace string) (*model.Service, error) {
+	if err := t.lock("service/"+namespace+"/"+name, true); err != nil {
+		return nil, err
+	}
+	return t.local.RLockService(name, namespace)
+}
+
+func (t *transaction) lock(key string, shared bool) error {
+	if err := t.s.lock(t.owner, key, shared); err != nil {
+		return err
+	}
+	t.locks = append(t.locks, key)
+	return nil
+}
@@ -0,0 +1,120 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package raftdb
+
+import (
+	"errors"
+
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+// raftTx 事务内的读操作使用本地 boltdb 的只读事务，写操作先缓存起来，
+// 提交时作为一条 raft 日志复制到集群，由各节点在一个本地写事务中执行，因此事务内的写操作对事务内的读不可见
+type raftTx struct {
+	s     *raftStore
+	local store.Tx
+	owner string
+	batch []*command
+	locks []string
+	done  bool
+}
+
+func newRaftTx(s *raftStore) (*raftTx, error) {
+	local, err := s.local.StartReadTx()
+	if err != nil {
+		return nil, err
+	}
+	return &raftTx{s: s, local: local, owner: utils.NewUUID()}, nil
+}
+
+func toRaftTx(tx store.Tx) (*raftTx, error) {
+	rtx, ok := tx.GetDelegateTx().(*raftTx)
+	if !ok {
+		return nil, errors.New("tx is not started by raft store")
+	}
+	return rtx, nil
+}
+
+// localTx 获取事务对应的本地只读事务
+func localTx(tx store.Tx) store.Tx {
+	if rtx, err := toRaftTx(tx); err == nil {
+		return rtx.local
+	}
+	return tx
+}
+
+// lock 获取排它锁，获取锁之前先释放本地只读事务，获取锁之后重新开启，保证加锁后读取到的是最新的数据
+func (t *raftTx) lock(key string) error {
+	_ = t.local.Rollback()
+	if err := t.s.lock(t.owner, key, false); err != nil {
+		t.local, _ = t.s.local.StartReadTx()
+		return err
+	}
+	t.locks = append(t.locks, key)
+	local, err := t.s.local.StartReadTx()
+	if err != nil {
+		return err
+	}
+	t.local = local
+	return nil
+}
+
+// Commit 提交事务中缓存的写操作，并释放事务中持有的锁
+func (t *raftTx) Commit() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	_ = t.local.Rollback()
+	defer t.unlock()
+	if len(t.batch) == 0 {
+		return nil
+	}
+	result, err := t.s.propose(&command{Type: commandBatch, Batch: t.batch})
+	if err != nil {
+		return store.Error(err)
+	}
+	return result.Err()
+}
+
+// Rollback 丢弃事务中缓存的写操作，并释放事务中持有的锁
+func (t *raftTx) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	_ = t.local.Rollback()
+	t.unlock()
+	return nil
+}
+
+func (t *raftTx) unlock() {
+	_ = t.s.unlock(t.owner, t.locks)
+	t.locks = nil
+}
+
+// GetDelegateTx 返回事务本身，store 的各个方法通过它获取缓存写操作的事务
+func (t *raftTx) GetDelegateTx() interface{} {
+	return t
+}
+
+// CreateReadView 本地只读事务本身就是一个快照
+func (t *raftTx) CreateReadView() error {
+	return nil
+}
@@ -62,6 +62,9 @@ func GetStore() (Store, error) {
 	}
 
 	initialize(store)
+	if changeNotifier != nil {
+		return wrapNotifyStore(store), nil
+	}
 	return store, nil
 }
 